@@ -0,0 +1,200 @@
+package chash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// DefaultHashCount is the number of points on the ring of caches without a hashCount in the CRConfig.
+const DefaultHashCount = 1000
+
+// Hash returns the consistent hash of the string, which is the first 8 bytes of its MD5 sum.
+func Hash(s string) uint64 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// RequestKey returns the string to hash for a request to the Delivery Service, which is the path, and the Delivery Service's consistentHashQueryParams in the query, sorted.
+// Other query parameters don't change the key, so requests differing only in them go to the same cache.
+func RequestKey(ds tc.CRConfigDeliveryService, path string, query url.Values) string {
+	if len(ds.ConsistentHashQueryParams) == 0 || len(query) == 0 {
+		return path
+	}
+	names := make([]string, 0, len(ds.ConsistentHashQueryParams))
+	for _, name := range ds.ConsistentHashQueryParams {
+		if _, ok := query[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return path
+	}
+	sort.Strings(names)
+
+	params := []string{}
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue // duplicate param in the CRConfig
+		}
+		for _, val := range query[name] {
+			params = append(params, url.QueryEscape(name)+"="+url.QueryEscape(val))
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+type node struct {
+	hash  uint64
+	cache tc.CacheName
+}
+
+// Ring is a consistent hash ring of caches. Each cache has hashCount points on the ring, hashed from its hashId, so adding or removing a cache only moves the keys on its own points.
+type Ring struct {
+	nodes []node
+}
+
+// NewRing creates the ring of the caches, with the hashId and hashCount of each from the CRConfig. Caches without a hashId use their name.
+func NewRing(crc *tc.CRConfig, caches []tc.CacheName) *Ring {
+	r := &Ring{}
+	for _, cache := range caches {
+		hashID := string(cache)
+		hashCount := DefaultHashCount
+		if crc == nil {
+			// no CRConfig, use the defaults
+		} else if server, ok := crc.ContentServers[string(cache)]; ok {
+			if server.HashId != nil && *server.HashId != "" {
+				hashID = *server.HashId
+			}
+			if server.HashCount != nil && *server.HashCount > 0 {
+				hashCount = *server.HashCount
+			}
+		}
+		for i := 0; i < hashCount; i++ {
+			r.nodes = append(r.nodes, node{hash: Hash(hashID + "-" + strconv.Itoa(i)), cache: cache})
+		}
+	}
+	sort.Slice(r.nodes, func(i, j int) bool {
+		if r.nodes[i].hash != r.nodes[j].hash {
+			return r.nodes[i].hash < r.nodes[j].hash
+		}
+		return r.nodes[i].cache < r.nodes[j].cache // so colliding points are the same for any order of caches
+	})
+	return r
+}
+
+// Get returns the cache of the first point on the ring at or after the hash, or false if the ring is empty.
+func (r *Ring) Get(hash uint64) (tc.CacheName, bool) {
+	if len(r.nodes) == 0 {
+		return "", false
+	}
+	i := sort.Search(len(r.nodes), func(i int) bool { return r.nodes[i].hash >= hash })
+	if i == len(r.nodes) {
+		i = 0
+	}
+	return r.nodes[i].cache, true
+}
+
+// GetAvailable returns the cache of the first point on the ring at or after the hash whose cache is available, or false if none of the ring's caches are available.
+// Keys of an unavailable cache move to the next available caches on the ring, and move back when it becomes available again, without the ring being rebuilt.
+func (r *Ring) GetAvailable(hash uint64, available []tc.CacheName) (tc.CacheName, bool) {
+	if len(r.nodes) == 0 || len(available) == 0 {
+		return "", false
+	}
+	availableSet := make(map[tc.CacheName]struct{}, len(available))
+	for _, cache := range available {
+		availableSet[cache] = struct{}{}
+	}
+	start := sort.Search(len(r.nodes), func(i int) bool { return r.nodes[i].hash >= hash })
+	for i := 0; i < len(r.nodes); i++ {
+		node := r.nodes[(start+i)%len(r.nodes)]
+		if _, ok := availableSet[node.cache]; ok {
+			return node.cache, true
+		}
+	}
+	return "", false
+}
+
+// Rings creates and holds the rings of a CRConfig, one for each Delivery Service and Cache Group, of all the Cache Group's caches assigned to the Delivery Service.
+// Rings are only created the first time they're needed, and include unavailable caches, which are skipped by Ring.GetAvailable, so the number of rings is bounded by the CRConfig, not by how often cache availability changes.
+// Rings is safe for use by multiple goroutines.
+type Rings struct {
+	crc   *tc.CRConfig
+	rings map[dsCacheGroup]*Ring
+	m     *sync.RWMutex
+}
+
+type dsCacheGroup struct {
+	ds tc.DeliveryServiceName
+	cg tc.CacheGroupName
+}
+
+// NewRings creates a new Rings, for the caches in the CRConfig. The CRConfig MUST NOT be modified after calling this.
+func NewRings(crc *tc.CRConfig) *Rings {
+	return &Rings{crc: crc, rings: map[dsCacheGroup]*Ring{}, m: &sync.RWMutex{}}
+}
+
+// Ring returns the ring of the caches in the Cache Group assigned to the Delivery Service in the CRConfig, whether or not they're available.
+func (r *Rings) Ring(ds tc.DeliveryServiceName, cg tc.CacheGroupName) *Ring {
+	key := dsCacheGroup{ds: ds, cg: cg}
+	r.m.RLock()
+	ring, ok := r.rings[key]
+	r.m.RUnlock()
+	if ok {
+		return ring
+	}
+
+	caches := []tc.CacheName{}
+	if r.crc != nil {
+		for name, server := range r.crc.ContentServers {
+			if server.CacheGroup == nil || *server.CacheGroup != string(cg) {
+				continue
+			}
+			if _, ok := server.DeliveryServices[string(ds)]; !ok {
+				continue
+			}
+			caches = append(caches, tc.CacheName(name))
+		}
+	}
+	ring = NewRing(r.crc, caches)
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.rings[key] = ring
+	return ring
+}
+
+// DeliveryService returns the CRConfig Delivery Service, or false if it doesn't exist.
+func (r *Rings) DeliveryService(ds tc.DeliveryServiceName) (tc.CRConfigDeliveryService, bool) {
+	if r.crc == nil {
+		return tc.CRConfigDeliveryService{}, false
+	}
+	crDS, ok := r.crc.DeliveryServices[string(ds)]
+	return crDS, ok
+}
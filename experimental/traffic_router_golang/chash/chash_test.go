@@ -0,0 +1,216 @@
+package chash
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func makeTestCRConfig(caches ...string) *tc.CRConfig {
+	crc := &tc.CRConfig{ContentServers: map[string]tc.CRConfigTrafficOpsServer{}}
+	for _, cache := range caches {
+		crc.ContentServers[cache] = tc.CRConfigTrafficOpsServer{HashId: util.StrPtr(cache + "-hash"), HashCount: util.IntPtr(100)}
+	}
+	return crc
+}
+
+func testPaths() []string {
+	paths := []string{}
+	for i := 0; i < 10000; i++ {
+		paths = append(paths, "/content/"+strconv.Itoa(i)+".ts")
+	}
+	return paths
+}
+
+func TestRingGet(t *testing.T) {
+	caches := []tc.CacheName{"edge0", "edge1", "edge2", "edge3"}
+	crc := makeTestCRConfig("edge0", "edge1", "edge2", "edge3")
+	ring := NewRing(crc, caches)
+	reversed := NewRing(crc, []tc.CacheName{"edge3", "edge2", "edge1", "edge0"})
+
+	counts := map[tc.CacheName]int{}
+	for _, path := range testPaths() {
+		cache, ok := ring.Get(Hash(path))
+		if !ok {
+			t.Fatalf("expected: cache for '%v', actual: empty ring", path)
+		}
+		if again, _ := ring.Get(Hash(path)); again != cache {
+			t.Fatalf("expected: same cache for '%v', actual: %v then %v", path, cache, again)
+		}
+		if other, _ := reversed.Get(Hash(path)); other != cache {
+			t.Fatalf("expected: same cache for '%v' for any order of caches, actual: %v and %v", path, cache, other)
+		}
+		counts[cache]++
+	}
+	for _, cache := range caches {
+		if counts[cache] < 1500 || counts[cache] > 3500 {
+			t.Errorf("expected: about a quarter of 10000 paths on each cache, actual: %v", counts)
+			break
+		}
+	}
+
+	if _, ok := NewRing(crc, nil).Get(Hash("/foo")); ok {
+		t.Errorf("expected: empty ring to have no cache, actual: cache")
+	}
+}
+
+func TestRingStable(t *testing.T) {
+	crc := makeTestCRConfig("edge0", "edge1", "edge2", "edge3", "edge4")
+	before := NewRing(crc, []tc.CacheName{"edge0", "edge1", "edge2", "edge3"})
+	removed := NewRing(crc, []tc.CacheName{"edge0", "edge1", "edge3"})
+	added := NewRing(crc, []tc.CacheName{"edge0", "edge1", "edge2", "edge3", "edge4"})
+
+	moved := 0
+	for _, path := range testPaths() {
+		hash := Hash(path)
+		cache, _ := before.Get(hash)
+		if afterRemove, _ := removed.Get(hash); cache != "edge2" && afterRemove != cache {
+			t.Fatalf("expected: '%v' to stay on %v when edge2 is removed, actual: %v", path, cache, afterRemove)
+		}
+		afterAdd, _ := added.Get(hash)
+		if afterAdd != cache {
+			if afterAdd != "edge4" {
+				t.Fatalf("expected: '%v' to stay on %v or move to the added edge4, actual: %v", path, cache, afterAdd)
+			}
+			moved++
+		}
+	}
+	if moved < 1000 || moved > 3000 {
+		t.Errorf("expected: about a fifth of 10000 paths to move to the added cache, actual: %v", moved)
+	}
+}
+
+func TestRingHashID(t *testing.T) {
+	// the hashId, not the name, places a cache on the ring, so a renamed cache with the same hashId gets the same content.
+	crc := &tc.CRConfig{ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+		"edge0":     {HashId: util.StrPtr("edge0"), HashCount: util.IntPtr(100)},
+		"edge1":     {HashId: util.StrPtr("edge1"), HashCount: util.IntPtr(100)},
+		"edge1-new": {HashId: util.StrPtr("edge1"), HashCount: util.IntPtr(100)},
+	}}
+	before := NewRing(crc, []tc.CacheName{"edge0", "edge1"})
+	after := NewRing(crc, []tc.CacheName{"edge0", "edge1-new"})
+	for _, path := range testPaths() {
+		cache, _ := before.Get(Hash(path))
+		newCache, _ := after.Get(Hash(path))
+		if cache == "edge1" {
+			cache = "edge1-new"
+		}
+		if cache != newCache {
+			t.Fatalf("expected: '%v' on %v, actual: %v", path, cache, newCache)
+		}
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	ds := tc.CRConfigDeliveryService{ConsistentHashQueryParams: []string{"format", "bitrate"}}
+	type testCase struct {
+		path     string
+		query    string
+		expected string
+	}
+	testCases := []testCase{
+		{"/video/a.m3u8", "", "/video/a.m3u8"},
+		{"/video/a.m3u8", "session=42", "/video/a.m3u8"},
+		{"/video/a.m3u8", "session=42&format=hls&bitrate=800", "/video/a.m3u8?bitrate=800&format=hls"},
+		{"/video/a.m3u8", "format=hls&session=43&bitrate=800", "/video/a.m3u8?bitrate=800&format=hls"},
+		{"/video/a.m3u8", "format=a+b&format=c", "/video/a.m3u8?format=a+b&format=c"},
+	}
+	for _, test := range testCases {
+		query, err := url.ParseQuery(test.query)
+		if err != nil {
+			t.Fatalf("parsing query '%v': %v", test.query, err)
+		}
+		if actual := RequestKey(ds, test.path, query); actual != test.expected {
+			t.Errorf("path '%v' query '%v' expected: '%v' actual: '%v'", test.path, test.query, test.expected, actual)
+		}
+	}
+
+	query, _ := url.ParseQuery("format=hls")
+	if actual := RequestKey(tc.CRConfigDeliveryService{}, "/video/a.m3u8", query); actual != "/video/a.m3u8" {
+		t.Errorf("no consistent hash query params expected: '/video/a.m3u8' actual: '%v'", actual)
+	}
+}
+
+func TestRingGetAvailable(t *testing.T) {
+	crc := makeTestCRConfig("edge0", "edge1", "edge2", "edge3")
+	ring := NewRing(crc, []tc.CacheName{"edge0", "edge1", "edge2", "edge3"})
+	without := NewRing(crc, []tc.CacheName{"edge0", "edge1", "edge3"})
+	available := []tc.CacheName{"edge0", "edge1", "edge3"}
+
+	for _, path := range testPaths() {
+		hash := Hash(path)
+		all, _ := ring.Get(hash)
+		if cache, _ := ring.GetAvailable(hash, []tc.CacheName{"edge3", "edge2", "edge1", "edge0"}); cache != all {
+			t.Fatalf("expected: '%v' on %v with all caches available, actual: %v", path, all, cache)
+		}
+		// skipping an unavailable cache gives the same cache as a ring without it, so keys only move off the unavailable cache.
+		expected, _ := without.Get(hash)
+		if cache, _ := ring.GetAvailable(hash, available); cache != expected {
+			t.Fatalf("expected: '%v' on %v with edge2 unavailable, actual: %v", path, expected, cache)
+		}
+	}
+
+	if _, ok := ring.GetAvailable(Hash("/foo"), []tc.CacheName{"edge9"}); ok {
+		t.Errorf("expected: no cache when none of the ring's caches are available, actual: cache")
+	}
+	if _, ok := ring.GetAvailable(Hash("/foo"), nil); ok {
+		t.Errorf("expected: no cache when no caches are available, actual: cache")
+	}
+}
+
+func TestRings(t *testing.T) {
+	crc := &tc.CRConfig{ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+		"edge0": {CacheGroup: util.StrPtr("cg0"), DeliveryServices: map[string][]string{"ds0": nil, "ds1": nil}},
+		"edge1": {CacheGroup: util.StrPtr("cg0"), DeliveryServices: map[string][]string{"ds0": nil}},
+		"edge2": {CacheGroup: util.StrPtr("cg1"), DeliveryServices: map[string][]string{"ds0": nil}},
+	}}
+	rings := NewRings(crc)
+	ring := rings.Ring("ds0", "cg0")
+	if other := rings.Ring("ds0", "cg0"); other != ring {
+		t.Errorf("expected: the same ring for the same delivery service and cachegroup, actual: different rings")
+	}
+
+	caches := map[tc.CacheName]struct{}{}
+	for _, path := range testPaths() {
+		cache, ok := ring.Get(Hash(path))
+		if !ok {
+			t.Fatalf("expected: cache for '%v', actual: empty ring", path)
+		}
+		caches[cache] = struct{}{}
+	}
+	if _, ok := caches["edge0"]; !ok || len(caches) != 2 {
+		t.Errorf("expected: ds0 cg0 ring of edge0 and edge1, actual: %v", caches)
+	}
+
+	for _, path := range testPaths() {
+		if cache, _ := rings.Ring("ds1", "cg0").Get(Hash(path)); cache != "edge0" {
+			t.Fatalf("expected: ds1 cg0 ring of edge0, actual: %v", cache)
+		}
+	}
+	if _, ok := rings.Ring("ds1", "cg1").Get(Hash("/foo")); ok {
+		t.Errorf("expected: empty ring for a cachegroup with no caches assigned to the delivery service, actual: cache")
+	}
+}
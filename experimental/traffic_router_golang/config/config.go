@@ -33,6 +33,7 @@ import (
 
 type Cfg struct {
 	Port                  uint     `json:"port"`
+	DNSPort               uint     `json:"dns_port"`
 	Monitors              []*URL   `json:"monitors"`
 	ReqTimeout            Duration `json:"request_timeout_ms"`
 	CRConfigInterval      Duration `json:"crconfig_poll_interval_ms"`
@@ -45,6 +46,8 @@ type Cfg struct {
 	TrafficOpsClientCache bool     `json:"traffic_ops_client_cache"`
 	TrafficOpsTimeout     Duration `json:"traffic_ops_timeout_ms"`
 	CoverageZoneFile      string   `json:"coverage_zone_file"`
+	GeolocationFile       string   `json:"geolocation_file"`
+	GeolocationInterval   Duration `json:"geolocation_poll_interval_ms"`
 	LogLocations
 }
 
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/chash"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfig"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfigregex"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/fetch"
@@ -73,7 +74,7 @@ func createCGSearcher(crc *tc.CRConfig) (cgsrch.Ths, error) {
 	return ths, nil
 }
 
-// createNextCacher creates and returns a NextCacher, which can be used to get the next cache to use for each Delivery Service (e.g. via round-robin, consistent hash, etc).
+// createNextCacher creates and returns a NextCacher, which can be used to get the next cache to use for each Delivery Service by round-robin. DNS routing uses it, because DNS queries have no path to consistent hash.
 func createNextCacher(crc *tc.CRConfig) nextcache.NextCacher {
 	dses := make([]tc.DeliveryServiceName, 0, len(crc.DeliveryServices))
 	for ds, _ := range crc.DeliveryServices {
@@ -83,11 +84,12 @@ func createNextCacher(crc *tc.CRConfig) nextcache.NextCacher {
 }
 
 // TODO implement HTTP poller
-func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, nextcache.Ths, error) {
+func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfigregex.Ths, cgsrch.Ths, nextcache.Ths, chash.Ths, error) {
 	thsCrcRgx := crconfigregex.NewThs()
 	thsCrc := crconfig.NewThs()
 	thsCGSearcher := cgsrch.NewThs()
 	thsNextCacher := nextcache.NewThs()
+	thsRings := chash.NewThs()
 	prevBts := []byte{}
 	prevCrc := (*tc.CRConfig)(nil)
 
@@ -127,6 +129,7 @@ func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfi
 		nextCacher := createNextCacher(crc)
 
 		thsNextCacher.Set(nextCacher)
+		thsRings.Set(chash.NewRings(crc))
 		thsCGSearcher.Set(cgSearcher)
 		thsCrc.Set(crc)
 		thsCrcRgx.Set(&crcRgx)
@@ -143,5 +146,5 @@ func Start(fetcher fetch.Fetcher, interval time.Duration) (crconfig.Ths, crconfi
 			get()
 		}
 	}()
-	return thsCrc, thsCrcRgx, thsCGSearcher, thsNextCacher, nil
+	return thsCrc, thsCrcRgx, thsCGSearcher, thsNextCacher, thsRings, nil
 }
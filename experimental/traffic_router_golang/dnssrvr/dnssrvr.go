@@ -0,0 +1,440 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfigregex"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/ipmap"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/nextcache"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/miekg/dns"
+)
+
+// MaxUDPSize is the largest UDP response, for clients which advertise a larger EDNS buffer. Larger responses are truncated, and clients retry over TCP.
+const MaxUDPSize = 4096
+
+// handler answers DNS queries for the CDN zone. It's safe for use by multiple goroutines.
+type handler struct {
+	crc        crconfig.Ths
+	regexes    crconfigregex.Ths
+	availSrvrs availableservers.AvailableServers
+	cgSrch     cgsrch.Ths
+	nextCacher nextcache.Ths
+	locs       ipmap.IPMap
+	zones      *zoneCache
+}
+
+// zoneCache holds the zone of the latest CRConfig, so it's only built when the CRConfig changes.
+type zoneCache struct {
+	z *zone
+	m *sync.Mutex
+}
+
+func (c *zoneCache) get(crc *tc.CRConfig) (*zone, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.z != nil && c.z.crc == crc {
+		return c.z, nil
+	}
+	z, err := makeZone(crc)
+	if err != nil {
+		return nil, err
+	}
+	c.z = z
+	return z, nil
+}
+
+func newHandler(
+	crc crconfig.Ths,
+	regexes crconfigregex.Ths,
+	availSrvrs availableservers.AvailableServers,
+	cgSrch cgsrch.Ths,
+	nextCacher nextcache.Ths,
+	locs ipmap.IPMap,
+) *handler {
+	return &handler{
+		crc:        crc,
+		regexes:    regexes,
+		availSrvrs: availSrvrs,
+		cgSrch:     cgSrch,
+		nextCacher: nextCacher,
+		locs:       locs,
+		zones:      &zoneCache{m: &sync.Mutex{}},
+	}
+}
+
+// ServeDNS implements dns.Handler. Responses over UDP which are too large for the client are truncated.
+func (h *handler) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	clientIP := net.IP(nil)
+	isUDP := false
+	switch addr := w.RemoteAddr().(type) {
+	case *net.UDPAddr:
+		clientIP = addr.IP
+		isUDP = true
+	case *net.TCPAddr:
+		clientIP = addr.IP
+	}
+
+	resp := h.respond(req, clientIP)
+
+	maxSize := dns.MinMsgSize
+	if opt := req.IsEdns0(); opt != nil {
+		resp.SetEdns0(MaxUDPSize, false)
+		if size := int(opt.UDPSize()); size > maxSize {
+			maxSize = size
+		}
+		if maxSize > MaxUDPSize {
+			maxSize = MaxUDPSize
+		}
+	}
+	if isUDP && resp.Len() > maxSize {
+		resp.Truncated = true
+		resp.Answer = nil
+		resp.Ns = nil
+		extra := []dns.RR{}
+		for _, rr := range resp.Extra {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				extra = append(extra, rr)
+			}
+		}
+		resp.Extra = extra
+	}
+
+	if err := w.WriteMsg(resp); err != nil {
+		log.Errorln("DNS writing response to " + w.RemoteAddr().String() + ": " + err.Error())
+	}
+}
+
+// respond returns the response to the query from the client IP.
+func (h *handler) respond(req *dns.Msg, clientIP net.IP) *dns.Msg {
+	resp := &dns.Msg{}
+	resp.SetReply(req)
+	resp.Compress = true
+	if req.Opcode != dns.OpcodeQuery {
+		resp.Rcode = dns.RcodeNotImplemented
+		return resp
+	}
+	if len(req.Question) != 1 {
+		resp.Rcode = dns.RcodeFormatError
+		return resp
+	}
+	q := req.Question[0]
+
+	z, err := h.zones.get(h.crc.Get())
+	if err != nil {
+		log.Errorln("DNS query '" + q.Name + "': getting zone: " + err.Error())
+		resp.Rcode = dns.RcodeServerFailure
+		return resp
+	}
+
+	name := strings.ToLower(q.Name)
+	if !dns.IsSubDomain(z.domain, name) || (q.Qclass != dns.ClassINET && q.Qclass != dns.ClassANY) {
+		resp.Rcode = dns.RcodeRefused // not authoritative
+		return resp
+	}
+	if q.Qtype == dns.TypeAXFR || q.Qtype == dns.TypeIXFR {
+		resp.Rcode = dns.RcodeRefused
+		return resp
+	}
+	resp.Authoritative = true
+
+	if name == z.domain {
+		return z.apex(resp, q.Qtype)
+	}
+	if rrs, ok := z.routerAddrs[name]; ok {
+		return z.answer(resp, q.Qtype, rrs)
+	}
+	if rrs, ok := z.static[name]; ok {
+		return z.answer(resp, q.Qtype, rrs)
+	}
+	if dsName, ok := h.deliveryService(z, name); ok {
+		return h.route(z, resp, name, q.Qtype, dsName, clientIP)
+	}
+	if _, ok := z.dsDomains[name]; ok {
+		return z.noData(resp)
+	}
+	return z.nxDomain(resp)
+}
+
+// deliveryService returns the Delivery Service whose routing name is name, which must be "routing-name.xmlid.cdn-domain.".
+func (h *handler) deliveryService(z *zone, name string) (tc.DeliveryServiceName, bool) {
+	labels := strings.Split(strings.TrimSuffix(name, "."+z.domain), ".")
+	if len(labels) != 2 {
+		return "", false
+	}
+	regexes := h.regexes.Get()
+	if regexes == nil {
+		return "", false
+	}
+	dsName, ok := (*crconfigregex.Regexes)(regexes).DeliveryService(strings.TrimSuffix(z.domain, "."), labels[1], labels[0])
+	if !ok {
+		return "", false
+	}
+	ds, ok := z.crc.DeliveryServices[string(dsName)]
+	if !ok {
+		return "", false
+	}
+
+	routingName := DefaultHTTPRoutingName
+	if _, ok := z.dnsDSes[dsName]; ok {
+		routingName = DefaultDNSRoutingName
+	}
+	if ds.RoutingName != nil && *ds.RoutingName != "" {
+		routingName = strings.ToLower(*ds.RoutingName)
+	}
+	if labels[0] != routingName {
+		return "", false
+	}
+	return dsName, true
+}
+
+// route answers the query for the routing name of the Delivery Service.
+// DNS Delivery Services are answered with the available caches of the client's nearest Cache Group, starting with the next cache and limited to the Delivery Service's max DNS answers. HTTP Delivery Services are answered with the Traffic Routers, which redirect clients to caches.
+func (h *handler) route(z *zone, resp *dns.Msg, name string, qtype uint16, dsName tc.DeliveryServiceName, clientIP net.IP) *dns.Msg {
+	ds := z.crc.DeliveryServices[string(dsName)]
+	if qtype == dns.TypeANY {
+		qtype = dns.TypeA
+	}
+	if qtype != dns.TypeA && qtype != dns.TypeAAAA {
+		return z.noData(resp)
+	}
+	ttl := dsTTL(ds, qtype, z.ttls)
+
+	if _, ok := z.dnsDSes[dsName]; !ok {
+		routers := []dns.RR{}
+		for _, rr := range z.routers {
+			rr = dns.Copy(rr)
+			rr.Header().Name = name
+			rr.Header().Ttl = ttl
+			routers = append(routers, rr)
+		}
+		return z.answer(resp, qtype, routers)
+	}
+
+	if qtype == dns.TypeAAAA && (ds.IP6RoutingEnabled == nil || !*ds.IP6RoutingEnabled) {
+		return z.noData(resp)
+	}
+
+	cg, err := h.cacheGroup(ds, clientIP)
+	if err != nil {
+		log.Errorln("DNS query '" + name + "' from " + clientIP.String() + ": " + err.Error())
+		resp.Rcode = dns.RcodeServerFailure
+		return resp
+	}
+
+	srvrs, err := h.availSrvrs.Get(dsName, cg)
+	if err == nil && len(srvrs) == 0 {
+		err = errors.New("no available servers")
+	}
+	if err != nil {
+		log.Warnln("DNS query '" + name + "' from " + clientIP.String() + " ds '" + string(dsName) + "' cg '" + string(cg) + "': getting available servers: " + err.Error())
+		resp.Rcode = dns.RcodeServerFailure
+		return resp
+	}
+
+	nextCacher := h.nextCacher.Get()
+	if nextCacher == nil {
+		log.Errorln("DNS query '" + name + "' ds '" + string(dsName) + "': no next cacher")
+		resp.Rcode = dns.RcodeServerFailure
+		return resp
+	}
+	next, ok := nextCacher.NextCache(dsName)
+	if !ok {
+		// should never happen
+		log.Errorln("DNS query '" + name + "' ds '" + string(dsName) + "' not found in NextCacher")
+		resp.Rcode = dns.RcodeServerFailure
+		return resp
+	}
+
+	maxAnswers := len(srvrs)
+	if ds.MaxDNSIPsForLocation != nil && *ds.MaxDNSIPsForLocation > 0 && *ds.MaxDNSIPsForLocation < maxAnswers {
+		maxAnswers = *ds.MaxDNSIPsForLocation
+	}
+
+	for i := 0; i < len(srvrs) && len(resp.Answer) < maxAnswers; i++ {
+		srvrName := srvrs[(next+uint64(i))%uint64(len(srvrs))]
+		srvr, ok := z.crc.ContentServers[string(srvrName)]
+		if !ok {
+			continue
+		}
+		ipStr := srvr.Ip
+		if qtype == dns.TypeAAAA {
+			ipStr = srvr.Ip6
+		}
+		if ipStr == nil || *ipStr == "" {
+			continue
+		}
+		ip := parseServerIP(*ipStr)
+		if ip == nil || (ip.To4() != nil) != (qtype == dns.TypeA) {
+			log.Warnln("DNS CRConfig server '" + string(srvrName) + "' has malformed IP '" + *ipStr + "', skipping")
+			continue
+		}
+		resp.Answer = append(resp.Answer, addrRR(name, ip, ttl))
+	}
+
+	log.Infoln("DNS query '" + name + "' from " + clientIP.String() + " ds '" + string(dsName) + "' cg '" + string(cg) + "' answered " + strconv.Itoa(len(resp.Answer)) + " caches")
+	if len(resp.Answer) == 0 {
+		return z.noData(resp)
+	}
+	return resp
+}
+
+// cacheGroup returns the Cache Group nearest the client, by its coverage zone location, or else its geolocation, or else the Delivery Service's miss location.
+func (h *handler) cacheGroup(ds tc.CRConfigDeliveryService, clientIP net.IP) (tc.CacheGroupName, error) {
+	pos, _ := h.locs.Location(ds, clientIP)
+
+	cgSrch := h.cgSrch.Get()
+	if cgSrch == nil {
+		return "", errors.New("no cachegroup searcher")
+	}
+	cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
+	if !ok {
+		return "", errors.New("no nearest cachegroup (should only happen if there are no cachegroups)")
+	}
+	return tc.CacheGroupName(cgDat.Obj), nil
+}
+
+// dsTTL returns the TTL of the Delivery Service's A or AAAA records: its TTL for the type, or its TTL, or the CDN's TTL for the type.
+func dsTTL(ds tc.CRConfigDeliveryService, qtype uint16, cdnTTLs tc.CRConfigTTL) uint32 {
+	dsTTLs := tc.CRConfigTTL{}
+	if ds.TTLs != nil {
+		dsTTLs = *ds.TTLs
+	}
+	dsTTL, cdnTTL := dsTTLs.ASeconds, cdnTTLs.ASeconds
+	if qtype == dns.TypeAAAA {
+		dsTTL, cdnTTL = dsTTLs.AAAASeconds, cdnTTLs.AAAASeconds
+	}
+	if dsTTL != nil && *dsTTL != "" {
+		return ttlSeconds(dsTTL, DefaultTTLSeconds)
+	}
+	if ds.TTL != nil && *ds.TTL >= 0 {
+		return uint32(*ds.TTL)
+	}
+	return ttlSeconds(cdnTTL, DefaultTTLSeconds)
+}
+
+// apex answers the query for the CDN domain itself, which has the SOA and NS records.
+func (z *zone) apex(resp *dns.Msg, qtype uint16) *dns.Msg {
+	switch qtype {
+	case dns.TypeSOA:
+		resp.Answer = []dns.RR{z.soa}
+	case dns.TypeNS:
+		resp.Answer = append(resp.Answer, z.ns...)
+	case dns.TypeANY:
+		resp.Answer = append([]dns.RR{z.soa}, z.ns...)
+	default:
+		return z.noData(resp)
+	}
+	if len(resp.Answer) == 0 {
+		return z.noData(resp)
+	}
+	if qtype != dns.TypeSOA {
+		for _, ns := range z.ns {
+			resp.Extra = append(resp.Extra, z.routerAddrs[ns.(*dns.NS).Ns]...)
+		}
+	}
+	return resp
+}
+
+// answer answers the query with the records of the type, or NODATA if there are none. CNAME records answer all types.
+func (z *zone) answer(resp *dns.Msg, qtype uint16, rrs []dns.RR) *dns.Msg {
+	for _, rr := range rrs {
+		if rrtype := rr.Header().Rrtype; rrtype == qtype || rrtype == dns.TypeCNAME || qtype == dns.TypeANY {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	if len(resp.Answer) == 0 {
+		return z.noData(resp)
+	}
+	return resp
+}
+
+// noData answers that the name exists, but has no records of the type.
+func (z *zone) noData(resp *dns.Msg) *dns.Msg {
+	resp.Ns = []dns.RR{z.negativeSOA()}
+	return resp
+}
+
+// nxDomain answers that the name doesn't exist.
+func (z *zone) nxDomain(resp *dns.Msg) *dns.Msg {
+	resp.Rcode = dns.RcodeNameError
+	resp.Ns = []dns.RR{z.negativeSOA()}
+	return resp
+}
+
+// negativeSOA returns the SOA for negative answers, whose TTL is the SOA minimum if it's less, per RFC 2308.
+func (z *zone) negativeSOA() dns.RR {
+	soa := dns.Copy(z.soa).(*dns.SOA)
+	if soa.Minttl < soa.Hdr.Ttl {
+		soa.Hdr.Ttl = soa.Minttl
+	}
+	return soa
+}
+
+// Start starts serving DNS on the given port, over both UDP and TCP. It returns the UDP and TCP servers, or an error if either can't listen.
+func Start(
+	crc crconfig.Ths,
+	regexes crconfigregex.Ths,
+	availableServers availableservers.AvailableServers,
+	cgSrch cgsrch.Ths,
+	nextCacher nextcache.Ths,
+	locs ipmap.IPMap,
+	port uint,
+) (*dns.Server, *dns.Server, error) {
+	addr := ":" + strconv.Itoa(int(port))
+	udpConn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, nil, errors.New("listening on UDP " + addr + ": " + err.Error())
+	}
+	tcpListener, err := net.Listen("tcp", addr)
+	if err != nil {
+		udpConn.Close()
+		return nil, nil, errors.New("listening on TCP " + addr + ": " + err.Error())
+	}
+	udpSrvr, tcpSrvr := serve(udpConn, tcpListener, newHandler(crc, regexes, availableServers, cgSrch, nextCacher, locs))
+	return udpSrvr, tcpSrvr, nil
+}
+
+// serve serves DNS with the handler on the UDP connection and TCP listener, and returns their servers.
+func serve(udpConn net.PacketConn, tcpListener net.Listener, handler dns.Handler) (*dns.Server, *dns.Server) {
+	udpSrvr := &dns.Server{PacketConn: udpConn, Handler: handler}
+	tcpSrvr := &dns.Server{Listener: tcpListener, Handler: handler}
+	for _, srvr := range []*dns.Server{udpSrvr, tcpSrvr} {
+		go func(srvr *dns.Server) {
+			if err := srvr.ActivateAndServe(); err != nil {
+				log.Errorln("DNS serving: " + err.Error())
+			}
+		}(srvr)
+	}
+	return udpSrvr, tcpSrvr
+}
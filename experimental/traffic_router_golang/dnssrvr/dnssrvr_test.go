@@ -0,0 +1,291 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/availableservers"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/cgsrch"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfigregex"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/ipmap"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/nextcache"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	"github.com/miekg/dns"
+)
+
+func makeTestCRConfig() *tc.CRConfig {
+	online := tc.CRConfigRouterStatus("ONLINE")
+	offline := tc.CRConfigRouterStatus("OFFLINE")
+	east, west := "cg-east", "cg-west"
+	return &tc.CRConfig{
+		Config: map[string]interface{}{
+			"domain_name": "cdn.example.net",
+			"soa":         map[string]interface{}{"admin": "twelve_monkeys", "minimum": "30", "refresh": "28800"},
+			"ttls":        map[string]interface{}{"A": "60", "NS": "3600", "SOA": "86400"},
+		},
+		ContentRouters: map[string]tc.CRConfigRouter{
+			"tr0": {FQDN: util.StrPtr("tr0.example.net"), IP: util.StrPtr("192.0.2.53"), IP6: util.StrPtr("2001:db8::53/64"), ServerStatus: &online},
+			"tr1": {FQDN: util.StrPtr("tr1.example.net"), IP: util.StrPtr("192.0.2.54"), ServerStatus: &offline},
+		},
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge0": {CacheGroup: &east, Ip: util.StrPtr("192.0.2.10"), Ip6: util.StrPtr("2001:db8::10/64")},
+			"edge1": {CacheGroup: &east, Ip: util.StrPtr("192.0.2.11"), Ip6: util.StrPtr("2001:db8::11/64")},
+			"edge2": {CacheGroup: &east, Ip: util.StrPtr("192.0.2.12"), Ip6: util.StrPtr("2001:db8::12/64")},
+			"edge3": {CacheGroup: &west, Ip: util.StrPtr("198.51.100.10")},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"ds0": {
+				MatchSets:            []*tc.MatchSet{{Protocol: "DNS", MatchList: []tc.MatchList{{Regex: `.*\.ds0\..*`}}}},
+				Domains:              []string{"ds0.cdn.example.net"},
+				RoutingName:          util.StrPtr("edge"),
+				TTLs:                 &tc.CRConfigTTL{ASeconds: util.StrPtr("42")},
+				TTL:                  util.IntPtr(20),
+				MaxDNSIPsForLocation: util.IntPtr(2),
+				IP6RoutingEnabled:    util.BoolPtr(true),
+				MissLocation:         &tc.CRConfigLatitudeLongitudeShort{Lat: 40, Lon: -75},
+				StaticDNSEntries: []tc.CRConfigStaticDNSEntry{
+					{Name: "static", TTL: 300, Type: "A_RECORD", Value: "203.0.113.1"},
+					{Name: "alias", TTL: 300, Type: "CNAME_RECORD", Value: "origin.example.org"},
+				},
+			},
+			"ds1": {
+				MatchSets:   []*tc.MatchSet{{Protocol: "HTTP", MatchList: []tc.MatchList{{Regex: `.*\.ds1\..*`}}}},
+				Domains:     []string{"ds1.cdn.example.net"},
+				RoutingName: util.StrPtr("ccr"),
+				TTL:         util.IntPtr(120),
+			},
+		},
+		EdgeLocations: map[string]tc.CRConfigLatitudeLongitude{
+			east: {Lat: 40, Lon: -75},
+			west: {Lat: 37, Lon: -122},
+		},
+		Stats: tc.CRConfigStats{DateUnixSeconds: func() *int64 { i := int64(1600000000); return &i }()},
+	}
+}
+
+func makeTestHandler(t *testing.T) *handler {
+	crc := makeTestCRConfig()
+	crcThs := crconfig.NewThs()
+	crcThs.Set(crc)
+
+	regexes, err := crconfigregex.Get(crc)
+	if err != nil {
+		t.Fatalf("getting regexes: %v", err)
+	}
+	regexThs := crconfigregex.NewThs()
+	regexThs.Set(&regexes)
+
+	cgSearcher, err := cgsrch.Create(crc)
+	if err != nil {
+		t.Fatalf("creating cachegroup searcher: %v", err)
+	}
+	cgSrchThs := cgsrch.NewThs()
+	cgSrchThs.Set(cgSearcher)
+
+	nextCacherThs := nextcache.NewThs()
+	nextCacherThs.Set(nextcache.New([]tc.DeliveryServiceName{"ds0", "ds1"}))
+
+	availSrvrs := availableservers.New()
+	availSrvrs.Set(availableservers.AvailableServersMap{
+		"ds0": {
+			"cg-east": {"edge0", "edge1", "edge2"},
+			"cg-west": {"edge3"},
+		},
+	})
+
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{
+		CoverageZones: map[tc.CacheGroupName]coveragezone.JSONCoverageZoneCacheGroup{
+			"cg-west": {Coordinates: tc.CRConfigLatitudeLongitude{Lat: 37, Lon: -122}, Network: []string{"127.0.0.0/8"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+
+	return newHandler(crcThs, regexThs, availSrvrs, cgSrchThs, nextCacherThs, ipmap.New(cz, nil))
+}
+
+func testQuery(h *handler, name string, qtype uint16, clientIP string) *dns.Msg {
+	req := &dns.Msg{}
+	req.SetQuestion(name, qtype)
+	return h.respond(req, net.ParseIP(clientIP))
+}
+
+func answerStrs(rrs []dns.RR) []string {
+	strs := []string{}
+	for _, rr := range rrs {
+		strs = append(strs, rr.String())
+	}
+	return strs
+}
+
+func TestRespondDNSDeliveryService(t *testing.T) {
+	h := makeTestHandler(t)
+
+	// a client outside the coverage zone gets the miss location's cachegroup, limited to the max DNS answers.
+	resp := testQuery(h, "edge.ds0.cdn.example.net.", dns.TypeA, "203.0.113.99")
+	if resp.Rcode != dns.RcodeSuccess || !resp.Authoritative {
+		t.Fatalf("expected: authoritative success, actual: %v", resp)
+	}
+	if len(resp.Answer) != 2 {
+		t.Fatalf("expected: max 2 answers, actual: %v", answerStrs(resp.Answer))
+	}
+	seen := map[string]struct{}{}
+	for _, rr := range resp.Answer {
+		a, ok := rr.(*dns.A)
+		if !ok {
+			t.Fatalf("expected: A records, actual: %v", rr)
+		}
+		if a.Hdr.Ttl != 42 {
+			t.Errorf("expected: delivery service A TTL 42, actual: %v", a.Hdr.Ttl)
+		}
+		if !(&net.IPNet{IP: net.ParseIP("192.0.2.0"), Mask: net.CIDRMask(24, 32)}).Contains(a.A) {
+			t.Errorf("expected: cg-east cache, actual: %v", a.A)
+		}
+		seen[a.A.String()] = struct{}{}
+	}
+
+	// successive queries start with the next cache.
+	next := testQuery(h, "EDGE.ds0.cdn.example.net.", dns.TypeA, "203.0.113.99")
+	if len(next.Answer) != 2 || next.Answer[0].(*dns.A).A.Equal(resp.Answer[0].(*dns.A).A) {
+		t.Errorf("expected: next query to start with the next cache, actual: %v then %v", answerStrs(resp.Answer), answerStrs(next.Answer))
+	}
+
+	// a client in the coverage zone gets its cachegroup.
+	resp = testQuery(h, "edge.ds0.cdn.example.net.", dns.TypeA, "127.0.0.1")
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != "198.51.100.10" {
+		t.Errorf("expected: cg-west cache 198.51.100.10, actual: %v", answerStrs(resp.Answer))
+	}
+
+	// AAAA has no delivery service TTL for the type, so it uses the delivery service TTL.
+	resp = testQuery(h, "edge.ds0.cdn.example.net.", dns.TypeAAAA, "203.0.113.99")
+	if len(resp.Answer) != 2 {
+		t.Fatalf("expected: 2 AAAA answers, actual: %v", answerStrs(resp.Answer))
+	}
+	for _, rr := range resp.Answer {
+		if aaaa, ok := rr.(*dns.AAAA); !ok || aaaa.Hdr.Ttl != 20 {
+			t.Errorf("expected: AAAA with TTL 20, actual: %v", rr)
+		}
+	}
+
+	// the cg-west cache has no IPv6 address.
+	resp = testQuery(h, "edge.ds0.cdn.example.net.", dns.TypeAAAA, "127.0.0.1")
+	if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 0 || len(resp.Ns) != 1 {
+		t.Errorf("expected: NODATA with SOA, actual: %v", resp)
+	}
+
+	resp = testQuery(h, "edge.ds0.cdn.example.net.", dns.TypeMX, "127.0.0.1")
+	if resp.Rcode != dns.RcodeSuccess || len(resp.Answer) != 0 || len(resp.Ns) != 1 {
+		t.Fatalf("expected: NODATA with SOA, actual: %v", resp)
+	}
+	if soa, ok := resp.Ns[0].(*dns.SOA); !ok || soa.Hdr.Ttl != 30 {
+		t.Errorf("expected: SOA with the minimum TTL 30, actual: %v", resp.Ns[0])
+	}
+}
+
+func TestRespondNames(t *testing.T) {
+	h := makeTestHandler(t)
+
+	type testCase struct {
+		name    string
+		qtype   uint16
+		rcode   int
+		answers []string
+		extras  int
+	}
+	testCases := []testCase{
+		{name: "static.ds0.cdn.example.net.", qtype: dns.TypeA, answers: []string{"static.ds0.cdn.example.net.\t300\tIN\tA\t203.0.113.1"}},
+		{name: "alias.ds0.cdn.example.net.", qtype: dns.TypeAAAA, answers: []string{"alias.ds0.cdn.example.net.\t300\tIN\tCNAME\torigin.example.org."}},
+		{name: "ccr.ds1.cdn.example.net.", qtype: dns.TypeA, answers: []string{"ccr.ds1.cdn.example.net.\t120\tIN\tA\t192.0.2.53"}},
+		{name: "ccr.ds1.cdn.example.net.", qtype: dns.TypeAAAA, answers: []string{"ccr.ds1.cdn.example.net.\t120\tIN\tAAAA\t2001:db8::53"}},
+		{name: "cdn.example.net.", qtype: dns.TypeSOA, answers: []string{"cdn.example.net.\t86400\tIN\tSOA\ttr0.example.net. twelve_monkeys.cdn.example.net. 1600000000 28800 7200 604800 30"}},
+		{name: "cdn.example.net.", qtype: dns.TypeNS, answers: []string{"cdn.example.net.\t3600\tIN\tNS\ttr0.example.net."}, extras: 2},
+		{name: "cdn.example.net.", qtype: dns.TypeA},
+		{name: "ds0.cdn.example.net.", qtype: dns.TypeA},
+		{name: "edge.ds1.cdn.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError},
+		{name: "foo.ds0.cdn.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError},
+		{name: "a.b.cdn.example.net.", qtype: dns.TypeA, rcode: dns.RcodeNameError},
+		{name: "cdn.example.net.", qtype: dns.TypeAXFR, rcode: dns.RcodeRefused},
+		{name: "www.example.org.", qtype: dns.TypeA, rcode: dns.RcodeRefused},
+	}
+	for _, tc := range testCases {
+		resp := testQuery(h, tc.name, tc.qtype, "127.0.0.1")
+		if resp.Rcode != tc.rcode {
+			t.Errorf("%v %v expected: rcode %v actual: %v", tc.name, dns.TypeToString[tc.qtype], dns.RcodeToString[tc.rcode], dns.RcodeToString[resp.Rcode])
+			continue
+		}
+		if expected, actual := tc.answers, answerStrs(resp.Answer); len(expected) != len(actual) || (len(expected) > 0 && expected[0] != actual[0]) {
+			t.Errorf("%v %v expected: answers %q actual: %q", tc.name, dns.TypeToString[tc.qtype], expected, actual)
+		}
+		if len(resp.Extra) != tc.extras {
+			t.Errorf("%v %v expected: %v additional records actual: %v", tc.name, dns.TypeToString[tc.qtype], tc.extras, answerStrs(resp.Extra))
+		}
+		if tc.rcode == dns.RcodeRefused {
+			if resp.Authoritative {
+				t.Errorf("%v %v expected: not authoritative, actual: authoritative", tc.name, dns.TypeToString[tc.qtype])
+			}
+			continue
+		}
+		if !resp.Authoritative {
+			t.Errorf("%v %v expected: authoritative, actual: not authoritative", tc.name, dns.TypeToString[tc.qtype])
+		}
+		if len(tc.answers) == 0 && (len(resp.Ns) != 1 || resp.Ns[0].Header().Rrtype != dns.TypeSOA) {
+			t.Errorf("%v %v expected: negative answer with SOA, actual: %v", tc.name, dns.TypeToString[tc.qtype], answerStrs(resp.Ns))
+		}
+	}
+}
+
+func TestServeUDPAndTCP(t *testing.T) {
+	h := makeTestHandler(t)
+
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening on UDP: %v", err)
+	}
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening on TCP: %v", err)
+	}
+	udpSrvr, tcpSrvr := serve(udpConn, tcpListener, h)
+	defer udpSrvr.Shutdown()
+	defer tcpSrvr.Shutdown()
+
+	for network, addr := range map[string]string{"udp": udpConn.LocalAddr().String(), "tcp": tcpListener.Addr().String()} {
+		client := &dns.Client{Net: network, Timeout: 5 * time.Second}
+		req := &dns.Msg{}
+		req.SetQuestion("edge.ds0.cdn.example.net.", dns.TypeA)
+		resp, _, err := client.Exchange(req, addr)
+		if err != nil {
+			t.Fatalf("%v query: %v", network, err)
+		}
+		if !resp.Authoritative || len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != "198.51.100.10" {
+			t.Errorf("%v expected: authoritative cg-west cache 198.51.100.10 for the 127.0.0.1 client, actual: %v", network, resp)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package dnssrvr
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/miekg/dns"
+)
+
+// DefaultDNSRoutingName is the routing name of DNS Delivery Services without one in the CRConfig.
+const DefaultDNSRoutingName = "edge"
+
+// DefaultHTTPRoutingName is the routing name of HTTP Delivery Services without one in the CRConfig.
+const DefaultHTTPRoutingName = "ccr"
+
+const DefaultTTLSeconds = 30
+const DefaultNSTTLSeconds = 3600
+const DefaultSOATTLSeconds = 86400
+
+const DefaultSOAAdmin = "traffic_ops"
+const DefaultSOARefreshSeconds = 28800
+const DefaultSOARetrySeconds = 7200
+const DefaultSOAExpireSeconds = 604800
+const DefaultSOAMinimumSeconds = 30
+
+const MatchSetProtocolDNS = "DNS"
+
+const CRConfigRouterStatusOnline = tc.CRConfigRouterStatus("ONLINE")
+const CRConfigRouterStatusReported = tc.CRConfigRouterStatus("REPORTED")
+
+// zone is the CDN zone, built from a CRConfig. It doesn't change until the CRConfig does.
+type zone struct {
+	crc *tc.CRConfig
+	// domain is the lowercase fully qualified CDN domain, with a trailing dot.
+	domain string
+	soa    *dns.SOA
+	ns     []dns.RR
+	// routerAddrs are the A and AAAA records of the available Traffic Routers, by fully qualified lowercase name.
+	routerAddrs map[string][]dns.RR
+	// routers are the A and AAAA records of all available Traffic Routers, which HTTP Delivery Services resolve to.
+	routers []dns.RR
+	// dsDomains are the Delivery Services, by their fully qualified lowercase domain, which is "xmlid.cdn-domain.".
+	dsDomains map[string]tc.DeliveryServiceName
+	dnsDSes   map[tc.DeliveryServiceName]struct{}
+	// static are the static DNS entries of all Delivery Services, by fully qualified lowercase name.
+	static map[string][]dns.RR
+	ttls   tc.CRConfigTTL
+}
+
+// makeZone builds the CDN zone from the CRConfig.
+func makeZone(crc *tc.CRConfig) (*zone, error) {
+	if crc == nil {
+		return nil, errors.New("CRConfig is nil")
+	}
+	cfg, err := getCRConfigConfig(crc)
+	if err != nil {
+		return nil, errors.New("getting CRConfig config: " + err.Error())
+	}
+	if cfg.DomainName == nil || *cfg.DomainName == "" {
+		return nil, errors.New("CRConfig config has no domain_name")
+	}
+
+	z := &zone{
+		crc:         crc,
+		domain:      strings.ToLower(dns.Fqdn(*cfg.DomainName)),
+		routerAddrs: map[string][]dns.RR{},
+		dsDomains:   map[string]tc.DeliveryServiceName{},
+		dnsDSes:     map[tc.DeliveryServiceName]struct{}{},
+		static:      map[string][]dns.RR{},
+	}
+	if cfg.Ttls != nil {
+		z.ttls = *cfg.Ttls
+	}
+
+	routerNames := []string{}
+	for routerName, _ := range crc.ContentRouters {
+		routerNames = append(routerNames, routerName)
+	}
+	sort.Strings(routerNames)
+
+	nsTTL := ttlSeconds(z.ttls.NSSeconds, DefaultNSTTLSeconds)
+	for _, routerName := range routerNames {
+		router := crc.ContentRouters[routerName]
+		if router.ServerStatus != nil && *router.ServerStatus != CRConfigRouterStatusOnline && *router.ServerStatus != CRConfigRouterStatusReported {
+			continue
+		}
+		if router.FQDN == nil || *router.FQDN == "" {
+			log.Warnln("DNS CRConfig router '" + routerName + "' has no FQDN, skipping")
+			continue
+		}
+		fqdn := strings.ToLower(dns.Fqdn(*router.FQDN))
+		z.ns = append(z.ns, &dns.NS{Hdr: rrHeader(z.domain, dns.TypeNS, nsTTL), Ns: fqdn})
+		for _, ipStr := range []*string{router.IP, router.IP6} {
+			if ipStr == nil || *ipStr == "" {
+				continue
+			}
+			ip := parseServerIP(*ipStr)
+			if ip == nil {
+				log.Warnln("DNS CRConfig router '" + routerName + "' has malformed IP '" + *ipStr + "', skipping")
+				continue
+			}
+			z.routerAddrs[fqdn] = append(z.routerAddrs[fqdn], addrRR(fqdn, ip, nsTTL))
+			z.routers = append(z.routers, addrRR(z.domain, ip, nsTTL))
+		}
+	}
+
+	z.soa = makeSOA(z.domain, cfg.SOA, z.ns, crc.Stats.DateUnixSeconds, ttlSeconds(z.ttls.SOASeconds, DefaultSOATTLSeconds))
+
+	for dsNameStr, ds := range crc.DeliveryServices {
+		dsName := tc.DeliveryServiceName(dsNameStr)
+		for _, matchSet := range ds.MatchSets {
+			if matchSet != nil && strings.ToUpper(matchSet.Protocol) == MatchSetProtocolDNS {
+				z.dnsDSes[dsName] = struct{}{}
+			}
+		}
+		for _, domain := range ds.Domains {
+			z.dsDomains[strings.ToLower(dns.Fqdn(domain))] = dsName
+		}
+		if len(ds.Domains) == 0 {
+			if len(ds.StaticDNSEntries) > 0 {
+				log.Warnln("DNS CRConfig delivery service '" + dsNameStr + "' has static DNS entries but no domains, skipping them")
+			}
+			continue
+		}
+		dsDomain := strings.ToLower(dns.Fqdn(ds.Domains[0]))
+		for _, entry := range ds.StaticDNSEntries {
+			rr, err := makeStaticRR(dsDomain, entry)
+			if err != nil {
+				log.Warnln("DNS CRConfig delivery service '" + dsNameStr + "' static DNS entry '" + entry.Name + "' " + entry.Type + " '" + entry.Value + "': " + err.Error() + ", skipping")
+				continue
+			}
+			z.static[rr.Header().Name] = append(z.static[rr.Header().Name], rr)
+		}
+	}
+	return z, nil
+}
+
+// getCRConfigConfig returns the CRConfig config, which is a loosely typed map in the CRConfig.
+func getCRConfigConfig(crc *tc.CRConfig) (tc.CRConfigConfig, error) {
+	cfg := tc.CRConfigConfig{}
+	bts, err := json.Marshal(crc.Config)
+	if err != nil {
+		return cfg, errors.New("marshalling: " + err.Error())
+	}
+	if err := json.Unmarshal(bts, &cfg); err != nil {
+		return cfg, errors.New("unmarshalling: " + err.Error())
+	}
+	return cfg, nil
+}
+
+// makeSOA returns the SOA record of the CDN zone. The primary name server is the first Traffic Router, and the serial is the CRConfig date, so it changes with every snapshot.
+func makeSOA(domain string, soa *tc.SOA, ns []dns.RR, serial *int64, ttl uint32) *dns.SOA {
+	if soa == nil {
+		soa = &tc.SOA{}
+	}
+	admin := DefaultSOAAdmin
+	if soa.Admin != nil && *soa.Admin != "" {
+		admin = *soa.Admin
+	}
+	if !dns.IsFqdn(admin) {
+		admin += "." + domain
+	}
+	primary := domain
+	if len(ns) > 0 {
+		primary = ns[0].(*dns.NS).Ns
+	}
+	rr := &dns.SOA{
+		Hdr:     rrHeader(domain, dns.TypeSOA, ttl),
+		Ns:      primary,
+		Mbox:    strings.ToLower(admin),
+		Refresh: ttlSeconds(soa.RefreshSeconds, DefaultSOARefreshSeconds),
+		Retry:   ttlSeconds(soa.RetrySeconds, DefaultSOARetrySeconds),
+		Expire:  ttlSeconds(soa.ExpireSeconds, DefaultSOAExpireSeconds),
+		Minttl:  ttlSeconds(soa.MinimumSeconds, DefaultSOAMinimumSeconds),
+	}
+	if serial != nil && *serial > 0 {
+		rr.Serial = uint32(*serial)
+	}
+	return rr
+}
+
+// makeStaticRR returns the record of a Delivery Service static DNS entry, whose name is relative to the Delivery Service domain.
+func makeStaticRR(dsDomain string, entry tc.CRConfigStaticDNSEntry) (dns.RR, error) {
+	name := strings.ToLower(strings.TrimSuffix(entry.Name, "."))
+	if name == "" || name == "@" {
+		name = dsDomain
+	} else {
+		name += "." + dsDomain
+	}
+	val := entry.Value
+	typ := strings.ToUpper(strings.TrimSuffix(entry.Type, "_RECORD"))
+	switch typ {
+	case "A", "AAAA":
+	case "CNAME":
+		val = dns.Fqdn(val)
+	case "TXT":
+		if !strings.HasPrefix(val, `"`) {
+			val = strconv.Quote(val)
+		}
+	default:
+		return nil, errors.New("unsupported type")
+	}
+	if entry.TTL < 0 {
+		return nil, errors.New("negative TTL")
+	}
+	return dns.NewRR(name + " " + strconv.Itoa(entry.TTL) + " IN " + typ + " " + val)
+}
+
+// ttlSeconds returns the number of seconds in the CRConfig string, or the default if it's missing or malformed.
+func ttlSeconds(s *string, def uint32) uint32 {
+	if s == nil || *s == "" {
+		return def
+	}
+	i, err := strconv.ParseUint(*s, 10, 32)
+	if err != nil {
+		log.Warnln("DNS CRConfig has malformed seconds '" + *s + "', using default " + strconv.Itoa(int(def)))
+		return def
+	}
+	return uint32(i)
+}
+
+// parseServerIP parses a CRConfig server or router IP, which may have a prefix length, like IPv6 addresses usually do.
+func parseServerIP(s string) net.IP {
+	if slash := strings.Index(s, "/"); slash >= 0 {
+		s = s[:slash]
+	}
+	return net.ParseIP(strings.TrimSpace(s))
+}
+
+func rrHeader(name string, rrtype uint16, ttl uint32) dns.RR_Header {
+	return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+}
+
+// addrRR returns the A or AAAA record of the IP.
+func addrRR(name string, ip net.IP, ttl uint32) dns.RR {
+	if ip4 := ip.To4(); ip4 != nil {
+		return &dns.A{Hdr: rrHeader(name, dns.TypeA, ttl), A: ip4}
+	}
+	return &dns.AAAA{Hdr: rrHeader(name, dns.TypeAAAA, ttl), AAAA: ip}
+}
@@ -0,0 +1,34 @@
+package geolocation
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// Provider geolocates client IP addresses.
+// Providers are consulted after the Coverage Zone File, for clients it doesn't contain.
+type Provider interface {
+	// Get returns the location of the given IP, and whether the provider knew it.
+	Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool)
+}
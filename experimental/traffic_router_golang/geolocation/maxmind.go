@@ -0,0 +1,144 @@
+package geolocation
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DefaultMaxMindPollInterval is how often the MaxMind database file is checked for changes, if no interval is given.
+const DefaultMaxMindPollInterval = time.Minute
+
+// maxMindCity is the part of a GeoIP2 or GeoLite2 City record needed to locate a client.
+type maxMindCity struct {
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+type maxMind struct {
+	db Ths
+}
+
+// Get returns the location of the given IP in the MaxMind database. Records without a location, such as those only known to the country level in some databases, are not found.
+func (m *maxMind) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
+	db := (*maxminddb.Reader)(m.db.Get())
+	if db == nil || ip == nil {
+		return tc.CRConfigLatitudeLongitude{}, false
+	}
+	city := maxMindCity{}
+	if err := db.Lookup(ip, &city); err != nil {
+		log.Errorln("looking up IP '" + ip.String() + "' in MaxMind database: " + err.Error())
+		return tc.CRConfigLatitudeLongitude{}, false
+	}
+	if city.Location.Latitude == nil || city.Location.Longitude == nil {
+		return tc.CRConfigLatitudeLongitude{}, false
+	}
+	return tc.CRConfigLatitudeLongitude{Lat: *city.Location.Latitude, Lon: *city.Location.Longitude}, true
+}
+
+// NewMaxMind returns a Provider for the given MaxMind DB (mmdb) file bytes, such as the GeoLite2-City database.
+func NewMaxMind(b []byte) (Provider, error) {
+	db, err := maxminddb.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	m := &maxMind{db: NewThs()}
+	m.db.Set(db)
+	return m, nil
+}
+
+// LoadMaxMind returns a Provider for the given MaxMind DB file, which is loaded once.
+func LoadMaxMind(filename string) (Provider, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.New("reading file '" + filename + "': " + err.Error())
+	}
+	p, err := NewMaxMind(b)
+	if err != nil {
+		return nil, errors.New("parsing MaxMind database '" + filename + "': " + err.Error())
+	}
+	return p, nil
+}
+
+// StartMaxMind returns a Provider for the given MaxMind DB file, and starts a goroutine which reloads the file whenever it changes, checking every interval.
+// If the changed file can't be loaded, the error is logged, and the previous database continues to be used.
+// The database is read into memory, rather than mapped, so the file may be safely replaced while it's in use.
+func StartMaxMind(filename string, interval time.Duration) (Provider, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, errors.New("getting file info '" + filename + "': " + err.Error())
+	}
+	p, err := LoadMaxMind(filename)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultMaxMindPollInterval
+	}
+	go pollMaxMind(filename, interval, p.(*maxMind).db, fi)
+	return p, nil
+}
+
+func pollMaxMind(filename string, interval time.Duration, db Ths, prevFI os.FileInfo) {
+	for {
+		time.Sleep(interval)
+		fi, changed, err := reloadMaxMind(filename, db, prevFI)
+		if err != nil {
+			log.Errorln("reloading MaxMind database '" + filename + "', continuing to use the previous database: " + err.Error())
+		} else if changed {
+			log.Infoln("reloaded MaxMind database '" + filename + "'")
+		}
+		prevFI = fi
+	}
+}
+
+// reloadMaxMind loads filename into db if its size or modification time differs from prevFI. It returns the file info to compare against next time, and whether the database was reloaded.
+// If the file can't be loaded, the returned info is that of the failed file, so the same broken file isn't retried until it changes again.
+func reloadMaxMind(filename string, db Ths, prevFI os.FileInfo) (os.FileInfo, bool, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return prevFI, false, errors.New("getting file info: " + err.Error())
+	}
+	if prevFI != nil && fi.Size() == prevFI.Size() && fi.ModTime().Equal(prevFI.ModTime()) {
+		return prevFI, false, nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fi, false, errors.New("reading file: " + err.Error())
+	}
+	newDB, err := maxminddb.FromBytes(b)
+	if err != nil {
+		return fi, false, errors.New("parsing: " + err.Error())
+	}
+	db.Set(newDB)
+	return fi, true, nil
+}
@@ -0,0 +1,265 @@
+package geolocation
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// testMMDBNode is a node of the search tree of a generated test MaxMind DB. Each record is either a child node, or an offset into the data section, or neither.
+type testMMDBNode struct {
+	children [2]*testMMDBNode
+	data     [2]int
+	hasData  [2]bool
+}
+
+// makeTestMMDB generates a small MaxMind DB (mmdb) file, in the same format as GeoLite2-City, with the given data for each CIDR. IPv4 networks are placed in the IPv4-compatible ::/96 subtree, as MaxMind does.
+// Networks must not overlap.
+func makeTestMMDB(t *testing.T, records map[string]map[string]interface{}) []byte {
+	root := &testMMDBNode{}
+	data := []byte{}
+	for cidr, record := range records {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("parsing test CIDR '%v': %v", cidr, err)
+		}
+		ip := ipNet.IP.To16()
+		ones, _ := ipNet.Mask.Size()
+		if v4 := ipNet.IP.To4(); v4 != nil {
+			ip = append(make(net.IP, 12), v4...)
+			ones += 96
+		}
+
+		offset := len(data)
+		data = append(data, encodeTestMMDB(record)...)
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> uint(7-i%8)) & 1
+			if i == ones-1 {
+				node.data[bit] = offset
+				node.hasData[bit] = true
+				break
+			}
+			if node.children[bit] == nil {
+				node.children[bit] = &testMMDBNode{}
+			}
+			node = node.children[bit]
+		}
+	}
+
+	nodes := []*testMMDBNode{root}
+	nums := map[*testMMDBNode]int{root: 0}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if child != nil {
+				nums[child] = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	const recordSize = 24
+	nodeCount := len(nodes)
+	buf := &bytes.Buffer{}
+	for _, node := range nodes {
+		for bit := 0; bit < 2; bit++ {
+			val := nodeCount // no data
+			if node.children[bit] != nil {
+				val = nums[node.children[bit]]
+			} else if node.hasData[bit] {
+				val = nodeCount + 16 + node.data[bit]
+			}
+			buf.Write([]byte{byte(val >> 16), byte(val >> 8), byte(val)})
+		}
+	}
+	buf.Write(make([]byte, 16)) // data section separator
+	buf.Write(data)
+	buf.WriteString("\xAB\xCD\xEFMaxMind.com")
+	buf.Write(encodeTestMMDB(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1539907200),
+		"database_type":               "GeoLite2-City",
+		"description":                 map[string]interface{}{"en": "test"},
+		"ip_version":                  uint16(6),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+	}))
+	return buf.Bytes()
+}
+
+// encodeTestMMDB encodes v in the MaxMind DB data section format. Only the types needed by tests are supported, and strings, maps, and arrays must have fewer than 29 elements.
+func encodeTestMMDB(v interface{}) []byte {
+	ctrl := func(typ int, size int) []byte {
+		if typ > 7 {
+			return []byte{byte(size), byte(typ - 7)} // extended type
+		}
+		return []byte{byte(typ<<5 | size)}
+	}
+	switch v := v.(type) {
+	case string:
+		return append(ctrl(2, len(v)), v...)
+	case float64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		return append(ctrl(3, 8), b...)
+	case uint16:
+		b := make([]byte, 2)
+		binary.BigEndian.PutUint16(b, v)
+		return append(ctrl(5, 2), b...)
+	case uint32:
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, v)
+		return append(ctrl(6, 4), b...)
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return append(ctrl(9, 8), b...)
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b := ctrl(7, len(v))
+		for _, key := range keys {
+			b = append(b, encodeTestMMDB(key)...)
+			b = append(b, encodeTestMMDB(v[key])...)
+		}
+		return b
+	case []interface{}:
+		b := ctrl(11, len(v))
+		for _, elem := range v {
+			b = append(b, encodeTestMMDB(elem)...)
+		}
+		return b
+	}
+	panic("encoding unsupported test MaxMind DB type")
+}
+
+func testCity(lat float64, lon float64) map[string]interface{} {
+	return map[string]interface{}{
+		"city":     map[string]interface{}{"names": map[string]interface{}{"en": "Test City"}},
+		"location": map[string]interface{}{"latitude": lat, "longitude": lon, "time_zone": "America/Denver"},
+	}
+}
+
+func TestMaxMindGet(t *testing.T) {
+	db := makeTestMMDB(t, map[string]map[string]interface{}{
+		"192.0.2.0/24":    testCity(39.5, -104.9),
+		"198.51.100.0/24": map[string]interface{}{"country": map[string]interface{}{"iso_code": "US"}},
+		"2001:db8::/32":   testCity(51.5, -0.1),
+	})
+	p, err := NewMaxMind(db)
+	if err != nil {
+		t.Fatalf("NewMaxMind expected: nil error, actual: %v", err)
+	}
+
+	tests := []struct {
+		ip  string
+		ok  bool
+		lat float64
+		lon float64
+	}{
+		{"192.0.2.1", true, 39.5, -104.9},
+		{"192.0.2.255", true, 39.5, -104.9},
+		{"2001:db8::1", true, 51.5, -0.1},
+		{"198.51.100.1", false, 0, 0}, // no location
+		{"203.0.113.1", false, 0, 0},
+		{"2001:db9::1", false, 0, 0},
+	}
+	for _, test := range tests {
+		pos, ok := p.Get(net.ParseIP(test.ip))
+		if ok != test.ok {
+			t.Errorf("Get(%v) expected: ok %v, actual: %v", test.ip, test.ok, ok)
+			continue
+		}
+		if pos.Lat != test.lat || pos.Lon != test.lon {
+			t.Errorf("Get(%v) expected: %v,%v actual: %v,%v", test.ip, test.lat, test.lon, pos.Lat, pos.Lon)
+		}
+	}
+
+	if _, err := NewMaxMind([]byte("not a database")); err == nil {
+		t.Errorf("NewMaxMind invalid database expected: error, actual: nil")
+	}
+}
+
+func TestMaxMindReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geolocation")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "GeoLite2-City.mmdb")
+
+	ip := net.ParseIP("192.0.2.1")
+	if err := ioutil.WriteFile(filename, makeTestMMDB(t, map[string]map[string]interface{}{"192.0.2.0/24": testCity(1, 2)}), 0644); err != nil {
+		t.Fatalf("writing test database: %v", err)
+	}
+	p, err := StartMaxMind(filename, time.Hour)
+	if err != nil {
+		t.Fatalf("StartMaxMind expected: nil error, actual: %v", err)
+	}
+	if pos, ok := p.Get(ip); !ok || pos.Lat != 1 || pos.Lon != 2 {
+		t.Fatalf("Get expected: 1,2 actual: %v,%v found %v", pos.Lat, pos.Lon, ok)
+	}
+	db := p.(*maxMind).db
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("getting test database info: %v", err)
+	}
+	if _, changed, err := reloadMaxMind(filename, db, fi); err != nil || changed {
+		t.Errorf("reloadMaxMind unchanged file expected: unchanged nil error, actual: changed %v error %v", changed, err)
+	}
+
+	if err := ioutil.WriteFile(filename, makeTestMMDB(t, map[string]map[string]interface{}{"192.0.2.0/25": testCity(3, 4)}), 0644); err != nil {
+		t.Fatalf("writing test database: %v", err)
+	}
+	fi, changed, err := reloadMaxMind(filename, db, fi)
+	if err != nil || !changed {
+		t.Fatalf("reloadMaxMind changed file expected: changed nil error, actual: changed %v error %v", changed, err)
+	}
+	if pos, ok := p.Get(ip); !ok || pos.Lat != 3 || pos.Lon != 4 {
+		t.Errorf("Get after reload expected: 3,4 actual: %v,%v found %v", pos.Lat, pos.Lon, ok)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("writing test database: %v", err)
+	}
+	if _, changed, err := reloadMaxMind(filename, db, fi); err == nil || changed {
+		t.Errorf("reloadMaxMind invalid file expected: unchanged error, actual: changed %v error %v", changed, err)
+	}
+	if pos, ok := p.Get(ip); !ok || pos.Lat != 3 || pos.Lon != 4 {
+		t.Errorf("Get after failed reload expected: previous database 3,4 actual: %v,%v found %v", pos.Lat, pos.Lon, ok)
+	}
+}
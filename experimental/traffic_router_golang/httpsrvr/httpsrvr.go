@@ -29,18 +29,14 @@ import (
 
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/availableservers"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/cgsrch"
-	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/chash"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfigregex"
-	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/nextcache"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/ipmap"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
-// TODO config
-// var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.578968, Lon: -104.934333}
-var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
-
 // TODO config
 const UseXForwardedFor = true
 
@@ -48,8 +44,8 @@ func getHandler(
 	regexes crconfigregex.Ths,
 	availSrvrs availableservers.AvailableServers,
 	cgSrchThs cgsrch.Ths,
-	nextCacherThs nextcache.Ths,
-	cz coveragezone.CoverageZone,
+	ringsThs chash.Ths,
+	locs ipmap.IPMap,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// host := r.Header.Get("Host")
@@ -99,12 +95,20 @@ func getHandler(
 			return
 		}
 
-		pos, ok := cz.Get(ip)
-		if !ok {
-			pos = DefaultPos
-			log.Warnln("request from" + r.RemoteAddr + " IP " + ip.String() + " not found, using default")
+		rings := (*chash.Rings)(ringsThs.Get())
+		if rings == nil {
+			// should never happen
+			fmt.Println("ERROR request '" + r.Host + "' ds '" + string(dsName) + "' has no consistent hash rings, returning 500")
+			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
+			return
+		}
+		ds, _ := rings.DeliveryService(dsName)
+
+		pos, src := locs.Location(ds, ip)
+		if src == ipmap.SourceMissLocation || src == ipmap.SourceDefault {
+			log.Warnln("request from" + r.RemoteAddr + " IP " + ip.String() + " not found, using " + string(src) + " location")
 		}
-		log.Infof("LATLON: Request from"+r.RemoteAddr+" IP "+ip.String()+" got %+v\n", pos)
+		log.Infof("LATLON: Request from"+r.RemoteAddr+" IP "+ip.String()+" got %+v from "+string(src)+"\n", pos)
 
 		cgSrch := cgSrchThs.Get()
 		cgDat, ok := cgSrch.Nearest(pos.Lat, pos.Lon)
@@ -130,17 +134,15 @@ func getHandler(
 			return
 		}
 
-		nextCacher := nextCacherThs.Get()
-		nextSrvrI, ok := nextCacher.NextCache(dsName)
+		// consistent hash the request, so requests for the same content go to the same cache, and stay there when other caches become available or unavailable.
+		srvr, ok := rings.Ring(dsName, cg).GetAvailable(chash.Hash(chash.RequestKey(ds, r.URL.Path, r.URL.Query())), srvrs)
 		if !ok {
 			// should never happen
-			fmt.Println("ERROR request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' not found in Nextcacher, returning 500")
+			fmt.Println("ERROR request '" + r.Host + "' with cg '" + string(cg) + "' ds '" + string(dsName) + "' has no available cache on its consistent hash ring, returning 500")
 			w.WriteHeader(http.StatusInternalServerError) // TODO better code?
 			return
 		}
 
-		srvr := srvrs[nextSrvrI%uint64(len(srvrs))]
-
 		newURL := string(srvr) + "." + subdomain + "." + domain + r.URL.Path
 		if r.URL.RawQuery != "" {
 			newURL += "?" + r.URL.RawQuery
@@ -155,13 +157,13 @@ func Start(
 	regexes crconfigregex.Ths,
 	availableServers availableservers.AvailableServers,
 	cgSrch cgsrch.Ths,
-	nextCacher nextcache.Ths,
-	cz coveragezone.CoverageZone,
+	rings chash.Ths,
+	locs ipmap.IPMap,
 	port uint,
 ) *http.Server {
 	srvr := http.Server{}
 	srvr.Addr = ":" + strconv.Itoa(int(port))
-	srvr.Handler = getHandler(regexes, availableServers, cgSrch, nextCacher, cz)
+	srvr.Handler = getHandler(regexes, availableServers, cgSrch, rings, locs)
 	go func() {
 		err := srvr.ListenAndServe()
 		if err != nil {
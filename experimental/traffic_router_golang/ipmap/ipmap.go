@@ -21,46 +21,77 @@ package ipmap
  */
 
 import (
-	"errors"
 	"net"
 
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/coveragezone"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/geolocation"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 )
 
-// TODO implement, with Coverage Zone File + Maxmind DB
+// TODO config
+// var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.578968, Lon: -104.934333}
+var DefaultPos = tc.CRConfigLatitudeLongitude{Lat: 39.579244, Lon: -104.934282}
 
-var ErrNotFound = errors.New("not found")
+// Source is where a client location came from.
+type Source string
 
-type LatLon struct {
-	Lat float64
-	Lon float64
-}
+const (
+	SourceCoverageZone Source = "coverage zone"
+	SourceGeolocation  Source = "geolocation"
+	SourceMissLocation Source = "delivery service miss location"
+	SourceDefault      Source = "default"
+)
 
-// DummyLocations returns dummy locations for IPs.
-// TODO remove when real geo lookup is implemented
-func DummyLocations() map[string]LatLon {
-	return map[string]LatLon{
-		"127.0.0.1":   {39.579244, -104.934282},
-		"192.168.0.1": {39.579244, -104.934282},
-		"::1":         {39.579244, -104.934282},
-	}
+// IPMap locates clients.
+type IPMap interface {
+	// Get returns the location of the given IP from the Coverage Zone File, or else the geolocation provider, and whether either knew it.
+	Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool)
+	// Location returns the location to route the given client IP from, for the given Delivery Service, and where the location came from.
+	// If neither the Coverage Zone File nor the geolocation provider know the IP, the Delivery Service's CRConfig miss location is used, or else DefaultPos.
+	Location(ds tc.CRConfigDeliveryService, ip net.IP) (tc.CRConfigLatitudeLongitude, Source)
 }
 
-func New() coveragezone.CoverageZone {
-	return &ipmap{}
+// New returns an IPMap which looks up client IPs in the Coverage Zone File first, then the geolocation provider.
+// Either may be nil, in which case it's skipped.
+func New(cz coveragezone.CoverageZone, geo geolocation.Provider) IPMap {
+	return &ipmap{cz: cz, geo: geo}
 }
 
-type ipmap struct{}
+type ipmap struct {
+	cz  coveragezone.CoverageZone
+	geo geolocation.Provider
+}
 
-// Get takes an IP and returns the Latitude and Longitude.
 func (i *ipmap) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
-	// TODO: get IP via req.RemoteAddr => net.SplitHostPort => net.ParseIP
-	locations := DummyLocations()
-	ll, ok := locations[ip.String()]
-	if !ok {
-		return tc.CRConfigLatitudeLongitude{}, false
+	pos, src := i.get(ip)
+	return pos, src != ""
+}
+
+func (i *ipmap) Location(ds tc.CRConfigDeliveryService, ip net.IP) (tc.CRConfigLatitudeLongitude, Source) {
+	if pos, src := i.get(ip); src != "" {
+		return pos, src
+	}
+	if ds.MissLocation != nil {
+		return tc.CRConfigLatitudeLongitude{Lat: ds.MissLocation.Lat, Lon: ds.MissLocation.Lon}, SourceMissLocation
+	}
+	return DefaultPos, SourceDefault
+}
+
+// get returns the location of the given IP, and its source, or an empty source if it wasn't found.
+func (i *ipmap) get(ip net.IP) (tc.CRConfigLatitudeLongitude, Source) {
+	if ip == nil {
+		return tc.CRConfigLatitudeLongitude{}, ""
+	}
+	if i.cz != nil {
+		if pos, ok := i.cz.Get(ip); ok {
+			return pos, SourceCoverageZone
+		}
+	}
+	if i.geo != nil {
+		if pos, ok := i.geo.Get(ip); ok {
+			return pos, SourceGeolocation
+		}
 	}
-	return tc.CRConfigLatitudeLongitude{Lat: ll.Lat, Lon: ll.Lon}, true
+	return tc.CRConfigLatitudeLongitude{}, ""
 }
@@ -0,0 +1,88 @@
+package ipmap
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+import (
+	"net"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/coveragezone"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+type testGeo map[string]tc.CRConfigLatitudeLongitude
+
+func (g testGeo) Get(ip net.IP) (tc.CRConfigLatitudeLongitude, bool) {
+	pos, ok := g[ip.String()]
+	return pos, ok
+}
+
+func TestLocation(t *testing.T) {
+	czPos := tc.CRConfigLatitudeLongitude{Lat: 1, Lon: 1}
+	geoPos := tc.CRConfigLatitudeLongitude{Lat: 2, Lon: 2}
+	missPos := tc.CRConfigLatitudeLongitude{Lat: 3, Lon: 3}
+
+	cz, err := coveragezone.New(coveragezone.JSONCoverageZones{
+		CoverageZones: map[tc.CacheGroupName]coveragezone.JSONCoverageZoneCacheGroup{
+			"cg0": {Coordinates: czPos, Network: []string{"192.0.2.0/24"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("creating coverage zone: %v", err)
+	}
+	geo := testGeo{
+		"192.0.2.1":    geoPos, // also in the coverage zone, which takes precedence
+		"198.51.100.1": geoPos,
+	}
+
+	dsMiss := tc.CRConfigDeliveryService{MissLocation: &tc.CRConfigLatitudeLongitudeShort{Lat: missPos.Lat, Lon: missPos.Lon}}
+	dsNoMiss := tc.CRConfigDeliveryService{}
+
+	tests := []struct {
+		name   string
+		locs   IPMap
+		ds     tc.CRConfigDeliveryService
+		ip     string
+		pos    tc.CRConfigLatitudeLongitude
+		source Source
+	}{
+		{"coverage zone before geolocation", New(cz, geo), dsMiss, "192.0.2.1", czPos, SourceCoverageZone},
+		{"geolocation outside coverage zone", New(cz, geo), dsMiss, "198.51.100.1", geoPos, SourceGeolocation},
+		{"miss location", New(cz, geo), dsMiss, "203.0.113.1", missPos, SourceMissLocation},
+		{"default", New(cz, geo), dsNoMiss, "203.0.113.1", DefaultPos, SourceDefault},
+		{"no coverage zone", New(nil, geo), dsMiss, "192.0.2.1", geoPos, SourceGeolocation},
+		{"no geolocation", New(cz, nil), dsMiss, "198.51.100.1", missPos, SourceMissLocation},
+	}
+	for _, test := range tests {
+		pos, source := test.locs.Location(test.ds, net.ParseIP(test.ip))
+		if pos.Lat != test.pos.Lat || pos.Lon != test.pos.Lon || source != test.source {
+			t.Errorf("%v: Location(%v) expected: %+v from %v, actual: %+v from %v", test.name, test.ip, test.pos, test.source, pos, source)
+		}
+	}
+
+	if _, ok := New(cz, geo).Get(net.ParseIP("203.0.113.1")); ok {
+		t.Errorf("Get unknown IP expected: not found, actual: found")
+	}
+	if pos, ok := New(cz, geo).Get(net.ParseIP("198.51.100.1")); !ok || pos.Lat != geoPos.Lat || pos.Lon != geoPos.Lon {
+		t.Errorf("Get geolocated IP expected: %+v, actual: %+v found %v", geoPos, pos, ok)
+	}
+}
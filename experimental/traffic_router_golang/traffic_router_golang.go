@@ -32,8 +32,11 @@ import (
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/coveragezone"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crconfigpoller"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/crstatespoller"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/dnssrvr"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/fetch"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/geolocation"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/httpsrvr"
+	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/ipmap"
 	"github.com/apache/trafficcontrol/traffic_router/experimental/traffic_router_golang/toutil"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
@@ -69,6 +72,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	geo := geolocation.Provider(nil)
+	if cfg.GeolocationFile != "" {
+		if geo, err = geolocation.StartMaxMind(cfg.GeolocationFile, time.Duration(cfg.GeolocationInterval)); err != nil {
+			fmt.Println("Error loading geolocation file '" + cfg.GeolocationFile + "': " + err.Error())
+			os.Exit(1)
+		}
+	}
+	locs := ipmap.New(cz, geo)
+
 	toURIStr := (*url.URL)(cfg.TrafficOpsURI).String()
 	log.Infof("TO URI Str: " + toURIStr + "\n")
 
@@ -95,7 +107,7 @@ func main() {
 	// crconfigFetcher := fetch.NewFile("./crconfig.json")
 	// crstatesFetcher := fetch.NewFile("./crstates.json")
 
-	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsNextCacher, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval))
+	thsCRConfig, thsCRConfigRegexes, thsCGSearcher, thsNextCacher, thsRings, err := crconfigpoller.Start(crconfigFetcher, time.Duration(cfg.CRConfigInterval))
 	if err != nil {
 		fmt.Println("Could not get initial CRConfig: ", err)
 	}
@@ -105,7 +117,14 @@ func main() {
 		fmt.Println("Could not get initial CRStates from: ", err)
 	}
 
-	httpsrvr.Start(thsCRConfigRegexes, availableServers, thsCGSearcher, thsNextCacher, cz, cfg.Port)
+	httpsrvr.Start(thsCRConfigRegexes, availableServers, thsCGSearcher, thsRings, locs, cfg.Port)
+
+	if cfg.DNSPort != 0 {
+		if _, _, err := dnssrvr.Start(thsCRConfig, thsCRConfigRegexes, availableServers, thsCGSearcher, thsNextCacher, locs, cfg.DNSPort); err != nil {
+			fmt.Println("Error starting DNS server: " + err.Error())
+			os.Exit(1)
+		}
+	}
 
 	// debug
 	for {
@@ -60,8 +60,11 @@ func (s *ServerInfo) IsTopLevelCache() bool {
 		(s.SecondaryParentCacheGroupType == tc.CacheGroupOriginTypeName || s.SecondaryParentCacheGroupID == InvalidID)
 }
 
+// Now returns the time config files are generated at. It may be replaced to generate files as of a fixed time, for reproducible output.
+var Now = time.Now
+
 func HeaderCommentWithTOVersionStr(name string, nameVersionStr string) string {
-	return "# DO NOT EDIT - Generated for " + name + " by " + nameVersionStr + " on " + time.Now().Format(HeaderCommentDateFormat) + "\n"
+	return "# DO NOT EDIT - Generated for " + name + " by " + nameVersionStr + " on " + Now().Format(HeaderCommentDateFormat) + "\n"
 }
 
 func GetNameVersionStringFromToolNameAndURL(toolName string, url string) string {
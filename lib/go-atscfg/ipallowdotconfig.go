@@ -20,7 +20,9 @@ package atscfg
  */
 
 import (
+	"errors"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -31,10 +33,19 @@ import (
 
 const IPAllowConfigFileName = `ip_allow.config`
 
+const IPAllowActionAllow = "ip_allow"
+const IPAllowActionDeny = "ip_deny"
+const IPAllowMethodAll = "ALL"
+
+// IPAllowData is an ip_allow rule: the action applies to requests with any of the methods, from any of the sources.
 type IPAllowData struct {
-	Src    string
-	Action string
-	Method string
+	// Srcs are the client networks of the rule, aggregated into the fewest CIDRs and sorted, IPv4 before IPv6.
+	Srcs []*net.IPNet
+	// RawSrcs are purge_allow_ip values which aren't an IP, CIDR or range, which are written as-is after the Srcs, as they always have been.
+	RawSrcs []string
+	Action  string
+	// Methods are the request methods of the rule. The single method IPAllowMethodAll applies to all methods.
+	Methods []string
 }
 
 const ParamPurgeAllowIP = "purge_allow_ip"
@@ -64,22 +75,32 @@ func MakeIPAllowDotConfig(
 	params map[string][]string, // map[name]value - config file should always be ip_allow.config
 	childServers map[tc.CacheName]IPAllowServer,
 ) string {
-	ipAllowData := []IPAllowData{}
-	const ActionAllow = "ip_allow"
-	const ActionDeny = "ip_deny"
-	const MethodAll = "ALL"
+	text := GenericHeaderComment(string(serverName), toToolName, toURL)
+	for _, al := range makeIPAllowData(serverName, serverType, params, childServers) {
+		for _, src := range al.Srcs {
+			text += `src_ip=` + util.RangeStr(src) + ` action=` + al.Action + ` method=` + strings.Join(al.Methods, "|") + "\n"
+		}
+		for _, src := range al.RawSrcs {
+			text += `src_ip=` + src + ` action=` + al.Action + ` method=` + strings.Join(al.Methods, "|") + "\n"
+		}
+	}
+	return text
+}
 
+// makeIPAllowData returns the ip_allow rules of the server, in order. The first rule matching a client's IP and method applies.
+// All sources allowed all methods are aggregated into a single rule, which is always first.
+func makeIPAllowData(
+	serverName tc.CacheName,
+	serverType tc.CacheType,
+	params map[string][]string,
+	childServers map[tc.CacheName]IPAllowServer,
+) []IPAllowData {
 	// localhost is trusted.
-	ipAllowData = append(ipAllowData, IPAllowData{
-		Src:    `127.0.0.1`,
-		Action: ActionAllow,
-		Method: MethodAll,
-	})
-	ipAllowData = append(ipAllowData, IPAllowData{
-		Src:    `::1`,
-		Action: ActionAllow,
-		Method: MethodAll,
-	})
+	allowed := []*net.IPNet{
+		util.IPToCIDR(net.IPv4(127, 0, 0, 1).To4()),
+		util.IPToCIDR(net.IPv6loopback),
+	}
+	rawAllowed := []string{}
 
 	// default for coalesce_ipv4 = 24, 5 and for ipv6 48, 5; override with the parameters in the server profile.
 	coalesceMaskLenV4 := DefaultCoalesceMaskLenV4
@@ -90,12 +111,14 @@ func MakeIPAllowDotConfig(
 	for name, vals := range params {
 		for _, val := range vals {
 			switch name {
-			case "purge_allow_ip":
-				ipAllowData = append(ipAllowData, IPAllowData{
-					Src:    val,
-					Action: ActionAllow,
-					Method: MethodAll,
-				})
+			case ParamPurgeAllowIP:
+				cidrs, err := parseIPAllowSrc(val)
+				if err != nil {
+					log.Warnln("MakeIPAllowDotConfig got param '" + name + "' val '" + val + "': " + err.Error() + " - using it as-is!")
+					rawAllowed = append(rawAllowed, strings.TrimSpace(val))
+					continue
+				}
+				allowed = append(allowed, cidrs...)
 			case ParamCoalesceMaskLenV4:
 				if vi, err := strconv.Atoi(val); err != nil {
 					log.Warnln("MakeIPAllowDotConfig got param '" + name + "' val '" + val + "' not a number, ignoring!")
@@ -132,122 +155,112 @@ func MakeIPAllowDotConfig(
 		}
 	}
 
+	allV4 := &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, net.IPv4len*util.BitsPerByte)}
+	allV6 := &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, net.IPv6len*util.BitsPerByte)}
+
 	// for edges deny "PUSH|PURGE|DELETE", allow everything else to everyone.
 	isMid := strings.HasPrefix(string(serverType), tc.MidTypePrefix)
 	if !isMid {
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `0.0.0.0-255.255.255.255`,
-			Action: ActionDeny,
-			Method: `PUSH|PURGE|DELETE`,
-		})
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff`,
-			Action: ActionDeny,
-			Method: `PUSH|PURGE|DELETE`,
-		})
-	} else {
-
-		ips := []*net.IPNet{}
-		ip6s := []*net.IPNet{}
-		for serverName, server := range childServers {
-
-			if ip := net.ParseIP(server.IPAddress).To4(); ip != nil {
-				// got an IP - convert it to a CIDR and add it to the list
-				ips = append(ips, util.IPToCIDR(ip))
+		return []IPAllowData{
+			{Srcs: util.AggregateCIDRs(allowed), RawSrcs: rawAllowed, Action: IPAllowActionAllow, Methods: []string{IPAllowMethodAll}},
+			{Srcs: []*net.IPNet{allV4, allV6}, Action: IPAllowActionDeny, Methods: []string{`PUSH`, `PURGE`, `DELETE`}},
+		}
+	}
+
+	// sort the children, so coalescing doesn't depend on map order.
+	childNames := []string{}
+	for serverName := range childServers {
+		childNames = append(childNames, string(serverName))
+	}
+	sort.Strings(childNames)
+
+	ips := []*net.IPNet{}
+	ip6s := []*net.IPNet{}
+	for _, childName := range childNames {
+		serverName := tc.CacheName(childName)
+		server := childServers[serverName]
+
+		if ip := net.ParseIP(server.IPAddress).To4(); ip != nil {
+			// got an IP - convert it to a CIDR and add it to the list
+			ips = append(ips, util.IPToCIDR(ip))
+		} else {
+			// not an IP, try a CIDR
+			if ip, cidr, err := net.ParseCIDR(server.IPAddress); err != nil {
+				// not a CIDR or IP - error out
+				log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP '" + server.IPAddress + " is not an IPv4 address or CIDR - skipping!")
 			} else {
-				// not an IP, try a CIDR
-				if ip, cidr, err := net.ParseCIDR(server.IPAddress); err != nil {
-					// not a CIDR or IP - error out
-					log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP '" + server.IPAddress + " is not an IPv4 address or CIDR - skipping!")
+				// got a valid CIDR - now make sure it's v4
+				ip = ip.To4()
+				if ip == nil {
+					// valid CIDR, but not v4
+					log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP '" + server.IPAddress + " is a CIDR, but not v4 - skipping!")
 				} else {
-					// got a valid CIDR - now make sure it's v4
-					ip = ip.To4()
-					if ip == nil {
-						// valid CIDR, but not v4
-						log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP '" + server.IPAddress + " is a CIDR, but not v4 - skipping!")
-					} else {
-						// got a valid IPv4 CIDR - add it to the list
-						ips = append(ips, cidr)
-					}
+					// got a valid IPv4 CIDR - add it to the list
+					ips = append(ips, cidr)
 				}
 			}
+		}
 
-			if server.IP6Address != "" {
-				ip6 := net.ParseIP(server.IP6Address)
-				if ip6 != nil && ip6.To4() == nil {
-					// got a valid IPv6 - add it to the list
-					ip6s = append(ip6s, util.IPToCIDR(ip6))
+		if server.IP6Address != "" {
+			ip6 := net.ParseIP(server.IP6Address)
+			if ip6 != nil && ip6.To4() == nil {
+				// got a valid IPv6 - add it to the list
+				ip6s = append(ip6s, util.IPToCIDR(ip6))
+			} else {
+				// not a v6 IP, try a CIDR
+				if ip, cidr, err := net.ParseCIDR(server.IP6Address); err != nil {
+					// not a CIDR or IP - error out
+					log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP6 '" + server.IP6Address + " is not an IPv6 address or CIDR - skipping!")
 				} else {
-					// not a v6 IP, try a CIDR
-					if ip, cidr, err := net.ParseCIDR(server.IP6Address); err != nil {
-						// not a CIDR or IP - error out
-						log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP6 '" + server.IP6Address + " is not an IPv6 address or CIDR - skipping!")
+					// got a valid CIDR - now make sure it's v6
+					ip = ip.To4()
+					if ip != nil {
+						// valid CIDR, but not v6
+						log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP6 '" + server.IPAddress + " is a CIDR, but not v6 - skipping!")
 					} else {
-						// got a valid CIDR - now make sure it's v6
-						ip = ip.To4()
-						if ip != nil {
-							// valid CIDR, but not v6
-							log.Errorln("MakeIPAllowDotConfig server '" + string(serverName) + "' IP6 '" + server.IPAddress + " is a CIDR, but not v6 - skipping!")
-						} else {
-							// got a valid IPv6 CIDR - add it to the list
-							ip6s = append(ip6s, cidr)
-						}
+						// got a valid IPv6 CIDR - add it to the list
+						ip6s = append(ip6s, cidr)
 					}
 				}
 			}
 		}
+	}
 
-		cidrs := util.CoalesceCIDRs(ips, coalesceNumberV4, coalesceMaskLenV4)
-		cidr6s := util.CoalesceCIDRs(ip6s, coalesceNumberV6, coalesceMaskLenV6)
+	allowed = append(allowed, util.CoalesceCIDRs(ips, coalesceNumberV4, coalesceMaskLenV4)...)
+	allowed = append(allowed, util.CoalesceCIDRs(ip6s, coalesceNumberV6, coalesceMaskLenV6)...)
 
-		for _, cidr := range cidrs {
-			ipAllowData = append(ipAllowData, IPAllowData{
-				Src:    util.RangeStr(cidr),
-				Action: ActionAllow,
-				Method: MethodAll,
-			})
-		}
-		for _, cidr := range cidr6s {
-			ipAllowData = append(ipAllowData, IPAllowData{
-				Src:    util.RangeStr(cidr),
-				Action: ActionAllow,
-				Method: MethodAll,
-			})
-		}
+	// allow RFC 1918 server space - TODO JvD: parameterize
+	for _, rfc1918 := range []string{`10.0.0.0/8`, `172.16.0.0/12`, `192.168.0.0/16`} {
+		_, cidr, _ := net.ParseCIDR(rfc1918)
+		allowed = append(allowed, cidr)
+	}
 
-		// allow RFC 1918 server space - TODO JvD: parameterize
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `10.0.0.0-10.255.255.255`,
-			Action: ActionAllow,
-			Method: MethodAll,
-		})
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `172.16.0.0-172.31.255.255`,
-			Action: ActionAllow,
-			Method: MethodAll,
-		})
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `192.168.0.0-192.168.255.255`,
-			Action: ActionAllow,
-			Method: MethodAll,
-		})
-
-		// end with a deny
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `0.0.0.0-255.255.255.255`,
-			Action: ActionDeny,
-			Method: MethodAll,
-		})
-		ipAllowData = append(ipAllowData, IPAllowData{
-			Src:    `::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff`,
-			Action: ActionDeny,
-			Method: MethodAll,
-		})
+	// end with a deny
+	return []IPAllowData{
+		{Srcs: util.AggregateCIDRs(allowed), RawSrcs: rawAllowed, Action: IPAllowActionAllow, Methods: []string{IPAllowMethodAll}},
+		{Srcs: []*net.IPNet{allV4, allV6}, Action: IPAllowActionDeny, Methods: []string{IPAllowMethodAll}},
 	}
+}
 
-	text := GenericHeaderComment(string(serverName), toToolName, toURL)
-	for _, al := range ipAllowData {
-		text += `src_ip=` + al.Src + ` action=` + al.Action + ` method=` + al.Method + "\n"
+// parseIPAllowSrc returns the CIDRs of an ip_allow source, which may be an IP, a CIDR, or a hyphenated range of IPs.
+func parseIPAllowSrc(src string) ([]*net.IPNet, error) {
+	src = strings.TrimSpace(src)
+	if ip := net.ParseIP(src); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return []*net.IPNet{util.IPToCIDR(ip)}, nil
 	}
-	return text
+	if _, cidr, err := net.ParseCIDR(src); err == nil {
+		return []*net.IPNet{cidr}, nil
+	}
+	dash := strings.Index(src, "-")
+	if dash < 0 {
+		return nil, errors.New("not an IP, CIDR, or range")
+	}
+	cidrs := util.RangeCIDRs(net.ParseIP(strings.TrimSpace(src[:dash])), net.ParseIP(strings.TrimSpace(src[dash+1:])))
+	if len(cidrs) == 0 {
+		return nil, errors.New("not a valid range of IPs")
+	}
+	return cidrs, nil
 }
@@ -70,18 +70,18 @@ func TestMakeIPAllowDotConfig(t *testing.T) {
 		},
 	}
 
+	// the children and purge IP are in 192.168.0.0/16, so they're aggregated into it.
 	expecteds := []string{
-		"127.0.0.1",
-		"::1",
-		"0.0.0.0-255.255.255.255",
-		"::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
-		"172.16.0.0-172.31.255.255",
-		"10.0.0.0-10.255.255.255",
-		"2001:db8:3::1",
-		"192.168.2.0-192.168.2.255",
-		"192.168.2.99",
-		"2001:db8:1::-2001:db8:1:0:ffff:ffff:ffff:ffff",
-		"2001:db8:2::-2001:db8:2:ffff:ffff:ffff:ffff:ffff",
+		"src_ip=10.0.0.0-10.255.255.255 action=ip_allow method=ALL",
+		"src_ip=127.0.0.1 action=ip_allow method=ALL",
+		"src_ip=172.16.0.0-172.31.255.255 action=ip_allow method=ALL",
+		"src_ip=192.168.0.0-192.168.255.255 action=ip_allow method=ALL",
+		"src_ip=::1 action=ip_allow method=ALL",
+		"src_ip=2001:db8:1::-2001:db8:1:0:ffff:ffff:ffff:ffff action=ip_allow method=ALL",
+		"src_ip=2001:db8:2::-2001:db8:2:ffff:ffff:ffff:ffff:ffff action=ip_allow method=ALL",
+		"src_ip=2001:db8:3::1 action=ip_allow method=ALL",
+		"src_ip=0.0.0.0-255.255.255.255 action=ip_deny method=ALL",
+		"src_ip=::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff action=ip_deny method=ALL",
 	}
 
 	txt := MakeIPAllowDotConfig(serverName, serverType, toToolName, toURL, params, childServers)
@@ -106,10 +106,9 @@ func TestMakeIPAllowDotConfig(t *testing.T) {
 
 	lines = lines[1:] // remove comment line
 
-	for _, expected := range expecteds {
-		if !strings.Contains(txt, expected) {
-			t.Errorf("expected %+v actual '%v'\n", expected, txt)
-		}
+	// rules must be in a deterministic order, for any order of children.
+	if expected, actual := strings.Join(expecteds, "\n")+"\n", strings.Join(lines, "\n"); expected != actual {
+		t.Errorf("expected rules '%v' actual '%v'\n", expected, actual)
 	}
 }
 
@@ -202,3 +201,27 @@ func TestMakeIPAllowDotConfigEdge(t *testing.T) {
 		}
 	}
 }
+
+func TestMakeIPAllowDotConfigUnparsedPurgeAllowIP(t *testing.T) {
+	params := map[string][]string{
+		ParamPurgeAllowIP: []string{"203.0.113.7", "purger.example.net"},
+	}
+
+	for _, serverType := range []tc.CacheType{tc.CacheTypeEdge, tc.CacheTypeMid} {
+		txt := MakeIPAllowDotConfig("server0", serverType, "to0", "trafficops.example.net", params, nil)
+		rawLine := "src_ip=purger.example.net action=ip_allow method=ALL\n"
+		if !strings.Contains(txt, rawLine) {
+			t.Errorf("%v expected: purge_allow_ip which isn't an IP written as-is '%v' actual: '%v'", serverType, rawLine, txt)
+		} else if strings.Index(txt, rawLine) > strings.Index(txt, "action=ip_deny") {
+			t.Errorf("%v expected: purge_allow_ip which isn't an IP allowed before the deny rules, actual: '%v'", serverType, txt)
+		}
+		if !strings.Contains(txt, "src_ip=203.0.113.7 action=ip_allow method=ALL\n") {
+			t.Errorf("%v expected: purge_allow_ip IP allowed, actual: '%v'", serverType, txt)
+		}
+
+		yamlTxt := MakeIPAllowDotYAML("server0", serverType, "to0", "trafficops.example.net", 9, params, nil)
+		if !strings.Contains(yamlTxt, "      - 'purger.example.net'\n") {
+			t.Errorf("%v expected: ip_allow.yaml purge_allow_ip which isn't an IP written as-is, actual: '%v'", serverType, yamlTxt)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const IPAllowYAMLFileName = `ip_allow.yaml`
+
+// IPAllowYAMLMinATSMajorVersion is the first ATS major version with ip_allow.yaml, which replaces ip_allow.config.
+const IPAllowYAMLMinATSMajorVersion = 9
+
+// MakeIPAllowDotYAML creates the ip_allow.yaml ATS config file.
+// It has exactly the rules of MakeIPAllowDotConfig, and takes the same data. Each rule is a single entry, with the aggregated CIDRs of its sources.
+func MakeIPAllowDotYAML(
+	serverName tc.CacheName,
+	serverType tc.CacheType,
+	toToolName string, // tm.toolname global parameter (TODO: cache itself?)
+	toURL string, // tm.url global parameter (TODO: cache itself?)
+	atsMajorVer int,
+	params map[string][]string, // map[name]value - config file should be ip_allow.config or ip_allow.yaml
+	childServers map[tc.CacheName]IPAllowServer,
+) string {
+	if atsMajorVer < IPAllowYAMLMinATSMajorVersion {
+		log.Warnln(IPAllowYAMLFileName + ": server '" + string(serverName) + "' ATS major version " + strconv.Itoa(atsMajorVer) + " doesn't read ip_allow.yaml, which needs version " + strconv.Itoa(IPAllowYAMLMinATSMajorVersion) + "; use " + IPAllowConfigFileName)
+	}
+
+	text := GenericHeaderComment(string(serverName), toToolName, toURL)
+	text += "ip_allow:\n"
+	for _, al := range makeIPAllowData(serverName, serverType, params, childServers) {
+		if len(al.Srcs) == 0 && len(al.RawSrcs) == 0 {
+			continue
+		}
+		text += "  - apply: in\n"
+		text += "    ip_addrs:\n"
+		for _, src := range al.Srcs {
+			text += "      - '" + src.String() + "'\n"
+		}
+		for _, src := range al.RawSrcs {
+			text += "      - '" + strings.Replace(src, "'", "''", -1) + "'\n"
+		}
+		action := "allow"
+		if al.Action == IPAllowActionDeny {
+			action = "deny"
+		}
+		text += "    action: " + action + "\n"
+		if len(al.Methods) == 1 {
+			text += "    methods: " + al.Methods[0] + "\n"
+			continue
+		}
+		text += "    methods:\n"
+		for _, method := range al.Methods {
+			text += "      - " + method + "\n"
+		}
+	}
+	return text
+}
@@ -0,0 +1,244 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	"gopkg.in/yaml.v2"
+)
+
+// testIPAllowRule is an ip_allow rule parsed from a generated ip_allow.config or ip_allow.yaml, for comparing the two.
+type testIPAllowRule struct {
+	Action  string
+	Methods string
+	CIDRs   []*net.IPNet
+}
+
+func (r testIPAllowRule) String() string {
+	cidrs := []string{}
+	for _, cidr := range r.CIDRs {
+		cidrs = append(cidrs, cidr.String())
+	}
+	return r.Action + " " + r.Methods + " " + strings.Join(cidrs, ",")
+}
+
+// testIPAllowRulesAllow returns whether the rules allow the method from the ip. The first rule containing the ip applies.
+func testIPAllowRulesAllow(rules []testIPAllowRule, ip net.IP, method string) bool {
+	for _, rule := range rules {
+		contains := false
+		for _, cidr := range rule.CIDRs {
+			if cidr.Contains(ip) {
+				contains = true
+				break
+			}
+		}
+		if !contains {
+			continue
+		}
+		matches := rule.Methods == IPAllowMethodAll
+		for _, ruleMethod := range strings.Split(rule.Methods, "|") {
+			matches = matches || ruleMethod == method
+		}
+		return matches == (rule.Action == "allow")
+	}
+	return true
+}
+
+// parseTestIPAllowDotConfig parses an ip_allow.config into rules, combining consecutive lines with the same action and method.
+func parseTestIPAllowDotConfig(t *testing.T, txt string) []testIPAllowRule {
+	rules := []testIPAllowRule{}
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := map[string]string{}
+		for _, field := range strings.Fields(line) {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				t.Fatalf("ip_allow.config line '%v' field '%v' isn't key=value", line, field)
+			}
+			fields[kv[0]] = kv[1]
+		}
+		cidrs, err := parseIPAllowSrc(fields["src_ip"])
+		if err != nil {
+			t.Fatalf("ip_allow.config line '%v' src_ip: %v", line, err)
+		}
+		action := map[string]string{IPAllowActionAllow: "allow", IPAllowActionDeny: "deny"}[fields["action"]]
+		if action == "" {
+			t.Fatalf("ip_allow.config line '%v' has unknown action", line)
+		}
+		if len(rules) > 0 && rules[len(rules)-1].Action == action && rules[len(rules)-1].Methods == fields["method"] {
+			rules[len(rules)-1].CIDRs = append(rules[len(rules)-1].CIDRs, cidrs...)
+			continue
+		}
+		rules = append(rules, testIPAllowRule{Action: action, Methods: fields["method"], CIDRs: cidrs})
+	}
+	for i := range rules {
+		rules[i].CIDRs = util.AggregateCIDRs(rules[i].CIDRs)
+	}
+	return rules
+}
+
+// parseTestIPAllowDotYAML parses an ip_allow.yaml into rules.
+func parseTestIPAllowDotYAML(t *testing.T, txt string) []testIPAllowRule {
+	ipAllow := struct {
+		IPAllow []struct {
+			Apply   string      `yaml:"apply"`
+			IPAddrs []string    `yaml:"ip_addrs"`
+			Action  string      `yaml:"action"`
+			Methods interface{} `yaml:"methods"`
+		} `yaml:"ip_allow"`
+	}{}
+	if err := yaml.UnmarshalStrict([]byte(txt), &ipAllow); err != nil {
+		t.Fatalf("ip_allow.yaml isn't valid YAML: %v", err)
+	}
+
+	rules := []testIPAllowRule{}
+	for _, entry := range ipAllow.IPAllow {
+		if entry.Apply != "in" {
+			t.Errorf("ip_allow.yaml expected: apply 'in' actual: '%v'", entry.Apply)
+		}
+		rule := testIPAllowRule{Action: entry.Action}
+		switch methods := entry.Methods.(type) {
+		case string:
+			rule.Methods = methods
+		case []interface{}:
+			methodStrs := []string{}
+			for _, method := range methods {
+				methodStrs = append(methodStrs, method.(string))
+			}
+			rule.Methods = strings.Join(methodStrs, "|")
+		default:
+			t.Fatalf("ip_allow.yaml rule methods expected: string or list, actual: %T", entry.Methods)
+		}
+		for _, addr := range entry.IPAddrs {
+			_, cidr, err := net.ParseCIDR(addr)
+			if err != nil {
+				t.Fatalf("ip_allow.yaml ip_addrs '%v' isn't a CIDR: %v", addr, err)
+			}
+			rule.CIDRs = append(rule.CIDRs, cidr)
+		}
+		aggregated := util.AggregateCIDRs(rule.CIDRs)
+		if len(aggregated) != len(rule.CIDRs) {
+			t.Errorf("ip_allow.yaml expected: aggregated ip_addrs %v actual: %v", aggregated, entry.IPAddrs)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestMakeIPAllowDotYAMLMatchesConfig(t *testing.T) {
+	params := map[string][]string{
+		"purge_allow_ip":       []string{"203.0.113.7", "203.0.113.8-203.0.113.15", "2001:db8:ffff::/64"},
+		ParamCoalesceMaskLenV4: []string{"24"},
+		ParamCoalesceNumberV4:  []string{"3"},
+		ParamCoalesceMaskLenV6: []string{"48"},
+		ParamCoalesceNumberV6:  []string{"4"},
+	}
+	childServers := map[tc.CacheName]IPAllowServer{
+		"child0": IPAllowServer{IPAddress: "198.51.100.1", IP6Address: "2001:DB8:1::1/64"},
+		"child1": IPAllowServer{IPAddress: "198.51.100.2", IP6Address: "2001:DB8:2::1/64"},
+		"child2": IPAllowServer{IPAddress: "198.51.100.3"},
+		"child3": IPAllowServer{IPAddress: "198.51.101.9/31", IP6Address: "2001:DB8:3::1"},
+		"child4": IPAllowServer{IPAddress: "10.1.2.3", IP6Address: "2001:DB8:2::2/64"},
+	}
+
+	for _, serverType := range []tc.CacheType{tc.CacheTypeEdge, tc.CacheTypeMid} {
+		configTxt := MakeIPAllowDotConfig("server0", serverType, "to0", "trafficops.example.net", params, childServers)
+		yamlTxt := MakeIPAllowDotYAML("server0", serverType, "to0", "trafficops.example.net", 9, params, childServers)
+		testComment(t, yamlTxt, "server0", "to0", "trafficops.example.net")
+
+		configRules := parseTestIPAllowDotConfig(t, configTxt)
+		yamlRules := parseTestIPAllowDotYAML(t, yamlTxt)
+		if len(configRules) != len(yamlRules) {
+			t.Fatalf("%v expected: ip_allow.yaml rules %v actual: %v", serverType, configRules, yamlRules)
+		}
+		for i := range configRules {
+			if configRules[i].String() != yamlRules[i].String() {
+				t.Errorf("%v rule %v expected: ip_allow.yaml '%v' actual: '%v'", serverType, i, configRules[i], yamlRules[i])
+			}
+		}
+
+		isMid := serverType == tc.CacheTypeMid
+		ipAllows := []struct {
+			IP      string
+			Method  string
+			Allowed bool
+		}{
+			{"127.0.0.1", "PURGE", true},
+			{"::1", "PURGE", true},
+			{"203.0.113.6", "PURGE", false},
+			{"203.0.113.7", "PURGE", true},
+			{"203.0.113.15", "PURGE", true},
+			{"203.0.113.16", "PURGE", false},
+			{"2001:db8:ffff::ffff", "PURGE", true},
+			{"2001:db8:ffff:1::", "PURGE", false},
+			{"203.0.113.16", "GET", !isMid},
+			{"198.51.100.0", "GET", true},
+			{"198.51.100.255", "PURGE", isMid},
+			{"198.51.101.9", "PURGE", isMid},
+			{"198.51.101.10", "GET", !isMid},
+			{"2001:db8:2::ffff", "GET", true},
+			{"2001:db8:2:ffff::1", "PURGE", false},
+			{"2001:db8:3::1", "PURGE", isMid},
+			{"2001:db8:3::2", "GET", !isMid},
+			{"10.255.255.255", "DELETE", isMid},
+			{"11.0.0.0", "GET", !isMid},
+		}
+		for _, ipAllow := range ipAllows {
+			ip := net.ParseIP(ipAllow.IP)
+			if actual := testIPAllowRulesAllow(configRules, ip, ipAllow.Method); actual != ipAllow.Allowed {
+				t.Errorf("%v ip_allow.config %v %v expected: allowed %v actual: %v", serverType, ipAllow.IP, ipAllow.Method, ipAllow.Allowed, actual)
+			}
+			if actual := testIPAllowRulesAllow(yamlRules, ip, ipAllow.Method); actual != ipAllow.Allowed {
+				t.Errorf("%v ip_allow.yaml %v %v expected: allowed %v actual: %v", serverType, ipAllow.IP, ipAllow.Method, ipAllow.Allowed, actual)
+			}
+		}
+	}
+}
+
+func TestMakeIPAllowDotYAMLDeterministic(t *testing.T) {
+	childServers := map[tc.CacheName]IPAllowServer{}
+	for i := 0; i < 64; i++ {
+		ip := net.IPv4(198, 51, 100, byte(i*4)).String()
+		childServers[tc.CacheName("child"+ip)] = IPAllowServer{IPAddress: ip, IP6Address: "2001:db8:" + strings.TrimPrefix(ip, "198.51.100.") + "::1"}
+	}
+
+	expected := ""
+	for i := 0; i < 10; i++ {
+		txt := MakeIPAllowDotYAML("server0", tc.CacheTypeMid, "to0", "trafficops.example.net", 9, nil, childServers)
+		txt = txt[strings.Index(txt, "\n")+1:] // remove the comment, which has a timestamp
+		if i == 0 {
+			expected = txt
+		} else if txt != expected {
+			t.Fatalf("expected: identical ip_allow.yaml for the same servers, actual: '%v' and '%v'", expected, txt)
+		}
+	}
+	if !strings.Contains(expected, "      - '198.51.100.0/24'\n") {
+		t.Errorf("expected: children coalesced into 198.51.100.0/24, actual: '%v'", expected)
+	}
+}
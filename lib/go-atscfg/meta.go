@@ -135,11 +135,13 @@ const DefaultScope = tc.ATSConfigMetaDataConfigFileScopeServers
 func getScope(cfgFile string, scopeParams map[string]string) tc.ATSConfigMetaDataConfigFileScope {
 	switch {
 	case cfgFile == "ip_allow.config",
+		cfgFile == IPAllowYAMLFileName,
 		cfgFile == "parent.config",
 		cfgFile == "hosting.config",
 		cfgFile == "packages",
 		cfgFile == "chkconfig",
 		cfgFile == "remap.config",
+		cfgFile == NginxConfigFileName,
 		strings.HasPrefix(cfgFile, "to_ext_") && strings.HasSuffix(cfgFile, ".config"):
 		return tc.ATSConfigMetaDataConfigFileScopeServers
 	case cfgFile == "12M_facts",
@@ -0,0 +1,576 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+// NginxConfigFileName is the nginx config of a cache server, which must be included in the nginx http block, e.g. by putting it in /etc/nginx/conf.d.
+const NginxConfigFileName = "nginx_tc.conf"
+
+// NginxServerTypeSuffix is the suffix of the types of nginx cache servers, e.g. EDGE_NGINX and MID_NGINX.
+// Because they start with EDGE and MID, the rest of Traffic Control treats them as edge and mid caches.
+const NginxServerTypeSuffix = "_NGINX"
+
+// The names of the server profile parameters with the config file NginxConfigFileName.
+const NginxParamCachePath = "proxy_cache_path"
+const NginxParamKeysZoneSize = "keys_zone_size"
+const NginxParamMaxSize = "max_size"
+const NginxParamInactive = "inactive"
+const NginxParamSSLDir = "ssl_dir"
+const NginxParamKeepalive = "keepalive"
+
+const DefaultNginxCachePath = "/var/cache/nginx/trafficcontrol"
+const DefaultNginxKeysZoneSize = "256m"
+const DefaultNginxInactive = "7d"
+const DefaultNginxSSLDir = "/etc/nginx/ssl"
+const DefaultNginxKeepalive = "32"
+
+// NginxCacheZone is the name of the proxy_cache zone of all delivery services.
+const NginxCacheZone = "trafficcontrol"
+
+// nginxMethods are the methods nginx limit_except accepts. Methods nginx doesn't know, like PURGE, are limited by every limit_except.
+var nginxMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "MKCOL", "COPY", "MOVE", "OPTIONS", "PROPFIND", "PROPPATCH", "LOCK", "UNLOCK", "PATCH"}
+
+// nginxNextUpstreamCodes are the response codes nginx proxy_next_upstream can retry on.
+var nginxNextUpstreamCodes = map[int]struct{}{403: {}, 404: {}, 429: {}, 500: {}, 502: {}, 503: {}, 504: {}}
+
+// IsNginxServerType returns whether the given server type is an nginx cache.
+func IsNginxServerType(serverType string) bool {
+	return strings.HasSuffix(strings.ToUpper(serverType), NginxServerTypeSuffix)
+}
+
+// MakeNginxDotConf returns the nginx config of an nginx cache server, equivalent to the remap.config, parent.config, header rewrite, and ip_allow.config files of a Traffic Server cache.
+// It takes the same data as those files' generators: remapDSData as MakeRemapDotConfig, parentConfigDSes, parentServerParams, and parentInfos as MakeParentDotConfig, and ipAllowParams and childServers as MakeIPAllowDotConfig.
+// The sslDSes are used to find the certificate files of HTTPS delivery services, with the same names as ssl_multicert.config; the files must be installed in the ssl_dir.
+// The serverParams are the server profile parameters with the config file NginxConfigFileName.
+//
+// Each delivery service has a server block, which proxies to upstreams of its parents with the same next-hop selection as strategies.yaml, failing over to each next parent ring, or directly to the origin if it has no parents.
+// Header rewrite set-header, add-header, and rm-header operators are translated to nginx header directives; other header rewrite rules, and ANY_MAP remap text, can't be translated, and are logged and skipped.
+func MakeNginxDotConf(
+	serverInfo *ServerInfo,
+	toToolName string, // tm.toolname global parameter (TODO: cache itself?)
+	toURL string, // tm.url global parameter (TODO: cache itself?)
+	serverParams map[string]string,
+	remapDSData []RemapConfigDSData,
+	sslDSes map[tc.DeliveryServiceName]SSLMultiCertDS,
+	parentConfigDSes []ParentConfigDSTopLevel,
+	parentServerParams map[string]string,
+	parentInfos map[OriginHost][]ParentInfo,
+	ipAllowParams map[string][]string,
+	childServers map[tc.CacheName]IPAllowServer,
+) string {
+	text := GenericHeaderComment(serverInfo.HostName, toToolName, toURL)
+	text += "# Include in the nginx http block.\n\n"
+
+	if !IsNginxServerType(serverInfo.Type) {
+		log.Warnln(NginxConfigFileName + ": server '" + serverInfo.HostName + "' type '" + serverInfo.Type + "' isn't an nginx type ending in " + NginxServerTypeSuffix)
+	}
+
+	text += makeNginxCachePath(serverParams)
+
+	strategies, dsStrategies := makeStrategies(serverInfo, parentConfigDSes, parentServerParams, parentInfos)
+	strategiesByName := map[string]strategy{}
+	keepalive := nginxParam(serverParams, NginxParamKeepalive, DefaultNginxKeepalive)
+	for _, st := range strategies {
+		strategiesByName[st.Name] = st
+		text += makeNginxUpstreams(st, keepalive)
+	}
+
+	serverType := tc.CacheTypeEdge
+	if tc.CacheTypeFromString(serverInfo.Type) == tc.CacheTypeMid {
+		serverType = tc.CacheTypeMid
+	}
+	accessRules, limitExcept := makeNginxAccessRules(makeIPAllowData(tc.CacheName(serverInfo.HostName), tc.CacheType(serverInfo.Type), ipAllowParams, childServers))
+
+	dses := make([]RemapConfigDSData, len(remapDSData))
+	copy(dses, remapDSData)
+	sort.Slice(dses, func(i, j int) bool { return dses[i].Name < dses[j].Name })
+
+	sslDir := nginxParam(serverParams, NginxParamSSLDir, DefaultNginxSSLDir)
+	seenOrigins := map[string]struct{}{}
+	for _, ds := range dses {
+		block := nginxServerBlock{AccessRules: accessRules, LimitExcept: limitExcept}
+		if serverType == tc.CacheTypeMid {
+			if !makeNginxMidServerBlock(&block, ds, serverInfo, seenOrigins) {
+				continue
+			}
+		} else {
+			if !makeNginxEdgeServerBlock(&block, ds, serverInfo, sslDSes, sslDir) {
+				continue
+			}
+		}
+		if !makeNginxProxy(&block, ds, serverType, strategiesByName[dsStrategies[tc.DeliveryServiceName(ds.Name)]]) {
+			continue
+		}
+		text += "\n" + block.format()
+	}
+	return text
+}
+
+// nginxServerBlock is the nginx server block of a delivery service.
+type nginxServerBlock struct {
+	Comment     string
+	Listens     []string
+	ServerNames []string
+	// Directives are the server-level directives, which are inherited by every location.
+	Directives  []string
+	AccessRules []string
+	LimitExcept string
+	// Locations are the proxy_pass of the location /, followed by the named fallback locations it fails over to, in order.
+	Locations []nginxLocation
+}
+
+type nginxLocation struct {
+	Name      string
+	ProxyPass string
+}
+
+func (b nginxServerBlock) format() string {
+	txt := "# " + b.Comment + "\n"
+	txt += "server {\n"
+	for _, listen := range b.Listens {
+		txt += "\tlisten " + listen + ";\n"
+	}
+	txt += "\tserver_name " + strings.Join(b.ServerNames, " ") + ";\n"
+	for _, directive := range b.Directives {
+		txt += "\t" + directive + "\n"
+	}
+	for _, rule := range b.AccessRules {
+		txt += "\t" + rule + "\n"
+	}
+	if len(b.Locations) > 1 {
+		txt += "\trecursive_error_pages on;\n"
+	}
+	for i, loc := range b.Locations {
+		txt += "\n\tlocation " + loc.Name + " {\n"
+		if i == 0 && b.LimitExcept != "" {
+			txt += "\t\t" + b.LimitExcept + "\n"
+		}
+		if i < len(b.Locations)-1 {
+			txt += "\t\terror_page 502 504 = " + b.Locations[i+1].Name + ";\n"
+		}
+		txt += "\t\tproxy_pass " + loc.ProxyPass + ";\n"
+		txt += "\t}\n"
+	}
+	txt += "}\n"
+	return txt
+}
+
+func nginxParam(params map[string]string, name string, defaultVal string) string {
+	if val := strings.TrimSpace(params[name]); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+func makeNginxCachePath(serverParams map[string]string) string {
+	txt := "proxy_cache_path " + nginxParam(serverParams, NginxParamCachePath, DefaultNginxCachePath) +
+		" levels=1:2 keys_zone=" + NginxCacheZone + ":" + nginxParam(serverParams, NginxParamKeysZoneSize, DefaultNginxKeysZoneSize) +
+		" inactive=" + nginxParam(serverParams, NginxParamInactive, DefaultNginxInactive)
+	if maxSize := nginxParam(serverParams, NginxParamMaxSize, ""); maxSize != "" {
+		txt += " max_size=" + maxSize
+	}
+	return txt + " use_temp_path=off;\n"
+}
+
+// nginxUpstreamName returns the name of the upstream of the given parent ring of a strategy. The first ring's upstream has the strategy's name.
+func nginxUpstreamName(st strategy, ring int) string {
+	if ring == 0 {
+		return st.Name
+	}
+	return st.Name + "-ring" + strconv.Itoa(ring+1)
+}
+
+// makeNginxUpstreams returns an upstream for each parent ring of the strategy.
+// Nginx doesn't allow backup servers with hashing, so each ring is its own upstream, which the server block fails over to.
+func makeNginxUpstreams(st strategy, keepalive string) string {
+	txt := ""
+	for ring, parents := range st.Groups {
+		txt += "\nupstream " + nginxUpstreamName(st, ring) + " {\n"
+		switch st.Policy {
+		case StrategyPolicyConsistentHash:
+			if st.HashKey == StrategyHashKeyPathQuery {
+				txt += "\thash $request_uri consistent;\n"
+			} else {
+				txt += "\thash $uri consistent;\n"
+			}
+		case StrategyPolicyRRIP:
+			txt += "\tip_hash;\n"
+		}
+		for i, parent := range parents {
+			txt += "\tserver " + net.JoinHostPort(parent.hostName(), strconv.Itoa(parent.Port))
+			if st.Policy == StrategyPolicyFirstLive || st.Policy == StrategyPolicyLatched {
+				if i > 0 {
+					txt += " backup" // the first live parent is used
+				}
+			} else {
+				txt += " weight=" + strconv.Itoa(nginxWeight(parent.Weight))
+			}
+			txt += ";\n"
+		}
+		if keepalive != "0" {
+			txt += "\tkeepalive " + keepalive + ";\n"
+		}
+		txt += "}\n"
+	}
+	return txt
+}
+
+// nginxWeight returns the nginx server weight of a parent.config weight, which is typically a fraction like 0.999.
+func nginxWeight(weight string) int {
+	w, err := strconv.ParseFloat(weight, 64)
+	if err != nil || w <= 0 {
+		return 1
+	}
+	if w <= 1 {
+		w *= 1000
+	}
+	if w < 1 {
+		return 1
+	}
+	return int(math.Round(w))
+}
+
+// makeNginxEdgeServerBlock sets the listens and server names of an edge delivery service from its remap.config lines, and returns false if it has none.
+func makeNginxEdgeServerBlock(block *nginxServerBlock, ds RemapConfigDSData, serverInfo *ServerInfo, sslDSes map[tc.DeliveryServiceName]SSLMultiCertDS, sslDir string) bool {
+	if ds.Type == tc.DSTypeAnyMap {
+		log.Warnln(NginxConfigFileName + ": delivery service '" + ds.Name + "' is ANY_MAP, whose raw remap text can't be translated to nginx - skipping")
+		return false
+	}
+	remapLines, err := MakeEdgeDSDataRemapLines(ds, serverInfo)
+	if err != nil {
+		log.Errorln(NginxConfigFileName + ": making remap lines for delivery service '" + ds.Name + "' - skipping! : " + err.Error())
+		return false
+	}
+	if len(remapLines) == 0 {
+		return false
+	}
+
+	block.Comment = "delivery service " + ds.Name
+	listens := map[string]struct{}{}
+	serverNames := map[string]struct{}{}
+	hasHTTPS := false
+	for _, line := range remapLines {
+		scheme, host, port := splitNginxRemapFrom(strings.Replace(line.From, `__http__`, serverInfo.HostName, -1))
+		if scheme == "https" {
+			hasHTTPS = true
+			if port == "" {
+				port = "443"
+			}
+			listens[port+" ssl"] = struct{}{}
+		} else {
+			if port == "" {
+				port = "80"
+			}
+			listens[port] = struct{}{}
+		}
+		serverNames[nginxServerName(host)] = struct{}{}
+	}
+	block.Listens = sortedKeys(listens)
+	block.ServerNames = sortedKeys(serverNames)
+
+	if hasHTTPS {
+		sslDS, ok := sslDSes[tc.DeliveryServiceName(ds.Name)]
+		if !ok || len(sslDS.ExampleURLs) == 0 {
+			log.Errorln(NginxConfigFileName + ": delivery service '" + ds.Name + "' is HTTPS, but has no example URLs to name its certificate - skipping!")
+			return false
+		}
+		cerName, keyName := sslMultiCertFileNames(tc.DeliveryServiceName(ds.Name), sslDS.ExampleURLs)
+		block.Directives = append(block.Directives,
+			"ssl_certificate "+strings.TrimSuffix(sslDir, "/")+"/"+cerName+";",
+			"ssl_certificate_key "+strings.TrimSuffix(sslDir, "/")+"/"+keyName+";",
+		)
+	}
+	return true
+}
+
+// makeNginxMidServerBlock sets the listens and server names of a mid delivery service, which are its origin, and returns false if the mid doesn't serve it.
+// As in remap.config, delivery services which share an origin are served by the first.
+func makeNginxMidServerBlock(block *nginxServerBlock, ds RemapConfigDSData, serverInfo *ServerInfo, seenOrigins map[string]struct{}) bool {
+	if ds.Type.IsLive() && !ds.Type.IsNational() {
+		return false // Live local delivery services skip mids
+	}
+	if ds.OriginFQDN == nil || *ds.OriginFQDN == "" {
+		log.Warnln(NginxConfigFileName + ": delivery service '" + ds.Name + "' has no origin fqdn, skipping!")
+		return false
+	}
+	if _, ok := seenOrigins[*ds.OriginFQDN]; ok {
+		return false
+	}
+	seenOrigins[*ds.OriginFQDN] = struct{}{}
+
+	org, err := url.Parse(*ds.OriginFQDN)
+	if err != nil || org.Hostname() == "" {
+		log.Errorln(NginxConfigFileName + ": delivery service '" + ds.Name + "' origin '" + *ds.OriginFQDN + "' isn't a URL - skipping!")
+		return false
+	}
+
+	port := serverInfo.Port
+	if port <= 0 {
+		port = 80
+	}
+	block.Comment = "delivery service " + ds.Name + " origin " + *ds.OriginFQDN
+	block.Listens = []string{strconv.Itoa(port)}
+	block.ServerNames = []string{org.Hostname()}
+	return true
+}
+
+// splitNginxRemapFrom returns the scheme, host, and port of a remap.config from URL, whose host may be a regex, which url.Parse can't parse.
+func splitNginxRemapFrom(from string) (string, string, string) {
+	scheme := "http"
+	if strings.HasPrefix(from, "https://") {
+		scheme = "https"
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(from, "http://"), "https://")
+	if slash := strings.Index(host, "/"); slash >= 0 {
+		host = host[:slash]
+	}
+	port := ""
+	if colon := strings.LastIndex(host, ":"); colon >= 0 && !strings.Contains(host[colon:], "]") {
+		if _, err := strconv.Atoi(host[colon+1:]); err == nil {
+			host, port = host[:colon], host[colon+1:]
+		}
+	}
+	return scheme, host, port
+}
+
+// nginxServerName returns the nginx server_name of a remap.config host, which is a regex if it has any regex characters other than escaped dots.
+func nginxServerName(host string) string {
+	name := strings.Replace(host, `\.`, `.`, -1)
+	if !strings.ContainsAny(name, `\*+?[]()^$|`) {
+		return name
+	}
+	return "~^" + host + "$"
+}
+
+// makeNginxProxy sets the cache, header, and proxy directives of the delivery service, proxying to the upstreams of its strategy, or to its origin if it has none (st.Name is empty).
+// Returns false if the delivery service can't be proxied.
+func makeNginxProxy(block *nginxServerBlock, ds RemapConfigDSData, serverType tc.CacheType, st strategy) bool {
+	if ds.OriginFQDN == nil || *ds.OriginFQDN == "" {
+		log.Warnln(NginxConfigFileName + ": delivery service '" + ds.Name + "' has no origin fqdn, skipping!")
+		return false
+	}
+	org, err := url.Parse(strings.TrimSuffix(*ds.OriginFQDN, "/"))
+	if err != nil || org.Host == "" {
+		log.Errorln(NginxConfigFileName + ": delivery service '" + ds.Name + "' origin '" + *ds.OriginFQDN + "' isn't a URL - skipping!")
+		return false
+	}
+
+	// Like Traffic Server, the cache key is the origin URL, so it's the same for every parent ring.
+	// Dropping the query string at the edge proxies only the path, and ignoring it caches on the path but still passes it up.
+	uriVar := ""
+	cacheKey := org.Scheme + "://" + org.Host + "$request_uri"
+	if serverType == tc.CacheTypeEdge && ds.QStringIgnore != nil {
+		switch *ds.QStringIgnore {
+		case tc.QueryStringIgnoreIgnoreInCacheKeyAndPassUp:
+			cacheKey = org.Scheme + "://" + org.Host + "$uri"
+		case tc.QueryStringIgnoreDropAtEdge:
+			cacheKey = org.Scheme + "://" + org.Host + "$uri"
+			uriVar = "$uri"
+		}
+	}
+
+	if ds.Type == tc.DSTypeHTTPNoCache {
+		block.Directives = append(block.Directives, "proxy_cache off;")
+	} else {
+		block.Directives = append(block.Directives,
+			"proxy_cache "+NginxCacheZone+";",
+			"proxy_cache_key "+cacheKey+";",
+		)
+	}
+	block.Directives = append(block.Directives,
+		"proxy_http_version 1.1;",
+		`proxy_set_header Connection "";`,
+		"proxy_set_header Host "+org.Host+";",
+	)
+	if org.Scheme == "https" {
+		block.Directives = append(block.Directives, "proxy_ssl_server_name on;", "proxy_ssl_name "+org.Hostname()+";")
+	}
+
+	hdrRewrite := ds.EdgeHeaderRewrite
+	if serverType == tc.CacheTypeMid {
+		hdrRewrite = ds.MidHeaderRewrite
+	}
+	if hdrRewrite != nil && *hdrRewrite != "" {
+		block.Directives = append(block.Directives, makeNginxHeaderRules(ds.Name, *hdrRewrite)...)
+	}
+
+	if st.Name == "" {
+		block.Locations = []nginxLocation{{Name: "/", ProxyPass: org.Scheme + "://" + org.Host + uriVar}}
+		return true
+	}
+
+	block.Directives = append(block.Directives, "proxy_next_upstream "+nginxNextUpstream(ds.Name, st)+";")
+	for ring := range st.Groups {
+		name := "/"
+		if ring > 0 {
+			name = "@" + nginxUpstreamName(st, ring)
+		}
+		block.Locations = append(block.Locations, nginxLocation{Name: name, ProxyPass: st.Scheme + "://" + nginxUpstreamName(st, ring) + uriVar})
+	}
+	if st.GoDirect {
+		block.Locations = append(block.Locations, nginxLocation{Name: "@" + st.Name + "-origin", ProxyPass: org.Scheme + "://" + org.Host + uriVar})
+	}
+	return true
+}
+
+// nginxNextUpstream returns the proxy_next_upstream conditions of the strategy: errors, timeouts, and the strategy's retry and markdown codes.
+func nginxNextUpstream(dsName string, st strategy) string {
+	codes := map[int]struct{}{502: {}, 503: {}, 504: {}}
+	for _, code := range append(append([]int{}, st.SimpleRetryCodes...), st.UnavailableRetryCodes...) {
+		if _, ok := nginxNextUpstreamCodes[code]; !ok {
+			log.Warnln(NginxConfigFileName + ": delivery service '" + dsName + "' retry response code " + strconv.Itoa(code) + " isn't supported by nginx proxy_next_upstream, not retrying it")
+			continue
+		}
+		codes[code] = struct{}{}
+	}
+	sortedCodes := []int{}
+	for code := range codes {
+		sortedCodes = append(sortedCodes, code)
+	}
+	sort.Ints(sortedCodes)
+
+	conditions := "error timeout"
+	for _, code := range sortedCodes {
+		conditions += " http_" + strconv.Itoa(code)
+	}
+	return conditions
+}
+
+// nginxHeaderRewriteHookRe matches the header rewrite hook conditions, which apply the following operators to the client response, or to the request.
+var nginxHeaderRewriteHookRe = regexp.MustCompile(`^cond\s+%\{(SEND_RESPONSE_HDR_HOOK|READ_RESPONSE_HDR_HOOK|REMAP_PSEUDO_HOOK|READ_REQUEST_HDR_HOOK|SEND_REQUEST_HDR_HOOK)\}`)
+
+// makeNginxHeaderRules returns the nginx directives equivalent to the set-header, add-header, and rm-header operators of a delivery service's header rewrite.
+// Operators after a response hook condition change the client response, and others change the request to the parent or origin. Operators after any other condition, operators with header rewrite variables, and other operators can't be translated, and are logged and skipped.
+func makeNginxHeaderRules(dsName string, hdrRewrite string) []string {
+	rules := []string{}
+	response := false
+	conditional := false
+	for _, line := range regexp.MustCompile(`\s*__RETURN__\s*|\n`).Split(hdrRewrite, -1) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if match := nginxHeaderRewriteHookRe.FindStringSubmatch(line); match != nil {
+			response = match[1] == "SEND_RESPONSE_HDR_HOOK" || match[1] == "READ_RESPONSE_HDR_HOOK"
+			conditional = false
+			continue
+		}
+		if strings.HasPrefix(line, "cond ") {
+			log.Warnln(NginxConfigFileName + ": delivery service '" + dsName + "' header rewrite condition '" + line + "' can't be translated to nginx, skipping the operators after it")
+			conditional = true
+			continue
+		}
+		if conditional {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimSuffix(line, "[L]"))
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.Contains(line, "%{") {
+			log.Warnln(NginxConfigFileName + ": delivery service '" + dsName + "' header rewrite '" + line + "' can't be translated to nginx, skipping")
+			continue
+		}
+		name := fields[1]
+		val := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(line, fields[0])), name)), `"`)
+		val = `"` + strings.Replace(val, `"`, `\"`, -1) + `"`
+
+		switch {
+		case fields[0] == "rm-header" && response:
+			rules = append(rules, "proxy_hide_header "+name+";")
+		case fields[0] == "rm-header":
+			rules = append(rules, "proxy_set_header "+name+` "";`)
+		case (fields[0] == "set-header" || fields[0] == "add-header") && len(fields) > 2 && response:
+			if fields[0] == "set-header" {
+				rules = append(rules, "proxy_hide_header "+name+";")
+			}
+			rules = append(rules, "add_header "+name+" "+val+" always;")
+		case (fields[0] == "set-header" || fields[0] == "add-header") && len(fields) > 2:
+			rules = append(rules, "proxy_set_header "+name+" "+val+";")
+		default:
+			log.Warnln(NginxConfigFileName + ": delivery service '" + dsName + "' header rewrite '" + line + "' can't be translated to nginx, skipping")
+		}
+	}
+	return rules
+}
+
+// makeNginxAccessRules returns the server-level allow and deny directives, and the limit_except block, equivalent to the ip_allow.config rules.
+// Sources allowed all methods are trusted. If any rule denies all methods to other sources, only trusted sources are allowed; otherwise, methods denied to other sources are limited to trusted sources.
+func makeNginxAccessRules(ipAllowData []IPAllowData) ([]string, string) {
+	trusted := []string{}
+	denyAll := false
+	deniedMethods := map[string]struct{}{}
+	for _, rule := range ipAllowData {
+		allMethods := len(rule.Methods) == 1 && rule.Methods[0] == IPAllowMethodAll
+		switch {
+		case rule.Action == IPAllowActionAllow && allMethods:
+			for _, cidr := range rule.Srcs {
+				trusted = append(trusted, "allow "+cidr.String()+";")
+			}
+			for _, src := range rule.RawSrcs {
+				log.Warnln(NginxConfigFileName + ": ip_allow source '" + src + "' isn't an IP, CIDR or range, and can't be translated to nginx, skipping")
+			}
+		case rule.Action == IPAllowActionDeny && allMethods:
+			denyAll = true
+		case rule.Action == IPAllowActionDeny:
+			for _, method := range rule.Methods {
+				deniedMethods[strings.ToUpper(method)] = struct{}{}
+			}
+		default:
+			log.Warnln(NginxConfigFileName + ": ip_allow rule allowing methods '" + strings.Join(rule.Methods, "|") + "' to some sources can't be translated to nginx, skipping")
+		}
+	}
+	trusted, _ = util.RemoveStrDuplicates(trusted, map[string]struct{}{})
+
+	if denyAll {
+		return append(trusted, "deny all;"), ""
+	}
+	if len(deniedMethods) == 0 {
+		return nil, ""
+	}
+	allowedMethods := []string{}
+	for _, method := range nginxMethods {
+		if _, ok := deniedMethods[method]; !ok {
+			allowedMethods = append(allowedMethods, method)
+		}
+	}
+	return nil, "limit_except " + strings.Join(allowedMethods, " ") + " { " + strings.Join(append(trusted, "deny all;"), " ") + " }"
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestMakeNginxDotConfEdge(t *testing.T) {
+	serverInfo := &ServerInfo{
+		CacheGroupID:                  42,
+		CDN:                           "myCDN",
+		DomainName:                    "example.net",
+		HostName:                      "myserver",
+		ID:                            44,
+		Port:                          80,
+		HTTPSPort:                     443,
+		ParentCacheGroupID:            45,
+		ParentCacheGroupType:          "MID_LOC",
+		SecondaryParentCacheGroupID:   47,
+		SecondaryParentCacheGroupType: "MID_LOC",
+		Type:                          "EDGE_NGINX",
+	}
+
+	hostRegex := string(tc.DSMatchTypeHostRegex)
+	httpProtocol := tc.DSProtocolHTTP
+	httpsProtocol := tc.DSProtocolHTTPS
+	dropQStr := tc.QueryStringIgnoreDropAtEdge
+	origin0 := "http://origin0.example.net"
+	origin1 := "https://origin1.example.net"
+	pattern0 := `.*\.ds0\..*`
+	pattern1 := `ds1\.mycdn\.example\.net`
+	domain := "mycdn.example.net"
+	hdrRewrite := "cond %{SEND_RESPONSE_HDR_HOOK} __RETURN__ set-header X-Served-By myserver [L] __RETURN__ rm-header Server"
+
+	remapDSes := []RemapConfigDSData{
+		{Name: "ds0", Type: tc.DSTypeHTTP, OriginFQDN: &origin0, RegexType: &hostRegex, Pattern: &pattern0, Domain: &domain, Protocol: &httpProtocol, QStringIgnore: &dropQStr},
+		{Name: "ds1", Type: tc.DSTypeHTTPNoCache, OriginFQDN: &origin1, RegexType: &hostRegex, Pattern: &pattern1, Domain: &domain, Protocol: &httpsProtocol, EdgeHeaderRewrite: &hdrRewrite},
+	}
+	sslDSes := map[tc.DeliveryServiceName]SSLMultiCertDS{
+		"ds1": {Type: tc.DSTypeHTTPNoCache, Protocol: tc.DSProtocolHTTPS, ExampleURLs: []string{"https://ds1.mycdn.example.net"}},
+	}
+	parentDSes := []ParentConfigDSTopLevel{
+		{ParentConfigDS: ParentConfigDS{Name: "ds0", OriginFQDN: origin0, Type: tc.DSTypeHTTP, QStringIgnore: tc.QStringIgnoreDrop}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds1", OriginFQDN: origin1, Type: tc.DSTypeHTTPNoCache, QStringIgnore: tc.QStringIgnoreUseInCacheKeyAndPassUp}},
+	}
+	parentInfos := map[OriginHost][]ParentInfo{
+		DeliveryServicesAllParentsKey: {
+			makeTestParentInfo("mid0", 45, serverInfo),
+			makeTestParentInfo("mid1", 47, serverInfo),
+		},
+	}
+
+	txt := MakeNginxDotConf(serverInfo, "myToolName", "https://myto.example.net", map[string]string{NginxParamMaxSize: "10g"}, remapDSes, sslDSes, parentDSes, map[string]string{}, parentInfos, map[string][]string{}, nil)
+
+	testComment(t, txt, serverInfo.HostName, "myToolName", "https://myto.example.net")
+
+	expecteds := []string{
+		"proxy_cache_path /var/cache/nginx/trafficcontrol levels=1:2 keys_zone=trafficcontrol:256m inactive=7d max_size=10g use_temp_path=off;",
+		"upstream strategy-ds0 {\n\thash $uri consistent;\n\tserver mid0.example.net:80 weight=999;\n\tkeepalive 32;\n}",
+		"upstream strategy-ds0-ring2 {\n\thash $uri consistent;\n\tserver mid1.example.net:80 weight=999;\n\tkeepalive 32;\n}",
+		"\tlisten 80;\n\tserver_name myserver.ds0.mycdn.example.net;\n",
+		"\tproxy_cache trafficcontrol;\n\tproxy_cache_key http://origin0.example.net$uri;\n",
+		"\tproxy_set_header Host origin0.example.net;\n",
+		"\tproxy_next_upstream error timeout http_502 http_503 http_504;\n",
+		"\trecursive_error_pages on;\n",
+		"\tlocation / {\n\t\tlimit_except GET HEAD POST PUT MKCOL COPY MOVE OPTIONS PROPFIND PROPPATCH LOCK UNLOCK PATCH { allow 127.0.0.1/32; allow ::1/128; deny all; }\n\t\terror_page 502 504 = @strategy-ds0-ring2;\n\t\tproxy_pass http://strategy-ds0$uri;\n\t}",
+		"\tlocation @strategy-ds0-ring2 {\n\t\tproxy_pass http://strategy-ds0-ring2$uri;\n\t}",
+		"\tlisten 443 ssl;\n\tserver_name ds1.mycdn.example.net;\n\tssl_certificate /etc/nginx/ssl/ds1_mycdn_example_net_cert.cer;\n\tssl_certificate_key /etc/nginx/ssl/ds1.mycdn.example.net.key;\n\tproxy_cache off;\n",
+		"\tproxy_set_header Host origin1.example.net;\n\tproxy_ssl_server_name on;\n\tproxy_ssl_name origin1.example.net;\n",
+		"\tproxy_hide_header X-Served-By;\n\tadd_header X-Served-By \"myserver\" always;\n\tproxy_hide_header Server;\n",
+		"\t\tproxy_pass https://origin1.example.net;\n", // like parent.config, no-cache delivery services go directly to the origin
+	}
+	for _, expected := range expecteds {
+		if !strings.Contains(txt, expected) {
+			t.Errorf("expected '%v', actual: '%v'", expected, txt)
+		}
+	}
+}
+
+func TestMakeNginxDotConfMid(t *testing.T) {
+	serverInfo := &ServerInfo{
+		CacheGroupID:                45,
+		CDN:                         "myCDN",
+		DomainName:                  "example.net",
+		HostName:                    "mymid",
+		ID:                          46,
+		Port:                        8080,
+		ParentCacheGroupID:          InvalidID,
+		ParentCacheGroupType:        tc.CacheGroupOriginTypeName,
+		SecondaryParentCacheGroupID: InvalidID,
+		Type:                        "MID_NGINX",
+	}
+
+	origin0 := "http://origin0.example.net"
+	origin1 := "http://origin1.example.net"
+	remapDSes := []RemapConfigDSData{
+		{Name: "ds0", Type: tc.DSTypeHTTP, OriginFQDN: &origin0},
+		{Name: "ds0-copy", Type: tc.DSTypeHTTP, OriginFQDN: &origin0},
+		{Name: "ds1", Type: tc.DSTypeHTTP, OriginFQDN: &origin1},
+		{Name: "ds-live", Type: tc.DSTypeHTTPLive, OriginFQDN: &origin1},
+	}
+	parentDSes := []ParentConfigDSTopLevel{
+		{ParentConfigDS: ParentConfigDS{Name: "ds0", OriginFQDN: origin0, Type: tc.DSTypeHTTP, MultiSiteOrigin: true}, MSOAlgorithm: "false"},
+		{ParentConfigDS: ParentConfigDS{Name: "ds0-copy", OriginFQDN: origin0, Type: tc.DSTypeHTTP, MultiSiteOrigin: true}, MSOAlgorithm: "false"},
+		{ParentConfigDS: ParentConfigDS{Name: "ds1", OriginFQDN: origin1, Type: tc.DSTypeHTTP}},
+	}
+	org0 := ParentInfo{Host: "org0", Domain: "example.net", Port: 80, Weight: "1", Rank: 1}
+	org1 := ParentInfo{Host: "org1", Domain: "example.net", Port: 80, Weight: "1", Rank: 2}
+	parentInfos := map[OriginHost][]ParentInfo{"origin0.example.net": {org0, org1}}
+	childServers := map[tc.CacheName]IPAllowServer{"edge0": {IPAddress: "192.0.2.1"}}
+
+	txt := MakeNginxDotConf(serverInfo, "myToolName", "https://myto.example.net", nil, remapDSes, nil, parentDSes, map[string]string{}, parentInfos, map[string][]string{}, childServers)
+
+	expecteds := []string{
+		"upstream strategy-ds0 {\n\tserver org0.example.net:80;\n\tserver org1.example.net:80 backup;\n\tkeepalive 32;\n}",
+		"# delivery service ds0 origin http://origin0.example.net\nserver {\n\tlisten 8080;\n\tserver_name origin0.example.net;\n",
+		"\tallow 10.0.0.0/8;\n\tallow 127.0.0.1/32;\n\tallow 172.16.0.0/12;\n\tallow 192.0.2.1/32;\n\tallow 192.168.0.0/16;\n\tallow ::1/128;\n\tdeny all;\n",
+		"\tlocation / {\n\t\tproxy_pass http://strategy-ds0;\n\t}",
+		"\tserver_name origin1.example.net;\n",
+		"\tlocation / {\n\t\tproxy_pass http://origin1.example.net;\n\t}",
+	}
+	for _, expected := range expecteds {
+		if !strings.Contains(txt, expected) {
+			t.Errorf("expected '%v', actual: '%v'", expected, txt)
+		}
+	}
+	if count := strings.Count(txt, "server {"); count != 2 {
+		t.Errorf("expected a server block for each origin, without live local delivery services, actual %v: '%v'", count, txt)
+	}
+	if strings.Contains(txt, "limit_except") {
+		t.Errorf("expected mids to deny untrusted clients every method, actual: '%v'", txt)
+	}
+}
+
+func TestMakeNginxHeaderRules(t *testing.T) {
+	hdrRewrite := `set-header X-Req "foo bar" [L] __RETURN__ rm-header Cookie` +
+		"\ncond %{SEND_RESPONSE_HDR_HOOK}\nadd-header Cache-Control max-age=60\nset-header X-Var %{CLIENT-IP}\n" +
+		"cond %{CLIENT-HEADER:X-Foo} =bar\nset-header X-Conditional yes\n" +
+		"cond %{REMAP_PSEUDO_HOOK}\nset-conn-dscp 8\n"
+	expected := []string{
+		`proxy_set_header X-Req "foo bar";`,
+		`proxy_set_header Cookie "";`,
+		`add_header Cache-Control "max-age=60" always;`,
+	}
+	actual := makeNginxHeaderRules("ds0", hdrRewrite)
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+}
+
+func TestNginxWeight(t *testing.T) {
+	inputExpecteds := map[string]int{"0.999": 999, "0.5": 500, "1": 1000, "10": 10, "0": 1, "": 1, "0.0001": 1, "2.5": 3}
+	for input, expected := range inputExpecteds {
+		if actual := nginxWeight(input); actual != expected {
+			t.Errorf("weight '%v' expected %v, actual %v", input, expected, actual)
+		}
+	}
+}
@@ -28,7 +28,6 @@ import (
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
-	"github.com/apache/trafficcontrol/lib/go-util"
 )
 
 const ParentConfigParamQStringHandling = "psel.qstring_handling"
@@ -65,6 +64,12 @@ type ParentConfigDS struct {
 	QStringHandling string
 
 	RequiredCapabilities map[ServerCapability]struct{}
+
+	// Topology is the name of the delivery service's topology, or empty if it uses the parents of the server's cache group.
+	Topology string
+	// TopologyParents are the server's parents for the delivery service in its topology, from MakeTopologyParentInfo.
+	// If the delivery service has a topology and no TopologyParents, the server is in the top tier of the topology, and goes to the origin.
+	TopologyParents []ParentInfo
 }
 
 type ParentConfigDSTopLevel struct {
@@ -90,13 +95,15 @@ type ParentInfo struct {
 }
 
 func (p ParentInfo) Format() string {
-	host := ""
+	return p.hostName() + ":" + strconv.Itoa(p.Port) + "|" + p.Weight + ";"
+}
+
+// hostName returns the IP of the parent if it uses its IP, and otherwise its FQDN.
+func (p ParentInfo) hostName() string {
 	if p.UseIP {
-		host = p.IP
-	} else {
-		host = p.Host + "." + p.Domain
+		return p.IP
 	}
-	return host + ":" + strconv.Itoa(p.Port) + "|" + p.Weight + ";"
+	return p.Host + "." + p.Domain
 }
 
 type OriginHost string
@@ -208,6 +215,11 @@ func MakeParentDotConfig(
 			}
 			uniqueOrigins[ds.OriginFQDN] = struct{}{}
 
+			if ds.Topology != "" {
+				textArr = append(textArr, getTopologyParentLine(ds, orgURI, serverParams, parentInfos, atsMajorVer))
+				continue
+			}
+
 			textLine := ""
 
 			if ds.OriginShield != "" {
@@ -217,31 +229,7 @@ func MakeParentDotConfig(
 				}
 				textLine += "dest_domain=" + orgURI.Hostname() + " port=" + orgURI.Port() + " parent=" + ds.OriginShield + " " + algorithm + " go_direct=true\n"
 			} else if ds.MultiSiteOrigin {
-				textLine += "dest_domain=" + orgURI.Hostname() + " port=" + orgURI.Port() + " "
-				if len(parentInfos) == 0 {
-				}
-
-				if len(parentInfos[OriginHost(orgURI.Hostname())]) == 0 {
-					// TODO error? emulates Perl
-					log.Warnln("ParentInfo: delivery service " + ds.Name + " has no parent servers")
-				}
-
-				parents, secondaryParents := getMSOParentStrs(ds, parentInfos[OriginHost(orgURI.Hostname())], atsMajorVer)
-				textLine += parents + secondaryParents + ` round_robin=` + ds.MSOAlgorithm + ` qstring=` + parentQStr + ` go_direct=false parent_is_proxy=false`
-
-				parentRetry := ds.MSOParentRetry
-				if atsMajorVer >= 6 && parentRetry != "" {
-					if unavailableServerRetryResponsesValid(ds.MSOUnavailableServerRetryResponses) {
-						textLine += ` parent_retry=` + parentRetry + ` unavailable_server_retry_responses=` + ds.MSOUnavailableServerRetryResponses
-					} else {
-						if ds.MSOUnavailableServerRetryResponses != "" {
-							log.Errorln("Malformed unavailable_server_retry_responses parameter '" + ds.MSOUnavailableServerRetryResponses + "', not using!")
-						}
-						textLine += ` parent_retry=` + parentRetry
-					}
-					textLine += ` max_simple_retries=` + ds.MSOMaxSimpleRetries + ` max_unavailable_server_retries=` + ds.MSOMaxUnavailableServerRetries
-				}
-				textLine += "\n" // TODO remove, and join later on "\n" instead of ""?
+				textLine += getMSOParentLine(ds, orgURI, parentQStr, parentInfos, atsMajorVer)
 				textArr = append(textArr, textLine)
 			}
 		}
@@ -259,6 +247,9 @@ func MakeParentDotConfig(
 
 		for _, ds := range parentConfigDSes {
 			parents, secondaryParents := getParentStrs(ds, parentInfos[DeliveryServicesAllParentsKey], atsMajorVer)
+			if ds.Topology != "" {
+				parents, secondaryParents = getParentStrs(ds, ds.TopologyParents, atsMajorVer)
+			}
 
 			text := ""
 			originFQDN := ds.OriginFQDN
@@ -291,28 +282,10 @@ func MakeParentDotConfig(
 			// TODO encode this in a DSType func, IsGoDirect() ?
 			if dsType := tc.DSType(ds.Type); dsType == tc.DSTypeHTTPNoCache || dsType == tc.DSTypeHTTPLive || dsType == tc.DSTypeDNSLive {
 				text += `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` go_direct=true` + "\n"
+			} else if ds.Topology != "" && len(ds.TopologyParents) == 0 {
+				text += getTopologyParentLine(ds, orgURI, serverParams, parentInfos, atsMajorVer)
 			} else {
-
-				// check for profile psel.qstring_handling.  If this parameter is assigned to the server profile,
-				// then edges will use the qstring handling value specified in the parameter for all profiles.
-
-				// If there is no defined parameter in the profile, then check the delivery service profile.
-				// If psel.qstring_handling exists in the DS profile, then we use that value for the specified DS only.
-				// This is used only if not overridden by a server profile qstring handling parameter.
-
-				// TODO refactor this logic, hard to understand (transliterated from Perl)
-				dsQSH := queryStringHandling
-				if dsQSH == "" {
-					dsQSH = ds.QStringHandling
-				}
-				parentQStr := dsQSH
-				if parentQStr == "" {
-					parentQStr = "ignore"
-				}
-				if ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp && dsQSH == "" {
-					parentQStr = "consider"
-				}
-
+				parentQStr := getParentQStr(ds, queryStringHandling)
 				text += `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` ` + parents + ` ` + secondaryParents + ` ` + roundRobin + ` ` + goDirect + ` qstring=` + parentQStr + "\n"
 			}
 			textArr = append(textArr, text)
@@ -338,10 +311,103 @@ func MakeParentDotConfig(
 	return text
 }
 
+// getParentQStr returns the qstring= value for a non-top-level parent.config line, from the server's psel.qstring_handling parameter, which overrides the delivery service's.
+func getParentQStr(ds ParentConfigDSTopLevel, serverQStringHandling string) string {
+	// check for profile psel.qstring_handling.  If this parameter is assigned to the server profile,
+	// then edges will use the qstring handling value specified in the parameter for all profiles.
+
+	// If there is no defined parameter in the profile, then check the delivery service profile.
+	// If psel.qstring_handling exists in the DS profile, then we use that value for the specified DS only.
+	// This is used only if not overridden by a server profile qstring handling parameter.
+
+	// TODO refactor this logic, hard to understand (transliterated from Perl)
+	dsQSH := serverQStringHandling
+	if dsQSH == "" {
+		dsQSH = ds.QStringHandling
+	}
+	parentQStr := dsQSH
+	if parentQStr == "" {
+		parentQStr = "ignore"
+	}
+	if ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp && dsQSH == "" {
+		parentQStr = "consider"
+	}
+	return parentQStr
+}
+
+// getMSOParentLine returns the parent.config line, after the dest_domain, of a multi-site origin delivery service on a top-level cache, whose parents are the origins.
+func getMSOParentLine(ds ParentConfigDSTopLevel, orgURI *url.URL, parentQStr string, parentInfos map[OriginHost][]ParentInfo, atsMajorVer int) string {
+	textLine := "dest_domain=" + orgURI.Hostname() + " port=" + orgURI.Port() + " "
+
+	if len(parentInfos[OriginHost(orgURI.Hostname())]) == 0 {
+		// TODO error? emulates Perl
+		log.Warnln("ParentInfo: delivery service " + ds.Name + " has no parent servers")
+	}
+
+	parents, secondaryParents := getMSOParentStrs(ds, parentInfos[OriginHost(orgURI.Hostname())], atsMajorVer)
+	textLine += parents + secondaryParents + ` round_robin=` + ds.MSOAlgorithm + ` qstring=` + parentQStr + ` go_direct=false parent_is_proxy=false`
+
+	parentRetry := ds.MSOParentRetry
+	if atsMajorVer >= 6 && parentRetry != "" {
+		if unavailableServerRetryResponsesValid(ds.MSOUnavailableServerRetryResponses) {
+			textLine += ` parent_retry=` + parentRetry + ` unavailable_server_retry_responses=` + ds.MSOUnavailableServerRetryResponses
+		} else {
+			if ds.MSOUnavailableServerRetryResponses != "" {
+				log.Errorln("Malformed unavailable_server_retry_responses parameter '" + ds.MSOUnavailableServerRetryResponses + "', not using!")
+			}
+			textLine += ` parent_retry=` + parentRetry
+		}
+		textLine += ` max_simple_retries=` + ds.MSOMaxSimpleRetries + ` max_unavailable_server_retries=` + ds.MSOMaxUnavailableServerRetries
+	}
+	textLine += "\n" // TODO remove, and join later on "\n" instead of ""?
+	return textLine
+}
+
+// getTopologyParentLine returns the parent.config line for a delivery service with a topology, from the server's place in the topology rather than its cache group parents.
+// Servers with parents in the topology use them. Servers in the top tier go to the origin: to the multi-site origins or origin shield if the delivery service has them, and directly otherwise.
+func getTopologyParentLine(ds ParentConfigDSTopLevel, orgURI *url.URL, serverParams map[string]string, parentInfos map[OriginHost][]ParentInfo, atsMajorVer int) string {
+	if len(ds.TopologyParents) > 0 {
+		parents, secondaryParents := getParentStrs(ds, ds.TopologyParents, atsMajorVer)
+		parentQStr := getParentQStr(ds, serverParams[ParentConfigParamQStringHandling])
+		return `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` ` + parents + ` ` + secondaryParents + ` round_robin=consistent_hash go_direct=false qstring=` + parentQStr + "\n"
+	}
+	if ds.OriginShield != "" {
+		algorithm := ""
+		if parentSelectAlg := serverParams[ParentConfigParamAlgorithm]; strings.TrimSpace(parentSelectAlg) != "" {
+			algorithm = "round_robin=" + parentSelectAlg
+		}
+		return "dest_domain=" + orgURI.Hostname() + " port=" + orgURI.Port() + " parent=" + ds.OriginShield + " " + algorithm + " go_direct=true\n"
+	}
+	if ds.MultiSiteOrigin {
+		parentQStr := "ignore"
+		if ds.QStringHandling == "" && ds.MSOAlgorithm == tc.AlgorithmConsistentHash && ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp {
+			parentQStr = "consider"
+		}
+		return getMSOParentLine(ds, orgURI, parentQStr, parentInfos, atsMajorVer)
+	}
+	return `dest_domain=` + orgURI.Hostname() + ` port=` + orgURI.Port() + ` go_direct=true` + "\n"
+}
+
 // getParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines.
 func getParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajorVer int) (string, string) {
-	parentInfo := []string{}
-	secondaryParentInfo := []string{}
+	parentInfo, secondaryParentInfo := getParentGroups(ds, parentInfos)
+
+	parents := ""
+	secondaryParents := "" // "secparents" in Perl
+
+	if atsMajorVer >= 6 && len(secondaryParentInfo) > 0 {
+		parents = `parent="` + formatParents(parentInfo) + `"`
+		secondaryParents = ` secondary_parent="` + formatParents(secondaryParentInfo) + `"`
+	} else {
+		parents = `parent="` + formatParents(parentInfo) + formatParents(secondaryParentInfo) + `"`
+	}
+	return parents, secondaryParents
+}
+
+// getParentGroups returns the primary and secondary parents of a parent.config line, in the order they're written.
+func getParentGroups(ds ParentConfigDSTopLevel, parentInfos []ParentInfo) ([]ParentInfo, []ParentInfo) {
+	parentInfo := []ParentInfo{}
+	secondaryParentInfo := []ParentInfo{}
 
 	sort.Sort(ParentInfoSortByRank(parentInfos))
 
@@ -350,59 +416,70 @@ func getParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajor
 			continue
 		}
 
-		pTxt := parent.Format()
 		if parent.PrimaryParent {
-			parentInfo = append(parentInfo, pTxt)
+			parentInfo = append(parentInfo, parent)
 		} else if parent.SecondaryParent {
-			secondaryParentInfo = append(secondaryParentInfo, pTxt)
+			secondaryParentInfo = append(secondaryParentInfo, parent)
 		}
 	}
 
 	if len(parentInfo) == 0 {
 		parentInfo = secondaryParentInfo
-		secondaryParentInfo = []string{}
+		secondaryParentInfo = []ParentInfo{}
 	}
 
 	// TODO remove duplicate code with top level if block
 	seen := map[string]struct{}{} // TODO change to host+port? host isn't unique
-	parentInfo, seen = util.RemoveStrDuplicates(parentInfo, seen)
-	secondaryParentInfo, seen = util.RemoveStrDuplicates(secondaryParentInfo, seen)
+	parentInfo = removeParentDuplicates(parentInfo, seen)
+	secondaryParentInfo = removeParentDuplicates(secondaryParentInfo, seen)
+
+	sort.Sort(parentInfoSortByFormat(parentInfo))
+	sort.Sort(parentInfoSortByFormat(secondaryParentInfo))
+	return parentInfo, secondaryParentInfo
+}
+
+// getMSOParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines, for MSO.
+func getMSOParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajorVer int) (string, string) {
+	parentInfo, secondaryParentInfo, nullParentInfo := getMSOParentGroups(ds, parentInfos)
+
+	secondaryParentStr := formatParents(secondaryParentInfo) + formatParents(nullParentInfo)
+
+	// If the ats version supports it and the algorithm is consistent hash, put secondary and non-primary parents into secondary parent group.
+	// This will ensure that secondary and tertiary parents will be unused unless all hosts in the primary group are unavailable.
 
 	parents := ""
-	secondaryParents := "" // "secparents" in Perl
-	sort.Sort(sort.StringSlice(parentInfo))
-	sort.Sort(sort.StringSlice(secondaryParentInfo))
+	secondaryParents := ""
 
-	if atsMajorVer >= 6 && len(secondaryParentInfo) > 0 {
-		parents = `parent="` + strings.Join(parentInfo, "") + `"`
-		secondaryParents = ` secondary_parent="` + strings.Join(secondaryParentInfo, "") + `"`
+	if atsMajorVer >= 6 && ds.MSOAlgorithm == "consistent_hash" && len(secondaryParents) > 0 {
+		parents = `parent="` + formatParents(parentInfo) + `"`
+		secondaryParents = `" secondary_parent="` + secondaryParentStr + `"`
 	} else {
-		parents = `parent="` + strings.Join(parentInfo, "") + strings.Join(secondaryParentInfo, "") + `"`
+		parents = `parent="` + formatParents(parentInfo) + secondaryParentStr + `"`
 	}
 	return parents, secondaryParents
 }
 
-// getMSOParentStrs returns the parents= and secondary_parents= strings for ATS parent.config lines, for MSO.
-func getMSOParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMajorVer int) (string, string) {
-	// TODO determine why MSO is different, and if possible, combine with getParentAndSecondaryParentStrs.
+// getMSOParentGroups returns the primary, secondary, and null (neither primary nor secondary) parents of an MSO parent.config line, in the order they're written.
+func getMSOParentGroups(ds ParentConfigDSTopLevel, parentInfos []ParentInfo) ([]ParentInfo, []ParentInfo, []ParentInfo) {
+	// TODO determine why MSO is different, and if possible, combine with getParentGroups.
 
 	rankedParents := ParentInfoSortByRank(parentInfos)
 	sort.Sort(rankedParents)
 
-	parentInfo := []string{}
-	secondaryParentInfo := []string{}
-	nullParentInfo := []string{}
+	parentInfo := []ParentInfo{}
+	secondaryParentInfo := []ParentInfo{}
+	nullParentInfo := []ParentInfo{}
 	for _, parent := range ([]ParentInfo)(rankedParents) {
 		if !HasRequiredCapabilities(parent.Capabilities, ds.RequiredCapabilities) {
 			continue
 		}
 
 		if parent.PrimaryParent {
-			parentInfo = append(parentInfo, parent.Format())
+			parentInfo = append(parentInfo, parent)
 		} else if parent.SecondaryParent {
-			secondaryParentInfo = append(secondaryParentInfo, parent.Format())
+			secondaryParentInfo = append(secondaryParentInfo, parent)
 		} else {
-			nullParentInfo = append(nullParentInfo, parent.Format())
+			nullParentInfo = append(nullParentInfo, parent)
 		}
 	}
 
@@ -411,35 +488,49 @@ func getMSOParentStrs(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, atsMa
 		// as the secondary parent list and clear the null parent list.
 		if len(secondaryParentInfo) == 0 {
 			secondaryParentInfo = nullParentInfo
-			nullParentInfo = []string{}
+			nullParentInfo = []ParentInfo{}
 		}
 		parentInfo = secondaryParentInfo
-		secondaryParentInfo = []string{} // TODO should thi be '= secondary'? Currently emulates Perl
+		secondaryParentInfo = []ParentInfo{} // TODO should thi be '= secondary'? Currently emulates Perl
 	}
 
 	// TODO benchmark, verify this isn't slow. if it is, it could easily be made faster
 	seen := map[string]struct{}{} // TODO change to host+port? host isn't unique
-	parentInfo, seen = util.RemoveStrDuplicates(parentInfo, seen)
-	secondaryParentInfo, seen = util.RemoveStrDuplicates(secondaryParentInfo, seen)
-	nullParentInfo, seen = util.RemoveStrDuplicates(nullParentInfo, seen)
-
-	secondaryParentStr := strings.Join(secondaryParentInfo, "") + strings.Join(nullParentInfo, "")
-
-	// If the ats version supports it and the algorithm is consistent hash, put secondary and non-primary parents into secondary parent group.
-	// This will ensure that secondary and tertiary parents will be unused unless all hosts in the primary group are unavailable.
+	parentInfo = removeParentDuplicates(parentInfo, seen)
+	secondaryParentInfo = removeParentDuplicates(secondaryParentInfo, seen)
+	nullParentInfo = removeParentDuplicates(nullParentInfo, seen)
+	return parentInfo, secondaryParentInfo, nullParentInfo
+}
 
-	parents := ""
-	secondaryParents := ""
+// removeParentDuplicates returns the parents whose parent.config text isn't in seen, adding them to seen.
+func removeParentDuplicates(parents []ParentInfo, seen map[string]struct{}) []ParentInfo {
+	unique := []ParentInfo{}
+	for _, parent := range parents {
+		txt := parent.Format()
+		if _, ok := seen[txt]; ok {
+			continue
+		}
+		seen[txt] = struct{}{}
+		unique = append(unique, parent)
+	}
+	return unique
+}
 
-	if atsMajorVer >= 6 && ds.MSOAlgorithm == "consistent_hash" && len(secondaryParents) > 0 {
-		parents = `parent="` + strings.Join(parentInfo, "") + `"`
-		secondaryParents = `" secondary_parent="` + secondaryParentStr + `"`
-	} else {
-		parents = `parent="` + strings.Join(parentInfo, "") + secondaryParentStr + `"`
+// formatParents returns the parent.config text of the given parents, in order.
+func formatParents(parents []ParentInfo) string {
+	txt := ""
+	for _, parent := range parents {
+		txt += parent.Format()
 	}
-	return parents, secondaryParents
+	return txt
 }
 
+type parentInfoSortByFormat []ParentInfo
+
+func (s parentInfoSortByFormat) Len() int           { return len(s) }
+func (s parentInfoSortByFormat) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s parentInfoSortByFormat) Less(i, j int) bool { return s[i].Format() < s[j].Format() }
+
 func MakeParentInfo(
 	server *ServerInfo,
 	serverDomain string, // getCDNDomainByProfileID(tx, server.ProfileID)
@@ -460,27 +551,55 @@ func MakeParentInfo(
 			// 	continue
 			// }
 
-			parentInf := ParentInfo{
-				Host:            row.ServerHost,
-				Port:            profile.Port,
-				Domain:          row.Domain,
-				Weight:          profile.Weight,
-				UseIP:           profile.UseIP,
-				Rank:            profile.Rank,
-				IP:              row.ServerIP,
-				PrimaryParent:   server.ParentCacheGroupID == row.CacheGroupID,
-				SecondaryParent: server.SecondaryParentCacheGroupID == row.CacheGroupID,
-				Capabilities:    row.Capabilities,
-			}
-			if parentInf.Port < 1 {
-				parentInf.Port = row.ServerPort
-			}
+			parentInf := makeParentInfo(row, profile, server.ParentCacheGroupID, server.SecondaryParentCacheGroupID)
 			parentInfos[originHost] = append(parentInfos[originHost], parentInf)
 		}
 	}
 	return parentInfos
 }
 
+// MakeTopologyParentInfo returns the parents of a server for a delivery service with a topology, for ParentConfigDS.TopologyParents.
+// The parentCacheGroupID and secondaryParentCacheGroupID are the IDs of the parents of the server's cache group in the topology, or InvalidID.
+// The servers should be the servers in those cache groups.
+func MakeTopologyParentInfo(
+	parentCacheGroupID int,
+	secondaryParentCacheGroupID int,
+	profileCaches map[ProfileID]ProfileCache,
+	servers []CGServer,
+) []ParentInfo {
+	parentInfos := []ParentInfo{}
+	for _, row := range servers {
+		if row.CacheGroupID != parentCacheGroupID && row.CacheGroupID != secondaryParentCacheGroupID {
+			continue
+		}
+		profile := profileCaches[row.ProfileID]
+		if profile.NotAParent {
+			continue
+		}
+		parentInfos = append(parentInfos, makeParentInfo(row, profile, parentCacheGroupID, secondaryParentCacheGroupID))
+	}
+	return parentInfos
+}
+
+func makeParentInfo(row CGServer, profile ProfileCache, parentCacheGroupID int, secondaryParentCacheGroupID int) ParentInfo {
+	parentInf := ParentInfo{
+		Host:            row.ServerHost,
+		Port:            profile.Port,
+		Domain:          row.Domain,
+		Weight:          profile.Weight,
+		UseIP:           profile.UseIP,
+		Rank:            profile.Rank,
+		IP:              row.ServerIP,
+		PrimaryParent:   parentCacheGroupID == row.CacheGroupID,
+		SecondaryParent: secondaryParentCacheGroupID == row.CacheGroupID,
+		Capabilities:    row.Capabilities,
+	}
+	if parentInf.Port < 1 {
+		parentInf.Port = row.ServerPort
+	}
+	return parentInf
+}
+
 // unavailableServerRetryResponsesValid returns whether a unavailable_server_retry_responses parameter is valid for an ATS parent rule.
 func unavailableServerRetryResponsesValid(s string) bool {
 	// optimization if param is empty
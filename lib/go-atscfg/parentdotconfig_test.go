@@ -238,3 +238,91 @@ func TestMakeParentDotConfigCapabilities(t *testing.T) {
 		}
 	}
 }
+
+func TestMakeParentDotConfigTopology(t *testing.T) {
+	atsMajorVer := 7
+	toolName := "myToolName"
+	toURL := "https://myto.example.net"
+
+	serverInfo := &ServerInfo{
+		CacheGroupID:                  42,
+		CDN:                           "myCDN",
+		CDNID:                         43,
+		DomainName:                    "serverdomain.example.net",
+		HostName:                      "myserver",
+		ID:                            44,
+		IP:                            "192.168.2.1",
+		ParentCacheGroupID:            45,
+		ParentCacheGroupType:          "MID_LOC",
+		ProfileID:                     46,
+		ProfileName:                   "MyProfileName",
+		Port:                          80,
+		SecondaryParentCacheGroupID:   InvalidID,
+		SecondaryParentCacheGroupType: "",
+		Type:                          "EDGE",
+	}
+
+	profileCaches := map[ProfileID]ProfileCache{48: DefaultProfileCache()}
+	topologyServers := []CGServer{
+		{ServerID: 1, ServerHost: "topology-mid-0", ServerIP: "192.168.2.3", ServerPort: 80, CacheGroupID: 50, ProfileID: 48, Domain: "example.net"},
+		{ServerID: 2, ServerHost: "topology-mid-1", ServerIP: "192.168.2.4", ServerPort: 80, CacheGroupID: 51, ProfileID: 48, Domain: "example.net"},
+		{ServerID: 3, ServerHost: "unrelated-mid", ServerIP: "192.168.2.5", ServerPort: 80, CacheGroupID: 52, ProfileID: 48, Domain: "example.net"},
+	}
+
+	parentConfigDSes := []ParentConfigDSTopLevel{
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:            "cgparents",
+				QStringIgnore:   tc.QStringIgnoreDrop,
+				OriginFQDN:      "http://cgparents.example.net",
+				Type:            tc.DSTypeHTTP,
+				QStringHandling: "",
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:            "threetier",
+				QStringIgnore:   tc.QStringIgnoreDrop,
+				OriginFQDN:      "http://threetier.example.net",
+				Type:            tc.DSTypeHTTP,
+				Topology:        "three-tier",
+				TopologyParents: MakeTopologyParentInfo(50, 51, profileCaches, topologyServers),
+			},
+		},
+		ParentConfigDSTopLevel{
+			ParentConfigDS: ParentConfigDS{
+				Name:          "edgeonly",
+				QStringIgnore: tc.QStringIgnoreDrop,
+				OriginFQDN:    "http://edgeonly.example.net",
+				Type:          tc.DSTypeHTTP,
+				Topology:      "edge-only",
+			},
+		},
+	}
+
+	parentInfos := map[OriginHost][]ParentInfo{
+		DeliveryServicesAllParentsKey: []ParentInfo{
+			ParentInfo{Host: "cg-parent", Port: 80, Domain: "example.net", Weight: "1", Rank: 1, IP: "192.168.2.2", PrimaryParent: true},
+		},
+	}
+
+	txt := MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, map[string]string{}, parentInfos)
+	lines := map[string]string{}
+	for _, line := range strings.Split(txt, "\n") {
+		for _, ds := range []string{"cgparents", "threetier", "edgeonly"} {
+			if strings.HasPrefix(line, "dest_domain="+ds+".example.net ") {
+				lines[ds] = line
+			}
+		}
+	}
+
+	if line := lines["cgparents"]; !strings.Contains(line, `parent="cg-parent.example.net:80|1;"`) {
+		t.Errorf("expected delivery service without a topology to use cachegroup parents, actual: '%v'", line)
+	}
+	if line := lines["threetier"]; !strings.Contains(line, `parent="topology-mid-0.example.net:80|0.999;"`) || !strings.Contains(line, `secondary_parent="topology-mid-1.example.net:80|0.999;"`) || strings.Contains(line, "cg-parent") || strings.Contains(line, "unrelated-mid") {
+		t.Errorf("expected delivery service with a topology to use topology parents, actual: '%v'", line)
+	}
+	if line := lines["edgeonly"]; !strings.Contains(line, "go_direct=true") || strings.Contains(line, "parent=") {
+		t.Errorf("expected delivery service with a topology without parents to go direct, actual: '%v'", line)
+	}
+}
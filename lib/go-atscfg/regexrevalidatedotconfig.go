@@ -128,11 +128,11 @@ func filterJobs(jobs []tc.Job, maxReval time.Duration, minTTL time.Duration) []J
 			continue
 		}
 
-		if jobStartTime.Add(maxReval).Before(time.Now()) {
+		if jobStartTime.Add(maxReval).Before(Now()) {
 			continue
 		}
 
-		if jobStartTime.Add(ttl).Before(time.Now()) {
+		if jobStartTime.Add(ttl).Before(Now()) {
 			continue
 		}
 		if job.Keyword != JobKeywordPurge {
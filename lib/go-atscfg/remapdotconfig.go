@@ -59,6 +59,9 @@ type RemapConfigDSData struct {
 	Protocol                 *int
 	AnonymousBlockingEnabled *bool
 	Active                   bool
+	// Strategy is the name of the delivery service's strategies.yaml next-hop strategy, from MakeDSStrategyNames, or empty if it has none.
+	// It's only used with ATS 9 and later.
+	Strategy string
 }
 
 func MakeRemapDotConfig(
@@ -142,6 +145,7 @@ func GetServerConfigRemapDotConfigForMid(
 		if ds.RangeRequestHandling != nil && *ds.RangeRequestHandling == tc.RangeRequestHandlingCacheRangeRequest {
 			midRemap += ` @plugin=cache_range_requests.so`
 		}
+		midRemap += getStrategyRemap(atsMajorVersion, ds)
 
 		if midRemap != "" {
 			midRemaps[*ds.OriginFQDN] = midRemap
@@ -296,10 +300,19 @@ func BuildRemapLine(cacheURLConfigParams map[string]string, atsMajorVersion int,
 	if ds.FQPacingRate != nil && *ds.FQPacingRate > 0 {
 		text += ` @plugin=fq_pacing.so @pparam=--rate=` + strconv.Itoa(*ds.FQPacingRate)
 	}
+	text += getStrategyRemap(atsMajorVersion, ds)
 	text += "\n"
 	return text
 }
 
+// getStrategyRemap returns the remap @strategy of the delivery service, or the empty string if it has no strategy or ATS doesn't support them.
+func getStrategyRemap(atsMajorVersion int, ds RemapConfigDSData) string {
+	if atsMajorVersion < StrategiesMinATSMajorVersion || ds.Strategy == "" {
+		return ""
+	}
+	return ` @strategy=` + ds.Strategy
+}
+
 func DSProfileIDs(dses []RemapConfigDSData) []int {
 	dsProfileIDs := []int{}
 	for _, ds := range dses {
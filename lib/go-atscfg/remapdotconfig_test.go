@@ -5419,3 +5419,53 @@ func TestMakeRemapDotConfigEdgeRegexTypeNil(t *testing.T) {
 	}
 
 }
+
+func TestMakeRemapDotConfigStrategy(t *testing.T) {
+	serverInfo := &ServerInfo{
+		CacheGroupID:                42,
+		CDN:                         "mycdn",
+		DomainName:                  "mydomain",
+		HostName:                    "myhost",
+		ID:                          44,
+		ParentCacheGroupID:          45,
+		SecondaryParentCacheGroupID: 47,
+		Port:                        80,
+		Type:                        "EDGE",
+	}
+
+	makeDS := func(strategy string) RemapConfigDSData {
+		return RemapConfigDSData{
+			ID:         48,
+			Type:       tc.DSTypeHTTP,
+			OriginFQDN: util.StrPtr("http://origin.example.test"),
+			Name:       "mydsname",
+			Pattern:    util.StrPtr(`.*\.mydsname\..*`),
+			RegexType:  util.StrPtr(string(tc.DSMatchTypeHostRegex)),
+			Domain:     util.StrPtr("mydomain"),
+			Protocol:   util.IntPtr(0),
+			Active:     true,
+			Strategy:   strategy,
+		}
+	}
+
+	txt := MakeRemapDotConfig("myhost", "to0", "trafficops.example.net", 9, map[string]string{}, map[int]map[string]string{}, map[string]string{}, serverInfo, []RemapConfigDSData{makeDS("strategy-mydsname")})
+	if !strings.Contains(txt, " @strategy=strategy-mydsname\n") {
+		t.Errorf("expected ATS 9 edge remap with strategy, actual '%v'", txt)
+	}
+
+	txt = MakeRemapDotConfig("myhost", "to0", "trafficops.example.net", 8, map[string]string{}, map[int]map[string]string{}, map[string]string{}, serverInfo, []RemapConfigDSData{makeDS("strategy-mydsname")})
+	if strings.Contains(txt, "@strategy") {
+		t.Errorf("expected ATS 8 edge remap without strategy, actual '%v'", txt)
+	}
+
+	txt = MakeRemapDotConfig("myhost", "to0", "trafficops.example.net", 9, map[string]string{}, map[int]map[string]string{}, map[string]string{}, serverInfo, []RemapConfigDSData{makeDS("")})
+	if strings.Contains(txt, "@strategy") {
+		t.Errorf("expected ATS 9 edge remap of delivery service without a strategy to have no strategy, actual '%v'", txt)
+	}
+
+	serverInfo.Type = "MID"
+	txt = MakeRemapDotConfig("myhost", "to0", "trafficops.example.net", 9, map[string]string{}, map[int]map[string]string{}, map[string]string{}, serverInfo, []RemapConfigDSData{makeDS("strategy-mydsname")})
+	if !strings.Contains(txt, "map http://origin.example.test http://origin.example.test @strategy=strategy-mydsname\n") {
+		t.Errorf("expected ATS 9 mid remap with strategy, actual '%v'", txt)
+	}
+}
@@ -0,0 +1,266 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// SNIParameterConfigFile is the config file of the delivery service profile parameters with the delivery service's TLS policy.
+const SNIParameterConfigFile = "sni.yaml"
+
+// The names of the delivery service profile parameters with the SNIParameterConfigFile config file.
+const SNIParamValidTLSVersions = "valid_tls_versions_in"
+const SNIParamHTTP2 = "http2"
+const SNIParamVerifyClient = "verify_client"
+const SNIParamTunnelRoute = "tunnel_route"
+
+// SNIMinATSMajorVersion is the first ATS major version with sni.yaml.
+const SNIMinATSMajorVersion = 8
+
+// SNIValidTLSVersionsMinATSMajorVersion is the first ATS major version with sni.yaml valid_tls_versions_in and http2.
+const SNIValidTLSVersionsMinATSMajorVersion = 9
+
+var sniTLSVersions = map[string]struct{}{"TLSv1": {}, "TLSv1_1": {}, "TLSv1_2": {}, "TLSv1_3": {}}
+var sniVerifyClients = map[string]struct{}{"NONE": {}, "MODERATE": {}, "STRICT": {}}
+
+// SNIDS is the delivery service data needed to make its sni.yaml entry.
+type SNIDS struct {
+	Type        tc.DSType
+	Protocol    int
+	ExampleURLs []string
+	// Params are the parameters on the delivery service's profile with the config file SNIParameterConfigFile.
+	Params map[string]string
+}
+
+// DeliveryServicesToSNIDSes returns the SNIDS of each delivery service, with the parameters of its profile in dsProfilesSNIParams, keyed by profile ID.
+func DeliveryServicesToSNIDSes(dses []tc.DeliveryServiceNullable, dsProfilesSNIParams map[int]map[string]string) map[tc.DeliveryServiceName]SNIDS {
+	sDSes := map[tc.DeliveryServiceName]SNIDS{}
+	for _, ds := range dses {
+		if ds.Type == nil || ds.Protocol == nil || ds.XMLID == nil {
+			if ds.XMLID == nil {
+				log.Errorln("atscfg.DeliveryServicesToSNIDSes got unknown DS with nil values! Skipping!")
+			} else {
+				log.Errorln("atscfg.DeliveryServicesToSNIDSes got DS '" + *ds.XMLID + "' with nil values! Skipping!")
+			}
+			continue
+		}
+		params := map[string]string{}
+		if ds.ProfileID != nil {
+			if profileParams, ok := dsProfilesSNIParams[*ds.ProfileID]; ok {
+				params = profileParams
+			}
+		}
+		sDSes[tc.DeliveryServiceName(*ds.XMLID)] = SNIDS{Type: *ds.Type, Protocol: *ds.Protocol, ExampleURLs: ds.ExampleURLs, Params: params}
+	}
+	return sDSes
+}
+
+// sniPolicy is the TLS policy of an sni.yaml entry.
+type sniPolicy struct {
+	ValidTLSVersions []string
+	HTTP2            string
+	VerifyClient     string
+	TunnelRoute      string
+}
+
+func (p sniPolicy) empty() bool {
+	return len(p.ValidTLSVersions) == 0 && p.HTTP2 == "" && p.VerifyClient == "" && p.TunnelRoute == ""
+}
+
+// MakeSNIDotYAML returns the ATS sni.yaml of the server, with an entry for the host names of each HTTPS delivery service with a TLS policy in its parameters.
+// Parameters which are malformed, or which the server's ATS version doesn't support, are logged and skipped.
+func MakeSNIDotYAML(
+	serverName tc.CacheName,
+	toToolName string, // tm.toolname global parameter (TODO: cache itself?)
+	toURL string, // tm.url global parameter (TODO: cache itself?)
+	atsMajorVer int,
+	dses map[tc.DeliveryServiceName]SNIDS,
+) string {
+	text := GenericHeaderComment(string(serverName), toToolName, toURL)
+
+	if atsMajorVer < SNIMinATSMajorVersion {
+		log.Errorln("sni.yaml: server '" + string(serverName) + "' ATS major version " + strconv.Itoa(atsMajorVer) + " doesn't support sni.yaml, which needs version " + strconv.Itoa(SNIMinATSMajorVersion) + "; generating empty file")
+		return text + "sni: []\n"
+	}
+
+	dsNames := []string{}
+	for dsName, _ := range dses {
+		dsNames = append(dsNames, string(dsName))
+	}
+	sort.Strings(dsNames)
+
+	fqdnPolicies := map[string]sniPolicy{}
+	fqdnDSes := map[string]tc.DeliveryServiceName{}
+	for _, dsNameStr := range dsNames {
+		dsName := tc.DeliveryServiceName(dsNameStr)
+		ds := dses[dsName]
+		if ds.Type.IsSteering() {
+			continue // Steering delivery service SSLs should not be on the edges.
+		}
+		if ds.Protocol == tc.DSProtocolHTTP {
+			continue
+		}
+		policy := makeSNIPolicy(dsName, ds.Params, atsMajorVer)
+		if policy.empty() {
+			continue
+		}
+		fqdns := getSNIFQDNs(dsName, ds.ExampleURLs)
+		if len(fqdns) == 0 {
+			log.Warnln("sni.yaml: delivery service '" + string(dsName) + "' has a TLS policy, but no example URLs, skipping")
+			continue
+		}
+		for _, fqdn := range fqdns {
+			if existingDS, ok := fqdnDSes[fqdn]; ok {
+				if existingDS == dsName {
+					continue
+				}
+				log.Errorln("sni.yaml: delivery services '" + string(existingDS) + "' and '" + string(dsName) + "' both have host '" + fqdn + "', using the policy of '" + string(existingDS) + "'")
+				continue
+			}
+			fqdnDSes[fqdn] = dsName
+			fqdnPolicies[fqdn] = policy
+		}
+	}
+
+	if len(fqdnPolicies) == 0 {
+		return text + "sni: []\n"
+	}
+
+	fqdns := []string{}
+	for fqdn, _ := range fqdnPolicies {
+		fqdns = append(fqdns, fqdn)
+	}
+	sort.Strings(fqdns)
+
+	text += "sni:\n"
+	for _, fqdn := range fqdns {
+		policy := fqdnPolicies[fqdn]
+		text += "  - fqdn: '" + fqdn + "'\n"
+		if len(policy.ValidTLSVersions) > 0 {
+			text += "    valid_tls_versions_in: [" + strings.Join(policy.ValidTLSVersions, ", ") + "]\n"
+		}
+		if policy.HTTP2 != "" {
+			if atsMajorVer >= SNIValidTLSVersionsMinATSMajorVersion {
+				text += "    http2: " + policy.HTTP2 + "\n"
+			} else {
+				text += "    disable_h2: " + strconv.FormatBool(policy.HTTP2 == "off") + "\n"
+			}
+		}
+		if policy.VerifyClient != "" {
+			text += "    verify_client: " + policy.VerifyClient + "\n"
+		}
+		if policy.TunnelRoute != "" {
+			text += "    tunnel_route: '" + policy.TunnelRoute + "'\n"
+		}
+	}
+	return text
+}
+
+// makeSNIPolicy returns the TLS policy of the given delivery service parameters, logging and skipping malformed and unsupported parameters.
+func makeSNIPolicy(dsName tc.DeliveryServiceName, params map[string]string, atsMajorVer int) sniPolicy {
+	policy := sniPolicy{}
+	for name, val := range params {
+		val = strings.TrimSpace(val)
+		switch name {
+		case SNIParamValidTLSVersions:
+			if atsMajorVer < SNIValidTLSVersionsMinATSMajorVersion {
+				log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' parameter '" + name + "' needs ATS " + strconv.Itoa(SNIValidTLSVersionsMinATSMajorVersion) + ", skipping")
+				continue
+			}
+			versions := []string{}
+			valid := true
+			for _, version := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ' ' }) {
+				if _, ok := sniTLSVersions[version]; !ok {
+					valid = false
+					break
+				}
+				versions = append(versions, version)
+			}
+			if !valid || len(versions) == 0 {
+				log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' parameter '" + name + "' value '" + val + "' is not a list of TLSv1, TLSv1_1, TLSv1_2, and TLSv1_3, skipping")
+				continue
+			}
+			sort.Strings(versions)
+			policy.ValidTLSVersions = versions
+		case SNIParamHTTP2:
+			switch strings.ToLower(val) {
+			case "on", "true":
+				policy.HTTP2 = "on"
+			case "off", "false":
+				policy.HTTP2 = "off"
+			default:
+				log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' parameter '" + name + "' value '" + val + "' is not on or off, skipping")
+			}
+		case SNIParamVerifyClient:
+			if _, ok := sniVerifyClients[strings.ToUpper(val)]; !ok {
+				log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' parameter '" + name + "' value '" + val + "' is not NONE, MODERATE, or STRICT, skipping")
+				continue
+			}
+			policy.VerifyClient = strings.ToUpper(val)
+		case SNIParamTunnelRoute:
+			host, port, err := net.SplitHostPort(val)
+			if err == nil {
+				_, err = strconv.ParseUint(port, 10, 16)
+			}
+			if err != nil || host == "" {
+				log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' parameter '" + name + "' value '" + val + "' is not a host:port, skipping")
+				continue
+			}
+			policy.TunnelRoute = val
+		default:
+			log.Warnln("sni.yaml: delivery service '" + string(dsName) + "' has unknown parameter '" + name + "', skipping")
+		}
+	}
+	return policy
+}
+
+// getSNIFQDNs returns the sni.yaml fqdns of a delivery service. The first example URL is the host of the delivery service's certificate, whose wildcard covers the routing names of all its cache servers, as in ssl_multicert.config. Other example URLs are exact hosts.
+func getSNIFQDNs(dsName tc.DeliveryServiceName, exampleURLs []string) []string {
+	fqdns := []string{}
+	wildcardDomain := ""
+	for i, exampleURL := range exampleURLs {
+		u, err := url.Parse(exampleURL)
+		if err != nil || u.Hostname() == "" {
+			log.Errorln("sni.yaml: delivery service '" + string(dsName) + "' has malformed example URL '" + exampleURL + "', skipping")
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if i == 0 {
+			if dot := strings.Index(host, "."); dot > 0 {
+				wildcardDomain = host[dot:]
+				fqdns = append(fqdns, "*"+wildcardDomain)
+				continue
+			}
+		}
+		if wildcardDomain != "" && strings.HasSuffix(host, wildcardDomain) && !strings.Contains(strings.TrimSuffix(host, wildcardDomain), ".") {
+			continue // covered by the wildcard
+		}
+		fqdns = append(fqdns, host)
+	}
+	return fqdns
+}
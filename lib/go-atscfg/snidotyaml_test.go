@@ -0,0 +1,115 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestMakeSNIDotYAML(t *testing.T) {
+	serverName := tc.CacheName("server0")
+	toToolName := "to0"
+	toURL := "trafficops.example.net"
+
+	dses := []tc.DeliveryServiceNullable{}
+	makeDS := func(name string, protocol int, profileID int, exampleURLs []string) tc.DeliveryServiceNullable {
+		ds := tc.DeliveryServiceNullable{}
+		ds.XMLID = util.StrPtr(name)
+		dsType := tc.DSTypeHTTP
+		ds.Type = &dsType
+		ds.Protocol = util.IntPtr(protocol)
+		ds.ProfileID = util.IntPtr(profileID)
+		ds.ExampleURLs = exampleURLs
+		return ds
+	}
+	dses = append(dses, makeDS("ds-strict", tc.DSProtocolHTTPS, 1, []string{"https://cdn.ds-strict.mycdn.example.net", "https://www.customer.example.com"}))
+	dses = append(dses, makeDS("ds-default", tc.DSProtocolHTTPAndHTTPS, 2, []string{"https://cdn.ds-default.mycdn.example.net"}))
+	dses = append(dses, makeDS("ds-http", tc.DSProtocolHTTP, 1, []string{"http://cdn.ds-http.mycdn.example.net"}))
+	dses = append(dses, makeDS("ds-bad", tc.DSProtocolHTTPS, 3, []string{"https://cdn.ds-bad.mycdn.example.net"}))
+
+	dsProfilesSNIParams := map[int]map[string]string{
+		1: {
+			SNIParamValidTLSVersions: "TLSv1_3, TLSv1_2",
+			SNIParamHTTP2:            "off",
+			SNIParamVerifyClient:     "strict",
+			SNIParamTunnelRoute:      "backend.example.net:443",
+		},
+		3: {
+			SNIParamValidTLSVersions: "SSLv3",
+			SNIParamVerifyClient:     "sometimes",
+			SNIParamTunnelRoute:      "backend.example.net",
+		},
+	}
+
+	sniDSes := DeliveryServicesToSNIDSes(dses, dsProfilesSNIParams)
+	txt := MakeSNIDotYAML(serverName, toToolName, toURL, 9, sniDSes)
+
+	testComment(t, txt, string(serverName), toToolName, toURL)
+
+	expected := `sni:
+  - fqdn: '*.ds-strict.mycdn.example.net'
+    valid_tls_versions_in: [TLSv1_2, TLSv1_3]
+    http2: off
+    verify_client: STRICT
+    tunnel_route: 'backend.example.net:443'
+  - fqdn: 'www.customer.example.com'
+    valid_tls_versions_in: [TLSv1_2, TLSv1_3]
+    http2: off
+    verify_client: STRICT
+    tunnel_route: 'backend.example.net:443'
+`
+	if !strings.HasSuffix(txt, expected) {
+		t.Errorf("expected '%v', actual '%v'", expected, txt)
+	}
+	if strings.Contains(txt, "ds-default") || strings.Contains(txt, "ds-http") || strings.Contains(txt, "ds-bad") {
+		t.Errorf("expected no entries for delivery services without a valid policy or HTTPS, actual '%v'", txt)
+	}
+}
+
+func TestMakeSNIDotYAMLATS8(t *testing.T) {
+	dses := map[tc.DeliveryServiceName]SNIDS{
+		"ds0": SNIDS{
+			Type:        tc.DSTypeHTTP,
+			Protocol:    tc.DSProtocolHTTPS,
+			ExampleURLs: []string{"https://cdn.ds0.mycdn.example.net"},
+			Params: map[string]string{
+				SNIParamValidTLSVersions: "TLSv1_2",
+				SNIParamHTTP2:            "off",
+			},
+		},
+	}
+
+	txt := MakeSNIDotYAML("server0", "to0", "trafficops.example.net", 8, dses)
+	if strings.Contains(txt, SNIParamValidTLSVersions) {
+		t.Errorf("expected ATS 8 to have no valid_tls_versions_in, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "  - fqdn: '*.ds0.mycdn.example.net'\n    disable_h2: true\n") {
+		t.Errorf("expected ATS 8 to disable HTTP/2 with disable_h2, actual '%v'", txt)
+	}
+
+	txt = MakeSNIDotYAML("server0", "to0", "trafficops.example.net", 7, dses)
+	if !strings.HasSuffix(txt, "sni: []\n") {
+		t.Errorf("expected ATS 7 to have no entries, actual '%v'", txt)
+	}
+}
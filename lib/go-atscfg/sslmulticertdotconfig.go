@@ -67,24 +67,30 @@ func MakeSSLMultiCertDotConfig(
 			continue // TODO warn? error? Perl doesn't
 		}
 
-		hostName := ds.ExampleURLs[0] // first one is the one we want
-
-		scheme := "https://"
-		if !strings.HasPrefix(hostName, scheme) {
-			scheme = "http://"
-		}
-		newHost := hostName
-		if len(hostName) < len(scheme) {
-			log.Errorln("MakeSSLMultiCertDotConfig got ds '" + string(dsName) + "' example url '" + hostName + "' with no scheme! ssl_multicert.config will likely be malformed!")
-		} else {
-			newHost = hostName[len(scheme):]
-		}
-		keyName := newHost + ".key"
-
-		newHost = strings.Replace(newHost, ".", "_", -1)
-
-		cerName := newHost + "_cert.cer"
+		cerName, keyName := sslMultiCertFileNames(dsName, ds.ExampleURLs)
 		text += `ssl_cert_name=` + cerName + "\t" + ` ssl_key_name=` + keyName + "\n"
 	}
 	return text
 }
+
+// sslMultiCertFileNames returns the names of the certificate and key files of the delivery service with the given example URLs, which must not be empty.
+func sslMultiCertFileNames(dsName tc.DeliveryServiceName, exampleURLs []string) (string, string) {
+	hostName := exampleURLs[0] // first one is the one we want
+
+	scheme := "https://"
+	if !strings.HasPrefix(hostName, scheme) {
+		scheme = "http://"
+	}
+	newHost := hostName
+	if len(hostName) < len(scheme) {
+		log.Errorln("MakeSSLMultiCertDotConfig got ds '" + string(dsName) + "' example url '" + hostName + "' with no scheme! ssl_multicert.config will likely be malformed!")
+	} else {
+		newHost = hostName[len(scheme):]
+	}
+	keyName := newHost + ".key"
+
+	newHost = strings.Replace(newHost, ".", "_", -1)
+
+	cerName := newHost + "_cert.cer"
+	return cerName, keyName
+}
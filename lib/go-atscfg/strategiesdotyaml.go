@@ -0,0 +1,423 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const StrategiesFileName = "strategies.yaml"
+
+// StrategiesMinATSMajorVersion is the first ATS major version with strategies.yaml next-hop strategies.
+const StrategiesMinATSMajorVersion = 9
+
+const StrategyPolicyConsistentHash = "consistent_hash"
+const StrategyPolicyFirstLive = "first_live"
+const StrategyPolicyRRIP = "rr_ip"
+const StrategyPolicyRRStrict = "rr_strict"
+const StrategyPolicyLatched = "latched"
+
+const StrategyHashKeyPath = "path"
+const StrategyHashKeyPathQuery = "path+query"
+
+const StrategyRingModeExhaust = "exhaust_ring"
+
+// StrategySimpleRetryResponseCode is the response code ATS parent.config retries with parent_retry=simple_retry.
+const StrategySimpleRetryResponseCode = 404
+
+// StrategyUnavailableRetryResponseCode is the response code ATS parent.config retries with parent_retry=unavailable_server_retry, if there are no unavailable_server_retry_responses.
+const StrategyUnavailableRetryResponseCode = 503
+
+const ParentRetrySimple = "simple_retry"
+const ParentRetryUnavailable = "unavailable_server_retry"
+const ParentRetryBoth = "both"
+
+// strategy is a strategies.yaml next-hop strategy, equivalent to a parent.config line.
+type strategy struct {
+	Name          string
+	Policy        string
+	HashKey       string
+	GoDirect      bool
+	ParentIsProxy bool
+	Scheme        string
+	// Groups are the parent rings, in the order they're tried.
+	Groups [][]ParentInfo
+	// MaxSimpleRetries is the max_simple_retries, or empty if the strategy doesn't retry on SimpleRetryCodes.
+	MaxSimpleRetries string
+	SimpleRetryCodes []int
+	// MaxUnavailableRetries is the max_unavailable_retries, or empty if the strategy doesn't mark parents down on UnavailableRetryCodes.
+	MaxUnavailableRetries string
+	UnavailableRetryCodes []int
+}
+
+type strategySortByName []strategy
+
+func (s strategySortByName) Len() int           { return len(s) }
+func (s strategySortByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s strategySortByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+// StrategyName returns the name of the strategies.yaml next-hop strategy of the delivery service with the given name.
+func StrategyName(dsName tc.DeliveryServiceName) string {
+	return "strategy-" + string(dsName)
+}
+
+// MakeStrategiesDotYAML returns the ATS 9 strategies.yaml of the server, with a next-hop strategy equivalent to each parent.config line of a delivery service with parents.
+// It takes the same data as MakeParentDotConfig. Delivery services which go directly to the origin, or to an origin shield, have no strategy, and keep using parent.config.
+func MakeStrategiesDotYAML(
+	serverInfo *ServerInfo,
+	atsMajorVer int,
+	toToolName string, // tm.toolname global parameter (TODO: cache itself?)
+	toURL string, // tm.url global parameter (TODO: cache itself?)
+	parentConfigDSes []ParentConfigDSTopLevel,
+	serverParams map[string]string,
+	parentInfos map[OriginHost][]ParentInfo,
+) string {
+	nameVersionStr := GetNameVersionStringFromToolNameAndURL(toToolName, toURL)
+	hdr := HeaderCommentWithTOVersionStr(serverInfo.HostName, nameVersionStr)
+
+	if atsMajorVer < StrategiesMinATSMajorVersion {
+		log.Warnln("strategies.yaml: server '" + serverInfo.HostName + "' ATS major version " + strconv.Itoa(atsMajorVer) + " doesn't support strategies, which need version " + strconv.Itoa(StrategiesMinATSMajorVersion) + "; remap.config won't use them")
+	}
+
+	strategies, _ := makeStrategies(serverInfo, parentConfigDSes, serverParams, parentInfos)
+	if len(strategies) == 0 {
+		return hdr + "strategies: []\n"
+	}
+
+	text := hdr + "strategies:\n"
+	for _, st := range strategies {
+		text += formatStrategy(st)
+	}
+	return text
+}
+
+// HasStrategiesLocation returns whether the server profile parameters have the "location" parameter of strategies.yaml.
+// Without it, strategies.yaml isn't generated or installed, so remap.config must not use its strategies, or Traffic Server will fail to load it.
+func HasStrategiesLocation(serverProfileParams []tc.Parameter) bool {
+	for _, param := range serverProfileParams {
+		if param.ConfigFile == StrategiesFileName && param.Name == "location" {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeDSStrategyNames returns the name of the next-hop strategy of each delivery service with one, for RemapConfigDSData.Strategy.
+// It takes the same data as MakeStrategiesDotYAML. If atsMajorVer is less than StrategiesMinATSMajorVersion, no delivery services have strategies.
+// Callers must only use the strategies if HasStrategiesLocation is true for the server's profile.
+func MakeDSStrategyNames(
+	serverInfo *ServerInfo,
+	atsMajorVer int,
+	parentConfigDSes []ParentConfigDSTopLevel,
+	serverParams map[string]string,
+	parentInfos map[OriginHost][]ParentInfo,
+) map[tc.DeliveryServiceName]string {
+	if atsMajorVer < StrategiesMinATSMajorVersion {
+		return map[tc.DeliveryServiceName]string{}
+	}
+	_, dsStrategies := makeStrategies(serverInfo, parentConfigDSes, serverParams, parentInfos)
+	return dsStrategies
+}
+
+// makeStrategies returns the strategies of the server, sorted by name, and the name of the strategy of each delivery service.
+// This follows the same logic as MakeParentDotConfig: delivery services which share an origin with another use the strategy of the one parent.config uses.
+func makeStrategies(
+	serverInfo *ServerInfo,
+	parentConfigDSes []ParentConfigDSTopLevel,
+	serverParams map[string]string,
+	parentInfos map[OriginHost][]ParentInfo,
+) ([]strategy, map[tc.DeliveryServiceName]string) {
+	strategies := []strategy{}
+	dsStrategies := map[tc.DeliveryServiceName]string{}
+
+	// originStrategies is the name of the strategy of each processed origin, or empty if it has none.
+	originStrategies := map[string]string{}
+	addStrategy := func(ds ParentConfigDSTopLevel, st *strategy) {
+		if st == nil {
+			originStrategies[ds.OriginFQDN] = ""
+			return
+		}
+		st.Name = StrategyName(ds.Name)
+		strategies = append(strategies, *st)
+		originStrategies[ds.OriginFQDN] = st.Name
+		dsStrategies[ds.Name] = st.Name
+	}
+
+	if serverInfo.IsTopLevelCache() {
+		for _, ds := range parentConfigDSes {
+			orgURI, err := getStrategyOriginURI(ds)
+			if err != nil {
+				continue // logged by MakeParentDotConfig
+			}
+			if name, ok := originStrategies[ds.OriginFQDN]; ok {
+				if name != "" {
+					dsStrategies[ds.Name] = name
+				}
+				continue
+			}
+
+			if ds.Topology != "" {
+				addStrategy(ds, getTopologyStrategy(ds, orgURI, serverParams, parentInfos))
+			} else if ds.OriginShield == "" && ds.MultiSiteOrigin {
+				addStrategy(ds, getMSOStrategy(ds, orgURI, getTopLevelParentQStr(ds), parentInfos))
+			} else {
+				addStrategy(ds, nil)
+			}
+		}
+	} else {
+		queryStringHandling := serverParams[ParentConfigParamQStringHandling]
+
+		dses := make([]ParentConfigDSTopLevel, len(parentConfigDSes))
+		copy(dses, parentConfigDSes)
+		sort.Sort(ParentConfigDSTopLevelSortByName(dses))
+
+		for _, ds := range dses {
+			if ds.OriginFQDN == "" {
+				continue
+			}
+			orgURI, err := getStrategyOriginURI(ds)
+			if err != nil {
+				continue // logged by MakeParentDotConfig
+			}
+			if name, ok := originStrategies[ds.OriginFQDN]; ok {
+				if name != "" {
+					dsStrategies[ds.Name] = name
+				}
+				continue
+			}
+
+			if dsType := tc.DSType(ds.Type); dsType == tc.DSTypeHTTPNoCache || dsType == tc.DSTypeHTTPLive || dsType == tc.DSTypeDNSLive {
+				addStrategy(ds, nil)
+			} else if ds.Topology != "" && len(ds.TopologyParents) == 0 {
+				addStrategy(ds, getTopologyStrategy(ds, orgURI, serverParams, parentInfos))
+			} else {
+				parents := parentInfos[DeliveryServicesAllParentsKey]
+				if ds.Topology != "" {
+					parents = ds.TopologyParents
+				}
+				addStrategy(ds, getParentsStrategy(ds, parents, getParentQStr(ds, queryStringHandling)))
+			}
+		}
+	}
+
+	sort.Sort(strategySortByName(strategies))
+	return strategies, dsStrategies
+}
+
+// getStrategyOriginURI returns the parsed origin of the delivery service, with the port of its scheme if it has none, as MakeParentDotConfig uses it.
+func getStrategyOriginURI(ds ParentConfigDSTopLevel) (*url.URL, error) {
+	orgURI, err := url.Parse(ds.OriginFQDN)
+	if err != nil {
+		return nil, err
+	}
+	if orgURI.Port() == "" {
+		if orgURI.Scheme == "http" {
+			orgURI.Host += ":80"
+		} else if orgURI.Scheme == "https" {
+			orgURI.Host += ":443"
+		}
+	}
+	return orgURI, nil
+}
+
+// getTopLevelParentQStr returns the qstring= value of a multi-site origin parent.config line on a top-level cache.
+func getTopLevelParentQStr(ds ParentConfigDSTopLevel) string {
+	if ds.QStringHandling == "" && ds.MSOAlgorithm == tc.AlgorithmConsistentHash && ds.QStringIgnore == tc.QStringIgnoreUseInCacheKeyAndPassUp {
+		return "consider"
+	}
+	return "ignore"
+}
+
+// getTopologyStrategy returns the strategy equivalent to getTopologyParentLine, or nil if the line goes directly to the origin or origin shield.
+func getTopologyStrategy(ds ParentConfigDSTopLevel, orgURI *url.URL, serverParams map[string]string, parentInfos map[OriginHost][]ParentInfo) *strategy {
+	if len(ds.TopologyParents) > 0 {
+		return getParentsStrategy(ds, ds.TopologyParents, getParentQStr(ds, serverParams[ParentConfigParamQStringHandling]))
+	}
+	if ds.OriginShield != "" {
+		return nil
+	}
+	if ds.MultiSiteOrigin {
+		return getMSOStrategy(ds, orgURI, getTopLevelParentQStr(ds), parentInfos)
+	}
+	return nil
+}
+
+// getParentsStrategy returns the strategy of a parent.config line whose parents are caches, or nil if there are no parents.
+func getParentsStrategy(ds ParentConfigDSTopLevel, parentInfos []ParentInfo, parentQStr string) *strategy {
+	primary, secondary := getParentGroups(ds, parentInfos)
+	groups := [][]ParentInfo{}
+	for _, group := range [][]ParentInfo{primary, secondary} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		log.Warnln("strategies.yaml: delivery service '" + string(ds.Name) + "' has no parents, not making a strategy")
+		return nil
+	}
+	return &strategy{
+		Policy:        StrategyPolicyConsistentHash,
+		HashKey:       getStrategyHashKey(parentQStr),
+		GoDirect:      false,
+		ParentIsProxy: true,
+		Scheme:        "http",
+		Groups:        groups,
+	}
+}
+
+// getMSOStrategy returns the strategy of a multi-site origin parent.config line, whose parents are the origins, or nil if there are no origins or the algorithm has no strategy policy.
+func getMSOStrategy(ds ParentConfigDSTopLevel, orgURI *url.URL, parentQStr string, parentInfos map[OriginHost][]ParentInfo) *strategy {
+	policy, ok := getStrategyPolicy(ds.MSOAlgorithm)
+	if !ok {
+		log.Errorln("strategies.yaml: delivery service '" + string(ds.Name) + "' has unknown " + ParentConfigParamMSOAlgorithm + " '" + ds.MSOAlgorithm + "', not making a strategy")
+		return nil
+	}
+
+	// getMSOParentStrs writes all parents in the parent= list, so they're all one group.
+	primary, secondary, null := getMSOParentGroups(ds, parentInfos[OriginHost(orgURI.Hostname())])
+	group := append(append(append([]ParentInfo{}, primary...), secondary...), null...)
+	if len(group) == 0 {
+		log.Warnln("strategies.yaml: delivery service '" + string(ds.Name) + "' has no parent servers, not making a strategy")
+		return nil
+	}
+
+	scheme := orgURI.Scheme
+	if scheme != "https" {
+		scheme = "http"
+	}
+
+	st := &strategy{
+		Policy:        policy,
+		HashKey:       getStrategyHashKey(parentQStr),
+		GoDirect:      false,
+		ParentIsProxy: false,
+		Scheme:        scheme,
+		Groups:        [][]ParentInfo{group},
+	}
+
+	switch ds.MSOParentRetry {
+	case ParentRetrySimple, ParentRetryUnavailable, ParentRetryBoth:
+	case "":
+		return st
+	default:
+		log.Errorln("strategies.yaml: delivery service '" + string(ds.Name) + "' has unknown " + ParentConfigParamMSOParentRetry + " '" + ds.MSOParentRetry + "', not retrying")
+		return st
+	}
+	if ds.MSOParentRetry == ParentRetrySimple || ds.MSOParentRetry == ParentRetryBoth {
+		st.MaxSimpleRetries = ds.MSOMaxSimpleRetries
+		st.SimpleRetryCodes = []int{StrategySimpleRetryResponseCode}
+	}
+	if ds.MSOParentRetry == ParentRetryUnavailable || ds.MSOParentRetry == ParentRetryBoth {
+		st.MaxUnavailableRetries = ds.MSOMaxUnavailableServerRetries
+		st.UnavailableRetryCodes = []int{StrategyUnavailableRetryResponseCode}
+		if unavailableServerRetryResponsesValid(ds.MSOUnavailableServerRetryResponses) {
+			st.UnavailableRetryCodes = parseRetryResponses(ds.MSOUnavailableServerRetryResponses)
+		}
+	}
+	return st
+}
+
+// getStrategyPolicy returns the strategy policy equivalent to the given parent.config round_robin value, and whether one exists.
+func getStrategyPolicy(roundRobin string) (string, bool) {
+	switch roundRobin {
+	case tc.AlgorithmConsistentHash:
+		return StrategyPolicyConsistentHash, true
+	case "true":
+		return StrategyPolicyRRIP, true
+	case "strict":
+		return StrategyPolicyRRStrict, true
+	case "false":
+		return StrategyPolicyFirstLive, true
+	case "latched":
+		return StrategyPolicyLatched, true
+	}
+	return "", false
+}
+
+// getStrategyHashKey returns the strategy hash_key equivalent to the given parent.config qstring value.
+func getStrategyHashKey(parentQStr string) string {
+	if parentQStr == "consider" {
+		return StrategyHashKeyPathQuery
+	}
+	return StrategyHashKeyPath
+}
+
+// parseRetryResponses returns the codes of a valid unavailable_server_retry_responses parameter, e.g. "502,503".
+func parseRetryResponses(s string) []int {
+	codes := []int{}
+	for _, codeStr := range strings.Split(strings.Trim(strings.TrimSpace(s), `"`), ",") {
+		code, err := strconv.Atoi(codeStr)
+		if err != nil {
+			continue // can't happen, the parameter was validated
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func formatStrategy(st strategy) string {
+	txt := "  - strategy: '" + st.Name + "'\n"
+	txt += "    policy: " + st.Policy + "\n"
+	txt += "    hash_key: " + st.HashKey + "\n"
+	txt += "    go_direct: " + strconv.FormatBool(st.GoDirect) + "\n"
+	txt += "    parent_is_proxy: " + strconv.FormatBool(st.ParentIsProxy) + "\n"
+	txt += "    groups:\n"
+	for _, group := range st.Groups {
+		for i, parent := range group {
+			prefix := "        "
+			if i == 0 {
+				prefix = "      - "
+			}
+			txt += prefix + "- host: " + parent.hostName() + "\n"
+			txt += "          protocol:\n"
+			txt += "            - scheme: " + st.Scheme + "\n"
+			txt += "              port: " + strconv.Itoa(parent.Port) + "\n"
+			txt += "          weight: " + parent.Weight + "\n"
+		}
+	}
+	txt += "    scheme: " + st.Scheme + "\n"
+	txt += "    failover:\n"
+	txt += "      ring_mode: " + StrategyRingModeExhaust + "\n"
+	if st.MaxSimpleRetries != "" {
+		txt += "      max_simple_retries: " + st.MaxSimpleRetries + "\n"
+		txt += "      response_codes: " + formatIntList(st.SimpleRetryCodes) + "\n"
+	}
+	if st.MaxUnavailableRetries != "" {
+		txt += "      max_unavailable_retries: " + st.MaxUnavailableRetries + "\n"
+		txt += "      markdown_codes: " + formatIntList(st.UnavailableRetryCodes) + "\n"
+	}
+	txt += "      health_check:\n"
+	txt += "        - passive\n"
+	return txt
+}
+
+func formatIntList(nums []int) string {
+	strs := []string{}
+	for _, num := range nums {
+		strs = append(strs, strconv.Itoa(num))
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
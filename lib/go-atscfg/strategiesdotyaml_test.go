@@ -0,0 +1,275 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// parseParentDotConfig returns the key=value fields of each parent.config line, keyed by dest_domain.
+func parseParentDotConfig(txt string) map[string]map[string]string {
+	lines := map[string]map[string]string{}
+	for _, line := range strings.Split(txt, "\n") {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := map[string]string{}
+		for _, field := range strings.Fields(line) {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			fields[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+		lines[fields["dest_domain"]] = fields
+	}
+	return lines
+}
+
+// testStrategiesEquivalent tests that the strategies made from the given data are equivalent to the parent.config made from it.
+func testStrategiesEquivalent(t *testing.T, serverInfo *ServerInfo, dses []ParentConfigDSTopLevel, serverParams map[string]string, parentInfos map[OriginHost][]ParentInfo) map[tc.DeliveryServiceName]string {
+	parentTxt := MakeParentDotConfig(serverInfo, 9, "myToolName", "https://myto.example.net", dses, serverParams, parentInfos)
+	lines := parseParentDotConfig(parentTxt)
+
+	strategies, dsStrategies := makeStrategies(serverInfo, dses, serverParams, parentInfos)
+	strategiesByName := map[string]strategy{}
+	for _, st := range strategies {
+		strategiesByName[st.Name] = st
+	}
+
+	for _, ds := range dses {
+		orgURI, err := getStrategyOriginURI(ds)
+		if err != nil {
+			t.Fatalf("parsing origin '%v': %v", ds.OriginFQDN, err)
+		}
+		stName, hasStrategy := dsStrategies[ds.Name]
+		line, ok := lines[orgURI.Hostname()]
+		if !ok || line["go_direct"] == "true" {
+			if hasStrategy {
+				t.Errorf("ds '%v' parent.config has no parents, expected no strategy, actual '%v'", ds.Name, stName)
+			}
+			continue
+		}
+		if !hasStrategy {
+			t.Errorf("ds '%v' parent.config has parents, expected a strategy, actual none: '%v'", ds.Name, line)
+			continue
+		}
+		st, ok := strategiesByName[stName]
+		if !ok {
+			t.Errorf("ds '%v' strategy '%v' expected in strategies, actual missing", ds.Name, stName)
+			continue
+		}
+
+		if actual := formatParents(st.Groups[0]); actual != line["parent"] {
+			t.Errorf("ds '%v' strategy first group expected parent.config parent '%v', actual '%v'", ds.Name, line["parent"], actual)
+		}
+		secondary := ""
+		if len(st.Groups) > 1 {
+			secondary = formatParents(st.Groups[1])
+		}
+		if secondary != line["secondary_parent"] {
+			t.Errorf("ds '%v' strategy second group expected parent.config secondary_parent '%v', actual '%v'", ds.Name, line["secondary_parent"], secondary)
+		}
+		if policy, _ := getStrategyPolicy(line["round_robin"]); policy != st.Policy {
+			t.Errorf("ds '%v' strategy policy expected '%v' from round_robin '%v', actual '%v'", ds.Name, policy, line["round_robin"], st.Policy)
+		}
+		if hashKey := getStrategyHashKey(line["qstring"]); hashKey != st.HashKey {
+			t.Errorf("ds '%v' strategy hash_key expected '%v' from qstring '%v', actual '%v'", ds.Name, hashKey, line["qstring"], st.HashKey)
+		}
+		if parentIsProxy := line["parent_is_proxy"] != "false"; parentIsProxy != st.ParentIsProxy {
+			t.Errorf("ds '%v' strategy parent_is_proxy expected %v, actual %v", ds.Name, parentIsProxy, st.ParentIsProxy)
+		}
+		if line["max_simple_retries"] != st.MaxSimpleRetries && line["parent_retry"] != ParentRetryUnavailable {
+			t.Errorf("ds '%v' strategy max_simple_retries expected '%v', actual '%v'", ds.Name, line["max_simple_retries"], st.MaxSimpleRetries)
+		}
+		if line["max_unavailable_server_retries"] != st.MaxUnavailableRetries && line["parent_retry"] != ParentRetrySimple {
+			t.Errorf("ds '%v' strategy max_unavailable_retries expected '%v', actual '%v'", ds.Name, line["max_unavailable_server_retries"], st.MaxUnavailableRetries)
+		}
+	}
+	return dsStrategies
+}
+
+func makeTestParentInfo(host string, cgID int, serverInfo *ServerInfo) ParentInfo {
+	return ParentInfo{
+		Host:            host,
+		Port:            80,
+		Domain:          "example.net",
+		Weight:          "0.999",
+		Rank:            1,
+		IP:              "192.0.2.1",
+		PrimaryParent:   cgID == serverInfo.ParentCacheGroupID,
+		SecondaryParent: cgID == serverInfo.SecondaryParentCacheGroupID,
+	}
+}
+
+func TestMakeStrategiesEdge(t *testing.T) {
+	serverInfo := &ServerInfo{
+		CacheGroupID:                  42,
+		CDN:                           "myCDN",
+		DomainName:                    "example.net",
+		HostName:                      "myserver",
+		ID:                            44,
+		ParentCacheGroupID:            45,
+		ParentCacheGroupType:          "MID_LOC",
+		SecondaryParentCacheGroupID:   47,
+		SecondaryParentCacheGroupType: "MID_LOC",
+		Type:                          "EDGE",
+	}
+
+	dses := []ParentConfigDSTopLevel{
+		{ParentConfigDS: ParentConfigDS{Name: "ds-http", OriginFQDN: "http://origin0.example.net", Type: tc.DSTypeHTTP, QStringIgnore: tc.QStringIgnoreUseInCacheKeyAndPassUp}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-same-origin", OriginFQDN: "http://origin0.example.net", Type: tc.DSTypeHTTP}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-live", OriginFQDN: "http://origin1.example.net", Type: tc.DSTypeHTTPLive}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-dns", OriginFQDN: "https://origin2.example.net", Type: tc.DSTypeDNS, QStringHandling: "ignore"}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-topology", OriginFQDN: "http://origin3.example.net:8080", Type: tc.DSTypeHTTP, Topology: "mytopology", TopologyParents: []ParentInfo{
+			{Host: "topo-mid", Port: 80, Domain: "example.net", Weight: "0.999", Rank: 1, PrimaryParent: true},
+		}}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-topology-top", OriginFQDN: "http://origin4.example.net", Type: tc.DSTypeHTTP, Topology: "mytopology"}},
+	}
+
+	parentInfos := map[OriginHost][]ParentInfo{
+		DeliveryServicesAllParentsKey: []ParentInfo{
+			makeTestParentInfo("mid-b", 45, serverInfo),
+			makeTestParentInfo("mid-a", 45, serverInfo),
+			makeTestParentInfo("mid-c", 47, serverInfo),
+		},
+	}
+
+	dsStrategies := testStrategiesEquivalent(t, serverInfo, dses, map[string]string{}, parentInfos)
+
+	expected := map[tc.DeliveryServiceName]string{
+		"ds-http":        "strategy-ds-http",
+		"ds-same-origin": "strategy-ds-http",
+		"ds-dns":         "strategy-ds-dns",
+		"ds-topology":    "strategy-ds-topology",
+	}
+	if len(dsStrategies) != len(expected) {
+		t.Errorf("expected strategies %v, actual %v", expected, dsStrategies)
+	}
+	for ds, name := range expected {
+		if dsStrategies[ds] != name {
+			t.Errorf("ds '%v' expected strategy '%v', actual '%v'", ds, name, dsStrategies[ds])
+		}
+	}
+
+	txt := MakeStrategiesDotYAML(serverInfo, 9, "myToolName", "https://myto.example.net", dses, map[string]string{}, parentInfos)
+	testComment(t, txt, "myserver", "myToolName", "https://myto.example.net")
+	if strings.Count(txt, "  - strategy: ") != 3 {
+		t.Errorf("expected 3 strategies, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "  - strategy: 'strategy-ds-http'\n    policy: consistent_hash\n    hash_key: path+query\n    go_direct: false\n    parent_is_proxy: true\n") {
+		t.Errorf("expected ds-http strategy with consistent hash of path and query, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "      - - host: mid-a.example.net\n          protocol:\n            - scheme: http\n              port: 80\n          weight: 0.999\n        - host: mid-b.example.net\n") {
+		t.Errorf("expected primary parents sorted in the first group, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "      - - host: mid-c.example.net\n") {
+		t.Errorf("expected secondary parent in the second group, actual '%v'", txt)
+	}
+}
+
+func TestMakeStrategiesMid(t *testing.T) {
+	serverInfo := &ServerInfo{
+		CacheGroupID:                45,
+		CDN:                         "myCDN",
+		DomainName:                  "example.net",
+		HostName:                    "mymid",
+		ID:                          46,
+		ParentCacheGroupID:          InvalidID,
+		SecondaryParentCacheGroupID: InvalidID,
+		Type:                        "MID",
+	}
+
+	dses := []ParentConfigDSTopLevel{
+		{
+			ParentConfigDS:                     ParentConfigDS{Name: "ds-mso", OriginFQDN: "http://mso.example.net", Type: tc.DSTypeHTTP, MultiSiteOrigin: true, QStringIgnore: tc.QStringIgnoreUseInCacheKeyAndPassUp},
+			MSOAlgorithm:                       tc.AlgorithmConsistentHash,
+			MSOParentRetry:                     ParentRetryBoth,
+			MSOUnavailableServerRetryResponses: `"502,503"`,
+			MSOMaxSimpleRetries:                "2",
+			MSOMaxUnavailableServerRetries:     "3",
+		},
+		{
+			ParentConfigDS: ParentConfigDS{Name: "ds-mso-rr", OriginFQDN: "https://mso-rr.example.net", Type: tc.DSTypeHTTP, MultiSiteOrigin: true},
+			MSOAlgorithm:   "strict",
+		},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-shield", OriginFQDN: "http://shield.example.net", Type: tc.DSTypeHTTP, OriginShield: "shield0.example.net:80|1;"}},
+		{ParentConfigDS: ParentConfigDS{Name: "ds-direct", OriginFQDN: "http://direct.example.net", Type: tc.DSTypeHTTP}},
+	}
+
+	parentInfos := map[OriginHost][]ParentInfo{
+		"mso.example.net": []ParentInfo{
+			{Host: "org0", Port: 80, Domain: "mso.example.net", Weight: "1", Rank: 1, PrimaryParent: true},
+			{Host: "org1", Port: 80, Domain: "mso.example.net", Weight: "1", Rank: 2, SecondaryParent: true},
+			{Host: "org2", Port: 80, Domain: "mso.example.net", Weight: "1", Rank: 3},
+		},
+		"mso-rr.example.net": []ParentInfo{
+			{Host: "org3", Port: 443, Domain: "mso-rr.example.net", Weight: "1", Rank: 1},
+		},
+	}
+
+	dsStrategies := testStrategiesEquivalent(t, serverInfo, dses, map[string]string{}, parentInfos)
+	if len(dsStrategies) != 2 || dsStrategies["ds-mso"] != "strategy-ds-mso" || dsStrategies["ds-mso-rr"] != "strategy-ds-mso-rr" {
+		t.Errorf("expected strategies for the multi-site origin delivery services only, actual %v", dsStrategies)
+	}
+
+	txt := MakeStrategiesDotYAML(serverInfo, 9, "myToolName", "https://myto.example.net", dses, map[string]string{}, parentInfos)
+	if !strings.Contains(txt, "    failover:\n      ring_mode: exhaust_ring\n      max_simple_retries: 2\n      response_codes: [404]\n      max_unavailable_retries: 3\n      markdown_codes: [502, 503]\n") {
+		t.Errorf("expected ds-mso failover from its retry parameters, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "  - strategy: 'strategy-ds-mso-rr'\n    policy: rr_strict\n") {
+		t.Errorf("expected ds-mso-rr strict round robin policy, actual '%v'", txt)
+	}
+	if !strings.Contains(txt, "            - scheme: https\n              port: 443\n") {
+		t.Errorf("expected ds-mso-rr https origin, actual '%v'", txt)
+	}
+}
+
+func TestMakeStrategiesDotYAMLEmpty(t *testing.T) {
+	serverInfo := &ServerInfo{HostName: "mymid", ParentCacheGroupID: InvalidID, SecondaryParentCacheGroupID: InvalidID, Type: "MID"}
+	txt := MakeStrategiesDotYAML(serverInfo, 9, "myToolName", "https://myto.example.net", nil, map[string]string{}, map[OriginHost][]ParentInfo{})
+	if !strings.HasSuffix(txt, "strategies: []\n") {
+		t.Errorf("expected empty strategies, actual '%v'", txt)
+	}
+	if names := MakeDSStrategyNames(serverInfo, 8, nil, map[string]string{}, map[OriginHost][]ParentInfo{}); len(names) != 0 {
+		t.Errorf("expected no strategy names before ATS 9, actual %v", names)
+	}
+}
+
+func TestHasStrategiesLocation(t *testing.T) {
+	params := []tc.Parameter{
+		{Name: "trafficserver", ConfigFile: "package", Value: "9.0.0"},
+		{Name: "location", ConfigFile: "parent.config", Value: "/opt/trafficserver/etc/trafficserver"},
+	}
+	if HasStrategiesLocation(params) {
+		t.Errorf("expected: no strategies.yaml location without the parameter, actual: location")
+	}
+	if HasStrategiesLocation(nil) {
+		t.Errorf("expected: no strategies.yaml location without parameters, actual: location")
+	}
+
+	params = append(params, tc.Parameter{Name: "location", ConfigFile: StrategiesFileName, Value: "/opt/trafficserver/etc/trafficserver"})
+	if !HasStrategiesLocation(params) {
+		t.Errorf("expected: strategies.yaml location with the parameter, actual: no location")
+	}
+}
@@ -0,0 +1,33 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// TopologyIncludesServer returns whether a server in the given cache group gets config for a delivery service with the given topology.
+// Servers in the edge tier of the topology only get config for the delivery services they're assigned to, like servers of delivery services without topologies. Servers in the other tiers get config for all of the topology's delivery services.
+func TopologyIncludesServer(topology tc.Topology, cacheGroup string, assigned bool) bool {
+	if _, _, ok := topology.CacheGroupParents(cacheGroup); !ok {
+		return false
+	}
+	return assigned || !topology.IsEdge(cacheGroup)
+}
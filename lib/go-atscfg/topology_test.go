@@ -0,0 +1,52 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func TestTopologyIncludesServer(t *testing.T) {
+	topology := tc.Topology{
+		Name: "three-tier",
+		Nodes: []tc.TopologyNode{
+			{CacheGroup: "edge", Parents: []int{1}},
+			{CacheGroup: "mid", Parents: []int{2}},
+			{CacheGroup: "top"},
+		},
+	}
+
+	if TopologyIncludesServer(topology, "edge", false) {
+		t.Errorf("expected unassigned edge to not be included, actual: included")
+	}
+	if !TopologyIncludesServer(topology, "edge", true) {
+		t.Errorf("expected assigned edge to be included, actual: not included")
+	}
+	for _, cg := range []string{"mid", "top"} {
+		if !TopologyIncludesServer(topology, cg, false) {
+			t.Errorf("expected unassigned %v to be included, actual: not included", cg)
+		}
+	}
+	if TopologyIncludesServer(topology, "other", true) {
+		t.Errorf("expected cachegroup not in the topology to not be included, actual: included")
+	}
+}
@@ -0,0 +1,340 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// ConfigFileError is a problem with a generated config file, found by ValidateConfigFiles.
+type ConfigFileError struct {
+	File string `json:"file"`
+	// Line is the 1-based line of the problem, or 0 if it isn't on a single line.
+	Line int    `json:"line,omitempty"`
+	Err  string `json:"error"`
+}
+
+func (e ConfigFileError) Error() string {
+	if e.Line == 0 {
+		return e.File + ": " + e.Err
+	}
+	return e.File + ":" + strconv.Itoa(e.Line) + ": " + e.Err
+}
+
+// remapPluginFileArgPlugins are the remap plugins whose @pparam arguments ending in .config are files generated for the delivery service.
+var remapPluginFileArgPlugins = map[string]struct{}{
+	"background_fetch.so": {},
+	"cacheurl.so":         {},
+	"header_rewrite.so":   {},
+	"regex_remap.so":      {},
+	"uri_signing.so":      {},
+	"url_sig.so":          {},
+}
+
+// ValidateConfigFiles checks generated config files for problems that would make Traffic Server fail to load them, or silently ignore parts of them.
+// The metaTxt is the server's generated meta config, and files is the generated text of its files, keyed by their name on disk. Files which aren't in files aren't checked, but references to them still are.
+//
+// This checks that:
+//   - remap.config plugin argument files and @strategy strategies are in the meta config, and that argument files aren't empty.
+//   - remap.config has no duplicate rules, and no map rules shadowed by an earlier rule matching a prefix of their path.
+//   - parent.config has no duplicate dest_domain lines, and no lines without parents that don't go direct.
+//
+// Returns nil if there are no problems.
+func ValidateConfigFiles(metaTxt string, files map[string]string) []ConfigFileError {
+	errs := []ConfigFileError{}
+
+	meta := tc.ATSConfigMetaData{}
+	if err := json.Unmarshal([]byte(metaTxt), &meta); err != nil {
+		return []ConfigFileError{{File: "meta config", Err: "malformed JSON: " + err.Error()}}
+	}
+
+	metaFiles := map[string]struct{}{}
+	for _, file := range meta.ConfigFiles {
+		if file.FileNameOnDisk == "" {
+			errs = append(errs, ConfigFileError{File: "meta config", Err: "file with location '" + file.Location + "' has no name"})
+			continue
+		}
+		if _, ok := metaFiles[file.FileNameOnDisk]; ok {
+			errs = append(errs, ConfigFileError{File: "meta config", Err: "duplicate file '" + file.FileNameOnDisk + "'"})
+			continue
+		}
+		metaFiles[file.FileNameOnDisk] = struct{}{}
+	}
+
+	if remapTxt, ok := files["remap.config"]; ok {
+		errs = append(errs, validateRemapDotConfig(remapTxt, metaFiles, files)...)
+	}
+	if parentTxt, ok := files["parent.config"]; ok {
+		errs = append(errs, validateParentDotConfig(parentTxt)...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sortConfigFileErrors(errs)
+	return errs
+}
+
+// remapRule is a parsed remap.config rule, for validation.
+type remapRule struct {
+	Line int
+	Type string
+	From string
+	// FromURL is the parsed From, or nil if it isn't a URL, e.g. for regex rules.
+	FromURL *url.URL
+}
+
+func validateRemapDotConfig(txt string, metaFiles map[string]struct{}, files map[string]string) []ConfigFileError {
+	const fileName = "remap.config"
+	errs := []ConfigFileError{}
+	rules := []remapRule{}
+	checkedArgFiles := map[string]struct{}{}
+	strategies := map[string]struct{}(nil) // lazily parsed from strategies.yaml
+
+	for i, line := range strings.Split(txt, "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ".") {
+			continue // blank, comment, or directive like .include
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "rule must have a type, from URL, and to URL"})
+			continue
+		}
+
+		rule := remapRule{Line: lineNum, Type: fields[0], From: fields[1]}
+		if !strings.HasPrefix(rule.Type, "regex_") {
+			fromURL, err := url.Parse(rule.From)
+			if err != nil || fromURL.Host == "" {
+				errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "malformed from URL '" + rule.From + "'"})
+				continue
+			}
+			if toURL, err := url.Parse(fields[2]); err != nil || toURL.Host == "" {
+				errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "malformed to URL '" + fields[2] + "'"})
+				continue
+			}
+			rule.FromURL = fromURL
+		}
+		rules = append(rules, rule)
+
+		plugin := ""
+		for _, opt := range fields[3:] {
+			switch {
+			case strings.HasPrefix(opt, "@plugin="):
+				plugin = strings.TrimPrefix(opt, "@plugin=")
+			case strings.HasPrefix(opt, "@pparam="):
+				arg := strings.TrimPrefix(opt, "@pparam=")
+				if _, ok := remapPluginFileArgPlugins[plugin]; !ok || strings.HasPrefix(arg, "-") || !strings.HasSuffix(arg, ".config") {
+					continue
+				}
+				argFile := path.Base(arg) // e.g. dscp/set_dscp_8.config is the file set_dscp_8.config in the dscp directory.
+				if _, ok := metaFiles[argFile]; !ok {
+					errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "plugin " + plugin + " argument file '" + arg + "' is not in the meta config"})
+					continue
+				}
+				if _, ok := checkedArgFiles[argFile]; ok {
+					continue
+				}
+				checkedArgFiles[argFile] = struct{}{}
+				if argTxt, ok := files[argFile]; ok && isEmptyConfigFile(argTxt) {
+					errs = append(errs, ConfigFileError{File: argFile, Err: "plugin " + plugin + " argument file referenced by " + fileName + " line " + strconv.Itoa(lineNum) + " is empty"})
+				}
+			case strings.HasPrefix(opt, "@strategy="):
+				strategy := strings.TrimPrefix(opt, "@strategy=")
+				if _, ok := metaFiles[StrategiesFileName]; !ok {
+					errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "strategy '" + strategy + "' is used, but " + StrategiesFileName + " is not in the meta config"})
+					continue
+				}
+				strategiesTxt, ok := files[StrategiesFileName]
+				if !ok {
+					continue
+				}
+				if strategies == nil {
+					strategies = parseStrategyNames(strategiesTxt)
+				}
+				if _, ok := strategies[strategy]; !ok {
+					errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "strategy '" + strategy + "' is not in " + StrategiesFileName})
+				}
+			}
+		}
+	}
+
+	return append(errs, validateRemapRules(fileName, rules)...)
+}
+
+// validateRemapRules returns errors for duplicate rules, and for map rules which are never used because an earlier map rule for the same scheme and host matches a prefix of their path.
+func validateRemapRules(fileName string, rules []remapRule) []ConfigFileError {
+	errs := []ConfigFileError{}
+	seen := map[string]int{} // map[type from]line
+	prevMaps := []remapRule{}
+	for _, rule := range rules {
+		key := rule.Type + " " + rule.From
+		if rule.FromURL != nil {
+			key = rule.Type + " " + strings.ToLower(rule.FromURL.Scheme) + "://" + strings.ToLower(rule.FromURL.Host) + rule.FromURL.Path
+		}
+		if prevLine, ok := seen[key]; ok {
+			errs = append(errs, ConfigFileError{File: fileName, Line: rule.Line, Err: "duplicate " + rule.Type + " from '" + rule.From + "', first on line " + strconv.Itoa(prevLine)})
+			continue
+		}
+		seen[key] = rule.Line
+
+		if rule.Type != "map" || rule.FromURL == nil {
+			continue
+		}
+		for _, prev := range prevMaps {
+			if !strings.EqualFold(prev.FromURL.Scheme, rule.FromURL.Scheme) || !strings.EqualFold(prev.FromURL.Host, rule.FromURL.Host) {
+				continue
+			}
+			if strings.HasPrefix(remapPath(rule.FromURL), remapPath(prev.FromURL)) {
+				errs = append(errs, ConfigFileError{File: fileName, Line: rule.Line, Err: "map from '" + rule.From + "' is shadowed by '" + prev.From + "' on line " + strconv.Itoa(prev.Line)})
+				break
+			}
+		}
+		prevMaps = append(prevMaps, rule)
+	}
+	return errs
+}
+
+// remapPath returns the path of a remap from URL, without the leading slash, which Traffic Server ignores.
+func remapPath(u *url.URL) string {
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+// parseStrategyNames returns the names of the strategies in a strategies.yaml made by MakeStrategiesDotYAML.
+func parseStrategyNames(txt string) map[string]struct{} {
+	names := map[string]struct{}{}
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "- strategy:") {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(line, "- strategy:"))
+		names[strings.Trim(name, `'"`)] = struct{}{}
+	}
+	return names
+}
+
+// isEmptyConfigFile returns whether txt has nothing but whitespace and comments.
+func isEmptyConfigFile(txt string) bool {
+	for _, line := range strings.Split(txt, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+func validateParentDotConfig(txt string) []ConfigFileError {
+	const fileName = "parent.config"
+	errs := []ConfigFileError{}
+	seen := map[string]int{} // map[destination]line
+	for i, line := range strings.Split(txt, "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, err := parseParentDotConfigLine(line)
+		if err != "" {
+			errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: err})
+			continue
+		}
+
+		dest := ""
+		for _, destKey := range []string{"dest_domain", "dest_host", "dest_ip", "url_regex"} {
+			if val, ok := fields[destKey]; ok {
+				dest = destKey + "=" + val
+				break
+			}
+		}
+		if dest == "" {
+			errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "line has no destination"})
+			continue
+		}
+		dest += " port=" + fields["port"]
+		if prevLine, ok := seen[dest]; ok {
+			errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: "duplicate " + dest + ", first on line " + strconv.Itoa(prevLine)})
+			continue
+		}
+		seen[dest] = lineNum
+
+		if strings.Trim(fields["parent"], " ;") == "" && fields["go_direct"] != "true" {
+			errs = append(errs, ConfigFileError{File: fileName, Line: lineNum, Err: dest + " has no parents and go_direct is not true"})
+		}
+	}
+	return errs
+}
+
+// parseParentDotConfigLine parses the space-separated key=value fields of a parent.config line, whose values may be quoted.
+// Returns a non-empty error string if the line is malformed.
+func parseParentDotConfigLine(line string) (map[string]string, string) {
+	fields := map[string]string{}
+	for line != "" {
+		eq := strings.Index(line, "=")
+		if eq <= 0 || strings.ContainsAny(line[:eq], " \t\"") {
+			return nil, "malformed field '" + strings.Fields(line)[0] + "'"
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+		val := ""
+		if strings.HasPrefix(line, `"`) {
+			end := strings.Index(line[1:], `"`)
+			if end < 0 {
+				return nil, "unterminated quote in " + key
+			}
+			val = line[1 : end+1]
+			line = line[end+2:]
+			if line != "" && line[0] != ' ' && line[0] != '\t' {
+				return nil, "malformed value of " + key
+			}
+		} else if end := strings.IndexAny(line, " \t"); end >= 0 {
+			val = line[:end]
+			line = line[end:]
+		} else {
+			val = line
+			line = ""
+		}
+		if _, ok := fields[key]; ok {
+			return nil, "duplicate field " + key
+		}
+		fields[key] = val
+		line = strings.TrimLeft(line, " \t")
+	}
+	return fields, ""
+}
+
+// sortConfigFileErrors sorts errs by file and line.
+func sortConfigFileErrors(errs []ConfigFileError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		return errs[i].Line < errs[j].Line
+	})
+}
@@ -0,0 +1,128 @@
+package atscfg
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func makeTestValidateMeta(t *testing.T, fileNames ...string) string {
+	meta := tc.ATSConfigMetaData{}
+	for _, name := range fileNames {
+		meta.ConfigFiles = append(meta.ConfigFiles, tc.ATSConfigMetaDataConfigFile{FileNameOnDisk: name, Location: "/opt/trafficserver/etc/trafficserver"})
+	}
+	bts, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshalling meta config: %v", err)
+	}
+	return string(bts)
+}
+
+func TestValidateConfigFiles(t *testing.T) {
+	hdr := "# DO NOT EDIT - Generated for myserver by myToolName (https://myto.example.net/) on Thu Jan 1 00:00:00 UTC 1970\n"
+	meta := makeTestValidateMeta(t, "remap.config", "parent.config", "hdr_rw_ds0.config", "set_dscp_8.config", "strategies.yaml")
+	files := map[string]string{
+		"remap.config": hdr +
+			"map	http://ds0.example.net/     http://origin0.example.net/ @plugin=header_rewrite.so @pparam=hdr_rw_ds0.config\n" +
+			"map	http://ds1.example.net/     http://origin1.example.net/ @plugin=header_rewrite.so @pparam=dscp/set_dscp_8.config @plugin=cachekey.so @pparam=--separator=\n" +
+			"map	https://ds1.example.net/     http://origin1.example.net/ @strategy=strategy-ds1\n" +
+			"regex_map	http://.*\\.ds2\\.example\\.net/     http://origin2.example.net/\n",
+		"parent.config": hdr +
+			`dest_domain=origin0.example.net port=80 parent="mid0.example.net:80|0.999;mid1.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=ignore` + "\n" +
+			`dest_domain=origin1.example.net port=80 go_direct=true` + "\n" +
+			`dest_domain=. parent="mid0.example.net:80|0.999;" round_robin=consistent_hash go_direct=false` + "\n",
+		"hdr_rw_ds0.config": hdr + "set-header X-Foo bar [L]\n",
+		"set_dscp_8.config": hdr + "cond %{REMAP_PSEUDO_HOOK}\nset-conn-dscp 8 [L]\n",
+		"strategies.yaml":   hdr + "strategies:\n  - strategy: 'strategy-ds1'\n    policy: consistent_hash\n",
+	}
+
+	if errs := ValidateConfigFiles(meta, files); len(errs) != 0 {
+		t.Errorf("expected valid files to have no errors, actual: %+v", errs)
+	}
+}
+
+func TestValidateConfigFilesErrors(t *testing.T) {
+	hdr := "# DO NOT EDIT - Generated for myserver by myToolName (https://myto.example.net/) on Thu Jan 1 00:00:00 UTC 1970\n"
+	meta := makeTestValidateMeta(t, "remap.config", "parent.config", "hdr_rw_ds0.config")
+	files := map[string]string{
+		"remap.config": hdr +
+			"map	http://ds0.example.net/     http://origin0.example.net/ @plugin=header_rewrite.so @pparam=hdr_rw_ds0.config\n" + // line 2
+			"map	http://ds1.example.net/     http://origin1.example.net/ @plugin=regex_remap.so @pparam=regex_remap_ds1.config\n" + // line 3: missing file
+			"map	http://DS0.example.net/     http://origin2.example.net/\n" + // line 4: duplicate of 2
+			"map	http://ds0.example.net/foo/     http://origin3.example.net/\n" + // line 5: shadowed by 2
+			"map	https://ds1.example.net/     http://origin1.example.net/ @strategy=strategy-ds1\n" + // line 6: no strategies.yaml
+			"map	http://ds3.example.net/\n", // line 7: no to URL
+		"parent.config": hdr +
+			`dest_domain=origin0.example.net port=80 parent="" round_robin=consistent_hash go_direct=false` + "\n" + // line 2: no parents
+			`dest_domain=origin1.example.net port=80 go_direct=true` + "\n" +
+			`dest_domain=origin1.example.net port=80 go_direct=true` + "\n" + // line 4: duplicate
+			`dest_domain=origin2.example.net port=80 parent="mid0.example.net:80|0.999; go_direct=false` + "\n", // line 5: unterminated quote
+		"hdr_rw_ds0.config": hdr,
+	}
+
+	errs := ValidateConfigFiles(meta, files)
+
+	expected := []struct {
+		File      string
+		Line      int
+		ErrSubstr string
+	}{
+		{"hdr_rw_ds0.config", 0, "is empty"},
+		{"parent.config", 2, "no parents"},
+		{"parent.config", 4, "duplicate"},
+		{"parent.config", 5, "unterminated quote"},
+		{"remap.config", 3, "not in the meta config"},
+		{"remap.config", 4, "duplicate"},
+		{"remap.config", 5, "shadowed"},
+		{"remap.config", 6, "strategies.yaml is not in the meta config"},
+		{"remap.config", 7, "must have a type, from URL, and to URL"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %v errors, actual: %+v", len(expected), errs)
+	}
+	for i, ex := range expected {
+		if errs[i].File != ex.File || errs[i].Line != ex.Line || !strings.Contains(errs[i].Err, ex.ErrSubstr) {
+			t.Errorf("expected error %v to be %+v, actual: %+v", i, ex, errs[i])
+		}
+	}
+}
+
+func TestValidateConfigFilesMissingStrategy(t *testing.T) {
+	meta := makeTestValidateMeta(t, "remap.config", "strategies.yaml")
+	files := map[string]string{
+		"remap.config":    "map	http://ds0.example.net/     http://origin0.example.net/ @strategy=strategy-ds0\n",
+		"strategies.yaml": "strategies:\n  - strategy: 'strategy-ds1'\n",
+	}
+	errs := ValidateConfigFiles(meta, files)
+	if len(errs) != 1 || errs[0].Line != 1 || !strings.Contains(errs[0].Err, "'strategy-ds0' is not in strategies.yaml") {
+		t.Errorf("expected missing strategy error, actual: %+v", errs)
+	}
+}
+
+func TestValidateConfigFilesMalformedMeta(t *testing.T) {
+	errs := ValidateConfigFiles("not json", nil)
+	if len(errs) != 1 || errs[0].File != "meta config" {
+		t.Errorf("expected malformed meta config error, actual: %+v", errs)
+	}
+}
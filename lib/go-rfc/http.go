@@ -0,0 +1,61 @@
+package rfc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// HTTP headers used for conditional requests, as defined by RFC7232.
+const (
+	ETagHeader      = "ETag"
+	IfMatch         = "If-Match"
+	IfNoneMatch     = "If-None-Match"
+	IfModifiedSince = "If-Modified-Since"
+	LastModified    = "Last-Modified"
+)
+
+// ETag returns a strong entity-tag for the given representation body, including the surrounding quotes.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches returns whether any entity-tag in the given If-None-Match (or If-Match) header value matches etag.
+//
+// Per RFC7232§3.2, the weak comparison function is used, so a "W/" prefix is ignored. The special value "*" matches any etag.
+func ETagMatches(headerVal string, etag string) bool {
+	headerVal = strings.TrimSpace(headerVal)
+	if headerVal == "" {
+		return false
+	}
+	if headerVal == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(headerVal, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
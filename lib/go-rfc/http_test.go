@@ -0,0 +1,56 @@
+package rfc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import "testing"
+
+func TestETag(t *testing.T) {
+	a := ETag([]byte("foo"))
+	if a != ETag([]byte("foo")) {
+		t.Errorf("expected ETag of the same body to be equal")
+	}
+	if a == ETag([]byte("bar")) {
+		t.Errorf("expected ETag of different bodies to differ")
+	}
+	if len(a) < 3 || a[0] != '"' || a[len(a)-1] != '"' {
+		t.Errorf("expected ETag to be quoted, actual: %s", a)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		Header   string
+		Expected bool
+	}{
+		{``, false},
+		{`*`, true},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+		{`abc`, false},
+	}
+	for _, test := range tests {
+		if actual := ETagMatches(test.Header, etag); actual != test.Expected {
+			t.Errorf("ETagMatches('%s', '%s') expected %v, actual %v", test.Header, etag, test.Expected, actual)
+		}
+	}
+}
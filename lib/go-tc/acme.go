@@ -0,0 +1,81 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ACMEChallengeType is the type of challenge used to prove control of a delivery service's domains to an ACME certificate authority.
+type ACMEChallengeType string
+
+const (
+	// ACMEChallengeTypeDNS01 challenges are answered with TXT static DNS entries served by Traffic Router.
+	ACMEChallengeTypeDNS01 = ACMEChallengeType("dns-01")
+	// ACMEChallengeTypeHTTP01 challenges are answered by Traffic Ops at /.well-known/acme-challenge/, which the CDN must route to Traffic Ops.
+	ACMEChallengeTypeHTTP01 = ACMEChallengeType("http-01")
+)
+
+// ACMEStatus is the state of a delivery service's ACME certificate.
+type ACMEStatus string
+
+const (
+	// ACMEStatusPending certificates have been requested, but not yet issued.
+	ACMEStatusPending = ACMEStatus("pending")
+	// ACMEStatusIssued certificates have been issued, and will be renewed before they expire.
+	ACMEStatusIssued = ACMEStatus("issued")
+	// ACMEStatusFailed certificates failed to be issued or renewed, and will be retried.
+	ACMEStatusFailed = ACMEStatus("failed")
+)
+
+// DeliveryServiceACMEReq is a request to issue a delivery service's SSL certificate from the ACME certificate authority, and keep it renewed.
+type DeliveryServiceACMEReq struct {
+	ChallengeType *ACMEChallengeType `json:"challengeType"`
+}
+
+// Validate implements the api.ParseValidator interface. A nil challenge type defaults to ACMEChallengeTypeDNS01.
+func (r *DeliveryServiceACMEReq) Validate(tx *sql.Tx) error {
+	if r.ChallengeType == nil {
+		ct := ACMEChallengeTypeDNS01
+		r.ChallengeType = &ct
+	}
+	switch *r.ChallengeType {
+	case ACMEChallengeTypeDNS01, ACMEChallengeTypeHTTP01:
+		return nil
+	}
+	return errors.New("challengeType must be one of '" + string(ACMEChallengeTypeDNS01) + "', '" + string(ACMEChallengeTypeHTTP01) + "'")
+}
+
+// DeliveryServiceACME is the ACME certificate of a delivery service.
+type DeliveryServiceACME struct {
+	DeliveryService string            `json:"deliveryservice" db:"xml_id"`
+	ChallengeType   ACMEChallengeType `json:"challengeType" db:"challenge_type"`
+	Status          ACMEStatus        `json:"status" db:"status"`
+	Expires         *TimeNoMod        `json:"expires" db:"expires"`
+	LastError       *string           `json:"lastError" db:"last_error"`
+	NextAttempt     TimeNoMod         `json:"nextAttempt" db:"next_attempt"`
+	LastUpdated     TimeNoMod         `json:"lastUpdated" db:"last_updated"`
+}
+
+// DeliveryServiceACMEResponse contains the result data from a GET /deliveryservices/{xmlid}/sslkeys/acme request.
+type DeliveryServiceACMEResponse struct {
+	Response DeliveryServiceACME `json:"response"`
+}
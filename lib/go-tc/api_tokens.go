@@ -0,0 +1,134 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MaxAPITokenLifetime is the longest an API token may be valid for.
+const MaxAPITokenLifetime = 365 * 24 * time.Hour
+
+// APITokenReq is a request to create an API token for the current user.
+type APITokenReq struct {
+	Name    *string    `json:"name"`
+	Expires *time.Time `json:"expires"`
+	// Capabilities, if not nil, limits the token to these capabilities of the user.
+	Capabilities *[]string `json:"capabilities"`
+	// Routes, if not nil, limits the token to these "METHOD path" patterns, matched against the path after the API version, e.g. "POST cdns/*/queue_update". The method may be "*".
+	Routes *[]string `json:"routes"`
+	// CDN, if not nil, limits the token to routes for this CDN.
+	CDN *string `json:"cdn"`
+}
+
+// Validate implements the api.ParseValidator interface.
+func (r *APITokenReq) Validate(tx *sql.Tx) error {
+	errs := []string{}
+	if r.Name == nil || strings.TrimSpace(*r.Name) == "" {
+		errs = append(errs, "name is required")
+	}
+	if r.Expires == nil {
+		errs = append(errs, "expires is required")
+	} else if !r.Expires.After(time.Now()) {
+		errs = append(errs, "expires must be in the future")
+	} else if r.Expires.After(time.Now().Add(MaxAPITokenLifetime)) {
+		errs = append(errs, "expires must be within "+MaxAPITokenLifetime.String()+" (365 days)")
+	}
+	if r.Routes != nil {
+		for _, route := range *r.Routes {
+			if err := validateAPITokenRoute(route); err != nil {
+				errs = append(errs, "route '"+route+"' "+err.Error())
+			}
+		}
+	}
+	if r.Capabilities != nil && len(*r.Capabilities) > 0 {
+		missing := []string{}
+		if err := tx.QueryRow(`SELECT ARRAY(SELECT c FROM unnest($1::text[]) AS c WHERE c NOT IN (SELECT name FROM capability))`, pq.Array(*r.Capabilities)).Scan(pq.Array(&missing)); err != nil {
+			return errors.New("checking capabilities: " + err.Error())
+		}
+		if len(missing) > 0 {
+			errs = append(errs, "no such capabilities: "+strings.Join(missing, ", "))
+		}
+	}
+	if r.CDN != nil {
+		exists := false
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM cdn WHERE name = $1)`, *r.CDN).Scan(&exists); err != nil {
+			return errors.New("checking cdn: " + err.Error())
+		}
+		if !exists {
+			errs = append(errs, "no such cdn '"+*r.CDN+"'")
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateAPITokenRoute returns an error if the route isn't a valid "METHOD path" pattern.
+func validateAPITokenRoute(route string) error {
+	fields := strings.Fields(route)
+	if len(fields) != 2 {
+		return errors.New("must be a method and a path pattern, e.g. 'GET cdns/*'")
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "*", "GET", "POST", "PUT", "DELETE":
+	default:
+		return errors.New("method must be one of '*', 'GET', 'POST', 'PUT', 'DELETE'")
+	}
+	if _, err := path.Match(fields[1], ""); err != nil {
+		return errors.New("path pattern is invalid: " + err.Error())
+	}
+	return nil
+}
+
+// APIToken is an API token, without its secret, which is only returned when it's created.
+type APIToken struct {
+	ID           int            `json:"id" db:"id"`
+	Name         string         `json:"name" db:"name"`
+	Expires      time.Time      `json:"expires" db:"expires"`
+	Capabilities pq.StringArray `json:"capabilities" db:"capabilities"`
+	Routes       pq.StringArray `json:"routes" db:"routes"`
+	CDN          *string        `json:"cdn" db:"cdn"`
+	LastUpdated  TimeNoMod      `json:"lastUpdated" db:"last_updated"`
+}
+
+// APITokenCreated is a newly created API token, with its secret.
+type APITokenCreated struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// APITokensResponse contains the result data from a GET /user/current/tokens request.
+type APITokensResponse struct {
+	Response []APIToken `json:"response"`
+}
+
+// APITokenCreatedResponse contains the result data from a POST /user/current/tokens request.
+type APITokenCreatedResponse struct {
+	Response APITokenCreated `json:"response"`
+	Alerts
+}
@@ -0,0 +1,372 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"strings"
+)
+
+// CDNDocument is a declarative description of a CDN, which can be exported, kept in version control, and planned and applied to Traffic Ops.
+// Objects refer to each other by name rather than ID, so a document can be applied to another Traffic Ops instance.
+type CDNDocument struct {
+	CDN              CDNDocCDN               `json:"cdn"`
+	CacheGroups      []CDNDocCacheGroup      `json:"cacheGroups"`
+	Profiles         []CDNDocProfile         `json:"profiles"`
+	Servers          []CDNDocServer          `json:"servers"`
+	DeliveryServices []CDNDocDeliveryService `json:"deliveryServices"`
+	Origins          []CDNDocOrigin          `json:"origins"`
+	Steering         []CDNDocSteering        `json:"steering"`
+	Federations      []CDNDocFederation      `json:"federations"`
+}
+
+// CDNDocCDN is the CDN itself in a CDNDocument.
+type CDNDocCDN struct {
+	Name          string `json:"name"`
+	DomainName    string `json:"domainName"`
+	DNSSECEnabled bool   `json:"dnssecEnabled"`
+}
+
+// CDNDocCacheGroup is a cache group used by the servers of a CDNDocument. Cache groups are shared by CDNs, so applying a document creates and updates them, but never deletes them.
+type CDNDocCacheGroup struct {
+	Name                      string   `json:"name"`
+	ShortName                 string   `json:"shortName"`
+	Type                      string   `json:"type"`
+	Latitude                  *float64 `json:"latitude"`
+	Longitude                 *float64 `json:"longitude"`
+	ParentCacheGroup          *string  `json:"parentCacheGroup"`
+	SecondaryParentCacheGroup *string  `json:"secondaryParentCacheGroup"`
+	FallbackToClosest         bool     `json:"fallbackToClosest"`
+}
+
+// CDNDocProfile is a profile of a CDNDocument, with its parameters.
+type CDNDocProfile struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Type            string            `json:"type"`
+	RoutingDisabled bool              `json:"routingDisabled"`
+	Parameters      []CDNDocParameter `json:"parameters"`
+}
+
+// CDNDocParameter is a parameter of a CDNDocProfile.
+type CDNDocParameter struct {
+	Name       string `json:"name"`
+	ConfigFile string `json:"configFile"`
+	Value      string `json:"value"`
+	Secure     bool   `json:"secure"`
+}
+
+// CDNDocServer is a server of a CDNDocument, identified by its host name.
+type CDNDocServer struct {
+	HostName      string  `json:"hostName"`
+	DomainName    string  `json:"domainName"`
+	CacheGroup    string  `json:"cacheGroup"`
+	Type          string  `json:"type"`
+	Profile       string  `json:"profile"`
+	Status        string  `json:"status"`
+	PhysLocation  string  `json:"physLocation"`
+	TCPPort       *int    `json:"tcpPort"`
+	HTTPSPort     *int    `json:"httpsPort"`
+	InterfaceName string  `json:"interfaceName"`
+	InterfaceMTU  int     `json:"interfaceMtu"`
+	IPAddress     string  `json:"ipAddress"`
+	IPNetmask     string  `json:"ipNetmask"`
+	IPGateway     string  `json:"ipGateway"`
+	IP6Address    *string `json:"ip6Address"`
+	IP6Gateway    *string `json:"ip6Gateway"`
+	Rack          *string `json:"rack"`
+}
+
+// CDNDocDeliveryService is a delivery service of a CDNDocument, with its regexes and assigned servers.
+type CDNDocDeliveryService struct {
+	XMLID                    string          `json:"xmlId"`
+	DisplayName              string          `json:"displayName"`
+	Type                     string          `json:"type"`
+	Tenant                   string          `json:"tenant"`
+	Active                   bool            `json:"active"`
+	Profile                  *string         `json:"profile"`
+	RoutingName              string          `json:"routingName"`
+	Protocol                 *int            `json:"protocol"`
+	DSCP                     int             `json:"dscp"`
+	QStringIgnore            *int            `json:"qstringIgnore"`
+	GeoLimit                 *int            `json:"geoLimit"`
+	GeoLimitCountries        *string         `json:"geoLimitCountries"`
+	GeoProvider              *int            `json:"geoProvider"`
+	MissLat                  *float64        `json:"missLat"`
+	MissLong                 *float64        `json:"missLong"`
+	CCRDNSTTL                *int            `json:"ccrDnsTtl"`
+	MaxDNSAnswers            *int            `json:"maxDnsAnswers"`
+	InitialDispersion        *int            `json:"initialDispersion"`
+	IPV6RoutingEnabled       bool            `json:"ipv6RoutingEnabled"`
+	RangeRequestHandling     *int            `json:"rangeRequestHandling"`
+	MultiSiteOrigin          bool            `json:"multiSiteOrigin"`
+	LogsEnabled              bool            `json:"logsEnabled"`
+	RegionalGeoBlocking      bool            `json:"regionalGeoBlocking"`
+	AnonymousBlockingEnabled bool            `json:"anonymousBlockingEnabled"`
+	DeepCachingType          string          `json:"deepCachingType"`
+	EdgeHeaderRewrite        *string         `json:"edgeHeaderRewrite"`
+	MidHeaderRewrite         *string         `json:"midHeaderRewrite"`
+	RegexRemap               *string         `json:"regexRemap"`
+	RemapText                *string         `json:"remapText"`
+	CheckPath                *string         `json:"checkPath"`
+	InfoURL                  *string         `json:"infoUrl"`
+	LongDesc                 *string         `json:"longDesc"`
+	Regexes                  []CDNDocDSRegex `json:"regexes"`
+	Servers                  []string        `json:"servers"`
+}
+
+// CDNDocDSRegex is a regex of a CDNDocDeliveryService.
+type CDNDocDSRegex struct {
+	Type      string `json:"type"`
+	Pattern   string `json:"pattern"`
+	SetNumber int    `json:"setNumber"`
+}
+
+// CDNDocOrigin is an origin of a delivery service of a CDNDocument, identified by its name.
+type CDNDocOrigin struct {
+	Name            string  `json:"name"`
+	DeliveryService string  `json:"deliveryService"`
+	Tenant          string  `json:"tenant"`
+	FQDN            string  `json:"fqdn"`
+	Protocol        string  `json:"protocol"`
+	Port            *int    `json:"port"`
+	IsPrimary       bool    `json:"isPrimary"`
+	IPAddress       *string `json:"ipAddress"`
+	IP6Address      *string `json:"ip6Address"`
+	CacheGroup      *string `json:"cacheGroup"`
+	Profile         *string `json:"profile"`
+}
+
+// CDNDocSteering is the targets of a steering delivery service of a CDNDocument.
+type CDNDocSteering struct {
+	DeliveryService string                 `json:"deliveryService"`
+	Targets         []CDNDocSteeringTarget `json:"targets"`
+}
+
+// CDNDocSteeringTarget is a target of a CDNDocSteering.
+type CDNDocSteeringTarget struct {
+	DeliveryService string `json:"deliveryService"`
+	Type            string `json:"type"`
+	Value           int    `json:"value"`
+}
+
+// CDNDocFederation is a federation of the delivery services of a CDNDocument, identified by its CNAME. A federation is part of a CDN if any of its delivery services are.
+type CDNDocFederation struct {
+	CName            string   `json:"cname"`
+	TTL              int      `json:"ttl"`
+	Description      *string  `json:"description"`
+	DeliveryServices []string `json:"deliveryServices"`
+}
+
+// Validate returns an error if the document is missing required fields, or has duplicate objects or references to objects which aren't in the document. References to objects outside of a CDN, like types and tenants, are checked when the document is planned.
+func (doc CDNDocument) Validate() error {
+	errs := []string{}
+	if doc.CDN.Name == "" {
+		errs = append(errs, "cdn name is required")
+	}
+	if doc.CDN.DomainName == "" {
+		errs = append(errs, "cdn domainName is required")
+	}
+
+	cacheGroups := map[string]struct{}{}
+	for _, cg := range doc.CacheGroups {
+		if cg.Name == "" || cg.ShortName == "" || cg.Type == "" {
+			errs = append(errs, "cache groups require a name, shortName, and type")
+			continue
+		}
+		if _, ok := cacheGroups[cg.Name]; ok {
+			errs = append(errs, "duplicate cache group '"+cg.Name+"'")
+		}
+		cacheGroups[cg.Name] = struct{}{}
+		if (cg.Latitude == nil) != (cg.Longitude == nil) {
+			errs = append(errs, "cache group '"+cg.Name+"' must have both a latitude and longitude, or neither")
+		}
+	}
+	for _, cg := range doc.CacheGroups {
+		for _, parent := range []*string{cg.ParentCacheGroup, cg.SecondaryParentCacheGroup} {
+			if parent == nil {
+				continue
+			}
+			if _, ok := cacheGroups[*parent]; !ok {
+				errs = append(errs, "cache group '"+cg.Name+"' parent '"+*parent+"' isn't in the document")
+			}
+		}
+	}
+
+	profiles := map[string]struct{}{}
+	for _, profile := range doc.Profiles {
+		if profile.Name == "" || profile.Type == "" {
+			errs = append(errs, "profiles require a name and type")
+			continue
+		}
+		if _, ok := profiles[profile.Name]; ok {
+			errs = append(errs, "duplicate profile '"+profile.Name+"'")
+		}
+		profiles[profile.Name] = struct{}{}
+		for _, param := range profile.Parameters {
+			if param.Name == "" {
+				errs = append(errs, "profile '"+profile.Name+"' has a parameter without a name")
+			}
+		}
+	}
+
+	servers := map[string]struct{}{}
+	for _, server := range doc.Servers {
+		if server.HostName == "" || server.DomainName == "" || server.InterfaceName == "" || server.IPAddress == "" || server.IPNetmask == "" || server.IPGateway == "" || server.Type == "" || server.Status == "" || server.PhysLocation == "" {
+			errs = append(errs, "servers require a hostName, domainName, interfaceName, ipAddress, ipNetmask, ipGateway, type, status, and physLocation")
+			continue
+		}
+		if _, ok := servers[server.HostName]; ok {
+			errs = append(errs, "duplicate server '"+server.HostName+"'")
+		}
+		servers[server.HostName] = struct{}{}
+		if _, ok := cacheGroups[server.CacheGroup]; !ok {
+			errs = append(errs, "server '"+server.HostName+"' cache group '"+server.CacheGroup+"' isn't in the document")
+		}
+		if _, ok := profiles[server.Profile]; !ok {
+			errs = append(errs, "server '"+server.HostName+"' profile '"+server.Profile+"' isn't in the document")
+		}
+	}
+
+	dses := map[string]struct{}{}
+	for _, ds := range doc.DeliveryServices {
+		if ds.XMLID == "" || ds.DisplayName == "" || ds.Type == "" || ds.Tenant == "" || ds.RoutingName == "" {
+			errs = append(errs, "delivery services require an xmlId, displayName, type, tenant, and routingName")
+			continue
+		}
+		if _, ok := dses[ds.XMLID]; ok {
+			errs = append(errs, "duplicate delivery service '"+ds.XMLID+"'")
+		}
+		dses[ds.XMLID] = struct{}{}
+		if ds.Profile != nil {
+			if _, ok := profiles[*ds.Profile]; !ok {
+				errs = append(errs, "delivery service '"+ds.XMLID+"' profile '"+*ds.Profile+"' isn't in the document")
+			}
+		}
+		if DeepCachingTypeFromString(ds.DeepCachingType) == DeepCachingTypeInvalid {
+			errs = append(errs, "delivery service '"+ds.XMLID+"' deepCachingType must be NEVER or ALWAYS")
+		}
+		for _, server := range ds.Servers {
+			if _, ok := servers[server]; !ok {
+				errs = append(errs, "delivery service '"+ds.XMLID+"' server '"+server+"' isn't in the document")
+			}
+		}
+	}
+
+	origins := map[string]struct{}{}
+	for _, origin := range doc.Origins {
+		if origin.Name == "" || origin.FQDN == "" || origin.Tenant == "" {
+			errs = append(errs, "origins require a name, fqdn, and tenant")
+			continue
+		}
+		if _, ok := origins[origin.Name]; ok {
+			errs = append(errs, "duplicate origin '"+origin.Name+"'")
+		}
+		origins[origin.Name] = struct{}{}
+		if _, ok := dses[origin.DeliveryService]; !ok {
+			errs = append(errs, "origin '"+origin.Name+"' delivery service '"+origin.DeliveryService+"' isn't in the document")
+		}
+		if origin.Protocol != "http" && origin.Protocol != "https" {
+			errs = append(errs, "origin '"+origin.Name+"' protocol must be http or https")
+		}
+		if origin.Profile != nil {
+			if _, ok := profiles[*origin.Profile]; !ok {
+				errs = append(errs, "origin '"+origin.Name+"' profile '"+*origin.Profile+"' isn't in the document")
+			}
+		}
+		if origin.CacheGroup != nil {
+			if _, ok := cacheGroups[*origin.CacheGroup]; !ok {
+				errs = append(errs, "origin '"+origin.Name+"' cache group '"+*origin.CacheGroup+"' isn't in the document")
+			}
+		}
+	}
+
+	steering := map[string]struct{}{}
+	for _, st := range doc.Steering {
+		if _, ok := steering[st.DeliveryService]; ok {
+			errs = append(errs, "duplicate steering for delivery service '"+st.DeliveryService+"'")
+		}
+		steering[st.DeliveryService] = struct{}{}
+		if _, ok := dses[st.DeliveryService]; !ok {
+			errs = append(errs, "steering delivery service '"+st.DeliveryService+"' isn't in the document")
+		}
+		for _, target := range st.Targets {
+			if _, ok := dses[target.DeliveryService]; !ok {
+				errs = append(errs, "steering delivery service '"+st.DeliveryService+"' target '"+target.DeliveryService+"' isn't in the document")
+			}
+			if target.Type == "" {
+				errs = append(errs, "steering delivery service '"+st.DeliveryService+"' target '"+target.DeliveryService+"' requires a type")
+			}
+		}
+	}
+
+	federations := map[string]struct{}{}
+	for _, fed := range doc.Federations {
+		if fed.CName == "" || fed.TTL <= 0 || len(fed.DeliveryServices) == 0 {
+			errs = append(errs, "federations require a cname, a positive ttl, and delivery services")
+			continue
+		}
+		if _, ok := federations[fed.CName]; ok {
+			errs = append(errs, "duplicate federation '"+fed.CName+"'")
+		}
+		federations[fed.CName] = struct{}{}
+		for _, ds := range fed.DeliveryServices {
+			if _, ok := dses[ds]; !ok {
+				errs = append(errs, "federation '"+fed.CName+"' delivery service '"+ds+"' isn't in the document")
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// CDNPlanAction is the action a CDNPlanChange takes.
+type CDNPlanAction string
+
+const (
+	CDNPlanCreate = CDNPlanAction("create")
+	CDNPlanUpdate = CDNPlanAction("update")
+	CDNPlanDelete = CDNPlanAction("delete")
+)
+
+// CDNPlanChange is one change of a CDNPlan.
+type CDNPlanChange struct {
+	Action CDNPlanAction `json:"action"`
+	// Type is the type of object changed: cdn, cacheGroup, profile, server, deliveryService, origin, steering, or federation.
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// Fields is the names of the changed fields of updates.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// CDNPlan is the changes applying a CDNDocument would make to a CDN.
+type CDNPlan struct {
+	CDN     string          `json:"cdn"`
+	Changes []CDNPlanChange `json:"changes"`
+}
+
+// CDNPlanResponse is the response to a plan or apply request.
+type CDNPlanResponse struct {
+	Response CDNPlan `json:"response"`
+	Alerts
+}
@@ -0,0 +1,57 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCDNDocumentValidate(t *testing.T) {
+	profile := "EDGE"
+	doc := CDNDocument{
+		CDN:         CDNDocCDN{Name: "cdn1", DomainName: "cdn1.example.net"},
+		CacheGroups: []CDNDocCacheGroup{{Name: "edge", ShortName: "edge", Type: "EDGE_LOC"}},
+		Profiles:    []CDNDocProfile{{Name: profile, Type: "ATS_PROFILE"}},
+		Servers: []CDNDocServer{
+			{HostName: "edge1", DomainName: "example.net", CacheGroup: "edge", Type: "EDGE", Profile: profile, Status: "REPORTED", PhysLocation: "plocation", InterfaceName: "eth0", IPAddress: "192.0.2.1", IPNetmask: "255.255.255.0", IPGateway: "192.0.2.254"},
+		},
+		DeliveryServices: []CDNDocDeliveryService{{XMLID: "ds1", DisplayName: "ds1", Type: "HTTP", Tenant: "root", RoutingName: "cdn", Servers: []string{"edge1"}}},
+		Origins:          []CDNDocOrigin{{Name: "o1", DeliveryService: "ds1", Tenant: "root", FQDN: "origin.example.net", Protocol: "http"}},
+		Federations:      []CDNDocFederation{{CName: "fed.example.net.", TTL: 60, DeliveryServices: []string{"ds1"}}},
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate valid document expected nil error, actual: %v", err)
+	}
+
+	doc.Servers = append(doc.Servers, doc.Servers[0])
+	doc.DeliveryServices[0].Servers = []string{"edge2"}
+	doc.Origins[0].Protocol = "ftp"
+	doc.Federations[0].DeliveryServices = nil
+	err := doc.Validate()
+	if err == nil {
+		t.Fatalf("Validate invalid document expected error, actual: nil")
+	}
+	for _, expected := range []string{"duplicate server 'edge1'", "server 'edge2' isn't in the document", "protocol must be http or https", "federations require"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Validate expected error containing '%s', actual: %v", expected, err)
+		}
+	}
+}
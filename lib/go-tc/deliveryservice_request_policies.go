@@ -0,0 +1,137 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"strings"
+)
+
+// The change types of delivery service requests.
+const (
+	DeliveryServiceRequestChangeTypeCreate = "create"
+	DeliveryServiceRequestChangeTypeUpdate = "update"
+	DeliveryServiceRequestChangeTypeDelete = "delete"
+)
+
+// DeliveryServiceRequestPolicy is the approval policy of delivery service requests.
+// The policy of a request is the one whose tenant is the nearest to the tenant of the request's delivery service, itself or one of its ancestors, preferring policies of the request's change type over those of all change types. Policies with no tenant are furthest.
+// Requests with no policy use DefaultDeliveryServiceRequestPolicy.
+type DeliveryServiceRequestPolicy struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// TenantID is the tenant whose delivery services, and those of its descendants, the policy applies to. If it's nil, the policy applies to all tenants.
+	TenantID *int `json:"tenantId"`
+	// Tenant is the name of the tenant. It's set by Traffic Ops, and ignored in requests.
+	Tenant *string `json:"tenant"`
+	// ChangeType is the change type of the requests the policy applies to: create, update, or delete. If it's nil, the policy applies to all change types.
+	ChangeType *string `json:"changeType"`
+	// RequiredApprovals is the number of approvals a request needs before it's approved. The default is 1.
+	RequiredApprovals int `json:"requiredApprovals"`
+	// TenantApprovers is whether only approvals by users of the delivery service's own tenant count.
+	TenantApprovers bool `json:"tenantApprovers"`
+	// AllowAuthorApproval is whether the request's author may approve it.
+	AllowAuthorApproval bool `json:"allowAuthorApproval"`
+	// AutoApply is whether Traffic Ops applies the request as soon as it's approved. If it's false, approved requests become pending, and must be applied explicitly. The default is true.
+	AutoApply   *bool      `json:"autoApply"`
+	LastUpdated *TimeNoMod `json:"lastUpdated"`
+}
+
+// DefaultDeliveryServiceRequestPolicy returns the policy of requests which no policy applies to: a single approval by anyone but the author, applied automatically.
+func DefaultDeliveryServiceRequestPolicy() DeliveryServiceRequestPolicy {
+	autoApply := true
+	return DeliveryServiceRequestPolicy{Name: "default", RequiredApprovals: 1, AutoApply: &autoApply}
+}
+
+// Sanitize sets the defaults of the policy's optional fields.
+func (p *DeliveryServiceRequestPolicy) Sanitize() {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.ChangeType != nil && strings.TrimSpace(*p.ChangeType) == "" {
+		p.ChangeType = nil
+	}
+	if p.RequiredApprovals == 0 {
+		p.RequiredApprovals = 1
+	}
+	if p.AutoApply == nil {
+		autoApply := true
+		p.AutoApply = &autoApply
+	}
+}
+
+// Validate returns an error if the policy is invalid, not including whether its tenant exists. It should be called after Sanitize.
+func (p *DeliveryServiceRequestPolicy) Validate() error {
+	errs := []string{}
+	if p.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	if p.ChangeType != nil && *p.ChangeType != DeliveryServiceRequestChangeTypeCreate && *p.ChangeType != DeliveryServiceRequestChangeTypeUpdate && *p.ChangeType != DeliveryServiceRequestChangeTypeDelete {
+		errs = append(errs, "changeType must be one of create, update, or delete")
+	}
+	if p.RequiredApprovals < 1 {
+		errs = append(errs, "requiredApprovals must be at least 1")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// DeliveryServiceRequestApproval is a user's approval of a delivery service request.
+type DeliveryServiceRequestApproval struct {
+	UserID    int       `json:"userId"`
+	User      string    `json:"user"`
+	CreatedAt TimeNoMod `json:"createdAt"`
+	// Counted is whether the approval counts toward the policy's required approvals. Approvals by the request's author don't, unless the policy allows it, and neither do approvals by users of other tenants, if the policy requires tenant approvers.
+	Counted bool `json:"counted"`
+}
+
+// DeliveryServiceRequestApprovals is the approval state of a delivery service request.
+type DeliveryServiceRequestApprovals struct {
+	RequestID int                              `json:"requestId"`
+	Policy    DeliveryServiceRequestPolicy     `json:"policy"`
+	Approvals []DeliveryServiceRequestApproval `json:"approvals"`
+	// Approved is whether the counted approvals satisfy the policy.
+	Approved bool `json:"approved"`
+	// Request is the request, after it was approved. It's only set in responses to approvals, so clients can see whether it was applied.
+	Request *DeliveryServiceRequestNullable `json:"request,omitempty"`
+}
+
+// DeliveryServiceRequestPoliciesResponse is the response to a GET /deliveryservice_request_policies request.
+type DeliveryServiceRequestPoliciesResponse struct {
+	Response []DeliveryServiceRequestPolicy `json:"response"`
+}
+
+// DeliveryServiceRequestPolicyResponse is the response to a POST or PUT /deliveryservice_request_policies request.
+type DeliveryServiceRequestPolicyResponse struct {
+	Response DeliveryServiceRequestPolicy `json:"response"`
+	Alerts
+}
+
+// DeliveryServiceRequestApprovalsResponse is the response to a GET or POST /deliveryservice_requests/{id}/approvals request.
+type DeliveryServiceRequestApprovalsResponse struct {
+	Response DeliveryServiceRequestApprovals `json:"response"`
+	Alerts
+}
+
+// DeliveryServiceRequestApplyResponse is the response to a POST /deliveryservice_requests/{id}/apply request.
+type DeliveryServiceRequestApplyResponse struct {
+	Response DeliveryServiceRequestNullable `json:"response"`
+	Alerts
+}
@@ -0,0 +1,60 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeliveryServiceRequestPolicySanitize(t *testing.T) {
+	changeType := " "
+	p := DeliveryServiceRequestPolicy{Name: " two-person ", ChangeType: &changeType}
+	p.Sanitize()
+	if p.Name != "two-person" {
+		t.Errorf("expected name 'two-person', actual '%s'", p.Name)
+	}
+	if p.ChangeType != nil {
+		t.Errorf("expected blank change type to be nil, actual '%s'", *p.ChangeType)
+	}
+	if p.RequiredApprovals != 1 {
+		t.Errorf("expected default required approvals 1, actual %d", p.RequiredApprovals)
+	}
+	if p.AutoApply == nil || !*p.AutoApply {
+		t.Errorf("expected default auto apply true, actual %v", p.AutoApply)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("expected sanitized policy to be valid, actual error: %v", err)
+	}
+}
+
+func TestDeliveryServiceRequestPolicyValidate(t *testing.T) {
+	changeType := "rename"
+	p := DeliveryServiceRequestPolicy{ChangeType: &changeType, RequiredApprovals: -1}
+	err := p.Validate()
+	if err == nil {
+		t.Fatal("expected invalid policy to return an error, actual nil")
+	}
+	for _, expected := range []string{"name is required", "changeType must be one of", "requiredApprovals must be at least 1"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain '%s', actual '%s'", expected, err.Error())
+		}
+	}
+}
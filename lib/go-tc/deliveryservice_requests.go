@@ -422,6 +422,30 @@ type DeliveryServiceRequestNullable struct {
 	DeliveryService *DeliveryServiceNullable `json:"deliveryService" db:"deliveryservice"` // TODO version DeliveryServiceRequest
 	Status          *RequestStatus           `json:"status" db:"status"`
 	XMLID           *string                  `json:"-" db:"xml_id"`
+	// AppliedAt is when Traffic Ops applied the request's delivery service, after it was approved. It's nil if the request hasn't been applied.
+	AppliedAt *TimeNoMod `json:"appliedAt,omitempty" db:"applied_at"`
+	// AppliedLogID is the ID of the change log entry of the request's application. It's nil if the request hasn't been applied, or the entry has been deleted.
+	AppliedLogID *IDNoMod `json:"appliedLogId,omitempty" db:"applied_log"`
+	// AppliedDiff is the difference the request made to its delivery service, when it was applied.
+	AppliedDiff *DeliveryServiceRequestDiff `json:"appliedDiff,omitempty" db:"applied_diff"`
+}
+
+// DeliveryServiceRequestDiff is the difference between a delivery service before and after a delivery service request was applied to it, sorted by path.
+// Every field of created delivery services is added, and every field of deleted ones is removed.
+type DeliveryServiceRequestDiff []SnapshotDiffEntry
+
+// Value implements the driver.Valuer interface
+func (d DeliveryServiceRequestDiff) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan implements the sql.Scanner interface
+func (d *DeliveryServiceRequestDiff) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("expected deliveryservice request diff in byte array form; got %T", src)
+	}
+	return json.Unmarshal(b, d)
 }
 
 // UnmarshalJSON implements the json.Unmarshaller interface to suppress unmarshalling for IDNoMod
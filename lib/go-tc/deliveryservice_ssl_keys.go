@@ -331,3 +331,35 @@ func (r *CDNGenerateKSKReq) Sanitize() {
 		r.EffectiveDate = &now
 	}
 }
+
+// CDNSSLKeyInventoryResponse contains the result data from a GET /cdns/name/{name}/sslkeys/inventory request.
+type CDNSSLKeyInventoryResponse struct {
+	Response []CDNSSLKeyInventoryItem `json:"response"`
+}
+
+// CDNSSLKeyInventoryItem is the parsed state of a delivery service's SSL certificate.
+//
+// Missing is true for HTTPS delivery services with no certificate, in which case all certificate fields are empty. Errors contains any problems parsing the certificate or key; certificate fields which could not be determined are empty.
+type CDNSSLKeyInventoryItem struct {
+	DeliveryService string     `json:"deliveryservice"`
+	HostName        string     `json:"hostname"`
+	Missing         bool       `json:"missing"`
+	Subject         string     `json:"subject"`
+	SANs            []string   `json:"sans"`
+	Issuer          string     `json:"issuer"`
+	NotBefore       *time.Time `json:"notBefore"`
+	NotAfter        *time.Time `json:"notAfter"`
+	DaysUntilExpiry *int       `json:"daysUntilExpiry"`
+	Expired         bool       `json:"expired"`
+	ChainValid      bool       `json:"chainValid"`
+	ChainError      *string    `json:"chainError"`
+	KeyMatches      bool       `json:"keyMatches"`
+	HostsMatch      bool       `json:"hostsMatch"`
+	UnmatchedHosts  []string   `json:"unmatchedHosts"`
+	Errors          []string   `json:"errors"`
+}
+
+// HasProblem returns whether the certificate is missing, expired, unparseable, or doesn't match its key, chain, or delivery service hosts.
+func (i CDNSSLKeyInventoryItem) HasProblem() bool {
+	return i.Missing || i.Expired || !i.ChainValid || !i.KeyMatches || !i.HostsMatch || len(i.Errors) > 0
+}
@@ -165,6 +165,7 @@ type DeliveryServiceNullable struct {
 	ConsistentHashRegex       *string  `json:"consistentHashRegex"`
 	ConsistentHashQueryParams []string `json:"consistentHashQueryParams"`
 	MaxOriginConnections      *int     `json:"maxOriginConnections" db:"max_origin_connections"`
+	Topology                  *string  `json:"topology" db:"topology"`
 }
 
 type DeliveryServiceNullableV13 struct {
@@ -333,6 +334,9 @@ func (ds *DeliveryServiceNullable) Sanitize() {
 	if !ds.Signed && ds.SigningAlgorithm != nil && *ds.SigningAlgorithm == signedAlgorithm {
 		ds.Signed = true
 	}
+	if ds.Topology != nil && strings.TrimSpace(*ds.Topology) == "" {
+		ds.Topology = nil
+	}
 	if ds.MaxOriginConnections == nil || *ds.MaxOriginConnections < 0 {
 		ds.MaxOriginConnections = util.IntPtr(0)
 	}
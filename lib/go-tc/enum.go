@@ -78,6 +78,8 @@ const OriginTypeName = "ORG"
 
 const CacheGroupOriginTypeName = "ORG_LOC"
 
+const CacheGroupEdgeTypeName = "EDGE_LOC"
+
 const GlobalProfileName = "GLOBAL"
 
 func (c CacheName) String() string {
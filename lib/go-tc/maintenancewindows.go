@@ -0,0 +1,169 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindowState is the state of a maintenance window.
+type MaintenanceWindowState string
+
+const (
+	// MaintenanceWindowStateScheduled is a window which hasn't started.
+	MaintenanceWindowStateScheduled = MaintenanceWindowState("scheduled")
+	// MaintenanceWindowStateActive is a window which has started, and whose servers have been given its status.
+	MaintenanceWindowStateActive = MaintenanceWindowState("active")
+	// MaintenanceWindowStateCompleted is a window which has ended, and whose servers' statuses have been reverted.
+	MaintenanceWindowStateCompleted = MaintenanceWindowState("completed")
+	// MaintenanceWindowStateMissed is a window which ended before Traffic Ops could start it, so its servers were never changed.
+	MaintenanceWindowStateMissed = MaintenanceWindowState("missed")
+)
+
+// MaintenanceWindow is a period during which a set of servers is given a status, by default ADMIN_DOWN. Traffic Ops changes the servers' statuses when the window starts, and reverts them when it ends.
+// The servers are those in Servers, plus all the servers in CacheGroup, if it isn't nil; the servers of the cache group are determined when the window starts.
+type MaintenanceWindow struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Servers     []int     `json:"servers"`
+	CacheGroup  *string   `json:"cachegroup"`
+	Status      string    `json:"status"`
+	// OfflineReason is the offline reason given to the servers, prefixed by the name of the user who scheduled the window. If it's empty, the window's name is used.
+	OfflineReason string `json:"offlineReason"`
+	// QueueUpdates is whether to queue updates on the child caches of the servers when the window starts and ends. The default is true.
+	QueueUpdates *bool `json:"queueUpdates"`
+	// Snapshot is whether to snapshot the CDNs of the servers when the window starts and ends.
+	Snapshot bool `json:"snapshot"`
+
+	// The following are set by Traffic Ops, and ignored in requests.
+
+	State MaintenanceWindowState `json:"state"`
+	// User is the user who last created or updated the window. The status changes are made, and recorded in the change log, as this user.
+	User      string  `json:"user"`
+	LastError *string `json:"lastError"`
+	// Warnings are the problems with the window which don't prevent it from being scheduled, such as overlapping other windows, or taking too many servers of a cache group out of service.
+	Warnings    []string   `json:"warnings"`
+	LastUpdated *TimeNoMod `json:"lastUpdated"`
+}
+
+// Sanitize sets the defaults of the window's optional fields.
+func (w *MaintenanceWindow) Sanitize() {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Status == "" {
+		w.Status = CacheStatusAdminDown.String()
+	}
+	if w.OfflineReason == "" {
+		w.OfflineReason = "maintenance window " + w.Name
+	}
+	if w.QueueUpdates == nil {
+		queueUpdates := true
+		w.QueueUpdates = &queueUpdates
+	}
+	if w.CacheGroup != nil && strings.TrimSpace(*w.CacheGroup) == "" {
+		w.CacheGroup = nil
+	}
+	if w.Servers == nil {
+		w.Servers = []int{}
+	}
+	sort.Ints(w.Servers)
+}
+
+// Validate returns an error if the window is invalid, not including whether its servers, cache group, and status exist. It should be called after Sanitize.
+func (w *MaintenanceWindow) Validate() error {
+	errs := []string{}
+	if w.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	if w.Start.IsZero() {
+		errs = append(errs, "start is required")
+	}
+	if w.End.IsZero() {
+		errs = append(errs, "end is required")
+	} else if !w.End.After(w.Start) {
+		errs = append(errs, "end must be after start")
+	}
+	if len(w.Servers) == 0 && w.CacheGroup == nil {
+		errs = append(errs, "servers or cachegroup is required")
+	}
+	for i := 1; i < len(w.Servers); i++ {
+		if w.Servers[i] == w.Servers[i-1] {
+			errs = append(errs, "server "+strconv.Itoa(w.Servers[i])+" is duplicated")
+		}
+	}
+	if w.Status == CacheStatusOnline.String() || w.Status == CacheStatusReported.String() {
+		errs = append(errs, "status must not be "+w.Status+"; maintenance windows take servers out of service")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ActiveChanges returns the names of the fields of updated which differ from the window, other than those which may be changed while the window is active: its name, description, and end. Both windows must be sanitized.
+func (w *MaintenanceWindow) ActiveChanges(updated MaintenanceWindow) []string {
+	changed := []string{}
+	if !w.Start.Equal(updated.Start) {
+		changed = append(changed, "start")
+	}
+	if len(w.Servers) != len(updated.Servers) {
+		changed = append(changed, "servers")
+	} else {
+		for i, server := range w.Servers {
+			if updated.Servers[i] != server {
+				changed = append(changed, "servers")
+				break
+			}
+		}
+	}
+	if (w.CacheGroup == nil) != (updated.CacheGroup == nil) || (w.CacheGroup != nil && *w.CacheGroup != *updated.CacheGroup) {
+		changed = append(changed, "cachegroup")
+	}
+	if w.Status != updated.Status {
+		changed = append(changed, "status")
+	}
+	if w.OfflineReason != updated.OfflineReason {
+		changed = append(changed, "offlineReason")
+	}
+	if *w.QueueUpdates != *updated.QueueUpdates {
+		changed = append(changed, "queueUpdates")
+	}
+	if w.Snapshot != updated.Snapshot {
+		changed = append(changed, "snapshot")
+	}
+	return changed
+}
+
+// MaintenanceWindowsResponse is the response to a GET /maintenance_windows request.
+type MaintenanceWindowsResponse struct {
+	Response []MaintenanceWindow `json:"response"`
+}
+
+// MaintenanceWindowResponse is the response to a POST or PUT /maintenance_windows request.
+type MaintenanceWindowResponse struct {
+	Response MaintenanceWindow `json:"response"`
+	Alerts
+}
@@ -0,0 +1,107 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowSanitize(t *testing.T) {
+	cg := " "
+	w := MaintenanceWindow{Name: " drain ", Servers: []int{3, 1, 2}, CacheGroup: &cg}
+	w.Sanitize()
+	if w.Name != "drain" {
+		t.Errorf("expected name 'drain', actual '%s'", w.Name)
+	}
+	if w.Status != "ADMIN_DOWN" {
+		t.Errorf("expected default status ADMIN_DOWN, actual '%s'", w.Status)
+	}
+	if w.OfflineReason != "maintenance window drain" {
+		t.Errorf("expected default offline reason, actual '%s'", w.OfflineReason)
+	}
+	if w.QueueUpdates == nil || !*w.QueueUpdates {
+		t.Errorf("expected queueUpdates to default to true, actual %v", w.QueueUpdates)
+	}
+	if w.CacheGroup != nil {
+		t.Errorf("expected blank cachegroup to be nil, actual '%s'", *w.CacheGroup)
+	}
+	if !reflect.DeepEqual(w.Servers, []int{1, 2, 3}) {
+		t.Errorf("expected sorted servers, actual %v", w.Servers)
+	}
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	w := MaintenanceWindow{Name: "drain", Start: start, End: end, Servers: []int{1}}
+	w.Sanitize()
+	if err := w.Validate(); err != nil {
+		t.Fatalf("expected valid window, actual error: %v", err)
+	}
+
+	cg := "edge"
+	invalids := map[string]MaintenanceWindow{
+		"name is required":   {Start: start, End: end, Servers: []int{1}},
+		"end must be after":  {Name: "backwards", Start: end, End: start, Servers: []int{1}},
+		"servers or":         {Name: "empty", Start: start, End: end},
+		"duplicated":         {Name: "dup", Start: start, End: end, Servers: []int{1, 1}},
+		"status must not be": {Name: "online", Start: start, End: end, CacheGroup: &cg, Status: "REPORTED"},
+		"start is required":  {Name: "nostart", End: end, Servers: []int{1}},
+		"end is required":    {Name: "noend", Start: start, Servers: []int{1}},
+	}
+	for expected, w := range invalids {
+		w.Sanitize()
+		err := w.Validate()
+		if err == nil {
+			t.Errorf("expected error containing '%s', actual: nil", expected)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing '%s', actual: %v", expected, err)
+		}
+	}
+}
+
+func TestMaintenanceWindowActiveChanges(t *testing.T) {
+	start := time.Now()
+	w := MaintenanceWindow{Name: "drain", Start: start, End: start.Add(time.Hour), Servers: []int{1, 2}}
+	w.Sanitize()
+
+	updated := w
+	updated.Name = "renamed"
+	updated.Description = "longer"
+	updated.End = start.Add(2 * time.Hour)
+	updated.Sanitize()
+	if changed := w.ActiveChanges(updated); len(changed) != 0 {
+		t.Errorf("expected no changes, actual %v", changed)
+	}
+
+	cg := "edge"
+	queueUpdates := false
+	updated.Servers = []int{1, 3}
+	updated.CacheGroup = &cg
+	updated.QueueUpdates = &queueUpdates
+	updated.Snapshot = true
+	expected := []string{"servers", "cachegroup", "queueUpdates", "snapshot"}
+	if changed := w.ActiveChanges(updated); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected changes %v, actual %v", expected, changed)
+	}
+}
@@ -0,0 +1,51 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// RateLimitStats is the state of a rate limit, of one kind of client of one group of routes.
+type RateLimitStats struct {
+	// Group is the group of routes: default, ort, or monitor.
+	Group string `json:"group"`
+	// Kind is the kind of client: user or ip.
+	Kind              string  `json:"kind"`
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+	// Clients is the number of clients with buckets, which made requests in the last idle period.
+	Clients int `json:"clients"`
+	// Allowed and Limited are the numbers of requests allowed and limited since Traffic Ops started.
+	Allowed uint64 `json:"allowed"`
+	Limited uint64 `json:"limited"`
+	// TopLimited is the clients with the most limited requests, at most 10.
+	TopLimited []RateLimitClientStats `json:"topLimited"`
+}
+
+// RateLimitClientStats is the state of the bucket of a single client.
+type RateLimitClientStats struct {
+	// Client is the user name or IP address of the client.
+	Client  string  `json:"client"`
+	Tokens  float64 `json:"tokens"`
+	Allowed uint64  `json:"allowed"`
+	Limited uint64  `json:"limited"`
+}
+
+// RateLimitStatsResponse is the response to a GET /rate_limits/stats request.
+type RateLimitStatsResponse struct {
+	Response []RateLimitStats `json:"response"`
+}
@@ -67,3 +67,16 @@ type RoleV11 struct {
 	// required: true
 	PrivLevel *int `json:"privLevel" db:"priv_level"`
 }
+
+// CurrentUserCapabilities is the capabilities the current user is authorized for.
+type CurrentUserCapabilities struct {
+	PrivLevel int `json:"privLevel"`
+	// Default is whether the capabilities are the defaults for the user's privilege level, because the user's role has no capabilities.
+	Default      bool     `json:"default"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// CurrentUserCapabilitiesResponse contains the result data from a GET /user/current/capabilities request.
+type CurrentUserCapabilitiesResponse struct {
+	Response CurrentUserCapabilities `json:"response"`
+}
@@ -0,0 +1,83 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+)
+
+// SnapshotVersion is a single historical CRConfig and monitoring snapshot of a CDN, without the snapshot content itself.
+type SnapshotVersion struct {
+	ID          int       `json:"id" db:"id"`
+	CDN         string    `json:"cdn" db:"cdn"`
+	User        *string   `json:"user" db:"tm_user"`
+	LastUpdated TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// SnapshotVersionsResponse contains the result data from a GET /cdns/{cdn}/snapshot/history request.
+type SnapshotVersionsResponse struct {
+	Response []SnapshotVersion `json:"response"`
+}
+
+// SnapshotVersionContent is a historical snapshot of a CDN, including the CRConfig and monitoring content.
+type SnapshotVersionContent struct {
+	SnapshotVersion
+	CRConfig   json.RawMessage `json:"crconfig"`
+	Monitoring json.RawMessage `json:"monitoring"`
+}
+
+// SnapshotVersionContentResponse contains the result data from a GET /cdns/{cdn}/snapshot/history/{id} request.
+type SnapshotVersionContentResponse struct {
+	Response SnapshotVersionContent `json:"response"`
+}
+
+// SnapshotDiffType is the kind of change a single SnapshotDiffEntry represents.
+type SnapshotDiffType string
+
+const (
+	SnapshotDiffAdded   = SnapshotDiffType("added")
+	SnapshotDiffRemoved = SnapshotDiffType("removed")
+	SnapshotDiffChanged = SnapshotDiffType("changed")
+)
+
+// SnapshotDiffEntry is a single difference between two snapshots.
+// The Path is the dot-separated path of JSON object keys and array indices to the value which differs.
+// Old is nil for added values, and New is nil for removed values.
+type SnapshotDiffEntry struct {
+	Path string           `json:"path"`
+	Type SnapshotDiffType `json:"type"`
+	Old  interface{}      `json:"old,omitempty"`
+	New  interface{}      `json:"new,omitempty"`
+}
+
+// SnapshotDiff is the difference between two snapshots of the same CDN.
+// From and To are the snapshot history IDs compared; a nil To means the compared snapshot was generated from the current data, as by /cdns/{cdn}/snapshot/new.
+type SnapshotDiff struct {
+	CDN        string              `json:"cdn"`
+	From       int                 `json:"from"`
+	To         *int                `json:"to"`
+	CRConfig   []SnapshotDiffEntry `json:"crconfig"`
+	Monitoring []SnapshotDiffEntry `json:"monitoring"`
+}
+
+// SnapshotDiffResponse contains the result data from a GET /cdns/{cdn}/snapshot/diff request.
+type SnapshotDiffResponse struct {
+	Response SnapshotDiff `json:"response"`
+}
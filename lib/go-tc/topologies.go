@@ -0,0 +1,193 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TopologyMaxParents is the maximum number of parents of a topology node: a primary and a secondary.
+const TopologyMaxParents = 2
+
+var topologyNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Topology is a named graph of cache groups, which delivery services may be assigned to, instead of using the parents of their servers' cache groups.
+// Each node's Parents are indices into Nodes: the first is the primary parent, and the second, if any, is the secondary parent.
+// Nodes which aren't the parent of any other node are the edge tier of the topology; nodes without parents are the top tier, whose caches go to the origin.
+type Topology struct {
+	Name        string         `json:"name" db:"name"`
+	Description string         `json:"description" db:"description"`
+	Nodes       []TopologyNode `json:"nodes"`
+	LastUpdated *TimeNoMod     `json:"lastUpdated" db:"last_updated"`
+}
+
+// TopologyNode is a cache group in a Topology.
+type TopologyNode struct {
+	CacheGroup string `json:"cachegroup"`
+	Parents    []int  `json:"parents"`
+}
+
+// TopologiesResponse is the response to a GET /topologies request.
+type TopologiesResponse struct {
+	Response []Topology `json:"response"`
+}
+
+// TopologyResponse is the response to a POST or PUT /topologies request.
+type TopologyResponse struct {
+	Response Topology `json:"response"`
+	Alerts
+}
+
+// Validate returns an error if the topology is missing required fields, has duplicate cache groups or invalid parents, or has a cycle. Whether the cache groups exist is checked by Traffic Ops.
+func (t Topology) Validate() error {
+	errs := []string{}
+	if t.Name == "" {
+		errs = append(errs, "name is required")
+	} else if !topologyNameRegex.MatchString(t.Name) {
+		errs = append(errs, "name may only contain alphanumeric characters, '-', and '_'")
+	}
+	if len(t.Nodes) == 0 {
+		errs = append(errs, "at least one node is required")
+	}
+
+	cacheGroups := map[string]struct{}{}
+	for i, node := range t.Nodes {
+		nodeName := "node " + strconv.Itoa(i)
+		if node.CacheGroup == "" {
+			errs = append(errs, nodeName+" cachegroup is required")
+		} else {
+			nodeName += " '" + node.CacheGroup + "'"
+			if _, ok := cacheGroups[node.CacheGroup]; ok {
+				errs = append(errs, "duplicate cachegroup '"+node.CacheGroup+"'")
+			}
+			cacheGroups[node.CacheGroup] = struct{}{}
+		}
+		if len(node.Parents) > TopologyMaxParents {
+			errs = append(errs, nodeName+" has more than "+strconv.Itoa(TopologyMaxParents)+" parents")
+		}
+		for j, parent := range node.Parents {
+			if parent < 0 || parent >= len(t.Nodes) {
+				errs = append(errs, nodeName+" parent "+strconv.Itoa(parent)+" is not a node")
+			} else if parent == i {
+				errs = append(errs, nodeName+" is its own parent")
+			} else if j > 0 && parent == node.Parents[0] {
+				errs = append(errs, nodeName+" primary and secondary parents are the same")
+			}
+		}
+	}
+	if len(errs) == 0 {
+		if cycle := t.findCycle(); len(cycle) > 0 {
+			errs = append(errs, "parents form a cycle: "+strings.Join(cycle, " -> "))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, ", "))
+}
+
+// findCycle returns the cache groups of a parent cycle in the topology, or nil if there are no cycles. The topology's parent indices must be valid.
+func (t Topology) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(t.Nodes))
+	path := []int{}
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		switch states[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := []string{}
+			for j := len(path) - 1; j >= 0; j-- {
+				if path[j] == i {
+					for _, k := range path[j:] {
+						cycle = append(cycle, t.Nodes[k].CacheGroup)
+					}
+					break
+				}
+			}
+			return append(cycle, t.Nodes[i].CacheGroup)
+		}
+		states[i] = visiting
+		path = append(path, i)
+		for _, parent := range t.Nodes[i].Parents {
+			if cycle := visit(parent); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		states[i] = visited
+		return nil
+	}
+	for i := range t.Nodes {
+		if cycle := visit(i); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// CacheGroupParents returns the primary and secondary parent cache groups of the given cache group in the topology, which are empty if it has none, and whether the cache group is in the topology.
+func (t Topology) CacheGroupParents(cacheGroup string) (string, string, bool) {
+	for _, node := range t.Nodes {
+		if node.CacheGroup != cacheGroup {
+			continue
+		}
+		primary := ""
+		secondary := ""
+		if len(node.Parents) > 0 && node.Parents[0] >= 0 && node.Parents[0] < len(t.Nodes) {
+			primary = t.Nodes[node.Parents[0]].CacheGroup
+		}
+		if len(node.Parents) > 1 && node.Parents[1] >= 0 && node.Parents[1] < len(t.Nodes) {
+			secondary = t.Nodes[node.Parents[1]].CacheGroup
+		}
+		return primary, secondary, true
+	}
+	return "", "", false
+}
+
+// IsEdge returns whether the given cache group is in the edge tier of the topology, that is, whether it's in the topology and isn't the parent of any other node.
+func (t Topology) IsEdge(cacheGroup string) bool {
+	index := -1
+	for i, node := range t.Nodes {
+		if node.CacheGroup == cacheGroup {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false
+	}
+	for _, node := range t.Nodes {
+		for _, parent := range node.Parents {
+			if parent == index {
+				return false
+			}
+		}
+	}
+	return true
+}
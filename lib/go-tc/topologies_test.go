@@ -0,0 +1,94 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopologyValidate(t *testing.T) {
+	topology := Topology{
+		Name: "three-tier",
+		Nodes: []TopologyNode{
+			{CacheGroup: "edge1", Parents: []int{2, 3}},
+			{CacheGroup: "edge2", Parents: []int{2}},
+			{CacheGroup: "mid1", Parents: []int{4}},
+			{CacheGroup: "mid2", Parents: []int{4}},
+			{CacheGroup: "mid3"},
+		},
+	}
+	if err := topology.Validate(); err != nil {
+		t.Fatalf("expected valid topology, actual error: %v", err)
+	}
+
+	invalids := map[string]Topology{
+		"name":       {Name: "has space", Nodes: []TopologyNode{{CacheGroup: "edge"}}},
+		"node":       {Name: "empty"},
+		"duplicate":  {Name: "dup", Nodes: []TopologyNode{{CacheGroup: "edge"}, {CacheGroup: "edge"}}},
+		"not a node": {Name: "range", Nodes: []TopologyNode{{CacheGroup: "edge", Parents: []int{1}}}},
+		"own parent": {Name: "self", Nodes: []TopologyNode{{CacheGroup: "edge", Parents: []int{0}}}},
+		"same":       {Name: "same", Nodes: []TopologyNode{{CacheGroup: "edge", Parents: []int{1, 1}}, {CacheGroup: "mid"}}},
+		"more than":  {Name: "many", Nodes: []TopologyNode{{CacheGroup: "edge", Parents: []int{1, 2, 3}}, {CacheGroup: "a"}, {CacheGroup: "b"}, {CacheGroup: "c"}}},
+		"cycle":      {Name: "cycle", Nodes: []TopologyNode{{CacheGroup: "edge", Parents: []int{1}}, {CacheGroup: "a", Parents: []int{2}}, {CacheGroup: "b", Parents: []int{1}}}},
+	}
+	for expected, topology := range invalids {
+		err := topology.Validate()
+		if err == nil {
+			t.Errorf("expected error containing '%s', actual: nil", expected)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing '%s', actual: %v", expected, err)
+		}
+	}
+}
+
+func TestTopologyCacheGroupParents(t *testing.T) {
+	topology := Topology{
+		Name: "three-tier",
+		Nodes: []TopologyNode{
+			{CacheGroup: "edge", Parents: []int{1, 2}},
+			{CacheGroup: "mid1", Parents: []int{3}},
+			{CacheGroup: "mid2", Parents: []int{3}},
+			{CacheGroup: "top"},
+		},
+	}
+
+	if primary, secondary, ok := topology.CacheGroupParents("edge"); !ok || primary != "mid1" || secondary != "mid2" {
+		t.Errorf("expected edge parents mid1 mid2 true, actual: %v %v %v", primary, secondary, ok)
+	}
+	if primary, secondary, ok := topology.CacheGroupParents("mid2"); !ok || primary != "top" || secondary != "" {
+		t.Errorf("expected mid2 parents top '' true, actual: %v %v %v", primary, secondary, ok)
+	}
+	if primary, secondary, ok := topology.CacheGroupParents("top"); !ok || primary != "" || secondary != "" {
+		t.Errorf("expected top parents '' '' true, actual: %v %v %v", primary, secondary, ok)
+	}
+	if _, _, ok := topology.CacheGroupParents("other"); ok {
+		t.Errorf("expected cachegroup not in topology to not be found, actual: found")
+	}
+
+	if !topology.IsEdge("edge") {
+		t.Errorf("expected edge to be an edge")
+	}
+	for _, cg := range []string{"mid1", "top", "other"} {
+		if topology.IsEdge(cg) {
+			t.Errorf("expected %v to not be an edge", cg)
+		}
+	}
+}
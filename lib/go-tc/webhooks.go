@@ -0,0 +1,101 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// WebhookSignatureHeader is the header of webhook deliveries containing the signature of the body, as returned by WebhookSignature.
+const WebhookSignatureHeader = "X-TrafficOps-Signature"
+
+// WebhookDeliveryHeader is the header of webhook deliveries containing the ID of the delivery, which is the same for every attempt.
+const WebhookDeliveryHeader = "X-TrafficOps-Delivery"
+
+// WebhookSignature returns the signature of a webhook delivery body with the given secret: "sha256=" followed by the hex HMAC-SHA256 of the body.
+// Receivers should compute the signature of the body they received, and compare it to the WebhookSignatureHeader with hmac.Equal.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookNullable is a subscription to deliver change log entries to a URL.
+// The ObjectType, CDN, and Level filter which change log entries are delivered; a nil filter matches every entry.
+// The Secret is used to sign deliveries. It is never returned, except in the response to creating the webhook.
+type WebhookNullable struct {
+	ID          *int       `json:"id" db:"id"`
+	Name        *string    `json:"name" db:"name"`
+	URL         *string    `json:"url" db:"url"`
+	Secret      *string    `json:"secret,omitempty" db:"secret"`
+	ObjectType  *string    `json:"objectType" db:"object_type"`
+	CDN         *string    `json:"cdn" db:"cdn"`
+	Level       *string    `json:"level" db:"level"`
+	Active      *bool      `json:"active" db:"active"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// WebhooksResponse contains the result data from a GET /webhooks request.
+type WebhooksResponse struct {
+	Response []WebhookNullable `json:"response"`
+}
+
+// ChangeLogEvent is a change log entry, as delivered to webhooks and written to the change log stream.
+// The ObjectType is the type of object the entry is about, parsed from the message, or nil if the message doesn't say.
+type ChangeLogEvent struct {
+	ID          int     `json:"id"`
+	Level       string  `json:"level"`
+	Message     string  `json:"message"`
+	User        string  `json:"user"`
+	TicketNum   *string `json:"ticketNum"`
+	ObjectType  *string `json:"objectType"`
+	LastUpdated Time    `json:"lastUpdated"`
+}
+
+// WebhookDeliveryStatus is the state of a WebhookDelivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   = WebhookDeliveryStatus("pending")
+	WebhookDeliveryDelivered = WebhookDeliveryStatus("delivered")
+	WebhookDeliveryDead      = WebhookDeliveryStatus("dead")
+)
+
+// WebhookDelivery is the delivery of a single change log entry to a webhook.
+// Pending deliveries are attempted until they succeed, or fail too many times, in which case they are dead, and are not attempted again unless retried.
+type WebhookDelivery struct {
+	ID          int                   `json:"id" db:"id"`
+	WebhookID   int                   `json:"webhookId" db:"webhook"`
+	LogID       int                   `json:"logId" db:"log"`
+	Status      WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts    int                   `json:"attempts" db:"attempts"`
+	NextAttempt *TimeNoMod            `json:"nextAttempt" db:"next_attempt"`
+	LastError   *string               `json:"lastError" db:"last_error"`
+	Event       json.RawMessage       `json:"event" db:"payload"`
+	LastUpdated TimeNoMod             `json:"lastUpdated" db:"last_updated"`
+}
+
+// WebhookDeliveriesResponse contains the result data from a GET /webhooks/{id}/deliveries request.
+type WebhookDeliveriesResponse struct {
+	Response []WebhookDelivery `json:"response"`
+}
@@ -21,7 +21,9 @@ package util
 
 import (
 	"bytes"
+	"math/big"
 	"net"
+	"sort"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 )
@@ -200,3 +202,130 @@ func IPToCIDR(ip net.IP) *net.IPNet {
 	}
 	return &net.IPNet{IP: ip, Mask: fullMask}
 }
+
+// RangeCIDRs returns the smallest list of CIDRs which contain exactly the IPs from first to last, inclusive.
+// For example, the range 192.0.2.0-192.0.2.130 returns 192.0.2.0/25, 192.0.2.128/31, and 192.0.2.130/32.
+// Returns nil if first and last aren't the same IP version, or first is after last.
+func RangeCIDRs(first net.IP, last net.IP) []*net.IPNet {
+	if first4, last4 := first.To4(), last.To4(); first4 != nil && last4 != nil {
+		first, last = first4, last4
+	} else if first4 == nil && last4 == nil {
+		first, last = first.To16(), last.To16()
+	} else {
+		return nil
+	}
+	if first == nil || last == nil {
+		return nil
+	}
+
+	bits := len(first) * BitsPerByte
+	start := new(big.Int).SetBytes(first)
+	end := new(big.Int).SetBytes(last)
+	if start.Cmp(end) > 0 {
+		return nil
+	}
+	one := big.NewInt(1)
+
+	cidrs := []*net.IPNet{}
+	for start.Cmp(end) <= 0 {
+		// find the largest block starting at start which is aligned and doesn't pass end
+		hostBits := 0
+		for hostBits < bits {
+			size := new(big.Int).Lsh(one, uint(hostBits+1))
+			if new(big.Int).Mod(start, size).Sign() != 0 {
+				break
+			}
+			if new(big.Int).Sub(new(big.Int).Add(start, size), one).Cmp(end) > 0 {
+				break
+			}
+			hostBits++
+		}
+
+		ip := make(net.IP, len(first))
+		startBts := start.Bytes()
+		copy(ip[len(ip)-len(startBts):], startBts)
+		cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits-hostBits, bits)})
+
+		start.Add(start, new(big.Int).Lsh(one, uint(hostBits)))
+	}
+	return cidrs
+}
+
+// AggregateCIDRs returns the smallest list of CIDRs which contain exactly the IPs of cidrs, by merging overlapping and adjacent networks.
+// The returned CIDRs are sorted, IPv4 before IPv6, so the result is the same for any order of cidrs.
+// For example, 192.0.2.0/25, 192.0.2.128/25, and 192.0.2.42/32 return 192.0.2.0/24.
+func AggregateCIDRs(cidrs []*net.IPNet) []*net.IPNet {
+	type ipRange struct {
+		first *big.Int
+		last  *big.Int
+	}
+	v4Ranges := []ipRange{}
+	v6Ranges := []ipRange{}
+	for _, cidr := range cidrs {
+		if cidr == nil {
+			continue
+		}
+		ip := cidr.IP.To4()
+		if ip == nil {
+			ip = cidr.IP.To16()
+		}
+		mask := cidr.Mask
+		switch {
+		case ip == nil:
+			continue
+		case len(mask) == net.IPv6len && len(ip) == net.IPv4len:
+			mask = mask[net.IPv6len-net.IPv4len:]
+		case len(mask) != len(ip):
+			continue
+		}
+		first := make([]byte, len(ip))
+		last := make([]byte, len(ip))
+		for i := range ip {
+			first[i] = ip[i] & mask[i]
+			last[i] = ip[i] | ^mask[i]
+		}
+		rng := ipRange{first: new(big.Int).SetBytes(first), last: new(big.Int).SetBytes(last)}
+		if len(ip) == net.IPv4len {
+			v4Ranges = append(v4Ranges, rng)
+		} else {
+			v6Ranges = append(v6Ranges, rng)
+		}
+	}
+
+	aggregated := []*net.IPNet{}
+	for _, ranges := range []struct {
+		ranges []ipRange
+		ipLen  int
+	}{{v4Ranges, net.IPv4len}, {v6Ranges, net.IPv6len}} {
+		rngs := ranges.ranges
+		if len(rngs) == 0 {
+			continue
+		}
+		sort.Slice(rngs, func(i, j int) bool { return rngs[i].first.Cmp(rngs[j].first) < 0 })
+
+		merged := []ipRange{rngs[0]}
+		for _, rng := range rngs[1:] {
+			prev := &merged[len(merged)-1]
+			if rng.first.Cmp(new(big.Int).Add(prev.last, big.NewInt(1))) > 0 {
+				merged = append(merged, rng)
+				continue
+			}
+			if rng.last.Cmp(prev.last) > 0 {
+				prev.last = rng.last
+			}
+		}
+
+		for _, rng := range merged {
+			aggregated = append(aggregated, RangeCIDRs(bigIntToIP(rng.first, ranges.ipLen), bigIntToIP(rng.last, ranges.ipLen))...)
+		}
+	}
+	return aggregated
+}
+
+// bigIntToIP returns the IP of ipLen bytes whose value is n.
+func bigIntToIP(n *big.Int, ipLen int) net.IP {
+	ip := make(net.IP, ipLen)
+	bts := n.Bytes()
+	copy(ip[ipLen-len(bts):], bts)
+	return ip
+}
@@ -18,6 +18,7 @@ package util
 
 import (
 	"net"
+	"strings"
 	"testing"
 )
 
@@ -145,6 +146,78 @@ func TestRangeStr(t *testing.T) {
 	}
 }
 
+func TestRangeCIDRs(t *testing.T) {
+	inputExpecteds := map[[2]string][]string{
+		{"192.0.2.0", "192.0.2.255"}:                      {"192.0.2.0/24"},
+		{"192.0.2.0", "192.0.2.130"}:                      {"192.0.2.0/25", "192.0.2.128/31", "192.0.2.130/32"},
+		{"192.0.2.1", "192.0.2.6"}:                        {"192.0.2.1/32", "192.0.2.2/31", "192.0.2.4/31", "192.0.2.6/32"},
+		{"192.0.2.42", "192.0.2.42"}:                      {"192.0.2.42/32"},
+		{"0.0.0.0", "255.255.255.255"}:                    {"0.0.0.0/0"},
+		{"2001:db8::", "2001:db8::ffff"}:                  {"2001:db8::/112"},
+		{"2001:db8::1", "2001:db8::2"}:                    {"2001:db8::1/128", "2001:db8::2/128"},
+		{"192.0.2.2", "192.0.2.1"}:                        nil,
+		{"192.0.2.1", "2001:db8::1"}:                      nil,
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}: {"::/0"},
+	}
+	for input, expected := range inputExpecteds {
+		cidrs := RangeCIDRs(net.ParseIP(input[0]), net.ParseIP(input[1]))
+		actual := []string{}
+		for _, cidr := range cidrs {
+			actual = append(actual, cidr.String())
+		}
+		if expected == nil {
+			if cidrs != nil {
+				t.Errorf("range %v expected: nil actual %v", input, actual)
+			}
+			continue
+		}
+		if strings.Join(expected, ",") != strings.Join(actual, ",") {
+			t.Errorf("range %v expected: %v actual %v", input, expected, actual)
+		}
+	}
+}
+
+func TestAggregateCIDRs(t *testing.T) {
+	inputExpecteds := map[string][]string{
+		"192.0.2.0/25,192.0.2.128/25":                   {"192.0.2.0/24"},
+		"192.0.2.42/32,192.0.2.0/24":                    {"192.0.2.0/24"},
+		"192.0.2.3/32,192.0.2.1/32,192.0.2.2/32":        {"192.0.2.1/32", "192.0.2.2/31"},
+		"198.51.100.0/24,192.0.2.0/24":                  {"192.0.2.0/24", "198.51.100.0/24"},
+		"2001:db8::/33,192.0.2.0/24,2001:db8:8000::/33": {"192.0.2.0/24", "2001:db8::/32"},
+		"2001:db8::1/128,2001:db8::3/128,::1/128":       {"::1/128", "2001:db8::1/128", "2001:db8::3/128"},
+		"0.0.0.0/0,10.0.0.0/8":                          {"0.0.0.0/0"},
+		"10.0.0.0/8,172.16.0.0/12,192.168.0.0/16":       {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		"255.255.255.254/32,255.255.255.255/32":         {"255.255.255.254/31"},
+		"":                                              {},
+	}
+	for input, expected := range inputExpecteds {
+		cidrs := []*net.IPNet{}
+		for _, cidrStr := range strings.Split(input, ",") {
+			if cidrStr == "" {
+				continue
+			}
+			_, cidr, err := net.ParseCIDR(cidrStr)
+			if err != nil {
+				t.Fatalf("parsing test CIDR '%v': %v", cidrStr, err)
+			}
+			cidrs = append(cidrs, cidr)
+		}
+		actual := []string{}
+		for _, cidr := range AggregateCIDRs(cidrs) {
+			actual = append(actual, cidr.String())
+		}
+		if strings.Join(expected, ",") != strings.Join(actual, ",") {
+			t.Errorf("cidrs %v expected: %v actual %v", input, expected, actual)
+		}
+	}
+
+	// a v4 IP in its 16-byte form, as net.ParseIP returns, is still v4.
+	actual := AggregateCIDRs([]*net.IPNet{IPToCIDR(net.ParseIP("192.0.2.1").To4()), &net.IPNet{IP: net.ParseIP("192.0.2.0"), Mask: net.CIDRMask(128, 128)}})
+	if len(actual) != 1 || actual[0].String() != "192.0.2.0/31" {
+		t.Errorf("cidrs 16-byte v4 expected: [192.0.2.0/31] actual %v", actual)
+	}
+}
+
 func TestFirstIP(t *testing.T) {
 	inputExpecteds := map[string]string{
 		"192.168.1.0/24":     "192.168.1.0",
@@ -0,0 +1,37 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS snapshot_history (
+    id bigserial NOT NULL,
+    cdn text NOT NULL,
+    crconfig json NOT NULL,
+    monitoring json NOT NULL,
+    tm_user text,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS snapshot_history_cdn_idx ON snapshot_history (cdn);
+
+INSERT INTO snapshot_history (cdn, crconfig, monitoring, tm_user, last_updated)
+SELECT cdn, crconfig, monitoring, crconfig->'stats'->>'tm_user', last_updated FROM snapshot;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS snapshot_history;
@@ -0,0 +1,56 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS acme_account (
+    email text NOT NULL,
+    directory_url text NOT NULL,
+    private_key text NOT NULL,
+    uri text NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (email, directory_url)
+);
+
+CREATE TABLE IF NOT EXISTS deliveryservice_acme (
+    deliveryservice bigint NOT NULL REFERENCES deliveryservice (id) ON DELETE CASCADE,
+    challenge_type text NOT NULL CHECK (challenge_type IN ('dns-01', 'http-01')),
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'issued', 'failed')),
+    tm_user bigint REFERENCES tm_user (id) ON DELETE SET NULL,
+    expires timestamp with time zone,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt timestamp with time zone DEFAULT now() NOT NULL,
+    last_error text,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (deliveryservice)
+);
+
+CREATE TABLE IF NOT EXISTS acme_challenge (
+    token text NOT NULL,
+    key_authorization text NOT NULL,
+    domain text NOT NULL,
+    expires timestamp with time zone NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (token)
+);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS acme_challenge;
+DROP TABLE IF EXISTS deliveryservice_acme;
+DROP TABLE IF EXISTS acme_account;
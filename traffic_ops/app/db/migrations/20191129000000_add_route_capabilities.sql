@@ -0,0 +1,76 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+INSERT INTO capability (name, description) VALUES
+    ('delivery-service-acme-read', 'Ability to view delivery service ACME certificates'),
+    ('delivery-service-acme-write', 'Ability to request and remove delivery service ACME certificates'),
+    ('delivery-service-security-keys-generate', 'Ability to generate and delete delivery service SSL and URL signing keys'),
+    ('delivery-service-url-keys-read', 'Ability to view delivery service URL signing keys'),
+    ('dnssec-keys-refresh', 'Ability to refresh cdn DNSSEC keys'),
+    ('federation-mappings-read', 'Ability to view the current user''s federation mappings'),
+    ('federation-mappings-write', 'Ability to edit the current user''s federation mappings'),
+    ('federation-resolvers-read', 'Ability to view federation resolvers'),
+    ('federation-resolvers-write', 'Ability to edit federation resolvers'),
+    ('federations-read-all', 'Ability to view the federation mappings of all users'),
+    ('key-store-ping', 'Ability to check the key store connection'),
+    ('queue-updates', 'Ability to queue server updates'),
+    ('ssl-key-inventory-read', 'Ability to view the cdn certificate inventory'),
+    ('webhooks-read', 'Ability to view change log webhooks'),
+    ('webhooks-write', 'Ability to edit change log webhooks')
+ON CONFLICT (name) DO NOTHING;
+
+-- Roles without capabilities are authorized by privilege level, and granting one a capability would limit it to that capability, so only roles which already have capabilities are granted the new ones.
+-- Later migrations adding capabilities follow the same rule. Existing grants are left alone.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name
+FROM role AS r
+JOIN (VALUES
+    ('delivery-service-acme-read', 10),
+    ('delivery-service-acme-write', 20),
+    ('delivery-service-security-keys-generate', 20),
+    ('delivery-service-url-keys-read', 10),
+    ('dnssec-keys-refresh', 20),
+    ('federation-mappings-read', 15),
+    ('federation-mappings-write', 15),
+    ('federation-resolvers-read', 10),
+    ('federation-resolvers-write', 30),
+    ('federations-read-all', 30),
+    ('key-store-ping', 10),
+    ('queue-updates', 20),
+    ('ssl-key-inventory-read', 10),
+    ('webhooks-read', 20),
+    ('webhooks-write', 20)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+WHERE EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- The default operations role was never granted these, but they're required by operations routes.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name
+FROM role AS r
+JOIN (VALUES ('origins-write'), ('server-capabilities-write')) AS c (name) ON true
+WHERE r.name = 'operations'
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability
+WHERE cap_name IN ('origins-write', 'server-capabilities-write')
+AND role_id IN (SELECT id FROM role WHERE name = 'operations');
+DELETE FROM role_capability WHERE cap_name IN ('delivery-service-acme-read', 'delivery-service-acme-write', 'delivery-service-security-keys-generate', 'delivery-service-url-keys-read', 'dnssec-keys-refresh', 'federation-mappings-read', 'federation-mappings-write', 'federation-resolvers-read', 'federation-resolvers-write', 'federations-read-all', 'key-store-ping', 'queue-updates', 'ssl-key-inventory-read', 'webhooks-read', 'webhooks-write');
+DELETE FROM capability WHERE name IN ('delivery-service-acme-read', 'delivery-service-acme-write', 'delivery-service-security-keys-generate', 'delivery-service-url-keys-read', 'dnssec-keys-refresh', 'federation-mappings-read', 'federation-mappings-write', 'federation-resolvers-read', 'federation-resolvers-write', 'federations-read-all', 'key-store-ping', 'queue-updates', 'ssl-key-inventory-read', 'webhooks-read', 'webhooks-write');
@@ -0,0 +1,55 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS api_token (
+    id bigserial NOT NULL,
+    tm_user bigint NOT NULL REFERENCES tm_user (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    token_hash text NOT NULL UNIQUE,
+    capabilities text[],
+    routes text[],
+    cdn bigint REFERENCES cdn (id) ON DELETE CASCADE,
+    expires timestamp with time zone NOT NULL,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (tm_user, name)
+);
+
+-- The name of the API token a change was made with, if any. It isn't a foreign key, so the change log outlives revoked tokens.
+ALTER TABLE log ADD COLUMN IF NOT EXISTS api_token text;
+
+INSERT INTO capability (name, description) VALUES
+    ('api-tokens-read', 'Ability to view the current user''s API tokens'),
+    ('api-tokens-write', 'Ability to create and revoke the current user''s API tokens')
+ON CONFLICT (name) DO NOTHING;
+
+-- Every user may manage their own API tokens, so every role with capabilities is granted both.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name
+FROM role AS r
+JOIN capability AS c ON c.name IN ('api-tokens-read', 'api-tokens-write')
+WHERE r.priv_level >= 10
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('api-tokens-read', 'api-tokens-write');
+DELETE FROM capability WHERE name IN ('api-tokens-read', 'api-tokens-write');
+ALTER TABLE log DROP COLUMN IF EXISTS api_token;
+DROP TABLE IF EXISTS api_token;
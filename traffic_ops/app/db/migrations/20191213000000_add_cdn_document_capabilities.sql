@@ -0,0 +1,35 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+INSERT INTO capability (name, description) VALUES
+    ('cdn-documents-read', 'Ability to export cdn documents and plan applying them'),
+    ('cdn-documents-apply', 'Ability to apply cdn documents')
+ON CONFLICT (name) DO NOTHING;
+
+-- Exporting, planning and applying CDN documents are operations tasks.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name
+FROM role AS r
+JOIN capability AS c ON c.name IN ('cdn-documents-read', 'cdn-documents-apply')
+WHERE r.priv_level >= 20
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('cdn-documents-read', 'cdn-documents-apply');
+DELETE FROM capability WHERE name IN ('cdn-documents-read', 'cdn-documents-apply');
@@ -0,0 +1,76 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS topology (
+    name text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (name)
+);
+
+CREATE TABLE IF NOT EXISTS topology_cachegroup (
+    id bigserial NOT NULL,
+    topology text NOT NULL REFERENCES topology (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    cachegroup text NOT NULL REFERENCES cachegroup (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE (topology, cachegroup)
+);
+
+CREATE TABLE IF NOT EXISTS topology_cachegroup_parents (
+    child bigint NOT NULL REFERENCES topology_cachegroup (id) ON DELETE CASCADE,
+    parent bigint NOT NULL REFERENCES topology_cachegroup (id) ON DELETE CASCADE,
+    rank integer NOT NULL CHECK (rank IN (1, 2)),
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (child, parent),
+    UNIQUE (child, rank)
+);
+
+ALTER TABLE deliveryservice ADD COLUMN IF NOT EXISTS topology text REFERENCES topology (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+INSERT INTO capability (name, description) VALUES
+    ('topologies-read', 'Ability to view topologies'),
+    ('topologies-write', 'Ability to edit topologies')
+ON CONFLICT (name) DO NOTHING;
+
+-- Like cache groups, topologies are viewed by read-only roles and edited by operations roles.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'topologies-read'
+FROM role AS r
+WHERE r.priv_level >= 10
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'topologies-write'
+FROM role AS r
+WHERE r.priv_level >= 20
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('topologies-read', 'topologies-write');
+DELETE FROM capability WHERE name IN ('topologies-read', 'topologies-write');
+
+ALTER TABLE deliveryservice DROP COLUMN IF EXISTS topology;
+DROP TABLE IF EXISTS topology_cachegroup_parents;
+DROP TABLE IF EXISTS topology_cachegroup;
+DROP TABLE IF EXISTS topology;
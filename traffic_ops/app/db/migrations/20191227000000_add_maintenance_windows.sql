@@ -0,0 +1,79 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS maintenance_window (
+    id bigserial NOT NULL,
+    name text NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    start_time timestamp with time zone NOT NULL,
+    end_time timestamp with time zone NOT NULL,
+    cachegroup text REFERENCES cachegroup (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    status bigint NOT NULL REFERENCES status (id) ON DELETE RESTRICT,
+    offline_reason text NOT NULL,
+    queue_updates boolean NOT NULL DEFAULT TRUE,
+    snapshot boolean NOT NULL DEFAULT FALSE,
+    state text NOT NULL DEFAULT 'scheduled' CHECK (state IN ('scheduled', 'active', 'completed', 'missed')),
+    tm_user bigint NOT NULL REFERENCES tm_user (id),
+    last_error text,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (id),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS maintenance_window_state_idx ON maintenance_window (state);
+
+-- previous_status and previous_offline_reason are the server's status and offline reason before the window started, which are restored when it ends.
+CREATE TABLE IF NOT EXISTS maintenance_window_server (
+    maintenance_window bigint NOT NULL REFERENCES maintenance_window (id) ON DELETE CASCADE,
+    server bigint NOT NULL REFERENCES server (id) ON DELETE CASCADE,
+    explicit boolean NOT NULL DEFAULT TRUE,
+    previous_status bigint REFERENCES status (id) ON DELETE SET NULL,
+    previous_offline_reason text,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (maintenance_window, server)
+);
+
+INSERT INTO capability (name, description) VALUES
+    ('maintenance-windows-read', 'Ability to view maintenance windows'),
+    ('maintenance-windows-write', 'Ability to schedule and delete maintenance windows')
+ON CONFLICT (name) DO NOTHING;
+
+-- Maintenance windows are viewed by read-only roles and scheduled by operations roles.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'maintenance-windows-read'
+FROM role AS r
+WHERE r.priv_level >= 10
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'maintenance-windows-write'
+FROM role AS r
+WHERE r.priv_level >= 20
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('maintenance-windows-read', 'maintenance-windows-write');
+DELETE FROM capability WHERE name IN ('maintenance-windows-read', 'maintenance-windows-write');
+
+DROP TABLE IF EXISTS maintenance_window_server;
+DROP TABLE IF EXISTS maintenance_window;
@@ -0,0 +1,78 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS deliveryservice_request_policy (
+    id bigserial NOT NULL,
+    name text NOT NULL UNIQUE,
+    tenant bigint REFERENCES tenant (id) ON DELETE CASCADE,
+    change_type change_types,
+    required_approvals integer NOT NULL DEFAULT 1 CHECK (required_approvals >= 1),
+    tenant_approvers boolean NOT NULL DEFAULT FALSE,
+    allow_author_approval boolean NOT NULL DEFAULT FALSE,
+    auto_apply boolean NOT NULL DEFAULT TRUE,
+    last_updated timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+-- There may be at most one policy per tenant and change type; COALESCE makes the NULL "all tenants" and "all change types" unique too.
+CREATE UNIQUE INDEX IF NOT EXISTS deliveryservice_request_policy_scope_idx ON deliveryservice_request_policy (COALESCE(tenant, 0), COALESCE(change_type::text, ''));
+
+CREATE TABLE IF NOT EXISTS deliveryservice_request_approval (
+    deliveryservice_request bigint NOT NULL REFERENCES deliveryservice_request (id) ON DELETE CASCADE,
+    tm_user bigint NOT NULL REFERENCES tm_user (id) ON DELETE CASCADE,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (deliveryservice_request, tm_user)
+);
+
+ALTER TABLE deliveryservice_request ADD COLUMN IF NOT EXISTS applied_at timestamp with time zone;
+ALTER TABLE deliveryservice_request ADD COLUMN IF NOT EXISTS applied_log bigint REFERENCES log (id) ON DELETE SET NULL;
+ALTER TABLE deliveryservice_request ADD COLUMN IF NOT EXISTS applied_diff jsonb;
+
+INSERT INTO capability (name, description) VALUES
+    ('delivery-service-requests-approve', 'Ability to approve delivery service requests'),
+    ('delivery-service-request-policies-write', 'Ability to create, edit, and delete delivery service request approval policies')
+ON CONFLICT (name) DO NOTHING;
+
+-- Approving delivery service requests and editing approval policies are operations tasks.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'delivery-service-requests-approve'
+FROM role AS r
+WHERE r.priv_level >= 20
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'delivery-service-request-policies-write'
+FROM role AS r
+WHERE r.priv_level >= 20
+AND EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('delivery-service-requests-approve', 'delivery-service-request-policies-write');
+DELETE FROM capability WHERE name IN ('delivery-service-requests-approve', 'delivery-service-request-policies-write');
+
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS applied_diff;
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS applied_log;
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS applied_at;
+
+DROP TABLE IF EXISTS deliveryservice_request_approval;
+DROP TABLE IF EXISTS deliveryservice_request_policy;
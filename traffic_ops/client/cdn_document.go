@@ -0,0 +1,54 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func cdnDocumentEp(cdnName string) string {
+	return apiBase + "/cdns/name/" + url.PathEscape(cdnName) + "/document"
+}
+
+// ExportCDNDocument returns the document describing the given CDN.
+func (to *Session) ExportCDNDocument(cdnName string) (tc.CDNDocument, ReqInf, error) {
+	doc := tc.CDNDocument{}
+	inf, err := get(to, cdnDocumentEp(cdnName), &doc)
+	return doc, inf, err
+}
+
+// PlanCDNDocument returns the changes applying the document to its CDN would make.
+func (to *Session) PlanCDNDocument(doc tc.CDNDocument) (tc.CDNPlanResponse, ReqInf, error) {
+	return to.postCDNDocument(doc, "/plan")
+}
+
+// ApplyCDNDocument applies the document to its CDN, returning the changes made.
+func (to *Session) ApplyCDNDocument(doc tc.CDNDocument) (tc.CDNPlanResponse, ReqInf, error) {
+	return to.postCDNDocument(doc, "/apply")
+}
+
+func (to *Session) postCDNDocument(doc tc.CDNDocument, action string) (tc.CDNPlanResponse, ReqInf, error) {
+	resp := tc.CDNPlanResponse{}
+	reqBody, err := json.Marshal(doc)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := post(to, cdnDocumentEp(doc.CDN.Name)+action, reqBody, &resp)
+	return resp, inf, err
+}
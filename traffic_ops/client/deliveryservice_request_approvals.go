@@ -0,0 +1,85 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_V14_DELIVERY_SERVICE_REQUESTS         = apiBase + "/deliveryservice_requests"
+	API_V14_DELIVERY_SERVICE_REQUEST_POLICIES = apiBase + "/deliveryservice_request_policies"
+)
+
+// GetDeliveryServiceRequestApprovals returns the approvals of the delivery service request with the given ID, and whether they satisfy its policy.
+func (to *Session) GetDeliveryServiceRequestApprovals(id int) (tc.DeliveryServiceRequestApprovals, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestApprovalsResponse{}
+	inf, err := get(to, API_V14_DELIVERY_SERVICE_REQUESTS+"/"+strconv.Itoa(id)+"/approvals", &resp)
+	return resp.Response, inf, err
+}
+
+// ApproveDeliveryServiceRequest approves the submitted delivery service request with the given ID. If the approval satisfies the request's policy, the request is applied, or becomes pending if the policy doesn't apply requests automatically.
+func (to *Session) ApproveDeliveryServiceRequest(id int) (tc.DeliveryServiceRequestApprovalsResponse, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestApprovalsResponse{}
+	inf, err := post(to, API_V14_DELIVERY_SERVICE_REQUESTS+"/"+strconv.Itoa(id)+"/approvals", nil, &resp)
+	return resp, inf, err
+}
+
+// ApplyDeliveryServiceRequest applies the pending, approved delivery service request with the given ID.
+func (to *Session) ApplyDeliveryServiceRequest(id int) (tc.DeliveryServiceRequestApplyResponse, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestApplyResponse{}
+	inf, err := post(to, API_V14_DELIVERY_SERVICE_REQUESTS+"/"+strconv.Itoa(id)+"/apply", nil, &resp)
+	return resp, inf, err
+}
+
+// GetDeliveryServiceRequestPolicies returns all delivery service request policies.
+func (to *Session) GetDeliveryServiceRequestPolicies() ([]tc.DeliveryServiceRequestPolicy, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestPoliciesResponse{}
+	inf, err := get(to, API_V14_DELIVERY_SERVICE_REQUEST_POLICIES, &resp)
+	return resp.Response, inf, err
+}
+
+// CreateDeliveryServiceRequestPolicy creates the given delivery service request policy.
+func (to *Session) CreateDeliveryServiceRequestPolicy(policy tc.DeliveryServiceRequestPolicy) (tc.DeliveryServiceRequestPolicyResponse, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestPolicyResponse{}
+	reqBody, err := json.Marshal(policy)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := post(to, API_V14_DELIVERY_SERVICE_REQUEST_POLICIES, reqBody, &resp)
+	return resp, inf, err
+}
+
+// UpdateDeliveryServiceRequestPolicy replaces the delivery service request policy with the given ID with the given policy.
+func (to *Session) UpdateDeliveryServiceRequestPolicy(id int, policy tc.DeliveryServiceRequestPolicy) (tc.DeliveryServiceRequestPolicyResponse, ReqInf, error) {
+	resp := tc.DeliveryServiceRequestPolicyResponse{}
+	reqBody, err := json.Marshal(policy)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := put(to, API_V14_DELIVERY_SERVICE_REQUEST_POLICIES+"?id="+strconv.Itoa(id), reqBody, &resp)
+	return resp, inf, err
+}
+
+// DeleteDeliveryServiceRequestPolicy deletes the delivery service request policy with the given ID.
+func (to *Session) DeleteDeliveryServiceRequestPolicy(id int) (tc.Alerts, ReqInf, error) {
+	resp := tc.Alerts{}
+	inf, err := del(to, API_V14_DELIVERY_SERVICE_REQUEST_POLICIES+"?id="+strconv.Itoa(id), &resp)
+	return resp, inf, err
+}
@@ -0,0 +1,76 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const API_V14_MAINTENANCE_WINDOWS = apiBase + "/maintenance_windows"
+
+// GetMaintenanceWindows returns all maintenance windows.
+func (to *Session) GetMaintenanceWindows() ([]tc.MaintenanceWindow, ReqInf, error) {
+	resp := tc.MaintenanceWindowsResponse{}
+	inf, err := get(to, API_V14_MAINTENANCE_WINDOWS, &resp)
+	return resp.Response, inf, err
+}
+
+// GetMaintenanceWindowsByState returns the maintenance windows in the given state.
+func (to *Session) GetMaintenanceWindowsByState(state tc.MaintenanceWindowState) ([]tc.MaintenanceWindow, ReqInf, error) {
+	resp := tc.MaintenanceWindowsResponse{}
+	inf, err := get(to, API_V14_MAINTENANCE_WINDOWS+"?state="+url.QueryEscape(string(state)), &resp)
+	return resp.Response, inf, err
+}
+
+// GetMaintenanceWindow returns the maintenance window with the given ID, which is empty if there is no such window.
+func (to *Session) GetMaintenanceWindow(id int) ([]tc.MaintenanceWindow, ReqInf, error) {
+	resp := tc.MaintenanceWindowsResponse{}
+	inf, err := get(to, API_V14_MAINTENANCE_WINDOWS+"?id="+strconv.Itoa(id), &resp)
+	return resp.Response, inf, err
+}
+
+// CreateMaintenanceWindow schedules the given maintenance window.
+func (to *Session) CreateMaintenanceWindow(window tc.MaintenanceWindow) (tc.MaintenanceWindowResponse, ReqInf, error) {
+	resp := tc.MaintenanceWindowResponse{}
+	reqBody, err := json.Marshal(window)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := post(to, API_V14_MAINTENANCE_WINDOWS, reqBody, &resp)
+	return resp, inf, err
+}
+
+// UpdateMaintenanceWindow replaces the maintenance window with the given ID with the given window.
+func (to *Session) UpdateMaintenanceWindow(id int, window tc.MaintenanceWindow) (tc.MaintenanceWindowResponse, ReqInf, error) {
+	resp := tc.MaintenanceWindowResponse{}
+	reqBody, err := json.Marshal(window)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := put(to, API_V14_MAINTENANCE_WINDOWS+"?id="+strconv.Itoa(id), reqBody, &resp)
+	return resp, inf, err
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the given ID.
+func (to *Session) DeleteMaintenanceWindow(id int) (tc.Alerts, ReqInf, error) {
+	resp := tc.Alerts{}
+	inf, err := del(to, API_V14_MAINTENANCE_WINDOWS+"?id="+strconv.Itoa(id), &resp)
+	return resp, inf, err
+}
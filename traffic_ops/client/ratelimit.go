@@ -0,0 +1,29 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const API_V14_RATE_LIMIT_STATS = apiBase + "/rate_limits/stats"
+
+// GetRateLimitStats returns the state of the API rate limits of the Traffic Ops instance which serves the request. It's empty if rate limiting is disabled.
+func (to *Session) GetRateLimitStats() ([]tc.RateLimitStats, ReqInf, error) {
+	resp := tc.RateLimitStatsResponse{}
+	inf, err := get(to, API_V14_RATE_LIMIT_STATS, &resp)
+	return resp.Response, inf, err
+}
@@ -0,0 +1,68 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+const API_V14_TOPOLOGIES = apiBase + "/topologies"
+
+// GetTopologies returns all topologies.
+func (to *Session) GetTopologies() ([]tc.Topology, ReqInf, error) {
+	resp := tc.TopologiesResponse{}
+	inf, err := get(to, API_V14_TOPOLOGIES, &resp)
+	return resp.Response, inf, err
+}
+
+// GetTopology returns the topology with the given name, which is empty if there is no such topology.
+func (to *Session) GetTopology(name string) ([]tc.Topology, ReqInf, error) {
+	resp := tc.TopologiesResponse{}
+	inf, err := get(to, API_V14_TOPOLOGIES+"?name="+url.QueryEscape(name), &resp)
+	return resp.Response, inf, err
+}
+
+// CreateTopology creates the given topology.
+func (to *Session) CreateTopology(topology tc.Topology) (tc.TopologyResponse, ReqInf, error) {
+	resp := tc.TopologyResponse{}
+	reqBody, err := json.Marshal(topology)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := post(to, API_V14_TOPOLOGIES, reqBody, &resp)
+	return resp, inf, err
+}
+
+// UpdateTopology replaces the topology with the given name with the given topology, which may have a different name.
+func (to *Session) UpdateTopology(name string, topology tc.Topology) (tc.TopologyResponse, ReqInf, error) {
+	resp := tc.TopologyResponse{}
+	reqBody, err := json.Marshal(topology)
+	if err != nil {
+		return resp, ReqInf{CacheHitStatus: CacheHitStatusMiss}, err
+	}
+	inf, err := put(to, API_V14_TOPOLOGIES+"?name="+url.QueryEscape(name), reqBody, &resp)
+	return resp, inf, err
+}
+
+// DeleteTopology deletes the topology with the given name.
+func (to *Session) DeleteTopology(name string) (tc.Alerts, ReqInf, error) {
+	resp := tc.Alerts{}
+	inf, err := del(to, API_V14_TOPOLOGIES+"?name="+url.QueryEscape(name), &resp)
+	return resp, inf, err
+}
@@ -0,0 +1,325 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// ReportFileName is the name of the report written to the output directory of --server-files. It's read on the next run, to find files which are no longer generated.
+const ReportFileName = AppName + "-report.json"
+
+const FileStatusUnchanged = "unchanged"
+const FileStatusChanged = "changed"
+const FileStatusNew = "new"
+const FileStatusRemoved = "removed"
+const FileStatusExternal = "external"
+
+const FileActionNone = "none"
+const FileActionReload = "reload"
+const FileActionRestart = "restart"
+
+// ServerFilesReport is the report of generating all of a server's config files, comparing each to the file on disk.
+type ServerFilesReport struct {
+	Server        string             `json:"server"`
+	Files         []ServerFileReport `json:"files"`
+	ReloadNeeded  bool               `json:"reloadNeeded"`
+	RestartNeeded bool               `json:"restartNeeded"`
+	// ValidationErrors are the problems found in the generated files, if they were validated.
+	ValidationErrors []atscfg.ConfigFileError `json:"validationErrors,omitempty"`
+}
+
+type ServerFileReport struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Status   string `json:"status"`
+	Action   string `json:"action"`
+	// Diff is the unified diff from the file on disk to the generated file, if the Status is FileStatusChanged.
+	Diff string `json:"diff,omitempty"`
+	// URL is the external URL ORT gets the file from, if the Status is FileStatusExternal.
+	URL string `json:"url,omitempty"`
+}
+
+// GenerateServerFiles generates every config file of the server cfg.ServerFiles, writes them to cfg.OutputDir, and returns the report of how they differ from the files on disk.
+// The report is also written to cfg.OutputDir/ReportFileName.
+// If cfg.Validate is set and the generated files have problems, nothing is written to or removed from cfg.OutputDir, and the returned report only has the validation errors.
+func GenerateServerFiles(cfg TCCfg) (ServerFilesReport, int, error) {
+	metaTxt, err := GetConfigFileMeta(cfg, cfg.ServerFiles)
+	if err != nil {
+		code := ExitCodeErrGeneric
+		if err == ErrNotFound {
+			code = ExitCodeNotFound
+		} else if err == ErrBadRequest {
+			code = ExitCodeBadRequest
+		}
+		return ServerFilesReport{}, code, errors.New("getting meta config: " + err.Error())
+	}
+
+	meta := tc.ATSConfigMetaData{}
+	if err := json.Unmarshal([]byte(metaTxt), &meta); err != nil {
+		return ServerFilesReport{}, ExitCodeErrGeneric, errors.New("decoding meta config: " + err.Error())
+	}
+
+	report := ServerFilesReport{Server: cfg.ServerFiles, Files: []ServerFileReport{}}
+	files := []generatedServerFile{}
+	for _, file := range meta.ConfigFiles {
+		if file.APIURI == "" {
+			report.Files = append(report.Files, ServerFileReport{
+				Name:     file.FileNameOnDisk,
+				Location: file.Location,
+				Status:   FileStatusExternal,
+				Action:   FileActionNone,
+				URL:      file.URL,
+			})
+			continue
+		}
+
+		txt, code, err := getServerFile(cfg, file.APIURI)
+		if err != nil {
+			return ServerFilesReport{}, code, errors.New("generating '" + file.FileNameOnDisk + "': " + err.Error())
+		}
+		files = append(files, generatedServerFile{Name: file.FileNameOnDisk, Location: file.Location, Txt: txt})
+	}
+
+	return validateAndWriteServerFiles(cfg, metaTxt, report, files)
+}
+
+// validateAndWriteServerFiles validates the generated files if cfg.Validate is set, and writes them with writeServerFiles if they're valid.
+// If they aren't, nothing is written or removed, so the output dir keeps the last generated files, and a caller installing from it never gets an invalid set.
+func validateAndWriteServerFiles(cfg TCCfg, metaTxt string, report ServerFilesReport, files []generatedServerFile) (ServerFilesReport, int, error) {
+	if cfg.Validate {
+		if validationErrs := validateServerFiles(metaTxt, files); len(validationErrs) > 0 {
+			return ServerFilesReport{Server: report.Server, Files: []ServerFileReport{}, ValidationErrors: validationErrs}, ExitCodeInvalidConfig, nil
+		}
+	}
+
+	report, err := writeServerFiles(cfg.OutputDir, report, files)
+	if err != nil {
+		return ServerFilesReport{}, ExitCodeErrGeneric, err
+	}
+	return report, ServerFilesReportExitCode(report), nil
+}
+
+// generatedServerFile is a generated config file, which hasn't been written yet.
+type generatedServerFile struct {
+	Name     string
+	Location string
+	Txt      string
+}
+
+// validateServerFiles returns the problems in the generated files, after logging them.
+func validateServerFiles(metaTxt string, files []generatedServerFile) []atscfg.ConfigFileError {
+	txts := map[string]string{}
+	for _, file := range files {
+		txts[file.Name] = file.Txt
+	}
+	validationErrs := atscfg.ValidateConfigFiles(metaTxt, txts)
+	for _, validationErr := range validationErrs {
+		log.Errorln("validating generated files: " + validationErr.Error())
+	}
+	return validationErrs
+}
+
+// writeServerFiles writes the generated files to outputDir, removes the files of the previous report which are no longer generated, and writes the report of the generated files added to the given report.
+func writeServerFiles(outputDir string, report ServerFilesReport, files []generatedServerFile) (ServerFilesReport, error) {
+	generated := map[string]struct{}{}
+	for _, file := range files {
+		fileReport, err := writeServerFile(outputDir, file.Name, file.Location, file.Txt)
+		if err != nil {
+			return ServerFilesReport{}, errors.New("writing '" + file.Name + "': " + err.Error())
+		}
+		report.Files = append(report.Files, fileReport)
+		generated[file.Name] = struct{}{}
+	}
+
+	removed, err := removeStaleServerFiles(outputDir, generated)
+	if err != nil {
+		return ServerFilesReport{}, errors.New("removing files no longer generated: " + err.Error())
+	}
+	report.Files = append(report.Files, removed...)
+
+	finishServerFilesReport(&report)
+
+	if err := writeServerFilesReport(outputDir, report); err != nil {
+		return ServerFilesReport{}, errors.New("writing report: " + err.Error())
+	}
+	return report, nil
+}
+
+// getServerFile generates the file of the given meta config API URI, in the same way as a single file request for that path.
+func getServerFile(cfg TCCfg, apiURI string) (string, int, error) {
+	fileURL := *cfg.TOURL
+	uri, err := url.Parse(apiURI)
+	if err != nil {
+		return "", ExitCodeErrGeneric, errors.New("parsing API URI '" + apiURI + "': " + err.Error())
+	}
+	fileURL.Path = uri.Path
+	fileURL.RawQuery = uri.RawQuery
+	cfg.TOURL = &fileURL
+
+	txt, code, err := GetConfigFile(cfg)
+	if err == nil && code != ExitCodeSuccess {
+		err = errors.New("request returned exit code " + strconv.Itoa(code))
+	}
+	if err != nil {
+		if code == ExitCodeSuccess {
+			code = ExitCodeErrGeneric
+		}
+		return "", code, err
+	}
+	return txt, ExitCodeSuccess, nil
+}
+
+// writeServerFile writes txt to outputDir/name, and returns the report comparing it to the file at location/name.
+func writeServerFile(outputDir string, name string, location string, txt string) (ServerFileReport, error) {
+	outPath := filepath.Join(outputDir, name)
+	if err := ioutil.WriteFile(outPath, []byte(txt), 0644); err != nil {
+		return ServerFileReport{}, err
+	}
+
+	diskPath := filepath.Join(location, name)
+	report := ServerFileReport{Name: name, Location: location, Action: FileActionNone}
+
+	diskTxt, err := ioutil.ReadFile(diskPath)
+	if os.IsNotExist(err) {
+		report.Status = FileStatusNew
+		report.Action = FileAction(name, location)
+		return report, nil
+	} else if err != nil {
+		return ServerFileReport{}, errors.New("reading file on disk '" + diskPath + "': " + err.Error())
+	}
+
+	if string(diskTxt) == txt {
+		report.Status = FileStatusUnchanged
+		return report, nil
+	}
+	report.Status = FileStatusChanged
+	report.Action = FileAction(name, location)
+	report.Diff = UnifiedDiff(diskPath, outPath, string(diskTxt), txt)
+	return report, nil
+}
+
+// removeStaleServerFiles removes the files of the previous report in outputDir which aren't in generated, and returns their reports.
+// The files on disk aren't removed; the report tells the caller to remove them.
+func removeStaleServerFiles(outputDir string, generated map[string]struct{}) ([]ServerFileReport, error) {
+	prevReport, err := readServerFilesReport(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []ServerFileReport{}
+	for _, file := range prevReport.Files {
+		if file.Status == FileStatusRemoved || file.Status == FileStatusExternal {
+			continue
+		}
+		if _, ok := generated[file.Name]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(outputDir, file.Name)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		removed = append(removed, ServerFileReport{
+			Name:     file.Name,
+			Location: file.Location,
+			Status:   FileStatusRemoved,
+			Action:   FileAction(file.Name, file.Location),
+		})
+	}
+	return removed, nil
+}
+
+// readServerFilesReport reads the previous report in outputDir. If there is none, an empty report is returned.
+func readServerFilesReport(outputDir string) (ServerFilesReport, error) {
+	bts, err := ioutil.ReadFile(filepath.Join(outputDir, ReportFileName))
+	if os.IsNotExist(err) {
+		return ServerFilesReport{}, nil
+	} else if err != nil {
+		return ServerFilesReport{}, errors.New("reading previous report: " + err.Error())
+	}
+	report := ServerFilesReport{}
+	if err := json.Unmarshal(bts, &report); err != nil {
+		log.Warnln("previous report '" + filepath.Join(outputDir, ReportFileName) + "' is malformed, ignoring: " + err.Error())
+		return ServerFilesReport{}, nil
+	}
+	return report, nil
+}
+
+func writeServerFilesReport(outputDir string, report ServerFilesReport) error {
+	bts, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(outputDir, ReportFileName), bts, 0644)
+}
+
+// finishServerFilesReport sorts the report's files by name, and sets whether a reload or restart is needed from the files' actions.
+func finishServerFilesReport(report *ServerFilesReport) {
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Name < report.Files[j].Name })
+	for _, file := range report.Files {
+		switch file.Action {
+		case FileActionRestart:
+			report.RestartNeeded = true
+		case FileActionReload:
+			report.ReloadNeeded = true
+		}
+	}
+}
+
+// ServerFilesReportExitCode returns the exit code for the report: ExitCodeInvalidConfig if validation found problems, else ExitCodeRestartNeeded if any file needs a restart, else ExitCodeReloadNeeded if any needs a reload, else ExitCodeSuccess.
+func ServerFilesReportExitCode(report ServerFilesReport) int {
+	if len(report.ValidationErrors) > 0 {
+		return ExitCodeInvalidConfig
+	}
+	if report.RestartNeeded {
+		return ExitCodeRestartNeeded
+	}
+	if report.ReloadNeeded {
+		return ExitCodeReloadNeeded
+	}
+	return ExitCodeSuccess
+}
+
+// FileAction returns the action Traffic Server needs when the given file changes, using the same rules, in the same order, as ORT.
+func FileAction(name string, location string) string {
+	switch {
+	case strings.HasPrefix(name, "url_sig_") || strings.HasPrefix(name, "uri_signing_") || strings.HasPrefix(name, "hdr_rw_"):
+		return FileActionReload
+	case name == "plugin.config" || name == "50-ats.rules":
+		return FileActionRestart
+	case strings.Contains(location, "ssl") && (strings.HasSuffix(name, ".cer") || strings.HasSuffix(name, ".key")):
+		return FileActionReload
+	case strings.Contains(location, "trafficserver"):
+		return FileActionReload
+	}
+	// sysctl.conf, ntpd.conf, facts, and cron files are applied by ORT without Traffic Server.
+	return FileActionNone
+}
@@ -0,0 +1,242 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+)
+
+func TestWriteServerFile(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "atstccfg-out")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(outDir)
+	diskDir, err := ioutil.TempDir("", "atstccfg-trafficserver")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(diskDir)
+
+	if err := ioutil.WriteFile(filepath.Join(diskDir, "same.config"), []byte("foo\n"), 0644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(diskDir, "plugin.config"), []byte("foo\n"), 0644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	report, err := writeServerFile(outDir, "same.config", diskDir, "foo\n")
+	if err != nil {
+		t.Fatalf("writeServerFile expected nil error, actual: %v", err)
+	}
+	if report.Status != FileStatusUnchanged || report.Action != FileActionNone || report.Diff != "" {
+		t.Errorf("writeServerFile unchanged file expected status %v action %v no diff, actual %+v", FileStatusUnchanged, FileActionNone, report)
+	}
+
+	report, err = writeServerFile(outDir, "plugin.config", diskDir, "bar\n")
+	if err != nil {
+		t.Fatalf("writeServerFile expected nil error, actual: %v", err)
+	}
+	if report.Status != FileStatusChanged || report.Action != FileActionRestart {
+		t.Errorf("writeServerFile changed file expected status %v action %v, actual %+v", FileStatusChanged, FileActionRestart, report)
+	}
+	if !strings.Contains(report.Diff, "-foo\n+bar\n") {
+		t.Errorf("writeServerFile changed file expected diff of foo to bar, actual '%v'", report.Diff)
+	}
+
+	report, err = writeServerFile(outDir, "remap.config", diskDir, "baz\n")
+	if err != nil {
+		t.Fatalf("writeServerFile expected nil error, actual: %v", err)
+	}
+	if report.Status != FileStatusNew || report.Action != FileActionReload {
+		t.Errorf("writeServerFile new file expected status %v action %v, actual %+v", FileStatusNew, FileActionReload, report)
+	}
+
+	if bts, err := ioutil.ReadFile(filepath.Join(outDir, "remap.config")); err != nil {
+		t.Errorf("reading generated file: %v", err)
+	} else if string(bts) != "baz\n" {
+		t.Errorf("generated file expected 'baz\\n', actual '%v'", string(bts))
+	}
+}
+
+func TestRemoveStaleServerFiles(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "atstccfg-out")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	prev := ServerFilesReport{Server: "myserver", Files: []ServerFileReport{
+		{Name: "remap.config", Location: "/opt/trafficserver/etc/trafficserver", Status: FileStatusUnchanged},
+		{Name: "hdr_rw_old.config", Location: "/opt/trafficserver/etc/trafficserver", Status: FileStatusChanged},
+		{Name: "gone.config", Location: "/opt/trafficserver/etc/trafficserver", Status: FileStatusRemoved},
+	}}
+	if err := writeServerFilesReport(outDir, prev); err != nil {
+		t.Fatalf("writing report: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(outDir, "hdr_rw_old.config"), []byte("foo\n"), 0644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+
+	removed, err := removeStaleServerFiles(outDir, map[string]struct{}{"remap.config": {}})
+	if err != nil {
+		t.Fatalf("removeStaleServerFiles expected nil error, actual: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Fatalf("removeStaleServerFiles expected 1 removed file, actual %+v", removed)
+	}
+	if removed[0].Name != "hdr_rw_old.config" || removed[0].Status != FileStatusRemoved || removed[0].Action != FileActionReload {
+		t.Errorf("removeStaleServerFiles expected hdr_rw_old.config removed with reload, actual %+v", removed[0])
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "hdr_rw_old.config")); !os.IsNotExist(err) {
+		t.Errorf("removeStaleServerFiles expected stale file to be deleted from the output dir, actual stat error %v", err)
+	}
+}
+
+func TestValidateServerFilesBeforeWrite(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "atstccfg-out")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	metaTxt := `{"configFiles":[{"fnameOnDisk":"remap.config","location":"/opt/trafficserver/etc/trafficserver","apiUri":"/api/1.4/servers/myserver/configfiles/ats/remap.config"}]}`
+	valid := []generatedServerFile{{Name: "remap.config", Location: "/opt/trafficserver/etc/trafficserver", Txt: "map http://ds0.example.net/ http://origin0.example.net/\n"}}
+	invalid := []generatedServerFile{{Name: "remap.config", Location: "/opt/trafficserver/etc/trafficserver", Txt: valid[0].Txt + valid[0].Txt}}
+
+	cfg := TCCfg{Cfg: Cfg{OutputDir: outDir, Validate: true}}
+	report, code, err := validateAndWriteServerFiles(cfg, metaTxt, ServerFilesReport{Server: "myserver"}, valid)
+	if err != nil || code != ExitCodeReloadNeeded || len(report.ValidationErrors) != 0 {
+		t.Fatalf("validateAndWriteServerFiles valid files expected: reload code, nil error, no validation errors, actual: code %v error %v validation errors %v", code, err, report.ValidationErrors)
+	}
+
+	report, code, err = validateAndWriteServerFiles(cfg, metaTxt, ServerFilesReport{Server: "myserver"}, invalid)
+	if err != nil || code != ExitCodeInvalidConfig || len(report.ValidationErrors) == 0 {
+		t.Fatalf("validateAndWriteServerFiles duplicate remap rule expected: invalid config code, nil error, validation errors, actual: code %v error %v validation errors %v", code, err, report.ValidationErrors)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(outDir, "remap.config"))
+	if err != nil {
+		t.Fatalf("reading written file: %v", err)
+	}
+	if string(b) != valid[0].Txt {
+		t.Errorf("expected: invalid files not written, and the output dir to keep the last valid remap.config, actual: '%v'", string(b))
+	}
+	prev, err := readServerFilesReport(outDir)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	if len(prev.ValidationErrors) != 0 || len(prev.Files) != 1 {
+		t.Errorf("expected: report of the last valid files kept in the output dir, actual: %+v", prev)
+	}
+}
+
+func TestServerFilesReportExitCode(t *testing.T) {
+	report := ServerFilesReport{Files: []ServerFileReport{
+		{Name: "sysctl.conf", Action: FileActionNone},
+		{Name: "remap.config", Action: FileActionReload},
+	}}
+	finishServerFilesReport(&report)
+	if code := ServerFilesReportExitCode(report); code != ExitCodeReloadNeeded {
+		t.Errorf("expected exit code %v, actual %v", ExitCodeReloadNeeded, code)
+	}
+	if report.Files[0].Name != "remap.config" {
+		t.Errorf("expected files sorted by name, actual %+v", report.Files)
+	}
+
+	report.Files = append(report.Files, ServerFileReport{Name: "plugin.config", Action: FileActionRestart})
+	finishServerFilesReport(&report)
+	if code := ServerFilesReportExitCode(report); code != ExitCodeRestartNeeded {
+		t.Errorf("expected exit code %v, actual %v", ExitCodeRestartNeeded, code)
+	}
+
+	report.ValidationErrors = []atscfg.ConfigFileError{{File: "remap.config", Line: 2, Err: "duplicate map"}}
+	if code := ServerFilesReportExitCode(report); code != ExitCodeInvalidConfig {
+		t.Errorf("expected exit code %v, actual %v", ExitCodeInvalidConfig, code)
+	}
+
+	if code := ServerFilesReportExitCode(ServerFilesReport{}); code != ExitCodeSuccess {
+		t.Errorf("expected exit code %v, actual %v", ExitCodeSuccess, code)
+	}
+}
+
+func TestFileAction(t *testing.T) {
+	tsDir := "/opt/trafficserver/etc/trafficserver"
+	expecteds := []struct {
+		name     string
+		location string
+		action   string
+	}{
+		{"plugin.config", tsDir, FileActionRestart},
+		{"50-ats.rules", "/etc/udev/rules.d", FileActionRestart},
+		{"remap.config", tsDir, FileActionReload},
+		{"url_sig_foo.config", tsDir, FileActionReload},
+		{"hdr_rw_foo.config", "/somewhere", FileActionReload},
+		{"foo.cer", tsDir + "/ssl", FileActionReload},
+		{"sysctl.conf", "/etc", FileActionNone},
+		{"ntpd.conf", "/etc", FileActionNone},
+		{"12M_facts", "/opt/ort", FileActionNone},
+	}
+	for _, expected := range expecteds {
+		if actual := FileAction(expected.name, expected.location); actual != expected.action {
+			t.Errorf("FileAction('%v', '%v') expected %v, actual %v", expected.name, expected.location, expected.action, actual)
+		}
+	}
+}
+
+func TestGetCachedJSONObjCache(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "atstccfg-cache")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cfg := TCCfg{Cfg: Cfg{TempDir: tempDir, CacheFileMaxAge: time.Hour}, ObjCache: map[string][]byte{}}
+
+	calls := 0
+	getter := func(obj interface{}) error {
+		calls++
+		*obj.(*[]string) = []string{"foo", "bar"}
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		obj := []string{}
+		if err := GetCachedJSON(cfg, "objs.json", &obj, getter); err != nil {
+			t.Fatalf("GetCachedJSON expected nil error, actual: %v", err)
+		}
+		if len(obj) != 2 || obj[0] != "foo" || obj[1] != "bar" {
+			t.Errorf("GetCachedJSON expected [foo bar], actual %v", obj)
+		}
+		if i == 0 {
+			// remove the file cache, so later calls can only be served from memory.
+			os.RemoveAll(filepath.Join(tempDir, "objs.json"))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("GetCachedJSON expected getter to be called once, actual %v", calls)
+	}
+}
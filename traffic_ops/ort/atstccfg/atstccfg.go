@@ -20,12 +20,16 @@ package main
  */
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
 	"github.com/apache/trafficcontrol/lib/go-log"
 	toclient "github.com/apache/trafficcontrol/traffic_ops/client"
 )
@@ -44,9 +48,18 @@ const ExitCodeErrGeneric = 1
 const ExitCodeNotFound = 104
 const ExitCodeBadRequest = 100
 
+// ExitCodeReloadNeeded and ExitCodeRestartNeeded are returned by --server-files when a generated file differs from the file on disk in a way that needs Traffic Server to be reloaded or restarted.
+const ExitCodeReloadNeeded = 2
+const ExitCodeRestartNeeded = 3
+
+// ExitCodeInvalidConfig is returned by --server-files with --validate when a generated file has problems that would break Traffic Server.
+const ExitCodeInvalidConfig = 4
+
 type TCCfg struct {
 	Cfg
 	TOClient **toclient.Session
+	// ObjCache is the JSON of the objects already gotten in this run, keyed by their cache file names, so objects used by many config files are only requested or read once. If it's nil, objects aren't cached in memory.
+	ObjCache map[string][]byte
 }
 
 func main() {
@@ -61,20 +74,70 @@ func main() {
 		os.Exit(ExitCodeSuccess)
 	}
 
-	log.Infoln("URL: '" + cfg.TOURL.String() + "' User: '" + cfg.TOUser + "' Pass len: '" + strconv.Itoa(len(cfg.TOPass)) + "'")
-	log.Infoln("TempDir: '" + cfg.TempDir + "'")
+	tccfg := TCCfg{Cfg: cfg}
 
-	toFQDN := cfg.TOURL.Scheme + "://" + cfg.TOURL.Host
-	log.Infoln("TO FQDN: '" + toFQDN + "'")
-	log.Infoln("TO URL: '" + cfg.TOURL.String() + "'")
-
-	toClient, err := GetClient(toFQDN, cfg.TOUser, cfg.TOPass, cfg.TempDir, cfg.CacheFileMaxAge, cfg.TOTimeout, cfg.TOInsecure)
-	if err != nil {
-		log.Errorln("Logging in to Traffic Ops: " + err.Error())
-		os.Exit(ExitCodeErrGeneric)
+	if cfg.InputBundle != "" {
+		bundle, err := ReadBundle(cfg.InputBundle)
+		if err != nil {
+			log.Errorln("Reading input bundle '" + cfg.InputBundle + "': " + err.Error())
+			os.Exit(ExitCodeErrGeneric)
+		}
+		log.Infoln("Input bundle '" + cfg.InputBundle + "' for server '" + bundle.Server + "' created " + bundle.Created.String() + " by " + bundle.Generator)
+		if cfg.ServerFiles != "" && cfg.ServerFiles != bundle.Server {
+			log.Warnln("Generating files for server '" + cfg.ServerFiles + "' from the input bundle of server '" + bundle.Server + "', objects may be missing")
+		}
+		if tccfg.TOURL == nil {
+			toURL, err := url.Parse(bundle.TrafficOpsURL)
+			if err != nil {
+				log.Errorln("Parsing input bundle Traffic Ops URL '" + bundle.TrafficOpsURL + "': " + err.Error())
+				os.Exit(ExitCodeErrGeneric)
+			}
+			tccfg.TOURL = toURL
+		}
+		tccfg.ObjCache = BundleObjCache(bundle)
+		atscfg.Now = func() time.Time { return bundle.Created }
+	} else {
+		log.Infoln("URL: '" + cfg.TOURL.String() + "' User: '" + cfg.TOUser + "' Pass len: '" + strconv.Itoa(len(cfg.TOPass)) + "'")
+		log.Infoln("TempDir: '" + cfg.TempDir + "'")
+
+		toFQDN := cfg.TOURL.Scheme + "://" + cfg.TOURL.Host
+		log.Infoln("TO FQDN: '" + toFQDN + "'")
+		log.Infoln("TO URL: '" + cfg.TOURL.String() + "'")
+
+		toClient, err := GetClient(toFQDN, cfg.TOUser, cfg.TOPass, cfg.TempDir, cfg.CacheFileMaxAge, cfg.TOTimeout, cfg.TOInsecure)
+		if err != nil {
+			log.Errorln("Logging in to Traffic Ops: " + err.Error())
+			os.Exit(ExitCodeErrGeneric)
+		}
+		tccfg.TOClient = &toClient
 	}
 
-	tccfg := TCCfg{Cfg: cfg, TOClient: &toClient}
+	if cfg.ServerFiles != "" {
+		if tccfg.ObjCache == nil {
+			tccfg.ObjCache = map[string][]byte{}
+		}
+		report, code, err := GenerateServerFiles(tccfg)
+		if err != nil {
+			log.Errorln("Generating files for server '" + cfg.ServerFiles + "': " + err.Error())
+			if code == ExitCodeSuccess {
+				code = ExitCodeErrGeneric
+			}
+			os.Exit(code)
+		}
+		if cfg.ExportBundle != "" {
+			if err := WriteBundle(cfg.ExportBundle, MakeBundle(tccfg)); err != nil {
+				log.Errorln("Exporting bundle '" + cfg.ExportBundle + "': " + err.Error())
+				os.Exit(ExitCodeErrGeneric)
+			}
+		}
+		bts, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Errorln("Encoding report: " + err.Error())
+			os.Exit(ExitCodeErrGeneric)
+		}
+		fmt.Println(string(bts))
+		os.Exit(code)
+	}
 
 	cfgFile, code, err := GetConfigFile(tccfg)
 	log.Infof("GetConfigFile returned %v %v\n", code, err)
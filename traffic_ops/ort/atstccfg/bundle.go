@@ -0,0 +1,107 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// BundleVersion is the version of the bundle format written by this app. Bundles of other versions can't be read.
+const BundleVersion = 1
+
+// Bundle is every Traffic Ops object needed to generate a server's config files, so they can be generated again with no access to Traffic Ops.
+type Bundle struct {
+	Version int `json:"version"`
+	// Created is the time the bundle was exported. Files generated from the bundle use it as the current time, so the same bundle always generates the same files.
+	Created       time.Time `json:"created"`
+	Generator     string    `json:"generator"`
+	TrafficOpsURL string    `json:"trafficOpsURL"`
+	Server        string    `json:"server"`
+	// Objects is the JSON of each Traffic Ops object, keyed by its cache file name.
+	Objects map[string]json.RawMessage `json:"objects"`
+}
+
+// MakeBundle makes a bundle of the objects in cfg.ObjCache, which must be every object gotten to generate the files of cfg.ServerFiles.
+func MakeBundle(cfg TCCfg) Bundle {
+	toURL := *cfg.TOURL
+	toURL.Path = ""
+	toURL.RawQuery = ""
+	bundle := Bundle{
+		Version:       BundleVersion,
+		Created:       time.Now(),
+		Generator:     UserAgent,
+		TrafficOpsURL: toURL.String(),
+		Server:        cfg.ServerFiles,
+		Objects:       map[string]json.RawMessage{},
+	}
+	for name, bts := range cfg.ObjCache {
+		bundle.Objects[name] = json.RawMessage(bts)
+	}
+	return bundle
+}
+
+// WriteBundle writes the bundle to the given path. The file is written to a temp file and renamed, so a partially written bundle is never left at path.
+func WriteBundle(path string, bundle Bundle) error {
+	bts, err := json.Marshal(bundle)
+	if err != nil {
+		return errors.New("encoding bundle: " + err.Error())
+	}
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, bts, 0600); err != nil {
+		return errors.New("writing bundle: " + err.Error())
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.New("renaming bundle: " + err.Error())
+	}
+	return nil
+}
+
+// ReadBundle reads the bundle at the given path, returning an error if it isn't a bundle of BundleVersion.
+func ReadBundle(path string) (Bundle, error) {
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Bundle{}, errors.New("reading bundle: " + err.Error())
+	}
+	bundle := Bundle{}
+	if err := json.Unmarshal(bts, &bundle); err != nil {
+		return Bundle{}, errors.New("decoding bundle: " + err.Error())
+	}
+	if bundle.Version != BundleVersion {
+		return Bundle{}, errors.New("bundle version " + strconv.Itoa(bundle.Version) + " is not supported, expected version " + strconv.Itoa(BundleVersion))
+	}
+	if bundle.Objects == nil {
+		return Bundle{}, errors.New("bundle has no objects")
+	}
+	return bundle, nil
+}
+
+// BundleObjCache returns the objects of the bundle, for TCCfg.ObjCache.
+func BundleObjCache(bundle Bundle) map[string][]byte {
+	objs := make(map[string][]byte, len(bundle.Objects))
+	for name, obj := range bundle.Objects {
+		objs[name] = []byte(obj)
+	}
+	return objs
+}
@@ -0,0 +1,142 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atstccfg-bundle")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	toURL, _ := url.Parse("https://to.example.net/api/1.2/servers/myserver/configfiles/ats")
+	cfg := TCCfg{
+		Cfg:      Cfg{TOURL: toURL, ServerFiles: "myserver"},
+		ObjCache: map[string][]byte{"servers.json": []byte(`[{"hostName":"myserver"}]`)},
+	}
+
+	path := filepath.Join(dir, "bundle.json")
+	if err := WriteBundle(path, MakeBundle(cfg)); err != nil {
+		t.Fatalf("WriteBundle expected nil error, actual: %v", err)
+	}
+	bundle, err := ReadBundle(path)
+	if err != nil {
+		t.Fatalf("ReadBundle expected nil error, actual: %v", err)
+	}
+	if bundle.Version != BundleVersion {
+		t.Errorf("expected version %v, actual %v", BundleVersion, bundle.Version)
+	}
+	if bundle.Server != "myserver" {
+		t.Errorf("expected server myserver, actual '%v'", bundle.Server)
+	}
+	if bundle.TrafficOpsURL != "https://to.example.net" {
+		t.Errorf("expected Traffic Ops URL without path, actual '%v'", bundle.TrafficOpsURL)
+	}
+	objs := BundleObjCache(bundle)
+	if string(objs["servers.json"]) != `[{"hostName":"myserver"}]` {
+		t.Errorf("expected servers object to round trip, actual '%v'", string(objs["servers.json"]))
+	}
+}
+
+func TestReadBundleVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atstccfg-bundle")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bundle.json")
+	if err := ioutil.WriteFile(path, []byte(`{"version":999,"objects":{}}`), 0600); err != nil {
+		t.Fatalf("writing bundle: %v", err)
+	}
+	if _, err := ReadBundle(path); err == nil {
+		t.Errorf("ReadBundle of unknown version expected error, actual nil")
+	}
+}
+
+func TestGetConfigFileFromBundle(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	oldNow := atscfg.Now
+	atscfg.Now = func() time.Time { return created }
+	defer func() { atscfg.Now = oldNow }()
+
+	toURL, _ := url.Parse("https://to.example.net/api/1.2/profiles/42/configfiles/ats/12M_facts")
+	cfg := TCCfg{
+		Cfg: Cfg{TOURL: toURL, InputBundle: "bundle.json"},
+		ObjCache: map[string][]byte{
+			"profile_global_parameters.json": []byte(`[{"name":"tm.toolname","value":"Traffic Ops"},{"name":"tm.url","value":"https://to.example.net/"}]`),
+			"profile_42.json":                []byte(`{"id":42,"name":"EDGE_PROFILE"}`),
+		},
+	}
+
+	txt, code, err := GetConfigFile(cfg)
+	if err != nil {
+		t.Fatalf("GetConfigFile expected nil error, actual: %v", err)
+	}
+	if code != ExitCodeSuccess {
+		t.Errorf("GetConfigFile expected code %v, actual %v", ExitCodeSuccess, code)
+	}
+	expected := "# DO NOT EDIT - Generated for EDGE_PROFILE by Traffic Ops (https://to.example.net/) on Thu Jan 2 03:04:05 UTC 2020\nprofile:EDGE_PROFILE\n"
+	if txt != expected {
+		t.Errorf("GetConfigFile expected '%v', actual '%v'", expected, txt)
+	}
+
+	delete(cfg.ObjCache, "profile_42.json")
+	if _, _, err := GetConfigFile(cfg); err == nil {
+		t.Errorf("GetConfigFile with object missing from bundle expected error, actual nil")
+	}
+}
+
+func TestGetCachedJSONInputBundle(t *testing.T) {
+	cfg := TCCfg{
+		Cfg:      Cfg{InputBundle: "bundle.json"},
+		ObjCache: map[string][]byte{"traffic_ops_file_/api/1.2/servers/foo/configfiles/ats/bar.config": []byte(`"bar\n"`)},
+	}
+	getter := func(obj interface{}) error {
+		t.Errorf("GetCachedJSON with an input bundle expected getter not to be called")
+		return nil
+	}
+
+	objs := []string{}
+	if err := GetCachedJSON(cfg, "servers.json", &objs, getter); err == nil || !strings.Contains(err.Error(), "not in input bundle") {
+		t.Errorf("GetCachedJSON of object missing from bundle expected not in bundle error, actual %v", err)
+	}
+
+	cfg.TOURL, _ = url.Parse("https://to.example.net/api/1.2/servers/foo/configfiles/ats/bar.config")
+	txt, code, err := GetConfigFileFromTrafficOps(cfg)
+	if err != nil {
+		t.Fatalf("GetConfigFileFromTrafficOps expected nil error, actual: %v", err)
+	}
+	if code != ExitCodeSuccess || txt != "bar\n" {
+		t.Errorf("GetConfigFileFromTrafficOps expected code %v text 'bar\\n', actual %v '%v'", ExitCodeSuccess, code, txt)
+	}
+}
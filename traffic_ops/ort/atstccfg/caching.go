@@ -36,9 +36,28 @@ import (
 // GetCachedJSON attempts to get the given object from tempDir/cacheFileName.
 // If the cache file doesn't exist, is too old, or is malformed, it uses getter to get the object, and stores it in cacheFileName.
 // The object is placed in obj (which must be a pointer to the type of object to decode from JSON), and the error from getter is returned.
+// If cfg.InputBundle is set, the object is only gotten from cfg.ObjCache, and an error is returned if it isn't there.
 func GetCachedJSON(cfg TCCfg, cacheFileName string, obj interface{}, getter func(obj interface{}) error) error {
+	if cfg.InputBundle != "" {
+		bts, ok := cfg.ObjCache[cacheFileName]
+		if !ok {
+			return errors.New("object '" + cacheFileName + "' not in input bundle")
+		}
+		if err := json.Unmarshal(bts, obj); err != nil {
+			return errors.New("decoding object '" + cacheFileName + "' from input bundle: " + err.Error())
+		}
+		return nil
+	}
+
+	if bts, ok := cfg.ObjCache[cacheFileName]; ok {
+		if err := json.Unmarshal(bts, obj); err == nil {
+			return nil
+		}
+	}
+
 	err := GetJSONObjFromFile(cfg.TempDir, cacheFileName, cfg.CacheFileMaxAge, obj)
 	if err == nil {
+		cacheObjInMemory(cfg, cacheFileName, obj)
 		return nil
 	}
 
@@ -62,9 +81,23 @@ func GetCachedJSON(cfg TCCfg, cacheFileName string, obj interface{}, getter func
 	}
 
 	WriteCacheJSON(cfg.TempDir, cacheFileName, obj)
+	cacheObjInMemory(cfg, cacheFileName, obj)
 	return nil
 }
 
+// cacheObjInMemory stores the JSON of obj in cfg.ObjCache, if it isn't nil. Objects are stored as JSON, not pointers, so config file generators can't modify each other's objects.
+func cacheObjInMemory(cfg TCCfg, cacheFileName string, obj interface{}) {
+	if cfg.ObjCache == nil {
+		return
+	}
+	bts, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorln("serializing object '" + cacheFileName + "' to JSON for the memory cache: " + err.Error())
+		return
+	}
+	cfg.ObjCache[cacheFileName] = bts
+}
+
 // WriteCacheJSON attempts to write obj to tempDir/cacheFileName.
 // If there is an error, it is written to stderr but not returned.
 func WriteCacheJSON(tempDir string, cacheFileName string, obj interface{}) {
@@ -46,6 +46,15 @@ type Cfg struct {
 	TOURL               *url.URL
 	TOUser              string
 	PrintGeneratedFiles bool
+	// ServerFiles is the host name of the server to generate every config file of. If it's empty, the single file of TOURL's path is generated.
+	ServerFiles string
+	OutputDir   string
+	// ExportBundle is the path to write a bundle of the Traffic Ops objects used by ServerFiles to.
+	ExportBundle string
+	// InputBundle is the path of a bundle to generate files from, instead of Traffic Ops.
+	InputBundle string
+	// Validate is whether to check the files generated by ServerFiles for problems that would break Traffic Server.
+	Validate bool
 }
 
 func (cfg Cfg) ErrorLog() log.LogLocation   { return log.LogLocation(cfg.LogLocationErr) }
@@ -69,6 +78,11 @@ func GetCfg() (Cfg, error) {
 	toInsecurePtr := flag.BoolP("traffic-ops-insecure", "s", false, "Whether to ignore HTTPS certificate errors from Traffic Ops. It is HIGHLY RECOMMENDED to never use this in a production environment, but only for debugging.")
 	toTimeoutMSPtr := flag.IntP("traffic-ops-timeout-milliseconds", "t", 10000, "Timeout in seconds for Traffic Ops requests.")
 	cacheFileMaxAgeSecondsPtr := flag.IntP("cache-file-max-age-seconds", "a", 60, "Maximum age to use cached files.")
+	serverFilesPtr := flag.StringP("server-files", "S", "", "The host name of a server to generate every config file of, into --output-dir, printing a report of how they differ from the files on disk. Optional. If it's given, the path of the Traffic Ops URL is ignored.")
+	outputDirPtr := flag.StringP("output-dir", "o", "", "The directory to write the files generated by --server-files into. Required with --server-files.")
+	exportBundlePtr := flag.StringP("export-bundle", "x", "", "The file to write a bundle of every Traffic Ops object used by --server-files to, for --input-bundle. Optional.")
+	inputBundlePtr := flag.StringP("input-bundle", "b", "", "A bundle written by --export-bundle, to generate files from with no requests to Traffic Ops. Optional. If it's given, --traffic-ops-user and --traffic-ops-password aren't required, and --traffic-ops-url is only required without --server-files.")
+	validatePtr := flag.BoolP("validate", "V", false, "Whether to check the files generated by --server-files for problems that would break Traffic Server, like remap.config plugin argument files that aren't generated, duplicate or shadowed remap rules, and parent.config lines with no parents. Problems are logged and added to the report, and the exit code is 4. Optional.")
 	flag.Parse()
 
 	if *printGeneratedFilesPtr {
@@ -86,6 +100,11 @@ func GetCfg() (Cfg, error) {
 	toInsecure := *toInsecurePtr
 	toTimeout := time.Millisecond * time.Duration(*toTimeoutMSPtr)
 	cacheFileMaxAge := time.Second * time.Duration(*cacheFileMaxAgeSecondsPtr)
+	serverFiles := *serverFilesPtr
+	outputDir := *outputDirPtr
+	exportBundle := *exportBundlePtr
+	inputBundle := *inputBundlePtr
+	validate := *validatePtr
 
 	urlSourceStr := "argument" // for error messages
 	if toURL == "" {
@@ -99,21 +118,40 @@ func GetCfg() (Cfg, error) {
 		toPass = os.Getenv("TO_PASS")
 	}
 
-	if strings.TrimSpace(toURL) == "" {
+	if exportBundle != "" && serverFiles == "" {
+		return Cfg{}, errors.New("Missing required argument --server-files, which is required with --export-bundle. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass --server-files myserver --output-dir mydir --export-bundle mybundle")
+	}
+	if validate && serverFiles == "" {
+		return Cfg{}, errors.New("Missing required argument --server-files, which is required with --validate. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass --server-files myserver --output-dir mydir --validate")
+	}
+	if exportBundle != "" && inputBundle != "" {
+		return Cfg{}, errors.New("--export-bundle and --input-bundle can't both be given")
+	}
+
+	// with an input bundle, the URL path is only needed for a single file; otherwise the bundle's Traffic Ops URL is used.
+	if strings.TrimSpace(toURL) == "" && (inputBundle == "" || serverFiles == "") {
 		return Cfg{}, errors.New("Missing required argument --traffic-ops-url or TO_URL environment variable. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass")
 	}
-	if strings.TrimSpace(toUser) == "" {
+	if inputBundle == "" && strings.TrimSpace(toUser) == "" {
 		return Cfg{}, errors.New("Missing required argument --traffic-ops-user or TO_USER environment variable. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass")
 	}
-	if strings.TrimSpace(toPass) == "" {
+	if inputBundle == "" && strings.TrimSpace(toPass) == "" {
 		return Cfg{}, errors.New("Missing required argument --traffic-ops-password or TO_PASS environment variable. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass")
 	}
 
-	toURLParsed, err := url.Parse(toURL)
-	if err != nil {
-		return Cfg{}, errors.New("parsing Traffic Ops URL from " + urlSourceStr + " '" + toURL + "': " + err.Error())
-	} else if err := ValidateURL(toURLParsed); err != nil {
-		return Cfg{}, errors.New("invalid Traffic Ops URL from " + urlSourceStr + " '" + toURL + "': " + err.Error())
+	if serverFiles != "" && strings.TrimSpace(outputDir) == "" {
+		return Cfg{}, errors.New("Missing required argument --output-dir, which is required with --server-files. Usage: ./" + AppName + " --traffic-ops-url myurl --traffic-ops-user myuser --traffic-ops-password mypass --server-files myserver --output-dir mydir")
+	}
+
+	toURLParsed := (*url.URL)(nil)
+	if strings.TrimSpace(toURL) != "" {
+		err := error(nil)
+		toURLParsed, err = url.Parse(toURL)
+		if err != nil {
+			return Cfg{}, errors.New("parsing Traffic Ops URL from " + urlSourceStr + " '" + toURL + "': " + err.Error())
+		} else if err := ValidateURL(toURLParsed); err != nil {
+			return Cfg{}, errors.New("invalid Traffic Ops URL from " + urlSourceStr + " '" + toURL + "': " + err.Error())
+		}
 	}
 
 	tmpDir := os.TempDir()
@@ -131,6 +169,11 @@ func GetCfg() (Cfg, error) {
 		TOTimeout:       toTimeout,
 		TOURL:           toURLParsed,
 		TOUser:          toUser,
+		ServerFiles:     serverFiles,
+		OutputDir:       outputDir,
+		ExportBundle:    exportBundle,
+		InputBundle:     inputBundle,
+		Validate:        validate,
 	}
 
 	if err := log.InitCfg(cfg); err != nil {
@@ -150,6 +193,15 @@ func GetCfg() (Cfg, error) {
 		return Cfg{}, errors.New("validating temp directory is writeable '" + tmpDir + "': " + err.Error())
 	}
 
+	if cfg.ServerFiles != "" {
+		if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+			return Cfg{}, errors.New("creating output directory '" + cfg.OutputDir + "': " + err.Error())
+		}
+		if err := ValidateDirWriteable(cfg.OutputDir); err != nil {
+			return Cfg{}, errors.New("validating output directory is writeable '" + cfg.OutputDir + "': " + err.Error())
+		}
+	}
+
 	return cfg, nil
 }
 
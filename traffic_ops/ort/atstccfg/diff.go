@@ -0,0 +1,258 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DiffContextLines is the number of unchanged lines around each change in a unified diff.
+const DiffContextLines = 3
+
+// MaxDiffEdits is the maximum number of line insertions and deletions the diff looks for. Files which differ by more are diffed as deleting every line and inserting every line, which is correct but not minimal.
+const MaxDiffEdits = 2000
+
+const noNewlineMarker = "\n\\ No newline at end of file"
+
+type diffKind int
+
+const (
+	diffEqual diffKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a line of a diff. For equal and deleted lines, aLine is the index of the line in a; for inserted lines, it's the index in a before which the line is inserted. Likewise bLine, for b.
+type diffOp struct {
+	kind  diffKind
+	aLine int
+	bLine int
+}
+
+// UnifiedDiff returns the unified diff of the lines of a and b, labelled with the given names, or the empty string if they're the same.
+func UnifiedDiff(aName string, bName string, a string, b string) string {
+	if a == b {
+		return ""
+	}
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+
+	sb := strings.Builder{}
+	sb.WriteString("--- " + aName + "\n")
+	sb.WriteString("+++ " + bName + "\n")
+	for _, hunk := range diffHunks(diffLines(aLines, bLines), DiffContextLines) {
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			if op.kind != diffInsert {
+				aLen++
+			}
+			if op.kind != diffDelete {
+				bLen++
+			}
+		}
+		sb.WriteString("@@ -" + hunkRange(hunk[0].aLine, aLen) + " +" + hunkRange(hunk[0].bLine, bLen) + " @@\n")
+		for _, op := range hunk {
+			switch op.kind {
+			case diffEqual:
+				sb.WriteString(" " + aLines[op.aLine] + "\n")
+			case diffDelete:
+				sb.WriteString("-" + aLines[op.aLine] + "\n")
+			case diffInsert:
+				sb.WriteString("+" + bLines[op.bLine] + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines. If s doesn't end with a newline, the marker is appended to its last line, so it differs from the same line with a newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += noNewlineMarker
+	return lines
+}
+
+// hunkRange returns the range of a hunk header, of the given 0-based start line and length.
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if length == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(length)
+}
+
+// diffLines returns the shortest edit script from a to b, using Myers' algorithm on the lines between their common prefix and suffix.
+func diffLines(a []string, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := []diffOp{}
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: diffEqual, aLine: i, bLine: i})
+	}
+	for _, op := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		op.aLine += prefix
+		op.bLine += prefix
+		ops = append(ops, op)
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, diffOp{kind: diffEqual, aLine: len(a) - i, bLine: len(b) - i})
+	}
+	return ops
+}
+
+// myers returns the shortest edit script from a to b, or deletes all of a and inserts all of b if it has more than MaxDiffEdits edits.
+func myers(a []string, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > MaxDiffEdits {
+		maxD = MaxDiffEdits
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	trace := [][]int{} // trace[d] is v before round d, v[offset-d:offset+d+1]
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			x := 0
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		ops := []diffOp{}
+		for i := range a {
+			ops = append(ops, diffOp{kind: diffDelete, aLine: i, bLine: 0})
+		}
+		for i := range b {
+			ops = append(ops, diffOp{kind: diffInsert, aLine: n, bLine: i})
+		}
+		return ops
+	}
+
+	reversed := []diffOp{}
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		get := func(k int) int { return prev[k+d] } // prev is indexed from -d
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = get(prevK)
+		}
+		prevY := prevX - prevK
+		if d == 0 {
+			prevY = 0
+		}
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, diffOp{kind: diffEqual, aLine: x, bLine: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffOp{kind: diffInsert, aLine: x, bLine: y - 1})
+			} else {
+				reversed = append(reversed, diffOp{kind: diffDelete, aLine: x - 1, bLine: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]diffOp, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		ops = append(ops, reversed[i])
+	}
+	return ops
+}
+
+// diffHunks groups the given edit script into hunks of changes, with the given number of unchanged lines of context around them. Changes separated by no more than twice the context are in the same hunk.
+func diffHunks(ops []diffOp, context int) [][]diffOp {
+	hunks := [][]diffOp{}
+	i := 0
+	for i < len(ops) {
+		for i < len(ops) && ops[i].kind == diffEqual {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != diffEqual {
+				end++
+			}
+			equalEnd := end
+			for equalEnd < len(ops) && ops[equalEnd].kind == diffEqual {
+				equalEnd++
+			}
+			if equalEnd < len(ops) && equalEnd-end <= 2*context {
+				end = equalEnd
+				continue
+			}
+			end += context
+			if end > len(ops) {
+				end = len(ops)
+			}
+			break
+		}
+		hunks = append(hunks, ops[start:end])
+		i = end
+	}
+	return hunks
+}
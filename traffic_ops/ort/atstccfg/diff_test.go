@@ -0,0 +1,142 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	if actual := UnifiedDiff("a", "b", a, b); actual != "" {
+		t.Errorf("UnifiedDiff of the same text expected: empty, actual: '%v'", actual)
+	}
+
+	b = "zero\none\ntwo\nthree\nfour\nfive\nsix\nseven\nEIGHT\nnine\nten\n"
+	expected := `--- a
++++ b
+@@ -1,3 +1,4 @@
++zero
+ one
+ two
+ three
+@@ -5,6 +6,6 @@
+ five
+ six
+ seven
+-eight
++EIGHT
+ nine
+ ten
+`
+	if actual := UnifiedDiff("a", "b", a, b); actual != expected {
+		t.Errorf("UnifiedDiff expected:\n%v\nactual:\n%v", expected, actual)
+	}
+
+	b = "one\ntwo\nthree\n"
+	expected = `--- a
++++ b
+@@ -1,10 +1,3 @@
+ one
+ two
+ three
+-four
+-five
+-six
+-seven
+-eight
+-nine
+-ten
+`
+	if actual := UnifiedDiff("a", "b", a, b); actual != expected {
+		t.Errorf("UnifiedDiff of truncated text expected:\n%v\nactual:\n%v", expected, actual)
+	}
+
+	expected = `--- a
++++ b
+@@ -1 +1 @@
+-one
++one
+\ No newline at end of file
+`
+	if actual := UnifiedDiff("a", "b", "one\n", "one"); actual != expected {
+		t.Errorf("UnifiedDiff of missing newline expected:\n%v\nactual:\n%v", expected, actual)
+	}
+}
+
+// applyDiffOps applies an edit script to a, which must result in b.
+func applyDiffOps(a []string, b []string, ops []diffOp) []string {
+	result := []string{}
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			result = append(result, a[op.aLine])
+		case diffInsert:
+			result = append(result, b[op.bLine])
+		}
+	}
+	return result
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := [][2]string{
+		{"a b c a b b a", "c b a b a c"},
+		{"", "a b c"},
+		{"a b c", ""},
+		{"x a y b z c", "a b c"},
+		{"a b c d e f g", "a x c d y f g z"},
+	}
+	for _, test := range tests {
+		a, b := strings.Fields(test[0]), strings.Fields(test[1])
+		ops := diffLines(a, b)
+		if actual := applyDiffOps(a, b, ops); strings.Join(actual, " ") != strings.Join(b, " ") {
+			t.Errorf("diffLines('%v', '%v') applied expected: '%v', actual: '%v'", test[0], test[1], test[1], strings.Join(actual, " "))
+		}
+	}
+
+	// the classic example has a shortest edit script of 5 edits
+	ops := diffLines(strings.Fields("a b c a b b a"), strings.Fields("c b a b a c"))
+	edits := 0
+	for _, op := range ops {
+		if op.kind != diffEqual {
+			edits++
+		}
+	}
+	if edits != 5 {
+		t.Errorf("diffLines edits expected: 5, actual: %v", edits)
+	}
+
+	// more edits than the maximum fall back to replacing everything
+	a, b := []string{}, []string{}
+	for i := 0; i < MaxDiffEdits; i++ {
+		a = append(a, "a"+strconv.Itoa(i))
+		b = append(b, "b"+strconv.Itoa(i))
+	}
+	ops = diffLines(a, b)
+	if len(ops) != 2*MaxDiffEdits {
+		t.Errorf("diffLines over max edits expected: %v ops, actual: %v", 2*MaxDiffEdits, len(ops))
+	}
+	if actual := applyDiffOps(a, b, ops); strings.Join(actual, " ") != strings.Join(b, " ") {
+		t.Error("diffLines over max edits applied expected: b, actual: not b")
+	}
+}
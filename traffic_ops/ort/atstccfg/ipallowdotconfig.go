@@ -28,10 +28,38 @@ import (
 )
 
 func GetConfigFileServerIPAllowDotConfig(cfg TCCfg, serverNameOrID string) (string, error) {
+	data, err := getIPAllowConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", err
+	}
+	return atscfg.MakeIPAllowDotConfig(data.ServerName, data.ServerType, data.TOToolName, data.TOURL, data.Params, data.ChildServers), nil
+}
+
+func GetConfigFileServerIPAllowDotYAML(cfg TCCfg, serverNameOrID string) (string, error) {
+	data, err := getIPAllowConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", err
+	}
+	return atscfg.MakeIPAllowDotYAML(data.ServerName, data.ServerType, data.TOToolName, data.TOURL, data.ATSMajorVer, data.Params, data.ChildServers), nil
+}
+
+// ipAllowConfigData is the data to make the ip_allow.config or ip_allow.yaml of a server.
+type ipAllowConfigData struct {
+	ServerName  tc.CacheName
+	ServerType  tc.CacheType
+	ATSMajorVer int
+	TOToolName  string
+	TOURL       string
+	// Params are the server profile parameters with the config file ip_allow.config or ip_allow.yaml.
+	Params       map[string][]string
+	ChildServers map[tc.CacheName]atscfg.IPAllowServer
+}
+
+func getIPAllowConfigData(cfg TCCfg, serverNameOrID string) (ipAllowConfigData, error) {
 	// TODO TOAPI add /servers?cdn=1 query param
 	servers, err := GetServers(cfg)
 	if err != nil {
-		return "", errors.New("getting servers: " + err.Error())
+		return ipAllowConfigData{}, errors.New("getting servers: " + err.Error())
 	}
 
 	server := tc.Server{ID: atscfg.InvalidID}
@@ -52,7 +80,7 @@ func GetConfigFileServerIPAllowDotConfig(cfg TCCfg, serverNameOrID string) (stri
 		}
 	}
 	if server.ID == atscfg.InvalidID {
-		return "", errors.New("server '" + serverNameOrID + " not found in servers")
+		return ipAllowConfigData{}, errors.New("server '" + serverNameOrID + " not found in servers")
 	}
 
 	serverName := tc.CacheName(server.HostName)
@@ -60,43 +88,55 @@ func GetConfigFileServerIPAllowDotConfig(cfg TCCfg, serverNameOrID string) (stri
 
 	toToolName, toURL, err := GetTOToolNameAndURLFromTO(cfg)
 	if err != nil {
-		return "", errors.New("getting global parameters: " + err.Error())
+		return ipAllowConfigData{}, errors.New("getting global parameters: " + err.Error())
 	}
 
 	profileParams, err := GetProfileParameters(cfg, server.Profile)
 	if err != nil {
-		return "", errors.New("getting profile '" + server.Profile + "' parameters: " + err.Error())
+		return ipAllowConfigData{}, errors.New("getting profile '" + server.Profile + "' parameters: " + err.Error())
 	}
 	if len(profileParams) == 0 {
 		// The TO endpoint behind toclient.GetParametersByProfileName returns an empty object with a 200, if the Profile doesn't exist.
 		// So we act as though we got a 404 if there are no params, to make ORT behave correctly.
-		return "", ErrNotFound
+		return ipAllowConfigData{}, ErrNotFound
 	}
 
+	atsVersionParam := ""
 	fileParams := map[string][]string{}
 	for _, param := range profileParams {
-		if param.ConfigFile != atscfg.IPAllowConfigFileName {
+		if param.ConfigFile == "package" && param.Name == "trafficserver" && atsVersionParam == "" {
+			atsVersionParam = param.Value
+		}
+		if param.ConfigFile != atscfg.IPAllowConfigFileName && param.ConfigFile != atscfg.IPAllowYAMLFileName {
 			continue
 		}
 		fileParams[param.Name] = append(fileParams[param.Name], param.Value)
 	}
+	if atsVersionParam == "" {
+		atsVersionParam = atscfg.DefaultATSVersion
+	}
+
+	atsMajorVer, err := atscfg.GetATSMajorVersionFromATSVersion(atsVersionParam)
+	if err != nil {
+		return ipAllowConfigData{}, errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
+	}
 
 	cacheGroups, err := GetCacheGroups(cfg)
 	if err != nil {
-		return "", errors.New("getting cachegroups: " + err.Error())
+		return ipAllowConfigData{}, errors.New("getting cachegroups: " + err.Error())
 	}
 
 	cgMap := map[string]tc.CacheGroupNullable{}
 	for _, cg := range cacheGroups {
 		if cg.Name == nil {
-			return "", errors.New("got cachegroup with nil name!'")
+			return ipAllowConfigData{}, errors.New("got cachegroup with nil name!'")
 		}
 		cgMap[*cg.Name] = cg
 	}
 
 	serverCG, ok := cgMap[server.Cachegroup]
 	if !ok {
-		return "", errors.New("server cachegroup not in cachegroups!")
+		return ipAllowConfigData{}, errors.New("server cachegroup not in cachegroups!")
 	}
 
 	childCGs := map[string]tc.CacheGroupNullable{}
@@ -114,6 +154,13 @@ func GetConfigFileServerIPAllowDotConfig(cfg TCCfg, serverNameOrID string) (stri
 		childServers[tc.CacheName(sv.HostName)] = atscfg.IPAllowServer{IPAddress: sv.IPAddress, IP6Address: sv.IP6Address}
 	}
 
-	txt := atscfg.MakeIPAllowDotConfig(serverName, serverType, toToolName, toURL, fileParams, childServers)
-	return txt, nil
+	return ipAllowConfigData{
+		ServerName:   serverName,
+		ServerType:   serverType,
+		ATSMajorVer:  atsMajorVer,
+		TOToolName:   toToolName,
+		TOURL:        toURL,
+		Params:       fileParams,
+		ChildServers: childServers,
+	}, nil
 }
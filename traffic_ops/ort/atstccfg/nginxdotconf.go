@@ -0,0 +1,64 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-log"
+)
+
+func GetConfigFileServerNginxDotConf(cfg TCCfg, serverNameOrID string) (string, error) {
+	remapData, err := getRemapConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", errors.New("getting remap data: " + err.Error())
+	}
+	parentData, err := getParentConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", errors.New("getting parent data: " + err.Error())
+	}
+	ipAllowData, err := getIPAllowConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", errors.New("getting ip allow data: " + err.Error())
+	}
+
+	serverProfileParams, err := GetProfileParameters(cfg, remapData.ServerInfo.ProfileName)
+	if err != nil {
+		return "", errors.New("getting profile parameters from server (profile '" + remapData.ServerInfo.ProfileName + ": " + err.Error())
+	}
+
+	nginxParams := map[string]string{}
+	for _, param := range serverProfileParams {
+		if param.ConfigFile != atscfg.NginxConfigFileName || param.Name == "location" {
+			continue
+		}
+		if existingVal, ok := nginxParams[param.Name]; ok {
+			log.Warnln("generating " + atscfg.NginxConfigFileName + ": server profile '" + remapData.ServerInfo.ProfileName + "' has multiple parameters for '" + param.Name + "' - using '" + existingVal + "' and ignoring the rest!")
+			continue
+		}
+		nginxParams[param.Name] = param.Value
+	}
+
+	sslDSes := atscfg.DeliveryServicesToSSLMultiCertDSes(remapData.DeliveryServices)
+
+	txt := atscfg.MakeNginxDotConf(remapData.ServerInfo, remapData.TOToolName, remapData.TOURL, nginxParams, remapData.RemapConfigDSData, sslDSes, parentData.ParentConfigDSes, parentData.ServerParams, parentData.ParentInfos, ipAllowData.Params, ipAllowData.ChildServers)
+	return txt, nil
+}
@@ -35,10 +35,37 @@ import (
 const GlobalProfileName = "GLOBAL"
 
 func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (string, error) {
+	data, err := getParentConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", err
+	}
+	return atscfg.MakeParentDotConfig(data.ServerInfo, data.ATSMajorVer, data.TOToolName, data.TOURL, data.ParentConfigDSes, data.ServerParams, data.ParentInfos), nil
+}
+
+func GetConfigFileServerStrategiesDotYAML(cfg TCCfg, serverNameOrID string) (string, error) {
+	data, err := getParentConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", err
+	}
+	return atscfg.MakeStrategiesDotYAML(data.ServerInfo, data.ATSMajorVer, data.TOToolName, data.TOURL, data.ParentConfigDSes, data.ServerParams, data.ParentInfos), nil
+}
+
+// parentConfigData is the data to make the parent.config and strategies.yaml of a server.
+type parentConfigData struct {
+	ServerInfo       *atscfg.ServerInfo
+	ATSMajorVer      int
+	TOToolName       string
+	TOURL            string
+	ParentConfigDSes []atscfg.ParentConfigDSTopLevel
+	ServerParams     map[string]string
+	ParentInfos      map[atscfg.OriginHost][]atscfg.ParentInfo
+}
+
+func getParentConfigData(cfg TCCfg, serverNameOrID string) (parentConfigData, error) {
 	// TODO TOAPI add /servers?cdn=1 query param
 	servers, err := GetServers(cfg)
 	if err != nil {
-		return "", errors.New("getting servers: " + err.Error())
+		return parentConfigData{}, errors.New("getting servers: " + err.Error())
 	}
 
 	server := tc.Server{ID: atscfg.InvalidID}
@@ -59,25 +86,25 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		}
 	}
 	if server.ID == atscfg.InvalidID {
-		return "", errors.New("server '" + serverNameOrID + " not found in servers")
+		return parentConfigData{}, errors.New("server '" + serverNameOrID + " not found in servers")
 	}
 
 	cacheGroups, err := GetCacheGroups(cfg)
 	if err != nil {
-		return "", errors.New("getting cachegroups: " + err.Error())
+		return parentConfigData{}, errors.New("getting cachegroups: " + err.Error())
 	}
 
 	cgMap := map[string]tc.CacheGroupNullable{}
 	for _, cg := range cacheGroups {
 		if cg.Name == nil {
-			return "", errors.New("got cachegroup with nil name!'")
+			return parentConfigData{}, errors.New("got cachegroup with nil name!'")
 		}
 		cgMap[*cg.Name] = cg
 	}
 
 	serverCG, ok := cgMap[server.Cachegroup]
 	if !ok {
-		return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' not found in CacheGroups")
+		return parentConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' not found in CacheGroups")
 	}
 
 	parentCGID := -1
@@ -85,15 +112,15 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 	if serverCG.ParentName != nil && *serverCG.ParentName != "" {
 		parentCG, ok := cgMap[*serverCG.ParentName]
 		if !ok {
-			return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' parent '" + *serverCG.ParentName + "' not found in CacheGroups")
+			return parentConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' parent '" + *serverCG.ParentName + "' not found in CacheGroups")
 		}
 		if parentCG.ID == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
+			return parentConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
 		}
 		parentCGID = *parentCG.ID
 
 		if parentCG.Type == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
+			return parentConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
 		}
 		parentCGType = *parentCG.Type
 	}
@@ -103,15 +130,15 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 	if serverCG.SecondaryParentName != nil && *serverCG.SecondaryParentName != "" {
 		parentCG, ok := cgMap[*serverCG.SecondaryParentName]
 		if !ok {
-			return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' secondary parent '" + *serverCG.SecondaryParentName + "' not found in CacheGroups")
+			return parentConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' secondary parent '" + *serverCG.SecondaryParentName + "' not found in CacheGroups")
 		}
 
 		if parentCG.ID == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
+			return parentConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
 		}
 		secondaryParentCGID = *parentCG.ID
 		if parentCG.Type == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
+			return parentConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
 		}
 
 		secondaryParentCGType = *parentCG.Type
@@ -140,10 +167,10 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		log.Infoln("This cache Is Top Level!")
 		for _, cg := range cacheGroups {
 			if cg.Type == nil {
-				return "", errors.New("cachegroup type is nil!")
+				return parentConfigData{}, errors.New("cachegroup type is nil!")
 			}
 			if cg.Name == nil {
-				return "", errors.New("cachegroup type is nil!")
+				return parentConfigData{}, errors.New("cachegroup type is nil!")
 			}
 
 			if *cg.Type != tc.CacheGroupOriginTypeName {
@@ -153,14 +180,14 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		}
 	} else {
 		if server.Cachegroup == "" {
-			return "", errors.New("server cachegroup is nil!")
+			return parentConfigData{}, errors.New("server cachegroup is nil!")
 		}
 		for _, cg := range cacheGroups {
 			if cg.Type == nil {
-				return "", errors.New("cachegroup type is nil!")
+				return parentConfigData{}, errors.New("cachegroup type is nil!")
 			}
 			if cg.Name == nil {
-				return "", errors.New("cachegroup type is nil!")
+				return parentConfigData{}, errors.New("cachegroup type is nil!")
 			}
 
 			if *cg.Name == server.Cachegroup {
@@ -175,12 +202,46 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		}
 	}
 
+	topologies, err := GetTopologies(cfg)
+	if err != nil {
+		return parentConfigData{}, errors.New("getting topologies: " + err.Error())
+	}
+
+	topologyMap := map[string]tc.Topology{}
+	topologyParentCacheGroups := map[string]struct{}{} // this server's parents in topologies, which are only parents of delivery services with those topologies
+	topologyTopLevel := false
+	for _, topology := range topologies {
+		topologyMap[topology.Name] = topology
+		primary, secondary, ok := topology.CacheGroupParents(server.Cachegroup)
+		if !ok {
+			continue
+		}
+		if primary == "" && secondary == "" {
+			topologyTopLevel = true
+		}
+		for _, parent := range []string{primary, secondary} {
+			if parent != "" {
+				topologyParentCacheGroups[parent] = struct{}{}
+			}
+		}
+	}
+	if topologyTopLevel && !serverInfo.IsTopLevelCache() {
+		// multi-site origin delivery services whose topology has this server in its top tier need the origins
+		for _, cg := range cacheGroups {
+			if cg.Type != nil && *cg.Type == tc.CacheGroupOriginTypeName {
+				topologyParentCacheGroups[*cg.Name] = struct{}{}
+			}
+		}
+	}
+
 	cgServers := map[int]tc.Server{} // map[serverID]server
 	for _, sv := range servers {
 		if sv.CDNName != server.CDNName {
 			continue
 		}
-		if _, ok := parentCacheGroups[sv.Cachegroup]; !ok {
+		_, isParent := parentCacheGroups[sv.Cachegroup]
+		_, isTopologyParent := topologyParentCacheGroups[sv.Cachegroup]
+		if !isParent && !isTopologyParent {
 			continue
 		}
 		if sv.Type != tc.OriginTypeName &&
@@ -202,18 +263,18 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	serverCapabilities, err := GetServerCapabilitiesByID(cfg, cgServerIDs)
 	if err != nil {
-		return "", errors.New("getting server capabilities: " + err.Error())
+		return parentConfigData{}, errors.New("getting server capabilities: " + err.Error())
 	}
 
 	cgDSServers, err := GetDeliveryServiceServers(cfg, nil, cgServerIDs)
 	if err != nil {
-		return "", errors.New("getting parent.config cachegroup parent server delivery service servers: " + err.Error())
+		return parentConfigData{}, errors.New("getting parent.config cachegroup parent server delivery service servers: " + err.Error())
 	}
 
 	parentServerDSes := map[int]map[int]struct{}{} // map[serverID][dsID] // cgServerDSes
 	for _, dss := range cgDSServers {
 		if dss.Server == nil || dss.DeliveryService == nil {
-			return "", errors.New("getting parent.config cachegroup parent server delivery service servers: got dss with nil members!" + err.Error())
+			return parentConfigData{}, errors.New("getting parent.config cachegroup parent server delivery service servers: got dss with nil members!" + err.Error())
 		}
 		if parentServerDSes[*dss.Server] == nil {
 			parentServerDSes[*dss.Server] = map[int]struct{}{}
@@ -223,7 +284,7 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	serverProfileParameters, err := GetServerProfileParameters(cfg, server.Profile)
 	if err != nil {
-		return "", errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
+		return parentConfigData{}, errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
 	}
 
 	atsVersionParam := ""
@@ -240,12 +301,12 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	atsMajorVer, err := atscfg.GetATSMajorVersionFromATSVersion(atsVersionParam)
 	if err != nil {
-		return "", errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
+		return parentConfigData{}, errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
 	}
 
 	globalParams, err := GetGlobalParameters(cfg)
 	if err != nil {
-		return "", errors.New("getting global parameters: " + err.Error())
+		return parentConfigData{}, errors.New("getting global parameters: " + err.Error())
 	}
 
 	toToolName := ""
@@ -263,17 +324,17 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	deliveryServices, err := GetCDNDeliveryServices(cfg, server.CDNID)
 	if err != nil {
-		return "", errors.New("getting delivery services: " + err.Error())
+		return parentConfigData{}, errors.New("getting delivery services: " + err.Error())
 	}
 
 	parentConfigParams, err := GetConfigFileParameters(cfg, "parent.config")
 	if err != nil {
-		return "", errors.New("getting parent.config parameters: " + err.Error())
+		return parentConfigData{}, errors.New("getting parent.config parameters: " + err.Error())
 	}
 
 	parentConfigParamsWithProfiles, err := TCParamsToParamsWithProfiles(parentConfigParams)
 	if err != nil {
-		return "", errors.New("unmarshalling parent.config parameters profiles: " + err.Error())
+		return parentConfigData{}, errors.New("unmarshalling parent.config parameters profiles: " + err.Error())
 	}
 
 	// this is an optimization, to avoid looping over all params, for every DS. Instead, we loop over all params only once, and put them in a profile map.
@@ -300,7 +361,7 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	cdn, err := GetCDN(cfg, serverInfo.CDN)
 	if err != nil {
-		return "", errors.New("getting cdn '" + string(serverInfo.CDN) + "': " + err.Error())
+		return parentConfigData{}, errors.New("getting cdn '" + string(serverInfo.CDN) + "': " + err.Error())
 	}
 
 	serverCDNDomain := cdn.DomainName
@@ -380,6 +441,12 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		}
 	}
 
+	for dsID, ds := range dsIDMap {
+		if ds.Topology != nil && *ds.Topology != "" {
+			allDSMap[dsID] = ds // servers in the inner tiers of a topology aren't assigned its delivery services
+		}
+	}
+
 	allDSes := []int{}
 	for ds, _ := range allDSMap {
 		allDSes = append(allDSes, int(ds))
@@ -387,7 +454,7 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	dsRequiredCapabilities, err := GetDeliveryServiceRequiredCapabilitiesByID(cfg, allDSes)
 	if err != nil {
-		return "", errors.New("getting DS required capabilities: " + err.Error())
+		return parentConfigData{}, errors.New("getting DS required capabilities: " + err.Error())
 	}
 
 	parentConfigDSes := []atscfg.ParentConfigDSTopLevel{}
@@ -396,7 +463,17 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 			continue // TODO warn?
 		}
 
-		if !serverInfo.IsTopLevelCache() {
+		if tcDS.Topology != nil && *tcDS.Topology != "" {
+			topology, ok := topologyMap[*tcDS.Topology]
+			if !ok {
+				log.Errorln("ds id " + strconv.Itoa(*tcDS.ID) + " topology '" + *tcDS.Topology + "' not found! Skipping!")
+				continue
+			}
+			_, assigned := parentServerDSes[server.ID][*tcDS.ID]
+			if !atscfg.TopologyIncludesServer(topology, server.Cachegroup, assigned) {
+				continue // skip DSes whose topology doesn't include this server
+			}
+		} else if !serverInfo.IsTopLevelCache() {
 			if _, ok := parentServerDSes[server.ID][*tcDS.ID]; !ok {
 				continue // skip DSes not assigned to this server.
 			}
@@ -472,6 +549,9 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 		}
 
 		ds.RequiredCapabilities = dsRequiredCapabilities[*tcDS.ID]
+		if tcDS.Topology != nil {
+			ds.Topology = *tcDS.Topology
+		}
 
 		parentConfigDSes = append(parentConfigDSes, ds)
 	}
@@ -492,6 +572,7 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	originServers := map[atscfg.OriginHost][]atscfg.CGServer{}  // "deliveryServices" in Perl
 	profileCaches := map[atscfg.ProfileID]atscfg.ProfileCache{} // map[profileID]ProfileCache
+	topologyServers := []atscfg.CGServer{}
 
 	for _, cgServer := range cgServers {
 		realCGServer := atscfg.CGServer{
@@ -524,7 +605,12 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 				}
 			}
 		} else {
-			originServers[atscfg.DeliveryServicesAllParentsKey] = append(originServers[atscfg.DeliveryServicesAllParentsKey], realCGServer)
+			if _, ok := parentCacheGroups[cgServer.Cachegroup]; ok {
+				originServers[atscfg.DeliveryServicesAllParentsKey] = append(originServers[atscfg.DeliveryServicesAllParentsKey], realCGServer)
+			}
+			if _, ok := topologyParentCacheGroups[cgServer.Cachegroup]; ok {
+				topologyServers = append(topologyServers, realCGServer)
+			}
 		}
 
 		if _, profileCachesHasProfile := profileCaches[realCGServer.ProfileID]; !profileCachesHasProfile {
@@ -539,7 +625,35 @@ func GetConfigFileServerParentDotConfig(cfg TCCfg, serverNameOrID string) (strin
 
 	parentInfos := atscfg.MakeParentInfo(&serverInfo, serverCDNDomain, profileCaches, originServers)
 
-	return atscfg.MakeParentDotConfig(&serverInfo, atsMajorVer, toToolName, toURL, parentConfigDSes, serverParams, parentInfos), nil
+	cgID := func(name string) int {
+		if cg, ok := cgMap[name]; ok && cg.ID != nil {
+			return *cg.ID
+		}
+		return atscfg.InvalidID
+	}
+	for i, ds := range parentConfigDSes {
+		if ds.Topology == "" {
+			continue
+		}
+		primary, secondary, _ := topologyMap[ds.Topology].CacheGroupParents(server.Cachegroup)
+		if primary == "" && secondary == "" {
+			continue
+		}
+		parentConfigDSes[i].TopologyParents = atscfg.MakeTopologyParentInfo(cgID(primary), cgID(secondary), profileCaches, topologyServers)
+		if len(parentConfigDSes[i].TopologyParents) == 0 {
+			log.Warnln("ds '" + string(ds.Name) + "' topology '" + ds.Topology + "' has no available parents for this server, going direct!")
+		}
+	}
+
+	return parentConfigData{
+		ServerInfo:       &serverInfo,
+		ATSMajorVer:      atsMajorVer,
+		TOToolName:       toToolName,
+		TOURL:            toURL,
+		ParentConfigDSes: parentConfigDSes,
+		ServerParams:     serverParams,
+		ParentInfos:      parentInfos,
+	}, nil
 }
 
 // GetDSOrigins takes a map[deliveryServiceID]DeliveryService, and returns a map[DeliveryServiceID]OriginURI.
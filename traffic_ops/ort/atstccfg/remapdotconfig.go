@@ -32,10 +32,48 @@ import (
 )
 
 func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string, error) {
+	data, err := getRemapConfigData(cfg, serverNameOrID)
+	if err != nil {
+		return "", err
+	}
+
+	// strategies.yaml isn't generated or installed without its location parameter, so remap.config can't use its strategies.
+	if data.ATSMajorVer >= atscfg.StrategiesMinATSMajorVersion && data.HasStrategiesLocation {
+		parentData, err := getParentConfigData(cfg, serverNameOrID)
+		if err != nil {
+			return "", errors.New("getting strategies data: " + err.Error())
+		}
+		dsStrategies := atscfg.MakeDSStrategyNames(parentData.ServerInfo, parentData.ATSMajorVer, parentData.ParentConfigDSes, parentData.ServerParams, parentData.ParentInfos)
+		for i, ds := range data.RemapConfigDSData {
+			data.RemapConfigDSData[i].Strategy = dsStrategies[tc.DeliveryServiceName(ds.Name)]
+		}
+	}
+
+	txt := atscfg.MakeRemapDotConfig(tc.CacheName(data.ServerInfo.HostName), data.TOToolName, data.TOURL, data.ATSMajorVer, data.CacheURLParams, data.DSProfilesCacheKeyConfigParams, data.ServerPackageParamData, data.ServerInfo, data.RemapConfigDSData)
+	return txt, nil
+}
+
+// remapConfigData is the data to make the remap.config of a server.
+type remapConfigData struct {
+	ServerInfo                     *atscfg.ServerInfo
+	ATSMajorVer                    int
+	TOToolName                     string
+	TOURL                          string
+	CacheURLParams                 map[string]string
+	DSProfilesCacheKeyConfigParams map[int]map[string]string
+	ServerPackageParamData         map[string]string
+	RemapConfigDSData              []atscfg.RemapConfigDSData
+	// DeliveryServices are the delivery services the server serves.
+	DeliveryServices []tc.DeliveryServiceNullable
+	// HasStrategiesLocation is whether the server's profile has the strategies.yaml location parameter.
+	HasStrategiesLocation bool
+}
+
+func getRemapConfigData(cfg TCCfg, serverNameOrID string) (remapConfigData, error) {
 	// TODO TOAPI add /servers?cdn=1 query param
 	servers, err := GetServers(cfg)
 	if err != nil {
-		return "", errors.New("getting servers: " + err.Error())
+		return remapConfigData{}, errors.New("getting servers: " + err.Error())
 	}
 
 	server := tc.Server{ID: atscfg.InvalidID}
@@ -56,26 +94,24 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 		}
 	}
 	if server.ID == atscfg.InvalidID {
-		return "", errors.New("server '" + serverNameOrID + " not found in servers")
+		return remapConfigData{}, errors.New("server '" + serverNameOrID + " not found in servers")
 	}
 
-	serverName := server.HostName
-
 	cdn, err := GetCDN(cfg, tc.CDNName(server.CDNName))
 	if err != nil {
-		return "", errors.New("getting cdn '" + string(server.CDNName) + "': " + err.Error())
+		return remapConfigData{}, errors.New("getting cdn '" + string(server.CDNName) + "': " + err.Error())
 	}
 
 	serverCDNDomain := cdn.DomainName
 
 	toToolName, toURL, err := GetTOToolNameAndURLFromTO(cfg)
 	if err != nil {
-		return "", errors.New("getting global parameters: " + err.Error())
+		return remapConfigData{}, errors.New("getting global parameters: " + err.Error())
 	}
 
 	serverProfileParameters, err := GetServerProfileParameters(cfg, server.Profile)
 	if err != nil {
-		return "", errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
+		return remapConfigData{}, errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
 	}
 
 	atsVersionParam := ""
@@ -92,12 +128,12 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	atsMajorVer, err := atscfg.GetATSMajorVersionFromATSVersion(atsVersionParam)
 	if err != nil {
-		return "", errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
+		return remapConfigData{}, errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
 	}
 
 	deliveryServices, err := GetCDNDeliveryServices(cfg, server.CDNID)
 	if err != nil {
-		return "", errors.New("getting delivery services: " + err.Error())
+		return remapConfigData{}, errors.New("getting delivery services: " + err.Error())
 	}
 
 	dsIDs := []int{}
@@ -119,7 +155,7 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	dsServers, err := GetDeliveryServiceServers(cfg, dsIDs, serverIDs)
 	if err != nil {
-		return "", errors.New("getting parent.config cachegroup parent server delivery service servers: " + err.Error())
+		return remapConfigData{}, errors.New("getting parent.config cachegroup parent server delivery service servers: " + err.Error())
 	}
 
 	dssMap := map[int]map[int]struct{}{} // set of map[dsID][serverID]
@@ -133,6 +169,15 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 		dssMap[*dss.DeliveryService][*dss.Server] = struct{}{}
 	}
 
+	topologies, err := GetTopologies(cfg)
+	if err != nil {
+		return remapConfigData{}, errors.New("getting topologies: " + err.Error())
+	}
+	topologyMap := map[string]tc.Topology{}
+	for _, topology := range topologies {
+		topologyMap[topology.Name] = topology
+	}
+
 	useInactive := false
 	if !isMid {
 		// mids get inactive DSes, edges don't. This is how it's always behaved, not necessarily how it should.
@@ -147,7 +192,17 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 		if ds.Active == nil {
 			continue // TODO log?
 		}
-		if _, ok := dssMap[*ds.ID]; !ok {
+		if ds.Topology != nil && *ds.Topology != "" {
+			topology, ok := topologyMap[*ds.Topology]
+			if !ok {
+				log.Errorln("ds id " + strconv.Itoa(*ds.ID) + " topology '" + *ds.Topology + "' not found! Skipping!")
+				continue
+			}
+			_, assigned := dssMap[*ds.ID][server.ID]
+			if !atscfg.TopologyIncludesServer(topology, server.Cachegroup, assigned) {
+				continue // skip DSes whose topology doesn't include this server
+			}
+		} else if _, ok := dssMap[*ds.ID]; !ok {
 			continue
 		}
 		if !useInactive && !*ds.Active {
@@ -158,7 +213,7 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	dsRegexes, err := GetDeliveryServiceRegexes(cfg)
 	if err != nil {
-		return "", errors.New("getting delivery service regexes: " + err.Error())
+		return remapConfigData{}, errors.New("getting delivery service regexes: " + err.Error())
 	}
 
 	dsRegexMap := map[tc.DeliveryServiceName][]tc.DeliveryServiceRegex{}
@@ -205,7 +260,7 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	serverProfileParams, err := GetProfileParameters(cfg, server.Profile)
 	if err != nil {
-		return "", errors.New("getting profile parameters from server (profile '" + server.Profile + ": " + err.Error())
+		return remapConfigData{}, errors.New("getting profile parameters from server (profile '" + server.Profile + ": " + err.Error())
 	}
 
 	serverPackageParamData := map[string]string{}
@@ -244,12 +299,12 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	cacheKeyParams, err := GetConfigFileParameters(cfg, atscfg.CacheKeyParameterConfigFile)
 	if err != nil {
-		return "", errors.New("getting cache key parameters: " + err.Error())
+		return remapConfigData{}, errors.New("getting cache key parameters: " + err.Error())
 	}
 
 	cacheKeyParamsWithProfiles, err := TCParamsToParamsWithProfiles(cacheKeyParams)
 	if err != nil {
-		return "", errors.New("decoding cache key parameter profiles: " + err.Error())
+		return remapConfigData{}, errors.New("decoding cache key parameter profiles: " + err.Error())
 	}
 
 	cacheKeyParamsWithProfilesMap := ParameterWithProfilesToMap(cacheKeyParamsWithProfiles)
@@ -284,20 +339,20 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 
 	cacheGroups, err := GetCacheGroups(cfg)
 	if err != nil {
-		return "", errors.New("getting cachegroups: " + err.Error())
+		return remapConfigData{}, errors.New("getting cachegroups: " + err.Error())
 	}
 
 	cgMap := map[string]tc.CacheGroupNullable{}
 	for _, cg := range cacheGroups {
 		if cg.Name == nil {
-			return "", errors.New("got cachegroup with nil name!'")
+			return remapConfigData{}, errors.New("got cachegroup with nil name!'")
 		}
 		cgMap[*cg.Name] = cg
 	}
 
 	serverCG, ok := cgMap[server.Cachegroup]
 	if !ok {
-		return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' not found in CacheGroups")
+		return remapConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' not found in CacheGroups")
 	}
 
 	parentCGID := -1
@@ -305,15 +360,15 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 	if serverCG.ParentName != nil && *serverCG.ParentName != "" {
 		parentCG, ok := cgMap[*serverCG.ParentName]
 		if !ok {
-			return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' parent '" + *serverCG.ParentName + "' not found in CacheGroups")
+			return remapConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' parent '" + *serverCG.ParentName + "' not found in CacheGroups")
 		}
 		if parentCG.ID == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
+			return remapConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
 		}
 		parentCGID = *parentCG.ID
 
 		if parentCG.Type == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
+			return remapConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
 		}
 		parentCGType = *parentCG.Type
 	}
@@ -323,15 +378,15 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 	if serverCG.SecondaryParentName != nil && *serverCG.SecondaryParentName != "" {
 		parentCG, ok := cgMap[*serverCG.SecondaryParentName]
 		if !ok {
-			return "", errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' secondary parent '" + *serverCG.SecondaryParentName + "' not found in CacheGroups")
+			return remapConfigData{}, errors.New("server '" + serverNameOrID + "' cachegroup '" + server.Cachegroup + "' secondary parent '" + *serverCG.SecondaryParentName + "' not found in CacheGroups")
 		}
 
 		if parentCG.ID == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
+			return remapConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil ID!'")
 		}
 		secondaryParentCGID = *parentCG.ID
 		if parentCG.Type == nil {
-			return "", errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
+			return remapConfigData{}, errors.New("got cachegroup '" + *parentCG.Name + "' with nil Type!'")
 		}
 
 		secondaryParentCGType = *parentCG.Type
@@ -356,8 +411,18 @@ func GetConfigFileServerRemapDotConfig(cfg TCCfg, serverNameOrID string) (string
 		Type:                          server.Type,
 	}
 
-	txt := atscfg.MakeRemapDotConfig(tc.CacheName(serverName), toToolName, toURL, atsMajorVer, cacheURLParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapConfigDSData)
-	return txt, nil
+	return remapConfigData{
+		ServerInfo:                     serverInfo,
+		ATSMajorVer:                    atsMajorVer,
+		TOToolName:                     toToolName,
+		TOURL:                          toURL,
+		CacheURLParams:                 cacheURLParams,
+		DSProfilesCacheKeyConfigParams: dsProfilesCacheKeyConfigParams,
+		ServerPackageParamData:         serverPackageParamData,
+		RemapConfigDSData:              remapConfigDSData,
+		DeliveryServices:               filteredDSes,
+		HasStrategiesLocation:          atscfg.HasStrategiesLocation(serverProfileParameters),
+	}, nil
 }
 
 type DeliveryServiceRegexesSortByTypeThenSetNum []tc.DeliveryServiceRegex
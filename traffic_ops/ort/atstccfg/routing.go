@@ -186,9 +186,13 @@ func ProfileConfigFileFuncs() map[string]func(cfg TCCfg, serverNameOrID string)
 func ServerConfigFileFuncs() map[string]func(cfg TCCfg, serverNameOrID string) (string, error) {
 	return map[string]func(cfg TCCfg, serverNameOrID string) (string, error){
 		"parent.config":   GetConfigFileServerParentDotConfig,
+		"strategies.yaml": GetConfigFileServerStrategiesDotYAML,
+		"sni.yaml":        GetConfigFileServerSNIDotYAML,
+		"nginx_tc.conf":   GetConfigFileServerNginxDotConf,
 		"remap.config":    GetConfigFileServerRemapDotConfig,
 		"cache.config":    GetConfigFileServerCacheDotConfig,
 		"ip_allow.config": GetConfigFileServerIPAllowDotConfig,
+		"ip_allow.yaml":   GetConfigFileServerIPAllowDotYAML,
 		"hosting.config":  GetConfigFileServerHostingDotConfig,
 		"packages":        GetConfigFileServerPackages,
 		"chkconfig":       GetConfigFileServerChkconfig,
@@ -215,6 +219,18 @@ func GetConfigFileFromTrafficOps(cfg TCCfg) (string, int, error) {
 	if cfg.TOURL.RawQuery != "" {
 		path += "?" + cfg.TOURL.RawQuery
 	}
+	// files from Traffic Ops are kept in the memory cache with the objects, so they're included in bundles.
+	cacheName := "traffic_ops_file_" + path
+
+	if cfg.InputBundle != "" {
+		log.Infoln("GetConfigFile path '" + path + "' not generated locally, getting from input bundle")
+		body := ""
+		if err := GetCachedJSON(cfg, cacheName, &body, nil); err != nil {
+			return "", ExitCodeNotFound, errors.New("getting path '" + path + "': " + err.Error())
+		}
+		return body, ExitCodeSuccess, nil
+	}
+
 	log.Infoln("GetConfigFile path '" + path + "' not generated locally, requesting from Traffic Ops")
 	log.Infoln("GetConfigFile url '" + cfg.TOURL.String() + "'")
 
@@ -225,5 +241,9 @@ func GetConfigFileFromTrafficOps(cfg TCCfg) (string, int, error) {
 
 	WriteCookiesToFile(CookiesToString((*cfg.TOClient).Client.Jar.Cookies(cfg.TOURL)), cfg.TempDir)
 
-	return string(body), HTTPCodeToExitCode(code), nil
+	exitCode := HTTPCodeToExitCode(code)
+	if exitCode == ExitCodeSuccess {
+		cacheObjInMemory(cfg, cacheName, body)
+	}
+	return string(body), exitCode, nil
 }
@@ -0,0 +1,129 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func GetConfigFileServerSNIDotYAML(cfg TCCfg, serverNameOrID string) (string, error) {
+	servers, err := GetServers(cfg)
+	if err != nil {
+		return "", errors.New("getting servers: " + err.Error())
+	}
+
+	server := tc.Server{ID: atscfg.InvalidID}
+	if serverID, err := strconv.Atoi(serverNameOrID); err == nil {
+		for _, toServer := range servers {
+			if toServer.ID == serverID {
+				server = toServer
+				break
+			}
+		}
+	} else {
+		serverName := serverNameOrID
+		for _, toServer := range servers {
+			if toServer.HostName == serverName {
+				server = toServer
+				break
+			}
+		}
+	}
+	if server.ID == atscfg.InvalidID {
+		return "", errors.New("server '" + serverNameOrID + " not found in servers")
+	}
+
+	toToolName, toURL, err := GetTOToolNameAndURLFromTO(cfg)
+	if err != nil {
+		return "", errors.New("getting global parameters: " + err.Error())
+	}
+
+	serverProfileParameters, err := GetServerProfileParameters(cfg, server.Profile)
+	if err != nil {
+		return "", errors.New("getting server profile '" + server.Profile + "' parameters: " + err.Error())
+	}
+
+	atsVersionParam := ""
+	for _, param := range serverProfileParameters {
+		if param.ConfigFile != "package" || param.Name != "trafficserver" {
+			continue
+		}
+		atsVersionParam = param.Value
+		break
+	}
+	if atsVersionParam == "" {
+		atsVersionParam = atscfg.DefaultATSVersion
+	}
+
+	atsMajorVer, err := atscfg.GetATSMajorVersionFromATSVersion(atsVersionParam)
+	if err != nil {
+		return "", errors.New("getting ATS major version from version parameter (profile '" + server.Profile + "' configFile 'package' name 'trafficserver'): " + err.Error())
+	}
+
+	dses, err := GetCDNDeliveryServices(cfg, server.CDNID)
+	if err != nil {
+		return "", errors.New("getting delivery services: " + err.Error())
+	}
+
+	sniParams, err := GetConfigFileParameters(cfg, atscfg.SNIParameterConfigFile)
+	if err != nil {
+		return "", errors.New("getting sni.yaml parameters: " + err.Error())
+	}
+
+	sniParamsWithProfiles, err := TCParamsToParamsWithProfiles(sniParams)
+	if err != nil {
+		return "", errors.New("decoding sni.yaml parameter profiles: " + err.Error())
+	}
+
+	sniParamsWithProfilesMap := ParameterWithProfilesToMap(sniParamsWithProfiles)
+
+	dsProfileNamesToIDs := map[string]int{}
+	for _, ds := range dses {
+		if ds.ProfileID == nil || ds.ProfileName == nil {
+			continue // DSes without profiles have no TLS policy
+		}
+		dsProfileNamesToIDs[*ds.ProfileName] = *ds.ProfileID
+	}
+
+	dsProfilesSNIParams := map[int]map[string]string{}
+	for _, param := range sniParamsWithProfilesMap {
+		for dsProfileName, dsProfileID := range dsProfileNamesToIDs {
+			if _, ok := param.ProfileNames[dsProfileName]; !ok {
+				continue
+			}
+			if _, ok := dsProfilesSNIParams[dsProfileID]; !ok {
+				dsProfilesSNIParams[dsProfileID] = map[string]string{}
+			}
+			if existingVal, ok := dsProfilesSNIParams[dsProfileID][param.Name]; ok {
+				log.Warnln("generating sni.yaml: delivery service profile '" + dsProfileName + "' has multiple parameters for '" + param.Name + "' - using '" + existingVal + "' and ignoring the rest!")
+				continue
+			}
+			dsProfilesSNIParams[dsProfileID][param.Name] = param.Value
+		}
+	}
+
+	sniDSes := atscfg.DeliveryServicesToSNIDSes(dses, dsProfilesSNIParams)
+	return atscfg.MakeSNIDotYAML(tc.CacheName(server.HostName), toToolName, toURL, atsMajorVer, sniDSes), nil
+}
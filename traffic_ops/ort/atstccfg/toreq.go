@@ -172,6 +172,23 @@ func GetCacheGroups(cfg TCCfg) ([]tc.CacheGroupNullable, error) {
 	return cacheGroups, nil
 }
 
+func GetTopologies(cfg TCCfg) ([]tc.Topology, error) {
+	topologies := []tc.Topology{}
+	err := GetCachedJSON(cfg, "topologies.json", &topologies, func(obj interface{}) error {
+		toTopologies, reqInf, err := (*cfg.TOClient).GetTopologies()
+		if err != nil {
+			return errors.New("getting topologies from Traffic Ops '" + MaybeIPStr(reqInf) + "': " + err.Error())
+		}
+		topologies := obj.(*[]tc.Topology)
+		*topologies = toTopologies
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("getting topologies: " + err.Error())
+	}
+	return topologies, nil
+}
+
 func GetDeliveryServiceServers(cfg TCCfg, dsIDs []int, serverIDs []int) ([]tc.DeliveryServiceServer, error) {
 	sortIDsInHash := true
 	serverIDsStr := ""
@@ -38,6 +38,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbreplica"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
 
 	influx "github.com/influxdata/influxdb/client/v2"
@@ -48,6 +49,7 @@ import (
 const DBContextKey = "db"
 const ConfigContextKey = "context"
 const ReqIDContextKey = "reqid"
+const ReplicasContextKey = "replicas"
 const APIRespWrittenKey = "respwritten"
 
 const influxServersQuery = `
@@ -304,6 +306,8 @@ type APIInfo struct {
 	Version   *Version
 	Tx        *sqlx.Tx
 	Config    *config.Config
+	// resultCount is the total number of results of a Read which was paginated in the database, set by CountResults.
+	resultCount *int
 }
 
 // Creates a deprecation warning for an endpoint, with a proposed alternative.
@@ -344,6 +348,16 @@ func DeprecationWarning(alternative string) tc.Alert {
 //  }
 //
 func NewInfo(r *http.Request, requiredParams []string, intParamNames []string) (*APIInfo, error, error, int) {
+	return newInfo(r, requiredParams, intParamNames, false)
+}
+
+// NewReadInfo is like NewInfo, but its transaction is read-only, and uses a read-only replica of the database if one is available, rather than the primary.
+// It must only be used by handlers which don't write, and whose clients can tolerate data which lags the primary by up to the configured maximum replica lag. Users who have written recently always read from the primary.
+func NewReadInfo(r *http.Request, requiredParams []string, intParamNames []string) (*APIInfo, error, error, int) {
+	return newInfo(r, requiredParams, intParamNames, true)
+}
+
+func newInfo(r *http.Request, requiredParams []string, intParamNames []string, readOnly bool) (*APIInfo, error, error, int) {
 	db, err := GetDB(r.Context())
 	if err != nil {
 		return &APIInfo{Tx: &sqlx.Tx{}}, errors.New("getting db: " + err.Error()), nil, http.StatusInternalServerError
@@ -366,8 +380,15 @@ func NewInfo(r *http.Request, requiredParams []string, intParamNames []string) (
 	if userErr != nil || sysErr != nil {
 		return &APIInfo{Tx: &sqlx.Tx{}}, userErr, sysErr, errCode
 	}
+	txOpts := (*sql.TxOptions)(nil)
+	if readOnly {
+		txOpts = &sql.TxOptions{ReadOnly: true}
+		if replica := GetReplicas(r.Context()).DB(user.UserName); replica != nil {
+			db = replica
+		}
+	}
 	dbCtx, _ := context.WithTimeout(r.Context(), time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second) //only place we could call cancel here is in APIInfo.Close(), which already will rollback the transaction (which is all cancel will do.)
-	tx, err := db.BeginTxx(dbCtx, nil)                                                                 // must be last, MUST not return an error if this succeeds, without closing the tx
+	tx, err := db.BeginTxx(dbCtx, txOpts)                                                              // must be last, MUST not return an error if this succeeds, without closing the tx
 	if err != nil {
 		return &APIInfo{Tx: &sqlx.Tx{}}, userErr, errors.New("could not begin transaction: " + err.Error()), http.StatusInternalServerError
 	}
@@ -542,6 +563,12 @@ func GetDB(ctx context.Context) (*sqlx.DB, error) {
 	return nil, errors.New("No db found in Context")
 }
 
+// GetReplicas returns the read-only replicas of the database from the context, or nil if there are none.
+func GetReplicas(ctx context.Context) *dbreplica.Pool {
+	replicas, _ := ctx.Value(ReplicasContextKey).(*dbreplica.Pool)
+	return replicas
+}
+
 func GetConfig(ctx context.Context) (*config.Config, error) {
 	val := ctx.Value(ConfigContextKey)
 	if val != nil {
@@ -700,6 +727,10 @@ func ParseDBError(ierr error) (error, error, int) {
 // GetUserFromReq returns the current user, any user error, any system error, and an error code to be returned if either error was not nil.
 // This also uses the given ResponseWriter to refresh the cookie, if it was valid.
 func GetUserFromReq(w http.ResponseWriter, r *http.Request, secret string) (auth.CurrentUser, error, error, int) {
+	if authz := r.Header.Get("Authorization"); strings.HasPrefix(authz, bearerPrefix) {
+		return getUserFromAPIToken(r, strings.TrimSpace(authz[len(bearerPrefix):]))
+	}
+
 	cookie, err := r.Cookie(tocookie.Name)
 	if err != nil {
 		return auth.CurrentUser{}, errors.New("Unauthorized, please log in."), errors.New("error getting cookie: " + err.Error()), http.StatusUnauthorized
@@ -718,32 +749,56 @@ func GetUserFromReq(w http.ResponseWriter, r *http.Request, secret string) (auth
 	if username == "" {
 		return auth.CurrentUser{}, errors.New("Unauthorized, please log in."), nil, http.StatusUnauthorized
 	}
+	db, cfg, err := getReqDBAndConfig(r)
+	if err != nil {
+		return auth.CurrentUser{}, nil, err, http.StatusInternalServerError
+	}
+
+	user, userErr, sysErr, code := auth.GetCurrentUserFromDB(db, username, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+	if userErr != nil || sysErr != nil {
+		return auth.CurrentUser{}, userErr, sysErr, code
+	}
+
+	duration := tocookie.DefaultDuration
+	newCookie := tocookie.GetCookie(oldCookie.AuthData, duration, secret)
+	http.SetCookie(w, newCookie)
+	return user, nil, nil, http.StatusOK
+}
+
+// bearerPrefix is the prefix of the Authorization header of requests authenticated with an API token.
+const bearerPrefix = "Bearer "
+
+// getUserFromAPIToken returns the user of the given API token. Unlike cookies, tokens aren't refreshed.
+func getUserFromAPIToken(r *http.Request, token string) (auth.CurrentUser, error, error, int) {
+	if token == "" {
+		return auth.CurrentUser{}, errors.New("Unauthorized, missing API token."), nil, http.StatusUnauthorized
+	}
+	db, cfg, err := getReqDBAndConfig(r)
+	if err != nil {
+		return auth.CurrentUser{}, nil, err, http.StatusInternalServerError
+	}
+	return auth.GetCurrentUserFromAPIToken(db, token, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+}
+
+// getReqDBAndConfig returns the database and config from the request context.
+func getReqDBAndConfig(r *http.Request) (*sqlx.DB, *config.Config, error) {
 	db := (*sqlx.DB)(nil)
 	val := r.Context().Value(DBContextKey)
 	if val == nil {
-		return auth.CurrentUser{}, nil, errors.New("request context db missing"), http.StatusInternalServerError
+		return nil, nil, errors.New("request context db missing")
 	}
 	switch v := val.(type) {
 	case *sqlx.DB:
 		db = v
 	default:
-		return auth.CurrentUser{}, nil, fmt.Errorf("request context db unknown type %T", val), http.StatusInternalServerError
+		return nil, nil, fmt.Errorf("request context db unknown type %T", val)
 	}
 
 	cfg, err := GetConfig(r.Context())
 	if err != nil {
-		return auth.CurrentUser{}, nil, errors.New("request context config missing"), http.StatusInternalServerError
+		return nil, nil, errors.New("request context config missing")
 	}
-
-	user, userErr, sysErr, code := auth.GetCurrentUserFromDB(db, username, time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
-	if userErr != nil || sysErr != nil {
-		return auth.CurrentUser{}, userErr, sysErr, code
-	}
-
-	duration := tocookie.DefaultDuration
-	newCookie := tocookie.GetCookie(oldCookie.AuthData, duration, secret)
-	http.SetCookie(w, newCookie)
-	return user, nil, nil, http.StatusOK
+	return db, cfg, nil
 }
 
 func AddUserToReq(r *http.Request, u auth.CurrentUser) {
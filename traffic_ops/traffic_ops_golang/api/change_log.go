@@ -80,14 +80,81 @@ func CreateChangeLogBuildMsg(level string, action string, user *auth.CurrentUser
 }
 
 func CreateChangeLogRawErr(level string, msg string, user *auth.CurrentUser, tx *sql.Tx) error {
-	if _, err := tx.Exec(`INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`, level, msg, user.ID); err != nil {
+	if _, err := tx.Exec(insertChangeLogQuery, level, msg, user.ID, changeLogTokenName(user)); err != nil {
 		return errors.New("Inserting change log level '" + level + "' message '" + msg + "' user '" + user.UserName + "': " + err.Error())
 	}
 	return nil
 }
 
+// CreateChangeLogRawID creates a change log entry like CreateChangeLogRawErr, and returns its ID, so other objects can reference it.
+func CreateChangeLogRawID(level string, msg string, user *auth.CurrentUser, tx *sql.Tx) (int, error) {
+	id := 0
+	if err := tx.QueryRow(insertChangeLogReturningIDQuery, level, msg, user.ID, changeLogTokenName(user)).Scan(&id); err != nil {
+		return 0, errors.New("Inserting change log level '" + level + "' message '" + msg + "' user '" + user.UserName + "': " + err.Error())
+	}
+	return id, nil
+}
+
+// changeLogTokenName returns the name of the API token the user authenticated with, or nil if they didn't use one.
+func changeLogTokenName(user *auth.CurrentUser) *string {
+	if user.Token == nil {
+		return nil
+	}
+	return &user.Token.Name
+}
+
 func CreateChangeLogRawTx(level string, msg string, user *auth.CurrentUser, tx *sql.Tx) {
-	if _, err := tx.Exec(`INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`, level, msg, user.ID); err != nil {
+	if _, err := tx.Exec(insertChangeLogQuery, level, msg, user.ID, changeLogTokenName(user)); err != nil {
 		log.Errorln("Inserting change log level '" + level + "' message '" + msg + "' user '" + user.UserName + "': " + err.Error())
 	}
 }
+
+// ChangeLogObjectTypeSQL is the SQL expression for the type of object the change log entry aliased 'l' is about, lower-cased.
+// This is the type in messages built by CreateChangeLogBuildMsg, or else the upper-case prefix most other messages start with, e.g. 'CDN: ...'. It is NULL if the message has neither.
+const ChangeLogObjectTypeSQL = `lower(COALESCE(substring(l.message from ', ACTION: \S+ ([^,]+), keys: '), substring(l.message from '^([A-Z][A-Z0-9 _-]*):')))`
+
+// ChangeLogFilterSQL is the SQL condition for whether the change log entry aliased 'l' matches the filter aliased 'f', which has the nullable text columns object_type, cdn, and level.
+// The level and object type match case-insensitively. The CDN matches if the message contains the CDN name as a word.
+const ChangeLogFilterSQL = `(f.level IS NULL OR upper(l.level) = upper(f.level))
+AND (f.object_type IS NULL OR ` + ChangeLogObjectTypeSQL + ` = lower(f.object_type))
+AND (f.cdn IS NULL OR l.message ~ ('(^|[^A-Za-z0-9_-])' || replace(f.cdn, '.', '\.') || '($|[^A-Za-z0-9_-])'))`
+
+// ChangeLogUserSQL is the SQL expression for the user of the change log entry aliased 'l', whose user is aliased 'u'. Changes made with an API token are "user via token name".
+const ChangeLogUserSQL = `u.username || COALESCE(' via token ' || l.api_token, '')`
+
+// ChangeLogEventJSONSQL is the SQL expression for the tc.ChangeLogEvent JSON text of the change log entry aliased 'l', whose user is aliased 'u'.
+const ChangeLogEventJSONSQL = `json_build_object(
+'id', l.id,
+'level', l.level,
+'message', l.message,
+'user', ` + ChangeLogUserSQL + `,
+'ticketNum', l.ticketnum,
+'objectType', ` + ChangeLogObjectTypeSQL + `,
+'lastUpdated', to_char(l.last_updated AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS') || '+00'
+)::text`
+
+// insertChangeLogQuery inserts a change log entry, and queues its delivery to every active webhook whose filter it matches, in the same transaction, so entries are delivered if and only if they're committed.
+const insertChangeLogQuery = insertChangeLogCTE + `
+` + insertChangeLogDeliveryQuery + `
+`
+
+// insertChangeLogReturningIDQuery is insertChangeLogQuery, but returns the ID of the inserted change log entry.
+const insertChangeLogReturningIDQuery = insertChangeLogCTE + `,
+d AS (
+` + insertChangeLogDeliveryQuery + `
+)
+SELECT id FROM l
+`
+
+const insertChangeLogCTE = `
+WITH l AS (
+  INSERT INTO log (level, message, tm_user, api_token) VALUES ($1, $2, $3, $4)
+  RETURNING id, level, message, tm_user, api_token, ticketnum, last_updated
+)`
+
+const insertChangeLogDeliveryQuery = `INSERT INTO webhook_delivery (webhook, log, payload)
+SELECT f.id, l.id, ` + ChangeLogEventJSONSQL + `
+FROM l
+JOIN tm_user u ON u.id = l.tm_user
+JOIN webhook f ON f.active
+WHERE ` + ChangeLogFilterSQL
@@ -70,10 +70,59 @@ func TestCreateChangeLog(t *testing.T) {
 	expectedMessage := strings.ToUpper(i.GetType()) + ": " + i.GetAuditName() + ", ID: " + strconv.Itoa(keys["id"].(int)) + ", ACTION: " + Created + " " + i.GetType() + ", keys: { id:" + strconv.Itoa(keys["id"].(int)) + " }"
 
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1, nil).WillReturnResult(sqlmock.NewResult(1, 1))
 	user := auth.CurrentUser{ID: 1}
 	err = CreateChangeLog(ApiChange, Created, &i, &user, db.MustBegin().Tx)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestCreateChangeLogAPIToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, "CDN: cdn1, ID: 1, ACTION: Queued updates", 1, "deploy-bot").WillReturnResult(sqlmock.NewResult(1, 1))
+	user := auth.CurrentUser{ID: 1, Token: &auth.UserToken{ID: 2, Name: "deploy-bot"}}
+	if err := CreateChangeLogRawErr(ApiChange, "CDN: cdn1, ID: 1, ACTION: Queued updates", &user, db.MustBegin().Tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the API token name to be recorded: %v", err)
+	}
+}
+
+func TestCreateChangeLogRawID(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO log").WithArgs(ApiChange, "DSR: ds1, ID: 3, ACTION: Applied", 1, nil).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	user := auth.CurrentUser{ID: 1}
+	id, err := CreateChangeLogRawID(ApiChange, "DSR: ds1, ID: 3, ACTION: Applied", &user, db.MustBegin().Tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("expected change log entry ID 42, actual %d", id)
+	}
+	if !strings.Contains(insertChangeLogReturningIDQuery, "INSERT INTO webhook_delivery") {
+		t.Error("expected the change log entry to be queued for webhook delivery")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+// ConfigFileHeaderCommentMarker is the text of the header comment atscfg adds to generated config files.
+// The comment includes the time the file was generated, so lines containing it are excluded from config file ETags.
+const ConfigFileHeaderCommentMarker = "DO NOT EDIT - Generated for "
+
+// WriteRespLastModified is like WriteResp, but supports conditional requests.
+// The response has an ETag computed from the response body, and if lastModified is not zero, a Last-Modified of lastModified.
+// If the request's If-None-Match matches the ETag, or the request has no If-None-Match and the response has not been modified since its If-Modified-Since, a 304 Not Modified is written instead of the body.
+func WriteRespLastModified(w http.ResponseWriter, r *http.Request, v interface{}, lastModified time.Time) {
+	WriteRespValsLastModified(w, r, v, nil, lastModified)
+}
+
+// WriteRespValsLastModified is like WriteRespLastModified, but also writes the given vals at the root of the response object, like WriteRespVals. The vals may be nil.
+func WriteRespValsLastModified(w http.ResponseWriter, r *http.Request, v interface{}, vals map[string]interface{}, lastModified time.Time) {
+	if respWritten(r) {
+		log.Errorf("WriteRespValsLastModified called after a write already occurred! Not double-writing! Path %s", r.URL.Path)
+		return
+	}
+	setRespWritten(r)
+
+	resp := map[string]interface{}{}
+	for key, val := range vals {
+		resp[key] = val
+	}
+	resp["response"] = v
+	bts, err := json.Marshal(resp)
+	if err != nil {
+		log.Errorf("marshalling JSON for %T: %v", v, err)
+		tc.GetHandleErrorsFunc(w, r)(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+	bts = append(bts, '\n')
+	if writeNotModified(w, r, rfc.ETag(bts), lastModified) {
+		return
+	}
+	w.Header().Set(tc.ContentType, tc.ApplicationJson)
+	w.Write(bts)
+}
+
+// WriteRespText writes the given text with the given Content-Type, supporting conditional requests with If-None-Match.
+// This should be used for generated config files. The ETag excludes the generated header comment, whose timestamp changes on every request.
+func WriteRespText(w http.ResponseWriter, r *http.Request, contentType string, text string) {
+	if respWritten(r) {
+		log.Errorf("WriteRespText called after a write already occurred! Not double-writing! Path %s", r.URL.Path)
+		return
+	}
+	setRespWritten(r)
+
+	if writeNotModified(w, r, ConfigFileETag(text), time.Time{}) {
+		return
+	}
+	if contentType != "" {
+		w.Header().Set(tc.ContentType, contentType)
+	}
+	w.Write([]byte(text))
+}
+
+// ConfigFileETag returns the ETag of the given config file text, excluding the generated header comment.
+func ConfigFileETag(text string) string {
+	if !strings.Contains(text, ConfigFileHeaderCommentMarker) {
+		return rfc.ETag([]byte(text))
+	}
+	lines := strings.Split(text, "\n")
+	etagLines := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.Contains(line, ConfigFileHeaderCommentMarker) {
+			continue
+		}
+		etagLines = append(etagLines, line)
+	}
+	return rfc.ETag([]byte(strings.Join(etagLines, "\n")))
+}
+
+// writeNotModified sets the ETag and Last-Modified headers, and writes a 304 Not Modified if the request's conditional headers match. Returns whether a 304 was written.
+// The lastModified may be zero, in which case no Last-Modified header is set and If-Modified-Since is ignored.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set(rfc.ETagHeader, etag)
+	if !lastModified.IsZero() {
+		w.Header().Set(rfc.LastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if !notModified(r, etag, lastModified) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// notModified returns whether the request's conditional headers indicate the client already has the representation with the given etag and lastModified.
+// Per RFC7232§6, If-Modified-Since is only evaluated if the request has no If-None-Match.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get(rfc.IfNoneMatch); inm != "" {
+		return rfc.ETagMatches(inm, etag)
+	}
+	ims := r.Header.Get(rfc.IfModifiedSince)
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	imsTime, err := http.ParseTime(ims)
+	if err != nil {
+		return false // per RFC7232§3.3, an invalid date is ignored
+	}
+	return !lastModified.Truncate(time.Second).After(imsTime)
+}
+
+// LastUpdated returns the latest LastUpdated field of the given objects, and whether any object had a LastUpdated.
+// Objects may be structs or pointers to structs, with a LastUpdated field of type tc.TimeNoMod, tc.Time, or time.Time, or a pointer to one of them. Objects without such a field are ignored.
+func LastUpdated(objs []interface{}) (time.Time, bool) {
+	max := time.Time{}
+	found := false
+	for _, obj := range objs {
+		val := reflect.ValueOf(obj)
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			if val.IsNil() {
+				break
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			continue
+		}
+		field := val.FieldByName("LastUpdated")
+		if !field.IsValid() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		t := time.Time{}
+		switch v := field.Interface().(type) {
+		case tc.TimeNoMod:
+			t = v.Time
+		case tc.Time:
+			t = v.Time
+		case time.Time:
+			t = v
+		default:
+			continue
+		}
+		found = true
+		if t.After(max) {
+			max = t
+		}
+	}
+	return max, found
+}
+
+// GetLastChangeLogTime returns the time of the latest change log entry, or the zero time if there are none.
+func GetLastChangeLogTime(tx *sql.Tx) (time.Time, error) {
+	t := time.Time{}
+	if err := tx.QueryRow(`SELECT last_updated FROM log ORDER BY id DESC LIMIT 1`).Scan(&t); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.New("querying last change log time: " + err.Error())
+	}
+	return t, nil
+}
+
+// ReadLastModified returns the Last-Modified time for the given Read results: the latest LastUpdated of the results, or the latest change log entry, whichever is later.
+// The change log is included because deleted rows can't be seen, and every deletion via the API is logged, so a deletion is never reported as Not Modified.
+// Returns the zero time if the results have no LastUpdated, in which case only the ETag is used for conditional requests.
+func ReadLastModified(tx *sql.Tx, results []interface{}) (time.Time, error) {
+	lastUpdated, ok := LastUpdated(results)
+	if !ok && len(results) > 0 {
+		return time.Time{}, nil
+	}
+	lastChange, err := GetLastChangeLogTime(tx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lastChange.After(lastUpdated) {
+		return lastChange, nil
+	}
+	return lastUpdated, nil
+}
@@ -0,0 +1,189 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-rfc"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestLastUpdated(t *testing.T) {
+	t1 := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+	type embedded struct {
+		LastUpdated *tc.TimeNoMod
+	}
+	type outer struct {
+		embedded
+	}
+	objs := []interface{}{
+		tc.CDNNullable{LastUpdated: &tc.TimeNoMod{Time: t1}},
+		&outer{embedded{LastUpdated: &tc.TimeNoMod{Time: t2}}},
+		tc.CDNNullable{},
+		struct{ ID int }{1},
+	}
+	actual, ok := LastUpdated(objs)
+	if !ok {
+		t.Fatal("LastUpdated expected found, actual not found")
+	}
+	if !actual.Equal(t2) {
+		t.Errorf("LastUpdated expected %v, actual %v", t2, actual)
+	}
+
+	if _, ok := LastUpdated([]interface{}{struct{ ID int }{1}}); ok {
+		t.Error("LastUpdated of objects without LastUpdated expected not found, actual found")
+	}
+}
+
+func TestReadLastModified(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	rowTime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	logTime := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT last_updated FROM log").WillReturnRows(sqlmock.NewRows([]string{"last_updated"}).AddRow(logTime))
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	actual, err := ReadLastModified(tx, []interface{}{tc.CDNNullable{LastUpdated: &tc.TimeNoMod{Time: rowTime}}})
+	if err != nil {
+		t.Fatalf("ReadLastModified expected nil error, actual: %v", err)
+	}
+	if !actual.Equal(logTime) {
+		t.Errorf("ReadLastModified expected the later change log time %v, actual %v", logTime, actual)
+	}
+
+	actual, err = ReadLastModified(tx, []interface{}{struct{ ID int }{1}})
+	if err != nil {
+		t.Fatalf("ReadLastModified expected nil error, actual: %v", err)
+	}
+	if !actual.IsZero() {
+		t.Errorf("ReadLastModified of objects without LastUpdated expected zero time, actual %v", actual)
+	}
+	tx.Commit()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
+
+func TestWriteRespLastModified(t *testing.T) {
+	lastModified := time.Date(2019, 1, 1, 12, 0, 0, 500, time.UTC)
+	obj := []string{"foo"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	WriteRespLastModified(w, r, obj, lastModified)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected code %v, actual %v", http.StatusOK, w.Code)
+	}
+	etag := w.Header().Get(rfc.ETagHeader)
+	if etag == "" {
+		t.Fatal("expected ETag header, actual none")
+	}
+	if lm := w.Header().Get(rfc.LastModified); lm != lastModified.Format(http.TimeFormat) {
+		t.Errorf("expected Last-Modified '%v', actual '%v'", lastModified.Format(http.TimeFormat), lm)
+	}
+
+	tests := []struct {
+		Name     string
+		Header   string
+		Val      string
+		Expected int
+	}{
+		{"matching If-None-Match", rfc.IfNoneMatch, etag, http.StatusNotModified},
+		{"non-matching If-None-Match", rfc.IfNoneMatch, `"other"`, http.StatusOK},
+		{"If-Modified-Since equal", rfc.IfModifiedSince, lastModified.Format(http.TimeFormat), http.StatusNotModified},
+		{"If-Modified-Since later", rfc.IfModifiedSince, lastModified.Add(time.Hour).Format(http.TimeFormat), http.StatusNotModified},
+		{"If-Modified-Since earlier", rfc.IfModifiedSince, lastModified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+		{"invalid If-Modified-Since", rfc.IfModifiedSince, "yesterday", http.StatusOK},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(test.Header, test.Val)
+		WriteRespLastModified(w, r, obj, lastModified)
+		if w.Code != test.Expected {
+			t.Errorf("%v: expected code %v, actual %v", test.Name, test.Expected, w.Code)
+		}
+		if test.Expected == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("%v: expected empty body, actual '%v'", test.Name, w.Body.String())
+		}
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, `"other"`)
+	r.Header.Set(rfc.IfModifiedSince, lastModified.Add(time.Hour).Format(http.TimeFormat))
+	WriteRespLastModified(w, r, obj, lastModified)
+	if w.Code != http.StatusOK {
+		t.Errorf("non-matching If-None-Match with later If-Modified-Since: expected code %v, actual %v", http.StatusOK, w.Code)
+	}
+}
+
+func TestWriteRespText(t *testing.T) {
+	textA := "# DO NOT EDIT - Generated for cdn1 by Traffic Ops (https://to.example) on Tue Jan 1 00:00:00 UTC 2019\nfoo bar\n"
+	textB := "# DO NOT EDIT - Generated for cdn1 by Traffic Ops (https://to.example) on Wed Jan 2 00:00:00 UTC 2019\nfoo bar\n"
+	textC := "# DO NOT EDIT - Generated for cdn1 by Traffic Ops (https://to.example) on Wed Jan 2 00:00:00 UTC 2019\nfoo baz\n"
+	if ConfigFileETag(textA) != ConfigFileETag(textB) {
+		t.Error("expected config file ETags differing only in header comment to be equal")
+	}
+	if ConfigFileETag(textA) == ConfigFileETag(textC) {
+		t.Error("expected config file ETags with different contents to differ")
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, ConfigFileETag(textA))
+	WriteRespText(w, r, tc.ContentTypeTextPlain, textB)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected code %v, actual %v", http.StatusNotModified, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(rfc.IfNoneMatch, ConfigFileETag(textA))
+	WriteRespText(w, r, tc.ContentTypeTextPlain, textC)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected code %v, actual %v", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != textC {
+		t.Errorf("expected body '%v', actual '%v'", textC, w.Body.String())
+	}
+	if ct := w.Header().Get(tc.ContentType); ct != tc.ContentTypeTextPlain {
+		t.Errorf("expected Content-Type '%v', actual '%v'", tc.ContentTypeTextPlain, ct)
+	}
+}
@@ -45,6 +45,12 @@ type GenericReader interface {
 	SelectQuery() string
 }
 
+// GenericCursorReader is a GenericReader whose results' IDs aren't the "id" column of its ParamColumns, because its "id" parameter filters by another column. GenericRead uses its CursorColumn for cursor pagination.
+type GenericCursorReader interface {
+	GenericReader
+	CursorColumn() string
+}
+
 type GenericUpdater interface {
 	GetType() string
 	APIInfo() *APIInfo
@@ -111,11 +117,19 @@ func GenericCreateNameBasedID(val GenericCreator) (error, error, int) {
 }
 
 func GenericRead(val GenericReader) ([]interface{}, error, error, int) {
-	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(val.APIInfo().Params, val.ParamColumns())
+	cursorColumn := val.ParamColumns()["id"].Column
+	if cursorReader, ok := val.(GenericCursorReader); ok {
+		cursorColumn = cursorReader.CursorColumn()
+	}
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndCursorPagination(val.APIInfo().Params, val.ParamColumns(), cursorColumn)
 	if len(errs) > 0 {
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
+	if err := val.APIInfo().CountResults(val.SelectQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting " + val.GetType() + ": " + err.Error()), http.StatusInternalServerError
+	}
+
 	query := val.SelectQuery() + where + orderBy + pagination
 	rows, err := val.APIInfo().Tx.NamedQuery(query, queryValues)
 	if err != nil {
@@ -0,0 +1,252 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+const (
+	LimitQueryParam   = "limit"
+	OffsetQueryParam  = "offset"
+	PageQueryParam    = "page"
+	CursorQueryParam  = "cursor"
+	OrderByQueryParam = "orderby"
+)
+
+// Summary is the "summary" object of paginated responses.
+type Summary struct {
+	Count int `json:"count"`
+}
+
+// Pagination is the requested page of a Read.
+//
+// With Cursor, results are ordered by ID, and the page is the Limit results with IDs greater than AfterID. Because the cursor is the last ID seen rather than a position, rows created or deleted between requests don't cause results to be skipped or repeated.
+// Without Cursor, the page is the Limit results after the first Offset, in the order the Reader returned them.
+type Pagination struct {
+	Limit   int
+	Offset  int
+	Cursor  bool
+	AfterID int
+}
+
+// ParsePagination returns the pagination requested by the limit, offset, page, and cursor parameters, and whether pagination was requested at all.
+//
+// Pagination is only requested if limit is given; offset and page are ignored without it. Cursor pagination is only used if the cursor parameter is given; an empty cursor requests the first page.
+// Returns a user error if any parameter is invalid.
+func ParsePagination(params map[string]string) (Pagination, bool, error) {
+	limitStr, ok := params[LimitQueryParam]
+	if !ok {
+		if _, ok := params[CursorQueryParam]; ok {
+			return Pagination{}, false, errors.New("cursor parameter requires a limit")
+		}
+		return Pagination{}, false, nil
+	}
+	p := Pagination{}
+	err := error(nil)
+	if p.Limit, err = strconv.Atoi(limitStr); err != nil || p.Limit < 1 {
+		return Pagination{}, false, errors.New("limit parameter must be a positive integer")
+	}
+
+	if cursorStr, ok := params[CursorQueryParam]; ok {
+		if _, ok := params[OrderByQueryParam]; ok {
+			return Pagination{}, false, errors.New("cursor parameter cannot be used with orderby, cursor pagination is always ordered by id")
+		}
+		if _, ok := params[OffsetQueryParam]; ok {
+			return Pagination{}, false, errors.New("cursor parameter cannot be used with offset")
+		}
+		if _, ok := params[PageQueryParam]; ok {
+			return Pagination{}, false, errors.New("cursor parameter cannot be used with page")
+		}
+		p.Cursor = true
+		if cursorStr != "" {
+			if p.AfterID, err = dbhelpers.DecodeCursor(cursorStr); err != nil {
+				return Pagination{}, false, err
+			}
+		}
+		return p, true, nil
+	}
+
+	if offsetStr, ok := params[OffsetQueryParam]; ok {
+		if p.Offset, err = strconv.Atoi(offsetStr); err != nil || p.Offset < 1 {
+			return Pagination{}, false, errors.New("offset parameter must be a positive integer")
+		}
+	} else if pageStr, ok := params[PageQueryParam]; ok {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return Pagination{}, false, errors.New("page parameter must be a positive integer")
+		}
+		p.Offset = (page - 1) * p.Limit
+	}
+	return p, true, nil
+}
+
+// WithoutPaginationParams returns a copy of params without the pagination parameters, for Readers which can't paginate in the database, so ReadHandler paginates all of their results instead.
+func WithoutPaginationParams(params map[string]string) map[string]string {
+	withoutPagination := make(map[string]string, len(params))
+	for key, val := range params {
+		withoutPagination[key] = val
+	}
+	delete(withoutPagination, LimitQueryParam)
+	delete(withoutPagination, OffsetQueryParam)
+	delete(withoutPagination, PageQueryParam)
+	delete(withoutPagination, CursorQueryParam)
+	return withoutPagination
+}
+
+// CountResults counts the results of a paginated read, for the summary of the response. The query is the Reader's query with the WHERE clause and queryValues from dbhelpers.BuildWhereAndOrderByAndPagination, without its ORDER BY or pagination clauses.
+// Readers which paginate in the database with dbhelpers.BuildWhereAndOrderByAndPagination must call this, so ReadHandler knows the results are already paginated. It does nothing if no limit was requested.
+func (inf *APIInfo) CountResults(query string, queryValues map[string]interface{}) error {
+	if _, ok := inf.Params[LimitQueryParam]; !ok {
+		return nil
+	}
+	count, err := dbhelpers.Count(inf.Tx, query, queryValues)
+	if err != nil {
+		return err
+	}
+	inf.resultCount = &count
+	return nil
+}
+
+// PaginatedPage returns the page of results read by a Reader which paginated in the database, and the pagination of the next page, or nil if this is the last page.
+// The count is the total number of results, from CountResults. With cursor pagination, the Reader read one result more than the limit if there is a next page, which is removed.
+func PaginatedPage(p Pagination, count int, results []interface{}) ([]interface{}, *Pagination, error) {
+	if p.Cursor {
+		if len(results) <= p.Limit {
+			return results, nil, nil
+		}
+		page := results[:p.Limit]
+		ids, ok := resultIDs(page)
+		if !ok {
+			return nil, nil, errors.New("cursor paginated results have no ID")
+		}
+		next := p
+		next.AfterID = ids[len(ids)-1]
+		return page, &next, nil
+	}
+	if p.Offset+len(results) >= count {
+		return results, nil, nil
+	}
+	next := p
+	next.Offset = p.Offset + p.Limit
+	return results, &next, nil
+}
+
+// Paginate returns the requested page of results, and the pagination of the next page, or nil if this is the last page. It's used for Readers which don't paginate in the database, and return every matching result.
+// Returns a user error if cursor pagination was requested, but the results have no ID field.
+func Paginate(p Pagination, results []interface{}) ([]interface{}, *Pagination, error) {
+	if p.Cursor {
+		ids, ok := resultIDs(results)
+		if !ok {
+			return nil, nil, errors.New("cursor pagination is not supported by this endpoint")
+		}
+		return paginateCursor(p, results, ids)
+	}
+
+	if p.Offset >= len(results) {
+		return []interface{}{}, nil, nil
+	}
+	end := p.Offset + p.Limit
+	if end >= len(results) {
+		return results[p.Offset:], nil, nil
+	}
+	next := p
+	next.Offset = end
+	return results[p.Offset:end], &next, nil
+}
+
+func paginateCursor(p Pagination, results []interface{}, ids []int) ([]interface{}, *Pagination, error) {
+	idxs := make([]int, len(results))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.SliceStable(idxs, func(i, j int) bool { return ids[idxs[i]] < ids[idxs[j]] })
+
+	page := []interface{}{}
+	for n, i := range idxs {
+		if ids[i] <= p.AfterID {
+			continue
+		}
+		if len(page) == p.Limit {
+			next := p
+			next.AfterID = ids[idxs[n-1]]
+			return page, &next, nil
+		}
+		page = append(page, results[i])
+	}
+	return page, nil, nil
+}
+
+// resultIDs returns the ID of each result, and whether every result had an ID.
+// Results may be structs or pointers to structs, with an ID field of an integer type, or a pointer to one. A nil ID is not an ID.
+func resultIDs(results []interface{}) ([]int, bool) {
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		val := reflect.ValueOf(result)
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			if val.IsNil() {
+				return nil, false
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field := val.FieldByName("ID")
+		if !field.IsValid() {
+			return nil, false
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return nil, false
+			}
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			ids = append(ids, int(field.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ids = append(ids, int(field.Uint()))
+		default:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// NextLink returns the path and query of the request for the next page, given the pagination of the next page.
+func NextLink(r *http.Request, next Pagination) string {
+	qry := r.URL.Query()
+	qry.Set(LimitQueryParam, strconv.Itoa(next.Limit))
+	if next.Cursor {
+		qry.Set(CursorQueryParam, dbhelpers.EncodeCursor(next.AfterID))
+	} else if qry.Get(OffsetQueryParam) != "" {
+		qry.Set(OffsetQueryParam, strconv.Itoa(next.Offset))
+	} else {
+		qry.Set(PageQueryParam, strconv.Itoa(next.Offset/next.Limit+1))
+	}
+	return r.URL.Path + "?" + qry.Encode()
+}
@@ -0,0 +1,251 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+type paginationTester struct {
+	ID   *int
+	Name string
+}
+
+func paginationResults(ids ...int) []interface{} {
+	results := []interface{}{}
+	for _, id := range ids {
+		id := id
+		results = append(results, paginationTester{ID: &id})
+	}
+	return results
+}
+
+func resultIDList(t *testing.T, results []interface{}) []int {
+	ids, ok := resultIDs(results)
+	if !ok {
+		t.Fatalf("expected results to have IDs")
+	}
+	return ids
+}
+
+func TestParsePagination(t *testing.T) {
+	type testCase struct {
+		params    map[string]string
+		expected  Pagination
+		paginated bool
+		err       bool
+	}
+	testCases := []testCase{
+		{map[string]string{}, Pagination{}, false, false},
+		{map[string]string{"limit": "10"}, Pagination{Limit: 10}, true, false},
+		{map[string]string{"limit": "10", "page": "3"}, Pagination{Limit: 10, Offset: 20}, true, false},
+		{map[string]string{"limit": "10", "offset": "5", "page": "3"}, Pagination{Limit: 10, Offset: 5}, true, false},
+		{map[string]string{"limit": "10", "orderby": "name"}, Pagination{Limit: 10}, true, false},
+		{map[string]string{"limit": "10", "cursor": ""}, Pagination{Limit: 10, Cursor: true}, true, false},
+		{map[string]string{"limit": "10", "cursor": dbhelpers.EncodeCursor(7)}, Pagination{Limit: 10, Cursor: true, AfterID: 7}, true, false},
+		{map[string]string{"limit": "0"}, Pagination{}, false, true},
+		{map[string]string{"limit": "x"}, Pagination{}, false, true},
+		{map[string]string{"limit": "10", "page": "0"}, Pagination{}, false, true},
+		{map[string]string{"limit": "10", "offset": "-1"}, Pagination{}, false, true},
+		{map[string]string{"cursor": ""}, Pagination{}, false, true},
+		{map[string]string{"limit": "10", "cursor": "", "orderby": "name"}, Pagination{}, false, true},
+		{map[string]string{"limit": "10", "cursor": "", "page": "2"}, Pagination{}, false, true},
+		{map[string]string{"limit": "10", "cursor": "!!"}, Pagination{}, false, true},
+	}
+	for _, tc := range testCases {
+		p, paginated, err := ParsePagination(tc.params)
+		if tc.err {
+			if err == nil {
+				t.Errorf("ParsePagination(%+v) expected error, actual: nil", tc.params)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePagination(%+v) expected nil error, actual: %v", tc.params, err)
+			continue
+		}
+		if paginated != tc.paginated {
+			t.Errorf("ParsePagination(%+v) expected paginated %v, actual: %v", tc.params, tc.paginated, paginated)
+		}
+		if p != tc.expected {
+			t.Errorf("ParsePagination(%+v) expected %+v, actual: %+v", tc.params, tc.expected, p)
+		}
+	}
+}
+
+func TestPaginateOffset(t *testing.T) {
+	results := paginationResults(5, 4, 3, 2, 1)
+	page, next, err := Paginate(Pagination{Limit: 2, Offset: 2}, results)
+	if err != nil {
+		t.Fatalf("Paginate expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{3, 2}) {
+		t.Errorf("Paginate expected IDs [3 2], actual: %v", ids)
+	}
+	if next == nil || next.Offset != 4 {
+		t.Fatalf("Paginate expected next offset 4, actual: %+v", next)
+	}
+
+	page, next, err = Paginate(*next, results)
+	if err != nil {
+		t.Fatalf("Paginate expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{1}) {
+		t.Errorf("Paginate expected IDs [1], actual: %v", ids)
+	}
+	if next != nil {
+		t.Errorf("Paginate last page expected nil next, actual: %+v", next)
+	}
+
+	page, next, err = Paginate(Pagination{Limit: 2, Offset: 10}, results)
+	if err != nil || len(page) != 0 || next != nil {
+		t.Errorf("Paginate past end expected empty page, actual: %v %+v %v", page, next, err)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	results := paginationResults(5, 1, 4, 2, 3)
+	page, next, err := Paginate(Pagination{Limit: 2, Cursor: true}, results)
+	if err != nil {
+		t.Fatalf("Paginate expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("Paginate expected IDs [1 2], actual: %v", ids)
+	}
+	if next == nil || !next.Cursor || next.AfterID != 2 {
+		t.Fatalf("Paginate expected next after 2, actual: %+v", next)
+	}
+
+	// the next page is stable even if results before the cursor are deleted, and results are created
+	results = paginationResults(6, 5, 4, 3)
+	page, next, err = Paginate(*next, results)
+	if err != nil {
+		t.Fatalf("Paginate expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{3, 4}) {
+		t.Errorf("Paginate expected IDs [3 4], actual: %v", ids)
+	}
+	if next == nil || next.AfterID != 4 {
+		t.Fatalf("Paginate expected next after 4, actual: %+v", next)
+	}
+
+	page, next, err = Paginate(*next, results)
+	if err != nil {
+		t.Fatalf("Paginate expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{5, 6}) {
+		t.Errorf("Paginate expected IDs [5 6], actual: %v", ids)
+	}
+	if next != nil {
+		t.Errorf("Paginate last page expected nil next, actual: %+v", next)
+	}
+}
+
+func TestPaginateCursorNoIDs(t *testing.T) {
+	results := []interface{}{struct{ Name string }{"a"}, struct{ Name string }{"b"}}
+	if _, _, err := Paginate(Pagination{Limit: 1, Cursor: true}, results); err == nil {
+		t.Errorf("Paginate cursor without IDs expected error, actual: nil")
+	}
+}
+
+func TestPaginatedPage(t *testing.T) {
+	// with cursor pagination, the Reader read one more than the limit if there's a next page
+	page, next, err := PaginatedPage(Pagination{Limit: 2, Cursor: true, AfterID: 1}, 5, paginationResults(2, 3, 4))
+	if err != nil {
+		t.Fatalf("PaginatedPage expected nil error, actual: %v", err)
+	}
+	if ids := resultIDList(t, page); !reflect.DeepEqual(ids, []int{2, 3}) {
+		t.Errorf("PaginatedPage expected IDs [2 3], actual: %v", ids)
+	}
+	if next == nil || !next.Cursor || next.AfterID != 3 {
+		t.Errorf("PaginatedPage expected next after 3, actual: %+v", next)
+	}
+
+	page, next, err = PaginatedPage(Pagination{Limit: 2, Cursor: true, AfterID: 3}, 5, paginationResults(4, 5))
+	if err != nil {
+		t.Fatalf("PaginatedPage expected nil error, actual: %v", err)
+	}
+	if len(page) != 2 || next != nil {
+		t.Errorf("PaginatedPage last cursor page expected 2 results and nil next, actual: %v %+v", page, next)
+	}
+
+	page, next, err = PaginatedPage(Pagination{Limit: 2, Offset: 2}, 5, paginationResults(3, 4))
+	if err != nil {
+		t.Fatalf("PaginatedPage expected nil error, actual: %v", err)
+	}
+	if len(page) != 2 || next == nil || next.Offset != 4 {
+		t.Errorf("PaginatedPage expected next offset 4, actual: %+v", next)
+	}
+
+	page, next, err = PaginatedPage(Pagination{Limit: 2, Offset: 4}, 5, paginationResults(5))
+	if err != nil {
+		t.Fatalf("PaginatedPage expected nil error, actual: %v", err)
+	}
+	if len(page) != 1 || next != nil {
+		t.Errorf("PaginatedPage last offset page expected 1 result and nil next, actual: %v %+v", page, next)
+	}
+}
+
+func TestWithoutPaginationParams(t *testing.T) {
+	params := map[string]string{"limit": "2", "offset": "2", "page": "2", "cursor": "", "orderby": "name", "type": "EDGE"}
+	expected := map[string]string{"orderby": "name", "type": "EDGE"}
+	if actual := WithoutPaginationParams(params); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("WithoutPaginationParams expected %v, actual: %v", expected, actual)
+	}
+	if len(params) != 6 {
+		t.Errorf("WithoutPaginationParams expected params to be unchanged, actual: %v", params)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	type testCase struct {
+		url      string
+		next     Pagination
+		expected url.Values
+	}
+	testCases := []testCase{
+		{"/api/1.4/servers?limit=2&cursor=", Pagination{Limit: 2, Cursor: true, AfterID: 9}, url.Values{"limit": {"2"}, "cursor": {dbhelpers.EncodeCursor(9)}}},
+		{"/api/1.4/servers?limit=2&type=EDGE&cursor=abc", Pagination{Limit: 2, Cursor: true, AfterID: 9}, url.Values{"limit": {"2"}, "type": {"EDGE"}, "cursor": {dbhelpers.EncodeCursor(9)}}},
+		{"/api/1.4/servers?limit=2&page=3", Pagination{Limit: 2, Offset: 6}, url.Values{"limit": {"2"}, "page": {"4"}}},
+		{"/api/1.4/servers?limit=2&orderby=hostName", Pagination{Limit: 2, Offset: 2}, url.Values{"limit": {"2"}, "orderby": {"hostName"}, "page": {"2"}}},
+		{"/api/1.4/servers?limit=2&offset=3", Pagination{Limit: 2, Offset: 5}, url.Values{"limit": {"2"}, "offset": {"5"}}},
+	}
+	for _, tc := range testCases {
+		r, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		link, err := url.Parse(NextLink(r, tc.next))
+		if err != nil {
+			t.Fatalf("NextLink(%v) returned invalid URL: %v", tc.url, err)
+		}
+		if link.Path != "/api/1.4/servers" {
+			t.Errorf("NextLink(%v) expected path /api/1.4/servers, actual: %v", tc.url, link.Path)
+		}
+		if !reflect.DeepEqual(link.Query(), tc.expected) {
+			t.Errorf("NextLink(%v) expected query %v, actual: %v", tc.url, tc.expected, link.Query())
+		}
+	}
+}
@@ -117,9 +117,11 @@ func decodeAndValidateRequestBody(r *http.Request, v Validator) error {
 //      combines the path and query parameters
 //      produces the proper status code based on the error code returned
 //      marshals the structs returned into the proper response json
+//      sets the ETag and Last-Modified headers, and responds 304 Not Modified to matching conditional requests
+//      counts and paginates the results, if a limit is given
 func ReadHandler(reader Reader) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		inf, userErr, sysErr, errCode := NewInfo(r, nil, nil)
+		inf, userErr, sysErr, errCode := NewReadInfo(r, nil, nil)
 		if userErr != nil || sysErr != nil {
 			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 			return
@@ -133,6 +135,12 @@ func ReadHandler(reader Reader) http.HandlerFunc {
 		}
 		objectType := reflect.Indirect(interfacePtr).Type()
 		obj := reflect.New(objectType).Interface().(Reader)
+
+		pagination, paginated, err := ParsePagination(inf.Params)
+		if err != nil {
+			HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+			return
+		}
 		obj.SetInfo(inf)
 
 		results, userErr, sysErr, errCode := obj.Read()
@@ -140,7 +148,33 @@ func ReadHandler(reader Reader) http.HandlerFunc {
 			HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 			return
 		}
-		WriteResp(w, r, results)
+		lastModified, err := ReadLastModified(inf.Tx.Tx, results)
+		if err != nil {
+			HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting last modified time: "+err.Error()))
+			return
+		}
+		if !paginated {
+			WriteRespLastModified(w, r, results, lastModified)
+			return
+		}
+		// Readers which paginate in the database count the matching results; the results of other Readers are paginated here, so pagination is consistent for all Readers
+		count := len(results)
+		page, next := []interface{}(nil), (*Pagination)(nil)
+		if inf.resultCount != nil {
+			count = *inf.resultCount
+			if page, next, err = PaginatedPage(pagination, count, results); err != nil {
+				HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, err)
+				return
+			}
+		} else if page, next, err = Paginate(pagination, results); err != nil {
+			HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+			return
+		}
+		vals := map[string]interface{}{"summary": Summary{Count: count}}
+		if next != nil {
+			vals["next"] = NextLink(r, *next)
+		}
+		WriteRespValsLastModified(w, r, page, vals, lastModified)
 	}
 }
 
@@ -136,7 +136,7 @@ func TestCreateHandler(t *testing.T) {
 	keys, _ := typeRef.GetKeys()
 	expectedMessage := strings.ToUpper(typeRef.GetType()) + ": " + typeRef.GetAuditName() + ", ID: " + strconv.Itoa(keys["id"].(int)) + ", ACTION: " + Created + " " + typeRef.GetType() + ", keys: { id:" + strconv.Itoa(keys["id"].(int)) + " }"
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1, nil).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	createFunc(w, r)
@@ -188,6 +188,99 @@ func TestReadHandler(t *testing.T) {
 	}
 }
 
+func TestReadHandlerPagination(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "/api/1.4/testers?limit=1", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+	ctx = context.WithValue(ctx, DBContextKey, db)
+	ctx = context.WithValue(ctx, ConfigContextKey, &cfg)
+	ctx = context.WithValue(ctx, ReqIDContextKey, uint64(0))
+
+	r = r.WithContext(ctx)
+	readFunc := ReadHandler(&tester{})
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	readFunc(w, r)
+
+	body := "{\"response\":[{\"ID\":1}],\"summary\":{\"count\":1}}\n"
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+}
+
+// countingTester is a Reader which paginates in the database, returning the page of the request "?limit=2&page=2" of 5 results.
+type countingTester struct {
+	tester
+}
+
+func (i *countingTester) Read() ([]interface{}, error, error, int) {
+	if err := i.APIInfo().CountResults("SELECT id FROM tester", map[string]interface{}{}); err != nil {
+		return nil, nil, err, http.StatusInternalServerError
+	}
+	return []interface{}{tester{ID: 3}, tester{ID: 4}}, nil, nil, http.StatusOK
+}
+
+func TestReadHandlerPaginatedInDB(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "/api/1.4/testers?limit=2&page=2", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+	ctx = context.WithValue(ctx, DBContextKey, db)
+	ctx = context.WithValue(ctx, ConfigContextKey, &cfg)
+	ctx = context.WithValue(ctx, ReqIDContextKey, uint64(0))
+
+	r = r.WithContext(ctx)
+	readFunc := ReadHandler(&countingTester{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(SELECT id FROM tester\) AS counted`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectCommit()
+
+	readFunc(w, r)
+
+	// the Reader's page isn't paginated again
+	body := "{\"next\":\"/api/1.4/testers?limit=2\\u0026page=3\",\"response\":[{\"ID\":3},{\"ID\":4}],\"summary\":{\"count\":5}}\n"
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestUpdateHandler(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -222,7 +315,7 @@ func TestUpdateHandler(t *testing.T) {
 	keys, _ := typeRef.GetKeys()
 	expectedMessage := strings.ToUpper(typeRef.GetType()) + ": " + typeRef.GetAuditName() + ", ID: " + strconv.Itoa(keys["id"].(int)) + ", ACTION: " + Updated + " " + typeRef.GetType() + ", keys: { id:" + strconv.Itoa(keys["id"].(int)) + " }"
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1, nil).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	updateFunc(w, r)
@@ -268,7 +361,7 @@ func TestDeleteHandler(t *testing.T) {
 	keys, _ := typeRef.GetKeys()
 	expectedMessage := strings.ToUpper(typeRef.GetType()) + ": " + typeRef.GetAuditName() + ", ID: " + strconv.Itoa(keys["id"].(int)) + ", ACTION: " + Deleted + " " + typeRef.GetType() + ", keys: { id:" + strconv.Itoa(keys["id"].(int)) + " }"
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1, nil).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 	deleteFunc(w, r)
 
@@ -0,0 +1,161 @@
+package apitoken
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/lib/pq"
+)
+
+// Get handles GET requests for the current user's API tokens. Token secrets are never returned after creation.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	tokens, err := getTokens(inf.Tx.Tx, inf.User.ID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting API tokens: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, tokens)
+}
+
+// Create handles POST requests to create an API token for the current user. The token is only returned in this response; only its hash is stored.
+func Create(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	if inf.User.Token != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("API tokens can't be created with an API token"), nil)
+		return
+	}
+	req := tc.APITokenReq{}
+	if err := api.Parse(r.Body, inf.Tx.Tx, &req); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("parsing request: "+err.Error()), nil)
+		return
+	}
+	token, err := auth.GenerateAPIToken()
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("generating API token: "+err.Error()))
+		return
+	}
+	created := tc.APITokenCreated{
+		APIToken: tc.APIToken{
+			Name:    strings.TrimSpace(*req.Name),
+			Expires: *req.Expires,
+			CDN:     req.CDN,
+		},
+		Token: token,
+	}
+	if req.Capabilities != nil {
+		created.Capabilities = pq.StringArray(*req.Capabilities)
+	}
+	if req.Routes != nil {
+		created.Routes = normalizeRoutes(*req.Routes)
+	}
+	if userErr, sysErr, errCode := insertToken(inf.Tx.Tx, inf.User.ID, auth.HashAPIToken(token), &created.APIToken); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "USER: "+inf.User.UserName+", ID: "+strconv.Itoa(inf.User.ID)+", ACTION: Created API token "+created.Name, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "API token created. It won't be shown again.", created)
+}
+
+// Delete handles DELETE requests to revoke one of the current user's API tokens.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	name := ""
+	if err := inf.Tx.Tx.QueryRow(`DELETE FROM api_token WHERE id = $1 AND tm_user = $2 RETURNING name`, inf.IntParams["id"], inf.User.ID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("API token not found"), nil)
+			return
+		}
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting API token: "+err.Error()))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "USER: "+inf.User.UserName+", ID: "+strconv.Itoa(inf.User.ID)+", ACTION: Revoked API token "+name, inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "API token revoked.")
+}
+
+// normalizeRoutes returns the route scopes with upper-case methods and single spaces, the form routing matches.
+func normalizeRoutes(routes []string) pq.StringArray {
+	normalized := pq.StringArray{}
+	for _, route := range routes {
+		fields := strings.Fields(route)
+		normalized = append(normalized, strings.ToUpper(fields[0])+" "+fields[1])
+	}
+	return normalized
+}
+
+func getTokens(tx *sql.Tx, userID int) ([]tc.APIToken, error) {
+	rows, err := tx.Query(`
+SELECT t.id, t.name, t.expires, t.capabilities, t.routes, cdn.name, t.last_updated
+FROM api_token AS t
+LEFT JOIN cdn ON cdn.id = t.cdn
+WHERE t.tm_user = $1
+ORDER BY t.name
+`, userID)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	tokens := []tc.APIToken{}
+	for rows.Next() {
+		t := tc.APIToken{}
+		if err := rows.Scan(&t.ID, &t.Name, &t.Expires, &t.Capabilities, &t.Routes, &t.CDN, &t.LastUpdated); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// insertToken inserts the token, setting its ID and last updated time. It returns a user-facing error, a system error, and an error code.
+func insertToken(tx *sql.Tx, userID int, hash string, t *tc.APIToken) (error, error, int) {
+	qry := `
+INSERT INTO api_token (tm_user, name, token_hash, capabilities, routes, cdn, expires)
+VALUES ($1, $2, $3, $4, $5, (SELECT id FROM cdn WHERE name = $6), $7)
+RETURNING id, last_updated
+`
+	if err := tx.QueryRow(qry, userID, t.Name, hash, t.Capabilities, t.Routes, t.CDN, t.Expires).Scan(&t.ID, &t.LastUpdated); err != nil {
+		return api.ParseDBError(err)
+	}
+	return nil, nil, http.StatusOK
+}
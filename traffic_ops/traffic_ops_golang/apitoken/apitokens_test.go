@@ -0,0 +1,104 @@
+package apitoken
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/lib/pq"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNormalizeRoutes(t *testing.T) {
+	actual := normalizeRoutes([]string{"get  servers", "* cdns/*/queue_update"})
+	expected := pq.StringArray{"GET servers", "* cdns/*/queue_update"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("normalizeRoutes expected %v, actual %v", expected, actual)
+	}
+}
+
+func TestGetTokens(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	expires := time.Now().Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "name", "expires", "capabilities", "routes", "cdn", "last_updated"})
+	rows = rows.AddRow(1, "ci", expires, "{servers-read}", nil, "cdn1", time.Now())
+	rows = rows.AddRow(2, "all", expires, nil, "{\"GET servers\"}", nil, time.Now())
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs(7).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	tokens, err := getTokens(tx, 7)
+	if err != nil {
+		t.Fatalf("getTokens expected nil error, actual: %v", err)
+	}
+	tx.Commit()
+	if len(tokens) != 2 {
+		t.Fatalf("getTokens expected 2 tokens, actual: %d", len(tokens))
+	}
+	if !reflect.DeepEqual([]string(tokens[0].Capabilities), []string{"servers-read"}) || tokens[0].CDN == nil || *tokens[0].CDN != "cdn1" {
+		t.Errorf("getTokens expected scoped token, actual: %+v", tokens[0])
+	}
+	if tokens[1].Capabilities != nil || !reflect.DeepEqual([]string(tokens[1].Routes), []string{"GET servers"}) || tokens[1].CDN != nil {
+		t.Errorf("getTokens expected route scoped token, actual: %+v", tokens[1])
+	}
+}
+
+func TestInsertToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	token := tc.APIToken{Name: "ci", Expires: time.Now().Add(time.Hour)}
+	hash := auth.HashAPIToken("secret")
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO api_token").WithArgs(7, "ci", hash, token.Capabilities, token.Routes, token.CDN, token.Expires).WillReturnRows(sqlmock.NewRows([]string{"id", "last_updated"}).AddRow(3, time.Now()))
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	if userErr, sysErr, _ := insertToken(tx, 7, hash, &token); userErr != nil || sysErr != nil {
+		t.Fatalf("insertToken expected nil errors, actual: %v %v", userErr, sysErr)
+	}
+	tx.Commit()
+	if token.ID != 3 {
+		t.Errorf("insertToken expected id 3, actual: %d", token.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -83,7 +83,7 @@ func WithProfileData(w http.ResponseWriter, r *http.Request, makeCfg func(tx *sq
 }
 
 func WithProfileDataHdr(w http.ResponseWriter, r *http.Request, addHdr bool, contentType string, makeCfg func(tx *sql.Tx, cfg *config.Config, profile ats.ProfileData, fileName string) (string, error)) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"profile-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"profile-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -136,10 +136,7 @@ func WithProfileDataHdr(w http.ResponseWriter, r *http.Request, addHdr bool, con
 		return
 	}
 
-	if contentType != "" {
-		w.Header().Set(tc.ContentType, contentType)
-	}
-	w.Write([]byte(hdr + text))
+	api.WriteRespText(w, r, contentType, hdr+text)
 }
 
 // GetCDNNameFromNameOrID takes a string which is a CDN name or ID, and returns the CDN name, whether the CDN exists, and any error.
@@ -30,7 +30,7 @@ import (
 )
 
 func GetBGFetchDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -52,6 +52,5 @@ func GetBGFetchDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeBGFetchDotConfig(cdnName, toToolName, toURL)
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
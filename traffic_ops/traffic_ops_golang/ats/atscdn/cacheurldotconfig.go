@@ -31,7 +31,7 @@ import (
 )
 
 func GetCacheURLDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -62,8 +62,7 @@ func GetCacheURLDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeCacheURLDotConfig(cdnName, toToolName, toURL, fullFileName, dses)
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 // TODO test for nil origin, nil qstring ignore
@@ -31,7 +31,7 @@ import (
 )
 
 func GetEdgeHeaderRewriteDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -65,12 +65,11 @@ func GetEdgeHeaderRewriteDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeHeaderRewriteDotConfig(tc.CDNName(cdnName), toToolName, toURL, ds, assignedEdges)
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 func GetMidHeaderRewriteDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -105,8 +104,7 @@ func GetMidHeaderRewriteDotConfig(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeHeaderRewriteMidDotConfig(tc.CDNName(cdnName), toToolName, toURL, ds, assignedMids)
 
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 func getDeliveryService(tx *sqlx.Tx, xmlId string) (atscfg.HeaderRewriteDS, error) {
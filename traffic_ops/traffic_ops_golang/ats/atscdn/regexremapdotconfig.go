@@ -32,7 +32,7 @@ import (
 
 // cdns/regex_remap_xdns-staging-medium.config
 func GetRegexRemapDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -63,8 +63,7 @@ func GetRegexRemapDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeRegexRemapDotConfig(cdnName, toToolName, toURL, fullFileName, dses)
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 // TODO combine with GetCacheURLDSes?
@@ -36,7 +36,7 @@ import (
 const JobKeywordPurge = "PURGE"
 
 func GetRegexRevalidateDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"cdn-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -79,8 +79,7 @@ func GetRegexRevalidateDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeRegexRevalidateDotConfig(tc.CDNName(cdnName), params, toToolName, toURL, jobs)
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 // getJobs returns jobs which
@@ -26,14 +26,13 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ats"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 )
 
 func WithProfileData(w http.ResponseWriter, r *http.Request, contentType string, makeCfg func(tx *sql.Tx, cfg *config.Config, profile ats.ProfileData, fileName string) (string, error)) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"profile-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"profile-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -78,8 +77,5 @@ func WithProfileData(w http.ResponseWriter, r *http.Request, contentType string,
 		return
 	}
 
-	if contentType != "" {
-		w.Header().Set(tc.ContentType, contentType)
-	}
-	w.Write([]byte(text))
+	api.WriteRespText(w, r, contentType, text)
 }
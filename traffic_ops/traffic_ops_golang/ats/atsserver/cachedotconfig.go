@@ -31,7 +31,7 @@ import (
 )
 
 func GetCacheDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id-or-host"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"id-or-host"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -65,6 +65,5 @@ func GetCacheDotConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeServerCacheDotConfig(serverName, toToolName, toURL, dses)
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
@@ -29,7 +29,7 @@ import (
 )
 
 func GetChkconfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id-or-host"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"id-or-host"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -53,6 +53,5 @@ func GetChkconfig(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeChkconfig(params)
 
-	w.Header().Set(tc.ContentType, tc.ApplicationJson)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ApplicationJson, txt)
 }
@@ -33,7 +33,7 @@ import (
 )
 
 func GetHostingDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id-or-host"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"id-or-host"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -84,8 +84,7 @@ func GetHostingDotConfig(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeHostingDotConfig(serverName, toToolName, toURL, params, origins)
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 // GetServerHostingOrigins returns the list of origins on delivery services assigned to the given server, to be used in the ATS config file.
@@ -32,7 +32,7 @@ import (
 )
 
 func GetIPAllowDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id-or-host"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"id-or-host"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -70,8 +70,7 @@ func GetIPAllowDotConfig(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeIPAllowDotConfig(serverName, serverType, toToolName, toURL, params, childServers)
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
 
 // GetChildServers returns the child servers of the given Mid serverName. This should not be called with an Edge server.
@@ -30,7 +30,7 @@ import (
 )
 
 func GetConfigMetaData(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"server-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"server-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -77,6 +77,5 @@ func GetConfigMetaData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	txt := atscfg.MakeMetaConfig(serverName, server, tmParams.URL, tmParams.ReverseProxyURL, locationParams, uriSignedDSes, scopeParams)
-	w.Header().Set(tc.ContentType, tc.ApplicationJson)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ApplicationJson, txt)
 }
@@ -36,7 +36,7 @@ import (
 )
 
 func GetParentDotConfig(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"server-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"server-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -79,11 +79,23 @@ func GetParentDotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	topologies, err := ats.GetTopologies(inf.Tx.Tx)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting topologies: "+err.Error()))
+		return
+	}
+
+	cacheGroup, err := ats.GetCacheGroupName(inf.Tx.Tx, serverInfo.CacheGroupID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server cachegroup: "+err.Error()))
+		return
+	}
+
 	parentConfigDSes := []atscfg.ParentConfigDSTopLevel{}
 	if serverInfo.IsTopLevelCache() {
-		parentConfigDSes, err = getParentConfigDSTopLevel(inf.Tx.Tx, serverInfo.CDN)
+		parentConfigDSes, err = getParentConfigDSTopLevel(inf.Tx.Tx, serverInfo, topologies, cacheGroup)
 	} else {
-		parentConfigDSes, err = getParentConfigDS(inf.Tx.Tx, serverInfo.ID)
+		parentConfigDSes, err = getParentConfigDS(inf.Tx.Tx, serverInfo, topologies, cacheGroup)
 	}
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server params: "+err.Error()))
@@ -96,7 +108,7 @@ func GetParentDotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parentInfos, err := getParentInfo(inf.Tx.Tx, serverInfo)
+	parentInfos, err := getParentInfo(inf.Tx.Tx, serverInfo, cacheGroup, topologies, parentConfigDSes)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting server parent info: "+err.Error()))
 		return
@@ -104,8 +116,7 @@ func GetParentDotConfig(w http.ResponseWriter, r *http.Request) {
 
 	text := atscfg.MakeParentDotConfig(serverInfo, atsMajorVer, toolName, toURL, parentConfigDSes, serverParams, parentInfos)
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte(text))
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, text)
 }
 
 type ParentConfigDSSortByName []atscfg.ParentConfigDS
@@ -214,7 +225,9 @@ SELECT
   COALESCE(ds.multi_site_origin, false),
   COALESCE(ds.origin_shield, ''),
   ARRAY(SELECT required_capability FROM deliveryservices_required_capability dsrc WHERE dsrc.deliveryservice_id = ds.id),
-  dt.name AS ds_type
+  dt.name AS ds_type,
+  COALESCE(ds.topology, '') AS topology,
+  ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server dss WHERE dss.server = $2) AS assigned
 `
 }
 
@@ -232,14 +245,17 @@ const ParentConfigDSQueryOrder = `
 ORDER BY ds.id
 ` // TODO: perl does 'ORDER BY ds.id, rt.name, dsr.set_number' - but doesn't actually use regexes - ensure it isn't necessary
 
+// ParentConfigDSQueryWhere selects the delivery services assigned to the server, and those with topologies, which may have the server in an inner tier.
 const ParentConfigDSQueryWhere = `
-WHERE ds.id in (SELECT DISTINCT(dss.deliveryservice) FROM deliveryservice_server dss where dss.server = $1)
+WHERE ds.id in (SELECT DISTINCT(dss.deliveryservice) FROM deliveryservice_server dss where dss.server = $2)
+OR (cdn.name = $1 AND ds.topology IS NOT NULL)
 `
 
+// ParentConfigDSQueryWhereTopLevel selects the active delivery services assigned to any server, and those with topologies, whose inner tiers aren't assigned.
 const ParentConfigDSQueryWhereTopLevel = `
 WHERE
   cdn.name = $1
-  AND ds.id in (SELECT deliveryservice_server.deliveryservice FROM deliveryservice_server)
+  AND (ds.id in (SELECT deliveryservice_server.deliveryservice FROM deliveryservice_server) OR ds.topology IS NOT NULL)
   AND ds.active = true
 `
 
@@ -257,8 +273,8 @@ func ParentConfigDSQueryTopLevel() string {
 		ParentConfigDSQueryOrder
 }
 
-func getParentConfigDSTopLevel(tx *sql.Tx, cdnName tc.CDNName) ([]atscfg.ParentConfigDSTopLevel, error) {
-	dses, err := getParentConfigDSRaw(tx, ParentConfigDSQueryTopLevel(), []interface{}{cdnName})
+func getParentConfigDSTopLevel(tx *sql.Tx, server *atscfg.ServerInfo, topologies map[string]tc.Topology, cacheGroup string) ([]atscfg.ParentConfigDSTopLevel, error) {
+	dses, err := getParentConfigDSRaw(tx, ParentConfigDSQueryTopLevel(), []interface{}{server.CDN, server.ID}, topologies, cacheGroup)
 	if err != nil {
 		return nil, errors.New("getting top level raw parent config ds: " + err.Error())
 	}
@@ -275,13 +291,22 @@ func getParentConfigDSTopLevel(tx *sql.Tx, cdnName tc.CDNName) ([]atscfg.ParentC
 	return dsesWithParams, nil
 }
 
-func getParentConfigDS(tx *sql.Tx, serverID int) ([]atscfg.ParentConfigDSTopLevel, error) {
-	dses, err := getParentConfigDSRaw(tx, ParentConfigDSQuery(), []interface{}{serverID})
+func getParentConfigDS(tx *sql.Tx, server *atscfg.ServerInfo, topologies map[string]tc.Topology, cacheGroup string) ([]atscfg.ParentConfigDSTopLevel, error) {
+	dses, err := getParentConfigDSRaw(tx, ParentConfigDSQuery(), []interface{}{server.CDN, server.ID}, topologies, cacheGroup)
 	if err != nil {
 		return nil, errors.New("getting raw parent config ds: " + err.Error())
 	}
 
-	dsesWithParams, err := getParentConfigDSParams(tx, dses)
+	getParams := getParentConfigDSParams
+	for _, ds := range dses {
+		if ds.Topology != "" {
+			// the server may be in the top tier of the topology, which needs the multi-site origin params of top level caches
+			getParams = getParentConfigDSParamsTopLevel
+			break
+		}
+	}
+
+	dsesWithParams, err := getParams(tx, dses)
 	if err != nil {
 		return nil, errors.New("getting ds params: " + err.Error())
 	}
@@ -325,7 +350,8 @@ WHERE
 }
 
 // getParentConfigDSRaw returns a ParentConfigDSTopLevel, but all fields in addition to ParentConfigDS will be defaulted. This is because a ParentConfigDSTopLevel is returned to share the same interface, but it doesn't actually have top level data.
-func getParentConfigDSRaw(tx *sql.Tx, qry string, qryParams []interface{}) ([]atscfg.ParentConfigDSTopLevel, error) {
+// Delivery services whose topology doesn't include the server's cacheGroup are skipped.
+func getParentConfigDSRaw(tx *sql.Tx, qry string, qryParams []interface{}, topologies map[string]tc.Topology, cacheGroup string) ([]atscfg.ParentConfigDSTopLevel, error) {
 	rows, err := tx.Query(qry, qryParams...)
 	if err != nil {
 		return nil, errors.New("querying: " + err.Error())
@@ -335,9 +361,13 @@ func getParentConfigDSRaw(tx *sql.Tx, qry string, qryParams []interface{}) ([]at
 	for rows.Next() {
 		d := atscfg.ParentConfigDS{RequiredCapabilities: map[atscfg.ServerCapability]struct{}{}}
 		requiredCaps := []string{}
-		if err := rows.Scan(&d.Name, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.OriginShield, pq.Array(&requiredCaps), &d.Type); err != nil {
+		assigned := false
+		if err := rows.Scan(&d.Name, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.OriginShield, pq.Array(&requiredCaps), &d.Type, &d.Topology, &assigned); err != nil {
 			return nil, errors.New("scanning: " + err.Error())
 		}
+		if d.Topology != "" && !atscfg.TopologyIncludesServer(topologies[d.Topology], cacheGroup, assigned) {
+			continue // skip DSes whose topology doesn't include this server
+		}
 		if d.OriginFQDN == "" {
 			// TODO skip ANY_MAP DSes? Why? Did Perl, I didn't see it?
 			log.Errorf("parent.config generation: getting parent config ds: server %+v has no origin, skipping!\n", d.Name)
@@ -491,7 +521,8 @@ func getParentConfigDSParamsRaw(tx *sql.Tx, qry string, dsNames []string) (map[t
 	return params, nil
 }
 
-func getParentInfo(tx *sql.Tx, server *atscfg.ServerInfo) (map[atscfg.OriginHost][]atscfg.ParentInfo, error) {
+// getParentInfo returns the parents of the server's cachegroup, and sets the TopologyParents of the dses with topologies to the parents of the server's cacheGroup in the topology.
+func getParentInfo(tx *sql.Tx, server *atscfg.ServerInfo, cacheGroup string, topologies map[string]tc.Topology, dses []atscfg.ParentConfigDSTopLevel) (map[atscfg.OriginHost][]atscfg.ParentInfo, error) {
 	parentInfos := map[atscfg.OriginHost][]atscfg.ParentInfo{}
 
 	serverDomain, ok, err := getCDNDomainByProfileID(tx, server.ProfileID)
@@ -501,30 +532,81 @@ func getParentInfo(tx *sql.Tx, server *atscfg.ServerInfo) (map[atscfg.OriginHost
 		return parentInfos, nil // TODO warn? Perl doesn't.
 	}
 
-	profileCaches, originServers, err := getServerParentCacheGroupProfiles(tx, server)
+	topologyParentNames := []string{} // this server's parents in topologies, which are only parents of delivery services with those topologies
+	topologyTopLevel := false
+	for _, ds := range dses {
+		if ds.Topology == "" {
+			continue
+		}
+		primary, secondary, _ := topologies[ds.Topology].CacheGroupParents(cacheGroup)
+		if primary == "" && secondary == "" {
+			topologyTopLevel = true
+		}
+		for _, parent := range []string{primary, secondary} {
+			if parent != "" {
+				topologyParentNames = append(topologyParentNames, parent)
+			}
+		}
+	}
+
+	topologyParentIDs, err := ats.GetCacheGroupIDs(tx, topologyParentNames)
+	if err != nil {
+		return nil, errors.New("getting topology parent cachegroup IDs: " + err.Error())
+	}
+
+	profileCaches, originServers, topologyServers, err := getServerParentCacheGroupProfiles(tx, server, topologyParentIDs, topologyTopLevel)
 	if err != nil {
 		return nil, errors.New("getting server parent cachegroup profiles: " + err.Error())
 	}
 
+	cgID := func(name string) int {
+		if id, ok := topologyParentIDs[name]; ok {
+			return id
+		}
+		return atscfg.InvalidID
+	}
+	for i, ds := range dses {
+		if ds.Topology == "" {
+			continue
+		}
+		primary, secondary, _ := topologies[ds.Topology].CacheGroupParents(cacheGroup)
+		if primary == "" && secondary == "" {
+			continue
+		}
+		dses[i].TopologyParents = atscfg.MakeTopologyParentInfo(cgID(primary), cgID(secondary), profileCaches, topologyServers)
+	}
+
 	return atscfg.MakeParentInfo(server, serverDomain, profileCaches, originServers), nil
 }
 
 // getServerParentCacheGroupProfiles gets the profile information for servers belonging to the parent cachegroup, and secondary parent cachegroup, of the cachegroup of each server.
-func getServerParentCacheGroupProfiles(tx *sql.Tx, server *atscfg.ServerInfo) (map[atscfg.ProfileID]atscfg.ProfileCache, map[atscfg.OriginHost][]atscfg.CGServer, error) {
+// It also returns the servers of the server's parents in topologies, the topologyParentIDs, which are only parents of delivery services with those topologies. If topologyTopLevel, the server is in the top tier of a topology, and the origins are included for its multi-site origin delivery services.
+func getServerParentCacheGroupProfiles(tx *sql.Tx, server *atscfg.ServerInfo, topologyParentIDs map[string]int, topologyTopLevel bool) (map[atscfg.ProfileID]atscfg.ProfileCache, map[atscfg.OriginHost][]atscfg.CGServer, []atscfg.CGServer, error) {
 	// TODO make this more efficient - should be a single query - this was transliterated from Perl - it's extremely inefficient.
 
 	profileCaches := map[atscfg.ProfileID]atscfg.ProfileCache{}
 	originServers := map[atscfg.OriginHost][]atscfg.CGServer{} // "deliveryServices" in Perl
+	topologyServers := []atscfg.CGServer{}
 
-	qry := ""
-	if server.IsTopLevelCache() {
-		// multisite origins take all the org groups in to account
-		qry = `
-WITH parent_cachegroup_ids AS (
+	topologyParents := map[int]struct{}{}
+	topologyParentIDList := []int{}
+	for _, id := range topologyParentIDs {
+		topologyParents[id] = struct{}{}
+		topologyParentIDList = append(topologyParentIDList, id)
+	}
+
+	originCacheGroupsQry := `
   SELECT cg.id as v
   FROM cachegroup cg
   JOIN type on type.id = cg.type
   WHERE type.name = '` + tc.CacheGroupOriginTypeName + `'
+`
+	qry := ""
+	if server.IsTopLevelCache() {
+		// multisite origins take all the org groups in to account
+		qry = `
+WITH parent_cachegroup_ids AS (` + originCacheGroupsQry + `  UNION ALL
+  SELECT id as v FROM cachegroup WHERE id = ANY($2)
 )
 `
 	} else {
@@ -538,7 +620,14 @@ parent_cachegroup_ids AS (
   UNION ALL
   SELECT secondary_parent_cachegroup_id as v
   FROM cachegroup WHERE id IN (SELECT v from server_cachegroup_ids)
-)
+  UNION ALL
+  SELECT id as v FROM cachegroup WHERE id = ANY($3)
+`
+		if topologyTopLevel {
+			// multi-site origin delivery services whose topology has this server in its top tier need the origins
+			qry += `  UNION ALL` + originCacheGroupsQry
+		}
+		qry += `)
 `
 	}
 
@@ -572,14 +661,14 @@ WHERE
 	// TODO move qry, qryParams to separate funcs/consts
 	qryParams := []interface{}{}
 	if server.IsTopLevelCache() {
-		qryParams = []interface{}{server.CDN}
+		qryParams = []interface{}{server.CDN, pq.Array(topologyParentIDList)}
 	} else {
-		qryParams = []interface{}{server.CDN, server.ID}
+		qryParams = []interface{}{server.CDN, server.ID, pq.Array(topologyParentIDList)}
 	}
 
 	rows, err := tx.Query(qry, qryParams...)
 	if err != nil {
-		return nil, nil, errors.New("querying: " + err.Error())
+		return nil, nil, nil, errors.New("querying: " + err.Error())
 	}
 	defer rows.Close()
 
@@ -589,7 +678,7 @@ WHERE
 		s := atscfg.CGServer{Capabilities: map[atscfg.ServerCapability]struct{}{}}
 		caps := []string{}
 		if err := rows.Scan(&s.ServerID, &s.ServerHost, &s.ServerIP, &s.ServerPort, &s.CacheGroupID, &s.Status, &s.Type, &s.ProfileID, &s.CDN, &s.TypeName, pq.Array(&caps), &s.Domain); err != nil {
-			return nil, nil, errors.New("scanning: " + err.Error())
+			return nil, nil, nil, errors.New("scanning: " + err.Error())
 		}
 		for _, cap := range caps {
 			s.Capabilities[atscfg.ServerCapability(cap)] = struct{}{}
@@ -600,17 +689,17 @@ WHERE
 
 	serverCapabilities, err := ats.GetServerCapabilitiesByID(tx, cgServerIDs)
 	if err != nil {
-		return nil, nil, errors.New("getting server capabilities: " + err.Error())
+		return nil, nil, nil, errors.New("getting server capabilities: " + err.Error())
 	}
 
 	cgServerDSes, err := getServerDSes(tx, cgServerIDs)
 	if err != nil {
-		return nil, nil, errors.New("getting cachegroup server deliveryservices: " + err.Error())
+		return nil, nil, nil, errors.New("getting cachegroup server deliveryservices: " + err.Error())
 	}
 
 	profileParams, err := getParentConfigServerCacheProfileParams(tx, cgServerIDs) // TODO change to take cg IDs directly?
 	if err != nil {
-		return nil, nil, errors.New("getting cachegroup server profile params: " + err.Error())
+		return nil, nil, nil, errors.New("getting cachegroup server profile params: " + err.Error())
 	}
 
 	allDSMap := map[atscfg.DeliveryServiceID]struct{}{}
@@ -626,12 +715,12 @@ WHERE
 
 	dsRequiredCapabilities, err := ats.GetDeliveryServiceRequiredCapabilities(tx, allDSes)
 	if err != nil {
-		return nil, nil, errors.New("getting DS required capabilities: " + err.Error())
+		return nil, nil, nil, errors.New("getting DS required capabilities: " + err.Error())
 	}
 
 	dsOrigins, err := getDSOrigins(tx, allDSes)
 	if err != nil {
-		return nil, nil, errors.New("getting deliveryservice origins: " + err.Error())
+		return nil, nil, nil, errors.New("getting deliveryservice origins: " + err.Error())
 	}
 
 	for _, cgServer := range cgServers {
@@ -644,7 +733,13 @@ WHERE
 				}
 			}
 		} else {
-			originServers[atscfg.DeliveryServicesAllParentsKey] = append(originServers[atscfg.DeliveryServicesAllParentsKey], cgServer)
+			_, isTopologyParent := topologyParents[cgServer.CacheGroupID]
+			if isTopologyParent {
+				topologyServers = append(topologyServers, cgServer)
+			}
+			if (server.IsTopLevelCache() && !isTopologyParent) || cgServer.CacheGroupID == server.ParentCacheGroupID || cgServer.CacheGroupID == server.SecondaryParentCacheGroupID {
+				originServers[atscfg.DeliveryServicesAllParentsKey] = append(originServers[atscfg.DeliveryServicesAllParentsKey], cgServer)
+			}
 		}
 
 		if _, profileCachesHasProfile := profileCaches[cgServer.ProfileID]; !profileCachesHasProfile {
@@ -657,7 +752,7 @@ WHERE
 			}
 		}
 	}
-	return profileCaches, originServers, nil
+	return profileCaches, originServers, topologyServers, nil
 }
 
 func getServerDSes(tx *sql.Tx, serverIDs []int) (map[atscfg.ServerID][]atscfg.DeliveryServiceID, error) {
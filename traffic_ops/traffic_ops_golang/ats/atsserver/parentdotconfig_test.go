@@ -0,0 +1,91 @@
+package atsserver
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetParentInfoTopology(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	// the server's cachegroup "edge" has the parent cachegroup 10, but its parent in the "edge-mid" topology is "mid", 20
+	server := &atscfg.ServerInfo{CDN: "mycdn", ID: 42, ProfileID: 5, CacheGroupID: 7, ParentCacheGroupID: 10, SecondaryParentCacheGroupID: atscfg.InvalidID, ParentCacheGroupType: "MID_LOC", Type: tc.EdgeTypePrefix}
+	topologies := map[string]tc.Topology{
+		"edge-mid": {
+			Name: "edge-mid",
+			Nodes: []tc.TopologyNode{
+				{CacheGroup: "edge", Parents: []int{1}},
+				{CacheGroup: "mid"},
+			},
+		},
+	}
+	dses := []atscfg.ParentConfigDSTopLevel{
+		{ParentConfigDS: atscfg.ParentConfigDS{Name: "no-topology"}},
+		{ParentConfigDS: atscfg.ParentConfigDS{Name: "topology", Topology: "edge-mid"}},
+	}
+
+	cgServerCols := []string{"id", "host_name", "ip_address", "tcp_port", "cachegroup", "status", "type", "profile", "cdn_id", "type_name", "capabilities", "domain_name"}
+	cgServers := sqlmock.NewRows(cgServerCols)
+	cgServers = cgServers.AddRow(1, "cg-parent", "192.0.2.1", 80, 10, 1, 1, 6, 1, "MID", "{}", "example.net")
+	cgServers = cgServers.AddRow(2, "topology-parent", "192.0.2.2", 80, 20, 1, 1, 6, 1, "MID", "{}", "example.net")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT domain_name").WithArgs(server.ProfileID).WillReturnRows(sqlmock.NewRows([]string{"domain_name"}).AddRow("example.net"))
+	mock.ExpectQuery("SELECT name, id FROM cachegroup").WithArgs(`{"mid"}`).WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).AddRow("mid", 20))
+	mock.ExpectQuery("WITH server_cachegroup_ids").WithArgs(server.CDN, server.ID, "{20}").WillReturnRows(cgServers)
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"server", "server_capability"}))
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"server", "deliveryservice"}))
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"profile", "name", "value"}))
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"deliveryservice_id", "required_capability"}))
+	mock.ExpectCommit()
+
+	dbTx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	parentInfos, err := getParentInfo(dbTx, server, "edge", topologies, dses)
+	if err != nil {
+		t.Fatalf("getParentInfo expected nil error, actual: %v", err)
+	}
+	dbTx.Commit()
+
+	if parents := parentInfos[atscfg.DeliveryServicesAllParentsKey]; len(parents) != 1 || parents[0].Host != "cg-parent" {
+		t.Errorf("getParentInfo expected cachegroup parents [cg-parent], actual: %+v", parents)
+	}
+	if len(dses[0].TopologyParents) != 0 {
+		t.Errorf("getParentInfo expected no topology parents for delivery service without a topology, actual: %+v", dses[0].TopologyParents)
+	}
+	if parents := dses[1].TopologyParents; len(parents) != 1 || parents[0].Host != "topology-parent" || !parents[0].PrimaryParent {
+		t.Errorf("getParentInfo expected topology parents [topology-parent] as primary, actual: %+v", parents)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -21,7 +21,6 @@ package atsserver
 
 import (
 	"errors"
-	"io"
 	"net/http"
 
 	"github.com/apache/trafficcontrol/lib/go-atscfg"
@@ -31,7 +30,7 @@ import (
 )
 
 func GetServerConfigRemap(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"server-name-or-id"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"server-name-or-id"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -88,6 +87,5 @@ func GetServerConfigRemap(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeRemapDotConfig(serverName, toToolName, toURL, atsMajorVersion, cacheURLConfigParams, dsProfilesCacheKeyConfigParams, serverPackageParamData, serverInfo, remapDSData)
 
-	w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
-	io.WriteString(w, txt)
+	api.WriteRespText(w, r, tc.ContentTypeTextPlain, txt)
 }
@@ -30,7 +30,7 @@ import (
 )
 
 func GetUnknown(w http.ResponseWriter, r *http.Request) {
-	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id-or-host"}, nil)
+	inf, userErr, sysErr, errCode := api.NewReadInfo(r, []string{"id-or-host"}, nil)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
 		return
@@ -65,6 +65,5 @@ func GetUnknown(w http.ResponseWriter, r *http.Request) {
 
 	txt := atscfg.MakeServerUnknown(serverName, serverDomain, toToolName, toURL, params)
 
-	w.Header().Set(tc.ContentType, tc.ApplicationJson)
-	w.Write([]byte(txt))
+	api.WriteRespText(w, r, tc.ApplicationJson, txt)
 }
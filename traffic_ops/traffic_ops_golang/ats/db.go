@@ -30,6 +30,7 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/topology"
 
 	"github.com/lib/pq"
 )
@@ -434,6 +435,7 @@ WHERE
 	return dses, nil
 }
 
+// GetRemapDSData returns the delivery services of the server's remap.config. Delivery services with a topology are included if the topology includes the server's cache group, as atscfg.TopologyIncludesServer.
 func GetRemapDSData(tx *sql.Tx, serverInfo *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
 	if tc.CacheTypeFromString(serverInfo.Type) == tc.CacheTypeMid {
 		return GetRemapDSDataForMid(tx, serverInfo)
@@ -471,7 +473,9 @@ SELECT
   ds.protocol,
   ds.profile,
   ds.anonymous_blocking_enabled,
-  ds.active
+  ds.active,
+  COALESCE(ds.topology, '') AS topology,
+  ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server dss WHERE dss.server = $2) AS assigned
 FROM
   deliveryservice ds
   JOIN deliveryservice_regex dsr ON dsr.deliveryservice = ds.id
@@ -481,16 +485,19 @@ FROM
   JOIN cdn ON cdn.id = ds.cdn_id
 `
 
+// RemapDSDataQueryWhereForMid selects the delivery services assigned to any server, and those with topologies, whose inner tiers aren't assigned.
 const RemapDSDataQueryWhereForMid = `
 WHERE
   cdn.name = $1
-  AND ds.id in (SELECT dss.deliveryservice FROM deliveryservice_server dss)
+  AND (ds.id in (SELECT dss.deliveryservice FROM deliveryservice_server dss) OR ds.topology IS NOT NULL)
   AND ds.active = true
 `
 
+// RemapDSDataQueryWhereForEdge selects the delivery services assigned to the server, and those with topologies, which may have the server in an inner tier.
 const RemapDSDataQueryWhereForEdge = `
-JOIN deliveryservice_server dss ON dss.deliveryservice = ds.id
-WHERE dss.server = $1
+WHERE
+  ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server dss WHERE dss.server = $2)
+  OR (cdn.name = $1 AND ds.topology IS NOT NULL)
 `
 
 const RemapDSDataQueryOrderBy = `
@@ -501,31 +508,25 @@ ORDER BY
 `
 
 func GetRemapDSDataForMid(tx *sql.Tx, serverInfo *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
-	qry := RemapDSDataQuerySelectFrom + RemapDSDataQueryWhereForMid + RemapDSDataQueryOrderBy
-	rows, err := tx.Query(qry, serverInfo.CDN)
+	return getRemapDSData(tx, RemapDSDataQuerySelectFrom+RemapDSDataQueryWhereForMid+RemapDSDataQueryOrderBy, serverInfo)
+}
+
+func GetRemapDSDataForEdge(tx *sql.Tx, server *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
+	return getRemapDSData(tx, RemapDSDataQuerySelectFrom+RemapDSDataQueryWhereForEdge+RemapDSDataQueryOrderBy, server)
+}
+
+// getRemapDSData returns the delivery services selected by qry, which takes the server's CDN name and ID, skipping those whose topology doesn't include the server.
+func getRemapDSData(tx *sql.Tx, qry string, server *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
+	topologies, err := GetTopologies(tx)
 	if err != nil {
-		return nil, errors.New("querying: " + err.Error())
+		return nil, errors.New("getting topologies: " + err.Error())
 	}
-	defer rows.Close()
-
-	dses := []atscfg.RemapConfigDSData{}
-	for rows.Next() {
-		d := atscfg.RemapConfigDSData{}
-		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active); err != nil {
-			return nil, errors.New("scanning: " + err.Error())
-		}
-		if !RemapDotConfigIncludeInactiveDeliveryServices && !d.Active {
-			continue
-		}
-		d.Type = tc.DSTypeFromString(string(d.Type))
-		dses = append(dses, d)
+	cacheGroup, err := GetCacheGroupName(tx, server.CacheGroupID)
+	if err != nil {
+		return nil, errors.New("getting server cachegroup: " + err.Error())
 	}
-	return dses, nil
-}
 
-func GetRemapDSDataForEdge(tx *sql.Tx, server *atscfg.ServerInfo) ([]atscfg.RemapConfigDSData, error) {
-	qry := RemapDSDataQuerySelectFrom + RemapDSDataQueryWhereForEdge + RemapDSDataQueryOrderBy
-	rows, err := tx.Query(qry, server.ID)
+	rows, err := tx.Query(qry, server.CDN, server.ID)
 	if err != nil {
 		return nil, errors.New("querying: " + err.Error())
 	}
@@ -534,12 +535,17 @@ func GetRemapDSDataForEdge(tx *sql.Tx, server *atscfg.ServerInfo) ([]atscfg.Rema
 	dses := []atscfg.RemapConfigDSData{}
 	for rows.Next() {
 		d := atscfg.RemapConfigDSData{}
-		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active); err != nil {
+		dsTopology := ""
+		assigned := false
+		if err := rows.Scan(&d.Name, &d.ID, &d.DSCP, &d.RoutingName, &d.SigningAlgorithm, &d.QStringIgnore, &d.OriginFQDN, &d.MultiSiteOrigin, &d.RangeRequestHandling, &d.FQPacingRate, &d.OriginShield, &d.Pattern, &d.RegexType, &d.Type, &d.Domain, &d.RegexSetNumber, &d.EdgeHeaderRewrite, &d.MidHeaderRewrite, &d.RegexRemap, &d.CacheURL, &d.RemapText, &d.Protocol, &d.ProfileID, &d.AnonymousBlockingEnabled, &d.Active, &dsTopology, &assigned); err != nil {
 			return nil, errors.New("scanning: " + err.Error())
 		}
 		if !RemapDotConfigIncludeInactiveDeliveryServices && !d.Active {
 			continue
 		}
+		if dsTopology != "" && !atscfg.TopologyIncludesServer(topologies[dsTopology], cacheGroup, assigned) {
+			continue // skip DSes whose topology doesn't include this server
+		}
 		d.Type = tc.DSTypeFromString(string(d.Type))
 		dses = append(dses, d)
 	}
@@ -1010,3 +1016,47 @@ WHERE
 	}
 	return dsCaps, nil
 }
+
+// GetTopologies returns all topologies, keyed by name.
+func GetTopologies(tx *sql.Tx) (map[string]tc.Topology, error) {
+	topologies, err := topology.ReadTopologies(tx, "")
+	if err != nil {
+		return nil, err
+	}
+	topologyMap := map[string]tc.Topology{}
+	for _, t := range topologies {
+		topologyMap[t.Name] = t
+	}
+	return topologyMap, nil
+}
+
+// GetCacheGroupName returns the name of the cachegroup with the given ID, or the empty string if it doesn't exist.
+func GetCacheGroupName(tx *sql.Tx, id int) (string, error) {
+	name := ""
+	if err := tx.QueryRow(`SELECT name FROM cachegroup WHERE id = $1`, id).Scan(&name); err != nil && err != sql.ErrNoRows {
+		return "", errors.New("querying: " + err.Error())
+	}
+	return name, nil
+}
+
+// GetCacheGroupIDs returns the IDs of the named cachegroups, keyed by name. Names which don't exist are omitted.
+func GetCacheGroupIDs(tx *sql.Tx, names []string) (map[string]int, error) {
+	ids := map[string]int{}
+	if len(names) == 0 {
+		return ids, nil
+	}
+	rows, err := tx.Query(`SELECT name, id FROM cachegroup WHERE name = ANY($1)`, pq.Array(names))
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		name := ""
+		id := 0
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		ids[name] = id
+	}
+	return ids, nil
+}
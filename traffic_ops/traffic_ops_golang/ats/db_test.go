@@ -0,0 +1,110 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-atscfg"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// expectTopologies expects the queries of GetTopologies, returning an "edge-mid" topology, whose "edge" cachegroup's parent is "mid", and a "mid-only" topology of just "mid2".
+func expectTopologies(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"name", "description", "last_updated", "id", "cachegroup"})
+	rows = rows.AddRow("edge-mid", "", time.Now(), 1, "edge")
+	rows = rows.AddRow("edge-mid", "", time.Now(), 2, "mid")
+	rows = rows.AddRow("mid-only", "", time.Now(), 3, "mid2")
+	parentRows := sqlmock.NewRows([]string{"child", "parent", "rank"})
+	parentRows = parentRows.AddRow(1, 2, 1)
+	mock.ExpectQuery("SELECT").WithArgs("").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT").WithArgs("").WillReturnRows(parentRows)
+}
+
+func TestGetRemapDSDataTopology(t *testing.T) {
+	type testCase struct {
+		serverType string
+		cacheGroup string
+		expected   []string
+	}
+	testCases := []testCase{
+		// mids get the topology's delivery services without being assigned, but not those of topologies they aren't in
+		{serverType: tc.MidTypePrefix, cacheGroup: "mid", expected: []string{"edge-mid-unassigned", "edge-mid-assigned", "no-topology"}},
+		// edges only get the topology's delivery services they're assigned
+		{serverType: tc.EdgeTypePrefix, cacheGroup: "edge", expected: []string{"edge-mid-assigned", "no-topology"}},
+	}
+
+	for _, c := range testCases {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		server := &atscfg.ServerInfo{CDN: "mycdn", ID: 42, CacheGroupID: 7, Type: c.serverType}
+
+		cols := []string{"xml_id", "ds_id", "dscp", "routing_name", "signing_algorithm", "qstring_ignore", "org_server_fqdn", "multi_site_origin", "range_request_handling", "fq_pacing_rate", "origin_shield", "pattern", "re_type", "ds_type", "domain_name", "set_number", "edge_header_rewrite", "mid_header_rewrite", "regex_remap", "cacheurl", "remap_text", "protocol", "profile", "anonymous_blocking_enabled", "active", "topology", "assigned"}
+		rows := sqlmock.NewRows(cols)
+		addRow := func(name string, id int, topology string, assigned bool) {
+			rows.AddRow(name, id, 0, nil, nil, nil, "http://origin.example", nil, nil, nil, nil, `.*\.`+name+`\..*`, "HOST_REGEXP", "HTTP", "example.net", "0", nil, nil, nil, nil, nil, 0, nil, false, true, topology, assigned)
+		}
+		addRow("edge-mid-unassigned", 1, "edge-mid", false)
+		addRow("edge-mid-assigned", 2, "edge-mid", true)
+		addRow("mid-only", 3, "mid-only", false)
+		addRow("no-topology", 4, "", true)
+
+		mock.ExpectBegin()
+		expectTopologies(mock)
+		mock.ExpectQuery("SELECT name FROM cachegroup").WithArgs(server.CacheGroupID).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(c.cacheGroup))
+		mock.ExpectQuery("SELECT").WithArgs(server.CDN, server.ID).WillReturnRows(rows)
+		mock.ExpectCommit()
+
+		dbTx, err := mockDB.Begin()
+		if err != nil {
+			t.Fatalf("beginning transaction: %v", err)
+		}
+		dses, err := GetRemapDSData(dbTx, server)
+		if err != nil {
+			t.Fatalf("GetRemapDSData %v expected nil error, actual: %v", c.serverType, err)
+		}
+		dbTx.Commit()
+
+		names := []string{}
+		for _, ds := range dses {
+			names = append(names, ds.Name)
+		}
+		if len(names) != len(c.expected) {
+			t.Errorf("GetRemapDSData %v expected %v, actual: %v", c.serverType, c.expected, names)
+			continue
+		}
+		for i, name := range names {
+			if name != c.expected[i] {
+				t.Errorf("GetRemapDSData %v expected %v, actual: %v", c.serverType, c.expected, names)
+				break
+			}
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// apiTokenBytes is the number of random bytes in an API token.
+const apiTokenBytes = 32
+
+// GenerateAPIToken returns a new random API token.
+func GenerateAPIToken() (string, error) {
+	b := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("reading random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIToken returns the hash an API token is stored as. Tokens are random, so they don't need a salt or a slow hash.
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetCurrentUserFromAPIToken returns the user of the given unexpired API token, with the token's scopes. It returns a user-facing error, a system error to log, and an error code to return, like GetCurrentUserFromDB.
+func GetCurrentUserFromAPIToken(db *sqlx.DB, token string, timeout time.Duration) (CurrentUser, error, error, int) {
+	if db == nil {
+		return CurrentUser{}, nil, errors.New("no db provided to GetCurrentUserFromAPIToken"), http.StatusInternalServerError
+	}
+	qry := `
+SELECT u.username, t.id, t.name, t.capabilities, t.routes, cdn.name
+FROM api_token AS t
+JOIN tm_user AS u ON u.id = t.tm_user
+LEFT JOIN cdn ON cdn.id = t.cdn
+WHERE t.token_hash = $1
+AND t.expires > now()
+`
+	dbCtx, dbClose := context.WithTimeout(context.Background(), timeout)
+	defer dbClose()
+
+	username := ""
+	userToken := UserToken{}
+	caps := pq.StringArray(nil)
+	routes := pq.StringArray(nil)
+	if err := db.QueryRowContext(dbCtx, qry, HashAPIToken(token)).Scan(&username, &userToken.ID, &userToken.Name, &caps, &routes, &userToken.CDN); err != nil {
+		if err == sql.ErrNoRows {
+			return CurrentUser{}, errors.New("Unauthorized, invalid or expired API token."), nil, http.StatusUnauthorized
+		}
+		if err == context.DeadlineExceeded || err == context.Canceled {
+			return CurrentUser{}, nil, errors.New("db access timed out getting API token: " + err.Error()), http.StatusServiceUnavailable
+		}
+		return CurrentUser{}, nil, errors.New("getting API token: " + err.Error()), http.StatusInternalServerError
+	}
+	if caps != nil {
+		userToken.Capabilities = []string(caps)
+	}
+	if routes != nil {
+		userToken.Routes = []string(routes)
+	}
+
+	user, userErr, sysErr, code := GetCurrentUserFromDB(db, username, timeout)
+	if userErr != nil || sysErr != nil {
+		return CurrentUser{}, userErr, sysErr, code
+	}
+	user.Token = &userToken
+	return user, nil, nil, http.StatusOK
+}
@@ -393,6 +393,9 @@ func (cg *TOCacheGroup) Read() ([]interface{}, error, error, int) {
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
+	if err := cg.ReqInfo.CountResults(SelectQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("cachegroup read: counting: " + err.Error()), http.StatusInternalServerError
+	}
 	query := SelectQuery() + where + orderBy + pagination
 	rows, err := cg.ReqInfo.Tx.NamedQuery(query, queryValues)
 	if err != nil {
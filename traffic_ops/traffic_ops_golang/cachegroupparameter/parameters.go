@@ -57,7 +57,8 @@ func (cgparam *TOCacheGroupParameter) GetType() string {
 func (cgparam *TOCacheGroupParameter) Read() ([]interface{}, error, error, int) {
 	queryParamsToQueryCols := cgparam.ParamColumns()
 	parameters := cgparam.APIInfo().Params
-	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(parameters, queryParamsToQueryCols)
+	// the "id" parameter is the cache group of the path, so cursor pages are by the parameter ID
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndCursorPagination(parameters, queryParamsToQueryCols, "p.id")
 	if len(errs) > 0 {
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
@@ -74,6 +75,9 @@ func (cgparam *TOCacheGroupParameter) Read() ([]interface{}, error, error, int)
 		return nil, errors.New("cachegroup does not exist"), nil, http.StatusNotFound
 	}
 
+	if err := cgparam.APIInfo().CountResults(selectQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting " + cgparam.GetType() + ": " + err.Error()), http.StatusInternalServerError
+	}
 	query := selectQuery() + where + orderBy + pagination
 	rows, err := cgparam.ReqInfo.Tx.NamedQuery(query, queryValues)
 	if err != nil {
@@ -20,14 +20,19 @@ package cachegroupparameter
  */
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/apache/trafficcontrol/lib/go-tc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
 	"github.com/jmoiron/sqlx"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -227,6 +232,85 @@ func TestReadCacheGroupParameters(t *testing.T) {
 	}
 }
 
+func TestReadCacheGroupParametersCursorPages(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	// the cache group's parameters, whose IDs are unrelated to the cache group's ID
+	const cgID = 7
+	paramIDs := []int{12, 15, 21, 30, 31}
+	limit := 2
+
+	// expectPage expects the queries of a page after the given parameter ID, selecting the parameters the database would.
+	expectPage := func(afterID int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("cachegroup").WillReturnRows(sqlmock.NewRows(cgRows).AddRow("cachegroup_name"))
+		mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs(fmt.Sprint(cgID), sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(paramIDs)))
+		rows := sqlmock.NewRows(cgpRows)
+		for _, id := range paramIDs {
+			if id > afterID {
+				p := generateParameter("global", fmt.Sprintf("param%d", id), "val", false, id)
+				rows = rows.AddRow(p.ConfigFile, p.ID, p.LastUpdated, p.Name, p.Value, p.Secure)
+			}
+		}
+		mock.ExpectQuery(`cgp\.cachegroup=\? AND p\.id > \?\s+ORDER BY p\.id\s+LIMIT 3`).WithArgs(fmt.Sprint(cgID), afterID).WillReturnRows(rows)
+		mock.ExpectQuery("SELECT last_updated FROM log").WillReturnRows(sqlmock.NewRows([]string{"last_updated"}))
+		mock.ExpectCommit()
+	}
+	getPage := func(path string) ([]int, string) {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		ctx := context.WithValue(r.Context(), auth.CurrentUserKey, auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+		ctx = context.WithValue(ctx, api.PathParamsKey, map[string]string{"id": fmt.Sprint(cgID)})
+		ctx = context.WithValue(ctx, api.DBContextKey, db)
+		ctx = context.WithValue(ctx, api.ConfigContextKey, &config.Config{ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{DBQueryTimeoutSeconds: 20}})
+		ctx = context.WithValue(ctx, api.ReqIDContextKey, uint64(0))
+		w := httptest.NewRecorder()
+		api.ReadHandler(&TOCacheGroupParameter{})(w, r.WithContext(ctx))
+
+		resp := struct {
+			Next     string                           `json:"next"`
+			Response []tc.CacheGroupParameterNullable `json:"response"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding response %s: %v", w.Body.String(), err)
+		}
+		ids := []int{}
+		for _, p := range resp.Response {
+			ids = append(ids, *p.ID)
+		}
+		return ids, resp.Next
+	}
+
+	expectPage(0)
+	first, next := getPage(fmt.Sprintf("/api/1.5/cachegroups/%d/parameters?limit=%d&cursor=", cgID, limit))
+	if next == "" {
+		t.Fatal("first page expected: a next page, actual: none")
+	}
+	expectPage(first[len(first)-1])
+	second, _ := getPage(next)
+
+	seen := map[int]bool{}
+	for _, id := range append(first, second...) {
+		if seen[id] {
+			t.Errorf("pages expected: no duplicates, actual: parameter %d twice", id)
+		}
+		seen[id] = true
+	}
+	for _, id := range paramIDs[:2*limit] {
+		if !seen[id] {
+			t.Errorf("pages expected: no gaps, actual: parameter %d missing", id)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func generateParameter(configFile, param, val string, secureFlag bool, id int) tc.CacheGroupParameterNullable {
 	lastUpdated := tc.TimeNoMod{}
 	lastUpdated.Scan(time.Now())
@@ -77,6 +77,9 @@ func (cgunparam *TOCacheGroupUnassignedParameter) Read() ([]interface{}, error,
 		where = fmt.Sprintf("\nAND%s", where[len(dbhelpers.BaseWhere):])
 	}
 
+	if err := cgunparam.APIInfo().CountResults(selectUnassignedParametersQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting " + cgunparam.GetType() + ": " + err.Error()), http.StatusInternalServerError
+	}
 	query := selectUnassignedParametersQuery() + where + orderBy + pagination
 	rows, err := cgunparam.ReqInfo.Tx.NamedQuery(query, queryValues)
 	if err != nil {
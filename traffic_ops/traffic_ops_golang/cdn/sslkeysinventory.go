@@ -0,0 +1,415 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+)
+
+// GetSSLKeysInventory returns the parsed state of every delivery service certificate in the CDN, including HTTPS delivery services which are missing certificates.
+// Only the delivery services of the user's tenants are returned. Keys without a delivery service have no tenant, and are only returned to admins.
+//
+// The optional expiresWithin parameter (e.g. 30d or 12h) limits the response to certificates expiring within that duration, including certificates which have already expired. The optional problems parameter limits the response to certificates with problems, and the optional deliveryservice parameter to a single delivery service.
+func GetSSLKeysInventory(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	if !inf.Config.KeyStoreEnabled {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("the Riak service is unavailable"), errors.New("getting cdn ssl key inventory: Riak is not configured"))
+		return
+	}
+
+	filter := inventoryFilter{DeliveryService: inf.Params["deliveryservice"]}
+	if expiresWithin, ok := inf.Params["expiresWithin"]; ok {
+		dur, err := parseExpiresWithin(expiresWithin)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("expiresWithin: "+err.Error()), nil)
+			return
+		}
+		filter.ExpiresWithin = &dur
+	}
+	if problems, ok := inf.Params["problems"]; ok {
+		b, err := strconv.ParseBool(problems)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("problems must be a boolean"), nil)
+			return
+		}
+		filter.Problems = b
+	}
+
+	cdnName := inf.Params["name"]
+	if ok, err := dbhelpers.CDNExists(cdnName, inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("checking cdn existence: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("cdn not found"), nil)
+		return
+	}
+	tenantIDs, err := tenant.GetUserTenantIDListTx(inf.Tx.Tx, inf.User.TenantID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting cdn ssl key inventory: getting user tenant ID list: "+err.Error()))
+		return
+	}
+	dses, authorizedDSes, err := getInventoryDSes(inf.Tx.Tx, cdnName, tenantIDs)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting cdn ssl key inventory: "+err.Error()))
+		return
+	}
+	keys, err := getSSLKeys(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, cdnName)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting cdn ssl key inventory: "+err.Error()))
+		return
+	}
+	keys = filterAuthorizedSSLKeys(keys, authorizedDSes, inf.User.PrivLevel >= auth.PrivLevelAdmin)
+	inventory := makeSSLKeysInventory(dses, keys, time.Now(), nil)
+	api.WriteResp(w, r, filterSSLKeysInventory(inventory, filter, time.Now()))
+}
+
+// inventoryDS is the delivery service data needed to check a certificate.
+type inventoryDS struct {
+	Name        string
+	Protocol    *int
+	ExampleURLs []string
+}
+
+// inventoryFilter is the filter parameters of an inventory request. Zero values don't filter.
+type inventoryFilter struct {
+	DeliveryService string
+	ExpiresWithin   *time.Duration
+	Problems        bool
+}
+
+// getInventoryDSes returns the delivery services in the given CDN which have SSL keys and are in one of the given tenants, keyed by name, and whether each delivery service in the CDN is in one of the tenants.
+func getInventoryDSes(tx *sql.Tx, cdnName string, tenantIDs []int) (map[string]inventoryDS, map[string]bool, error) {
+	qry := `
+SELECT ds.xml_id, ds.protocol, t.name, ds.routing_name, cdn.domain_name, COALESCE(ds.tenant_id = ANY($2), false)
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE cdn.name = $1
+`
+	rows, err := tx.Query(qry, cdnName, pq.Array(tenantIDs))
+	if err != nil {
+		return nil, nil, errors.New("querying delivery services: " + err.Error())
+	}
+	defer rows.Close()
+
+	type dsData struct {
+		protocol    *int
+		dsType      tc.DSType
+		routingName string
+		cdnDomain   string
+	}
+	data := map[string]dsData{}
+	names := []string{}
+	authorized := map[string]bool{}
+	for rows.Next() {
+		name := ""
+		protocol := sql.NullInt64{}
+		dsType := ""
+		d := dsData{}
+		isAuthorized := false
+		if err := rows.Scan(&name, &protocol, &dsType, &d.routingName, &d.cdnDomain, &isAuthorized); err != nil {
+			return nil, nil, errors.New("scanning delivery services: " + err.Error())
+		}
+		authorized[name] = isAuthorized
+		if !isAuthorized {
+			continue
+		}
+		d.dsType = tc.DSTypeFromString(dsType)
+		if !d.dsType.HasSSLKeys() {
+			continue
+		}
+		if protocol.Valid {
+			p := int(protocol.Int64)
+			d.protocol = &p
+		}
+		data[name] = d
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.New("iterating delivery services: " + err.Error())
+	}
+	if len(names) == 0 {
+		return map[string]inventoryDS{}, authorized, nil
+	}
+
+	matchLists, err := deliveryservice.GetDeliveryServicesMatchLists(names, tx)
+	if err != nil {
+		return nil, nil, errors.New("getting delivery service matchlists: " + err.Error())
+	}
+	dses := make(map[string]inventoryDS, len(data))
+	for name, d := range data {
+		dses[name] = inventoryDS{
+			Name:        name,
+			Protocol:    d.protocol,
+			ExampleURLs: deliveryservice.MakeExampleURLs(d.protocol, d.dsType, d.routingName, matchLists[name], d.cdnDomain),
+		}
+	}
+	return dses, authorized, nil
+}
+
+// filterAuthorizedSSLKeys returns the keys of the delivery services which authorized says are in the user's tenants. Keys without a delivery service have no tenant, and are only returned if includeOrphans is true.
+func filterAuthorizedSSLKeys(keys []tc.CDNSSLKey, authorized map[string]bool, includeOrphans bool) []tc.CDNSSLKey {
+	filtered := []tc.CDNSSLKey{}
+	for _, key := range keys {
+		isAuthorized, ok := authorized[key.DeliveryService]
+		if ok && !isAuthorized {
+			continue
+		}
+		if !ok && !includeOrphans {
+			continue
+		}
+		filtered = append(filtered, key)
+	}
+	return filtered
+}
+
+// makeSSLKeysInventory parses the given keys, and returns an inventory item for each key, and for each HTTPS delivery service without a key. If roots is nil, the system roots are used to verify chains.
+func makeSSLKeysInventory(dses map[string]inventoryDS, keys []tc.CDNSSLKey, now time.Time, roots *x509.CertPool) []tc.CDNSSLKeyInventoryItem {
+	inventory := []tc.CDNSSLKeyInventoryItem{}
+	hasKey := map[string]struct{}{}
+	for _, key := range keys {
+		hasKey[key.DeliveryService] = struct{}{}
+		ds, ok := dses[key.DeliveryService]
+		item := makeSSLKeyInventoryItem(key, ds.ExampleURLs, now, roots)
+		if !ok {
+			item.Errors = append(item.Errors, "no delivery service '"+key.DeliveryService+"' with SSL keys in this CDN")
+		}
+		inventory = append(inventory, item)
+	}
+	for name, ds := range dses {
+		if _, ok := hasKey[name]; ok {
+			continue
+		}
+		if ds.Protocol == nil || *ds.Protocol == 0 {
+			continue // HTTP-only delivery services don't need certificates
+		}
+		inventory = append(inventory, tc.CDNSSLKeyInventoryItem{DeliveryService: name, Missing: true})
+	}
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i].DeliveryService < inventory[j].DeliveryService })
+	return inventory
+}
+
+// validHostRe matches literal host names and wildcards. Example URLs from non-zero set numbers are the raw match pattern, which may be a regular expression that can't be checked against a certificate.
+var validHostRe = regexp.MustCompile(`^[A-Za-z0-9*][A-Za-z0-9.-]*$`)
+
+func makeSSLKeyInventoryItem(key tc.CDNSSLKey, exampleURLs []string, now time.Time, roots *x509.CertPool) tc.CDNSSLKeyInventoryItem {
+	item := tc.CDNSSLKeyInventoryItem{DeliveryService: key.DeliveryService, HostName: key.HostName}
+
+	certs, err := parseCertChain(key.Certificate.Crt)
+	if err != nil {
+		item.Errors = append(item.Errors, "parsing certificate: "+err.Error())
+		return item
+	}
+	leaf := certs[0]
+	item.Subject = leaf.Subject.String()
+	item.Issuer = leaf.Issuer.String()
+	item.SANs = append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		item.SANs = append(item.SANs, ip.String())
+	}
+	notBefore := leaf.NotBefore
+	notAfter := leaf.NotAfter
+	item.NotBefore = &notBefore
+	item.NotAfter = &notAfter
+	days := int(notAfter.Sub(now).Hours() / 24)
+	item.DaysUntilExpiry = &days
+	item.Expired = now.After(notAfter)
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates, Roots: roots, CurrentTime: now}); err != nil {
+		chainErr := err.Error()
+		item.ChainError = &chainErr
+	} else {
+		item.ChainValid = true
+	}
+
+	if matches, err := keyMatchesCert(key.Certificate.Key, leaf); err != nil {
+		item.Errors = append(item.Errors, "parsing private key: "+err.Error())
+	} else {
+		item.KeyMatches = matches
+	}
+
+	hosts := []string{}
+	if key.HostName != "" {
+		hosts = append(hosts, key.HostName)
+	}
+	for _, exampleURL := range exampleURLs {
+		if !strings.HasPrefix(exampleURL, "https://") {
+			continue
+		}
+		if host := strings.TrimPrefix(exampleURL, "https://"); validHostRe.MatchString(host) {
+			hosts = append(hosts, host)
+		}
+	}
+	item.UnmatchedHosts = []string{}
+	for _, host := range hosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			item.UnmatchedHosts = append(item.UnmatchedHosts, host)
+		}
+	}
+	item.HostsMatch = len(item.UnmatchedHosts) == 0
+	return item
+}
+
+// decodeStoredPEM returns the PEM of a certificate or key from the key store, which is base64-encoded by Traffic Ops, but may be plain PEM if it was added by other tools.
+func decodeStoredPEM(stored string) ([]byte, error) {
+	if strings.Contains(stored, "-----BEGIN") {
+		return []byte(stored), nil
+	}
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(stored), ""))
+}
+
+// parseCertChain parses the stored certificate chain. The first certificate returned is the leaf.
+func parseCertChain(stored string) ([]*x509.Certificate, error) {
+	rest, err := decodeStoredPEM(stored)
+	if err != nil {
+		return nil, errors.New("decoding base64: " + err.Error())
+	}
+	certs := []*x509.Certificate{}
+	for {
+		block := (*pem.Block)(nil)
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.New("parsing x509: " + err.Error())
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+// keyMatchesCert returns whether the stored private key is the key of the given certificate.
+func keyMatchesCert(stored string, cert *x509.Certificate) (bool, error) {
+	keyPEM, err := decodeStoredPEM(stored)
+	if err != nil {
+		return false, errors.New("decoding base64: " + err.Error())
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return false, errors.New("no PEM private key found")
+	}
+	signer, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return false, err
+	}
+	keyPub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return false, errors.New("marshalling key public key: " + err.Error())
+	}
+	certPub, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return false, errors.New("marshalling certificate public key: " + err.Error())
+	}
+	return bytes.Equal(keyPub, certPub), nil
+}
+
+// parsePrivateKey parses a DER PKCS#1 RSA, SEC 1 EC, or PKCS#8 private key.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.New("unknown private key format")
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key type")
+}
+
+// parseExpiresWithin parses a duration, which may be a number of days such as 30d, or any duration accepted by time.ParseDuration such as 12h.
+func parseExpiresWithin(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days < 0 {
+			return 0, errors.New("must be a duration, such as 30d or 12h")
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil || dur < 0 {
+		return 0, errors.New("must be a duration, such as 30d or 12h")
+	}
+	return dur, nil
+}
+
+func filterSSLKeysInventory(inventory []tc.CDNSSLKeyInventoryItem, filter inventoryFilter, now time.Time) []tc.CDNSSLKeyInventoryItem {
+	filtered := []tc.CDNSSLKeyInventoryItem{}
+	for _, item := range inventory {
+		if filter.DeliveryService != "" && item.DeliveryService != filter.DeliveryService {
+			continue
+		}
+		if filter.ExpiresWithin != nil && (item.NotAfter == nil || item.NotAfter.After(now.Add(*filter.ExpiresWithin))) {
+			continue
+		}
+		if filter.Problems && !item.HasProblem() {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
@@ -0,0 +1,196 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+)
+
+func makeTestCert(t *testing.T, cn string, dnsNames []string, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              dnsNames,
+		NotBefore:             notAfter.AddDate(-20, 0, 0),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if parent == nil {
+		parent = tmpl
+		parentKey = key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	return cert, key
+}
+
+func encodeTestKeys(t *testing.T, cert *x509.Certificate, key *ecdsa.PrivateKey) tc.CDNSSLKeyCert {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshalling key: %v", err)
+	}
+	crt := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return tc.CDNSSLKeyCert{Crt: base64.StdEncoding.EncodeToString(crt), Key: base64.StdEncoding.EncodeToString(keyPEM)}
+}
+
+func TestMakeSSLKeysInventory(t *testing.T) {
+	now := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	ca, caKey := makeTestCert(t, "Test CA", nil, now.Add(10*365*24*time.Hour), nil, nil)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	goodCert, goodKey := makeTestCert(t, "*.good.cdn.test", []string{"*.good.cdn.test"}, now.Add(90*24*time.Hour), ca, caKey)
+	expiringCert, expiringKey := makeTestCert(t, "*.expiring.cdn.test", []string{"*.expiring.cdn.test"}, now.Add(10*24*time.Hour), ca, caKey)
+	wrongHostCert, wrongHostKey := makeTestCert(t, "other.test", []string{"other.test"}, now.Add(90*24*time.Hour), ca, caKey)
+	selfSigned, selfSignedKey := makeTestCert(t, "*.self.cdn.test", []string{"*.self.cdn.test"}, now.Add(90*24*time.Hour), nil, nil)
+
+	https := 1
+	httpOnly := 0
+	dses := map[string]inventoryDS{
+		"good":      {Name: "good", Protocol: &https, ExampleURLs: []string{"https://cdn.good.cdn.test"}},
+		"expiring":  {Name: "expiring", Protocol: &https, ExampleURLs: []string{"https://cdn.expiring.cdn.test"}},
+		"mismatch":  {Name: "mismatch", Protocol: &https, ExampleURLs: []string{"https://cdn.mismatch.cdn.test"}},
+		"wronghost": {Name: "wronghost", Protocol: &https, ExampleURLs: []string{"https://cdn.wronghost.cdn.test", `https://.*\.regex\.test`}},
+		"self":      {Name: "self", Protocol: &https, ExampleURLs: []string{"https://cdn.self.cdn.test"}},
+		"missing":   {Name: "missing", Protocol: &https},
+		"httponly":  {Name: "httponly", Protocol: &httpOnly},
+	}
+	keys := []tc.CDNSSLKey{
+		{DeliveryService: "good", HostName: "*.good.cdn.test", Certificate: encodeTestKeys(t, goodCert, goodKey)},
+		{DeliveryService: "expiring", HostName: "*.expiring.cdn.test", Certificate: encodeTestKeys(t, expiringCert, expiringKey)},
+		{DeliveryService: "mismatch", HostName: "*.good.cdn.test", Certificate: tc.CDNSSLKeyCert{Crt: encodeTestKeys(t, goodCert, goodKey).Crt, Key: encodeTestKeys(t, expiringCert, expiringKey).Key}},
+		{DeliveryService: "wronghost", HostName: "*.wronghost.cdn.test", Certificate: encodeTestKeys(t, wrongHostCert, wrongHostKey)},
+		{DeliveryService: "self", HostName: "*.self.cdn.test", Certificate: encodeTestKeys(t, selfSigned, selfSignedKey)},
+		{DeliveryService: "garbage", HostName: "garbage.test", Certificate: tc.CDNSSLKeyCert{Crt: "bm90IGEgY2VydA==", Key: "bm90IGEga2V5"}},
+	}
+
+	inventory := makeSSLKeysInventory(dses, keys, now, roots)
+	items := map[string]tc.CDNSSLKeyInventoryItem{}
+	for _, item := range inventory {
+		items[item.DeliveryService] = item
+	}
+	if len(items) != 7 {
+		t.Fatalf("expected 7 inventory items, actual %d: %+v", len(items), inventory)
+	}
+	if _, ok := items["httponly"]; ok {
+		t.Errorf("expected HTTP-only delivery service without a certificate to be omitted")
+	}
+
+	good := items["good"]
+	if good.HasProblem() {
+		t.Errorf("expected good certificate to have no problems, actual %+v", good)
+	}
+	if good.DaysUntilExpiry == nil || *good.DaysUntilExpiry != 90 {
+		t.Errorf("expected good certificate to expire in 90 days, actual %v", good.DaysUntilExpiry)
+	}
+	if good.Subject != "CN=*.good.cdn.test" || good.Issuer != "CN=Test CA" || len(good.SANs) != 1 || good.SANs[0] != "*.good.cdn.test" {
+		t.Errorf("expected good certificate subject, issuer, and SANs, actual %+v", good)
+	}
+	if !items["missing"].Missing || !items["missing"].HasProblem() {
+		t.Errorf("expected delivery service without certificate to be missing, actual %+v", items["missing"])
+	}
+	if items["mismatch"].KeyMatches {
+		t.Errorf("expected mismatched key to not match")
+	}
+	if wh := items["wronghost"]; wh.HostsMatch || len(wh.UnmatchedHosts) != 2 {
+		t.Errorf("expected wrong host certificate to have 2 unmatched hosts (excluding the regex), actual %+v", wh.UnmatchedHosts)
+	}
+	if self := items["self"]; self.ChainValid || self.ChainError == nil {
+		t.Errorf("expected self-signed certificate to have an invalid chain, actual %+v", self)
+	}
+	if garbage := items["garbage"]; len(garbage.Errors) != 2 {
+		t.Errorf("expected unparseable certificate and unknown delivery service errors, actual %+v", garbage.Errors)
+	}
+
+	thirtyDays := 30 * 24 * time.Hour
+	expiring := filterSSLKeysInventory(inventory, inventoryFilter{ExpiresWithin: &thirtyDays}, now)
+	if len(expiring) != 1 || expiring[0].DeliveryService != "expiring" {
+		t.Errorf("expected expiresWithin 30d to return only the expiring certificate, actual %+v", expiring)
+	}
+	problems := filterSSLKeysInventory(inventory, inventoryFilter{Problems: true}, now)
+	if len(problems) != 5 {
+		t.Errorf("expected 5 certificates with problems, actual %d", len(problems))
+	}
+	one := filterSSLKeysInventory(inventory, inventoryFilter{DeliveryService: "good"}, now)
+	if len(one) != 1 || one[0].DeliveryService != "good" {
+		t.Errorf("expected deliveryservice filter to return only 'good', actual %+v", one)
+	}
+}
+
+func TestFilterAuthorizedSSLKeys(t *testing.T) {
+	keys := []tc.CDNSSLKey{{DeliveryService: "mine"}, {DeliveryService: "theirs"}, {DeliveryService: "deleted"}}
+	authorized := map[string]bool{"mine": true, "theirs": false}
+
+	filtered := filterAuthorizedSSLKeys(keys, authorized, false)
+	if len(filtered) != 1 || filtered[0].DeliveryService != "mine" {
+		t.Errorf("expected only the key of the user's tenant's delivery service, actual %+v", filtered)
+	}
+
+	filtered = filterAuthorizedSSLKeys(keys, authorized, true)
+	if len(filtered) != 2 || filtered[0].DeliveryService != "mine" || filtered[1].DeliveryService != "deleted" {
+		t.Errorf("expected the key of the user's tenant's delivery service and the key without a delivery service, actual %+v", filtered)
+	}
+}
+
+func TestParseExpiresWithin(t *testing.T) {
+	valid := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"0d":  0,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for s, expected := range valid {
+		if actual, err := parseExpiresWithin(s); err != nil {
+			t.Errorf("parseExpiresWithin(%q) expected no error, actual %v", s, err)
+		} else if actual != expected {
+			t.Errorf("parseExpiresWithin(%q) expected %v, actual %v", s, expected, actual)
+		}
+	}
+	for _, s := range []string{"", "d", "-1d", "30", "thirty days", "-5h"} {
+		if _, err := parseExpiresWithin(s); err == nil {
+			t.Errorf("parseExpiresWithin(%q) expected error, actual nil", s)
+		}
+	}
+}
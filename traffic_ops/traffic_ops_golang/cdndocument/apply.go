@@ -0,0 +1,490 @@
+package cdndocument
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/lib/pq"
+)
+
+// ApplyHandler applies the requested document to the CDN, making the changes PlanHandler would return in the request transaction, so either all of them are made or none are.
+func ApplyHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	cdnID, desired, plan, userErr, sysErr, errCode := planRequest(inf, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if len(plan.Changes) == 0 {
+		api.WriteRespAlertObj(w, r, tc.SuccessLevel, "CDN "+plan.CDN+" already matches the document, no changes applied", plan)
+		return
+	}
+	applier := newDocumentApplier(inf.Tx.Tx, cdnID, desired)
+	for _, change := range plan.Changes {
+		if err := applier.apply(change); err != nil {
+			userErr, sysErr, errCode := api.ParseDBError(err)
+			if userErr != nil {
+				userErr = errors.New(string(change.Action) + " " + change.Type + " '" + change.Name + "': " + userErr.Error())
+			}
+			if sysErr != nil {
+				sysErr = errors.New("applying cdn document " + string(change.Action) + " " + change.Type + " '" + change.Name + "': " + sysErr.Error())
+			}
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+plan.CDN+", ACTION: Applied CDN document: "+string(change.Action)+" "+change.Type+" "+change.Name, inf.User, inf.Tx.Tx)
+	}
+	if err := applier.setCacheGroupParents(); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Applied "+strconv.Itoa(len(plan.Changes))+" changes to CDN "+plan.CDN, plan)
+}
+
+// documentApplier applies the changes of a plan to a CDN.
+type documentApplier struct {
+	tx    *sql.Tx
+	cdnID int
+	doc   tc.CDNDocument
+	// changedCacheGroups is the created and updated cache groups, whose parents are set after all of them exist.
+	changedCacheGroups []tc.CDNDocCacheGroup
+}
+
+func newDocumentApplier(tx *sql.Tx, cdnID int, doc tc.CDNDocument) *documentApplier {
+	return &documentApplier{tx: tx, cdnID: cdnID, doc: doc}
+}
+
+// apply makes the given change. Database errors are returned unwrapped, so constraint violations can be returned to the user.
+func (a *documentApplier) apply(change tc.CDNPlanChange) error {
+	if change.Action == tc.CDNPlanDelete {
+		return a.delete(change)
+	}
+	create := change.Action == tc.CDNPlanCreate
+	switch change.Type {
+	case cdnType:
+		_, err := a.tx.Exec(`UPDATE cdn SET domain_name = $1, dnssec_enabled = $2 WHERE id = $3`, a.doc.CDN.DomainName, a.doc.CDN.DNSSECEnabled, a.cdnID)
+		return err
+	case cacheGroupType:
+		for _, cg := range a.doc.CacheGroups {
+			if cg.Name == change.Name {
+				a.changedCacheGroups = append(a.changedCacheGroups, cg)
+				return a.upsertCacheGroup(cg, create)
+			}
+		}
+	case profileType:
+		for _, profile := range a.doc.Profiles {
+			if profile.Name == change.Name {
+				return a.upsertProfile(profile, create)
+			}
+		}
+	case serverType:
+		for _, server := range a.doc.Servers {
+			if server.HostName == change.Name {
+				return a.upsertServer(server, create)
+			}
+		}
+	case deliveryServiceType:
+		for _, ds := range a.doc.DeliveryServices {
+			if ds.XMLID == change.Name {
+				return a.upsertDeliveryService(ds, create)
+			}
+		}
+	case originType:
+		for _, origin := range a.doc.Origins {
+			if origin.Name == change.Name {
+				return a.upsertOrigin(origin, create)
+			}
+		}
+	case steeringType:
+		for _, steering := range a.doc.Steering {
+			if steering.DeliveryService == change.Name {
+				return a.setSteeringTargets(steering)
+			}
+		}
+	case federationType:
+		for _, fed := range a.doc.Federations {
+			if fed.CName == change.Name {
+				return a.upsertFederation(fed, create)
+			}
+		}
+	}
+	return errors.New("no " + change.Type + " '" + change.Name + "' in the document")
+}
+
+func (a *documentApplier) delete(change tc.CDNPlanChange) error {
+	switch change.Type {
+	case profileType:
+		_, err := a.tx.Exec(`DELETE FROM profile WHERE name = $1 AND cdn = $2`, change.Name, a.cdnID)
+		return err
+	case serverType:
+		_, err := a.tx.Exec(`DELETE FROM server WHERE host_name = $1 AND cdn_id = $2`, change.Name, a.cdnID)
+		return err
+	case deliveryServiceType:
+		if _, err := a.tx.Exec(`DELETE FROM regex WHERE id IN (SELECT dr.regex FROM deliveryservice_regex AS dr JOIN deliveryservice AS ds ON ds.id = dr.deliveryservice WHERE ds.xml_id = $1 AND ds.cdn_id = $2)`, change.Name, a.cdnID); err != nil {
+			return err
+		}
+		_, err := a.tx.Exec(`DELETE FROM deliveryservice WHERE xml_id = $1 AND cdn_id = $2`, change.Name, a.cdnID)
+		return err
+	case originType:
+		_, err := a.tx.Exec(`DELETE FROM origin WHERE name = $1 AND deliveryservice IN (SELECT id FROM deliveryservice WHERE cdn_id = $2)`, change.Name, a.cdnID)
+		return err
+	case steeringType:
+		_, err := a.tx.Exec(`DELETE FROM steering_target WHERE deliveryservice = (SELECT id FROM deliveryservice WHERE xml_id = $1 AND cdn_id = $2)`, change.Name, a.cdnID)
+		return err
+	case federationType:
+		ids, err := a.federationIDs(change.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := a.tx.Exec(`DELETE FROM federation_deliveryservice WHERE federation = ANY($1::bigint[]) AND deliveryservice IN (SELECT id FROM deliveryservice WHERE cdn_id = $2)`, pq.Array(ids), a.cdnID); err != nil {
+			return err
+		}
+		// federations which still have delivery services in other CDNs are kept
+		_, err = a.tx.Exec(`DELETE FROM federation AS f WHERE f.id = ANY($1::bigint[]) AND NOT EXISTS (SELECT 1 FROM federation_deliveryservice AS fd WHERE fd.federation = f.id)`, pq.Array(ids))
+		return err
+	}
+	return errors.New("can't delete " + change.Type + " '" + change.Name + "'")
+}
+
+func (a *documentApplier) upsertCacheGroup(cg tc.CDNDocCacheGroup, create bool) error {
+	if create {
+		coordinateID := (*int)(nil)
+		if cg.Latitude != nil && cg.Longitude != nil {
+			id := 0
+			if err := a.tx.QueryRow(`INSERT INTO coordinate (name, latitude, longitude) VALUES ($1, $2, $3) RETURNING id`, tc.CachegroupCoordinateNamePrefix+cg.Name, *cg.Latitude, *cg.Longitude).Scan(&id); err != nil {
+				return err
+			}
+			coordinateID = &id
+		}
+		_, err := a.tx.Exec(`INSERT INTO cachegroup (name, short_name, type, coordinate, fallback_to_closest) VALUES ($1, $2, (SELECT id FROM type WHERE name = $3), $4, $5)`, cg.Name, cg.ShortName, cg.Type, coordinateID, cg.FallbackToClosest)
+		return err
+	}
+
+	id := 0
+	coordinateID := sql.NullInt64{}
+	if err := a.tx.QueryRow(`UPDATE cachegroup SET short_name = $2, type = (SELECT id FROM type WHERE name = $3), fallback_to_closest = $4 WHERE name = $1 RETURNING id, coordinate`, cg.Name, cg.ShortName, cg.Type, cg.FallbackToClosest).Scan(&id, &coordinateID); err != nil {
+		return err
+	}
+	switch {
+	case cg.Latitude != nil && cg.Longitude != nil && coordinateID.Valid:
+		_, err := a.tx.Exec(`UPDATE coordinate SET latitude = $1, longitude = $2 WHERE id = $3`, *cg.Latitude, *cg.Longitude, coordinateID.Int64)
+		return err
+	case cg.Latitude != nil && cg.Longitude != nil:
+		_, err := a.tx.Exec(`WITH co AS (INSERT INTO coordinate (name, latitude, longitude) VALUES ($1, $2, $3) RETURNING id) UPDATE cachegroup SET coordinate = (SELECT id FROM co) WHERE id = $4`, tc.CachegroupCoordinateNamePrefix+cg.Name, *cg.Latitude, *cg.Longitude, id)
+		return err
+	case coordinateID.Valid:
+		if _, err := a.tx.Exec(`UPDATE cachegroup SET coordinate = NULL WHERE id = $1`, id); err != nil {
+			return err
+		}
+		_, err := a.tx.Exec(`DELETE FROM coordinate WHERE id = $1`, coordinateID.Int64)
+		return err
+	}
+	return nil
+}
+
+// setCacheGroupParents sets the parents of the created and updated cache groups. Parents are set after all cache groups are created, because they may be created in any order.
+func (a *documentApplier) setCacheGroupParents() error {
+	for _, cg := range a.changedCacheGroups {
+		qry := `
+UPDATE cachegroup SET
+parent_cachegroup_id = (SELECT id FROM cachegroup WHERE name = $2),
+secondary_parent_cachegroup_id = (SELECT id FROM cachegroup WHERE name = $3)
+WHERE name = $1
+`
+		if _, err := a.tx.Exec(qry, cg.Name, cg.ParentCacheGroup, cg.SecondaryParentCacheGroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *documentApplier) upsertProfile(profile tc.CDNDocProfile, create bool) error {
+	id := 0
+	qry := `UPDATE profile SET description = $2, type = $3::profile_type, routing_disabled = $4 WHERE name = $1 AND cdn = $5 RETURNING id`
+	if create {
+		qry = `INSERT INTO profile (name, description, type, routing_disabled, cdn) VALUES ($1, $2, $3::profile_type, $4, $5) RETURNING id`
+	}
+	if err := a.tx.QueryRow(qry, profile.Name, profile.Description, profile.Type, profile.RoutingDisabled, a.cdnID).Scan(&id); err != nil {
+		return err
+	}
+	return a.setProfileParameters(id, profile.Parameters)
+}
+
+// setProfileParameters replaces the parameters of the profile, creating parameters which don't exist. Parameters are shared by profiles, so the secure flag of an existing parameter is changed for all of its profiles.
+func (a *documentApplier) setProfileParameters(profileID int, params []tc.CDNDocParameter) error {
+	if _, err := a.tx.Exec(`DELETE FROM profile_parameter WHERE profile = $1`, profileID); err != nil {
+		return err
+	}
+	ids := []int64{}
+	seen := map[int64]struct{}{}
+	for _, param := range params {
+		id := int64(0)
+		secure := false
+		err := a.tx.QueryRow(`SELECT id, secure FROM parameter WHERE name = $1 AND COALESCE(config_file, '') = $2 AND value = $3`, param.Name, param.ConfigFile, param.Value).Scan(&id, &secure)
+		switch {
+		case err == sql.ErrNoRows:
+			if err := a.tx.QueryRow(`INSERT INTO parameter (name, config_file, value, secure) VALUES ($1, NULLIF($2, ''), $3, $4) RETURNING id`, param.Name, param.ConfigFile, param.Value, param.Secure).Scan(&id); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case secure != param.Secure:
+			if _, err := a.tx.Exec(`UPDATE parameter SET secure = $1 WHERE id = $2`, param.Secure, id); err != nil {
+				return err
+			}
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := a.tx.Exec(`INSERT INTO profile_parameter (profile, parameter) VALUES ($1, unnest($2::bigint[]))`, profileID, pq.Array(ids))
+	return err
+}
+
+func (a *documentApplier) upsertServer(s tc.CDNDocServer, create bool) error {
+	qry := `
+UPDATE server SET
+domain_name = $2,
+cachegroup = (SELECT id FROM cachegroup WHERE name = $3),
+type = (SELECT id FROM type WHERE name = $4),
+profile = (SELECT id FROM profile WHERE name = $5),
+status = (SELECT id FROM status WHERE name = $6),
+phys_location = (SELECT id FROM phys_location WHERE name = $7),
+tcp_port = $8,
+https_port = $9,
+interface_name = $10,
+interface_mtu = $11,
+ip_address = $12,
+ip_netmask = $13,
+ip_gateway = $14,
+ip6_address = $15,
+ip6_gateway = $16,
+rack = $17
+WHERE host_name = $1 AND cdn_id = $18
+`
+	if create {
+		qry = `
+INSERT INTO server (host_name, domain_name, cachegroup, type, profile, status, phys_location, tcp_port, https_port, interface_name, interface_mtu, ip_address, ip_netmask, ip_gateway, ip6_address, ip6_gateway, rack, cdn_id)
+VALUES (
+$1,
+$2,
+(SELECT id FROM cachegroup WHERE name = $3),
+(SELECT id FROM type WHERE name = $4),
+(SELECT id FROM profile WHERE name = $5),
+(SELECT id FROM status WHERE name = $6),
+(SELECT id FROM phys_location WHERE name = $7),
+$8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+`
+	}
+	_, err := a.tx.Exec(qry, s.HostName, s.DomainName, s.CacheGroup, s.Type, s.Profile, s.Status, s.PhysLocation, s.TCPPort, s.HTTPSPort, s.InterfaceName, s.InterfaceMTU, s.IPAddress, s.IPNetmask, s.IPGateway, s.IP6Address, s.IP6Gateway, s.Rack, a.cdnID)
+	return err
+}
+
+func (a *documentApplier) upsertDeliveryService(ds tc.CDNDocDeliveryService, create bool) error {
+	qry := `
+UPDATE deliveryservice SET
+display_name = $2,
+type = (SELECT id FROM type WHERE name = $3),
+tenant_id = (SELECT id FROM tenant WHERE name = $4),
+active = $5,
+profile = (SELECT id FROM profile WHERE name = $6),
+routing_name = $7,
+protocol = $8,
+dscp = $9,
+qstring_ignore = $10,
+geo_limit = $11,
+geo_limit_countries = $12,
+geo_provider = $13,
+miss_lat = $14,
+miss_long = $15,
+ccr_dns_ttl = $16,
+max_dns_answers = $17,
+initial_dispersion = $18,
+ipv6_routing_enabled = $19,
+range_request_handling = $20,
+multi_site_origin = $21,
+logs_enabled = $22,
+regional_geo_blocking = $23,
+anonymous_blocking_enabled = $24,
+deep_caching_type = $25::deep_caching_type,
+edge_header_rewrite = $26,
+mid_header_rewrite = $27,
+regex_remap = $28,
+remap_text = $29,
+check_path = $30,
+info_url = $31,
+long_desc = $32
+WHERE xml_id = $1 AND cdn_id = $33
+RETURNING id
+`
+	if create {
+		qry = `
+INSERT INTO deliveryservice (xml_id, display_name, type, tenant_id, active, profile, routing_name, protocol, dscp, qstring_ignore, geo_limit, geo_limit_countries, geo_provider, miss_lat, miss_long, ccr_dns_ttl, max_dns_answers, initial_dispersion, ipv6_routing_enabled, range_request_handling, multi_site_origin, logs_enabled, regional_geo_blocking, anonymous_blocking_enabled, deep_caching_type, edge_header_rewrite, mid_header_rewrite, regex_remap, remap_text, check_path, info_url, long_desc, cdn_id)
+VALUES (
+$1,
+$2,
+(SELECT id FROM type WHERE name = $3),
+(SELECT id FROM tenant WHERE name = $4),
+$5,
+(SELECT id FROM profile WHERE name = $6),
+$7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+$25::deep_caching_type,
+$26, $27, $28, $29, $30, $31, $32, $33)
+RETURNING id
+`
+	}
+	id := 0
+	if err := a.tx.QueryRow(qry, ds.XMLID, ds.DisplayName, ds.Type, ds.Tenant, ds.Active, ds.Profile, ds.RoutingName, ds.Protocol, ds.DSCP, ds.QStringIgnore, ds.GeoLimit, ds.GeoLimitCountries, ds.GeoProvider, ds.MissLat, ds.MissLong, ds.CCRDNSTTL, ds.MaxDNSAnswers, ds.InitialDispersion, ds.IPV6RoutingEnabled, ds.RangeRequestHandling, ds.MultiSiteOrigin, ds.LogsEnabled, ds.RegionalGeoBlocking, ds.AnonymousBlockingEnabled, ds.DeepCachingType, ds.EdgeHeaderRewrite, ds.MidHeaderRewrite, ds.RegexRemap, ds.RemapText, ds.CheckPath, ds.InfoURL, ds.LongDesc, a.cdnID).Scan(&id); err != nil {
+		return err
+	}
+
+	if _, err := a.tx.Exec(`DELETE FROM regex WHERE id IN (SELECT regex FROM deliveryservice_regex WHERE deliveryservice = $1)`, id); err != nil {
+		return err
+	}
+	for _, re := range ds.Regexes {
+		if _, err := a.tx.Exec(`WITH r AS (INSERT INTO regex (pattern, type) VALUES ($2, (SELECT id FROM type WHERE name = $3)) RETURNING id) INSERT INTO deliveryservice_regex (deliveryservice, regex, set_number) SELECT $1, r.id, $4 FROM r`, id, re.Pattern, re.Type, re.SetNumber); err != nil {
+			return err
+		}
+	}
+
+	if _, err := a.tx.Exec(`DELETE FROM deliveryservice_server WHERE deliveryservice = $1`, id); err != nil {
+		return err
+	}
+	_, err := a.tx.Exec(`INSERT INTO deliveryservice_server (deliveryservice, server) SELECT $1, id FROM server WHERE cdn_id = $2 AND host_name = ANY($3::text[])`, id, a.cdnID, pq.Array(ds.Servers))
+	return err
+}
+
+func (a *documentApplier) upsertOrigin(o tc.CDNDocOrigin, create bool) error {
+	qry := `
+UPDATE origin SET
+deliveryservice = (SELECT id FROM deliveryservice WHERE xml_id = $2 AND cdn_id = $12),
+tenant = (SELECT id FROM tenant WHERE name = $3),
+fqdn = $4,
+protocol = $5::origin_protocol,
+port = $6,
+is_primary = $7,
+ip_address = $8,
+ip6_address = $9,
+cachegroup = (SELECT id FROM cachegroup WHERE name = $10),
+profile = (SELECT id FROM profile WHERE name = $11)
+WHERE name = $1 AND deliveryservice IN (SELECT id FROM deliveryservice WHERE cdn_id = $12)
+`
+	if create {
+		qry = `
+INSERT INTO origin (name, deliveryservice, tenant, fqdn, protocol, port, is_primary, ip_address, ip6_address, cachegroup, profile)
+SELECT
+$1,
+(SELECT id FROM deliveryservice WHERE xml_id = $2 AND cdn_id = $12),
+(SELECT id FROM tenant WHERE name = $3),
+$4,
+$5::origin_protocol,
+$6, $7, $8, $9,
+(SELECT id FROM cachegroup WHERE name = $10),
+(SELECT id FROM profile WHERE name = $11)
+`
+	}
+	_, err := a.tx.Exec(qry, o.Name, o.DeliveryService, o.Tenant, o.FQDN, o.Protocol, o.Port, o.IsPrimary, o.IPAddress, o.IP6Address, o.CacheGroup, o.Profile, a.cdnID)
+	return err
+}
+
+func (a *documentApplier) setSteeringTargets(steering tc.CDNDocSteering) error {
+	if _, err := a.tx.Exec(`DELETE FROM steering_target WHERE deliveryservice = (SELECT id FROM deliveryservice WHERE xml_id = $1 AND cdn_id = $2)`, steering.DeliveryService, a.cdnID); err != nil {
+		return err
+	}
+	qry := `
+INSERT INTO steering_target (deliveryservice, target, type, value) VALUES (
+(SELECT id FROM deliveryservice WHERE xml_id = $1 AND cdn_id = $5),
+(SELECT id FROM deliveryservice WHERE xml_id = $2 AND cdn_id = $5),
+(SELECT id FROM type WHERE name = $3),
+$4)
+`
+	for _, target := range steering.Targets {
+		if _, err := a.tx.Exec(qry, steering.DeliveryService, target.DeliveryService, target.Type, target.Value, a.cdnID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// federationIDs returns the IDs of the federations with the given CNAME and delivery services in the CDN.
+func (a *documentApplier) federationIDs(cname string) ([]int64, error) {
+	ids := []int64{}
+	qry := `
+SELECT ARRAY(
+	SELECT DISTINCT f.id
+	FROM federation AS f
+	JOIN federation_deliveryservice AS fd ON fd.federation = f.id
+	JOIN deliveryservice AS ds ON ds.id = fd.deliveryservice
+	WHERE f.cname = $1 AND ds.cdn_id = $2
+	ORDER BY f.id
+)
+`
+	if err := a.tx.QueryRow(qry, cname, a.cdnID).Scan(pq.Array(&ids)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (a *documentApplier) upsertFederation(fed tc.CDNDocFederation, create bool) error {
+	ids := []int64{}
+	if create {
+		id := int64(0)
+		if err := a.tx.QueryRow(`INSERT INTO federation (cname, ttl, description) VALUES ($1, $2, $3) RETURNING id`, fed.CName, fed.TTL, fed.Description).Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	} else {
+		existing, err := a.federationIDs(fed.CName)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return errors.New("federation not found")
+		}
+		ids = existing
+		if _, err := a.tx.Exec(`UPDATE federation SET ttl = $2, description = $3 WHERE id = ANY($1::bigint[])`, pq.Array(ids), fed.TTL, fed.Description); err != nil {
+			return err
+		}
+		if _, err := a.tx.Exec(`DELETE FROM federation_deliveryservice WHERE federation = ANY($1::bigint[]) AND deliveryservice IN (SELECT id FROM deliveryservice WHERE cdn_id = $2)`, pq.Array(ids), a.cdnID); err != nil {
+			return err
+		}
+	}
+	// federations with the same CNAME in the CDN are exported as one, so their delivery services are assigned to the first
+	_, err := a.tx.Exec(`INSERT INTO federation_deliveryservice (federation, deliveryservice) SELECT $1, id FROM deliveryservice WHERE cdn_id = $2 AND xml_id = ANY($3::text[])`, ids[0], a.cdnID, pq.Array(fed.DeliveryServices))
+	return err
+}
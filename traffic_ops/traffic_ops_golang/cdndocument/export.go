@@ -0,0 +1,363 @@
+package cdndocument
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+// ExportHandler exports the CDN as a document, which can be kept in version control and planned and applied with PlanHandler and ApplyHandler.
+func ExportHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	cdnName := inf.Params["name"]
+	doc, exists, err := readDocument(inf.Tx.Tx, cdnName)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("exporting cdn document: "+err.Error()))
+		return
+	}
+	if !exists {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("cdn not found"), nil)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%v.json\"", cdnName))
+	api.WriteRespRaw(w, r, doc)
+}
+
+// readDocument returns the live document of the given CDN, normalized so it can be compared to other documents, and whether the CDN exists.
+func readDocument(tx *sql.Tx, cdnName string) (tc.CDNDocument, bool, error) {
+	doc := tc.CDNDocument{}
+	cdnID := 0
+	if err := tx.QueryRow(`SELECT id, name, domain_name, dnssec_enabled FROM cdn WHERE name = $1`, cdnName).Scan(&cdnID, &doc.CDN.Name, &doc.CDN.DomainName, &doc.CDN.DNSSECEnabled); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.CDNDocument{}, false, nil
+		}
+		return tc.CDNDocument{}, false, errors.New("querying cdn: " + err.Error())
+	}
+	readers := []func(*sql.Tx, int, *tc.CDNDocument) error{
+		readCacheGroups,
+		readProfiles,
+		readServers,
+		readDeliveryServices,
+		readOrigins,
+		readSteering,
+		readFederations,
+	}
+	for _, read := range readers {
+		if err := read(tx, cdnID, &doc); err != nil {
+			return tc.CDNDocument{}, false, err
+		}
+	}
+	normalizeDocument(&doc)
+	return doc, true, nil
+}
+
+// cdnCacheGroupsQuery is the recursive cgs query of the IDs of the cache groups of servers and origins whose CDN matches the given condition on $1, and their parents.
+func cdnCacheGroupsQuery(cdnCondition string) string {
+	return `
+WITH RECURSIVE cgs AS (
+	SELECT s.cachegroup AS id FROM server AS s WHERE s.cdn_id ` + cdnCondition + `
+	UNION
+	SELECT o.cachegroup FROM origin AS o JOIN deliveryservice AS ds ON ds.id = o.deliveryservice WHERE ds.cdn_id ` + cdnCondition + ` AND o.cachegroup IS NOT NULL
+	UNION
+	SELECT p.id FROM cgs JOIN cachegroup AS c ON c.id = cgs.id JOIN cachegroup AS p ON p.id = c.parent_cachegroup_id OR p.id = c.secondary_parent_cachegroup_id
+)
+`
+}
+
+// selectCacheGroupsQuery selects the document cache groups of the IDs in cgs, which the query must be preceded by.
+const selectCacheGroupsQuery = `
+SELECT c.name, c.short_name, t.name, co.latitude, co.longitude, p.name, sp.name, COALESCE(c.fallback_to_closest, false)
+FROM cachegroup AS c
+JOIN type AS t ON t.id = c.type
+LEFT JOIN coordinate AS co ON co.id = c.coordinate
+LEFT JOIN cachegroup AS p ON p.id = c.parent_cachegroup_id
+LEFT JOIN cachegroup AS sp ON sp.id = c.secondary_parent_cachegroup_id
+WHERE c.id IN (SELECT id FROM cgs)
+`
+
+// readCacheGroups reads the cache groups of the CDN's servers and origins, and their parents.
+func readCacheGroups(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	cgs, err := queryCacheGroups(tx, cdnCacheGroupsQuery("= $1")+selectCacheGroupsQuery, cdnID)
+	if err != nil {
+		return err
+	}
+	doc.CacheGroups = append(doc.CacheGroups, cgs...)
+	return nil
+}
+
+// queryCacheGroups returns the document cache groups selected by the given selectCacheGroupsQuery.
+func queryCacheGroups(tx *sql.Tx, qry string, args ...interface{}) ([]tc.CDNDocCacheGroup, error) {
+	rows, err := tx.Query(qry, args...)
+	if err != nil {
+		return nil, errors.New("querying cache groups: " + err.Error())
+	}
+	defer rows.Close()
+	cgs := []tc.CDNDocCacheGroup{}
+	for rows.Next() {
+		cg := tc.CDNDocCacheGroup{}
+		if err := rows.Scan(&cg.Name, &cg.ShortName, &cg.Type, &cg.Latitude, &cg.Longitude, &cg.ParentCacheGroup, &cg.SecondaryParentCacheGroup, &cg.FallbackToClosest); err != nil {
+			return nil, errors.New("scanning cache groups: " + err.Error())
+		}
+		cgs = append(cgs, cg)
+	}
+	return cgs, rows.Err()
+}
+
+func readProfiles(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT p.name, COALESCE(p.description, ''), p.type, p.routing_disabled, pa.name, COALESCE(pa.config_file, ''), pa.value, pa.secure
+FROM profile AS p
+LEFT JOIN profile_parameter AS pp ON pp.profile = p.id
+LEFT JOIN parameter AS pa ON pa.id = pp.parameter
+WHERE p.cdn = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying profiles: " + err.Error())
+	}
+	defer rows.Close()
+	profiles := map[string]*tc.CDNDocProfile{}
+	names := []string{}
+	for rows.Next() {
+		profile := tc.CDNDocProfile{}
+		paramName, paramConfigFile, paramValue, paramSecure := sql.NullString{}, sql.NullString{}, sql.NullString{}, sql.NullBool{}
+		if err := rows.Scan(&profile.Name, &profile.Description, &profile.Type, &profile.RoutingDisabled, &paramName, &paramConfigFile, &paramValue, &paramSecure); err != nil {
+			return errors.New("scanning profiles: " + err.Error())
+		}
+		if _, ok := profiles[profile.Name]; !ok {
+			profiles[profile.Name] = &profile
+			names = append(names, profile.Name)
+		}
+		if paramName.Valid {
+			profiles[profile.Name].Parameters = append(profiles[profile.Name].Parameters, tc.CDNDocParameter{Name: paramName.String, ConfigFile: paramConfigFile.String, Value: paramValue.String, Secure: paramSecure.Bool})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating profiles: " + err.Error())
+	}
+	for _, name := range names {
+		doc.Profiles = append(doc.Profiles, *profiles[name])
+	}
+	return nil
+}
+
+func readServers(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT s.host_name, s.domain_name, cg.name, t.name, p.name, st.name, pl.name, s.tcp_port, s.https_port, s.interface_name, s.interface_mtu, s.ip_address, s.ip_netmask, s.ip_gateway, s.ip6_address, s.ip6_gateway, s.rack
+FROM server AS s
+JOIN cachegroup AS cg ON cg.id = s.cachegroup
+JOIN type AS t ON t.id = s.type
+JOIN profile AS p ON p.id = s.profile
+JOIN status AS st ON st.id = s.status
+JOIN phys_location AS pl ON pl.id = s.phys_location
+WHERE s.cdn_id = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying servers: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s := tc.CDNDocServer{}
+		if err := rows.Scan(&s.HostName, &s.DomainName, &s.CacheGroup, &s.Type, &s.Profile, &s.Status, &s.PhysLocation, &s.TCPPort, &s.HTTPSPort, &s.InterfaceName, &s.InterfaceMTU, &s.IPAddress, &s.IPNetmask, &s.IPGateway, &s.IP6Address, &s.IP6Gateway, &s.Rack); err != nil {
+			return errors.New("scanning servers: " + err.Error())
+		}
+		doc.Servers = append(doc.Servers, s)
+	}
+	return rows.Err()
+}
+
+func readDeliveryServices(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT ds.xml_id, ds.display_name, t.name, te.name, ds.active, p.name, ds.routing_name, ds.protocol, ds.dscp, ds.qstring_ignore, ds.geo_limit, ds.geo_limit_countries, ds.geo_provider, ds.miss_lat, ds.miss_long, ds.ccr_dns_ttl, ds.max_dns_answers, ds.initial_dispersion, COALESCE(ds.ipv6_routing_enabled, false), ds.range_request_handling, COALESCE(ds.multi_site_origin, false), COALESCE(ds.logs_enabled, false), ds.regional_geo_blocking, ds.anonymous_blocking_enabled, ds.deep_caching_type, ds.edge_header_rewrite, ds.mid_header_rewrite, ds.regex_remap, ds.remap_text, ds.check_path, ds.info_url, ds.long_desc
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN tenant AS te ON te.id = ds.tenant_id
+LEFT JOIN profile AS p ON p.id = ds.profile
+WHERE ds.cdn_id = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying delivery services: " + err.Error())
+	}
+	defer rows.Close()
+	dsIdx := map[string]int{}
+	for rows.Next() {
+		ds := tc.CDNDocDeliveryService{}
+		if err := rows.Scan(&ds.XMLID, &ds.DisplayName, &ds.Type, &ds.Tenant, &ds.Active, &ds.Profile, &ds.RoutingName, &ds.Protocol, &ds.DSCP, &ds.QStringIgnore, &ds.GeoLimit, &ds.GeoLimitCountries, &ds.GeoProvider, &ds.MissLat, &ds.MissLong, &ds.CCRDNSTTL, &ds.MaxDNSAnswers, &ds.InitialDispersion, &ds.IPV6RoutingEnabled, &ds.RangeRequestHandling, &ds.MultiSiteOrigin, &ds.LogsEnabled, &ds.RegionalGeoBlocking, &ds.AnonymousBlockingEnabled, &ds.DeepCachingType, &ds.EdgeHeaderRewrite, &ds.MidHeaderRewrite, &ds.RegexRemap, &ds.RemapText, &ds.CheckPath, &ds.InfoURL, &ds.LongDesc); err != nil {
+			return errors.New("scanning delivery services: " + err.Error())
+		}
+		dsIdx[ds.XMLID] = len(doc.DeliveryServices)
+		doc.DeliveryServices = append(doc.DeliveryServices, ds)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("iterating delivery services: " + err.Error())
+	}
+
+	regexQry := `
+SELECT ds.xml_id, t.name, r.pattern, COALESCE(dr.set_number, 0)
+FROM deliveryservice_regex AS dr
+JOIN deliveryservice AS ds ON ds.id = dr.deliveryservice
+JOIN regex AS r ON r.id = dr.regex
+JOIN type AS t ON t.id = r.type
+WHERE ds.cdn_id = $1
+`
+	regexRows, err := tx.Query(regexQry, cdnID)
+	if err != nil {
+		return errors.New("querying delivery service regexes: " + err.Error())
+	}
+	defer regexRows.Close()
+	for regexRows.Next() {
+		xmlID := ""
+		re := tc.CDNDocDSRegex{}
+		if err := regexRows.Scan(&xmlID, &re.Type, &re.Pattern, &re.SetNumber); err != nil {
+			return errors.New("scanning delivery service regexes: " + err.Error())
+		}
+		if i, ok := dsIdx[xmlID]; ok {
+			doc.DeliveryServices[i].Regexes = append(doc.DeliveryServices[i].Regexes, re)
+		}
+	}
+	if err := regexRows.Err(); err != nil {
+		return errors.New("iterating delivery service regexes: " + err.Error())
+	}
+
+	serverQry := `
+SELECT ds.xml_id, s.host_name
+FROM deliveryservice_server AS dss
+JOIN deliveryservice AS ds ON ds.id = dss.deliveryservice
+JOIN server AS s ON s.id = dss.server
+WHERE ds.cdn_id = $1 AND s.cdn_id = $1
+`
+	serverRows, err := tx.Query(serverQry, cdnID)
+	if err != nil {
+		return errors.New("querying delivery service servers: " + err.Error())
+	}
+	defer serverRows.Close()
+	for serverRows.Next() {
+		xmlID, hostName := "", ""
+		if err := serverRows.Scan(&xmlID, &hostName); err != nil {
+			return errors.New("scanning delivery service servers: " + err.Error())
+		}
+		if i, ok := dsIdx[xmlID]; ok {
+			doc.DeliveryServices[i].Servers = append(doc.DeliveryServices[i].Servers, hostName)
+		}
+	}
+	return serverRows.Err()
+}
+
+func readOrigins(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT o.name, ds.xml_id, te.name, o.fqdn, o.protocol::text, o.port, o.is_primary, o.ip_address, o.ip6_address, cg.name, p.name
+FROM origin AS o
+JOIN deliveryservice AS ds ON ds.id = o.deliveryservice
+JOIN tenant AS te ON te.id = o.tenant
+LEFT JOIN cachegroup AS cg ON cg.id = o.cachegroup
+LEFT JOIN profile AS p ON p.id = o.profile
+WHERE ds.cdn_id = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying origins: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		o := tc.CDNDocOrigin{}
+		if err := rows.Scan(&o.Name, &o.DeliveryService, &o.Tenant, &o.FQDN, &o.Protocol, &o.Port, &o.IsPrimary, &o.IPAddress, &o.IP6Address, &o.CacheGroup, &o.Profile); err != nil {
+			return errors.New("scanning origins: " + err.Error())
+		}
+		doc.Origins = append(doc.Origins, o)
+	}
+	return rows.Err()
+}
+
+func readSteering(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT ds.xml_id, target.xml_id, t.name, st.value
+FROM steering_target AS st
+JOIN deliveryservice AS ds ON ds.id = st.deliveryservice
+JOIN deliveryservice AS target ON target.id = st.target
+JOIN type AS t ON t.id = st.type
+WHERE ds.cdn_id = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying steering targets: " + err.Error())
+	}
+	defer rows.Close()
+	steeringIdx := map[string]int{}
+	for rows.Next() {
+		xmlID := ""
+		target := tc.CDNDocSteeringTarget{}
+		if err := rows.Scan(&xmlID, &target.DeliveryService, &target.Type, &target.Value); err != nil {
+			return errors.New("scanning steering targets: " + err.Error())
+		}
+		i, ok := steeringIdx[xmlID]
+		if !ok {
+			i = len(doc.Steering)
+			steeringIdx[xmlID] = i
+			doc.Steering = append(doc.Steering, tc.CDNDocSteering{DeliveryService: xmlID})
+		}
+		doc.Steering[i].Targets = append(doc.Steering[i].Targets, target)
+	}
+	return rows.Err()
+}
+
+// readFederations reads the federations of the CDN's delivery services. A federation is part of a CDN if any of its delivery services are.
+func readFederations(tx *sql.Tx, cdnID int, doc *tc.CDNDocument) error {
+	qry := `
+SELECT f.cname, f.ttl, f.description, ds.xml_id
+FROM federation AS f
+JOIN federation_deliveryservice AS fd ON fd.federation = f.id
+JOIN deliveryservice AS ds ON ds.id = fd.deliveryservice
+WHERE ds.cdn_id = $1
+`
+	rows, err := tx.Query(qry, cdnID)
+	if err != nil {
+		return errors.New("querying federations: " + err.Error())
+	}
+	defer rows.Close()
+	fedIdx := map[string]int{}
+	for rows.Next() {
+		fed := tc.CDNDocFederation{}
+		xmlID := ""
+		if err := rows.Scan(&fed.CName, &fed.TTL, &fed.Description, &xmlID); err != nil {
+			return errors.New("scanning federations: " + err.Error())
+		}
+		i, ok := fedIdx[fed.CName]
+		if !ok {
+			i = len(doc.Federations)
+			fedIdx[fed.CName] = i
+			doc.Federations = append(doc.Federations, fed)
+		}
+		doc.Federations[i].DeliveryServices = append(doc.Federations[i].DeliveryServices, xmlID)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,64 @@
+package cdndocument
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReadProfiles(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	rows := sqlmock.NewRows([]string{"name", "description", "type", "routing_disabled", "param_name", "param_config_file", "param_value", "param_secure"})
+	rows = rows.AddRow("EDGE", "edge", "ATS_PROFILE", false, "location", "remap.config", "/etc", false)
+	rows = rows.AddRow("EDGE", "edge", "ATS_PROFILE", false, "secret", "", "s3cr3t", true)
+	rows = rows.AddRow("EMPTY", "", "ATS_PROFILE", true, nil, nil, nil, nil)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	doc := tc.CDNDocument{}
+	if err := readProfiles(tx, 1, &doc); err != nil {
+		t.Fatalf("readProfiles expected nil error, actual: %v", err)
+	}
+	tx.Commit()
+
+	if len(doc.Profiles) != 2 {
+		t.Fatalf("readProfiles expected 2 profiles, actual: %+v", doc.Profiles)
+	}
+	if len(doc.Profiles[0].Parameters) != 2 || !doc.Profiles[0].Parameters[1].Secure {
+		t.Errorf("readProfiles expected 2 parameters of profile EDGE, the second secure, actual: %+v", doc.Profiles[0].Parameters)
+	}
+	if len(doc.Profiles[1].Parameters) != 0 || !doc.Profiles[1].RoutingDisabled {
+		t.Errorf("readProfiles expected routing disabled profile EMPTY without parameters, actual: %+v", doc.Profiles[1])
+	}
+}
@@ -0,0 +1,498 @@
+package cdndocument
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/lib/pq"
+)
+
+// The types of objects in plan changes.
+const (
+	cdnType             = "cdn"
+	cacheGroupType      = "cacheGroup"
+	profileType         = "profile"
+	serverType          = "server"
+	deliveryServiceType = "deliveryService"
+	originType          = "origin"
+	steeringType        = "steering"
+	federationType      = "federation"
+)
+
+// PlanHandler returns the changes applying the requested document to the CDN would make, without making them.
+func PlanHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	_, _, plan, userErr, sysErr, errCode := planRequest(inf, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteResp(w, r, plan)
+}
+
+// planRequest reads the requested document and the live CDN, and returns the CDN's ID, the normalized requested document, and the plan to apply it.
+func planRequest(inf *api.APIInfo, r *http.Request) (int, tc.CDNDocument, tc.CDNPlan, error, error, int) {
+	cdnName := inf.Params["name"]
+	desired := tc.CDNDocument{}
+	if err := json.NewDecoder(r.Body).Decode(&desired); err != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, errors.New("malformed JSON: " + err.Error()), nil, http.StatusBadRequest
+	}
+	if desired.CDN.Name != cdnName {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, errors.New("the document is for cdn '" + desired.CDN.Name + "', not '" + cdnName + "'"), nil, http.StatusBadRequest
+	}
+	if err := desired.Validate(); err != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, errors.New("invalid document: " + err.Error()), nil, http.StatusBadRequest
+	}
+	normalizeDocument(&desired)
+
+	cdnID, ok, err := getCDNID(inf.Tx.Tx, cdnName)
+	if err != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, nil, errors.New("getting cdn: " + err.Error()), http.StatusInternalServerError
+	}
+	if !ok {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, errors.New("cdn not found"), nil, http.StatusNotFound
+	}
+	live, _, err := readDocument(inf.Tx.Tx, cdnName)
+	if err != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, nil, errors.New("reading cdn document: " + err.Error()), http.StatusInternalServerError
+	}
+	if userErr, sysErr, errCode := checkReferences(inf.Tx.Tx, desired); userErr != nil || sysErr != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, userErr, sysErr, errCode
+	}
+	if userErr, sysErr, errCode := checkTenancy(inf.Tx.Tx, inf.User, live, desired); userErr != nil || sysErr != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, userErr, sysErr, errCode
+	}
+	if err := addExistingCacheGroups(inf.Tx.Tx, &live, desired); err != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, nil, errors.New("reading existing cache groups: " + err.Error()), http.StatusInternalServerError
+	}
+	plan := makePlan(live, desired)
+	if userErr, sysErr, errCode := checkSharedCacheGroups(inf.Tx.Tx, cdnID, plan); userErr != nil || sysErr != nil {
+		return 0, tc.CDNDocument{}, tc.CDNPlan{}, userErr, sysErr, errCode
+	}
+	return cdnID, desired, plan, nil, nil, http.StatusOK
+}
+
+// addExistingCacheGroups adds the desired cache groups which exist, but which the live CDN doesn't use yet, to the live document. Cache group names are global, so these are updated if they differ, rather than created.
+func addExistingCacheGroups(tx *sql.Tx, live *tc.CDNDocument, desired tc.CDNDocument) error {
+	liveNames := map[string]struct{}{}
+	for _, cg := range live.CacheGroups {
+		liveNames[cg.Name] = struct{}{}
+	}
+	names := []string{}
+	for _, cg := range desired.CacheGroups {
+		if _, ok := liveNames[cg.Name]; !ok {
+			names = append(names, cg.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	cgs, err := queryCacheGroups(tx, `WITH cgs AS (SELECT id FROM cachegroup WHERE name = ANY($1::text[]))`+selectCacheGroupsQuery, pq.Array(names))
+	if err != nil {
+		return err
+	}
+	live.CacheGroups = append(live.CacheGroups, cgs...)
+	sort.Slice(live.CacheGroups, func(i, j int) bool { return live.CacheGroups[i].Name < live.CacheGroups[j].Name })
+	return nil
+}
+
+// checkSharedCacheGroups returns a user error if the plan updates cache groups which other CDNs use, so applying the document of one CDN never changes another. Such cache groups must be changed with the cache group API.
+func checkSharedCacheGroups(tx *sql.Tx, cdnID int, plan tc.CDNPlan) (error, error, int) {
+	names := []string{}
+	for _, change := range plan.Changes {
+		if change.Type == cacheGroupType && change.Action == tc.CDNPlanUpdate {
+			names = append(names, change.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil, http.StatusOK
+	}
+	shared := []string{}
+	qry := cdnCacheGroupsQuery("<> $1") + `SELECT ARRAY(SELECT c.name FROM cachegroup AS c WHERE c.id IN (SELECT id FROM cgs) AND c.name = ANY($2::text[]) ORDER BY c.name)`
+	if err := tx.QueryRow(qry, cdnID, pq.Array(names)).Scan(pq.Array(&shared)); err != nil {
+		return nil, errors.New("checking cache groups of other cdns: " + err.Error()), http.StatusInternalServerError
+	}
+	if len(shared) > 0 {
+		return errors.New("the document changes cache groups which other cdns use, which must be changed with the cache group API: " + strings.Join(shared, ", ")), nil, http.StatusBadRequest
+	}
+	return nil, nil, http.StatusOK
+}
+
+func getCDNID(tx *sql.Tx, name string) (int, bool, error) {
+	id := 0
+	if err := tx.QueryRow(`SELECT id FROM cdn WHERE name = $1`, name).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// normalizeDocument sorts the document's objects and replaces null lists with empty ones and default values with their names, so documents can be compared.
+func normalizeDocument(doc *tc.CDNDocument) {
+	if doc.CacheGroups == nil {
+		doc.CacheGroups = []tc.CDNDocCacheGroup{}
+	}
+	sort.Slice(doc.CacheGroups, func(i, j int) bool { return doc.CacheGroups[i].Name < doc.CacheGroups[j].Name })
+
+	if doc.Profiles == nil {
+		doc.Profiles = []tc.CDNDocProfile{}
+	}
+	sort.Slice(doc.Profiles, func(i, j int) bool { return doc.Profiles[i].Name < doc.Profiles[j].Name })
+	for i := range doc.Profiles {
+		params := doc.Profiles[i].Parameters
+		if params == nil {
+			params = []tc.CDNDocParameter{}
+		}
+		sort.Slice(params, func(i, j int) bool {
+			if params[i].ConfigFile != params[j].ConfigFile {
+				return params[i].ConfigFile < params[j].ConfigFile
+			}
+			if params[i].Name != params[j].Name {
+				return params[i].Name < params[j].Name
+			}
+			return params[i].Value < params[j].Value
+		})
+		doc.Profiles[i].Parameters = params
+	}
+
+	if doc.Servers == nil {
+		doc.Servers = []tc.CDNDocServer{}
+	}
+	sort.Slice(doc.Servers, func(i, j int) bool { return doc.Servers[i].HostName < doc.Servers[j].HostName })
+
+	if doc.DeliveryServices == nil {
+		doc.DeliveryServices = []tc.CDNDocDeliveryService{}
+	}
+	sort.Slice(doc.DeliveryServices, func(i, j int) bool { return doc.DeliveryServices[i].XMLID < doc.DeliveryServices[j].XMLID })
+	for i := range doc.DeliveryServices {
+		ds := &doc.DeliveryServices[i]
+		ds.DeepCachingType = tc.DeepCachingTypeFromString(ds.DeepCachingType).String()
+		if ds.Regexes == nil {
+			ds.Regexes = []tc.CDNDocDSRegex{}
+		}
+		regexes := ds.Regexes
+		sort.Slice(regexes, func(i, j int) bool {
+			if regexes[i].SetNumber != regexes[j].SetNumber {
+				return regexes[i].SetNumber < regexes[j].SetNumber
+			}
+			if regexes[i].Type != regexes[j].Type {
+				return regexes[i].Type < regexes[j].Type
+			}
+			return regexes[i].Pattern < regexes[j].Pattern
+		})
+		if ds.Servers == nil {
+			ds.Servers = []string{}
+		}
+		sort.Strings(ds.Servers)
+	}
+
+	if doc.Origins == nil {
+		doc.Origins = []tc.CDNDocOrigin{}
+	}
+	sort.Slice(doc.Origins, func(i, j int) bool { return doc.Origins[i].Name < doc.Origins[j].Name })
+
+	if doc.Steering == nil {
+		doc.Steering = []tc.CDNDocSteering{}
+	}
+	sort.Slice(doc.Steering, func(i, j int) bool { return doc.Steering[i].DeliveryService < doc.Steering[j].DeliveryService })
+	for i := range doc.Steering {
+		targets := doc.Steering[i].Targets
+		if targets == nil {
+			targets = []tc.CDNDocSteeringTarget{}
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i].DeliveryService < targets[j].DeliveryService })
+		doc.Steering[i].Targets = targets
+	}
+
+	if doc.Federations == nil {
+		doc.Federations = []tc.CDNDocFederation{}
+	}
+	sort.Slice(doc.Federations, func(i, j int) bool { return doc.Federations[i].CName < doc.Federations[j].CName })
+	for i := range doc.Federations {
+		if doc.Federations[i].DeliveryServices == nil {
+			doc.Federations[i].DeliveryServices = []string{}
+		}
+		sort.Strings(doc.Federations[i].DeliveryServices)
+	}
+}
+
+// keyedObject is a document object and the name which identifies it.
+type keyedObject struct {
+	key string
+	obj interface{}
+}
+
+// objectKind is the live and desired objects of one type of a document.
+type objectKind struct {
+	typ     string
+	live    []keyedObject
+	desired []keyedObject
+	// keep is whether live objects missing from the desired document are kept, rather than deleted.
+	keep bool
+}
+
+// makePlan returns the changes to make the live document match the desired document. Both must be normalized.
+// Creates and updates are in dependency order, followed by deletes in reverse dependency order, so applying the changes in order never refers to a missing object.
+func makePlan(live tc.CDNDocument, desired tc.CDNDocument) tc.CDNPlan {
+	plan := tc.CDNPlan{CDN: desired.CDN.Name, Changes: []tc.CDNPlanChange{}}
+	if fields := changedFields(live.CDN, desired.CDN); len(fields) > 0 {
+		plan.Changes = append(plan.Changes, tc.CDNPlanChange{Action: tc.CDNPlanUpdate, Type: cdnType, Name: desired.CDN.Name, Fields: fields})
+	}
+
+	kinds := []objectKind{
+		{typ: cacheGroupType, live: cacheGroupObjects(live), desired: cacheGroupObjects(desired), keep: true},
+		{typ: profileType, live: profileObjects(live), desired: profileObjects(desired)},
+		{typ: serverType, live: serverObjects(live), desired: serverObjects(desired)},
+		{typ: deliveryServiceType, live: deliveryServiceObjects(live), desired: deliveryServiceObjects(desired)},
+		{typ: originType, live: originObjects(live), desired: originObjects(desired)},
+		{typ: steeringType, live: steeringObjects(live), desired: steeringObjects(desired)},
+		{typ: federationType, live: federationObjects(live), desired: federationObjects(desired)},
+	}
+	deletes := [][]tc.CDNPlanChange{}
+	for _, kind := range kinds {
+		liveObjs := map[string]interface{}{}
+		for _, obj := range kind.live {
+			liveObjs[obj.key] = obj.obj
+		}
+		desiredKeys := map[string]struct{}{}
+		for _, obj := range kind.desired {
+			desiredKeys[obj.key] = struct{}{}
+			liveObj, ok := liveObjs[obj.key]
+			if !ok {
+				plan.Changes = append(plan.Changes, tc.CDNPlanChange{Action: tc.CDNPlanCreate, Type: kind.typ, Name: obj.key})
+				continue
+			}
+			if fields := changedFields(liveObj, obj.obj); len(fields) > 0 {
+				plan.Changes = append(plan.Changes, tc.CDNPlanChange{Action: tc.CDNPlanUpdate, Type: kind.typ, Name: obj.key, Fields: fields})
+			}
+		}
+		kindDeletes := []tc.CDNPlanChange{}
+		if !kind.keep {
+			for _, obj := range kind.live {
+				if _, ok := desiredKeys[obj.key]; !ok {
+					kindDeletes = append(kindDeletes, tc.CDNPlanChange{Action: tc.CDNPlanDelete, Type: kind.typ, Name: obj.key})
+				}
+			}
+		}
+		deletes = append(deletes, kindDeletes)
+	}
+	for i := len(deletes) - 1; i >= 0; i-- {
+		plan.Changes = append(plan.Changes, deletes[i]...)
+	}
+	return plan
+}
+
+// changedFields returns the JSON names of the fields which differ between two structs of the same type.
+func changedFields(live interface{}, desired interface{}) []string {
+	liveVal := reflect.ValueOf(live)
+	desiredVal := reflect.ValueOf(desired)
+	fields := []string{}
+	for i := 0; i < liveVal.NumField(); i++ {
+		if !reflect.DeepEqual(liveVal.Field(i).Interface(), desiredVal.Field(i).Interface()) {
+			fields = append(fields, strings.Split(liveVal.Type().Field(i).Tag.Get("json"), ",")[0])
+		}
+	}
+	return fields
+}
+
+func cacheGroupObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, cg := range doc.CacheGroups {
+		objs = append(objs, keyedObject{key: cg.Name, obj: cg})
+	}
+	return objs
+}
+
+func profileObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, profile := range doc.Profiles {
+		objs = append(objs, keyedObject{key: profile.Name, obj: profile})
+	}
+	return objs
+}
+
+func serverObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, server := range doc.Servers {
+		objs = append(objs, keyedObject{key: server.HostName, obj: server})
+	}
+	return objs
+}
+
+func deliveryServiceObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, ds := range doc.DeliveryServices {
+		objs = append(objs, keyedObject{key: ds.XMLID, obj: ds})
+	}
+	return objs
+}
+
+func originObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, origin := range doc.Origins {
+		objs = append(objs, keyedObject{key: origin.Name, obj: origin})
+	}
+	return objs
+}
+
+func steeringObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, steering := range doc.Steering {
+		objs = append(objs, keyedObject{key: steering.DeliveryService, obj: steering})
+	}
+	return objs
+}
+
+func federationObjects(doc tc.CDNDocument) []keyedObject {
+	objs := []keyedObject{}
+	for _, fed := range doc.Federations {
+		objs = append(objs, keyedObject{key: fed.CName, obj: fed})
+	}
+	return objs
+}
+
+// checkReferences returns a user error if the document refers to types, statuses, physical locations, or tenants which don't exist. Objects outside of the CDN aren't created by applying a document.
+func checkReferences(tx *sql.Tx, doc tc.CDNDocument) (error, error, int) {
+	types, statuses, physLocations, tenants := []string{}, []string{}, []string{}, []string{}
+	for _, cg := range doc.CacheGroups {
+		types = append(types, cg.Type)
+	}
+	for _, server := range doc.Servers {
+		types = append(types, server.Type)
+		statuses = append(statuses, server.Status)
+		physLocations = append(physLocations, server.PhysLocation)
+	}
+	for _, ds := range doc.DeliveryServices {
+		types = append(types, ds.Type)
+		tenants = append(tenants, ds.Tenant)
+		for _, re := range ds.Regexes {
+			types = append(types, re.Type)
+		}
+	}
+	for _, origin := range doc.Origins {
+		tenants = append(tenants, origin.Tenant)
+	}
+	for _, steering := range doc.Steering {
+		for _, target := range steering.Targets {
+			types = append(types, target.Type)
+		}
+	}
+	errs := []string{}
+	for _, ref := range []struct {
+		table string
+		names []string
+	}{
+		{"type", types},
+		{"status", statuses},
+		{"phys_location", physLocations},
+		{"tenant", tenants},
+	} {
+		missing, err := missingNames(tx, ref.table, ref.names)
+		if err != nil {
+			return nil, errors.New("checking " + ref.table + " names: " + err.Error()), http.StatusInternalServerError
+		}
+		if len(missing) > 0 {
+			errs = append(errs, "no such "+strings.Replace(ref.table, "_", " ", -1)+": "+strings.Join(missing, ", "))
+		}
+	}
+	profileTypes := []string{}
+	for _, profile := range doc.Profiles {
+		profileTypes = append(profileTypes, profile.Type)
+	}
+	invalidProfileTypes := []string{}
+	if err := tx.QueryRow(`SELECT ARRAY(SELECT DISTINCT n FROM unnest($1::text[]) AS n WHERE n NOT IN (SELECT unnest(enum_range(NULL::profile_type))::text) ORDER BY n)`, pq.Array(profileTypes)).Scan(pq.Array(&invalidProfileTypes)); err != nil {
+		return nil, errors.New("checking profile types: " + err.Error()), http.StatusInternalServerError
+	}
+	if len(invalidProfileTypes) > 0 {
+		errs = append(errs, "no such profile type: "+strings.Join(invalidProfileTypes, ", "))
+	}
+	if len(errs) > 0 {
+		return errors.New("invalid document: " + strings.Join(errs, "; ")), nil, http.StatusBadRequest
+	}
+	return nil, nil, http.StatusOK
+}
+
+// missingNames returns the names which aren't in the name column of the given table.
+func missingNames(tx *sql.Tx, table string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	missing := []string{}
+	qry := `SELECT ARRAY(SELECT DISTINCT n FROM unnest($1::text[]) AS n WHERE n NOT IN (SELECT name FROM ` + table + `) ORDER BY n)`
+	if err := tx.QueryRow(qry, pq.Array(names)).Scan(pq.Array(&missing)); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// checkTenancy returns a user error if the user isn't authorized for the tenants of the live or desired delivery services and origins.
+func checkTenancy(tx *sql.Tx, user *auth.CurrentUser, live tc.CDNDocument, desired tc.CDNDocument) (error, error, int) {
+	userTenants, err := tenant.GetUserTenantListTx(*user, tx)
+	if err != nil {
+		return nil, errors.New("getting user tenants: " + err.Error()), http.StatusInternalServerError
+	}
+	authorized := map[string]struct{}{}
+	for _, t := range userTenants {
+		if t.Name != nil {
+			authorized[*t.Name] = struct{}{}
+		}
+	}
+	unauthorized := map[string]struct{}{}
+	for _, doc := range []tc.CDNDocument{live, desired} {
+		for _, ds := range doc.DeliveryServices {
+			if _, ok := authorized[ds.Tenant]; !ok {
+				unauthorized[ds.Tenant] = struct{}{}
+			}
+		}
+		for _, origin := range doc.Origins {
+			if _, ok := authorized[origin.Tenant]; !ok {
+				unauthorized[origin.Tenant] = struct{}{}
+			}
+		}
+	}
+	if len(unauthorized) > 0 {
+		names := []string{}
+		for name := range unauthorized {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return errors.New("not authorized on tenants: " + strings.Join(names, ", ")), nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
@@ -0,0 +1,201 @@
+package cdndocument
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func testDocument() tc.CDNDocument {
+	doc := tc.CDNDocument{
+		CDN: tc.CDNDocCDN{Name: "cdn1", DomainName: "cdn1.example.net"},
+		CacheGroups: []tc.CDNDocCacheGroup{
+			{Name: "mid", ShortName: "mid", Type: "MID_LOC"},
+			{Name: "edge", ShortName: "edge", Type: "EDGE_LOC", ParentCacheGroup: util.StrPtr("mid")},
+		},
+		Profiles: []tc.CDNDocProfile{
+			{Name: "EDGE", Type: "ATS_PROFILE", Parameters: []tc.CDNDocParameter{
+				{Name: "location", ConfigFile: "remap.config", Value: "/opt/trafficserver/etc/trafficserver"},
+				{Name: "CONFIG proxy.config.http.cache.http", ConfigFile: "records.config", Value: "INT 1"},
+			}},
+		},
+		Servers: []tc.CDNDocServer{
+			{HostName: "edge1", DomainName: "example.net", CacheGroup: "edge", Type: "EDGE", Profile: "EDGE", Status: "REPORTED", PhysLocation: "plocation", InterfaceName: "eth0", InterfaceMTU: 1500, IPAddress: "192.0.2.1", IPNetmask: "255.255.255.0", IPGateway: "192.0.2.254"},
+			{HostName: "edge2", DomainName: "example.net", CacheGroup: "edge", Type: "EDGE", Profile: "EDGE", Status: "REPORTED", PhysLocation: "plocation", InterfaceName: "eth0", InterfaceMTU: 1500, IPAddress: "192.0.2.2", IPNetmask: "255.255.255.0", IPGateway: "192.0.2.254"},
+		},
+		DeliveryServices: []tc.CDNDocDeliveryService{
+			{XMLID: "ds1", DisplayName: "ds1", Type: "HTTP", Tenant: "root", RoutingName: "cdn", Regexes: []tc.CDNDocDSRegex{{Type: "HOST_REGEXP", Pattern: `.*\.ds1\..*`}}, Servers: []string{"edge2", "edge1"}},
+		},
+		Origins: []tc.CDNDocOrigin{
+			{Name: "ds1-origin", DeliveryService: "ds1", Tenant: "root", FQDN: "origin.example.net", Protocol: "http", IsPrimary: true},
+		},
+		Federations: []tc.CDNDocFederation{
+			{CName: "fed.example.net.", TTL: 60, DeliveryServices: []string{"ds1"}},
+		},
+	}
+	normalizeDocument(&doc)
+	return doc
+}
+
+func TestNormalizeDocument(t *testing.T) {
+	doc := testDocument()
+	if doc.CacheGroups[0].Name != "edge" {
+		t.Errorf("expected cache groups sorted by name, actual: %+v", doc.CacheGroups)
+	}
+	if doc.Profiles[0].Parameters[0].ConfigFile != "records.config" {
+		t.Errorf("expected parameters sorted by config file, actual: %+v", doc.Profiles[0].Parameters)
+	}
+	if !reflect.DeepEqual(doc.DeliveryServices[0].Servers, []string{"edge1", "edge2"}) {
+		t.Errorf("expected delivery service servers sorted, actual: %+v", doc.DeliveryServices[0].Servers)
+	}
+	if doc.DeliveryServices[0].DeepCachingType != "NEVER" {
+		t.Errorf("expected default deep caching type NEVER, actual: '%s'", doc.DeliveryServices[0].DeepCachingType)
+	}
+	if doc.Steering == nil {
+		t.Errorf("expected empty steering, actual: nil")
+	}
+}
+
+func TestMakePlanNoChanges(t *testing.T) {
+	plan := makePlan(testDocument(), testDocument())
+	if len(plan.Changes) != 0 {
+		t.Errorf("expected no changes between identical documents, actual: %+v", plan.Changes)
+	}
+}
+
+func TestMakePlan(t *testing.T) {
+	live := testDocument()
+	desired := testDocument()
+	desired.CDN.DNSSECEnabled = true
+	desired.CacheGroups = desired.CacheGroups[1:] // cache groups are never deleted
+	desired.Servers = desired.Servers[:1]
+	desired.Servers[0].Status = "ONLINE"
+	desired.Servers = append(desired.Servers, tc.CDNDocServer{HostName: "edge3"})
+	desired.DeliveryServices[0].Servers = []string{"edge1", "edge3"}
+	desired.Origins = nil
+	normalizeDocument(&desired)
+
+	expected := []tc.CDNPlanChange{
+		{Action: tc.CDNPlanUpdate, Type: cdnType, Name: "cdn1", Fields: []string{"dnssecEnabled"}},
+		{Action: tc.CDNPlanUpdate, Type: serverType, Name: "edge1", Fields: []string{"status"}},
+		{Action: tc.CDNPlanCreate, Type: serverType, Name: "edge3"},
+		{Action: tc.CDNPlanUpdate, Type: deliveryServiceType, Name: "ds1", Fields: []string{"servers"}},
+		{Action: tc.CDNPlanDelete, Type: originType, Name: "ds1-origin"},
+		{Action: tc.CDNPlanDelete, Type: serverType, Name: "edge2"},
+	}
+	plan := makePlan(live, desired)
+	if plan.CDN != "cdn1" {
+		t.Errorf("expected plan cdn 'cdn1', actual: '%s'", plan.CDN)
+	}
+	if !reflect.DeepEqual(plan.Changes, expected) {
+		t.Errorf("expected changes %+v, actual: %+v", expected, plan.Changes)
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	a := tc.CDNDocOrigin{Name: "o", Port: util.IntPtr(80), CacheGroup: util.StrPtr("edge")}
+	b := a
+	b.Port = util.IntPtr(80)
+	if fields := changedFields(a, b); len(fields) != 0 {
+		t.Errorf("expected equal pointed-to values to be unchanged, actual changed: %v", fields)
+	}
+	b.Port = util.IntPtr(443)
+	b.CacheGroup = nil
+	if fields := changedFields(a, b); !reflect.DeepEqual(fields, []string{"port", "cacheGroup"}) {
+		t.Errorf("expected changed fields [port cacheGroup], actual: %v", fields)
+	}
+}
+
+func TestAddExistingCacheGroups(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	// the mid cache group exists, but no server or origin of the cdn uses it yet
+	live := testDocument()
+	live.CacheGroups = live.CacheGroups[:1]
+	desired := testDocument()
+	desired.CacheGroups[1].ShortName = "mid2"
+
+	rows := sqlmock.NewRows([]string{"name", "short_name", "type", "latitude", "longitude", "parent", "secondary_parent", "fallback_to_closest"})
+	rows = rows.AddRow("mid", "mid", "MID_LOC", nil, nil, nil, nil, false)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM cachegroup WHERE name = ANY").WithArgs(`{"mid"}`).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	if err := addExistingCacheGroups(tx, &live, desired); err != nil {
+		t.Fatalf("addExistingCacheGroups expected nil error, actual: %v", err)
+	}
+	tx.Commit()
+
+	expected := []tc.CDNPlanChange{{Action: tc.CDNPlanUpdate, Type: cacheGroupType, Name: "mid", Fields: []string{"shortName"}}}
+	if plan := makePlan(live, desired); !reflect.DeepEqual(plan.Changes, expected) {
+		t.Errorf("expected the existing cache group to be updated, not created: %+v, actual: %+v", expected, plan.Changes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
+
+func TestCheckSharedCacheGroups(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	plan := tc.CDNPlan{CDN: "cdn1", Changes: []tc.CDNPlanChange{
+		{Action: tc.CDNPlanCreate, Type: cacheGroupType, Name: "new"},
+		{Action: tc.CDNPlanUpdate, Type: cacheGroupType, Name: "mid", Fields: []string{"shortName"}},
+		{Action: tc.CDNPlanUpdate, Type: serverType, Name: "edge1", Fields: []string{"status"}},
+	}}
+	mock.ExpectBegin()
+	mock.ExpectQuery("cdn_id <> ").WithArgs(1, `{"mid"}`).WillReturnRows(sqlmock.NewRows([]string{"array"}).AddRow("{mid}"))
+	mock.ExpectQuery("cdn_id <> ").WithArgs(1, `{"mid"}`).WillReturnRows(sqlmock.NewRows([]string{"array"}).AddRow("{}"))
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	if userErr, sysErr, errCode := checkSharedCacheGroups(tx, 1, plan); userErr == nil || sysErr != nil || errCode != http.StatusBadRequest {
+		t.Errorf("expected a user error updating a cache group another cdn uses, actual: %v %v %d", userErr, sysErr, errCode)
+	}
+	if userErr, sysErr, _ := checkSharedCacheGroups(tx, 1, plan); userErr != nil || sysErr != nil {
+		t.Errorf("expected no error updating a cache group only this cdn uses, actual: %v %v", userErr, sysErr)
+	}
+	tx.Commit()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
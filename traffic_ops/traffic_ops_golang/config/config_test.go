@@ -21,6 +21,7 @@ package config
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -262,3 +263,35 @@ func TestLoadConfig(t *testing.T) {
 		t.Error("Expected KeyPath() == /etc/pki/tls/private/localhost.key")
 	}
 }
+
+func TestParseConfigRateLimit(t *testing.T) {
+	cfg := Config{}
+	if err := json.Unmarshal([]byte(goodConfig), &cfg); err != nil {
+		t.Fatalf("unmarshalling config: %v", err)
+	}
+	cfg.RateLimit = &ConfigRateLimit{
+		Default: ConfigRateLimitGroup{User: &ConfigRateLimitBucket{RequestsPerSecond: 2.5}},
+		ORT:     &ConfigRateLimitGroup{IP: &ConfigRateLimitBucket{RequestsPerSecond: 10, Burst: 50}},
+	}
+	cfg, err := ParseConfig(cfg)
+	if err != nil {
+		t.Fatalf("ParseConfig expected: nil error, actual: %v", err)
+	}
+	if cfg.RateLimit.Default.User.Burst != 3 {
+		t.Errorf("default user burst expected: 3, actual: %v", cfg.RateLimit.Default.User.Burst)
+	}
+	if cfg.RateLimit.ORT.User != nil || cfg.RateLimit.ORT.IP.Burst != 50 {
+		t.Errorf("ort expected: unlimited user and ip burst 50, actual: %+v", cfg.RateLimit.ORT)
+	}
+	if cfg.RateLimit.Monitor == nil || cfg.RateLimit.Monitor.User == nil || cfg.RateLimit.Monitor.User.RequestsPerSecond != 2.5 {
+		t.Errorf("monitor expected: default limits, actual: %+v", cfg.RateLimit.Monitor)
+	}
+	if cfg.RateLimit.IdleSeconds != DefaultRateLimitIdleSecs {
+		t.Errorf("idle seconds expected: %v, actual: %v", DefaultRateLimitIdleSecs, cfg.RateLimit.IdleSeconds)
+	}
+
+	cfg.RateLimit = &ConfigRateLimit{Default: ConfigRateLimitGroup{IP: &ConfigRateLimitBucket{RequestsPerSecond: 0}}}
+	if _, err := ParseConfig(cfg); err == nil {
+		t.Error("ParseConfig with zero requests_per_second expected: error, actual: nil")
+	}
+}
@@ -191,6 +191,7 @@ and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
 	return servers, nil
 }
 
+// getServerDSNames returns the delivery services assigned to each server. Delivery services with a topology are only routed to servers in the topology's edge tier.
 func getServerDSNames(cdn string, tx *sql.Tx) (map[tc.CacheName][]tc.DeliveryServiceName, error) {
 	q := `
 select s.host_name, ds.xml_id
@@ -205,6 +206,13 @@ and ds.active = true` +
 		fmt.Sprintf(" and dt.name != '%s' ", tc.DSTypeAnyMap) + `
 and p.routing_disabled = false
 and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
+and (ds.topology is null or exists (
+	select 1 from topology_cachegroup as tcg
+	inner join cachegroup as cg on cg.name = tcg.cachegroup
+	where tcg.topology = ds.topology
+	and cg.id = s.cachegroup
+	and not exists (select 1 from topology_cachegroup_parents as tcgp where tcgp.parent = tcg.id)
+))
 `
 	rows, err := tx.Query(q, cdn)
 	if err != nil {
@@ -32,7 +32,8 @@ import (
 
 // Snapshot takes the CRConfig JSON-serializable object (which may be generated via crconfig.Make), and writes it to the snapshot table.
 // It also takes the monitoring config JSON and writes it to the snapshot table.
-func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitoring) error {
+// Every snapshot is also appended to the snapshot_history table, so previous snapshots can be compared and restored; only the newest maxHistory snapshots of the CDN are kept, or all of them if maxHistory isn't positive.
+func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitoring, maxHistory int) error {
 	log.Debugln("calling Snapshot")
 	bts, err := json.Marshal(crc)
 	if err != nil {
@@ -53,6 +54,29 @@ func Snapshot(tx *sql.Tx, crc *tc.CRConfig, monitoringJSON *monitoring.Monitorin
 	if _, err := tx.Exec(q, crc.Stats.CDNName, bts, date, btstm); err != nil {
 		return errors.New("Error inserting the crconfig and monitoring snapshot into database: " + err.Error())
 	}
+	qh := `insert into snapshot_history (cdn, crconfig, monitoring, tm_user, last_updated) values ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(qh, crc.Stats.CDNName, bts, btstm, crc.Stats.TMUser, date); err != nil {
+		return errors.New("Error inserting the crconfig and monitoring snapshot into the snapshot history: " + err.Error())
+	}
+	if err := pruneSnapshotHistory(tx, *crc.Stats.CDNName, maxHistory); err != nil {
+		return errors.New("Error pruning the snapshot history: " + err.Error())
+	}
+	return nil
+}
+
+// pruneSnapshotHistory deletes all but the newest max snapshots of the given CDN from the snapshot history. If max isn't positive, nothing is deleted.
+func pruneSnapshotHistory(tx *sql.Tx, cdn string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	q := `
+DELETE FROM snapshot_history
+WHERE cdn = $1
+AND id NOT IN (SELECT id FROM snapshot_history WHERE cdn = $1 ORDER BY id DESC LIMIT $2)
+`
+	if _, err := tx.Exec(q, cdn, max); err != nil {
+		return errors.New("deleting old snapshots: " + err.Error())
+	}
 	return nil
 }
 
@@ -115,3 +139,47 @@ WHERE c.name = $1
 	}
 	return monitorSnapshot.String, true, nil
 }
+
+// UpdateSnapshotStaticDNSEntries replaces the static DNS entries of the given delivery service, in the current CRConfig snapshot of the given CDN, with the result of calling update with the current entries.
+// This lets entries which must be served immediately, such as ACME challenges, be added to Traffic Router without snapshotting any other changes pending in the CDN.
+// The updated snapshot is appended to the snapshot history with the given user, which is pruned to maxHistory snapshots as by Snapshot. Returns false if the CDN has no snapshot, or the delivery service isn't in it.
+func UpdateSnapshotStaticDNSEntries(tx *sql.Tx, cdn string, ds string, user string, maxHistory int, update func([]tc.CRConfigStaticDNSEntry) []tc.CRConfigStaticDNSEntry) (bool, error) {
+	crcBts := []byte{}
+	monitoringBts := []byte{}
+	if err := tx.QueryRow(`SELECT crconfig, monitoring FROM snapshot WHERE cdn = $1 FOR UPDATE`, cdn).Scan(&crcBts, &monitoringBts); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("querying snapshot: " + err.Error())
+	}
+	crc := tc.CRConfig{}
+	if err := json.Unmarshal(crcBts, &crc); err != nil {
+		return false, errors.New("unmarshalling snapshot: " + err.Error())
+	}
+	dsCfg, ok := crc.DeliveryServices[ds]
+	if !ok {
+		return false, nil
+	}
+	dsCfg.StaticDNSEntries = update(dsCfg.StaticDNSEntries)
+	crc.DeliveryServices[ds] = dsCfg
+
+	date := time.Now()
+	dateUnix := date.Unix()
+	crc.Stats.DateUnixSeconds = &dateUnix // Traffic Router only loads snapshots newer than the one it has
+	crc.Stats.TMUser = &user
+	bts, err := json.Marshal(crc)
+	if err != nil {
+		return false, errors.New("marshalling snapshot: " + err.Error())
+	}
+	if _, err := tx.Exec(`UPDATE snapshot SET crconfig = $1, last_updated = $2 WHERE cdn = $3`, bts, date, cdn); err != nil {
+		return false, errors.New("updating snapshot: " + err.Error())
+	}
+	qh := `insert into snapshot_history (cdn, crconfig, monitoring, tm_user, last_updated) values ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(qh, cdn, bts, monitoringBts, user, date); err != nil {
+		return false, errors.New("inserting snapshot history: " + err.Error())
+	}
+	if err := pruneSnapshotHistory(tx, cdn, maxHistory); err != nil {
+		return false, errors.New("pruning snapshot history: " + err.Error())
+	}
+	return true, nil
+}
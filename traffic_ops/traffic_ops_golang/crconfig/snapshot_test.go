@@ -104,8 +104,10 @@ func (a Any) Match(v driver.Value) bool {
 	return true
 }
 
-func MockSnapshot(mock sqlmock.Sqlmock, expected []byte, expectedtm []byte, cdn string) {
-	mock.ExpectExec("insert").WithArgs(cdn, expected, AnyTime{}, expectedtm).WillReturnResult(sqlmock.NewResult(1, 1))
+func MockSnapshot(mock sqlmock.Sqlmock, expected []byte, expectedtm []byte, cdn string, maxHistory int) {
+	mock.ExpectExec("insert into snapshot").WithArgs(cdn, expected, AnyTime{}, expectedtm).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert into snapshot_history").WithArgs(cdn, expected, expectedtm, Any{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM snapshot_history").WithArgs(cdn, maxHistory).WillReturnResult(sqlmock.NewResult(0, 1))
 }
 
 func TestSnapshot(t *testing.T) {
@@ -138,12 +140,85 @@ func TestSnapshot(t *testing.T) {
 	}
 
 	tm, _ := monitoring.GetMonitoringJSON(tx, *crc.Stats.CDNName)
-	MockSnapshot(mock, expected, expectedtm, cdn)
+	MockSnapshot(mock, expected, expectedtm, cdn, 10)
 	mock.ExpectCommit()
 
-	defer tx.Commit()
-
-	if err := Snapshot(tx, crc, tm); err != nil {
+	if err := Snapshot(tx, crc, tm, 10); err != nil {
 		t.Fatalf("GetSnapshot err expected: nil, actual: %v", err)
 	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be executed, actual: %v", err)
+	}
+}
+
+type snapshotStaticDNSEntriesArg struct {
+	ds       string
+	expected []tc.CRConfigStaticDNSEntry
+}
+
+// Match satisfies sqlmock.Argument interface
+func (a snapshotStaticDNSEntriesArg) Match(v driver.Value) bool {
+	bts, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	crc := tc.CRConfig{}
+	if err := json.Unmarshal(bts, &crc); err != nil {
+		return false
+	}
+	return crc.Stats.DateUnixSeconds != nil && reflect.DeepEqual(crc.DeliveryServices[a.ds].StaticDNSEntries, a.expected)
+}
+
+func TestUpdateSnapshotStaticDNSEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	crc := tc.CRConfig{DeliveryServices: map[string]tc.CRConfigDeliveryService{
+		"ds1": tc.CRConfigDeliveryService{StaticDNSEntries: []tc.CRConfigStaticDNSEntry{{Name: "a", TTL: 60, Type: "A", Value: "192.0.2.1"}}},
+	}}
+	crcBts, err := json.Marshal(crc)
+	if err != nil {
+		t.Fatalf("marshalling CRConfig: %v", err)
+	}
+	added := tc.CRConfigStaticDNSEntry{Name: "_acme-challenge.edge", TTL: 60, Type: "TXT", Value: "token"}
+	expected := []tc.CRConfigStaticDNSEntry{crc.DeliveryServices["ds1"].StaticDNSEntries[0], added}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT crconfig, monitoring FROM snapshot").WithArgs(cdn).WillReturnRows(sqlmock.NewRows([]string{"crconfig", "monitoring"}).AddRow(crcBts, []byte(`{}`)))
+	mock.ExpectExec("UPDATE snapshot").WithArgs(snapshotStaticDNSEntriesArg{ds: "ds1", expected: expected}, AnyTime{}, cdn).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert into snapshot_history").WithArgs(cdn, snapshotStaticDNSEntriesArg{ds: "ds1", expected: expected}, []byte(`{}`), "admin", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM snapshot_history").WithArgs(cdn, 10).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT crconfig, monitoring FROM snapshot").WithArgs(cdn).WillReturnRows(sqlmock.NewRows([]string{"crconfig", "monitoring"}).AddRow(crcBts, []byte(`{}`)))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	addEntry := func(entries []tc.CRConfigStaticDNSEntry) []tc.CRConfigStaticDNSEntry {
+		return append(entries, added)
+	}
+	if ok, err := UpdateSnapshotStaticDNSEntries(tx, cdn, "ds1", "admin", 10, addEntry); err != nil {
+		t.Fatalf("UpdateSnapshotStaticDNSEntries expected nil error, actual: %v", err)
+	} else if !ok {
+		t.Errorf("UpdateSnapshotStaticDNSEntries expected ok, actual: false")
+	}
+	if ok, err := UpdateSnapshotStaticDNSEntries(tx, cdn, "nonexistent", "admin", 10, addEntry); err != nil {
+		t.Fatalf("UpdateSnapshotStaticDNSEntries expected nil error, actual: %v", err)
+	} else if ok {
+		t.Errorf("UpdateSnapshotStaticDNSEntries nonexistent delivery service expected not ok, actual: true")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("committing: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be executed, actual: %v", err)
+	}
 }
@@ -0,0 +1,321 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/monitoring"
+)
+
+// SnapshotDiffNew is the value of the "to" parameter of the snapshot diff endpoint which compares against a CRConfig and monitoring snapshot generated from the current data, like cdns/{cdn}/snapshot/new.
+const SnapshotDiffNew = "new"
+
+// GetSnapshotVersions returns the snapshot history of the given CDN, newest first, without the snapshot contents.
+// If the CDN does not exist, false is returned.
+func GetSnapshotVersions(tx *sql.Tx, cdn string) ([]tc.SnapshotVersion, bool, error) {
+	if ok, err := dbhelpers.CDNExists(cdn, tx); err != nil {
+		return nil, false, errors.New("checking CDN existence: " + err.Error())
+	} else if !ok {
+		return nil, false, nil
+	}
+	rows, err := tx.Query(`SELECT id, cdn, tm_user, last_updated FROM snapshot_history WHERE cdn = $1 ORDER BY id DESC`, cdn)
+	if err != nil {
+		return nil, false, errors.New("querying snapshot history: " + err.Error())
+	}
+	defer rows.Close()
+	versions := []tc.SnapshotVersion{}
+	for rows.Next() {
+		v := tc.SnapshotVersion{}
+		if err := rows.Scan(&v.ID, &v.CDN, &v.User, &v.LastUpdated); err != nil {
+			return nil, false, errors.New("scanning snapshot history: " + err.Error())
+		}
+		versions = append(versions, v)
+	}
+	return versions, true, nil
+}
+
+// GetSnapshotVersion returns the historical snapshot of the given CDN with the given ID, including the CRConfig and monitoring content.
+// If the CDN or the snapshot does not exist, false is returned.
+func GetSnapshotVersion(tx *sql.Tx, cdn string, id int) (tc.SnapshotVersionContent, bool, error) {
+	v := tc.SnapshotVersionContent{}
+	crc := []byte{}
+	tm := []byte{}
+	q := `SELECT id, cdn, tm_user, last_updated, crconfig, monitoring FROM snapshot_history WHERE cdn = $1 AND id = $2`
+	if err := tx.QueryRow(q, cdn, id).Scan(&v.ID, &v.CDN, &v.User, &v.LastUpdated, &crc, &tm); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.SnapshotVersionContent{}, false, nil
+		}
+		return tc.SnapshotVersionContent{}, false, errors.New("querying snapshot history: " + err.Error())
+	}
+	v.CRConfig = json.RawMessage(crc)
+	v.Monitoring = json.RawMessage(tm)
+	return v, true, nil
+}
+
+// SnapshotHistoryHandler serves the list of historical snapshots of a CDN.
+func SnapshotHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	versions, cdnExists, err := GetSnapshotVersions(inf.Tx.Tx, inf.Params["cdn"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot history: "+err.Error()))
+		return
+	}
+	if !cdnExists {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("CDN not found"), nil)
+		return
+	}
+	api.WriteResp(w, r, versions)
+}
+
+// SnapshotHistoryVersionHandler serves a single historical snapshot of a CDN, including its CRConfig and monitoring content.
+func SnapshotHistoryVersionHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	version, ok, err := GetSnapshotVersion(inf.Tx.Tx, inf.Params["cdn"], inf.IntParams["id"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot version: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot not found"), nil)
+		return
+	}
+	api.WriteResp(w, r, version)
+}
+
+// SnapshotDiffHandler serves the difference between two historical snapshots of a CDN, given by the "from" and "to" parameters.
+// If "to" is omitted or is SnapshotDiffNew, the "from" snapshot is compared with a snapshot generated from the current data, which has not been written to the snapshot table.
+func SnapshotDiffHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "from"}, []string{"from"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	from, ok, err := GetSnapshotVersion(inf.Tx.Tx, cdn, inf.IntParams["from"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot version: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot 'from' not found"), nil)
+		return
+	}
+
+	diff := tc.SnapshotDiff{CDN: cdn, From: from.ID}
+	toCRConfig := []byte{}
+	toMonitoring := []byte{}
+	if toStr, ok := inf.Params["to"]; ok && toStr != SnapshotDiffNew {
+		toID, err := strconv.Atoi(toStr)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("parameter 'to' must be an integer or '"+SnapshotDiffNew+"'"), nil)
+			return
+		}
+		to, ok, err := GetSnapshotVersion(inf.Tx.Tx, cdn, toID)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot version: "+err.Error()))
+			return
+		}
+		if !ok {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot 'to' not found"), nil)
+			return
+		}
+		diff.To = util.IntPtr(to.ID)
+		toCRConfig = to.CRConfig
+		toMonitoring = to.Monitoring
+	} else {
+		crConfig, err := Make(inf.Tx.Tx, cdn, inf.User.UserName, r.Host, r.URL.Path, inf.Config.Version, inf.Config.CRConfigUseRequestHost, inf.Config.CRConfigEmulateOldPath)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("making CRConfig: "+err.Error()))
+			return
+		}
+		tm, err := monitoring.GetMonitoringJSON(inf.Tx.Tx, cdn)
+		if err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting monitoring.json data: "+err.Error()))
+			return
+		}
+		if toCRConfig, err = json.Marshal(crConfig); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("marshalling CRConfig: "+err.Error()))
+			return
+		}
+		if toMonitoring, err = json.Marshal(tm); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("marshalling monitoring: "+err.Error()))
+			return
+		}
+	}
+
+	if diff.CRConfig, err = DiffJSON(from.CRConfig, toCRConfig); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("diffing CRConfig: "+err.Error()))
+		return
+	}
+	if diff.Monitoring, err = DiffJSON(from.Monitoring, toMonitoring); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("diffing monitoring: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, diff)
+}
+
+// SnapshotRollbackHandler re-publishes a historical snapshot of a CDN as its current snapshot.
+// The republished CRConfig gets a new date and user, so Traffic Router and Traffic Monitor treat it as a new snapshot. It is also added to the history, so a rollback can itself be rolled back.
+func SnapshotRollbackHandler(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"cdn", "id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	cdn := inf.Params["cdn"]
+	version, ok, err := GetSnapshotVersion(inf.Tx.Tx, cdn, inf.IntParams["id"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting snapshot version: "+err.Error()))
+		return
+	}
+	if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("snapshot not found"), nil)
+		return
+	}
+
+	crConfig := tc.CRConfig{}
+	if err := json.Unmarshal(version.CRConfig, &crConfig); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling snapshot CRConfig: "+err.Error()))
+		return
+	}
+	tm := monitoring.Monitoring{}
+	if err := json.Unmarshal(version.Monitoring, &tm); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("unmarshalling snapshot monitoring: "+err.Error()))
+		return
+	}
+
+	crConfig.Stats.CDNName = &cdn
+	crConfig.Stats.DateUnixSeconds = util.Int64Ptr(time.Now().Unix())
+	crConfig.Stats.TMUser = &inf.User.UserName
+
+	if err := Snapshot(inf.Tx.Tx, &crConfig, &tm, inf.Config.SnapshotHistory.MaxSnapshots); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New(r.RemoteAddr+" rolling back CRConfig and Monitoring snapshot: "+err.Error()))
+		return
+	}
+
+	api.CreateChangeLogRawTx(api.ApiChange, "CDN: "+cdn+", ID: "+strconv.Itoa(version.ID)+", ACTION: Rollback of CRConfig and Monitor snapshot", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Snapshot "+strconv.Itoa(version.ID)+" of CDN "+cdn+" was re-published")
+}
+
+// DiffJSON returns the differences between the JSON documents a and b, sorted by path.
+// Objects are compared by key, and arrays are compared by index. An empty or nil document is treated as an empty object.
+func DiffJSON(a []byte, b []byte) ([]tc.SnapshotDiffEntry, error) {
+	av, err := unmarshalDiffJSON(a)
+	if err != nil {
+		return nil, errors.New("unmarshalling from: " + err.Error())
+	}
+	bv, err := unmarshalDiffJSON(b)
+	if err != nil {
+		return nil, errors.New("unmarshalling to: " + err.Error())
+	}
+	diffs := diffJSONVals("", av, bv, []tc.SnapshotDiffEntry{})
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func unmarshalDiffJSON(bts []byte) (interface{}, error) {
+	if len(bts) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v := interface{}(nil)
+	if err := json.Unmarshal(bts, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffJSONVals(path string, a interface{}, b interface{}, diffs []tc.SnapshotDiffEntry) []tc.SnapshotDiffEntry {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, aVal := range av {
+			bVal, ok := bv[key]
+			if !ok {
+				diffs = append(diffs, tc.SnapshotDiffEntry{Path: diffJSONPath(path, key), Type: tc.SnapshotDiffRemoved, Old: aVal})
+				continue
+			}
+			diffs = diffJSONVals(diffJSONPath(path, key), aVal, bVal, diffs)
+		}
+		for key, bVal := range bv {
+			if _, ok := av[key]; !ok {
+				diffs = append(diffs, tc.SnapshotDiffEntry{Path: diffJSONPath(path, key), Type: tc.SnapshotDiffAdded, New: bVal})
+			}
+		}
+		return diffs
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i, aVal := range av {
+			if i >= len(bv) {
+				diffs = append(diffs, tc.SnapshotDiffEntry{Path: diffJSONPath(path, strconv.Itoa(i)), Type: tc.SnapshotDiffRemoved, Old: aVal})
+				continue
+			}
+			diffs = diffJSONVals(diffJSONPath(path, strconv.Itoa(i)), aVal, bv[i], diffs)
+		}
+		for i := len(av); i < len(bv); i++ {
+			diffs = append(diffs, tc.SnapshotDiffEntry{Path: diffJSONPath(path, strconv.Itoa(i)), Type: tc.SnapshotDiffAdded, New: bv[i]})
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(a, b) {
+		diffs = append(diffs, tc.SnapshotDiffEntry{Path: path, Type: tc.SnapshotDiffChanged, Old: a, New: b})
+	}
+	return diffs
+}
+
+func diffJSONPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
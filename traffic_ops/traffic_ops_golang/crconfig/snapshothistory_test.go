@@ -0,0 +1,121 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestDiffJSON(t *testing.T) {
+	from := []byte(`{"stats":{"date":1,"CDN_name":"mycdn"},"contentServers":{"edge0":{"status":"REPORTED"},"edge1":{"status":"REPORTED"}},"list":[1,2,3]}`)
+	to := []byte(`{"stats":{"date":2,"CDN_name":"mycdn"},"contentServers":{"edge0":{"status":"ADMIN_DOWN"},"edge2":{"status":"REPORTED"}},"list":[1,2]}`)
+
+	expected := []tc.SnapshotDiffEntry{
+		{Path: "contentServers.edge0.status", Type: tc.SnapshotDiffChanged, Old: "REPORTED", New: "ADMIN_DOWN"},
+		{Path: "contentServers.edge1", Type: tc.SnapshotDiffRemoved, Old: map[string]interface{}{"status": "REPORTED"}},
+		{Path: "contentServers.edge2", Type: tc.SnapshotDiffAdded, New: map[string]interface{}{"status": "REPORTED"}},
+		{Path: "list.2", Type: tc.SnapshotDiffRemoved, Old: float64(3)},
+		{Path: "stats.date", Type: tc.SnapshotDiffChanged, Old: float64(1), New: float64(2)},
+	}
+
+	actual, err := DiffJSON(from, to)
+	if err != nil {
+		t.Fatalf("DiffJSON err expected: nil, actual: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DiffJSON expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestDiffJSONEqual(t *testing.T) {
+	doc := []byte(`{"a":{"b":[1,{"c":"d"}]}}`)
+	actual, err := DiffJSON(doc, doc)
+	if err != nil {
+		t.Fatalf("DiffJSON err expected: nil, actual: %v", err)
+	}
+	if len(actual) != 0 {
+		t.Errorf("DiffJSON of identical documents expected: no differences, actual: %+v", actual)
+	}
+}
+
+func TestDiffJSONEmpty(t *testing.T) {
+	actual, err := DiffJSON(nil, []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("DiffJSON err expected: nil, actual: %v", err)
+	}
+	expected := []tc.SnapshotDiffEntry{{Path: "a", Type: tc.SnapshotDiffAdded, New: float64(1)}}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DiffJSON expected: %+v, actual: %+v", expected, actual)
+	}
+
+	if _, err := DiffJSON([]byte(`{`), nil); err == nil {
+		t.Errorf("DiffJSON of invalid JSON expected: error, actual: nil")
+	}
+}
+
+func TestGetSnapshotVersions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM cdn").WithArgs(cdn).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	rows := sqlmock.NewRows([]string{"id", "cdn", "tm_user", "last_updated"})
+	rows = rows.AddRow(2, cdn, "admin", now)
+	rows = rows.AddRow(1, cdn, nil, now)
+	mock.ExpectQuery("SELECT id, cdn, tm_user, last_updated FROM snapshot_history").WithArgs(cdn).WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	dbCtx, cancel := context.WithTimeout(context.TODO(), time.Duration(10)*time.Second)
+	defer cancel()
+	tx, err := db.BeginTx(dbCtx, nil)
+	if err != nil {
+		t.Fatalf("creating transaction: %v", err)
+	}
+	defer tx.Commit()
+
+	versions, exists, err := GetSnapshotVersions(tx, cdn)
+	if err != nil {
+		t.Fatalf("GetSnapshotVersions err expected: nil, actual: %v", err)
+	}
+	if !exists {
+		t.Fatalf("GetSnapshotVersions exists expected: true, actual: false")
+	}
+	if len(versions) != 2 {
+		t.Fatalf("GetSnapshotVersions expected: 2 versions, actual: %v", len(versions))
+	}
+	if versions[0].ID != 2 || versions[0].User == nil || *versions[0].User != "admin" {
+		t.Errorf("GetSnapshotVersions expected: newest version by admin first, actual: %+v", versions[0])
+	}
+	if versions[1].User != nil {
+		t.Errorf("GetSnapshotVersions expected: nil user, actual: %v", *versions[1].User)
+	}
+}
@@ -21,14 +21,18 @@ package dbhelpers
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/lib/go-tc"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
@@ -42,6 +46,9 @@ const BaseOrderBy = "\nORDER BY"
 const BaseLimit = "\nLIMIT"
 const BaseOffset = "\nOFFSET"
 
+// CursorAfterIDQueryValue is the name of the query value of the ID a cursor is after, in the WHERE clause of cursor pagination.
+const CursorAfterIDQueryValue = "cursorAfterID"
+
 const getDSTenantIDFromXMLIDQuery = `
 SELECT deliveryservice.tenant_id
 FROM deliveryservice
@@ -62,7 +69,15 @@ WHERE federation_deliveryservice.deliveryservice IN (
 )
 `
 
+// BuildWhereAndOrderByAndPagination returns the WHERE, ORDER BY, and LIMIT and OFFSET clauses for the given query parameters, and the named values of the WHERE clause.
+//
+// If a cursor parameter is given with the limit, the page is selected by keyset: the WHERE clause selects IDs after the cursor's, the results are ordered by the "id" column of queryParamsToSQLCols, and the LIMIT is one more than requested, so the caller can tell whether there is a next page. Cursor pagination is not supported if queryParamsToSQLCols has no "id" column.
 func BuildWhereAndOrderByAndPagination(parameters map[string]string, queryParamsToSQLCols map[string]WhereColumnInfo) (string, string, string, map[string]interface{}, []error) {
+	return BuildWhereAndOrderByAndCursorPagination(parameters, queryParamsToSQLCols, queryParamsToSQLCols["id"].Column)
+}
+
+// BuildWhereAndOrderByAndCursorPagination is like BuildWhereAndOrderByAndPagination, but cursor pagination selects and orders by the given cursorColumn, which must be the column of the results' IDs. This is for reads whose "id" parameter filters by another column, such as the ID of a parent object in the path. Cursor pagination is not supported if cursorColumn is empty.
+func BuildWhereAndOrderByAndCursorPagination(parameters map[string]string, queryParamsToSQLCols map[string]WhereColumnInfo, cursorColumn string) (string, string, string, map[string]interface{}, []error) {
 	whereClause := BaseWhere
 	orderBy := BaseOrderBy
 	paginationClause := BaseLimit
@@ -106,24 +121,49 @@ func BuildWhereAndOrderByAndPagination(parameters map[string]string, queryParams
 			return "", "", "", queryValues, errs
 		}
 		log.Debugln("limit: ", limit)
-		paginationClause += " " + limit
-		if offset, exists := parameters["offset"]; exists {
-			// check that offset is valid
-			offsetInt, err := strconv.Atoi(offset)
-			if err != nil || offsetInt < 1 {
-				errs = append(errs, errors.New("offset parameter must be a positive integer"))
+		if cursor, exists := parameters["cursor"]; exists {
+			if cursorColumn == "" {
+				errs = append(errs, errors.New("cursor pagination is not supported by this endpoint"))
 				return "", "", "", queryValues, errs
 			}
-			paginationClause += BaseOffset + " " + offset
-		} else if page, exists := parameters["page"]; exists {
-			// check that offset is valid
-			page, err := strconv.Atoi(page)
-			if err != nil || page < 1 {
-				errs = append(errs, errors.New("page parameter must be a positive integer"))
-				return "", "", "", queryValues, errs
+			afterID := 0
+			if cursor != "" {
+				if afterID, err = DecodeCursor(cursor); err != nil {
+					errs = append(errs, err)
+					return "", "", "", queryValues, errs
+				}
+			}
+			if whereClause == BaseWhere {
+				whereClause += " " + cursorColumn + " > :" + CursorAfterIDQueryValue
+			} else {
+				whereClause += " AND " + cursorColumn + " > :" + CursorAfterIDQueryValue
+			}
+			queryValues[CursorAfterIDQueryValue] = afterID
+			orderBy = BaseOrderBy + " " + cursorColumn
+			paginationClause += " " + strconv.Itoa(limitInt+1)
+		} else {
+			paginationClause += " " + limit
+			if offset, exists := parameters["offset"]; exists {
+				// check that offset is valid
+				offsetInt, err := strconv.Atoi(offset)
+				if err != nil || offsetInt < 1 {
+					errs = append(errs, errors.New("offset parameter must be a positive integer"))
+					return "", "", "", queryValues, errs
+				}
+				paginationClause += BaseOffset + " " + offset
+			} else if page, exists := parameters["page"]; exists {
+				// check that offset is valid
+				page, err := strconv.Atoi(page)
+				if err != nil || page < 1 {
+					errs = append(errs, errors.New("page parameter must be a positive integer"))
+					return "", "", "", queryValues, errs
+				}
+				paginationClause += BaseOffset + " " + strconv.Itoa((page-1)*limitInt)
 			}
-			paginationClause += BaseOffset + " " + strconv.Itoa((page-1)*limitInt)
 		}
+	} else if _, exists := parameters["cursor"]; exists {
+		errs = append(errs, errors.New("cursor parameter requires a limit"))
+		return "", "", "", queryValues, errs
 	}
 
 	if whereClause == BaseWhere {
@@ -139,6 +179,55 @@ func BuildWhereAndOrderByAndPagination(parameters map[string]string, queryParams
 	return whereClause, orderBy, paginationClause, queryValues, errs
 }
 
+// Count returns the number of rows selected by query, which is a read's query with the WHERE clause from BuildWhereAndOrderByAndPagination, without its ORDER BY or pagination clauses.
+// Rows before the cursor of cursor pagination are counted too, so the count is the same for every page.
+func Count(tx *sqlx.Tx, query string, queryValues map[string]interface{}) (int, error) {
+	countValues := make(map[string]interface{}, len(queryValues))
+	for key, val := range queryValues {
+		countValues[key] = val
+	}
+	if _, ok := countValues[CursorAfterIDQueryValue]; ok {
+		countValues[CursorAfterIDQueryValue] = int64(math.MinInt64)
+	}
+	rows, err := tx.NamedQuery(`SELECT COUNT(*) FROM (`+query+`) AS counted`, countValues)
+	if err != nil {
+		return 0, errors.New("querying count: " + err.Error())
+	}
+	defer rows.Close()
+	count := 0
+	if !rows.Next() {
+		return 0, errors.New("querying count: no rows returned")
+	}
+	if err := rows.Scan(&count); err != nil {
+		return 0, errors.New("scanning count: " + err.Error())
+	}
+	return count, nil
+}
+
+// cursor is the decoded value of the opaque cursor query parameter.
+type cursor struct {
+	AfterID int `json:"after"`
+}
+
+// EncodeCursor returns the opaque cursor for the page after the given ID.
+func EncodeCursor(afterID int) string {
+	bts, _ := json.Marshal(cursor{AfterID: afterID}) // marshalling a struct of an int can't fail
+	return base64.RawURLEncoding.EncodeToString(bts)
+}
+
+// DecodeCursor returns the ID the given opaque cursor is after.
+func DecodeCursor(s string) (int, error) {
+	bts, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+	c := cursor{}
+	if err := json.Unmarshal(bts, &c); err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+	return c.AfterID, nil
+}
+
 func parseCriteriaAndQueryValues(queryParamsToSQLCols map[string]WhereColumnInfo, parameters map[string]string) (string, map[string]interface{}, []error) {
 	var criteria string
 
@@ -21,6 +21,7 @@ package dbhelpers
 
 import (
 	"errors"
+	"math"
 	"strings"
 	"testing"
 	"unicode"
@@ -81,6 +82,114 @@ FROM table t
 
 }
 
+func TestBuildQueryCursor(t *testing.T) {
+	queryParamsToSQLCols := map[string]WhereColumnInfo{
+		"id":     WhereColumnInfo{"t.id", nil},
+		"param1": WhereColumnInfo{"t.col1", nil},
+	}
+
+	v := map[string]string{"param1": "queryParamv1", "limit": "20", "cursor": EncodeCursor(42)}
+	where, orderBy, pagination, queryValues, errs := BuildWhereAndOrderByAndPagination(v, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: no errors, actual: %v", errs)
+	}
+	if expected := "\nWHERE t.col1=:param1 AND t.id > :" + CursorAfterIDQueryValue; where != expected {
+		t.Errorf("expected: %q for where, actual: %q", expected, where)
+	}
+	if expected := "\nORDER BY t.id"; orderBy != expected {
+		t.Errorf("expected: %q for order by, actual: %q", expected, orderBy)
+	}
+	// one more than the limit, to tell whether there's a next page
+	if expected := "\nLIMIT 21"; pagination != expected {
+		t.Errorf("expected: %q for pagination, actual: %q", expected, pagination)
+	}
+	if queryValues[CursorAfterIDQueryValue] != 42 {
+		t.Errorf("expected: cursor query value 42, actual: %v", queryValues[CursorAfterIDQueryValue])
+	}
+
+	v = map[string]string{"limit": "20", "cursor": ""}
+	where, _, _, queryValues, errs = BuildWhereAndOrderByAndPagination(v, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: no errors, actual: %v", errs)
+	}
+	if expected := "\nWHERE t.id > :" + CursorAfterIDQueryValue; where != expected {
+		t.Errorf("expected: %q for where, actual: %q", expected, where)
+	}
+	if queryValues[CursorAfterIDQueryValue] != 0 {
+		t.Errorf("expected: cursor query value 0, actual: %v", queryValues[CursorAfterIDQueryValue])
+	}
+
+	// the "id" parameter filters by the parent, and cursor pages are by the results' own IDs
+	v = map[string]string{"id": "7", "limit": "20", "cursor": EncodeCursor(42)}
+	where, orderBy, _, _, errs = BuildWhereAndOrderByAndCursorPagination(v, queryParamsToSQLCols, "c.id")
+	if len(errs) > 0 {
+		t.Fatalf("expected: no errors, actual: %v", errs)
+	}
+	if expected := "\nWHERE t.id=:id AND c.id > :" + CursorAfterIDQueryValue; where != expected {
+		t.Errorf("expected: %q for where, actual: %q", expected, where)
+	}
+	if expected := "\nORDER BY c.id"; orderBy != expected {
+		t.Errorf("expected: %q for order by, actual: %q", expected, orderBy)
+	}
+
+	if _, _, _, _, errs := BuildWhereAndOrderByAndPagination(map[string]string{"cursor": ""}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for cursor without limit, actual: nil")
+	}
+	if _, _, _, _, errs := BuildWhereAndOrderByAndPagination(map[string]string{"limit": "20", "cursor": "!!"}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for malformed cursor, actual: nil")
+	}
+	delete(queryParamsToSQLCols, "id")
+	if _, _, _, _, errs := BuildWhereAndOrderByAndPagination(map[string]string{"limit": "20", "cursor": ""}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for cursor without an id column, actual: nil")
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id, err := DecodeCursor(EncodeCursor(42))
+	if err != nil {
+		t.Fatalf("DecodeCursor expected nil error, actual: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("DecodeCursor expected 42, actual: %v", id)
+	}
+	if _, err := DecodeCursor("not a cursor!"); err == nil {
+		t.Errorf("DecodeCursor malformed cursor expected error, actual: nil")
+	}
+}
+
+func TestCount(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	// rows before the cursor are counted too
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(SELECT t.id FROM table t WHERE t.id > \?\) AS counted`).WithArgs(int64(math.MinInt64)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	mock.ExpectCommit()
+
+	tx := db.MustBegin()
+	queryValues := map[string]interface{}{CursorAfterIDQueryValue: 42}
+	count, err := Count(tx, "SELECT t.id FROM table t WHERE t.id > :"+CursorAfterIDQueryValue, queryValues)
+	if err != nil {
+		t.Fatalf("Count expected: nil error, actual: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("Count expected: 7, actual: %v", count)
+	}
+	if queryValues[CursorAfterIDQueryValue] != 42 {
+		t.Errorf("Count expected: query values unchanged, actual: %v", queryValues)
+	}
+	tx.Commit()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestGetCacheGroupByName(t *testing.T) {
 	var testCases = []struct {
 		description  string
@@ -0,0 +1,244 @@
+package dbreplica
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Pool is a set of read-only replicas of the Traffic Ops database, which read-only requests use instead of the primary.
+// The lag of each replica behind the primary is checked periodically, and replicas which lag too far behind, or can't be reached, aren't used until they catch up.
+// Users who recently wrote to the primary read from it, until their writes have had time to reach the replicas.
+// A nil Pool has no replicas.
+type Pool struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	maxLag   time.Duration
+	interval time.Duration
+	next     uint32
+	now      func() time.Time
+
+	writesMutex sync.Mutex
+	lastWrites  map[string]time.Time
+}
+
+type replica struct {
+	db      *sqlx.DB
+	name    string
+	healthy int32 // accessed atomically
+}
+
+// Open opens the replicas of the given config's database, whose lag is checked against the given primary. If it has none, Open returns nil.
+func Open(cfg *config.Config, primary *sqlx.DB) (*Pool, error) {
+	if len(cfg.DB.Replicas) == 0 {
+		return nil, nil
+	}
+	dbs := []*sqlx.DB{}
+	for i, dsn := range cfg.DB.Replicas {
+		db, err := sqlx.Open("postgres", dsn)
+		if err != nil {
+			for _, opened := range dbs {
+				opened.Close()
+			}
+			return nil, errors.New("opening replica " + strconv.Itoa(i) + ": " + err.Error())
+		}
+		db.SetMaxOpenConns(cfg.MaxDBConnections)
+		db.SetMaxIdleConns(cfg.DBMaxIdleConnections)
+		db.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeSeconds) * time.Second)
+		dbs = append(dbs, db)
+	}
+	return New(primary, dbs, time.Duration(cfg.DB.MaxReplicaLagSeconds)*time.Second, time.Duration(cfg.DB.ReplicaCheckIntervalSeconds)*time.Second), nil
+}
+
+// New creates a Pool of the given replicas of the given primary. They aren't used until their lag is checked, by Run or CheckLag.
+func New(primary *sqlx.DB, dbs []*sqlx.DB, maxLag time.Duration, interval time.Duration) *Pool {
+	p := &Pool{primary: primary, maxLag: maxLag, interval: interval, now: time.Now, lastWrites: map[string]time.Time{}}
+	for i, db := range dbs {
+		p.replicas = append(p.replicas, &replica{db: db, name: "replica " + strconv.Itoa(i)})
+	}
+	return p
+}
+
+// Run checks the lag of the replicas forever. It should be called in its own goroutine.
+func (p *Pool) Run() {
+	for {
+		p.CheckLag()
+		p.purgeWrites()
+		time.Sleep(p.interval)
+	}
+}
+
+// Close closes the replicas.
+func (p *Pool) Close() {
+	if p == nil {
+		return
+	}
+	for _, r := range p.replicas {
+		r.db.Close()
+	}
+}
+
+// CheckLag checks the lag of each replica, and marks whether it may be used.
+// If the primary's WAL location can't be read, no replica may be used, because their lag can't be known.
+func (p *Pool) CheckLag() {
+	primaryLSN, primaryErr := getPrimaryLSN(p.primary.DB)
+	if primaryErr != nil {
+		log.Errorln("checking replica lag: getting primary WAL location: " + primaryErr.Error())
+	}
+	for _, r := range p.replicas {
+		healthy := false
+		if primaryErr == nil {
+			lag, err := getLag(r.db.DB, primaryLSN)
+			healthy = err == nil && lag <= p.maxLag
+			if err != nil {
+				log.Errorln("checking lag of database " + r.name + ": " + err.Error())
+			} else if !healthy {
+				log.Warnln("database " + r.name + " lags " + lag.String() + " behind the primary, more than the maximum " + p.maxLag.String())
+			}
+		}
+		was := atomic.SwapInt32(&r.healthy, boolToInt32(healthy))
+		if was == 1 && !healthy {
+			log.Warnln("database " + r.name + " is unavailable, reading from the primary")
+		} else if was == 0 && healthy {
+			log.Infoln("database " + r.name + " is available")
+		}
+	}
+}
+
+// DB returns a replica for the given user to read from, or nil if the user should read from the primary, because there are no available replicas, or the user wrote to the primary too recently for the replicas to have the write.
+// Replicas are returned in turn.
+func (p *Pool) DB(user string) *sqlx.DB {
+	if p == nil || len(p.replicas) == 0 {
+		return nil
+	}
+	if p.wroteRecently(user) {
+		return nil
+	}
+	start := atomic.AddUint32(&p.next, 1)
+	for i := 0; i < len(p.replicas); i++ {
+		r := p.replicas[(int(start)+i)%len(p.replicas)]
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// RecordWrite records that the given user wrote to the primary, so their reads use it until the write has reached the replicas.
+func (p *Pool) RecordWrite(user string) {
+	if p == nil || len(p.replicas) == 0 {
+		return
+	}
+	p.writesMutex.Lock()
+	defer p.writesMutex.Unlock()
+	p.lastWrites[user] = p.now()
+}
+
+// writeWindow is how long after a write a replica may not have it: the maximum lag, plus the time until the lag is next checked.
+func (p *Pool) writeWindow() time.Duration {
+	return p.maxLag + p.interval
+}
+
+func (p *Pool) wroteRecently(user string) bool {
+	p.writesMutex.Lock()
+	defer p.writesMutex.Unlock()
+	lastWrite, ok := p.lastWrites[user]
+	return ok && p.now().Sub(lastWrite) < p.writeWindow()
+}
+
+func (p *Pool) purgeWrites() {
+	p.writesMutex.Lock()
+	defer p.writesMutex.Unlock()
+	now := p.now()
+	for user, lastWrite := range p.lastWrites {
+		if now.Sub(lastWrite) >= p.writeWindow() {
+			delete(p.lastWrites, user)
+		}
+	}
+}
+
+// PostgreSQL 10 renamed the xlog functions to wal.
+const pgWALFunctionsVersion = 100000
+
+// walFunctions returns the functions of the given database for the current WAL location of a primary, and the replayed WAL location of a replica.
+func walFunctions(db *sql.DB) (string, string, error) {
+	version := 0
+	if err := db.QueryRow(`SELECT current_setting('server_version_num')::integer`).Scan(&version); err != nil {
+		return "", "", errors.New("getting server version: " + err.Error())
+	}
+	if version >= pgWALFunctionsVersion {
+		return "pg_current_wal_lsn()", "pg_last_wal_replay_lsn()", nil
+	}
+	return "pg_current_xlog_location()", "pg_last_xlog_replay_location()", nil
+}
+
+// getPrimaryLSN returns the current WAL location of the primary, which replicas have all of the primary's writes once they've replayed.
+func getPrimaryLSN(db *sql.DB) (string, error) {
+	current, _, err := walFunctions(db)
+	if err != nil {
+		return "", err
+	}
+	lsn := ""
+	if err := db.QueryRow(`SELECT ` + current + `::text`).Scan(&lsn); err != nil {
+		return "", errors.New("querying WAL location: " + err.Error())
+	}
+	return lsn, nil
+}
+
+// getLag returns how far the given replica lags behind the primary, whose current WAL location is primaryLSN.
+// A replica which has replayed the primary's WAL location doesn't lag, even if the primary hasn't been written to recently. Otherwise, the lag is the time since the replica last replayed a transaction, so a replica whose replication has stopped, and which has replayed everything it received, still lags once the primary is written to.
+func getLag(db *sql.DB, primaryLSN string) (time.Duration, error) {
+	_, replay, err := walFunctions(db)
+	if err != nil {
+		return 0, err
+	}
+	qry := `
+SELECT CASE
+  WHEN NOT pg_is_in_recovery() THEN 0
+  WHEN ` + replay + ` >= $1::pg_lsn THEN 0
+  ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END
+`
+	lagSecs := sql.NullFloat64{}
+	if err := db.QueryRow(qry, primaryLSN).Scan(&lagSecs); err != nil {
+		return 0, errors.New("querying lag: " + err.Error())
+	}
+	if !lagSecs.Valid {
+		return 0, errors.New("replica has not replayed any transactions")
+	}
+	return time.Duration(lagSecs.Float64 * float64(time.Second)), nil
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package dbreplica
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const primaryLSN = "0/3000060"
+
+func expectPrimaryLSN(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectQuery("SELECT current_setting").WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).AddRow(version))
+	mock.ExpectQuery("SELECT pg_current_").WillReturnRows(sqlmock.NewRows([]string{"lsn"}).AddRow(primaryLSN))
+}
+
+func expectLag(mock sqlmock.Sqlmock, version int, lag interface{}) {
+	mock.ExpectQuery("SELECT current_setting").WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).AddRow(version))
+	mock.ExpectQuery("pg_is_in_recovery").WithArgs(primaryLSN).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(lag))
+}
+
+func TestPool(t *testing.T) {
+	primaryDB, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primaryDB.Close()
+
+	mockDBs := []*sqlx.DB{}
+	mocks := []sqlmock.Sqlmock{}
+	for i := 0; i < 2; i++ {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer db.Close()
+		mockDBs = append(mockDBs, sqlx.NewDb(db, "sqlmock"))
+		mocks = append(mocks, mock)
+	}
+
+	now := time.Unix(1000, 0)
+	p := New(sqlx.NewDb(primaryDB, "sqlmock"), mockDBs, 5*time.Second, 5*time.Second)
+	p.now = func() time.Time { return now }
+
+	if db := p.DB("bill"); db != nil {
+		t.Error("DB before checking lag expected: nil, actual: replica")
+	}
+
+	expectPrimaryLSN(primaryMock, 100005)
+	expectLag(mocks[0], 90600, 1.5)
+	expectLag(mocks[1], 100005, 30.0)
+	p.CheckLag()
+	for i := 0; i < 3; i++ {
+		if db := p.DB("bill"); db != mockDBs[0] {
+			t.Errorf("DB with one replica lagging expected: replica 0, actual: %v", db)
+		}
+	}
+
+	expectPrimaryLSN(primaryMock, 100005)
+	expectLag(mocks[0], 90600, 0.0)
+	expectLag(mocks[1], 100005, 0.0)
+	p.CheckLag()
+	first, second := p.DB("bill"), p.DB("bill")
+	if first == nil || second == nil || first == second {
+		t.Errorf("DB with both replicas available expected: each in turn, actual: %v, %v", first, second)
+	}
+
+	p.RecordWrite("bill")
+	if db := p.DB("bill"); db != nil {
+		t.Error("DB after writing expected: nil, actual: replica")
+	}
+	if db := p.DB("ted"); db == nil {
+		t.Error("DB of other user after writing expected: replica, actual: nil")
+	}
+	now = now.Add(10 * time.Second)
+	if db := p.DB("bill"); db == nil {
+		t.Error("DB after write window expected: replica, actual: nil")
+	}
+	p.purgeWrites()
+	if len(p.lastWrites) != 0 {
+		t.Errorf("writes after purge expected: 0, actual: %v", len(p.lastWrites))
+	}
+
+	expectPrimaryLSN(primaryMock, 100005)
+	mocks[0].ExpectQuery("SELECT current_setting").WillReturnError(errors.New("connection refused"))
+	expectLag(mocks[1], 100005, nil)
+	p.CheckLag()
+	if db := p.DB("bill"); db != nil {
+		t.Error("DB with no replicas available expected: nil, actual: replica")
+	}
+
+	expectPrimaryLSN(primaryMock, 100005)
+	expectLag(mocks[0], 100005, 0.0)
+	expectLag(mocks[1], 100005, 0.0)
+	p.CheckLag()
+	if db := p.DB("bill"); db == nil {
+		t.Error("DB with both replicas caught up expected: replica, actual: nil")
+	}
+
+	// replicas can't be checked without the primary's WAL location
+	primaryMock.ExpectQuery("SELECT current_setting").WillReturnError(errors.New("connection refused"))
+	p.CheckLag()
+	if db := p.DB("bill"); db != nil {
+		t.Error("DB with primary unavailable expected: nil, actual: replica")
+	}
+
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("primary: there were unfulfilled expectations: %s", err)
+	}
+	for i, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("replica %v: there were unfulfilled expectations: %s", i, err)
+		}
+	}
+}
+
+func TestNilPool(t *testing.T) {
+	p := (*Pool)(nil)
+	p.RecordWrite("bill")
+	if db := p.DB("bill"); db != nil {
+		t.Error("nil pool DB expected: nil, actual: replica")
+	}
+	p.Close()
+}
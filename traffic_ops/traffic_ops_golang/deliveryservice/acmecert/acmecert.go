@@ -0,0 +1,205 @@
+package acmecert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Request requests a certificate for the delivery service from the ACME certificate authority configured in cdn.conf.
+// The certificate is issued in the background by the Renewer, which keeps it renewed until it's deleted.
+func Request(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"xmlid"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	if inf.Config.ACME == nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("ACME is not configured"), nil)
+		return
+	}
+	if !inf.Config.KeyStoreEnabled {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("the Riak service is unavailable"), errors.New("requesting ACME certificate: Riak is not configured"))
+		return
+	}
+
+	req := tc.DeliveryServiceACMEReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF { // the body is optional
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()), nil)
+		return
+	}
+	if err := req.Validate(inf.Tx.Tx); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, err, nil)
+		return
+	}
+
+	xmlID := inf.Params["xmlid"]
+	if userErr, sysErr, errCode := tenant.Check(inf.User, xmlID, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	dsID, ok, err := getDSID(inf.Tx.Tx, xmlID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service ID: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no DS with name "+xmlID), nil)
+		return
+	}
+	hostName, _, err := deliveryservice.GetSSLKeyHostName(inf.Tx.Tx, xmlID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("getting delivery service host name: "+err.Error()), nil)
+		return
+	}
+	if *req.ChallengeType == tc.ACMEChallengeTypeHTTP01 && strings.HasPrefix(hostName, "*") {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("HTTP delivery services need the wildcard certificate '"+hostName+"', which can only be issued with '"+string(tc.ACMEChallengeTypeDNS01)+"' challenges"), nil)
+		return
+	}
+
+	q := `
+INSERT INTO deliveryservice_acme (deliveryservice, challenge_type, tm_user) VALUES ($1, $2, $3)
+ON CONFLICT (deliveryservice) DO UPDATE SET
+challenge_type = $2, tm_user = $3, status = 'pending', attempts = 0, next_attempt = now(), last_error = NULL, last_updated = now()
+`
+	if _, err := inf.Tx.Tx.Exec(q, dsID, string(*req.ChallengeType), inf.User.ID); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting delivery service ACME certificate: "+err.Error()))
+		return
+	}
+	cert, _, err := getACMECert(inf.Tx.Tx, xmlID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service ACME certificate: "+err.Error()))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "DS: "+xmlID+", ID: "+strconv.Itoa(dsID)+", ACTION: Requested ACME SSL keys for "+hostName+" with "+string(*req.ChallengeType)+" challenges", inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Requested ACME SSL keys for "+xmlID+", they will be issued in the background", cert)
+}
+
+// Get returns the state of the delivery service's ACME certificate.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"xmlid"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	xmlID := inf.Params["xmlid"]
+	if userErr, sysErr, errCode := tenant.Check(inf.User, xmlID, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	cert, ok, err := getACMECert(inf.Tx.Tx, xmlID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service ACME certificate: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no ACME certificate was requested for delivery service "+xmlID), nil)
+		return
+	}
+	api.WriteResp(w, r, cert)
+}
+
+// Delete stops renewing the delivery service's ACME certificate. The delivery service's SSL keys are not deleted.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"xmlid"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	xmlID := inf.Params["xmlid"]
+	if userErr, sysErr, errCode := tenant.Check(inf.User, xmlID, inf.Tx.Tx); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	dsID := 0
+	if err := inf.Tx.Tx.QueryRow(`DELETE FROM deliveryservice_acme WHERE deliveryservice = (SELECT id FROM deliveryservice WHERE xml_id = $1) RETURNING deliveryservice`, xmlID).Scan(&dsID); err != nil {
+		if err == sql.ErrNoRows {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("no ACME certificate was requested for delivery service "+xmlID), nil)
+			return
+		}
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting delivery service ACME certificate: "+err.Error()))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "DS: "+xmlID+", ID: "+strconv.Itoa(dsID)+", ACTION: Stopped renewing ACME SSL keys", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "Stopped renewing ACME SSL keys for "+xmlID+", its current SSL keys were not deleted")
+}
+
+// ChallengeHandler returns the handler of ACME HTTP-01 challenges, which certificate authorities request from /.well-known/acme-challenge/{token} of the domain being validated.
+// It's unauthenticated, because certificate authorities can't log in. Challenges are only known while the Renewer is validating them.
+func ChallengeHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		keyAuth := ""
+		if err := db.QueryRow(`SELECT key_authorization FROM acme_challenge WHERE token = $1 AND expires > now()`, token).Scan(&keyAuth); err != nil {
+			if err != sql.ErrNoRows {
+				log.Errorln("getting ACME challenge: " + err.Error())
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ContentTypeTextPlain)
+		w.Write([]byte(keyAuth))
+	}
+}
+
+func getDSID(tx *sql.Tx, xmlID string) (int, bool, error) {
+	id := 0
+	if err := tx.QueryRow(`SELECT id FROM deliveryservice WHERE xml_id = $1`, xmlID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, errors.New("querying delivery service ID: " + err.Error())
+	}
+	return id, true, nil
+}
+
+func getACMECert(tx *sql.Tx, xmlID string) (tc.DeliveryServiceACME, bool, error) {
+	q := `
+SELECT ds.xml_id, a.challenge_type, a.status, a.expires, a.last_error, a.next_attempt, a.last_updated
+FROM deliveryservice_acme AS a
+JOIN deliveryservice AS ds ON ds.id = a.deliveryservice
+WHERE ds.xml_id = $1
+`
+	c := tc.DeliveryServiceACME{}
+	if err := tx.QueryRow(q, xmlID).Scan(&c.DeliveryService, &c.ChallengeType, &c.Status, &c.Expires, &c.LastError, &c.NextAttempt, &c.LastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.DeliveryServiceACME{}, false, nil
+		}
+		return tc.DeliveryServiceACME{}, false, errors.New("querying: " + err.Error())
+	}
+	return c, true, nil
+}
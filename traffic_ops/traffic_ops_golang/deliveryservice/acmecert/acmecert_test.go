@@ -0,0 +1,61 @@
+package acmecert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestChallengeHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT key_authorization FROM acme_challenge").WithArgs("tok1").WillReturnRows(sqlmock.NewRows([]string{"key_authorization"}).AddRow("tok1.thumbprint"))
+	mock.ExpectQuery("SELECT key_authorization FROM acme_challenge").WithArgs("tok2").WillReturnRows(sqlmock.NewRows([]string{"key_authorization"}))
+
+	h := ChallengeHandler(sqlx.NewDb(db, "sqlmock"))
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/.well-known/acme-challenge/tok1", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("ChallengeHandler known token expected code %v, actual: %v", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != "tok1.thumbprint" {
+		t.Errorf("ChallengeHandler known token expected body 'tok1.thumbprint', actual: '%v'", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/.well-known/acme-challenge/tok2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("ChallengeHandler unknown token expected code %v, actual: %v", http.StatusNotFound, w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be executed, actual: %v", err)
+	}
+}
@@ -0,0 +1,559 @@
+package acmecert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+
+	"golang.org/x/crypto/acme"
+)
+
+// OrderTimeout is the longest an ACME order may take, after any DNS-01 challenge records are served.
+const OrderTimeout = 10 * time.Minute
+
+// MaxRetryInterval is the longest time between attempts of a failed issuance.
+const MaxRetryInterval = 24 * time.Hour
+
+// RenewBatchSize is the maximum number of certificates claimed at once. They're issued one at a time.
+const RenewBatchSize = 10
+
+// ChallengeTTL is the TTL of the static DNS entries of DNS-01 challenges.
+const ChallengeTTL = 60
+
+// ChallengeExpiry is how long HTTP-01 challenges are served, if the Renewer fails to delete them.
+const ChallengeExpiry = time.Hour
+
+// DNSPollInterval is how often to check whether Traffic Router serves a DNS-01 challenge record.
+const DNSPollInterval = 10 * time.Second
+
+// ChallengeHostPrefix is the label prepended to a domain, to get the name of its DNS-01 challenge record.
+const ChallengeHostPrefix = "_acme-challenge"
+
+// Renewer issues the ACME certificates requested for delivery services, and renews them before they expire.
+// Certificates are claimed from the database, so any number of Traffic Ops instances may run a Renewer; each certificate is issued by only one of them at a time.
+type Renewer struct {
+	DB     *sql.DB
+	Client *http.Client
+	Cfg    config.Config
+}
+
+// NewRenewer creates a Renewer from the given config, whose ACME section must not be nil.
+func NewRenewer(db *sql.DB, cfg config.Config) *Renewer {
+	return &Renewer{
+		DB:     db,
+		Client: &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.ACME.Insecure}}},
+		Cfg:    cfg,
+	}
+}
+
+// Run issues and renews certificates forever. It should be called in its own goroutine.
+func (rn *Renewer) Run() {
+	interval := time.Duration(rn.Cfg.ACME.PollIntervalSeconds) * time.Second
+	for {
+		if _, err := rn.DB.Exec(`DELETE FROM acme_challenge WHERE expires < now()`); err != nil {
+			log.Errorln("deleting expired ACME challenges: " + err.Error())
+		}
+		n, err := rn.RenewBatch()
+		if err != nil {
+			log.Errorln("renewing ACME certificates: " + err.Error())
+		}
+		if n < RenewBatchSize {
+			time.Sleep(interval) // if the batch was full, there may be more due, so don't wait
+		}
+	}
+}
+
+// dueCert is a delivery service certificate claimed for issuance.
+type dueCert struct {
+	DSID          int
+	XMLID         string
+	ChallengeType tc.ACMEChallengeType
+	Status        tc.ACMEStatus
+	UserID        *int
+	Attempts      int
+}
+
+// RenewBatch claims up to RenewBatchSize certificates which are requested, failed and due to be retried, or near expiry, and issues them. Returns the number of certificates claimed.
+func (rn *Renewer) RenewBatch() (int, error) {
+	certs, err := rn.claim()
+	if err != nil {
+		return 0, errors.New("claiming certificates: " + err.Error())
+	}
+	for _, cert := range certs {
+		expires, issueErr := rn.issue(cert)
+		if err := rn.record(cert, expires, issueErr); err != nil {
+			log.Errorln("recording ACME certificate of delivery service '" + cert.XMLID + "': " + err.Error())
+		}
+	}
+	return len(certs), nil
+}
+
+// claim returns the certificates which are due, and pushes their next attempt back far enough that no other Renewer will claim them while they're being issued.
+func (rn *Renewer) claim() ([]dueCert, error) {
+	leaseSecs := (rn.Cfg.ACME.DNSPropagationTimeoutSeconds + int(OrderTimeout/time.Second)) * 2
+	qry := `
+WITH claimed AS (
+  UPDATE deliveryservice_acme SET next_attempt = now() + ($1 || ' SECONDS')::INTERVAL
+  WHERE deliveryservice IN (
+    SELECT deliveryservice FROM deliveryservice_acme
+    WHERE next_attempt <= now()
+    AND (status <> 'issued' OR expires IS NULL OR expires <= now() + ($2 || ' DAYS')::INTERVAL)
+    ORDER BY next_attempt
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING deliveryservice, challenge_type, status, tm_user, attempts
+)
+SELECT c.deliveryservice, ds.xml_id, c.challenge_type, c.status, c.tm_user, c.attempts
+FROM claimed c JOIN deliveryservice ds ON ds.id = c.deliveryservice
+ORDER BY c.deliveryservice
+`
+	rows, err := rn.DB.Query(qry, leaseSecs, rn.Cfg.ACME.RenewBeforeDays, RenewBatchSize)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	certs := []dueCert{}
+	for rows.Next() {
+		c := dueCert{}
+		if err := rows.Scan(&c.DSID, &c.XMLID, &c.ChallengeType, &c.Status, &c.UserID, &c.Attempts); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		certs = append(certs, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating: " + err.Error())
+	}
+	return certs, nil
+}
+
+// record saves the result of issuing the certificate. If it failed, the issuance is scheduled to be retried.
+func (rn *Renewer) record(c dueCert, expires time.Time, issueErr error) error {
+	if issueErr == nil {
+		_, err := rn.DB.Exec(`UPDATE deliveryservice_acme SET status = 'issued', expires = $2, attempts = 0, next_attempt = now(), last_error = NULL, last_updated = now() WHERE deliveryservice = $1`, c.DSID, expires)
+		return err
+	}
+	attempts := c.Attempts + 1
+	log.Warnln("ACME certificate of delivery service '" + c.XMLID + "' attempt " + strconv.Itoa(attempts) + " failed: " + issueErr.Error())
+	retrySecs := int(RetryInterval(time.Duration(rn.Cfg.ACME.RetryBaseSeconds)*time.Second, attempts) / time.Second)
+	_, err := rn.DB.Exec(`
+UPDATE deliveryservice_acme SET status = 'failed', attempts = $2, next_attempt = now() + ($3 || ' SECONDS')::INTERVAL, last_error = $4, last_updated = now()
+WHERE deliveryservice = $1
+`, c.DSID, attempts, retrySecs, issueErr.Error())
+	return err
+}
+
+// RetryInterval returns how long to wait before the next attempt of an issuance which has failed the given number of attempts: the base interval, doubled for each attempt after the first, up to MaxRetryInterval.
+func RetryInterval(base time.Duration, attempts int) time.Duration {
+	interval := base
+	for i := 1; i < attempts; i++ {
+		interval *= 2
+		if interval >= MaxRetryInterval {
+			return MaxRetryInterval
+		}
+	}
+	return interval
+}
+
+// dsInfo is the delivery service data needed to issue its certificate.
+type dsInfo struct {
+	CDNID      int
+	CDNName    string
+	HostName   string
+	KeyVersion int64
+	User       auth.CurrentUser
+}
+
+// issue issues the certificate from the ACME certificate authority, answering its challenges, and saves it as the delivery service's SSL keys. Returns when the certificate expires.
+func (rn *Renewer) issue(c dueCert) (time.Time, error) {
+	ds, err := rn.getDSInfo(c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(rn.Cfg.ACME.DNSPropagationTimeoutSeconds)*time.Second+OrderTimeout)
+	defer cancel()
+
+	client, err := rn.client(ctx)
+	if err != nil {
+		return time.Time{}, errors.New("getting ACME account: " + err.Error())
+	}
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(ds.HostName))
+	if err != nil {
+		return time.Time{}, errors.New("creating ACME order: " + err.Error())
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := rn.authorize(ctx, client, c, ds, authzURL); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return time.Time{}, errors.New("waiting for ACME order: " + err.Error())
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048) // RSA, because Traffic Router doesn't support ECDSA keys for HTTP delivery services
+	if err != nil {
+		return time.Time{}, errors.New("generating key: " + err.Error())
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: ds.HostName},
+		DNSNames:           []string{ds.HostName},
+		SignatureAlgorithm: x509.SHA256WithRSA,
+	}, key)
+	if err != nil {
+		return time.Time{}, errors.New("creating certificate request: " + err.Error())
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csrDER, true)
+	if err != nil {
+		return time.Time{}, errors.New("finalizing ACME order: " + err.Error())
+	}
+	crtPEM, expires, err := EncodeChain(chain)
+	if err != nil {
+		return time.Time{}, errors.New("encoding certificate: " + err.Error())
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	if err := rn.save(c, ds, crtPEM, keyPEM, csrPEM, expires); err != nil {
+		return time.Time{}, errors.New("saving certificate: " + err.Error())
+	}
+	log.Infoln("issued ACME certificate of delivery service '" + c.XMLID + "' for " + ds.HostName + ", expires " + expires.Format(time.RFC3339))
+	return expires, nil
+}
+
+// authorize answers the challenge of the given authorization, and waits for the certificate authority to validate it.
+func (rn *Renewer) authorize(ctx context.Context, client *acme.Client, c dueCert, ds dsInfo, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return errors.New("getting ACME authorization: " + err.Error())
+	}
+	if authz.Status == acme.StatusValid {
+		return nil // previously validated, and still valid
+	}
+	chal := (*acme.Challenge)(nil)
+	for _, ch := range authz.Challenges {
+		if ch.Type == string(c.ChallengeType) {
+			chal = ch
+			break
+		}
+	}
+	if chal == nil {
+		return errors.New("ACME authorization of '" + authz.Identifier.Value + "' has no " + string(c.ChallengeType) + " challenge")
+	}
+
+	switch c.ChallengeType {
+	case tc.ACMEChallengeTypeDNS01:
+		record, err := client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return errors.New("creating DNS-01 challenge record: " + err.Error())
+		}
+		entry, err := rn.addDNSChallenge(c, ds, authz.Identifier.Value, record)
+		if err != nil {
+			return errors.New("adding DNS-01 challenge record: " + err.Error())
+		}
+		defer func() {
+			if err := rn.deleteDNSChallenge(c, ds, entry); err != nil {
+				log.Errorln("deleting DNS-01 challenge record of delivery service '" + c.XMLID + "': " + err.Error())
+			}
+		}()
+		fqdn := ChallengeHostPrefix + "." + authz.Identifier.Value
+		if !waitForTXT(ctx, fqdn, record, time.Duration(rn.Cfg.ACME.DNSPropagationTimeoutSeconds)*time.Second) {
+			log.Warnln("ACME certificate of delivery service '" + c.XMLID + "': Traffic Router isn't serving the DNS-01 challenge record " + fqdn + " yet, asking the certificate authority to validate it anyway")
+		}
+	case tc.ACMEChallengeTypeHTTP01:
+		keyAuth, err := client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return errors.New("creating HTTP-01 challenge response: " + err.Error())
+		}
+		if _, err := rn.DB.Exec(`INSERT INTO acme_challenge (token, key_authorization, domain, expires) VALUES ($1, $2, $3, now() + ($4 || ' SECONDS')::INTERVAL)`, chal.Token, keyAuth, authz.Identifier.Value, int(ChallengeExpiry/time.Second)); err != nil {
+			return errors.New("adding HTTP-01 challenge: " + err.Error())
+		}
+		defer func() {
+			if _, err := rn.DB.Exec(`DELETE FROM acme_challenge WHERE token = $1`, chal.Token); err != nil {
+				log.Errorln("deleting HTTP-01 challenge of delivery service '" + c.XMLID + "': " + err.Error())
+			}
+		}()
+	}
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return errors.New("accepting ACME challenge: " + err.Error())
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return errors.New("waiting for ACME authorization of '" + authz.Identifier.Value + "': " + err.Error())
+	}
+	return nil
+}
+
+// ChallengeStaticHost returns the host of the static DNS entry of the DNS-01 challenge of the given domain, relative to the domain of the delivery service, as Traffic Router serves static DNS entries.
+// The domain of a delivery service is its certificate host name without the first label, e.g. 'ds.cdn.example' for both 'edge.ds.cdn.example' and '*.ds.cdn.example'.
+// Returns false if the domain isn't in the delivery service domain, so Traffic Router can't serve its challenge.
+func ChallengeStaticHost(hostName string, domain string) (string, bool) {
+	dsDomain := hostName[strings.Index(hostName, ".")+1:]
+	if domain == dsDomain {
+		return ChallengeHostPrefix, true
+	}
+	if !strings.HasSuffix(domain, "."+dsDomain) {
+		return "", false
+	}
+	return ChallengeHostPrefix + "." + strings.TrimSuffix(domain, "."+dsDomain), true
+}
+
+// addDNSChallenge adds the DNS-01 challenge record of the given domain as a static DNS entry of the delivery service, both in the database and in the CDN's current snapshot, so Traffic Router serves it without snapshotting any other pending changes.
+func (rn *Renewer) addDNSChallenge(c dueCert, ds dsInfo, domain string, record string) (tc.CRConfigStaticDNSEntry, error) {
+	host, ok := ChallengeStaticHost(ds.HostName, domain)
+	if !ok {
+		return tc.CRConfigStaticDNSEntry{}, errors.New("domain '" + domain + "' is not served by Traffic Router")
+	}
+	entry := tc.CRConfigStaticDNSEntry{Name: host, TTL: ChallengeTTL, Type: "TXT", Value: record}
+	err := rn.withTx(func(tx *sql.Tx) error {
+		q := `INSERT INTO staticdnsentry (host, address, type, ttl, deliveryservice) VALUES ($1, $2, (SELECT id FROM type WHERE name = 'TXT_RECORD'), $3, $4)`
+		if _, err := tx.Exec(q, entry.Name, entry.Value, entry.TTL, c.DSID); err != nil {
+			return errors.New("inserting static DNS entry: " + err.Error())
+		}
+		ok, err := crconfig.UpdateSnapshotStaticDNSEntries(tx, ds.CDNName, c.XMLID, ds.User.UserName, rn.Cfg.SnapshotHistory.MaxSnapshots, func(entries []tc.CRConfigStaticDNSEntry) []tc.CRConfigStaticDNSEntry {
+			return append(entries, entry)
+		})
+		if err != nil {
+			return errors.New("updating snapshot: " + err.Error())
+		} else if !ok {
+			return errors.New("delivery service is not in the snapshot of CDN '" + ds.CDNName + "', snapshot the CDN first")
+		}
+		return nil
+	})
+	return entry, err
+}
+
+// deleteDNSChallenge removes the static DNS entry added by addDNSChallenge.
+func (rn *Renewer) deleteDNSChallenge(c dueCert, ds dsInfo, entry tc.CRConfigStaticDNSEntry) error {
+	return rn.withTx(func(tx *sql.Tx) error {
+		q := `DELETE FROM staticdnsentry WHERE host = $1 AND address = $2 AND deliveryservice = $3`
+		if _, err := tx.Exec(q, entry.Name, entry.Value, c.DSID); err != nil {
+			return errors.New("deleting static DNS entry: " + err.Error())
+		}
+		_, err := crconfig.UpdateSnapshotStaticDNSEntries(tx, ds.CDNName, c.XMLID, ds.User.UserName, rn.Cfg.SnapshotHistory.MaxSnapshots, func(entries []tc.CRConfigStaticDNSEntry) []tc.CRConfigStaticDNSEntry {
+			kept := []tc.CRConfigStaticDNSEntry{}
+			for _, e := range entries {
+				if e != entry {
+					kept = append(kept, e)
+				}
+			}
+			return kept
+		})
+		if err != nil {
+			return errors.New("updating snapshot: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// waitForTXT returns whether the TXT record of the given name has the given value, checking until the timeout expires.
+func waitForTXT(ctx context.Context, name string, value string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		vals, err := net.DefaultResolver.LookupTXT(ctx, name)
+		if err == nil {
+			for _, val := range vals {
+				if val == value {
+					return true
+				}
+			}
+		}
+		if time.Now().Add(DNSPollInterval).After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(DNSPollInterval):
+		}
+	}
+}
+
+// EncodeChain returns the PEM of the given DER certificate chain, leaf first, and when the leaf expires.
+func EncodeChain(chain [][]byte) ([]byte, time.Time, error) {
+	if len(chain) == 0 {
+		return nil, time.Time{}, errors.New("empty certificate chain")
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, time.Time{}, errors.New("parsing certificate: " + err.Error())
+	}
+	buf := bytes.Buffer{}
+	for _, der := range chain {
+		if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			return nil, time.Time{}, errors.New("pem-encoding certificate: " + err.Error())
+		}
+	}
+	return buf.Bytes(), leaf.NotAfter, nil
+}
+
+// save stores the issued certificate as a new version of the delivery service's SSL keys, records it in the change log, and queues updates of the CDN's servers.
+func (rn *Renewer) save(c dueCert, ds dsInfo, crtPEM []byte, keyPEM []byte, csrPEM []byte, expires time.Time) error {
+	return rn.withTx(func(tx *sql.Tx) error {
+		version := ds.KeyVersion + 1
+		keys := tc.DeliveryServiceSSLKeys{
+			CDN:             ds.CDNName,
+			DeliveryService: c.XMLID,
+			Hostname:        ds.HostName,
+			Key:             c.XMLID,
+			Version:         util.JSONIntStr(version),
+			Certificate: tc.DeliveryServiceSSLKeysCertificate{
+				Crt: string(deliveryservice.EncodePEMToLegacyPerlRiakFormat(crtPEM)),
+				Key: string(deliveryservice.EncodePEMToLegacyPerlRiakFormat(keyPEM)),
+				CSR: string(deliveryservice.EncodePEMToLegacyPerlRiakFormat(csrPEM)),
+			},
+		}
+		if err := riaksvc.PutDeliveryServiceSSLKeysObj(keys, tx, rn.Cfg.RiakAuthOptions, rn.Cfg.RiakPort); err != nil {
+			return errors.New("putting SSL keys: " + err.Error())
+		}
+		if _, err := tx.Exec(`UPDATE deliveryservice SET ssl_key_version = $1 WHERE id = $2`, version, c.DSID); err != nil {
+			return errors.New("updating delivery service ssl_key_version: " + err.Error())
+		}
+		if _, err := tx.Exec(`UPDATE server SET upd_pending = TRUE WHERE cdn_id = $1`, ds.CDNID); err != nil {
+			return errors.New("queueing updates: " + err.Error())
+		}
+		action := "Renewed"
+		if c.Status == tc.ACMEStatusPending {
+			action = "Added"
+		}
+		msg := "DS: " + c.XMLID + ", ID: " + strconv.Itoa(c.DSID) + ", ACTION: " + action + " ACME SSL keys for " + ds.HostName + " expiring " + expires.UTC().Format(time.RFC3339) + ", queued CDN " + ds.CDNName + " updates"
+		return api.CreateChangeLogRawErr(api.ApiChange, msg, &ds.User, tx)
+	})
+}
+
+// getDSInfo returns the data of the delivery service needed to issue its certificate.
+func (rn *Renewer) getDSInfo(c dueCert) (dsInfo, error) {
+	ds := dsInfo{}
+	if c.UserID == nil {
+		return dsInfo{}, errors.New("the user who requested the certificate was deleted, request it again")
+	}
+	err := rn.withTx(func(tx *sql.Tx) error {
+		q := `
+SELECT cdn.id, cdn.name, COALESCE(ds.ssl_key_version, 0), u.id, u.username
+FROM deliveryservice AS ds
+JOIN cdn ON cdn.id = ds.cdn_id
+JOIN tm_user AS u ON u.id = $2
+WHERE ds.id = $1
+`
+		if err := tx.QueryRow(q, c.DSID, *c.UserID).Scan(&ds.CDNID, &ds.CDNName, &ds.KeyVersion, &ds.User.ID, &ds.User.UserName); err != nil {
+			return errors.New("querying delivery service: " + err.Error())
+		}
+		hostName, ok, err := deliveryservice.GetSSLKeyHostName(tx, c.XMLID)
+		if err != nil {
+			return errors.New("getting delivery service host name: " + err.Error())
+		} else if !ok {
+			return errors.New("delivery service not found")
+		}
+		ds.HostName = hostName
+		return nil
+	})
+	return ds, err
+}
+
+// client returns the ACME client of the configured account, creating the account if it doesn't exist.
+// By creating the account, Traffic Ops agrees to the certificate authority's terms of service.
+func (rn *Renewer) client(ctx context.Context) (*acme.Client, error) {
+	email := rn.Cfg.ACME.Email
+	dirURL := rn.Cfg.ACME.DirectoryURL
+	client := &acme.Client{DirectoryURL: dirURL, HTTPClient: rn.Client, UserAgent: "traffic_ops_golang"}
+
+	keyPEM := ""
+	uri := ""
+	err := rn.DB.QueryRow(`SELECT private_key, uri FROM acme_account WHERE email = $1 AND directory_url = $2`, email, dirURL).Scan(&keyPEM, &uri)
+	if err == nil {
+		key, err := decodeAccountKey(keyPEM)
+		if err != nil {
+			return nil, errors.New("decoding account key: " + err.Error())
+		}
+		client.Key = key
+		client.KID = acme.KeyID(uri)
+		return client, nil
+	} else if err != sql.ErrNoRows {
+		return nil, errors.New("querying account: " + err.Error())
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.New("generating account key: " + err.Error())
+	}
+	client.Key = key
+	acct := &acme.Account{}
+	if email != "" {
+		acct.Contact = []string{"mailto:" + email}
+	}
+	if acct, err = client.Register(ctx, acct, acme.AcceptTOS); err != nil {
+		return nil, errors.New("registering account: " + err.Error())
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, errors.New("marshalling account key: " + err.Error())
+	}
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	if _, err := rn.DB.Exec(`INSERT INTO acme_account (email, directory_url, private_key, uri) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, email, dirURL, keyPEM, acct.URI); err != nil {
+		return nil, errors.New("inserting account: " + err.Error())
+	}
+	return client, nil
+}
+
+func decodeAccountKey(keyPEM string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// withTx calls f in a new transaction, committing it if f succeeds.
+func (rn *Renewer) withTx(f func(tx *sql.Tx) error) error {
+	tx, err := rn.DB.Begin()
+	if err != nil {
+		return errors.New("beginning transaction: " + err.Error())
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
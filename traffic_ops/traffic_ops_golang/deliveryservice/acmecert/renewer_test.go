@@ -0,0 +1,131 @@
+package acmecert
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestChallengeStaticHost(t *testing.T) {
+	type testCase struct {
+		hostName string
+		domain   string
+		expected string
+		ok       bool
+	}
+	testCases := []testCase{
+		{"*.ds1.cdn.example", "ds1.cdn.example", "_acme-challenge", true},
+		{"edge.ds1.cdn.example", "edge.ds1.cdn.example", "_acme-challenge.edge", true},
+		{"edge.ds1.cdn.example", "ds1.cdn.example", "_acme-challenge", true},
+		{"edge.ds1.cdn.example", "www.customer.example", "", false},
+		{"edge.ds1.cdn.example", "xds1.cdn.example", "", false},
+	}
+	for _, tc := range testCases {
+		host, ok := ChallengeStaticHost(tc.hostName, tc.domain)
+		if ok != tc.ok || host != tc.expected {
+			t.Errorf("ChallengeStaticHost(%v, %v) expected %v %v, actual: %v %v", tc.hostName, tc.domain, tc.expected, tc.ok, host, ok)
+		}
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	base := 10 * time.Minute
+	if actual := RetryInterval(base, 1); actual != base {
+		t.Errorf("RetryInterval first attempt expected %v, actual: %v", base, actual)
+	}
+	if actual := RetryInterval(base, 3); actual != 40*time.Minute {
+		t.Errorf("RetryInterval third attempt expected %v, actual: %v", 40*time.Minute, actual)
+	}
+	if actual := RetryInterval(base, 20); actual != MaxRetryInterval {
+		t.Errorf("RetryInterval many attempts expected %v, actual: %v", MaxRetryInterval, actual)
+	}
+}
+
+func TestEncodeChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second).UTC()
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "edge.ds1.cdn.example"}, NotBefore: time.Now(), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	chainPEM, expires, err := EncodeChain([][]byte{der, der})
+	if err != nil {
+		t.Fatalf("EncodeChain expected nil error, actual: %v", err)
+	}
+	if !expires.Equal(notAfter) {
+		t.Errorf("EncodeChain expected expiration %v, actual: %v", notAfter, expires)
+	}
+	if n := bytes.Count(chainPEM, []byte("-----BEGIN CERTIFICATE-----")); n != 2 {
+		t.Errorf("EncodeChain expected 2 certificates, actual: %v", n)
+	}
+	if block, _ := pem.Decode(chainPEM); block == nil || !bytes.Equal(block.Bytes, der) {
+		t.Errorf("EncodeChain expected the leaf first")
+	}
+
+	if _, _, err := EncodeChain(nil); err == nil {
+		t.Errorf("EncodeChain empty chain expected error, actual: nil")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rn := &Renewer{DB: db, Cfg: config.Config{ACME: &config.ConfigACME{RetryBaseSeconds: 600}}}
+	cert := dueCert{DSID: 1, XMLID: "ds1", ChallengeType: tc.ACMEChallengeTypeDNS01, Status: tc.ACMEStatusIssued, Attempts: 1}
+	expires := time.Now().Add(90 * 24 * time.Hour)
+
+	mock.ExpectExec("UPDATE deliveryservice_acme SET status = 'issued'").WithArgs(1, expires).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := rn.record(cert, expires, nil); err != nil {
+		t.Errorf("record success expected nil error, actual: %v", err)
+	}
+
+	mock.ExpectExec("UPDATE deliveryservice_acme SET status = 'failed'").WithArgs(1, 2, 1200, "validation failed").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := rn.record(cert, time.Time{}, errors.New("validation failed")); err != nil {
+		t.Errorf("record failure expected nil error, actual: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be executed, actual: %v", err)
+	}
+}
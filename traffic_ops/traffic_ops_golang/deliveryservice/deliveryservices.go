@@ -109,7 +109,7 @@ func CreateV12(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, status, userErr, sysErr := createV12(w, r, inf, ds)
+	res, status, userErr, sysErr := createV12(inf, ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -131,7 +131,7 @@ func CreateV13(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, status, userErr, sysErr := createV13(w, r, inf, ds)
+	res, status, userErr, sysErr := createV13(inf, ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -154,7 +154,7 @@ func CreateV14(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, status, userErr, sysErr := createV14(w, r, inf, ds)
+	res, status, userErr, sysErr := createV14(inf, ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -162,18 +162,18 @@ func CreateV14(w http.ResponseWriter, r *http.Request) {
 	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Deliveryservice creation was successful.", []tc.DeliveryServiceNullableV14{*res})
 }
 
-func createV12(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV12) (*tc.DeliveryServiceNullableV12, int, error, error) {
+func createV12(inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV12) (*tc.DeliveryServiceNullableV12, int, error, error) {
 	dsV13 := tc.DeliveryServiceNullableV13{DeliveryServiceNullableV12: reqDS}
-	res, status, userErr, sysErr := createV13(w, r, inf, dsV13)
+	res, status, userErr, sysErr := createV13(inf, dsV13)
 	if res != nil {
 		return &res.DeliveryServiceNullableV12, status, userErr, sysErr
 	}
 	return nil, status, userErr, sysErr
 }
 
-func createV13(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV13) (*tc.DeliveryServiceNullableV13, int, error, error) {
+func createV13(inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV13) (*tc.DeliveryServiceNullableV13, int, error, error) {
 	dsV14 := tc.DeliveryServiceNullableV14{DeliveryServiceNullableV13: reqDS}
-	res, status, userErr, sysErr := createV14(w, r, inf, dsV14)
+	res, status, userErr, sysErr := createV14(inf, dsV14)
 	if res != nil {
 		return &res.DeliveryServiceNullableV13, status, userErr, sysErr
 	}
@@ -181,7 +181,7 @@ func createV13(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 }
 
 // create creates the given ds in the database, and returns the DS with its id and other fields created on insert set. On error, the HTTP status code, user error, and system error are returned. The status code SHOULD NOT be used, if both errors are nil.
-func createV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
+func createV14(inf *api.APIInfo, reqDS tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
 	ds := tc.DeliveryServiceNullable(reqDS)
 	user := inf.User
 	tx := inf.Tx.Tx
@@ -255,7 +255,8 @@ func createV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS t
 		&ds.TRRequestHeaders,
 		&ds.TRResponseHeaders,
 		&ds.TypeID,
-		&ds.XMLID)
+		&ds.XMLID,
+		&ds.Topology)
 
 	if err != nil {
 		usrErr, sysErr, code := api.ParseDBError(err)
@@ -380,7 +381,7 @@ func (ds *TODeliveryService) Read() ([]interface{}, error, error, int) {
 	}
 
 	returnable := []interface{}{}
-	dses, userErr, sysErr, errCode := readGetDeliveryServices(ds.APIInfo().Params, ds.APIInfo().Tx, ds.APIInfo().User)
+	dses, userErr, sysErr, errCode := readGetDeliveryServices(ds.APIInfo())
 
 	if sysErr != nil {
 		sysErr = errors.New("reading dses: " + sysErr.Error())
@@ -423,7 +424,7 @@ func UpdateV12(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
-	res, status, userErr, sysErr := updateV12(w, r, inf, &ds)
+	res, status, userErr, sysErr := updateV12(inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -448,7 +449,7 @@ func UpdateV13(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
-	res, status, userErr, sysErr := updateV13(w, r, inf, &ds)
+	res, status, userErr, sysErr := updateV13(inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -473,7 +474,7 @@ func UpdateV14(w http.ResponseWriter, r *http.Request) {
 	}
 	ds.ID = &id
 
-	res, status, userErr, sysErr := updateV14(w, r, inf, &ds)
+	res, status, userErr, sysErr := updateV14(inf, &ds)
 	if userErr != nil || sysErr != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, status, userErr, sysErr)
 		return
@@ -481,7 +482,7 @@ func UpdateV14(w http.ResponseWriter, r *http.Request) {
 	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "Deliveryservice update was successful.", []tc.DeliveryServiceNullableV14{*res})
 }
 
-func updateV12(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV12) (*tc.DeliveryServiceNullableV12, int, error, error) {
+func updateV12(inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV12) (*tc.DeliveryServiceNullableV12, int, error, error) {
 	dsV13 := tc.DeliveryServiceNullableV13{DeliveryServiceNullableV12: *reqDS}
 	// query the DB for existing 1.3 fields in order to "upgrade" this 1.2 request into a 1.3 request
 	query := `
@@ -511,20 +512,21 @@ WHERE
 		*dsV13.DeepCachingType = tc.DeepCachingTypeFromString(string(*dsV13.DeepCachingType))
 	}
 
-	res, status, userErr, sysErr := updateV13(w, r, inf, &dsV13)
+	res, status, userErr, sysErr := updateV13(inf, &dsV13)
 	if res != nil {
 		return &res.DeliveryServiceNullableV12, status, userErr, sysErr
 	}
 	return nil, status, userErr, sysErr
 }
 
-func updateV13(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV13) (*tc.DeliveryServiceNullableV13, int, error, error) {
+func updateV13(inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV13) (*tc.DeliveryServiceNullableV13, int, error, error) {
 	dsV14 := tc.DeliveryServiceNullableV14{DeliveryServiceNullableV13: *reqDS}
 	// query the DB for existing 1.4 fields in order to "upgrade" this 1.3 request into a 1.4 request
 	query := `
 SELECT
   ds.consistent_hash_regex,
   ds.max_origin_connections,
+  ds.topology,
   (SELECT ARRAY_AGG(name ORDER BY name)
     FROM deliveryservice_consistent_hash_query_param
     WHERE deliveryservice_id = ds.id) AS query_keys
@@ -535,6 +537,7 @@ WHERE
 	if err := inf.Tx.Tx.QueryRow(query, *reqDS.ID).Scan(
 		&dsV14.ConsistentHashRegex,
 		&dsV14.MaxOriginConnections,
+		&dsV14.Topology,
 		pq.Array(&dsV14.ConsistentHashQueryParams),
 	); err != nil {
 		if err == sql.ErrNoRows {
@@ -542,14 +545,14 @@ WHERE
 		}
 		return nil, http.StatusInternalServerError, nil, fmt.Errorf("querying delivery service ID %d: %s", *dsV14.ID, err.Error())
 	}
-	res, status, userErr, sysErr := updateV14(w, r, inf, &dsV14)
+	res, status, userErr, sysErr := updateV14(inf, &dsV14)
 	if res != nil {
 		return &res.DeliveryServiceNullableV13, status, userErr, sysErr
 	}
 	return nil, status, userErr, sysErr
 }
 
-func updateV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
+func updateV14(inf *api.APIInfo, reqDS *tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
 	converted := tc.DeliveryServiceNullable(*reqDS)
 	ds := &converted
 	tx := inf.Tx.Tx
@@ -649,6 +652,7 @@ func updateV14(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, reqDS *
 		&ds.AnonymousBlockingEnabled,
 		&ds.ConsistentHashRegex,
 		&ds.MaxOriginConnections,
+		&ds.Topology,
 		&ds.ID)
 
 	if err != nil {
@@ -797,7 +801,50 @@ func (v *TODeliveryService) DeleteQuery() string {
 	return `DELETE FROM deliveryservice WHERE id = :id`
 }
 
-func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.DeliveryServiceNullable, error, error, int) {
+// Create creates the given delivery service in the transaction of inf, as the latest version's create handler does, including its tenancy checks and change log entry.
+// On error, the HTTP status code, user error, and system error are returned. The status code SHOULD NOT be used, if both errors are nil.
+func Create(inf *api.APIInfo, ds tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
+	return createV14(inf, ds)
+}
+
+// Update updates the given delivery service, which must have its ID set, in the transaction of inf, as the latest version's update handler does.
+// On error, the HTTP status code, user error, and system error are returned. The status code SHOULD NOT be used, if both errors are nil.
+func Update(inf *api.APIInfo, ds tc.DeliveryServiceNullableV14) (*tc.DeliveryServiceNullableV14, int, error, error) {
+	return updateV14(inf, &ds)
+}
+
+// DeleteByID deletes the delivery service with the given ID in the transaction of inf, as the delete handler does, including its tenancy check and change log entry.
+func DeleteByID(inf *api.APIInfo, id int) (error, error, int) {
+	ds := &TODeliveryService{APIInfoImpl: api.APIInfoImpl{ReqInfo: inf}}
+	ds.ID = &id
+	if authorized, err := ds.IsTenantAuthorized(inf.User); err != nil {
+		return nil, errors.New("checking tenant: " + err.Error()), http.StatusInternalServerError
+	} else if !authorized {
+		return errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+	}
+	if userErr, sysErr, errCode := ds.Delete(); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+	if err := api.CreateChangeLog(api.ApiChange, api.Deleted, ds, inf.User, inf.Tx.Tx); err != nil {
+		return nil, errors.New("inserting changelog: " + err.Error()), http.StatusInternalServerError
+	}
+	return nil, nil, http.StatusOK
+}
+
+// GetByID returns the delivery service with the given ID, and whether it exists.
+func GetByID(tx *sqlx.Tx, id int) (*tc.DeliveryServiceNullable, bool, error) {
+	dses, userErr, sysErr, _ := GetDeliveryServices(selectQuery()+`WHERE ds.id = :id`, map[string]interface{}{"id": id}, tx)
+	if userErr != nil || sysErr != nil {
+		return nil, false, util.JoinErrs([]error{userErr, sysErr})
+	}
+	if len(dses) == 0 {
+		return nil, false, nil
+	}
+	return &dses[0], true, nil
+}
+
+func readGetDeliveryServices(inf *api.APIInfo) ([]tc.DeliveryServiceNullable, error, error, int) {
+	params, tx, user := inf.Params, inf.Tx, inf.User
 	if strings.HasSuffix(params["id"], ".json") {
 		params["id"] = params["id"][:len(params["id"])-len(".json")]
 	}
@@ -833,6 +880,10 @@ func readGetDeliveryServices(params map[string]string, tx *sqlx.Tx, user *auth.C
 
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "ds.tenant_id", tenantIDs)
 
+	if err := inf.CountResults(selectQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting delivery services: " + err.Error()), http.StatusInternalServerError
+	}
+
 	query := selectQuery() + where + orderBy + pagination
 
 	log.Debugln("generated deliveryServices query: " + query)
@@ -1034,6 +1085,7 @@ func GetDeliveryServices(query string, queryValues map[string]interface{}, tx *s
 			&ds.Type,
 			&ds.TypeID,
 			&ds.XMLID,
+			&ds.Topology,
 			&cdnDomain)
 
 		if err != nil {
@@ -1564,6 +1616,7 @@ ds.tr_response_headers,
 type.name,
 ds.type as type_id,
 ds.xml_id,
+ds.topology,
 cdn.domain_name as cdn_domain
 from deliveryservice as ds
 JOIN type ON ds.type = type.id
@@ -1628,8 +1681,9 @@ type=$48,
 xml_id=$49,
 anonymous_blocking_enabled=$50,
 consistent_hash_regex=$51,
-max_origin_connections=$52
-WHERE id=$53
+max_origin_connections=$52,
+topology=$53
+WHERE id=$54
 RETURNING last_updated
 `
 }
@@ -1688,9 +1742,10 @@ tenant_id,
 tr_request_headers,
 tr_response_headers,
 type,
-xml_id
+xml_id,
+topology
 )
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47,$48,$49,$50,$51,$52,$53)
 RETURNING id, last_updated
 `
 }
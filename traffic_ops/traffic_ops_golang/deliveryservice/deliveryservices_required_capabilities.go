@@ -200,6 +200,9 @@ func (rc *RequiredCapability) getCapabilities(tenantIDs []int) ([]tc.DeliverySer
 	}
 
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "ds.tenant_id", tenantIDs)
+	if err := rc.APIInfo().CountResults(rc.SelectQuery()+where, queryValues); err != nil {
+		return nil, nil, fmt.Errorf("%s get counting: %s", rc.GetType(), err.Error()), http.StatusInternalServerError
+	}
 	query := rc.SelectQuery() + where + orderBy + pagination
 
 	rows, err := rc.APIInfo().Tx.NamedQuery(query, queryValues)
@@ -52,7 +52,7 @@ func AddSSLKeys(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer inf.Close()
-	if !inf.Config.RiakEnabled {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("adding SSL keys to Riak for delivery service: Riak is not configured"))
 		return
 	}
@@ -129,7 +129,7 @@ func GetSSLKeysByHostName(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("the Riak service is unavailable"), errors.New("getting SSL keys from Riak by host name: Riak is not configured"))
 		return
 	}
@@ -179,7 +179,7 @@ func GetSSLKeysByXMLID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer inf.Close()
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("the Riak service is unavailable"), errors.New("getting SSL keys from Riak by xml id: Riak is not configured"))
 		return
 	}
@@ -245,7 +245,7 @@ func DeleteSSLKeys(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer inf.Close()
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, userErr, errors.New("deliveryservice.DeleteSSLKeys: Riak is not configured"))
 		return
 	}
@@ -278,6 +278,42 @@ func updateSSLKeyVersion(xmlID string, version int64, tx *sql.Tx) error {
 	return nil
 }
 
+// GetSSLKeyHostName returns the host name of the given delivery service's SSL certificate, which is the host of its example URL, or for HTTP delivery services, the wildcard of its domain, which also matches the caches clients are redirected to.
+// Returns false if the delivery service doesn't exist.
+func GetSSLKeyHostName(tx *sql.Tx, xmlID string) (string, bool, error) {
+	q := `
+SELECT ds.protocol, t.name, ds.routing_name, cdn.domain_name
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE ds.xml_id = $1
+`
+	protocol := sql.NullInt64{}
+	dsType := ""
+	routingName := ""
+	cdnDomain := ""
+	if err := tx.QueryRow(q, xmlID).Scan(&protocol, &dsType, &routingName, &cdnDomain); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, errors.New("querying delivery service: " + err.Error())
+	}
+	matchLists, err := GetDeliveryServicesMatchLists([]string{xmlID}, tx)
+	if err != nil {
+		return "", false, errors.New("getting delivery service matchlist: " + err.Error())
+	}
+	protocolPtr := (*int)(nil)
+	if protocol.Valid {
+		p := int(protocol.Int64)
+		protocolPtr = &p
+	}
+	hostName, err := getHostName(protocolPtr, tc.DSTypeFromString(dsType), routingName, matchLists[xmlID], cdnDomain)
+	if err != nil {
+		return "", false, err
+	}
+	return hostName, true, nil
+}
+
 // returns the cdn_id found by domainname.
 func getCDNIDByDomainname(domainName string, tx *sql.Tx) (int64, bool, error) {
 	cdnID := int64(0)
@@ -0,0 +1,368 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetApprovals handles GET requests for the approvals of a delivery service request, and the policy they're counted against.
+func GetApprovals(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	req, userErr, sysErr, errCode := getRequest(inf, inf.IntParams["id"], false)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	approvals, err := getApprovals(inf.Tx.Tx, req)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service request approvals: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, approvals)
+}
+
+// Approve handles POST requests to approve a submitted delivery service request as the current user.
+// If the approval satisfies the request's policy, the request is applied if the policy is to apply automatically, and becomes pending otherwise.
+func Approve(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	req, userErr, sysErr, errCode := getRequest(inf, inf.IntParams["id"], true)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if *req.Status != tc.RequestStatusSubmitted {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("cannot approve a deliveryservice_request with status "+string(*req.Status)+"; only submitted requests can be approved"), nil)
+		return
+	}
+
+	tenantID, err := getTenantID(inf.Tx.Tx, req)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service request tenant: "+err.Error()))
+		return
+	}
+	policy, err := getPolicy(inf.Tx.Tx, tenantID, *req.ChangeType)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service request policy: "+err.Error()))
+		return
+	}
+	if !countsToward(policy, req, tenantID, inf.User.ID, inf.User.TenantID) {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusForbidden, errors.New("approval would not count toward the policy '"+policy.Name+"' of this request: "+approverRequirement(policy)), nil)
+		return
+	}
+
+	res, err := inf.Tx.Tx.Exec(`INSERT INTO deliveryservice_request_approval (deliveryservice_request, tm_user) VALUES ($1, $2) ON CONFLICT DO NOTHING`, *req.ID, inf.User.ID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting delivery service request approval: "+err.Error()))
+		return
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("deliveryservice_request already approved by "+inf.User.UserName), nil)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, requestChangeLogPrefix(req)+"Approved "+*req.ChangeType+" delivery service request", inf.User, inf.Tx.Tx)
+
+	approvals, err := getApprovals(inf.Tx.Tx, req)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service request approvals: "+err.Error()))
+		return
+	}
+
+	msg := "deliveryservice_request was approved."
+	if approvals.Approved && *approvals.Policy.AutoApply {
+		if userErr, sysErr, errCode := apply(inf, req); userErr != nil || sysErr != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		msg = "deliveryservice_request was approved and applied."
+	} else if approvals.Approved {
+		if _, err := inf.Tx.Tx.Exec(`UPDATE deliveryservice_request SET status = $1 WHERE id = $2`, tc.RequestStatusPending, *req.ID); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("setting delivery service request pending: "+err.Error()))
+			return
+		}
+		msg = "deliveryservice_request was approved, and is pending until it's applied."
+	}
+
+	approvals.Request, userErr, sysErr, errCode = getRequest(inf, *req.ID, false)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, msg, approvals)
+}
+
+// Apply handles POST requests to apply a pending delivery service request, whose approvals satisfy its policy.
+func Apply(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	req, userErr, sysErr, errCode := getRequest(inf, inf.IntParams["id"], true)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if *req.Status != tc.RequestStatusPending {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("cannot apply a deliveryservice_request with status "+string(*req.Status)+"; only pending requests can be applied"), nil)
+		return
+	}
+	approvals, err := getApprovals(inf.Tx.Tx, req)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting delivery service request approvals: "+err.Error()))
+		return
+	}
+	if !approvals.Approved {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("deliveryservice_request does not have the "+strconv.Itoa(approvals.Policy.RequiredApprovals)+" approvals required by its policy '"+approvals.Policy.Name+"'"), nil)
+		return
+	}
+	if userErr, sysErr, errCode := apply(inf, req); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	applied, userErr, sysErr, errCode := getRequest(inf, *req.ID, false)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "deliveryservice_request was applied.", applied)
+}
+
+func requestChangeLogPrefix(req *tc.DeliveryServiceRequestNullable) string {
+	return "DSR: " + *req.DeliveryService.XMLID + ", ID: " + strconv.Itoa(*req.ID) + ", ACTION: "
+}
+
+// getRequest returns the delivery service request of the given id, locking it if lock is true, and checks the user is authorized on its delivery service's tenant.
+func getRequest(inf *api.APIInfo, id int, lock bool) (*tc.DeliveryServiceRequestNullable, error, error, int) {
+	qry := selectDeliveryServiceRequestsQuery() + `WHERE r.id = $1`
+	if lock {
+		qry += ` FOR UPDATE OF r`
+	}
+	req := tc.DeliveryServiceRequestNullable{}
+	if err := inf.Tx.QueryRowx(qry, id).StructScan(&req); err == sql.ErrNoRows {
+		return nil, errors.New("deliveryservice_request " + strconv.Itoa(id) + " not found"), nil, http.StatusNotFound
+	} else if err != nil {
+		return nil, nil, errors.New("querying delivery service request: " + err.Error()), http.StatusInternalServerError
+	}
+	if req.DeliveryService == nil || req.DeliveryService.XMLID == nil || req.ChangeType == nil || req.Status == nil {
+		return nil, nil, errors.New("delivery service request " + strconv.Itoa(id) + " is missing its delivery service, xmlId, change type, or status"), http.StatusInternalServerError
+	}
+	if req.DeliveryService.TenantID != nil {
+		if authorized, err := tenant.IsResourceAuthorizedToUserTx(*req.DeliveryService.TenantID, inf.User, inf.Tx.Tx); err != nil {
+			return nil, nil, errors.New("checking tenant: " + err.Error()), http.StatusInternalServerError
+		} else if !authorized {
+			return nil, errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+		}
+	}
+	return &req, nil, nil, http.StatusOK
+}
+
+// getTenantID returns the tenant whose policy and approvers the request is subject to.
+// This is the tenant of the existing delivery service an update or delete changes, rather than the tenant in the request, so the author can't escape the policy by changing the tenant. A create is subject to the tenant of its new delivery service. If the delivery service of an update or delete doesn't exist, the request's tenant is returned; such a request can't be applied.
+func getTenantID(tx *sql.Tx, req *tc.DeliveryServiceRequestNullable) (*int, error) {
+	if *req.ChangeType == tc.DeliveryServiceRequestChangeTypeCreate {
+		return req.DeliveryService.TenantID, nil
+	}
+	tenantID := sql.NullInt64{}
+	if err := tx.QueryRow(`SELECT tenant_id FROM deliveryservice WHERE id = $1 OR ($1 IS NULL AND xml_id = $2)`, req.DeliveryService.ID, req.DeliveryService.XMLID).Scan(&tenantID); err == sql.ErrNoRows {
+		return req.DeliveryService.TenantID, nil
+	} else if err != nil {
+		return nil, errors.New("querying delivery service tenant: " + err.Error())
+	}
+	if !tenantID.Valid {
+		return nil, nil
+	}
+	id := int(tenantID.Int64)
+	return &id, nil
+}
+
+// countsToward returns whether an approval of the request by the user of the given ID and tenant counts toward the policy. The tenantID is the request's, from getTenantID.
+func countsToward(policy tc.DeliveryServiceRequestPolicy, req *tc.DeliveryServiceRequestNullable, tenantID *int, userID int, userTenantID int) bool {
+	if !policy.AllowAuthorApproval && req.AuthorID != nil && int(*req.AuthorID) == userID {
+		return false
+	}
+	if policy.TenantApprovers && (tenantID == nil || *tenantID != userTenantID) {
+		return false
+	}
+	return true
+}
+
+// approverRequirement returns a description of whose approvals count toward the policy.
+func approverRequirement(policy tc.DeliveryServiceRequestPolicy) string {
+	who := "approvers"
+	if policy.TenantApprovers {
+		who += " must belong to the delivery service's tenant"
+		if !policy.AllowAuthorApproval {
+			who += ", and"
+		}
+	}
+	if !policy.AllowAuthorApproval {
+		who += " must not be the request's author"
+	}
+	return who
+}
+
+// getApprovals returns the approvals of the request, and whether they satisfy its policy.
+func getApprovals(tx *sql.Tx, req *tc.DeliveryServiceRequestNullable) (tc.DeliveryServiceRequestApprovals, error) {
+	tenantID, err := getTenantID(tx, req)
+	if err != nil {
+		return tc.DeliveryServiceRequestApprovals{}, errors.New("getting tenant: " + err.Error())
+	}
+	policy, err := getPolicy(tx, tenantID, *req.ChangeType)
+	if err != nil {
+		return tc.DeliveryServiceRequestApprovals{}, errors.New("getting policy: " + err.Error())
+	}
+	approvals := tc.DeliveryServiceRequestApprovals{RequestID: *req.ID, Policy: policy, Approvals: []tc.DeliveryServiceRequestApproval{}}
+
+	qry := `
+SELECT u.id, u.username, u.tenant_id, a.created_at
+FROM deliveryservice_request_approval a
+JOIN tm_user u ON u.id = a.tm_user
+WHERE a.deliveryservice_request = $1
+ORDER BY a.created_at, u.username
+`
+	rows, err := tx.Query(qry, *req.ID)
+	if err != nil {
+		return tc.DeliveryServiceRequestApprovals{}, errors.New("querying approvals: " + err.Error())
+	}
+	defer rows.Close()
+	counted := 0
+	for rows.Next() {
+		approval := tc.DeliveryServiceRequestApproval{}
+		userTenantID := sql.NullInt64{}
+		if err := rows.Scan(&approval.UserID, &approval.User, &userTenantID, &approval.CreatedAt); err != nil {
+			return tc.DeliveryServiceRequestApprovals{}, errors.New("scanning approvals: " + err.Error())
+		}
+		approval.Counted = countsToward(policy, req, tenantID, approval.UserID, int(userTenantID.Int64))
+		if approval.Counted {
+			counted++
+		}
+		approvals.Approvals = append(approvals.Approvals, approval)
+	}
+	approvals.Approved = counted >= policy.RequiredApprovals
+	return approvals, nil
+}
+
+// apply applies the delivery service of the request, marks the request complete, and records the change log entry of its application and the difference it made to the delivery service.
+// It must be called in the transaction which locked the request, so the delivery service applied is exactly the one which was approved, and the request is applied at most once.
+func apply(inf *api.APIInfo, req *tc.DeliveryServiceRequestNullable) (error, error, int) {
+	ds := tc.DeliveryServiceNullableV14(*req.DeliveryService)
+	before := []byte(nil)
+	id := 0
+	if *req.ChangeType != tc.DeliveryServiceRequestChangeTypeCreate {
+		existingID, ok, err := getDSID(inf.Tx, ds)
+		if err != nil {
+			return nil, errors.New("getting delivery service id: " + err.Error()), http.StatusInternalServerError
+		} else if !ok {
+			return errors.New("cannot " + *req.ChangeType + " delivery service '" + *ds.XMLID + "': not found"), nil, http.StatusBadRequest
+		}
+		id = existingID
+		if before, err = getDSJSON(inf.Tx, id); err != nil {
+			return nil, errors.New("getting delivery service before applying request: " + err.Error()), http.StatusInternalServerError
+		}
+	}
+
+	switch *req.ChangeType {
+	case tc.DeliveryServiceRequestChangeTypeCreate:
+		res, errCode, userErr, sysErr := deliveryservice.Create(inf, ds)
+		if userErr != nil || sysErr != nil {
+			return userErr, sysErr, errCode
+		}
+		id = *res.ID
+	case tc.DeliveryServiceRequestChangeTypeUpdate:
+		ds.ID = &id
+		if _, errCode, userErr, sysErr := deliveryservice.Update(inf, ds); userErr != nil || sysErr != nil {
+			return userErr, sysErr, errCode
+		}
+	case tc.DeliveryServiceRequestChangeTypeDelete:
+		if userErr, sysErr, errCode := deliveryservice.DeleteByID(inf, id); userErr != nil || sysErr != nil {
+			return userErr, sysErr, errCode
+		}
+	default:
+		return errors.New("cannot apply a deliveryservice_request of unknown change type '" + *req.ChangeType + "'"), nil, http.StatusBadRequest
+	}
+
+	after := []byte(nil)
+	if *req.ChangeType != tc.DeliveryServiceRequestChangeTypeDelete {
+		var err error
+		if after, err = getDSJSON(inf.Tx, id); err != nil {
+			return nil, errors.New("getting delivery service after applying request: " + err.Error()), http.StatusInternalServerError
+		}
+	}
+	diff, err := crconfig.DiffJSON(before, after)
+	if err != nil {
+		return nil, errors.New("diffing delivery service: " + err.Error()), http.StatusInternalServerError
+	}
+
+	logID, err := api.CreateChangeLogRawID(api.ApiChange, requestChangeLogPrefix(req)+"Applied "+*req.ChangeType+" delivery service request to delivery service "+strconv.Itoa(id), inf.User, inf.Tx.Tx)
+	if err != nil {
+		return nil, errors.New("writing change log entry: " + err.Error()), http.StatusInternalServerError
+	}
+	qry := `UPDATE deliveryservice_request SET status = $1, applied_at = now(), applied_log = $2, applied_diff = $3 WHERE id = $4`
+	if _, err := inf.Tx.Tx.Exec(qry, tc.RequestStatusComplete, logID, tc.DeliveryServiceRequestDiff(diff), *req.ID); err != nil {
+		return nil, errors.New("completing delivery service request: " + err.Error()), http.StatusInternalServerError
+	}
+	return nil, nil, http.StatusOK
+}
+
+// getDSID returns the ID of the existing delivery service the request's delivery service is a change to, by its ID if it has one, and otherwise its XMLID.
+func getDSID(tx *sqlx.Tx, ds tc.DeliveryServiceNullableV14) (int, bool, error) {
+	id := 0
+	if err := tx.QueryRow(`SELECT id FROM deliveryservice WHERE id = $1 OR ($1 IS NULL AND xml_id = $2)`, ds.ID, ds.XMLID).Scan(&id); err == sql.ErrNoRows {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// getDSJSON returns the JSON of the delivery service of the given id, as it's returned by the API.
+func getDSJSON(tx *sqlx.Tx, id int) ([]byte, error) {
+	ds, ok, err := deliveryservice.GetByID(tx, id)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("delivery service " + strconv.Itoa(id) + " not found")
+	}
+	return json.Marshal(ds)
+}
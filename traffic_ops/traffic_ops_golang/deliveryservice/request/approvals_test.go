@@ -0,0 +1,202 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func testRequest(authorID int, tenantID int) *tc.DeliveryServiceRequestNullable {
+	id := 7
+	author := tc.IDNoMod(authorID)
+	changeType := tc.DeliveryServiceRequestChangeTypeUpdate
+	xmlID := "ds1"
+	return &tc.DeliveryServiceRequestNullable{
+		ID:              &id,
+		AuthorID:        &author,
+		ChangeType:      &changeType,
+		DeliveryService: &tc.DeliveryServiceNullable{XMLID: &xmlID, TenantID: &tenantID},
+	}
+}
+
+func TestCountsToward(t *testing.T) {
+	req := testRequest(1, 10)
+	policy := tc.DefaultDeliveryServiceRequestPolicy()
+	if countsToward(policy, req, req.DeliveryService.TenantID, 1, 10) {
+		t.Error("expected the author's approval not to count by default")
+	}
+	if !countsToward(policy, req, req.DeliveryService.TenantID, 2, 20) {
+		t.Error("expected another user's approval to count by default")
+	}
+
+	policy.AllowAuthorApproval = true
+	if !countsToward(policy, req, req.DeliveryService.TenantID, 1, 10) {
+		t.Error("expected the author's approval to count when the policy allows it")
+	}
+
+	policy.TenantApprovers = true
+	if countsToward(policy, req, req.DeliveryService.TenantID, 2, 20) {
+		t.Error("expected an approval by a user of another tenant not to count when the policy requires tenant approvers")
+	}
+	if !countsToward(policy, req, req.DeliveryService.TenantID, 2, 10) {
+		t.Error("expected an approval by a user of the delivery service's tenant to count when the policy requires tenant approvers")
+	}
+}
+
+func TestApproverRequirement(t *testing.T) {
+	policy := tc.DefaultDeliveryServiceRequestPolicy()
+	if actual := approverRequirement(policy); actual != "approvers must not be the request's author" {
+		t.Errorf("expected default requirement, actual '%s'", actual)
+	}
+	policy.TenantApprovers = true
+	if actual := approverRequirement(policy); actual != "approvers must belong to the delivery service's tenant, and must not be the request's author" {
+		t.Errorf("expected tenant and author requirement, actual '%s'", actual)
+	}
+}
+
+func TestGetApprovals(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT tenant_id FROM deliveryservice").WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(10))
+	policyCols := []string{"id", "name", "tenant", "tenant_name", "change_type", "required_approvals", "tenant_approvers", "allow_author_approval", "auto_apply", "last_updated"}
+	mock.ExpectQuery("WITH RECURSIVE ancestors").WithArgs(10, tc.DeliveryServiceRequestChangeTypeUpdate).WillReturnRows(sqlmock.NewRows(policyCols).AddRow(3, "two-person", 10, "tenant1", nil, 2, false, false, false, now))
+	approvalCols := []string{"id", "username", "tenant_id", "created_at"}
+	mock.ExpectQuery("FROM deliveryservice_request_approval").WithArgs(7).WillReturnRows(sqlmock.NewRows(approvalCols).
+		AddRow(1, "author", 10, now).
+		AddRow(2, "reviewer", 10, now))
+
+	tx := db.MustBegin().Tx
+	approvals, err := getApprovals(tx, testRequest(1, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approvals.Policy.Name != "two-person" || approvals.Policy.RequiredApprovals != 2 || *approvals.Policy.AutoApply {
+		t.Errorf("expected policy 'two-person' requiring 2 approvals without auto apply, actual %+v", approvals.Policy)
+	}
+	if len(approvals.Approvals) != 2 {
+		t.Fatalf("expected 2 approvals, actual %d", len(approvals.Approvals))
+	}
+	if approvals.Approvals[0].Counted || !approvals.Approvals[1].Counted {
+		t.Errorf("expected only the reviewer's approval to count, actual %+v", approvals.Approvals)
+	}
+	if approvals.Approved {
+		t.Error("expected 1 counted approval not to satisfy a policy requiring 2")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
+
+func TestGetApprovalsExistingTenant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	// the author moved the delivery service of tenant 10 to their own tenant 20 in the request, whose policy would let them approve it
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT tenant_id FROM deliveryservice").WithArgs(nil, "ds1").WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(10))
+	policyCols := []string{"id", "name", "tenant", "tenant_name", "change_type", "required_approvals", "tenant_approvers", "allow_author_approval", "auto_apply", "last_updated"}
+	mock.ExpectQuery("WITH RECURSIVE ancestors").WithArgs(10, tc.DeliveryServiceRequestChangeTypeUpdate).WillReturnRows(sqlmock.NewRows(policyCols).AddRow(3, "tenant-approvers", 10, "tenant1", nil, 1, true, true, false, now))
+	approvalCols := []string{"id", "username", "tenant_id", "created_at"}
+	mock.ExpectQuery("FROM deliveryservice_request_approval").WithArgs(7).WillReturnRows(sqlmock.NewRows(approvalCols).AddRow(1, "author", 20, now))
+
+	approvals, err := getApprovals(db.MustBegin().Tx, testRequest(1, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approvals.Policy.Name != "tenant-approvers" {
+		t.Errorf("expected the policy of the existing delivery service's tenant, actual %+v", approvals.Policy)
+	}
+	if len(approvals.Approvals) != 1 || approvals.Approvals[0].Counted || approvals.Approved {
+		t.Errorf("expected the approval by a user of the request's tenant, but not the delivery service's, not to count, actual %+v", approvals)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
+
+func TestGetTenantIDCreate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	req := testRequest(1, 20)
+	changeType := tc.DeliveryServiceRequestChangeTypeCreate
+	req.ChangeType = &changeType
+	tenantID, err := getTenantID(db.MustBegin().Tx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenantID == nil || *tenantID != 20 {
+		t.Errorf("expected the tenant of a create request to be the request's, 20, actual %v", tenantID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
+
+func TestGetPolicyDefault(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE ancestors").WithArgs(nil, tc.DeliveryServiceRequestChangeTypeCreate).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	policy, err := getPolicy(db.MustBegin().Tx, nil, tc.DeliveryServiceRequestChangeTypeCreate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Name != "default" || policy.RequiredApprovals != 1 || policy.AllowAuthorApproval || !*policy.AutoApply {
+		t.Errorf("expected the default policy, actual %+v", policy)
+	}
+	if !strings.Contains(approverRequirement(policy), "author") {
+		t.Error("expected the default policy to exclude the author")
+	}
+}
@@ -0,0 +1,261 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+)
+
+// GetPolicies handles GET requests for delivery service request policies, optionally filtered by the id query parameter.
+func GetPolicies(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	policies, err := readPolicies(inf.Tx.Tx, inf.IntParams["id"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("reading delivery service request policies: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, policies)
+}
+
+// CreatePolicy handles POST requests to create a delivery service request policy.
+func CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	policy, userErr, sysErr, errCode := parsePolicy(inf, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	qry := `
+INSERT INTO deliveryservice_request_policy (name, tenant, change_type, required_approvals, tenant_approvers, allow_author_approval, auto_apply)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id
+`
+	if err := inf.Tx.Tx.QueryRow(qry, policy.Name, policy.TenantID, policy.ChangeType, policy.RequiredApprovals, policy.TenantApprovers, policy.AllowAuthorApproval, *policy.AutoApply).Scan(&policy.ID); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	writePolicy(w, r, inf, policy.ID, "created")
+}
+
+// UpdatePolicy handles PUT requests to update the delivery service request policy of the id query parameter.
+func UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	id := inf.IntParams["id"]
+	if userErr, sysErr, errCode := checkPolicyTenant(inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	policy, userErr, sysErr, errCode := parsePolicy(inf, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	qry := `
+UPDATE deliveryservice_request_policy SET
+name = $1,
+tenant = $2,
+change_type = $3,
+required_approvals = $4,
+tenant_approvers = $5,
+allow_author_approval = $6,
+auto_apply = $7,
+last_updated = now()
+WHERE id = $8
+`
+	if _, err := inf.Tx.Tx.Exec(qry, policy.Name, policy.TenantID, policy.ChangeType, policy.RequiredApprovals, policy.TenantApprovers, policy.AllowAuthorApproval, *policy.AutoApply, id); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	writePolicy(w, r, inf, id, "updated")
+}
+
+// DeletePolicy handles DELETE requests to delete the delivery service request policy of the id query parameter. Requests it applied to use the next most specific policy.
+func DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	id := inf.IntParams["id"]
+	if userErr, sysErr, errCode := checkPolicyTenant(inf, id); userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	name := ""
+	if err := inf.Tx.Tx.QueryRow(`DELETE FROM deliveryservice_request_policy WHERE id = $1 RETURNING name`, id).Scan(&name); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting delivery service request policy: "+err.Error()))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, policyChangeLogPrefix(name, id)+"Deleted delivery service request policy", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "delivery service request policy was deleted.")
+}
+
+func policyChangeLogPrefix(name string, id int) string {
+	return "DELIVERYSERVICE REQUEST POLICY: " + name + ", ID: " + strconv.Itoa(id) + ", ACTION: "
+}
+
+// parsePolicy decodes, sanitizes, and validates the policy in the request body, and checks the user is authorized on its tenant.
+func parsePolicy(inf *api.APIInfo, r *http.Request) (tc.DeliveryServiceRequestPolicy, error, error, int) {
+	policy := tc.DeliveryServiceRequestPolicy{}
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		return policy, errors.New("malformed JSON: " + err.Error()), nil, http.StatusBadRequest
+	}
+	policy.Sanitize()
+	if err := policy.Validate(); err != nil {
+		return policy, errors.New("invalid delivery service request policy: " + err.Error()), nil, http.StatusBadRequest
+	}
+	if policy.TenantID != nil {
+		if authorized, err := tenant.IsResourceAuthorizedToUserTx(*policy.TenantID, inf.User, inf.Tx.Tx); err != nil {
+			return policy, nil, errors.New("checking tenant: " + err.Error()), http.StatusInternalServerError
+		} else if !authorized {
+			return policy, errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+		}
+	}
+	return policy, nil, nil, http.StatusOK
+}
+
+// checkPolicyTenant returns an error if the policy of the given id doesn't exist, or the user isn't authorized on its tenant.
+func checkPolicyTenant(inf *api.APIInfo, id int) (error, error, int) {
+	tenantID := (*int)(nil)
+	if err := inf.Tx.Tx.QueryRow(`SELECT tenant FROM deliveryservice_request_policy WHERE id = $1 FOR UPDATE`, id).Scan(&tenantID); err == sql.ErrNoRows {
+		return errors.New("delivery service request policy " + strconv.Itoa(id) + " not found"), nil, http.StatusNotFound
+	} else if err != nil {
+		return nil, errors.New("getting delivery service request policy tenant: " + err.Error()), http.StatusInternalServerError
+	}
+	if tenantID == nil {
+		return nil, nil, http.StatusOK
+	}
+	if authorized, err := tenant.IsResourceAuthorizedToUserTx(*tenantID, inf.User, inf.Tx.Tx); err != nil {
+		return nil, errors.New("checking tenant: " + err.Error()), http.StatusInternalServerError
+	} else if !authorized {
+		return errors.New("not authorized on this tenant"), nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
+// writePolicy writes the policy of the given id, which was just created or updated, and its change log entry.
+func writePolicy(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, id int, action string) {
+	policies, err := readPolicies(inf.Tx.Tx, id)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("reading delivery service request policy: "+err.Error()))
+		return
+	} else if len(policies) == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery service request policy "+strconv.Itoa(id)+" not found"), nil)
+		return
+	}
+	policy := policies[0]
+	api.CreateChangeLogRawTx(api.ApiChange, policyChangeLogPrefix(policy.Name, id)+"Delivery service request policy "+action, inf.User, inf.Tx.Tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "delivery service request policy was "+action+".", policy)
+}
+
+const selectPoliciesQuery = `
+SELECT
+p.id,
+p.name,
+p.tenant,
+t.name,
+p.change_type,
+p.required_approvals,
+p.tenant_approvers,
+p.allow_author_approval,
+p.auto_apply,
+p.last_updated
+FROM deliveryservice_request_policy p
+LEFT JOIN tenant t ON t.id = p.tenant
+`
+
+// readPolicies returns the policies, or only the one with the given id if it isn't 0.
+func readPolicies(tx *sql.Tx, id int) ([]tc.DeliveryServiceRequestPolicy, error) {
+	rows, err := tx.Query(selectPoliciesQuery+`WHERE ($1 = 0 OR p.id = $1) ORDER BY p.name`, id)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	policies := []tc.DeliveryServiceRequestPolicy{}
+	for rows.Next() {
+		p, err := scanPolicy(rows)
+		if err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPolicy(s scanner) (tc.DeliveryServiceRequestPolicy, error) {
+	p := tc.DeliveryServiceRequestPolicy{AutoApply: new(bool), LastUpdated: &tc.TimeNoMod{}}
+	err := s.Scan(&p.ID, &p.Name, &p.TenantID, &p.Tenant, &p.ChangeType, &p.RequiredApprovals, &p.TenantApprovers, &p.AllowAuthorApproval, p.AutoApply, p.LastUpdated)
+	return p, err
+}
+
+// getPolicy returns the policy of requests of the given change type, for delivery services of the given tenant: the one of the nearest tenant, preferring those of the change type.
+// If no policy applies, tc.DefaultDeliveryServiceRequestPolicy is returned.
+func getPolicy(tx *sql.Tx, tenantID *int, changeType string) (tc.DeliveryServiceRequestPolicy, error) {
+	qry := `
+WITH RECURSIVE ancestors AS (
+  SELECT id, parent_id, 0 AS depth FROM tenant WHERE id = $1
+  UNION ALL
+  SELECT t.id, t.parent_id, a.depth + 1 FROM tenant t JOIN ancestors a ON t.id = a.parent_id
+)
+` + selectPoliciesQuery + `
+LEFT JOIN ancestors a ON a.id = p.tenant
+WHERE (p.tenant IS NULL OR a.id IS NOT NULL)
+AND (p.change_type IS NULL OR p.change_type::text = $2)
+ORDER BY a.depth ASC NULLS LAST, p.change_type IS NULL, p.id
+LIMIT 1
+`
+	p, err := scanPolicy(tx.QueryRow(qry, tenantID, changeType))
+	if err == sql.ErrNoRows {
+		return tc.DefaultDeliveryServiceRequestPolicy(), nil
+	} else if err != nil {
+		return tc.DeliveryServiceRequestPolicy{}, err
+	}
+	return p, nil
+}
@@ -108,6 +108,9 @@ func (req *TODeliveryServiceRequest) Read() ([]interface{}, error, error, int) {
 	}
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "CAST(r.deliveryservice->>'tenantId' AS bigint)", tenantIDs)
 
+	if err := req.APIInfo().CountResults(selectDeliveryServiceRequestsQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("dsr counting: " + err.Error()), http.StatusInternalServerError
+	}
 	query := selectDeliveryServiceRequestsQuery() + where + orderBy + pagination
 	log.Debugln("Query is ", query)
 
@@ -144,7 +147,10 @@ r.last_edited_by_id,
 r.last_updated,
 r.deliveryservice,
 r.status,
-r.deliveryservice->>'xmlId' as xml_id
+r.deliveryservice->>'xmlId' as xml_id,
+r.applied_at,
+r.applied_log,
+r.applied_diff
 
 FROM deliveryservice_request r
 JOIN tm_user a ON r.author_id = a.id
@@ -202,7 +208,15 @@ func (req *TODeliveryServiceRequest) Update() (error, error, int) {
 	userID := tc.IDNoMod(req.APIInfo().User.ID)
 	req.LastEditedByID = &userID
 
-	return api.GenericUpdate(req)
+	if userErr, sysErr, errCode := api.GenericUpdate(req); userErr != nil || sysErr != nil {
+		return userErr, sysErr, errCode
+	}
+
+	// approvals were of the request as it was, so a changed request must be approved again
+	if _, err := req.APIInfo().Tx.Tx.Exec(`DELETE FROM deliveryservice_request_approval WHERE deliveryservice_request = $1`, *req.ID); err != nil {
+		return nil, errors.New("dsr update deleting approvals: " + err.Error()), http.StatusInternalServerError
+	}
+	return nil, nil, http.StatusOK
 }
 
 // Creator implements the tc.Creator interface
@@ -618,7 +618,8 @@ type TODSSDeliveryService struct {
 // Read shows all of the delivery services associated with the specified server.
 func (dss *TODSSDeliveryService) Read() ([]interface{}, error, error, int) {
 	returnable := []interface{}{}
-	params := dss.APIInfo().Params
+	// the id parameter is the server's, so the delivery services can't be paged by their id in the database, and are paginated by ReadHandler instead
+	params := api.WithoutPaginationParams(dss.APIInfo().Params)
 	tx := dss.APIInfo().Tx.Tx
 	user := dss.APIInfo().User
 
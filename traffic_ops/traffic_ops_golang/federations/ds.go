@@ -130,6 +130,10 @@ func (v *TOFedDSes) ParamColumns() map[string]dbhelpers.WhereColumnInfo {
 		"dsID": dbhelpers.WhereColumnInfo{"fds.deliveryservice", api.IsInt},
 	}
 }
+
+// CursorColumn implements api.GenericCursorReader. The "id" parameter is the federation, but the results are delivery services.
+func (v *TOFedDSes) CursorColumn() string { return "ds.id" }
+
 func (v *TOFedDSes) GetType() string {
 	return "federation deliveryservice"
 }
@@ -212,6 +212,9 @@ func (job *InvalidationJob) Read() ([]interface{}, error, error, int) {
 	}
 
 	// TODO: check tenancy here
+	if err := job.APIInfo().CountResults(readQuery+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting jobs: " + err.Error()), http.StatusInternalServerError
+	}
 	query := readQuery + where + orderBy + pagination
 
 	log.Debugln("generated job query: " + query)
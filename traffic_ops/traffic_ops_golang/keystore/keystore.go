@@ -0,0 +1,95 @@
+package keystore
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+)
+
+// The KeyStore interface, and the functions which use it, are in the riaksvc package.
+// This package contains the key stores Traffic Ops may use instead of Riak, and registers the one configured in cdn.conf with riaksvc.
+
+// NewOpener returns the KeyStoreOpener for the given key store config.
+// Returns a nil opener if the key store is Riak, in which case riaksvc uses the pooled Riak cluster.
+func NewOpener(cfg *config.ConfigKeyStore) (riaksvc.KeyStoreOpener, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Type {
+	case config.KeyStoreTypeRiak:
+		return nil, nil
+	case config.KeyStoreTypePostgres:
+		aesKey, err := LoadAESKey(cfg.EncryptionKeyPath)
+		if err != nil {
+			return nil, errors.New("loading encryption key: " + err.Error())
+		}
+		return func(tx *sql.Tx) (riaksvc.KeyStore, error) {
+			return PostgresKeyStore{Tx: tx, AESKey: aesKey}, nil
+		}, nil
+	case config.KeyStoreTypeVault:
+		if cfg.Vault == nil {
+			return nil, errors.New("missing vault config")
+		}
+		ks := NewVaultKeyStore(*cfg.Vault)
+		return func(tx *sql.Tx) (riaksvc.KeyStore, error) {
+			return ks, nil
+		}, nil
+	default:
+		return nil, errors.New("unknown key store type '" + cfg.Type + "'")
+	}
+}
+
+// Init creates the key store configured in cdn.conf, and sets riaksvc to use it.
+func Init(cfg config.Config) error {
+	opener, err := NewOpener(cfg.KeyStore)
+	if err != nil {
+		return err
+	}
+	riaksvc.SetKeyStoreOpener(opener)
+	return nil
+}
+
+// MigrateFromRiak copies every key in Riak into the key store configured in cdn.conf, returning the number of keys copied from each bucket.
+// The tx is used to find the Riak servers, and by key stores which are stored in the Traffic Ops database. The caller must commit it.
+func MigrateFromRiak(tx *sql.Tx, cfg config.Config) (map[string]int, error) {
+	if !cfg.RiakEnabled {
+		return nil, errors.New("riak is not configured, cannot migrate keys from it")
+	}
+	opener, err := NewOpener(cfg.KeyStore)
+	if err != nil {
+		return nil, errors.New("creating key store: " + err.Error())
+	}
+	if opener == nil {
+		return nil, errors.New("no key store other than riak is configured, nothing to migrate to")
+	}
+	to, err := opener(tx)
+	if err != nil {
+		return nil, errors.New("opening key store: " + err.Error())
+	}
+	cluster, err := riaksvc.GetPooledCluster(tx, cfg.RiakAuthOptions, cfg.RiakPort)
+	if err != nil {
+		return nil, errors.New("getting riak cluster: " + err.Error())
+	}
+	return riaksvc.CopyKeys(riaksvc.RiakKeyStore{Cluster: cluster}, to)
+}
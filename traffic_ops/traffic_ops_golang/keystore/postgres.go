@@ -0,0 +1,204 @@
+package keystore
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+
+	"github.com/basho/riak-go-client"
+)
+
+// AESKeyLen is the length in bytes of the key used to encrypt keys stored in the Traffic Ops database, which are encrypted with AES-256-GCM.
+const AESKeyLen = 32
+
+// PostgresKeyStore is a KeyStore which stores keys encrypted in the Traffic Ops database 'secret' table.
+// Because it uses the request transaction, keys are saved or deleted atomically with the rest of the request.
+type PostgresKeyStore struct {
+	Tx     *sql.Tx
+	AESKey []byte
+}
+
+// LoadAESKey loads the base64-encoded AES-256 key from the given file.
+func LoadAESKey(path string) ([]byte, error) {
+	keyBts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("reading file '" + path + "': " + err.Error())
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyBts)))
+	if err != nil {
+		return nil, errors.New("decoding base64 key in file '" + path + "': " + err.Error())
+	}
+	if len(key) != AESKeyLen {
+		return nil, errors.New("key in file '" + path + "' is " + strconv.Itoa(len(key)) + " bytes, must be " + strconv.Itoa(AESKeyLen))
+	}
+	return key, nil
+}
+
+// Fetch implements riaksvc.KeyStore.
+func (pk PostgresKeyStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	encrypted := []byte{}
+	if err := pk.Tx.QueryRow(`SELECT value FROM secret WHERE bucket = $1 AND name = $2`, bucket, key).Scan(&encrypted); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, errors.New("querying secret: " + err.Error())
+	}
+	val, err := decrypt(pk.AESKey, encrypted)
+	if err != nil {
+		return nil, false, errors.New("decrypting bucket '" + bucket + "' key '" + key + "': " + err.Error())
+	}
+	return val, true, nil
+}
+
+// Save implements riaksvc.KeyStore.
+func (pk PostgresKeyStore) Save(bucket string, key string, val []byte) error {
+	encrypted, err := encrypt(pk.AESKey, val)
+	if err != nil {
+		return errors.New("encrypting: " + err.Error())
+	}
+	qry := `
+INSERT INTO secret (bucket, name, value) VALUES ($1, $2, $3)
+ON CONFLICT (bucket, name) DO UPDATE SET value = EXCLUDED.value, last_updated = now()
+`
+	if _, err := pk.Tx.Exec(qry, bucket, key, encrypted); err != nil {
+		return errors.New("inserting secret: " + err.Error())
+	}
+	return nil
+}
+
+// Delete implements riaksvc.KeyStore.
+func (pk PostgresKeyStore) Delete(bucket string, key string) error {
+	if _, err := pk.Tx.Exec(`DELETE FROM secret WHERE bucket = $1 AND name = $2`, bucket, key); err != nil {
+		return errors.New("deleting secret: " + err.Error())
+	}
+	return nil
+}
+
+// Keys implements riaksvc.KeyStore.
+func (pk PostgresKeyStore) Keys(bucket string) ([]string, error) {
+	rows, err := pk.Tx.Query(`SELECT name FROM secret WHERE bucket = $1 ORDER BY name`, bucket)
+	if err != nil {
+		return nil, errors.New("querying secret names: " + err.Error())
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		key := ""
+		if err := rows.Scan(&key); err != nil {
+			return nil, errors.New("scanning secret names: " + err.Error())
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating secret names: " + err.Error())
+	}
+	return keys, nil
+}
+
+// Ping implements riaksvc.KeyStore, by querying the secret table.
+func (pk PostgresKeyStore) Ping() error {
+	if _, err := pk.Tx.Exec(`SELECT 1 FROM secret LIMIT 1`); err != nil {
+		return errors.New("querying secret: " + err.Error())
+	}
+	return nil
+}
+
+// Search implements riaksvc.KeyStore, by decrypting every key in the index's bucket and matching them with riaksvc.SearchValues.
+func (pk PostgresKeyStore) Search(index string, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error) {
+	bucket, ok := riaksvc.SearchIndexBuckets[index]
+	if !ok {
+		return nil, errors.New("unknown search index '" + index + "'")
+	}
+	rows, err := pk.Tx.Query(`SELECT name, value FROM secret WHERE bucket = $1`, bucket)
+	if err != nil {
+		return nil, errors.New("querying secrets: " + err.Error())
+	}
+	defer rows.Close()
+	vals := map[string][]byte{}
+	for rows.Next() {
+		key := ""
+		encrypted := []byte{}
+		if err := rows.Scan(&key, &encrypted); err != nil {
+			return nil, errors.New("scanning secrets: " + err.Error())
+		}
+		val, err := decrypt(pk.AESKey, encrypted)
+		if err != nil {
+			return nil, errors.New("decrypting bucket '" + bucket + "' key '" + key + "': " + err.Error())
+		}
+		vals[key] = val
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating secrets: " + err.Error())
+	}
+	return riaksvc.SearchValues(vals, query, filterQuery, numRows, fields)
+}
+
+// encrypt encrypts val with AES-GCM, returning the random nonce followed by the ciphertext.
+func encrypt(key []byte, val []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.New("generating nonce: " + err.Error())
+	}
+	return gcm.Seal(nonce, nonce, val, nil), nil
+}
+
+// decrypt decrypts a value encrypted by encrypt.
+func decrypt(key []byte, encrypted []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("encrypted value too short")
+	}
+	nonce, ciphertext := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	val, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("opening: " + err.Error())
+	}
+	return val, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New("creating cipher: " + err.Error())
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.New("creating GCM: " + err.Error())
+	}
+	return gcm, nil
+}
@@ -0,0 +1,99 @@
+package keystore
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var testAESKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestEncryptDecrypt(t *testing.T) {
+	val := []byte(`{"cdn":"cdn1"}`)
+	encrypted, err := encrypt(testAESKey, val)
+	if err != nil {
+		t.Fatalf("encrypt expected nil error, actual: %v", err)
+	}
+	if bytes.Contains(encrypted, val) {
+		t.Error("encrypt expected value to not contain plaintext")
+	}
+	decrypted, err := decrypt(testAESKey, encrypted)
+	if err != nil {
+		t.Fatalf("decrypt expected nil error, actual: %v", err)
+	}
+	if !bytes.Equal(decrypted, val) {
+		t.Errorf("decrypt expected '%s', actual '%s'", val, decrypted)
+	}
+
+	otherKey := []byte("fedcba9876543210fedcba9876543210")
+	if _, err := decrypt(otherKey, encrypted); err == nil {
+		t.Error("decrypt with the wrong key expected error, actual: nil")
+	}
+	if _, err := decrypt(testAESKey, encrypted[:4]); err == nil {
+		t.Error("decrypt with a truncated value expected error, actual: nil")
+	}
+}
+
+func TestPostgresKeyStoreFetch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	val := []byte(`{"deliveryservice":"ds1"}`)
+	encrypted, err := encrypt(testAESKey, val)
+	if err != nil {
+		t.Fatalf("encrypt expected nil error, actual: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT value FROM secret").WithArgs(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(encrypted))
+	mock.ExpectQuery("SELECT value FROM secret").WithArgs(riaksvc.DeliveryServiceSSLKeysBucket, "ds2-latest").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	ks := PostgresKeyStore{Tx: tx, AESKey: testAESKey}
+
+	actual, ok, err := ks.Fetch(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest")
+	if err != nil {
+		t.Fatalf("Fetch expected nil error, actual: %v", err)
+	}
+	if !ok || !bytes.Equal(actual, val) {
+		t.Errorf("Fetch expected '%s' true, actual '%s' %v", val, actual, ok)
+	}
+
+	if _, ok, err := ks.Fetch(riaksvc.DeliveryServiceSSLKeysBucket, "ds2-latest"); err != nil || ok {
+		t.Errorf("Fetch of a nonexistent key expected not found and nil error, actual: %v %v", ok, err)
+	}
+	tx.Commit()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expections: %s", err)
+	}
+}
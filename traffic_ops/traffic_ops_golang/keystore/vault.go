@@ -0,0 +1,213 @@
+package keystore
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+
+	"github.com/basho/riak-go-client"
+)
+
+// VaultTokenHeader is the HTTP header Vault uses for authentication.
+const VaultTokenHeader = "X-Vault-Token"
+
+// VaultKeyStore is a KeyStore which stores keys in a Vault-compatible KV version 2 secrets engine, via its HTTP API.
+// Each key is stored as the secret {mount}/{prefix}/{bucket}/{key}, with the value base64-encoded in the secret's "value" field.
+type VaultKeyStore struct {
+	Client  *http.Client
+	Address string
+	Token   string
+	Mount   string
+	Prefix  string
+}
+
+// NewVaultKeyStore creates a VaultKeyStore from the given config.
+func NewVaultKeyStore(cfg config.ConfigVaultKV) VaultKeyStore {
+	return VaultKeyStore{
+		Client: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.Insecure}},
+		},
+		Address: strings.TrimSuffix(cfg.Address, "/"),
+		Token:   cfg.Token,
+		Mount:   strings.Trim(cfg.Mount, "/"),
+		Prefix:  strings.Trim(cfg.Prefix, "/"),
+	}
+}
+
+type vaultSecretData struct {
+	Value string `json:"value"`
+}
+
+type vaultSecretReq struct {
+	Data vaultSecretData `json:"data"`
+}
+
+type vaultSecretResp struct {
+	Data struct {
+		Data vaultSecretData `json:"data"`
+	} `json:"data"`
+}
+
+type vaultListResp struct {
+	Data struct {
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+// path returns the URL of the given KV API ("data" or "metadata") for the given bucket, and key if it isn't empty.
+func (vk VaultKeyStore) path(api string, bucket string, key string) string {
+	path := vk.Address + "/v1/" + vk.Mount + "/" + api + "/"
+	if vk.Prefix != "" {
+		path += vk.Prefix + "/"
+	}
+	path += url.PathEscape(bucket)
+	if key != "" {
+		path += "/" + url.PathEscape(key)
+	}
+	return path
+}
+
+// do makes the given request, returning the response body, and whether the secret was found.
+func (vk VaultKeyStore) do(method string, path string, body io.Reader) ([]byte, bool, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, false, errors.New("creating request: " + err.Error())
+	}
+	req.Header.Set(VaultTokenHeader, vk.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := vk.Client.Do(req)
+	if err != nil {
+		return nil, false, errors.New("requesting " + method + " " + path + ": " + err.Error())
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, errors.New("reading response body: " + err.Error())
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, false, errors.New(method + " " + path + " returned code " + strconv.Itoa(resp.StatusCode) + ": " + string(respBody))
+	}
+	return respBody, true, nil
+}
+
+// Fetch implements riaksvc.KeyStore.
+func (vk VaultKeyStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	body, ok, err := vk.do(http.MethodGet, vk.path("data", bucket, key), nil)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	secret := vaultSecretResp{}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, false, errors.New("decoding secret: " + err.Error())
+	}
+	val, err := base64.StdEncoding.DecodeString(secret.Data.Data.Value)
+	if err != nil {
+		return nil, false, errors.New("decoding secret base64 value: " + err.Error())
+	}
+	return val, true, nil
+}
+
+// Save implements riaksvc.KeyStore.
+func (vk VaultKeyStore) Save(bucket string, key string, val []byte) error {
+	body, err := json.Marshal(vaultSecretReq{Data: vaultSecretData{Value: base64.StdEncoding.EncodeToString(val)}})
+	if err != nil {
+		return errors.New("encoding secret: " + err.Error())
+	}
+	_, _, err = vk.do(http.MethodPost, vk.path("data", bucket, key), bytes.NewReader(body))
+	return err
+}
+
+// Delete implements riaksvc.KeyStore. This deletes every version of the secret.
+func (vk VaultKeyStore) Delete(bucket string, key string) error {
+	_, _, err := vk.do(http.MethodDelete, vk.path("metadata", bucket, key), nil)
+	return err
+}
+
+// Keys implements riaksvc.KeyStore.
+func (vk VaultKeyStore) Keys(bucket string) ([]string, error) {
+	body, ok, err := vk.do(http.MethodGet, vk.path("metadata", bucket, "")+"?list=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []string{}, nil // Vault returns a 404 when listing an empty path
+	}
+	list := vaultListResp{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, errors.New("decoding secret list: " + err.Error())
+	}
+	keys := []string{}
+	for _, key := range list.Data.Keys {
+		if strings.HasSuffix(key, "/") {
+			continue // a sub-path, not a secret
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Ping implements riaksvc.KeyStore, by listing the secrets under the prefix, which checks the token as well as the connection.
+func (vk VaultKeyStore) Ping() error {
+	_, _, err := vk.do(http.MethodGet, vk.path("metadata", "", "")+"?list=true", nil)
+	return err
+}
+
+// Search implements riaksvc.KeyStore, by fetching every key in the index's bucket and matching them with riaksvc.SearchValues.
+func (vk VaultKeyStore) Search(index string, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error) {
+	bucket, ok := riaksvc.SearchIndexBuckets[index]
+	if !ok {
+		return nil, errors.New("unknown search index '" + index + "'")
+	}
+	keys, err := vk.Keys(bucket)
+	if err != nil {
+		return nil, errors.New("listing keys: " + err.Error())
+	}
+	vals := map[string][]byte{}
+	for _, key := range keys {
+		val, ok, err := vk.Fetch(bucket, key)
+		if err != nil {
+			return nil, errors.New("fetching key '" + key + "': " + err.Error())
+		} else if !ok {
+			continue // deleted since listing
+		}
+		vals[key] = val
+	}
+	return riaksvc.SearchValues(vals, query, filterQuery, numRows, fields)
+}
@@ -0,0 +1,155 @@
+package keystore
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+)
+
+const testVaultToken = "test-token"
+
+// newTestVault returns a server emulating the subset of the Vault KV version 2 API used by VaultKeyStore.
+func newTestVault(t *testing.T) *httptest.Server {
+	secrets := map[string]string{}
+	m := sync.Mutex{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		if r.Header.Get(VaultTokenHeader) != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		const dataPrefix = "/v1/secret/data/"
+		const metadataPrefix = "/v1/secret/metadata/"
+		switch {
+		case strings.HasPrefix(r.URL.Path, dataPrefix) && r.Method == http.MethodGet:
+			val, ok := secrets[strings.TrimPrefix(r.URL.Path, dataPrefix)]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"value":"` + val + `"}}}`))
+		case strings.HasPrefix(r.URL.Path, dataPrefix) && r.Method == http.MethodPost:
+			body, _ := ioutil.ReadAll(r.Body)
+			req := vaultSecretReq{}
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Errorf("vault received malformed request: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			secrets[strings.TrimPrefix(r.URL.Path, dataPrefix)] = req.Data.Value
+			w.Write([]byte(`{"data":{"version":1}}`))
+		case strings.HasPrefix(r.URL.Path, metadataPrefix) && r.Method == http.MethodDelete:
+			delete(secrets, strings.TrimPrefix(r.URL.Path, metadataPrefix))
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasPrefix(r.URL.Path, metadataPrefix) && r.URL.Query().Get("list") == "true":
+			dir := strings.TrimPrefix(r.URL.Path, metadataPrefix) + "/"
+			keys := []string{}
+			for path := range secrets {
+				if strings.HasPrefix(path, dir) {
+					keys = append(keys, strings.TrimPrefix(path, dir))
+				}
+			}
+			if len(keys) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			resp := vaultListResp{}
+			resp.Data.Keys = keys
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func TestVaultKeyStore(t *testing.T) {
+	srv := newTestVault(t)
+	defer srv.Close()
+
+	ks := NewVaultKeyStore(config.ConfigVaultKV{Address: srv.URL, Token: testVaultToken, Mount: config.DefaultVaultKVMount, Prefix: config.DefaultVaultKVPrefix, TimeoutSeconds: 5})
+
+	if err := ks.Ping(); err != nil {
+		t.Fatalf("Ping expected nil error, actual: %v", err)
+	}
+	if _, ok, err := ks.Fetch(riaksvc.DNSSECKeysBucket, "cdn1"); err != nil || ok {
+		t.Fatalf("Fetch of a nonexistent key expected not found and nil error, actual: %v %v", ok, err)
+	}
+	if keys, err := ks.Keys(riaksvc.DNSSECKeysBucket); err != nil || len(keys) != 0 {
+		t.Fatalf("Keys of an empty bucket expected no keys and nil error, actual: %v %v", keys, err)
+	}
+
+	if err := ks.Save(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest", []byte(`{"cdn":"cdn1","deliveryservice":"ds1"}`)); err != nil {
+		t.Fatalf("Save expected nil error, actual: %v", err)
+	}
+	if err := ks.Save(riaksvc.DeliveryServiceSSLKeysBucket, "ds2-latest", []byte(`{"cdn":"cdn2","deliveryservice":"ds2"}`)); err != nil {
+		t.Fatalf("Save expected nil error, actual: %v", err)
+	}
+
+	val, ok, err := ks.Fetch(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest")
+	if err != nil {
+		t.Fatalf("Fetch expected nil error, actual: %v", err)
+	}
+	if !ok || string(val) != `{"cdn":"cdn1","deliveryservice":"ds1"}` {
+		t.Errorf("Fetch expected saved value, actual: %v '%s'", ok, val)
+	}
+
+	keys, err := ks.Keys(riaksvc.DeliveryServiceSSLKeysBucket)
+	if err != nil {
+		t.Fatalf("Keys expected nil error, actual: %v", err)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "ds1-latest" || keys[1] != "ds2-latest" {
+		t.Errorf("Keys expected [ds1-latest ds2-latest], actual: %v", keys)
+	}
+
+	docs, err := ks.Search(riaksvc.SSLKeysIndex, "cdn:cdn2", "", riaksvc.CDNSSLKeysLimit, []string{"deliveryservice"})
+	if err != nil {
+		t.Fatalf("Search expected nil error, actual: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Key != "ds2-latest" || docs[0].Fields["deliveryservice"][0] != "ds2" {
+		t.Errorf("Search expected ds2-latest, actual: %+v", docs)
+	}
+
+	if err := ks.Delete(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest"); err != nil {
+		t.Fatalf("Delete expected nil error, actual: %v", err)
+	}
+	if _, ok, err := ks.Fetch(riaksvc.DeliveryServiceSSLKeysBucket, "ds1-latest"); err != nil || ok {
+		t.Errorf("Fetch of a deleted key expected not found and nil error, actual: %v %v", ok, err)
+	}
+
+	ks.Token = "wrong"
+	if _, _, err := ks.Fetch(riaksvc.DeliveryServiceSSLKeysBucket, "ds2-latest"); err == nil {
+		t.Error("Fetch with an invalid token expected error, actual: nil")
+	}
+	if err := ks.Ping(); err == nil {
+		t.Error("Ping with an invalid token expected error, actual: nil")
+	}
+}
@@ -103,7 +103,7 @@ func getLastSeenCookie(r *http.Request) (time.Time, bool) {
 
 func getLog(tx *sql.Tx, days int, limit int) ([]tc.Log, error) {
 	rows, err := tx.Query(`
-SELECT l.id, l.level, l.message, u.username as user, l.ticketnum, l.last_updated
+SELECT l.id, l.level, l.message, `+api.ChangeLogUserSQL+` as user, l.ticketnum, l.last_updated
 FROM "log" as l JOIN tm_user as u ON l.tm_user = u.id
 WHERE l.last_updated > now() - ($1 || ' DAY')::INTERVAL
 ORDER BY l.last_updated DESC
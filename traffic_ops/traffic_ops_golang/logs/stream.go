@@ -0,0 +1,216 @@
+package logs
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+)
+
+// StreamPollInterval is how often the change log stream checks for new entries.
+const StreamPollInterval = time.Second
+
+// StreamKeepAliveInterval is how often a comment is written to an idle change log stream, so proxies don't close it.
+const StreamKeepAliveInterval = 15 * time.Second
+
+// StreamLookback is how many entry IDs before the last one sent are checked again on each poll. Entry IDs are assigned when entries are created, but transactions may commit out of order, so an entry may appear after entries with greater IDs.
+const StreamLookback = 100
+
+// StreamBatchSize is the maximum number of entries written per poll.
+const StreamBatchSize = 1000
+
+// StreamWriteTimeoutMargin is how long before the server write timeout the stream is ended, so it can be ended cleanly.
+const StreamWriteTimeoutMargin = 5 * time.Second
+
+// LastEventIDHeader is the header with which Server-Sent Events clients resume a stream.
+const LastEventIDHeader = "Last-Event-ID"
+
+// Stream writes change log entries as Server-Sent Events, as they're created, until the client disconnects or the server write timeout is reached.
+// Entries may be filtered by the objectType, cdn, and level parameters, which match entries exactly as webhook filters do.
+// The stream starts after the entry with the ID of the Last-Event-ID header or 'since' parameter, or if neither is given, with the next new entry.
+func Stream(w http.ResponseWriter, r *http.Request) {
+	db, err := api.GetDB(r.Context())
+	if err != nil {
+		api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("getting db: "+err.Error()))
+		return
+	}
+	cfg, err := api.GetConfig(r.Context())
+	if err != nil {
+		api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("getting config: "+err.Error()))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("response writer does not support flushing"))
+		return
+	}
+
+	params := r.URL.Query()
+	filter := streamFilter{
+		ObjectType: optionalParam(params, "objectType"),
+		CDN:        optionalParam(params, "cdn"),
+		Level:      optionalParam(params, "level"),
+	}
+
+	lastID := 0
+	if since := r.Header.Get(LastEventIDHeader); since != "" {
+		if lastID, err = strconv.Atoi(since); err != nil {
+			api.HandleErr(w, r, nil, http.StatusBadRequest, errors.New(LastEventIDHeader+" header must be an integer"), nil)
+			return
+		}
+	} else if since := params.Get("since"); since != "" {
+		if lastID, err = strconv.Atoi(since); err != nil {
+			api.HandleErr(w, r, nil, http.StatusBadRequest, errors.New("since parameter must be an integer"), nil)
+			return
+		}
+	} else if lastID, err = getLastLogID(db.DB); err != nil {
+		api.HandleErr(w, r, nil, http.StatusInternalServerError, nil, errors.New("getting last change log id: "+err.Error()))
+		return
+	}
+
+	end := time.Time{}
+	if cfg.WriteTimeout > 0 {
+		end = time.Now().Add(time.Duration(cfg.WriteTimeout)*time.Second - StreamWriteTimeoutMargin)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", StreamPollInterval/time.Millisecond)
+	flusher.Flush()
+
+	startID := lastID
+	sent := map[int]struct{}{}
+	lastWrite := time.Now()
+	ticker := time.NewTicker(StreamPollInterval)
+	defer ticker.Stop()
+	for {
+		afterID := lastID - StreamLookback
+		if afterID < startID {
+			afterID = startID // entries before the start were already seen by the client
+		}
+		events, err := getStreamEvents(db.DB, afterID, filter)
+		if err != nil {
+			log.Errorln("change log stream: getting entries: " + err.Error())
+			return
+		}
+		for _, ev := range events {
+			if _, ok := sent[ev.ID]; ok {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: changelog\ndata: %s\n\n", ev.ID, ev.JSON); err != nil {
+				return // the client disconnected
+			}
+			sent[ev.ID] = struct{}{}
+			if ev.ID > lastID {
+				lastID = ev.ID
+			}
+			lastWrite = time.Now()
+		}
+		for id := range sent {
+			if id <= lastID-StreamLookback {
+				delete(sent, id)
+			}
+		}
+		if time.Since(lastWrite) > StreamKeepAliveInterval {
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		flusher.Flush()
+
+		if !end.IsZero() && time.Now().After(end) {
+			return // the client will reconnect with the Last-Event-ID
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// optionalParam returns the value of the given query parameter, or nil if it wasn't given.
+func optionalParam(params url.Values, name string) *string {
+	if _, ok := params[name]; !ok {
+		return nil
+	}
+	val := params.Get(name)
+	return &val
+}
+
+// streamFilter is the filter of the change log stream. Nil fields match every entry.
+type streamFilter struct {
+	ObjectType *string
+	CDN        *string
+	Level      *string
+}
+
+type streamEvent struct {
+	ID   int
+	JSON string
+}
+
+// getStreamEvents returns the change log entries after the given ID which match the filter, as tc.ChangeLogEvent JSON, in ID order.
+func getStreamEvents(db *sql.DB, afterID int, filter streamFilter) ([]streamEvent, error) {
+	qry := `
+SELECT l.id, ` + api.ChangeLogEventJSONSQL + `
+FROM log l
+JOIN tm_user u ON u.id = l.tm_user
+CROSS JOIN (SELECT $2::text AS object_type, $3::text AS cdn, $4::text AS level) f
+WHERE l.id > $1 AND ` + api.ChangeLogFilterSQL + `
+ORDER BY l.id
+LIMIT $5
+`
+	rows, err := db.Query(qry, afterID, filter.ObjectType, filter.CDN, filter.Level, StreamBatchSize)
+	if err != nil {
+		return nil, errors.New("querying log: " + err.Error())
+	}
+	defer rows.Close()
+	events := []streamEvent{}
+	for rows.Next() {
+		ev := streamEvent{}
+		if err := rows.Scan(&ev.ID, &ev.JSON); err != nil {
+			return nil, errors.New("scanning log: " + err.Error())
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating log: " + err.Error())
+	}
+	return events, nil
+}
+
+func getLastLogID(db *sql.DB) (int, error) {
+	id := 0
+	if err := db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM log`).Scan(&id); err != nil {
+		return 0, errors.New("querying: " + err.Error())
+	}
+	return id, nil
+}
@@ -0,0 +1,414 @@
+package maintenancewindow
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	"github.com/lib/pq"
+)
+
+// Get handles GET requests for maintenance windows, optionally filtered by the id and state query parameters.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	windows, err := readWindows(inf.Tx.Tx, inf.IntParams["id"], inf.Params["state"], inf.Config.Maintenance.MaxCacheGroupDownPercent)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("reading maintenance windows: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, windows)
+}
+
+// Create handles POST requests to schedule a maintenance window.
+func Create(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	window, statusID, userErr, sysErr, errCode := parseWindow(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if !window.End.After(time.Now()) {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("end must be in the future"), nil)
+		return
+	}
+	qry := `
+INSERT INTO maintenance_window (name, description, start_time, end_time, cachegroup, status, offline_reason, queue_updates, snapshot, tm_user)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id
+`
+	if err := inf.Tx.Tx.QueryRow(qry, window.Name, window.Description, window.Start, window.End, window.CacheGroup, statusID, window.OfflineReason, *window.QueueUpdates, window.Snapshot, inf.User.ID).Scan(&window.ID); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if err := insertServers(inf.Tx.Tx, window); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting maintenance window servers: "+err.Error()))
+		return
+	}
+	writeWindow(w, r, inf, window, "scheduled")
+}
+
+// Update handles PUT requests to replace the maintenance window with the id query parameter.
+// Only the name, description, and end of an active window may be changed; ending it early is done by setting its end to the current time. Windows which have completed or were missed can't be changed.
+func Update(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	window, statusID, userErr, sysErr, errCode := parseWindow(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	window.ID = inf.IntParams["id"]
+	existing, ok, err := lockWindow(inf.Tx.Tx, window.ID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("locking maintenance window: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("maintenance window "+strconv.Itoa(window.ID)+" not found"), nil)
+		return
+	}
+	switch existing.State {
+	case tc.MaintenanceWindowStateScheduled:
+		if !window.End.After(time.Now()) {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("end must be in the future"), nil)
+			return
+		}
+	case tc.MaintenanceWindowStateActive:
+		if changed := existing.ActiveChanges(window); len(changed) > 0 {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("only the name, description, and end of an active maintenance window can be changed, not: "+strings.Join(changed, ", ")), nil)
+			return
+		}
+	default:
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("maintenance window is "+string(existing.State)+", and can't be changed"), nil)
+		return
+	}
+	qry := `
+UPDATE maintenance_window SET
+name = $1, description = $2, start_time = $3, end_time = $4, cachegroup = $5, status = $6, offline_reason = $7, queue_updates = $8, snapshot = $9, tm_user = $10, last_error = NULL, last_updated = now()
+WHERE id = $11
+`
+	if _, err := inf.Tx.Tx.Exec(qry, window.Name, window.Description, window.Start, window.End, window.CacheGroup, statusID, window.OfflineReason, *window.QueueUpdates, window.Snapshot, inf.User.ID, window.ID); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if existing.State == tc.MaintenanceWindowStateScheduled {
+		if _, err := inf.Tx.Tx.Exec(`DELETE FROM maintenance_window_server WHERE maintenance_window = $1`, window.ID); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting maintenance window servers: "+err.Error()))
+			return
+		}
+		if err := insertServers(inf.Tx.Tx, window); err != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("inserting maintenance window servers: "+err.Error()))
+			return
+		}
+	}
+	writeWindow(w, r, inf, window, "updated")
+}
+
+// Delete handles DELETE requests for the maintenance window with the id query parameter. Active windows can't be deleted; they must be ended first, by setting their end to the current time.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	id := inf.IntParams["id"]
+	existing, ok, err := lockWindow(inf.Tx.Tx, id)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("locking maintenance window: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("maintenance window "+strconv.Itoa(id)+" not found"), nil)
+		return
+	}
+	if existing.State == tc.MaintenanceWindowStateActive {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("maintenance window is active; end it by setting its end to the current time, and delete it after its servers are reverted"), nil)
+		return
+	}
+	if _, err := inf.Tx.Tx.Exec(`DELETE FROM maintenance_window WHERE id = $1`, id); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting maintenance window: "+err.Error()))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, changeLogPrefix(existing.Name, id)+"Deleted maintenance window", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "maintenance window was deleted.")
+}
+
+// changeLogPrefix returns the start of change log messages about the given window.
+func changeLogPrefix(name string, id int) string {
+	return "MAINTENANCE WINDOW: " + name + ", ID: " + strconv.Itoa(id) + ", ACTION: "
+}
+
+// parseWindow decodes, sanitizes, and validates the maintenance window in the request body, including that its status, servers, and cache group exist. Returns the window and the ID of its status.
+func parseWindow(tx *sql.Tx, r *http.Request) (tc.MaintenanceWindow, int, error, error, int) {
+	window := tc.MaintenanceWindow{}
+	if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+		return tc.MaintenanceWindow{}, 0, errors.New("decoding: " + err.Error()), nil, http.StatusBadRequest
+	}
+	window.Sanitize()
+	if err := window.Validate(); err != nil {
+		return tc.MaintenanceWindow{}, 0, errors.New("validating: " + err.Error()), nil, http.StatusBadRequest
+	}
+	status, ok, err := dbhelpers.GetStatusByName(window.Status, tx)
+	if err != nil {
+		return tc.MaintenanceWindow{}, 0, nil, errors.New("getting status: " + err.Error()), http.StatusInternalServerError
+	} else if !ok {
+		return tc.MaintenanceWindow{}, 0, errors.New("status '" + window.Status + "' not found"), nil, http.StatusBadRequest
+	}
+	missing := []int64{}
+	if err := tx.QueryRow(`SELECT ARRAY(SELECT id FROM unnest($1::bigint[]) AS id WHERE id NOT IN (SELECT id FROM server) ORDER BY id)`, pq.Array(serverIDs(window))).Scan(pq.Array(&missing)); err != nil {
+		return tc.MaintenanceWindow{}, 0, nil, errors.New("checking servers: " + err.Error()), http.StatusInternalServerError
+	}
+	if len(missing) > 0 {
+		strs := []string{}
+		for _, id := range missing {
+			strs = append(strs, strconv.FormatInt(id, 10))
+		}
+		return tc.MaintenanceWindow{}, 0, errors.New("servers not found: " + strings.Join(strs, ", ")), nil, http.StatusBadRequest
+	}
+	if window.CacheGroup != nil {
+		exists := false
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM cachegroup WHERE name = $1)`, *window.CacheGroup).Scan(&exists); err != nil {
+			return tc.MaintenanceWindow{}, 0, nil, errors.New("checking cachegroup: " + err.Error()), http.StatusInternalServerError
+		}
+		if !exists {
+			return tc.MaintenanceWindow{}, 0, errors.New("cachegroup '" + *window.CacheGroup + "' not found"), nil, http.StatusBadRequest
+		}
+	}
+	return window, *status.ID, nil, nil, http.StatusOK
+}
+
+func serverIDs(window tc.MaintenanceWindow) []int64 {
+	ids := []int64{}
+	for _, id := range window.Servers {
+		ids = append(ids, int64(id))
+	}
+	return ids
+}
+
+// insertServers inserts the window's servers, which must not exist.
+func insertServers(tx *sql.Tx, window tc.MaintenanceWindow) error {
+	_, err := tx.Exec(`INSERT INTO maintenance_window_server (maintenance_window, server) SELECT $1, unnest($2::bigint[])`, window.ID, pq.Array(serverIDs(window)))
+	return err
+}
+
+// lockWindow locks the window for update, so the Scheduler won't start or end it until the transaction is done, and returns it. Returns false if it doesn't exist.
+func lockWindow(tx *sql.Tx, id int) (tc.MaintenanceWindow, bool, error) {
+	if _, err := tx.Exec(`SELECT id FROM maintenance_window WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return tc.MaintenanceWindow{}, false, err
+	}
+	windows, err := readWindows(tx, id, "", 0)
+	if err != nil {
+		return tc.MaintenanceWindow{}, false, err
+	}
+	if len(windows) == 0 {
+		return tc.MaintenanceWindow{}, false, nil
+	}
+	return windows[0], true, nil
+}
+
+// writeWindow records the created or updated window in the change log, and writes it with a success alert.
+func writeWindow(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, window tc.MaintenanceWindow, action string) {
+	tx := inf.Tx.Tx
+	windows, err := readWindows(tx, window.ID, "", inf.Config.Maintenance.MaxCacheGroupDownPercent)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("reading "+action+" maintenance window: "+err.Error()))
+		return
+	}
+	if len(windows) != 1 {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("reading "+action+" maintenance window: not found"))
+		return
+	}
+	window = windows[0]
+	msg := changeLogPrefix(window.Name, window.ID) + strings.Title(action) + " maintenance window from " + window.Start.UTC().Format(time.RFC3339) + " to " + window.End.UTC().Format(time.RFC3339)
+	api.CreateChangeLogRawTx(api.ApiChange, msg, inf.User, tx)
+	alert := "maintenance window was " + action + "."
+	if len(window.Warnings) > 0 {
+		alert = "maintenance window was " + action + ", with warnings: " + strings.Join(window.Warnings, "; ")
+	}
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, alert, window)
+}
+
+// readWindows returns the maintenance windows, ordered by start, optionally only the one with the given ID and those in the given state.
+// The warnings of windows which are scheduled or active are computed, if maxDownPercent isn't zero.
+func readWindows(tx *sql.Tx, id int, state string, maxDownPercent int) ([]tc.MaintenanceWindow, error) {
+	qry := `
+SELECT w.id, w.name, w.description, w.start_time, w.end_time, w.cachegroup, st.name, w.offline_reason, w.queue_updates, w.snapshot, w.state, u.username, w.last_error, w.last_updated,
+  ARRAY(SELECT ws.server FROM maintenance_window_server AS ws WHERE ws.maintenance_window = w.id AND ws.explicit ORDER BY ws.server)
+FROM maintenance_window AS w
+JOIN status AS st ON st.id = w.status
+JOIN tm_user AS u ON u.id = w.tm_user
+WHERE ($1 = 0 OR w.id = $1) AND ($2 = '' OR w.state = $2)
+ORDER BY w.start_time, w.id
+`
+	rows, err := tx.Query(qry, id, state)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	windows := []tc.MaintenanceWindow{}
+	for rows.Next() {
+		w := tc.MaintenanceWindow{}
+		queueUpdates := false
+		servers := []int64{}
+		if err := rows.Scan(&w.ID, &w.Name, &w.Description, &w.Start, &w.End, &w.CacheGroup, &w.Status, &w.OfflineReason, &queueUpdates, &w.Snapshot, &w.State, &w.User, &w.LastError, &w.LastUpdated, pq.Array(&servers)); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		w.QueueUpdates = &queueUpdates
+		w.Servers = []int{}
+		for _, server := range servers {
+			w.Servers = append(w.Servers, int(server))
+		}
+		w.Warnings = []string{}
+		windows = append(windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating: " + err.Error())
+	}
+	rows.Close()
+	if maxDownPercent == 0 {
+		return windows, nil
+	}
+	for i, w := range windows {
+		if w.State != tc.MaintenanceWindowStateScheduled && w.State != tc.MaintenanceWindowStateActive {
+			continue
+		}
+		if windows[i].Warnings, err = getWarnings(tx, w.ID, maxDownPercent); err != nil {
+			return nil, errors.New("getting warnings of maintenance window '" + w.Name + "': " + err.Error())
+		}
+	}
+	return windows, nil
+}
+
+// targetsCTE is a common table expression of the servers each window targets, with the columns mw and server. These are the servers added to the window, and, for scheduled windows, the servers of its cache group; the servers of an active window's cache group were added to it when it started.
+const targetsCTE = `
+targets AS (
+  SELECT ws.maintenance_window AS mw, ws.server FROM maintenance_window_server AS ws
+  UNION
+  SELECT w.id, s.id FROM maintenance_window AS w
+  JOIN cachegroup AS cg ON cg.name = w.cachegroup
+  JOIN server AS s ON s.cachegroup = cg.id
+  WHERE w.state = 'scheduled'
+)`
+
+// overlapsSQL is the SQL condition for whether the scheduled or active window aliased 'o' overlaps the window aliased 'w'.
+const overlapsSQL = `o.state IN ('scheduled', 'active') AND o.start_time < w.end_time AND w.start_time < o.end_time`
+
+// getWarnings returns the warnings of the window: the other windows it overlaps, which target the same servers, and the cache groups which would have more than maxDownPercent of their servers out of service at once.
+// Servers are considered out of service if they're targeted by the window, or by any window it overlaps, or are ADMIN_DOWN or OFFLINE.
+func getWarnings(tx *sql.Tx, id int, maxDownPercent int) ([]string, error) {
+	warnings := []string{}
+	rows, err := tx.Query(`
+WITH `+targetsCTE+`
+SELECT o.name, ARRAY_AGG(s.host_name ORDER BY s.host_name)
+FROM maintenance_window AS w
+JOIN maintenance_window AS o ON o.id <> w.id AND `+overlapsSQL+`
+JOIN targets AS wt ON wt.mw = w.id
+JOIN targets AS ot ON ot.mw = o.id AND ot.server = wt.server
+JOIN server AS s ON s.id = wt.server
+WHERE w.id = $1
+GROUP BY o.name
+ORDER BY o.name
+`, id)
+	if err != nil {
+		return nil, errors.New("querying overlapping windows: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		name := ""
+		hosts := []string{}
+		if err := rows.Scan(&name, pq.Array(&hosts)); err != nil {
+			return nil, errors.New("scanning overlapping windows: " + err.Error())
+		}
+		warnings = append(warnings, "overlaps maintenance window '"+name+"' on servers: "+strings.Join(hosts, ", "))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating overlapping windows: " + err.Error())
+	}
+	rows.Close()
+
+	cgRows, err := tx.Query(`
+WITH `+targetsCTE+`,
+down AS (
+  SELECT t.server FROM targets AS t
+  JOIN maintenance_window AS o ON o.id = t.mw
+  JOIN maintenance_window AS w ON w.id = $1
+  WHERE o.id = w.id OR (`+overlapsSQL+`)
+)
+SELECT cg.name, COUNT(*), COUNT(*) FILTER (WHERE s.id IN (SELECT server FROM down) OR st.name IN ('`+tc.CacheStatusAdminDown.String()+`', '`+tc.CacheStatusOffline.String()+`'))
+FROM server AS s
+JOIN cachegroup AS cg ON cg.id = s.cachegroup
+JOIN status AS st ON st.id = s.status
+WHERE s.cachegroup IN (SELECT ts.cachegroup FROM targets AS t JOIN server AS ts ON ts.id = t.server WHERE t.mw = $1)
+GROUP BY cg.name
+ORDER BY cg.name
+`, id)
+	if err != nil {
+		return nil, errors.New("querying cachegroups: " + err.Error())
+	}
+	defer cgRows.Close()
+	for cgRows.Next() {
+		cg := ""
+		total := 0
+		down := 0
+		if err := cgRows.Scan(&cg, &total, &down); err != nil {
+			return nil, errors.New("scanning cachegroups: " + err.Error())
+		}
+		if warning := capacityWarning(cg, down, total, maxDownPercent); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	if err := cgRows.Err(); err != nil {
+		return nil, errors.New("iterating cachegroups: " + err.Error())
+	}
+	return warnings, nil
+}
+
+// capacityWarning returns the warning for a cache group with down of its total servers out of service, or the empty string if it's no more than maxDownPercent.
+func capacityWarning(cg string, down int, total int, maxDownPercent int) string {
+	if total == 0 || down*100 <= total*maxDownPercent {
+		return ""
+	}
+	return "takes up to " + strconv.Itoa(down) + " of the " + strconv.Itoa(total) + " servers of cachegroup '" + cg + "' out of service at once, more than the maximum " + strconv.Itoa(maxDownPercent) + "%"
+}
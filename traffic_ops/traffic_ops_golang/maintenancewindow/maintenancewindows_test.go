@@ -0,0 +1,79 @@
+package maintenancewindow
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCapacityWarning(t *testing.T) {
+	if warning := capacityWarning("edge", 5, 10, 50); warning != "" {
+		t.Errorf("expected no warning at the maximum, actual '%s'", warning)
+	}
+	if warning := capacityWarning("edge", 0, 0, 50); warning != "" {
+		t.Errorf("expected no warning for an empty cachegroup, actual '%s'", warning)
+	}
+	expected := "takes up to 6 of the 10 servers of cachegroup 'edge' out of service at once, more than the maximum 50%"
+	if warning := capacityWarning("edge", 6, 10, 50); warning != expected {
+		t.Errorf("expected warning '%s', actual '%s'", expected, warning)
+	}
+}
+
+func TestGetWarnings(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	overlapRows := sqlmock.NewRows([]string{"name", "hosts"})
+	overlapRows = overlapRows.AddRow("kernel-upgrade", []byte(`{edge-1,edge-2}`))
+	cgRows := sqlmock.NewRows([]string{"name", "total", "down"})
+	cgRows = cgRows.AddRow("edge-east", 4, 2)
+	cgRows = cgRows.AddRow("edge-west", 4, 3)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.name").WithArgs(7).WillReturnRows(overlapRows)
+	mock.ExpectQuery("SELECT cg.name").WithArgs(7).WillReturnRows(cgRows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	warnings, err := getWarnings(tx, 7, 50)
+	if err != nil {
+		t.Fatalf("getWarnings expected nil error, actual: %v", err)
+	}
+	tx.Commit()
+
+	expected := []string{
+		"overlaps maintenance window 'kernel-upgrade' on servers: edge-1, edge-2",
+		"takes up to 3 of the 4 servers of cachegroup 'edge-west' out of service at once, more than the maximum 50%",
+	}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("getWarnings expected %+v, actual %+v", expected, warnings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package maintenancewindow
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/monitoring"
+)
+
+// Scheduler starts and ends maintenance windows when they're due, changing and reverting the statuses of their servers.
+// Each window is locked in the database while it's started or ended, so any number of Traffic Ops instances may run a Scheduler.
+type Scheduler struct {
+	DB  *sql.DB
+	Cfg config.Config
+}
+
+// NewScheduler creates a Scheduler from the given config, whose Maintenance section must not be nil.
+func NewScheduler(db *sql.DB, cfg config.Config) *Scheduler {
+	return &Scheduler{DB: db, Cfg: cfg}
+}
+
+// Run starts and ends maintenance windows forever. It should be called in its own goroutine.
+func (sc *Scheduler) Run() {
+	interval := time.Duration(sc.Cfg.Maintenance.PollIntervalSeconds) * time.Second
+	for {
+		if err := sc.RunDue(); err != nil {
+			log.Errorln("running maintenance windows: " + err.Error())
+		}
+		time.Sleep(interval)
+	}
+}
+
+// RunDue starts the windows which are due to start, ends those which are due to end, and marks as missed those which ended before they could be started.
+// A window which fails is retried the next time RunDue is called, and its error is recorded in the window.
+func (sc *Scheduler) RunDue() error {
+	ids, err := sc.dueWindows()
+	if err != nil {
+		return errors.New("getting due windows: " + err.Error())
+	}
+	for _, id := range ids {
+		runErr := sc.withTx(func(tx *sql.Tx) error { return sc.run(tx, id) })
+		if runErr == nil {
+			continue
+		}
+		log.Errorln("running maintenance window " + strconv.Itoa(id) + ": " + runErr.Error())
+		if _, err := sc.DB.Exec(`UPDATE maintenance_window SET last_error = $1 WHERE id = $2`, runErr.Error(), id); err != nil {
+			log.Errorln("recording error of maintenance window " + strconv.Itoa(id) + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// dueSQL is the SQL condition for whether the window aliased 'w' is due to be started or ended.
+const dueSQL = `((w.state = 'scheduled' AND w.start_time <= now()) OR (w.state = 'active' AND w.end_time <= now()))`
+
+func (sc *Scheduler) dueWindows() ([]int, error) {
+	rows, err := sc.DB.Query(`SELECT w.id FROM maintenance_window AS w WHERE ` + dueSQL + ` ORDER BY w.id`)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		id := 0
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// dueWindow is a maintenance window locked to be started or ended.
+type dueWindow struct {
+	ID            int
+	Name          string
+	State         tc.MaintenanceWindowState
+	Ended         bool
+	StatusID      int
+	Status        string
+	OfflineReason string
+	QueueUpdates  bool
+	Snapshot      bool
+	CacheGroup    *string
+	User          auth.CurrentUser
+}
+
+// run locks the window, if it's still due and no other Scheduler has locked it, and starts, ends, or marks it missed.
+func (sc *Scheduler) run(tx *sql.Tx, id int) error {
+	w := dueWindow{ID: id}
+	qry := `
+SELECT w.name, w.state, w.end_time <= now(), w.status, st.name, w.offline_reason, w.queue_updates, w.snapshot, w.cachegroup, u.id, u.username
+FROM maintenance_window AS w
+JOIN status AS st ON st.id = w.status
+JOIN tm_user AS u ON u.id = w.tm_user
+WHERE w.id = $1 AND ` + dueSQL + `
+FOR UPDATE OF w SKIP LOCKED
+`
+	if err := tx.QueryRow(qry, id).Scan(&w.Name, &w.State, &w.Ended, &w.StatusID, &w.Status, &w.OfflineReason, &w.QueueUpdates, &w.Snapshot, &w.CacheGroup, &w.User.ID, &w.User.UserName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil // it was run by another Scheduler, or changed so it's no longer due
+		}
+		return errors.New("locking: " + err.Error())
+	}
+	switch {
+	case w.State == tc.MaintenanceWindowStateScheduled && w.Ended:
+		return sc.miss(tx, w)
+	case w.State == tc.MaintenanceWindowStateScheduled:
+		return sc.start(tx, w)
+	default:
+		return sc.end(tx, w)
+	}
+}
+
+// start records the current statuses of the window's servers, adding the servers of its cache group, and gives them the window's status.
+func (sc *Scheduler) start(tx *sql.Tx, w dueWindow) error {
+	if w.CacheGroup != nil {
+		qry := `
+INSERT INTO maintenance_window_server (maintenance_window, server, explicit)
+SELECT $1, s.id, FALSE FROM server AS s JOIN cachegroup AS cg ON cg.id = s.cachegroup WHERE cg.name = $2
+ON CONFLICT DO NOTHING
+`
+		if _, err := tx.Exec(qry, w.ID, *w.CacheGroup); err != nil {
+			return errors.New("adding cachegroup servers: " + err.Error())
+		}
+	}
+	qry := `
+UPDATE maintenance_window_server AS ws SET previous_status = s.status, previous_offline_reason = s.offline_reason, last_updated = now()
+FROM server AS s
+WHERE s.id = ws.server AND ws.maintenance_window = $1
+`
+	if _, err := tx.Exec(qry, w.ID); err != nil {
+		return errors.New("recording server statuses: " + err.Error())
+	}
+	offlineReason := (*string)(nil)
+	if w.Status == tc.CacheStatusAdminDown.String() || w.Status == tc.CacheStatusOffline.String() {
+		reason := w.User.UserName + ": " + w.OfflineReason
+		offlineReason = &reason
+	}
+	hosts, err := queryStrings(tx, `
+UPDATE server AS s SET status = $2, offline_reason = $3
+FROM maintenance_window_server AS ws
+WHERE ws.server = s.id AND ws.maintenance_window = $1
+RETURNING s.host_name
+`, w.ID, w.StatusID, offlineReason)
+	if err != nil {
+		return errors.New("updating server statuses: " + err.Error())
+	}
+	msg := changeLogPrefix(w.Name, w.ID) + "Started maintenance window, updated status [ " + w.Status + " ] for servers: " + strings.Join(hosts, ", ")
+	if err := api.CreateChangeLogRawErr(api.ApiChange, msg, &w.User, tx); err != nil {
+		return err
+	}
+	if err := sc.propagate(tx, w, "start"); err != nil {
+		return err
+	}
+	return setState(tx, w.ID, tc.MaintenanceWindowStateActive)
+}
+
+// end reverts the statuses of the window's servers, except those whose status was changed while the window was active, which are left as they are.
+func (sc *Scheduler) end(tx *sql.Tx, w dueWindow) error {
+	reverted, err := queryStrings(tx, `
+UPDATE server AS s SET status = ws.previous_status, offline_reason = ws.previous_offline_reason
+FROM maintenance_window_server AS ws
+WHERE ws.server = s.id AND ws.maintenance_window = $1 AND s.status = $2 AND ws.previous_status IS NOT NULL
+RETURNING s.host_name
+`, w.ID, w.StatusID)
+	if err != nil {
+		return errors.New("reverting server statuses: " + err.Error())
+	}
+	unchanged, err := queryStrings(tx, `
+SELECT s.host_name
+FROM server AS s
+JOIN maintenance_window_server AS ws ON ws.server = s.id
+WHERE ws.maintenance_window = $1 AND (s.status <> $2 OR ws.previous_status IS NULL)
+`, w.ID, w.StatusID)
+	if err != nil {
+		return errors.New("getting unchanged servers: " + err.Error())
+	}
+	msg := changeLogPrefix(w.Name, w.ID) + "Ended maintenance window, reverted status for servers: " + strings.Join(reverted, ", ")
+	if len(unchanged) > 0 {
+		msg += "; left servers whose status was changed during the window: " + strings.Join(unchanged, ", ")
+	}
+	if err := api.CreateChangeLogRawErr(api.ApiChange, msg, &w.User, tx); err != nil {
+		return err
+	}
+	if err := sc.propagate(tx, w, "end"); err != nil {
+		return err
+	}
+	return setState(tx, w.ID, tc.MaintenanceWindowStateCompleted)
+}
+
+// miss marks the window missed, without changing its servers, because it ended before it could be started, e.g. because no Traffic Ops was running.
+func (sc *Scheduler) miss(tx *sql.Tx, w dueWindow) error {
+	msg := changeLogPrefix(w.Name, w.ID) + "Missed maintenance window, which ended before it could be started; no servers were changed"
+	if err := api.CreateChangeLogRawErr(api.ApiChange, msg, &w.User, tx); err != nil {
+		return err
+	}
+	return setState(tx, w.ID, tc.MaintenanceWindowStateMissed)
+}
+
+// propagate queues updates on the child caches of the window's servers, and snapshots their CDNs, if the window is configured to.
+func (sc *Scheduler) propagate(tx *sql.Tx, w dueWindow, event string) error {
+	if w.QueueUpdates {
+		// like PUT servers/{id}/status, queue updates on the children of EDGE and MID servers
+		qry := `
+UPDATE server SET upd_pending = TRUE
+WHERE (cdn_id, cachegroup) IN (
+  SELECT s.cdn_id, cg.id
+  FROM maintenance_window_server AS ws
+  JOIN server AS s ON s.id = ws.server
+  JOIN type AS t ON t.id = s.type
+  JOIN cachegroup AS cg ON cg.parent_cachegroup_id = s.cachegroup OR cg.secondary_parent_cachegroup_id = s.cachegroup
+  WHERE ws.maintenance_window = $1 AND (t.name LIKE '` + tc.CacheTypeEdge.String() + `%' OR t.name LIKE '` + tc.CacheTypeMid.String() + `%')
+)
+`
+		if _, err := tx.Exec(qry, w.ID); err != nil {
+			return errors.New("queueing updates on child caches: " + err.Error())
+		}
+		msg := changeLogPrefix(w.Name, w.ID) + "Queued updates on all child caches at maintenance window " + event
+		if err := api.CreateChangeLogRawErr(api.ApiChange, msg, &w.User, tx); err != nil {
+			return err
+		}
+	}
+	if !w.Snapshot {
+		return nil
+	}
+	cdns, err := queryStrings(tx, `
+SELECT DISTINCT cdn.name
+FROM cdn
+JOIN server AS s ON s.cdn_id = cdn.id
+JOIN maintenance_window_server AS ws ON ws.server = s.id
+WHERE ws.maintenance_window = $1
+`, w.ID)
+	if err != nil {
+		return errors.New("getting cdns: " + err.Error())
+	}
+	for _, cdn := range cdns {
+		if err := sc.snapshot(tx, cdn, w.User.UserName); err != nil {
+			return errors.New("snapshotting cdn '" + cdn + "': " + err.Error())
+		}
+		msg := "CDN: " + cdn + ", ACTION: Snapshot of CRConfig and Monitor at " + event + " of maintenance window '" + w.Name + "'"
+		if err := api.CreateChangeLogRawErr(api.ApiChange, msg, &w.User, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot snapshots the CRConfig and monitoring config of the CDN. There is no request to take the Traffic Ops host from, so it's always taken from the global tm.url parameter.
+func (sc *Scheduler) snapshot(tx *sql.Tx, cdn string, user string) error {
+	crConfig, err := crconfig.Make(tx, cdn, user, "", "", sc.Cfg.Version, false, sc.Cfg.CRConfigEmulateOldPath)
+	if err != nil {
+		return errors.New("making CRConfig: " + err.Error())
+	}
+	monitoringJSON, err := monitoring.GetMonitoringJSON(tx, cdn)
+	if err != nil {
+		return errors.New("getting monitoring.json data: " + err.Error())
+	}
+	return crconfig.Snapshot(tx, crConfig, monitoringJSON, sc.Cfg.SnapshotHistory.MaxSnapshots)
+}
+
+func setState(tx *sql.Tx, id int, state tc.MaintenanceWindowState) error {
+	if _, err := tx.Exec(`UPDATE maintenance_window SET state = $1, last_error = NULL, last_updated = now() WHERE id = $2`, string(state), id); err != nil {
+		return errors.New("setting state: " + err.Error())
+	}
+	return nil
+}
+
+// queryStrings returns the first column of the rows of the query, sorted.
+func queryStrings(tx *sql.Tx, qry string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(qry, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	strs := []string{}
+	for rows.Next() {
+		str := ""
+		if err := rows.Scan(&str); err != nil {
+			return nil, err
+		}
+		strs = append(strs, str)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(strs)
+	return strs, nil
+}
+
+// withTx calls f in a new transaction, committing it if f succeeds.
+func (sc *Scheduler) withTx(f func(tx *sql.Tx) error) error {
+	tx, err := sc.DB.Begin()
+	if err != nil {
+		return errors.New("beginning transaction: " + err.Error())
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
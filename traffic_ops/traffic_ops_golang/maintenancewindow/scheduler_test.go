@@ -0,0 +1,97 @@
+package maintenancewindow
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestRunDueStartsAndEnds(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	cols := []string{"name", "state", "ended", "status", "status_name", "offline_reason", "queue_updates", "snapshot", "cachegroup", "user_id", "username"}
+	mock.ExpectQuery("SELECT w.id FROM maintenance_window").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+
+	// window 1 is due to start, with its cachegroup and queued updates
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF w SKIP LOCKED").WithArgs(1).WillReturnRows(sqlmock.NewRows(cols).AddRow("drain-east", "scheduled", false, 3, "ADMIN_DOWN", "rack work", true, false, "edge-east", 5, "ops"))
+	mock.ExpectExec("INSERT INTO maintenance_window_server").WithArgs(1, "edge-east").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("SET previous_status").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("UPDATE server AS s SET status").WithArgs(1, 3, "ops: rack work").WillReturnRows(sqlmock.NewRows([]string{"host_name"}).AddRow("edge-2").AddRow("edge-1"))
+	mock.ExpectExec("INSERT INTO log").WithArgs("APICHANGE", "MAINTENANCE WINDOW: drain-east, ID: 1, ACTION: Started maintenance window, updated status [ ADMIN_DOWN ] for servers: edge-1, edge-2", 5, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE server SET upd_pending").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("INSERT INTO log").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE maintenance_window SET state").WithArgs("active", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// window 2 is due to end; edge-3's status was changed during it
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF w SKIP LOCKED").WithArgs(2).WillReturnRows(sqlmock.NewRows(cols).AddRow("drain-west", "active", true, 3, "ADMIN_DOWN", "rack work", false, false, nil, 5, "ops"))
+	mock.ExpectQuery("SET status = ws.previous_status").WithArgs(2, 3).WillReturnRows(sqlmock.NewRows([]string{"host_name"}).AddRow("edge-4"))
+	mock.ExpectQuery("SELECT s.host_name").WithArgs(2, 3).WillReturnRows(sqlmock.NewRows([]string{"host_name"}).AddRow("edge-3"))
+	mock.ExpectExec("INSERT INTO log").WithArgs("APICHANGE", "MAINTENANCE WINDOW: drain-west, ID: 2, ACTION: Ended maintenance window, reverted status for servers: edge-4; left servers whose status was changed during the window: edge-3", 5, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE maintenance_window SET state").WithArgs("completed", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// window 3 was run by another Traffic Ops
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF w SKIP LOCKED").WithArgs(3).WillReturnRows(sqlmock.NewRows(cols))
+	mock.ExpectCommit()
+
+	sc := NewScheduler(mockDB, config.Config{Maintenance: &config.ConfigMaintenance{}})
+	if err := sc.RunDue(); err != nil {
+		t.Fatalf("RunDue expected nil error, actual: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
+
+func TestRunDueMissed(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	cols := []string{"name", "state", "ended", "status", "status_name", "offline_reason", "queue_updates", "snapshot", "cachegroup", "user_id", "username"}
+	mock.ExpectQuery("SELECT w.id FROM maintenance_window").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF w SKIP LOCKED").WithArgs(4).WillReturnRows(sqlmock.NewRows(cols).AddRow("overnight", "scheduled", true, 3, "ADMIN_DOWN", "rack work", true, true, nil, 5, "ops"))
+	mock.ExpectExec("INSERT INTO log").WithArgs("APICHANGE", "MAINTENANCE WINDOW: overnight, ID: 4, ACTION: Missed maintenance window, which ended before it could be started; no servers were changed", 5, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE maintenance_window SET state").WithArgs("missed", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	sc := NewScheduler(mockDB, config.Config{Maintenance: &config.ConfigMaintenance{}})
+	if err := sc.RunDue(); err != nil {
+		t.Fatalf("RunDue expected nil error, actual: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %v", err)
+	}
+}
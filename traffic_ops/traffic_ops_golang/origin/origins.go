@@ -132,7 +132,7 @@ func (origin *TOOrigin) IsTenantAuthorized(user *auth.CurrentUser) (bool, error)
 func (origin *TOOrigin) Read() ([]interface{}, error, error, int) {
 	returnable := []interface{}{}
 
-	origins, userErr, sysErr, errCode := getOrigins(origin.ReqInfo.Params, origin.ReqInfo.Tx, origin.ReqInfo.User)
+	origins, userErr, sysErr, errCode := getOrigins(origin.ReqInfo)
 	if userErr != nil || sysErr != nil {
 		return nil, userErr, sysErr, errCode
 	}
@@ -144,7 +144,8 @@ func (origin *TOOrigin) Read() ([]interface{}, error, error, int) {
 	return returnable, nil, nil, http.StatusOK
 }
 
-func getOrigins(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.Origin, error, error, int) {
+func getOrigins(inf *api.APIInfo) ([]tc.Origin, error, error, int) {
+	params, tx, user := inf.Params, inf.Tx, inf.User
 	var rows *sqlx.Rows
 	var err error
 
@@ -173,6 +174,10 @@ func getOrigins(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) (
 	}
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "o.tenant", tenantIDs)
 
+	if err := inf.CountResults(selectQuery()+where, queryValues); err != nil {
+		return nil, nil, fmt.Errorf("counting: %v", err), http.StatusInternalServerError
+	}
+
 	query := selectQuery() + where + orderBy + pagination
 	log.Debugln("Query is ", query)
 
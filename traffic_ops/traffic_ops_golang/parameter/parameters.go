@@ -126,6 +126,9 @@ func (param *TOParameter) Read() ([]interface{}, error, error, int) {
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
+	if err := param.APIInfo().CountResults(selectQuery()+where+ParametersGroupBy(), queryValues); err != nil {
+		return nil, nil, errors.New("counting " + param.GetType() + ": " + err.Error()), http.StatusInternalServerError
+	}
 	query := selectQuery() + where + ParametersGroupBy() + orderBy + pagination
 	rows, err := param.ReqInfo.Tx.NamedQuery(query, queryValues)
 	if err != nil {
@@ -35,10 +35,12 @@ func Keys(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	pingResp, err := riaksvc.Ping(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort)
+	err := riaksvc.WithKeyStore(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, func(ks riaksvc.KeyStore) error {
+		return ks.Ping()
+	})
 	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("error pinging Riak keys: "+err.Error()))
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("error pinging key store: "+err.Error()))
 		return
 	}
-	api.WriteResp(w, r, pingResp.Status)
+	api.WriteResp(w, r, "OK")
 }
@@ -125,6 +125,9 @@ func (prof *TOProfile) Read() ([]interface{}, error, error, int) {
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
+	if err := prof.APIInfo().CountResults(selectProfilesQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("profile read counting: " + err.Error()), http.StatusInternalServerError
+	}
 	query := selectProfilesQuery() + where + orderBy + pagination
 	log.Debugln("Query is ", query)
 
@@ -0,0 +1,229 @@
+package ratelimit
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+// The groups of routes, which have separate limits.
+const (
+	GroupDefault = "default"
+	GroupORT     = "ort"
+	GroupMonitor = "monitor"
+)
+
+// The kinds of clients, which have separate buckets.
+const (
+	KindUser = "user"
+	KindIP   = "ip"
+)
+
+// MaxTopLimited is the maximum number of clients in the TopLimited of each limit's stats.
+const MaxTopLimited = 10
+
+// Limiter limits the rate of requests with token buckets, one per client of each kind of each group.
+// Buckets are only kept in memory, so each Traffic Ops instance limits its own requests.
+// A nil Limiter allows all requests.
+type Limiter struct {
+	limits    map[limitKey]*limit
+	now       func() time.Time
+	mutex     sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type limitKey struct {
+	group string
+	kind  string
+}
+
+type limit struct {
+	rps     float64
+	burst   float64
+	idle    time.Duration
+	allowed uint64
+	limited uint64
+}
+
+type bucketKey struct {
+	limitKey
+	client string
+}
+
+type bucket struct {
+	tokens  float64
+	last    time.Time
+	allowed uint64
+	limited uint64
+}
+
+// New creates a Limiter from the given config, which must have been parsed by config.ParseConfig. If the config is nil, New returns nil, which allows all requests.
+func New(cfg *config.ConfigRateLimit) *Limiter {
+	if cfg == nil {
+		return nil
+	}
+	l := &Limiter{limits: map[limitKey]*limit{}, now: time.Now, buckets: map[bucketKey]*bucket{}}
+	groups := map[string]*config.ConfigRateLimitGroup{GroupDefault: &cfg.Default, GroupORT: cfg.ORT, GroupMonitor: cfg.Monitor}
+	for name, group := range groups {
+		if group == nil {
+			group = &cfg.Default
+		}
+		l.addLimit(name, KindUser, group.User, cfg.IdleSeconds)
+		l.addLimit(name, KindIP, group.IP, cfg.IdleSeconds)
+	}
+	return l
+}
+
+func (l *Limiter) addLimit(group string, kind string, b *config.ConfigRateLimitBucket, idleSeconds int) {
+	if b == nil {
+		return
+	}
+	lim := &limit{rps: b.RequestsPerSecond, burst: float64(b.Burst), idle: time.Duration(idleSeconds) * time.Second}
+	if lim.burst < 1 {
+		lim.burst = 1
+	}
+	// a bucket forgotten before it's full would be refilled early
+	if fill := time.Duration(lim.burst / lim.rps * float64(time.Second)); fill > lim.idle {
+		lim.idle = fill
+	}
+	l.limits[limitKey{group: group, kind: kind}] = lim
+}
+
+// Allow takes a token from the bucket of the given client of the given kind and group, and returns whether there was one.
+// If there wasn't, it also returns how long until there will be.
+// Requests of groups and kinds without limits are always allowed.
+func (l *Limiter) Allow(group string, kind string, client string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	lk := limitKey{group: group, kind: kind}
+	lim, ok := l.limits[lk]
+	if !ok {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bk := bucketKey{limitKey: lk, client: client}
+	b, ok := l.buckets[bk]
+	if !ok {
+		b = &bucket{tokens: lim.burst, last: now}
+		l.buckets[bk] = b
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(lim.burst, b.tokens+elapsed.Seconds()*lim.rps)
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		b.limited++
+		lim.limited++
+		return false, time.Duration((1 - b.tokens) / lim.rps * float64(time.Second))
+	}
+	b.tokens--
+	b.allowed++
+	lim.allowed++
+	return true, 0
+}
+
+// sweep forgets the buckets of clients which have been idle for longer than their limit's idle time. The mutex must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > l.limits[key.limitKey].idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Stats returns the state of each limit, ordered by group and kind.
+func (l *Limiter) Stats() []tc.RateLimitStats {
+	stats := []tc.RateLimitStats{}
+	if l == nil {
+		return stats
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	for _, group := range []string{GroupDefault, GroupORT, GroupMonitor} {
+		for _, kind := range []string{KindUser, KindIP} {
+			lk := limitKey{group: group, kind: kind}
+			lim, ok := l.limits[lk]
+			if !ok {
+				continue
+			}
+			st := tc.RateLimitStats{
+				Group:             group,
+				Kind:              kind,
+				RequestsPerSecond: lim.rps,
+				Burst:             int(lim.burst),
+				Allowed:           lim.allowed,
+				Limited:           lim.limited,
+				TopLimited:        []tc.RateLimitClientStats{},
+			}
+			for key, b := range l.buckets {
+				if key.limitKey != lk || now.Sub(b.last) > lim.idle {
+					continue
+				}
+				st.Clients++
+				if b.limited == 0 {
+					continue
+				}
+				tokens := math.Min(lim.burst, b.tokens+now.Sub(b.last).Seconds()*lim.rps)
+				st.TopLimited = append(st.TopLimited, tc.RateLimitClientStats{Client: key.client, Tokens: tokens, Allowed: b.allowed, Limited: b.limited})
+			}
+			sort.Slice(st.TopLimited, func(i, j int) bool {
+				if st.TopLimited[i].Limited != st.TopLimited[j].Limited {
+					return st.TopLimited[i].Limited > st.TopLimited[j].Limited
+				}
+				return st.TopLimited[i].Client < st.TopLimited[j].Client
+			})
+			if len(st.TopLimited) > MaxTopLimited {
+				st.TopLimited = st.TopLimited[:MaxTopLimited]
+			}
+			stats = append(stats, st)
+		}
+	}
+	return stats
+}
+
+// StatsHandler returns a handler of GET /rate_limits/stats, which writes the stats of the given Limiter.
+func StatsHandler(l *Limiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		api.WriteResp(w, r, l.Stats())
+	}
+}
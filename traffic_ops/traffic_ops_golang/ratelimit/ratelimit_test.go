@@ -0,0 +1,135 @@
+package ratelimit
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+func newTestLimiter(now *time.Time) *Limiter {
+	l := New(&config.ConfigRateLimit{
+		Default:     config.ConfigRateLimitGroup{User: &config.ConfigRateLimitBucket{RequestsPerSecond: 2, Burst: 3}},
+		ORT:         &config.ConfigRateLimitGroup{IP: &config.ConfigRateLimitBucket{RequestsPerSecond: 1, Burst: 1}},
+		IdleSeconds: 600,
+	})
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestNilLimiter(t *testing.T) {
+	l := New(nil)
+	if l != nil {
+		t.Fatalf("New(nil) expected: nil, actual: %+v", l)
+	}
+	if allowed, _ := l.Allow(GroupDefault, KindUser, "bill"); !allowed {
+		t.Error("nil limiter expected: allowed, actual: limited")
+	}
+	if stats := l.Stats(); len(stats) != 0 {
+		t.Errorf("nil limiter stats expected: empty, actual: %+v", stats)
+	}
+}
+
+func TestAllow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newTestLimiter(&now)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := l.Allow(GroupDefault, KindUser, "bill"); !allowed {
+			t.Fatalf("request %v in burst expected: allowed, actual: limited", i)
+		}
+	}
+	allowed, retry := l.Allow(GroupDefault, KindUser, "bill")
+	if allowed {
+		t.Fatal("request after burst expected: limited, actual: allowed")
+	}
+	if retry != 500*time.Millisecond {
+		t.Errorf("retry expected: 500ms, actual: %v", retry)
+	}
+	if allowed, _ := l.Allow(GroupDefault, KindUser, "ted"); !allowed {
+		t.Error("request of other user expected: allowed, actual: limited")
+	}
+	if allowed, _ := l.Allow(GroupDefault, KindIP, "192.0.2.1"); !allowed {
+		t.Error("request of unlimited kind expected: allowed, actual: limited")
+	}
+
+	now = now.Add(250 * time.Millisecond)
+	allowed, retry = l.Allow(GroupDefault, KindUser, "bill")
+	if allowed {
+		t.Fatal("request after half a token expected: limited, actual: allowed")
+	}
+	if retry != 250*time.Millisecond {
+		t.Errorf("retry expected: 250ms, actual: %v", retry)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if allowed, _ := l.Allow(GroupDefault, KindUser, "bill"); !allowed {
+		t.Error("request after refill expected: allowed, actual: limited")
+	}
+
+	// ORT limits are separate from the default limits
+	if allowed, _ := l.Allow(GroupORT, KindIP, "192.0.2.1"); !allowed {
+		t.Error("first ort request expected: allowed, actual: limited")
+	}
+	if allowed, _ := l.Allow(GroupORT, KindIP, "192.0.2.1"); allowed {
+		t.Error("second ort request expected: limited, actual: allowed")
+	}
+	// the monitor group has the default limits
+	if allowed, _ := l.Allow(GroupMonitor, KindUser, "bill"); !allowed {
+		t.Error("monitor request expected: allowed, actual: limited")
+	}
+}
+
+func TestStats(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newTestLimiter(&now)
+	for i := 0; i < 5; i++ {
+		l.Allow(GroupDefault, KindUser, "bill")
+	}
+	l.Allow(GroupDefault, KindUser, "ted")
+
+	stats := l.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("stats expected: 3 limits, actual: %+v", stats)
+	}
+	st := stats[0]
+	if st.Group != GroupDefault || st.Kind != KindUser || st.RequestsPerSecond != 2 || st.Burst != 3 {
+		t.Errorf("stats[0] expected: default user 2/s burst 3, actual: %+v", st)
+	}
+	if st.Clients != 2 || st.Allowed != 4 || st.Limited != 2 {
+		t.Errorf("stats[0] expected: 2 clients, 4 allowed, 2 limited, actual: %+v", st)
+	}
+	if len(st.TopLimited) != 1 || st.TopLimited[0].Client != "bill" || st.TopLimited[0].Limited != 2 || st.TopLimited[0].Allowed != 3 {
+		t.Errorf("stats[0] top limited expected: bill with 2 limited, actual: %+v", st.TopLimited)
+	}
+	if stats[1].Group != GroupORT || stats[1].Kind != KindIP || stats[2].Group != GroupMonitor || stats[2].Kind != KindUser {
+		t.Errorf("stats expected: ordered by group and kind, actual: %+v", stats)
+	}
+
+	now = now.Add(time.Hour)
+	l.Allow(GroupORT, KindIP, "192.0.2.1")
+	if len(l.buckets) != 1 {
+		t.Errorf("buckets after idle expected: 1, actual: %v", len(l.buckets))
+	}
+	if stats := l.Stats(); stats[0].Clients != 0 || stats[0].Allowed != 4 {
+		t.Errorf("stats after idle expected: 0 clients and totals kept, actual: %+v", stats[0])
+	}
+}
@@ -47,16 +47,16 @@ func MakeDSSSLKeyKey(dsName, version string) string {
 func GetDeliveryServiceSSLKeysObj(xmlID string, version string, tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint) (tc.DeliveryServiceSSLKeys, bool, error) {
 	key := tc.DeliveryServiceSSLKeys{}
 	found := false
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
 		// get the deliveryservice ssl keys by xmlID and version
-		ro, err := FetchObjectValues(MakeDSSSLKeyKey(xmlID, version), DeliveryServiceSSLKeysBucket, cluster)
+		val, ok, err := ks.Fetch(DeliveryServiceSSLKeysBucket, MakeDSSSLKeyKey(xmlID, version))
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		if err := json.Unmarshal(ro[0].Value, &key); err != nil {
+		if err := json.Unmarshal(val, &key); err != nil {
 			log.Errorf("failed at unmarshaling sslkey response: %s\n", err)
 			return errors.New("unmarshalling Riak result: " + err.Error())
 		}
@@ -74,19 +74,11 @@ func PutDeliveryServiceSSLKeysObj(key tc.DeliveryServiceSSLKeys, tx *sql.Tx, aut
 	if err != nil {
 		return errors.New("marshalling key: " + err.Error())
 	}
-	err = WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		obj := &riak.Object{
-			ContentType:     "application/json",
-			Charset:         "utf-8",
-			ContentEncoding: "utf-8",
-			Key:             MakeDSSSLKeyKey(key.DeliveryService, key.Version.String()),
-			Value:           []byte(keyJSON),
-		}
-		if err := SaveObject(obj, DeliveryServiceSSLKeysBucket, cluster); err != nil {
+	err = WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Save(DeliveryServiceSSLKeysBucket, MakeDSSSLKeyKey(key.DeliveryService, key.Version.String()), keyJSON); err != nil {
 			return errors.New("saving Riak object: " + err.Error())
 		}
-		obj.Key = MakeDSSSLKeyKey(key.DeliveryService, DSSSLKeyVersionLatest)
-		if err := SaveObject(obj, DeliveryServiceSSLKeysBucket, cluster); err != nil {
+		if err := ks.Save(DeliveryServiceSSLKeysBucket, MakeDSSSLKeyKey(key.DeliveryService, DSSSLKeyVersionLatest), keyJSON); err != nil {
 			return errors.New("saving Riak object: " + err.Error())
 		}
 		return nil
@@ -127,15 +119,15 @@ func Ping(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint) (tc.RiakPingRe
 func GetDNSSECKeys(cdnName string, tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint) (tc.DNSSECKeysRiak, bool, error) {
 	key := tc.DNSSECKeysRiak{}
 	found := false
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		ro, err := FetchObjectValues(cdnName, DNSSECKeysBucket, cluster)
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		val, ok, err := ks.Fetch(DNSSECKeysBucket, cdnName)
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		if err := json.Unmarshal(ro[0].Value, &key); err != nil {
+		if err := json.Unmarshal(val, &key); err != nil {
 			return errors.New("unmarshalling Riak dnssec response: " + err.Error())
 		}
 		found = true
@@ -153,15 +145,8 @@ func PutDNSSECKeys(keys tc.DNSSECKeysRiak, cdnName string, tx *sql.Tx, authOpts
 		return errors.New("marshalling keys: " + err.Error())
 	}
 
-	err = WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		obj := &riak.Object{
-			ContentType:     "application/json",
-			Charset:         "utf-8",
-			ContentEncoding: "utf-8",
-			Key:             cdnName,
-			Value:           []byte(keyJSON),
-		}
-		if err = SaveObject(obj, DNSSECKeysBucket, cluster); err != nil {
+	err = WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err = ks.Save(DNSSECKeysBucket, cdnName, keyJSON); err != nil {
 			return errors.New("saving Riak object: " + err.Error())
 		}
 		return nil
@@ -169,19 +154,28 @@ func PutDNSSECKeys(keys tc.DNSSECKeysRiak, cdnName string, tx *sql.Tx, authOpts
 	return err
 }
 
+// DeleteDNSSECKeys deletes the DNSSEC keys of the given CDN.
+func DeleteDNSSECKeys(cdnName string, tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint) error {
+	return WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Delete(DNSSECKeysBucket, cdnName); err != nil {
+			return errors.New("deleting DNSSEC keys: " + err.Error())
+		}
+		return nil
+	})
+}
+
 func GetBucketKey(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, bucket string, key string) ([]byte, bool, error) {
 	val := []byte{}
 	found := false
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		// get the deliveryservice ssl keys by xmlID and version
-		ro, err := FetchObjectValues(key, bucket, cluster)
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		v, ok, err := ks.Fetch(bucket, key)
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		val = ro[0].Value
+		val = v
 		found = true
 		return nil
 	})
@@ -192,8 +186,8 @@ func GetBucketKey(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, bucket
 }
 
 func DeleteDSSSLKeys(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, xmlID string, version string) error {
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		if err := DeleteObject(MakeDSSSLKeyKey(xmlID, version), DeliveryServiceSSLKeysBucket, cluster); err != nil {
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Delete(DeliveryServiceSSLKeysBucket, MakeDSSSLKeyKey(xmlID, version)); err != nil {
 			return errors.New("deleting SSL keys: " + err.Error())
 		}
 		return nil
@@ -205,8 +199,8 @@ func DeleteDSSSLKeys(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, xml
 // This should almost never be used directly, prefer DeleteDSSSLKeys instead.
 // This should only be used to delete keys, which may not conform to the MakeDSSSLKeyKey format. For example when deleting all keys on a delivery service, and some may have been created manually outside Traffic Ops, or are otherwise malformed.
 func DeleteDeliveryServicesSSLKey(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, key string) error {
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		if err := DeleteObject(key, DeliveryServiceSSLKeysBucket, cluster); err != nil {
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Delete(DeliveryServiceSSLKeysBucket, key); err != nil {
 			return errors.New("deleting SSL keys: " + err.Error())
 		}
 		return nil
@@ -224,15 +218,15 @@ func GetURLSigKeys(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, ds tc
 	val := tc.URLSigKeys{}
 	found := false
 	key := GetURLSigConfigFileName(ds)
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		ro, err := FetchObjectValues(key, URLSigKeysBucket, cluster)
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		v, ok, err := ks.Fetch(URLSigKeysBucket, key)
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		if err := json.Unmarshal(ro[0].Value, &val); err != nil {
+		if err := json.Unmarshal(v, &val); err != nil {
 			return errors.New("unmarshalling Riak response: " + err.Error())
 		}
 		found = true
@@ -249,15 +243,8 @@ func PutURLSigKeys(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, ds tc
 	if err != nil {
 		return errors.New("marshalling keys: " + err.Error())
 	}
-	err = WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		obj := &riak.Object{
-			ContentType:     "application/json",
-			Charset:         "utf-8",
-			ContentEncoding: "utf-8",
-			Key:             GetURLSigConfigFileName(ds),
-			Value:           []byte(keyJSON),
-		}
-		if err = SaveObject(obj, URLSigKeysBucket, cluster); err != nil {
+	err = WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err = ks.Save(URLSigKeysBucket, GetURLSigConfigFileName(ds), keyJSON); err != nil {
 			return errors.New("saving Riak object: " + err.Error())
 		}
 		return nil
@@ -270,12 +257,12 @@ const CDNSSLKeysLimit = 1000 // TODO: emulates Perl; reevaluate?
 
 func GetCDNSSLKeysObj(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, cdnName string) ([]tc.CDNSSLKey, error) {
 	keys := []tc.CDNSSLKey{}
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
 		// get the deliveryservice ssl keys by xmlID and version
 		query := `cdn:` + cdnName
-		filterQuery := `_yz_rk:*latest`
+		filterQuery := RiakKeyKeyField + `:*latest`
 		fields := []string{"deliveryservice", "hostname", "certificate.crt", "certificate.key"}
-		searchDocs, err := Search(cluster, SSLKeysIndex, query, filterQuery, CDNSSLKeysLimit, fields)
+		searchDocs, err := ks.Search(SSLKeysIndex, query, filterQuery, CDNSSLKeysLimit, fields)
 		if err != nil {
 			return errors.New("riak search error: " + err.Error())
 		}
@@ -317,12 +304,12 @@ func SearchDocsToCDNSSLKeys(docs []*riak.SearchDoc) []tc.CDNSSLKey {
 // Returns map[tc.DeliveryServiceName][]key
 func GetCDNSSLKeysDSNames(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, cdn tc.CDNName) (map[tc.DeliveryServiceName][]string, error) {
 	dsVersions := map[tc.DeliveryServiceName][]string{}
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
 		// get the deliveryservice ssl keys by xmlID and version
 		query := `cdn:` + string(cdn)
 		filterQuery := ""
-		fields := []string{"_yz_rk", "deliveryservice"} // '_yz_rk' is the magic Riak field that populates the key. Without this, doc.Key would be empty.
-		searchDocs, err := Search(cluster, SSLKeysIndex, query, filterQuery, CDNSSLKeysLimit, fields)
+		fields := []string{RiakKeyKeyField, "deliveryservice"} // '_yz_rk' is the magic Riak field that populates the key. Without this, doc.Key would be empty.
+		searchDocs, err := ks.Search(SSLKeysIndex, query, filterQuery, CDNSSLKeysLimit, fields)
 		if err != nil {
 			return errors.New("riak search error: " + err.Error())
 		}
@@ -355,15 +342,15 @@ func GetCDNSSLKeysDSNames(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint
 func GetURISigningKeysRaw(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, key string) ([]byte, bool, error) {
 	val := []byte(nil)
 	found := false
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		ro, err := FetchObjectValues(key, URISigningKeysBucket, cluster)
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		v, ok, err := ks.Fetch(URISigningKeysBucket, key)
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		val = ro[0].Value
+		val = v
 		found = true
 		return nil
 	})
@@ -373,19 +360,39 @@ func GetURISigningKeysRaw(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint
 	return val, found, nil
 }
 
+// PutURISigningKeysRaw saves the given raw URI Signing keys for the given delivery service.
+func PutURISigningKeysRaw(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, key string, val []byte) error {
+	return WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Save(URISigningKeysBucket, key, val); err != nil {
+			return errors.New("saving Riak object: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// DeleteURISigningKeys deletes the URI Signing keys of the given delivery service.
+func DeleteURISigningKeys(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, key string) error {
+	return WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		if err := ks.Delete(URISigningKeysBucket, key); err != nil {
+			return errors.New("deleting URI Signing keys: " + err.Error())
+		}
+		return nil
+	})
+}
+
 // GetURLSigKeysFromKey gets the URL Sig keys from the raw Riak key, which is the ATS config file name.
 func GetURLSigKeysFromConfigFileKey(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, configFileKey string) (tc.URLSigKeys, bool, error) {
 	val := tc.URLSigKeys{}
 	found := false
-	err := WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
-		ro, err := FetchObjectValues(configFileKey, URLSigKeysBucket, cluster)
+	err := WithKeyStore(tx, authOpts, riakPort, func(ks KeyStore) error {
+		v, ok, err := ks.Fetch(URLSigKeysBucket, configFileKey)
 		if err != nil {
 			return err
 		}
-		if len(ro) == 0 {
+		if !ok {
 			return nil // not found
 		}
-		if err := json.Unmarshal(ro[0].Value, &val); err != nil {
+		if err := json.Unmarshal(v, &val); err != nil {
 			return errors.New("unmarshalling Riak response: " + err.Error())
 		}
 		found = true
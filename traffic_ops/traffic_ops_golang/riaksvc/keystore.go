@@ -0,0 +1,325 @@
+package riaksvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/basho/riak-go-client"
+)
+
+// KeyStore is a store of secret keys, such as SSL certificates, DNSSEC keys, and URL and URI signing keys.
+// Keys are grouped into buckets, as they are in Riak.
+type KeyStore interface {
+	// Fetch returns the value of the given key in the given bucket, and whether it exists.
+	Fetch(bucket string, key string) ([]byte, bool, error)
+	// Save creates or replaces the value of the given key in the given bucket.
+	Save(bucket string, key string, val []byte) error
+	// Delete removes the given key from the given bucket. Deleting a key which doesn't exist is not an error.
+	Delete(bucket string, key string) error
+	// Keys returns the names of all keys in the given bucket.
+	Keys(bucket string) ([]string, error)
+	// Search returns the documents of the given search index matching the Riak Search (Solr) query and filter query.
+	// Returns nil and a nil error if no document was found. If fields is empty, all fields will be returned.
+	Search(index string, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error)
+	// Ping returns an error if the key store can't be reached.
+	Ping() error
+}
+
+// KeyStoreOpener returns the KeyStore to use for the given transaction.
+type KeyStoreOpener func(tx *sql.Tx) (KeyStore, error)
+
+// KeyStoreBuckets is every bucket Traffic Ops stores keys in.
+var KeyStoreBuckets = []string{DeliveryServiceSSLKeysBucket, DNSSECKeysBucket, URLSigKeysBucket, URISigningKeysBucket}
+
+// SearchIndexBuckets is the bucket indexed by each Riak Search index, used by key stores without a search index of their own.
+var SearchIndexBuckets = map[string]string{
+	SSLKeysIndex: DeliveryServiceSSLKeysBucket,
+}
+
+// RiakKeyKeyField is the magic Riak Search field containing the key of the document.
+const RiakKeyKeyField = "_yz_rk"
+
+var (
+	keyStoreOpener KeyStoreOpener
+	keyStoreMutex  sync.RWMutex
+)
+
+// SetKeyStoreOpener sets the KeyStore used by all key functions in this package, instead of the pooled Riak cluster.
+// If opener is nil, the pooled Riak cluster is used.
+func SetKeyStoreOpener(opener KeyStoreOpener) {
+	keyStoreMutex.Lock()
+	defer keyStoreMutex.Unlock()
+	keyStoreOpener = opener
+}
+
+func getKeyStoreOpener() KeyStoreOpener {
+	keyStoreMutex.RLock()
+	defer keyStoreMutex.RUnlock()
+	return keyStoreOpener
+}
+
+// WithKeyStore calls f with the configured KeyStore, or with the pooled Riak cluster if no other KeyStore was set via SetKeyStoreOpener.
+func WithKeyStore(tx *sql.Tx, authOpts *riak.AuthOptions, riakPort *uint, f func(KeyStore) error) error {
+	if opener := getKeyStoreOpener(); opener != nil {
+		ks, err := opener(tx)
+		if err != nil {
+			return errors.New("opening key store: " + err.Error())
+		}
+		return f(ks)
+	}
+	return WithCluster(tx, authOpts, riakPort, func(cluster StorageCluster) error {
+		return f(RiakKeyStore{Cluster: cluster})
+	})
+}
+
+// RiakKeyStore is a KeyStore backed by a Riak cluster.
+type RiakKeyStore struct {
+	Cluster StorageCluster
+}
+
+// Fetch implements KeyStore.
+func (rk RiakKeyStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	ro, err := FetchObjectValues(key, bucket, rk.Cluster)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ro) == 0 {
+		return nil, false, nil
+	}
+	return ro[0].Value, true, nil
+}
+
+// Save implements KeyStore.
+func (rk RiakKeyStore) Save(bucket string, key string, val []byte) error {
+	obj := &riak.Object{
+		ContentType:     "application/json",
+		Charset:         "utf-8",
+		ContentEncoding: "utf-8",
+		Key:             key,
+		Value:           val,
+	}
+	return SaveObject(obj, bucket, rk.Cluster)
+}
+
+// Delete implements KeyStore.
+func (rk RiakKeyStore) Delete(bucket string, key string) error {
+	return DeleteObject(key, bucket, rk.Cluster)
+}
+
+// Keys implements KeyStore.
+// Note listing keys requires traversing every key in the Riak cluster, and should only be used for administrative tasks such as migrations.
+func (rk RiakKeyStore) Keys(bucket string) ([]string, error) {
+	return ListKeys(bucket, rk.Cluster)
+}
+
+// Search implements KeyStore.
+func (rk RiakKeyStore) Search(index string, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error) {
+	return Search(rk.Cluster, index, query, filterQuery, numRows, fields)
+}
+
+// Ping implements KeyStore.
+func (rk RiakKeyStore) Ping() error {
+	return PingCluster(rk.Cluster)
+}
+
+// ListKeys returns all keys in the given Riak bucket.
+func ListKeys(bucket string, cluster StorageCluster) ([]string, error) {
+	if cluster == nil {
+		return nil, errors.New("ERROR: No valid cluster on which to execute a command")
+	}
+	iCmd, err := riak.NewListKeysCommandBuilder().
+		WithBucket(bucket).
+		WithAllowListing().
+		WithStreaming(false).
+		WithTimeout(TimeOut).
+		Build()
+	if err != nil {
+		return nil, errors.New("building Riak command: " + err.Error())
+	}
+	if err := cluster.Execute(iCmd); err != nil {
+		return nil, errors.New("executing Riak list keys command bucket '" + bucket + "': " + err.Error())
+	}
+	cmd, ok := iCmd.(*riak.ListKeysCommand)
+	if !ok {
+		return nil, fmt.Errorf("Riak command unexpected type %T", iCmd)
+	}
+	if cmd.Response == nil {
+		return nil, nil
+	}
+	return cmd.Response.Keys, nil
+}
+
+// CopyKeys copies every key in KeyStoreBuckets from one KeyStore to another, returning the number of keys copied from each bucket.
+// Keys already existing in the destination are overwritten.
+func CopyKeys(from KeyStore, to KeyStore) (map[string]int, error) {
+	copied := map[string]int{}
+	for _, bucket := range KeyStoreBuckets {
+		keys, err := from.Keys(bucket)
+		if err != nil {
+			return copied, errors.New("listing keys in bucket '" + bucket + "': " + err.Error())
+		}
+		for _, key := range keys {
+			val, ok, err := from.Fetch(bucket, key)
+			if err != nil {
+				return copied, errors.New("fetching bucket '" + bucket + "' key '" + key + "': " + err.Error())
+			} else if !ok {
+				continue // deleted since listing
+			}
+			if err := to.Save(bucket, key, val); err != nil {
+				return copied, errors.New("saving bucket '" + bucket + "' key '" + key + "': " + err.Error())
+			}
+			copied[bucket]++
+		}
+	}
+	return copied, nil
+}
+
+// SearchValues emulates Riak Search over the given values, for key stores which don't have a search index.
+// The values must be JSON objects, which are searched as documents whose fields are the dot-separated paths of their scalar values, plus the key itself as the field _yz_rk.
+// Only the simple queries used by Traffic Ops are supported: terms of the form field:value joined by whitespace or AND, where the value may contain * wildcards. Values which aren't JSON objects are ignored.
+// Returns nil if no document matched. If fields is empty, all fields will be returned.
+func SearchValues(vals map[string][]byte, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error) {
+	terms, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, errors.New("parsing query '" + query + "': " + err.Error())
+	}
+	filterTerms, err := parseSearchQuery(filterQuery)
+	if err != nil {
+		return nil, errors.New("parsing filter query '" + filterQuery + "': " + err.Error())
+	}
+	terms = append(terms, filterTerms...)
+
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	docs := []*riak.SearchDoc(nil)
+	for _, key := range keys {
+		if numRows > 0 && len(docs) >= numRows {
+			break
+		}
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(vals[key], &obj); err != nil {
+			continue // not a JSON object, so not indexed
+		}
+		docFields := map[string][]string{RiakKeyKeyField: []string{key}}
+		flattenSearchFields("", obj, docFields)
+		if !searchTermsMatch(terms, docFields) {
+			continue
+		}
+		doc := &riak.SearchDoc{Key: key, Fields: docFields}
+		if len(fields) > 0 {
+			doc.Fields = map[string][]string{}
+			for _, field := range fields {
+				if vals, ok := docFields[field]; ok {
+					doc.Fields[field] = vals
+				}
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+type searchTerm struct {
+	Field string
+	Value string
+}
+
+func parseSearchQuery(query string) ([]searchTerm, error) {
+	terms := []searchTerm{}
+	for _, word := range strings.Fields(query) {
+		if word == "AND" || word == "*:*" {
+			continue
+		}
+		i := strings.Index(word, ":")
+		if i < 1 {
+			return nil, errors.New("unsupported term '" + word + "'")
+		}
+		terms = append(terms, searchTerm{Field: word[:i], Value: strings.Trim(word[i+1:], `"`)})
+	}
+	return terms, nil
+}
+
+func searchTermsMatch(terms []searchTerm, fields map[string][]string) bool {
+	for _, term := range terms {
+		matched := false
+		for _, val := range fields[term.Field] {
+			if wildcardMatch(term.Value, val) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// wildcardMatch returns whether s matches pattern, where * in the pattern matches any sequence of characters.
+func wildcardMatch(pattern string, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+func flattenSearchFields(prefix string, val interface{}, fields map[string][]string) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			flattenSearchFields(name, child, fields)
+		}
+	case []interface{}:
+		for _, child := range v {
+			flattenSearchFields(prefix, child, fields)
+		}
+	case nil:
+	case string:
+		fields[prefix] = append(fields[prefix], v)
+	default:
+		fields[prefix] = append(fields[prefix], fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,171 @@
+package riaksvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/basho/riak-go-client"
+)
+
+func TestSearchValues(t *testing.T) {
+	vals := map[string][]byte{
+		"ds1-latest": []byte(`{"cdn":"cdn1","deliveryservice":"ds1","hostname":"*.ds1.example","certificate":{"crt":"crt1","key":"key1"}}`),
+		"ds1-1":      []byte(`{"cdn":"cdn1","deliveryservice":"ds1","hostname":"*.ds1.example","certificate":{"crt":"crt1","key":"key1"}}`),
+		"ds2-latest": []byte(`{"cdn":"cdn2","deliveryservice":"ds2","hostname":"*.ds2.example","certificate":{"crt":"crt2","key":"key2"}}`),
+		"notjson":    []byte(`not json`),
+	}
+
+	docs, err := SearchValues(vals, "cdn:cdn1", RiakKeyKeyField+":*latest", 100, []string{"deliveryservice", "certificate.crt"})
+	if err != nil {
+		t.Fatalf("SearchValues expected nil error, actual: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("SearchValues expected 1 doc, actual: %+v", docs)
+	}
+	if docs[0].Key != "ds1-latest" {
+		t.Errorf("SearchValues expected key 'ds1-latest', actual: '%v'", docs[0].Key)
+	}
+	expectedFields := map[string][]string{"deliveryservice": []string{"ds1"}, "certificate.crt": []string{"crt1"}}
+	if !reflect.DeepEqual(docs[0].Fields, expectedFields) {
+		t.Errorf("SearchValues expected fields %+v, actual: %+v", expectedFields, docs[0].Fields)
+	}
+
+	docs, err = SearchValues(vals, "cdn:cdn1", "", 100, []string{RiakKeyKeyField})
+	if err != nil {
+		t.Fatalf("SearchValues expected nil error, actual: %v", err)
+	}
+	if len(docs) != 2 || docs[0].Fields[RiakKeyKeyField][0] != "ds1-1" || docs[1].Fields[RiakKeyKeyField][0] != "ds1-latest" {
+		t.Errorf("SearchValues expected keys [ds1-1 ds1-latest], actual: %+v", docs)
+	}
+
+	docs, err = SearchValues(vals, "*:*", "", 1, nil)
+	if err != nil {
+		t.Fatalf("SearchValues expected nil error, actual: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Errorf("SearchValues with numRows 1 expected 1 doc, actual: %+v", docs)
+	}
+
+	docs, err = SearchValues(vals, "cdn:nonexistent", "", 100, nil)
+	if err != nil {
+		t.Fatalf("SearchValues expected nil error, actual: %v", err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("SearchValues expected no docs, actual: %+v", docs)
+	}
+
+	if _, err := SearchValues(vals, "cdn1", "", 100, nil); err == nil {
+		t.Error("SearchValues with a term without a field expected error, actual: nil")
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		Pattern  string
+		S        string
+		Expected bool
+	}{
+		{"ds1-latest", "ds1-latest", true},
+		{"ds1-latest", "ds1-1", false},
+		{"*latest", "ds1-latest", true},
+		{"*latest", "ds1-latest-1", false},
+		{"ds1*", "ds1-latest", true},
+		{"ds*-*st", "ds1-latest", true},
+		{"ab*b", "ab", false},
+		{"*", "", true},
+	}
+	for _, test := range tests {
+		if actual := wildcardMatch(test.Pattern, test.S); actual != test.Expected {
+			t.Errorf("wildcardMatch('%v', '%v') expected %v, actual %v", test.Pattern, test.S, test.Expected, actual)
+		}
+	}
+}
+
+type mapKeyStore map[string]map[string][]byte
+
+func (mk mapKeyStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	val, ok := mk[bucket][key]
+	return val, ok, nil
+}
+
+func (mk mapKeyStore) Save(bucket string, key string, val []byte) error {
+	if mk[bucket] == nil {
+		mk[bucket] = map[string][]byte{}
+	}
+	mk[bucket][key] = val
+	return nil
+}
+
+func (mk mapKeyStore) Delete(bucket string, key string) error {
+	delete(mk[bucket], key)
+	return nil
+}
+
+func (mk mapKeyStore) Keys(bucket string) ([]string, error) {
+	keys := []string{}
+	for key := range mk[bucket] {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (mk mapKeyStore) Search(index string, query string, filterQuery string, numRows int, fields []string) ([]*riak.SearchDoc, error) {
+	return SearchValues(mk[SearchIndexBuckets[index]], query, filterQuery, numRows, fields)
+}
+
+func (mk mapKeyStore) Ping() error {
+	return nil
+}
+
+func TestWithKeyStorePing(t *testing.T) {
+	SetKeyStoreOpener(func(tx *sql.Tx) (KeyStore, error) { return mapKeyStore{}, nil })
+	defer SetKeyStoreOpener(nil)
+
+	// with another key store configured, Riak isn't used, so pinging doesn't need a transaction to find Riak servers
+	if err := WithKeyStore(nil, nil, nil, func(ks KeyStore) error { return ks.Ping() }); err != nil {
+		t.Errorf("WithKeyStore ping expected nil error, actual: %v", err)
+	}
+}
+
+func TestCopyKeys(t *testing.T) {
+	from := mapKeyStore{
+		DeliveryServiceSSLKeysBucket: {"ds1-latest": []byte(`{}`), "ds1-1": []byte(`{}`)},
+		DNSSECKeysBucket:             {"cdn1": []byte(`{}`)},
+		"unknown":                    {"foo": []byte(`bar`)},
+	}
+	to := mapKeyStore{}
+	copied, err := CopyKeys(from, to)
+	if err != nil {
+		t.Fatalf("CopyKeys expected nil error, actual: %v", err)
+	}
+	expected := map[string]int{DeliveryServiceSSLKeysBucket: 2, DNSSECKeysBucket: 1}
+	if !reflect.DeepEqual(copied, expected) {
+		t.Errorf("CopyKeys expected copied %+v, actual: %+v", expected, copied)
+	}
+	if _, ok := to["unknown"]; ok {
+		t.Error("CopyKeys expected unknown bucket to not be copied")
+	}
+	if val, ok, _ := to.Fetch(DNSSECKeysBucket, "cdn1"); !ok || string(val) != `{}` {
+		t.Errorf("CopyKeys expected dnssec key copied, actual: %v %v", ok, string(val))
+	}
+}
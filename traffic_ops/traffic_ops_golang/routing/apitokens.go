@@ -0,0 +1,163 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// routeCDNParam returns the name of the path parameter which identifies the route's CDN, or the empty string if the route isn't for a single CDN.
+func routeCDNParam(routePath string) string {
+	routePath = strings.TrimPrefix(routePath, "^")
+	param := ""
+	switch {
+	case strings.HasPrefix(routePath, "cdns/name/{"):
+		param = strings.TrimPrefix(routePath, "cdns/name/{")
+	case strings.HasPrefix(routePath, "cdns/{"):
+		param = strings.TrimPrefix(routePath, "cdns/{")
+	case strings.Contains(routePath, "{cdn}"):
+		return "cdn"
+	default:
+		return ""
+	}
+	if end := strings.Index(param, "}"); end >= 0 {
+		return param[:end]
+	}
+	return ""
+}
+
+// apiRequestPath returns the request path API token route scopes match: the path after the API version for API routes, or the path without its leading slash for raw routes. Trailing slashes and .json suffixes are removed.
+func apiRequestPath(urlPath string) string {
+	p := strings.TrimPrefix(urlPath, "/")
+	if strings.HasPrefix(p, "api/") {
+		if versionEnd := strings.Index(p[len("api/"):], "/"); versionEnd >= 0 {
+			p = p[len("api/")+versionEnd+1:]
+		}
+	}
+	p = strings.TrimSuffix(p, "/")
+	return strings.TrimSuffix(p, ".json")
+}
+
+// apiTokenRouteAllowed returns whether the request method and path match one of the token's route scopes.
+func apiTokenRouteAllowed(routes []string, method string, requestPath string) bool {
+	for _, route := range routes {
+		fields := strings.Fields(route)
+		if len(fields) != 2 {
+			continue
+		}
+		if fields[0] != "*" && !strings.EqualFold(fields[0], method) {
+			continue
+		}
+		if ok, err := path.Match(fields[1], requestPath); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apiTokenCapabilitiesAllowed returns whether the token's capability scopes include all of the route's capabilities.
+func apiTokenCapabilitiesAllowed(tokenCaps []string, capabilities []string) bool {
+	for _, capability := range capabilities {
+		found := false
+		for _, tokenCap := range tokenCaps {
+			if tokenCap == capability {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// apiTokenAuthorized returns whether the request is within the scopes of the API token the user authenticated with. It returns a user-facing error, a system error, and an error code, if the request isn't allowed.
+func apiTokenAuthorized(r *http.Request, token auth.UserToken, capabilities []string, cdnParam string) (error, error, int) {
+	if token.Capabilities != nil && !apiTokenCapabilitiesAllowed(token.Capabilities, capabilities) {
+		return errors.New("Forbidden: the API token's capabilities don't include this route."), nil, http.StatusForbidden
+	}
+	if token.Routes != nil && !apiTokenRouteAllowed(token.Routes, r.Method, apiRequestPath(r.URL.Path)) {
+		return errors.New("Forbidden: the API token's routes don't include this route."), nil, http.StatusForbidden
+	}
+	if token.CDN == nil {
+		return nil, nil, http.StatusOK
+	}
+	if cdnParam == "" {
+		return errors.New("Forbidden: the API token is restricted to CDN '" + *token.CDN + "', and this route isn't for a single CDN."), nil, http.StatusForbidden
+	}
+	params, err := api.GetPathParams(r.Context())
+	if err != nil {
+		return nil, errors.New("getting path params: " + err.Error()), http.StatusInternalServerError
+	}
+	ok, err := isTokenCDN(r, params[cdnParam], *token.CDN)
+	if err != nil {
+		return nil, errors.New("checking API token CDN: " + err.Error()), http.StatusInternalServerError
+	}
+	if !ok {
+		return errors.New("Forbidden: the API token is restricted to CDN '" + *token.CDN + "'."), nil, http.StatusForbidden
+	}
+	return nil, nil, http.StatusOK
+}
+
+// isTokenCDN returns whether the CDN name or ID from a request path is the token's CDN. If the value is both the name of one CDN and the ID of another, it isn't.
+func isTokenCDN(r *http.Request, nameOrID string, tokenCDN string) (bool, error) {
+	db, ok := r.Context().Value(api.DBContextKey).(*sqlx.DB)
+	if !ok || db == nil {
+		return false, errors.New("request context db missing")
+	}
+	cfg, err := api.GetConfig(r.Context())
+	if err != nil {
+		return false, errors.New("request context config missing")
+	}
+	dbCtx, dbClose := context.WithTimeout(r.Context(), time.Duration(cfg.DBQueryTimeoutSeconds)*time.Second)
+	defer dbClose()
+
+	rows, err := db.QueryContext(dbCtx, `SELECT name FROM cdn WHERE name = $1 OR id::text = $1`, nameOrID)
+	if err != nil {
+		return false, errors.New("querying cdns: " + err.Error())
+	}
+	defer rows.Close()
+	found := false
+	for rows.Next() {
+		name := ""
+		if err := rows.Scan(&name); err != nil {
+			return false, errors.New("scanning cdns: " + err.Error())
+		}
+		if name != tokenCDN {
+			return false, nil
+		}
+		found = true
+	}
+	if err := rows.Err(); err != nil {
+		return false, errors.New("iterating cdns: " + err.Error())
+	}
+	return found, nil
+}
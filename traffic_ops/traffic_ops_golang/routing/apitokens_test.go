@@ -0,0 +1,117 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+func TestRouteCDNParam(t *testing.T) {
+	expected := map[string]string{
+		`cdns/name/{name}/sslkeys/?(\.json)?$`:  "name",
+		`cdns/{id}/queue_update$`:               "id",
+		`cdns/{name}/snapshot/?$`:               "name",
+		`deliveryservices/{xmlid}/urisignkeys$`: "",
+		`cdns/?(\.json)?$`:                      "",
+		`servers/{cdn}/ping$`:                   "cdn",
+	}
+	for routePath, param := range expected {
+		if actual := routeCDNParam(routePath); actual != param {
+			t.Errorf("routeCDNParam(%s) expected '%s', actual '%s'", routePath, param, actual)
+		}
+	}
+}
+
+func TestAPIRequestPath(t *testing.T) {
+	expected := map[string]string{
+		"/api/1.4/cdns/name/foo/sslkeys.json": "cdns/name/foo/sslkeys",
+		"/api/1.4/servers/":                   "servers",
+		"/api/1.1/servers/1/status":           "servers/1/status",
+		"/internal/api/1.2/federations.json":  "internal/api/1.2/federations",
+	}
+	for urlPath, p := range expected {
+		if actual := apiRequestPath(urlPath); actual != p {
+			t.Errorf("apiRequestPath(%s) expected '%s', actual '%s'", urlPath, p, actual)
+		}
+	}
+}
+
+func TestAPITokenRouteAllowed(t *testing.T) {
+	routes := []string{"POST cdns/*/queue_update", "* servers/*/status", "GET servers"}
+	type testCase struct {
+		method  string
+		path    string
+		allowed bool
+	}
+	testCases := []testCase{
+		{http.MethodPost, "cdns/2/queue_update", true},
+		{http.MethodGet, "cdns/2/queue_update", false},
+		{http.MethodPut, "servers/3/status", true},
+		{http.MethodGet, "servers", true},
+		{http.MethodGet, "servers/3", false},
+		{http.MethodPost, "cdns/2/3/queue_update", false},
+	}
+	for _, tc := range testCases {
+		if actual := apiTokenRouteAllowed(routes, tc.method, tc.path); actual != tc.allowed {
+			t.Errorf("apiTokenRouteAllowed(%s %s) expected %v, actual %v", tc.method, tc.path, tc.allowed, actual)
+		}
+	}
+}
+
+func TestAPITokenAuthorized(t *testing.T) {
+	cdn := "cdn1"
+	type testCase struct {
+		name         string
+		token        auth.UserToken
+		method       string
+		path         string
+		capabilities []string
+		cdnParam     string
+		code         int
+	}
+	testCases := []testCase{
+		{"unrestricted", auth.UserToken{}, http.MethodPost, "/api/1.4/servers", []string{"servers-write"}, "", http.StatusOK},
+		{"capability in scope", auth.UserToken{Capabilities: []string{"servers-read", "servers-write"}}, http.MethodPost, "/api/1.4/servers", []string{"servers-write"}, "", http.StatusOK},
+		{"capability out of scope", auth.UserToken{Capabilities: []string{"servers-read"}}, http.MethodPost, "/api/1.4/servers", []string{"servers-write"}, "", http.StatusForbidden},
+		{"empty capability scope", auth.UserToken{Capabilities: []string{}}, http.MethodGet, "/api/1.4/servers", []string{"servers-read"}, "", http.StatusForbidden},
+		{"route in scope", auth.UserToken{Routes: []string{"GET servers"}}, http.MethodGet, "/api/1.4/servers.json", []string{"servers-read"}, "", http.StatusOK},
+		{"route out of scope", auth.UserToken{Routes: []string{"GET servers"}}, http.MethodPost, "/api/1.4/servers", []string{"servers-write"}, "", http.StatusForbidden},
+		{"cdn route for no cdn", auth.UserToken{CDN: &cdn}, http.MethodGet, "/api/1.4/servers", []string{"servers-read"}, "", http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		r, err := http.NewRequest(tc.method, "http://to.example"+tc.path, nil)
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		userErr, sysErr, code := apiTokenAuthorized(r, tc.token, tc.capabilities, tc.cdnParam)
+		if sysErr != nil {
+			t.Errorf("%s: unexpected system error: %v", tc.name, sysErr)
+		}
+		if code != tc.code {
+			t.Errorf("%s: expected code %d, actual %d (%v)", tc.name, tc.code, code, userErr)
+		}
+		if (code == http.StatusOK) != (userErr == nil) {
+			t.Errorf("%s: expected a user error only when forbidden, actual code %d error %v", tc.name, code, userErr)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+// userAuthorized returns whether the user may use a route with the given required privilege level and capabilities.
+//
+// Users whose role has capabilities must have all of the route's capabilities. Users whose role has no capabilities are authorized by privilege level, which is the same as having the DefaultCapabilities of their privilege level, because every capability is only required by routes of a single privilege level. Routes with no capabilities are authorized by privilege level for all users.
+func userAuthorized(user auth.CurrentUser, privLevel int, capabilities []string) bool {
+	if len(capabilities) == 0 || len(user.Capabilities) == 0 {
+		return user.PrivLevel >= privLevel
+	}
+	userCaps := make(map[string]struct{}, len(user.Capabilities))
+	for _, capability := range user.Capabilities {
+		userCaps[capability] = struct{}{}
+	}
+	for _, capability := range capabilities {
+		if _, ok := userCaps[capability]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CapabilityPrivLevels returns the privilege level of the routes which require each capability.
+func CapabilityPrivLevels(routes []Route, rawRoutes []RawRoute) map[string]int {
+	privLevels := map[string]int{}
+	for _, r := range routes {
+		for _, capability := range r.RequiredCapabilities {
+			privLevels[capability] = r.RequiredPrivLevel
+		}
+	}
+	for _, r := range rawRoutes {
+		for _, capability := range r.RequiredCapabilities {
+			privLevels[capability] = r.RequiredPrivLevel
+		}
+	}
+	return privLevels
+}
+
+// DefaultCapabilities returns the capabilities of roles with the given privilege level and no capabilities of their own, sorted by name.
+func DefaultCapabilities(routes []Route, rawRoutes []RawRoute, privLevel int) []string {
+	capabilities := []string{}
+	for capability, capPrivLevel := range CapabilityPrivLevels(routes, rawRoutes) {
+		if privLevel >= capPrivLevel {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// currentUserCapabilitiesHandler returns the handler for the capabilities the current user is authorized for, limited to the scopes of the API token they authenticated with, if any. The routes are pointers, because the handler is itself one of the routes.
+func currentUserCapabilitiesHandler(routes *[]Route, rawRoutes *[]RawRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+		if userErr != nil || sysErr != nil {
+			api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+			return
+		}
+		defer inf.Close()
+
+		caps := tc.CurrentUserCapabilities{PrivLevel: inf.User.PrivLevel}
+		if len(inf.User.Capabilities) == 0 {
+			caps.Default = true
+			caps.Capabilities = DefaultCapabilities(*routes, *rawRoutes, inf.User.PrivLevel)
+		} else {
+			caps.Capabilities = append([]string{}, inf.User.Capabilities...)
+			sort.Strings(caps.Capabilities)
+		}
+		if inf.User.Token != nil && inf.User.Token.Capabilities != nil {
+			scoped := []string{}
+			for _, capability := range caps.Capabilities {
+				if apiTokenCapabilitiesAllowed(inf.User.Token.Capabilities, []string{capability}) {
+					scoped = append(scoped, capability)
+				}
+			}
+			caps.Capabilities = scoped
+		}
+		api.WriteResp(w, r, caps)
+	}
+}
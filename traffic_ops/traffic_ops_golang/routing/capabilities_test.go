@@ -0,0 +1,114 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+// TestRouteCapabilities verifies the invariant userAuthorized relies on: every capability is required only by routes of a single privilege level, so users without capabilities are authorized the same by privilege level and by DefaultCapabilities.
+func TestRouteCapabilities(t *testing.T) {
+	u, err := url.Parse("https://to.test")
+	if err != nil {
+		t.Fatal("error parsing test url")
+	}
+	routes, rawRoutes, _, err := Routes(ServerData{Config: config.Config{URL: u, Secrets: []string{"n0SeCr3t$"}}})
+	if err != nil {
+		t.Fatal("error fetching routes: ", err.Error())
+	}
+
+	privLevels := map[string]int{}
+	check := func(method string, path string, privLevel int, capabilities []string, authenticated bool) {
+		name := method + " " + path
+		if authenticated && privLevel > 0 && len(capabilities) == 0 {
+			t.Errorf("route %s requires privilege level %d but no capabilities", name, privLevel)
+		}
+		if !authenticated && len(capabilities) > 0 {
+			t.Errorf("unauthenticated route %s requires capabilities", name)
+		}
+		for _, capability := range capabilities {
+			if capPrivLevel, ok := privLevels[capability]; ok && capPrivLevel != privLevel {
+				t.Errorf("capability '%s' is required by route %s with privilege level %d, and by other routes with privilege level %d", capability, name, privLevel, capPrivLevel)
+			}
+			privLevels[capability] = privLevel
+		}
+	}
+	for _, r := range routes {
+		check(r.Method, strconv.FormatFloat(r.Version, 'f', -1, 64)+"/"+r.Path, r.RequiredPrivLevel, r.RequiredCapabilities, r.Authenticated)
+	}
+	for _, r := range rawRoutes {
+		check(r.Method, r.Path, r.RequiredPrivLevel, r.RequiredCapabilities, r.Authenticated)
+	}
+}
+
+func TestUserAuthorized(t *testing.T) {
+	readOnly := auth.CurrentUser{PrivLevel: auth.PrivLevelReadOnly}
+	operations := auth.CurrentUser{PrivLevel: auth.PrivLevelOperations}
+	dsEditor := auth.CurrentUser{PrivLevel: auth.PrivLevelReadOnly, Capabilities: []string{"delivery-services-read", "delivery-services-write"}}
+
+	tests := []struct {
+		name         string
+		user         auth.CurrentUser
+		privLevel    int
+		capabilities []string
+		expected     bool
+	}{
+		{"no capabilities, sufficient privilege", operations, auth.PrivLevelOperations, []string{"servers-write"}, true},
+		{"no capabilities, insufficient privilege", readOnly, auth.PrivLevelOperations, []string{"servers-write"}, false},
+		{"capabilities, has capability above privilege", dsEditor, auth.PrivLevelOperations, []string{"delivery-services-write"}, true},
+		{"capabilities, missing capability", dsEditor, auth.PrivLevelOperations, []string{"servers-write"}, false},
+		{"capabilities, missing one of several", dsEditor, auth.PrivLevelReadOnly, []string{"delivery-services-read", "servers-read"}, false},
+		{"route without capabilities", dsEditor, auth.PrivLevelInvalid, nil, true},
+		{"route without capabilities, insufficient privilege", dsEditor, auth.PrivLevelAdmin, nil, false},
+	}
+	for _, test := range tests {
+		if actual := userAuthorized(test.user, test.privLevel, test.capabilities); actual != test.expected {
+			t.Errorf("%s: expected %v, actual %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestDefaultCapabilities(t *testing.T) {
+	routes := []Route{
+		{1.1, "GET", `servers/?$`, nil, auth.PrivLevelReadOnly, []string{"servers-read"}, Authenticated, nil},
+		{1.1, "POST", `servers/?$`, nil, auth.PrivLevelOperations, []string{"servers-write"}, Authenticated, nil},
+		{1.1, "POST", `servercheck/?$`, nil, auth.PrivLevelInvalid, nil, Authenticated, nil},
+	}
+	rawRoutes := []RawRoute{
+		{"GET", `dbdump/?$`, nil, auth.PrivLevelAdmin, []string{"db-dump"}, Authenticated, nil},
+	}
+	expected := map[int][]string{
+		0:                        {},
+		auth.PrivLevelReadOnly:   {"servers-read"},
+		auth.PrivLevelOperations: {"servers-read", "servers-write"},
+		auth.PrivLevelAdmin:      {"db-dump", "servers-read", "servers-write"},
+	}
+	for privLevel, expectedCaps := range expected {
+		if actual := DefaultCapabilities(routes, rawRoutes, privLevel); !reflect.DeepEqual(actual, expectedCaps) {
+			t.Errorf("privilege level %d: expected %v, actual %v", privLevel, expectedCaps, actual)
+		}
+	}
+}
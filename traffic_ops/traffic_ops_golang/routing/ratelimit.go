@@ -0,0 +1,89 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ratelimit"
+)
+
+// rateLimitGroup returns the rate limit group of the given route: the routes ORT uses to fetch config files and check for updates, the routes Traffic Monitor uses to fetch its config, or the default group of all other routes.
+// ORT and Traffic Monitor only read, so routes of other methods, such as taking a snapshot, are always in the default group.
+func rateLimitGroup(method string, routePath string) string {
+	if method != http.MethodGet {
+		return ratelimit.GroupDefault
+	}
+	routePath = strings.TrimPrefix(routePath, "^")
+	switch {
+	case strings.Contains(routePath, "/configfiles/ats/"),
+		strings.HasPrefix(routePath, "servers/{host_name}/update_status"),
+		strings.HasPrefix(routePath, "system/info"):
+		return ratelimit.GroupORT
+	case strings.HasPrefix(routePath, "cdns/{cdn}/configs/monitoring"),
+		routePath == "cdns/{cdn}/snapshot/?$":
+		return ratelimit.GroupMonitor
+	}
+	return ratelimit.GroupDefault
+}
+
+// wrapRateLimit returns middleware which limits the rate of requests of the given group, by the given kind of client. Limited requests get a 429 response, with a Retry-After header.
+// The user kind must follow the auth middleware, which sets the current user. Requests without one aren't limited by user.
+func wrapRateLimit(limiter *ratelimit.Limiter, group string, kind string) Middleware {
+	return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			client := ""
+			if kind == ratelimit.KindUser {
+				user, err := auth.GetCurrentUser(r.Context())
+				if err != nil {
+					handlerFunc(w, r)
+					return
+				}
+				client = user.UserName
+			} else {
+				client = requestIP(r)
+			}
+			allowed, retry := limiter.Allow(group, kind, client)
+			if !allowed {
+				retrySecs := strconv.Itoa(int(math.Ceil(retry.Seconds())))
+				w.Header().Set("Retry-After", retrySecs)
+				api.HandleErr(w, r, nil, http.StatusTooManyRequests, errors.New("too many requests, retry after "+retrySecs+" seconds"), nil)
+				return
+			}
+			handlerFunc(w, r)
+		}
+	}
+}
+
+// requestIP returns the IP address of the client of the given request.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,110 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ratelimit"
+)
+
+func TestRateLimitGroup(t *testing.T) {
+	type route struct {
+		method string
+		path   string
+	}
+	expected := map[route]string{
+		{http.MethodGet, `servers/{server-name-or-id}/configfiles/ats/?(\.json)?$`}:         ratelimit.GroupORT,
+		{http.MethodGet, `profiles/{profile-name-or-id}/configfiles/ats/records.config/?$`}: ratelimit.GroupORT,
+		{http.MethodGet, `servers/{host_name}/update_status$`}:                              ratelimit.GroupORT,
+		{http.MethodGet, `system/info/?(\.json)?$`}:                                         ratelimit.GroupORT,
+		{http.MethodGet, `cdns/{cdn}/configs/monitoring(\.json)?$`}:                         ratelimit.GroupMonitor,
+		{http.MethodGet, `cdns/{cdn}/snapshot/?$`}:                                          ratelimit.GroupMonitor,
+		{http.MethodGet, `cdns/{cdn}/snapshot/history/?$`}:                                  ratelimit.GroupDefault,
+		{http.MethodPost, `cdns/{cdn}/snapshot/history/{id}/rollback/?$`}:                   ratelimit.GroupDefault,
+		{http.MethodPut, `cdns/{id}/snapshot/?$`}:                                           ratelimit.GroupDefault,
+		{http.MethodGet, `servers/?(\.json)?$`}:                                             ratelimit.GroupDefault,
+	}
+	for r, group := range expected {
+		if actual := rateLimitGroup(r.method, r.path); actual != group {
+			t.Errorf("rateLimitGroup(%s, %s) expected '%s', actual '%s'", r.method, r.path, group, actual)
+		}
+	}
+}
+
+func TestWrapRateLimit(t *testing.T) {
+	limiter := ratelimit.New(&config.ConfigRateLimit{
+		Default: config.ConfigRateLimitGroup{
+			User: &config.ConfigRateLimitBucket{RequestsPerSecond: 0.5, Burst: 1},
+			IP:   &config.ConfigRateLimitBucket{RequestsPerSecond: 0.5, Burst: 1},
+		},
+		IdleSeconds: 600,
+	})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}
+	// wrapHeaders writes the status code set by api.HandleErr
+	userHandler := wrapHeaders(wrapRateLimit(limiter, ratelimit.GroupDefault, ratelimit.KindUser)(handler))
+	ipHandler := wrapHeaders(wrapRateLimit(limiter, ratelimit.GroupDefault, ratelimit.KindIP)(handler))
+
+	newReq := func(user string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/1.4/servers", nil)
+		r.RemoteAddr = "192.0.2.1:12345"
+		if user != "" {
+			r = r.WithContext(context.WithValue(r.Context(), auth.CurrentUserKey, auth.CurrentUser{UserName: user}))
+		}
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	userHandler(w, newReq("bill"))
+	if w.Code != http.StatusOK {
+		t.Errorf("first user request expected: %v, actual: %v", http.StatusOK, w.Code)
+	}
+	w = httptest.NewRecorder()
+	userHandler(w, newReq("bill"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("second user request expected: %v, actual: %v", http.StatusTooManyRequests, w.Code)
+	}
+	if retry := w.Header().Get("Retry-After"); retry != "2" {
+		t.Errorf("second user request Retry-After expected: 2, actual: '%s'", retry)
+	}
+	w = httptest.NewRecorder()
+	userHandler(w, newReq(""))
+	if w.Code != http.StatusOK {
+		t.Errorf("request without a user expected: %v, actual: %v", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	ipHandler(w, newReq(""))
+	if w.Code != http.StatusOK {
+		t.Errorf("first ip request expected: %v, actual: %v", http.StatusOK, w.Code)
+	}
+	w = httptest.NewRecorder()
+	ipHandler(w, newReq(""))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("second ip request expected: %v, actual: %v", http.StatusTooManyRequests, w.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+)
+
+// getWritePaths are the paths of the GET routes which write, to the database or to the key store. Their users must read from the primary afterwards, like users of routes with other methods.
+var getWritePaths = []string{
+	`cdns/name/{name}/dnsseckeys/delete`,
+	`cdns/dnsseckeys/refresh`,
+	`deliveryservices/xmlId/{xmlid}/sslkeys/delete`,
+	`tools/write_crconfig/{cdn}`,
+}
+
+// routeWrites returns whether the route with the given method and path may write. Routes which write are all those with methods other than GET and HEAD, and the GET routes in getWritePaths.
+func routeWrites(method string, routePath string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return true
+	}
+	routePath = strings.TrimPrefix(routePath, "^")
+	for _, writePath := range getWritePaths {
+		if strings.HasPrefix(routePath, writePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapRecordWrite returns middleware which records the current user's write with the database replicas, so their reads use the primary until the write has reached the replicas. This includes handlers which don't use an api.APIInfo.
+// It must follow the auth middleware, which sets the current user. Requests without one aren't recorded.
+func wrapRecordWrite() Middleware {
+	return func(handlerFunc http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			handlerFunc(w, r)
+			user, err := auth.GetCurrentUser(r.Context())
+			if err != nil {
+				return
+			}
+			api.GetReplicas(r.Context()).RecordWrite(user.UserName)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package routing
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbreplica"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestRouteWrites(t *testing.T) {
+	type route struct {
+		method string
+		path   string
+	}
+	expected := map[route]bool{
+		{http.MethodGet, `servers/?(\.json)?$`}:                            false,
+		{http.MethodHead, `servers/?(\.json)?$`}:                           false,
+		{http.MethodPost, `servers/?(\.json)?$`}:                           true,
+		{http.MethodPut, `cdns/{id}/snapshot/?$`}:                          true,
+		{http.MethodGet, `cdns/{cdn}/snapshot/?$`}:                         false,
+		{http.MethodGet, `deliveryservices/xmlId/{xmlid}/sslkeys/delete$`}: true,
+		{http.MethodGet, `cdns/name/{name}/dnsseckeys/delete/?(\.json)?$`}: true,
+		{http.MethodGet, `cdns/dnsseckeys/refresh/?(\.json)?$`}:            true,
+		{http.MethodGet, `tools/write_crconfig/{cdn}/?$`}:                  true,
+		{http.MethodGet, `deliveryservices/xmlId/{xmlid}/sslkeys$`}:        false,
+	}
+	for r, writes := range expected {
+		if actual := routeWrites(r.method, r.path); actual != writes {
+			t.Errorf("routeWrites(%s, %s) expected %v, actual %v", r.method, r.path, writes, actual)
+		}
+	}
+}
+
+func TestWrapRecordWrite(t *testing.T) {
+	primaryDB, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primaryDB.Close()
+	replicaDB, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer replicaDB.Close()
+
+	primaryMock.ExpectQuery("SELECT current_setting").WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).AddRow(100005))
+	primaryMock.ExpectQuery("SELECT pg_current_").WillReturnRows(sqlmock.NewRows([]string{"lsn"}).AddRow("0/3000060"))
+	replicaMock.ExpectQuery("SELECT current_setting").WillReturnRows(sqlmock.NewRows([]string{"current_setting"}).AddRow(100005))
+	replicaMock.ExpectQuery("pg_is_in_recovery").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.0))
+
+	replicas := dbreplica.New(sqlx.NewDb(primaryDB, "sqlmock"), []*sqlx.DB{sqlx.NewDb(replicaDB, "sqlmock")}, 5*time.Second, 5*time.Second)
+	replicas.CheckLag()
+	if replicas.DB("bill") == nil {
+		t.Fatal("DB before writing expected: replica, actual: nil")
+	}
+
+	handler := wrapRecordWrite()(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	newReq := func(user string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/1.4/deliveryservices/xmlId/ds1/sslkeys/delete", nil)
+		ctx := context.WithValue(r.Context(), api.ReplicasContextKey, replicas)
+		if user != "" {
+			ctx = context.WithValue(ctx, auth.CurrentUserKey, auth.CurrentUser{UserName: user})
+		}
+		return r.WithContext(ctx)
+	}
+
+	handler(httptest.NewRecorder(), newReq(""))
+	if replicas.DB("bill") == nil {
+		t.Error("DB after a request without a user expected: replica, actual: nil")
+	}
+
+	handler(httptest.NewRecorder(), newReq("bill"))
+	if db := replicas.DB("bill"); db != nil {
+		t.Errorf("DB after writing expected: nil, actual: %v", db)
+	}
+	if replicas.DB("sue") == nil {
+		t.Error("DB of another user after writing expected: replica, actual: nil")
+	}
+}
@@ -32,7 +32,9 @@ import (
 	"github.com/apache/trafficcontrol/lib/go-log"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbreplica"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/plugin"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ratelimit"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -51,8 +53,10 @@ type Route struct {
 	Path              string
 	Handler           http.HandlerFunc
 	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []Middleware
+	// RequiredCapabilities are the capabilities a user's role must have to use the route. Routes which any authenticated user may use have none.
+	RequiredCapabilities []string
+	Authenticated        bool
+	Middlewares          []Middleware
 }
 
 // RawRoute is an HTTP route to be served at the root, rather than under /api/version. Raw Routes should be rare, and almost exclusively converted old Perl routes which have yet to be moved to an API path.
@@ -62,20 +66,31 @@ type RawRoute struct {
 	Path              string
 	Handler           http.HandlerFunc
 	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []Middleware
+	// RequiredCapabilities are the capabilities a user's role must have to use the route. Routes which any authenticated user may use have none.
+	RequiredCapabilities []string
+	Authenticated        bool
+	Middlewares          []Middleware
 }
 
 func getDefaultMiddleware(secret string, requestTimeout time.Duration) []Middleware {
 	return []Middleware{getWrapAccessLog(secret), timeOutWrapper(requestTimeout), wrapHeaders, wrapPanicRecover}
 }
 
+// getStreamMiddleware returns the middleware for streaming responses, which omits the default middleware which buffers the response body.
+func getStreamMiddleware(secret string) []Middleware {
+	return []Middleware{getWrapAccessLog(secret), wrapPanicRecover}
+}
+
 // ServerData ...
 type ServerData struct {
 	config.Config
 	DB        *sqlx.DB
 	Profiling *bool // Yes this is a field in the config but we want to live reload this value and NOT the entire config
 	Plugins   plugin.Plugins
+	// RateLimiter limits the rate of requests. If it's nil, requests aren't limited.
+	RateLimiter *ratelimit.Limiter
+	// Replicas are the read-only replicas of the database. If it's nil, all requests use DB.
+	Replicas *dbreplica.Pool
 }
 
 // CompiledRoute ...
@@ -106,7 +121,7 @@ type PathHandler struct {
 
 // CreateRouteMap returns a map of methods to a slice of paths and handlers; wrapping the handlers in the appropriate middleware. Uses Semantic Versioning: routes are added to every subsequent minor version, but not subsequent major versions. For example, a 1.2 route is added to 1.3 but not 2.1. Also truncates '2.0' to '2', creating succinct major versions.
 // Returns the map of routes, and a map of API versions served.
-func CreateRouteMap(rs []Route, rawRoutes []RawRoute, authBase AuthBase, reqTimeOutSeconds int) (map[string][]PathHandler, map[float64]struct{}) {
+func CreateRouteMap(rs []Route, rawRoutes []RawRoute, authBase AuthBase, reqTimeOutSeconds int, limiter *ratelimit.Limiter) (map[string][]PathHandler, map[float64]struct{}) {
 	// TODO strong types for method, path
 	versions := getSortedRouteVersions(rs)
 	requestTimeout := time.Second * time.Duration(60)
@@ -123,13 +138,13 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, authBase AuthBase, reqTime
 			}
 			vstr := strconv.FormatFloat(version, 'f', -1, 64)
 			path := RoutePrefix + "/" + vstr + "/" + r.Path
-			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+			middlewares := getRouteMiddleware(r.Middlewares, authBase, routeWrites(r.Method, r.Path), r.Authenticated, r.RequiredPrivLevel, r.RequiredCapabilities, routeCDNParam(r.Path), requestTimeout, rateLimitGroup(r.Method, r.Path), limiter)
 			m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: use(r.Handler, middlewares)})
 			log.Infof("adding route %v %v\n", r.Method, path)
 		}
 	}
 	for _, r := range rawRoutes {
-		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel, requestTimeout)
+		middlewares := getRouteMiddleware(r.Middlewares, authBase, routeWrites(r.Method, r.Path), r.Authenticated, r.RequiredPrivLevel, r.RequiredCapabilities, routeCDNParam(r.Path), requestTimeout, rateLimitGroup(r.Method, r.Path), limiter)
 		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: use(r.Handler, middlewares)})
 		log.Infof("adding raw route %v %v\n", r.Method, r.Path)
 	}
@@ -142,13 +157,22 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, authBase AuthBase, reqTime
 	return m, versionSet
 }
 
-func getRouteMiddleware(middlewares []Middleware, authBase AuthBase, authenticated bool, privLevel int, requestTimeout time.Duration) []Middleware {
+func getRouteMiddleware(middlewares []Middleware, authBase AuthBase, writes bool, authenticated bool, privLevel int, capabilities []string, cdnParam string, requestTimeout time.Duration, rateLimitGroup string, limiter *ratelimit.Limiter) []Middleware {
 	if middlewares == nil {
 		middlewares = getDefaultMiddleware(authBase.secret, requestTimeout)
 	}
+	if limiter != nil { // limit by IP before authenticating, so unauthenticated clients can't flood the database
+		middlewares = append(middlewares, wrapRateLimit(limiter, rateLimitGroup, ratelimit.KindIP))
+	}
 	if authenticated { // a privLevel of zero is an unauthenticated endpoint.
-		authWrapper := authBase.GetWrapper(privLevel)
+		authWrapper := authBase.GetWrapper(privLevel, capabilities, cdnParam)
 		middlewares = append(middlewares, authWrapper)
+		if limiter != nil {
+			middlewares = append(middlewares, wrapRateLimit(limiter, rateLimitGroup, ratelimit.KindUser))
+		}
+		if writes {
+			middlewares = append(middlewares, wrapRecordWrite())
+		}
 	}
 	return middlewares
 }
@@ -184,6 +208,7 @@ func Handler(
 	versions map[float64]struct{},
 	catchall http.Handler,
 	db *sqlx.DB,
+	replicas *dbreplica.Pool,
 	cfg *config.Config,
 	getReqID func() uint64,
 	plugins plugin.Plugins,
@@ -201,6 +226,7 @@ func Handler(
 
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, api.DBContextKey, db)
+	ctx = context.WithValue(ctx, api.ReplicasContextKey, replicas)
 	ctx = context.WithValue(ctx, api.ConfigContextKey, cfg)
 	ctx = context.WithValue(ctx, api.ReqIDContextKey, reqID)
 
@@ -276,11 +302,11 @@ func RegisterRoutes(d ServerData) error {
 	}
 
 	authBase := AuthBase{secret: d.Config.Secrets[0], override: nil} //we know d.Config.Secrets is a slice of at least one or start up would fail.
-	routes, versions := CreateRouteMap(routeSlice, rawRoutes, authBase, d.RequestTimeout)
+	routes, versions := CreateRouteMap(routeSlice, rawRoutes, authBase, d.RequestTimeout, d.RateLimiter)
 	compiledRoutes := CompileRoutes(routes)
 	getReqID := nextReqIDGetter()
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		Handler(compiledRoutes, versions, catchall, d.DB, &d.Config, getReqID, d.Plugins, w, r)
+		Handler(compiledRoutes, versions, catchall, d.DB, d.Replicas, &d.Config, getReqID, d.Plugins, w, r)
 	})
 	return nil
 }
@@ -135,7 +135,7 @@ func TestCompileRoutes(t *testing.T) {
 	}
 
 	authBase := AuthBase{secret: d.Secrets[0], override: nil}
-	routes, versions := CreateRouteMap(routeSlice, nil, authBase, 1)
+	routes, versions := CreateRouteMap(routeSlice, nil, authBase, 1, nil)
 	if len(routes) == 0 {
 		t.Error("no routes handler defined")
 	}
@@ -199,13 +199,13 @@ func TestCreateRouteMap(t *testing.T) {
 	}
 
 	routes := []Route{
-		{1.2, http.MethodGet, `path1`, PathOneHandler, auth.PrivLevelReadOnly, true, nil},
-		{1.2, http.MethodGet, `path2`, PathTwoHandler, 0, false, nil},
-		{1.2, http.MethodGet, `path3`, PathThreeHandler, 0, false, []Middleware{}},
+		{1.2, http.MethodGet, `path1`, PathOneHandler, auth.PrivLevelReadOnly, []string{"path1-read"}, true, nil},
+		{1.2, http.MethodGet, `path2`, PathTwoHandler, 0, nil, false, nil},
+		{1.2, http.MethodGet, `path3`, PathThreeHandler, 0, nil, false, []Middleware{}},
 	}
 
 	rawRoutes := []RawRoute{}
-	routeMap, _ := CreateRouteMap(routes, rawRoutes, authBase, 60)
+	routeMap, _ := CreateRouteMap(routes, rawRoutes, authBase, 60, nil)
 
 	route1Handler := routeMap["GET"][0].Handler
 
@@ -48,8 +48,8 @@ type AuthBase struct {
 	override Middleware
 }
 
-// GetWrapper ...
-func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
+// GetWrapper returns the middleware which authenticates the user, and authorizes them for the route's required capabilities. See userAuthorized. Users who authenticated with an API token must also be within its scopes; cdnParam is the path parameter which identifies the route's CDN, if any, for tokens restricted to a CDN.
+func (a AuthBase) GetWrapper(privLevelRequired int, capabilitiesRequired []string, cdnParam string) Middleware {
 	if a.override != nil {
 		return a.override
 	}
@@ -60,10 +60,16 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 				api.HandleErr(w, r, nil, errCode, userErr, sysErr)
 				return
 			}
-			if user.PrivLevel < privLevelRequired {
+			if !userAuthorized(user, privLevelRequired, capabilitiesRequired) {
 				api.HandleErr(w, r, nil, http.StatusForbidden, errors.New("Forbidden."), nil)
 				return
 			}
+			if user.Token != nil {
+				if userErr, sysErr, errCode := apiTokenAuthorized(r, *user.Token, capabilitiesRequired, cdnParam); userErr != nil || sysErr != nil {
+					api.HandleErr(w, r, nil, errCode, userErr, sysErr)
+					return
+				}
+			}
 			api.AddUserToReq(r, user)
 			handlerFunc(w, r)
 		}
@@ -245,6 +251,13 @@ func (i *Interceptor) Header() http.Header {
 	return i.w.Header()
 }
 
+// Flush implements http.Flusher, if the underlying writer does.
+func (i *Interceptor) Flush() {
+	if f, ok := i.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // BodyInterceptor fulfills the Writer interface, but records the body and doesn't actually write. This allows performing operations on the entire body written by a handler, for example, compressing or hashing. To actually write, call `RealWrite()`. Note this means `len(b)` and `nil` are always returned by `Write()`, any real write errors will be returned by `RealWrite()`.
 type BodyInterceptor struct {
 	w    http.ResponseWriter
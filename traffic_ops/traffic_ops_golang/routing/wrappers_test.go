@@ -186,7 +186,7 @@ func TestWrapAuth(t *testing.T) {
 		fmt.Fprintf(w, "%s", respBts)
 	}
 
-	authWrapper := authBase.GetWrapper(15)
+	authWrapper := authBase.GetWrapper(15, nil, "")
 
 	f := authWrapper(handler)
 
@@ -174,7 +174,7 @@ func (server TOServer) ChangeLogMessage(action string) (string, error) {
 func (server *TOServer) Read() ([]interface{}, error, error, int) {
 	returnable := []interface{}{}
 
-	servers, userErr, sysErr, errCode := getServers(server.ReqInfo.Params, server.ReqInfo.Tx, server.ReqInfo.User)
+	servers, userErr, sysErr, errCode := getServers(server.ReqInfo)
 
 	if userErr != nil || sysErr != nil {
 		return nil, userErr, sysErr, errCode
@@ -187,7 +187,8 @@ func (server *TOServer) Read() ([]interface{}, error, error, int) {
 	return returnable, nil, nil, http.StatusOK
 }
 
-func getServers(params map[string]string, tx *sqlx.Tx, user *auth.CurrentUser) ([]tc.ServerNullable, error, error, int) {
+func getServers(inf *api.APIInfo) ([]tc.ServerNullable, error, error, int) {
+	params, tx, user := inf.Params, inf.Tx, inf.User
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToSQLCols := map[string]dbhelpers.WhereColumnInfo{
@@ -226,6 +227,10 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 		}
 		usesMids = dsType.UsesMidCache()
 		log.Debugf("Servers for ds %d; uses mids? %v\n", dsID, usesMids)
+		if usesMids {
+			// the mids are found from the edges, so the servers are paginated by ReadHandler rather than in the database
+			params = api.WithoutPaginationParams(params)
+		}
 	}
 
 	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndPagination(params, queryParamsToSQLCols)
@@ -233,6 +238,12 @@ FULL OUTER JOIN deliveryservice_server dss ON dss.server = s.id
 		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
 	}
 
+	if !usesMids {
+		if err := inf.CountResults(selectQuery()+queryAddition+where, queryValues); err != nil {
+			return nil, nil, errors.New("counting: " + err.Error()), http.StatusInternalServerError
+		}
+	}
+
 	query := selectQuery() + queryAddition + where + orderBy + pagination
 	log.Debugln("Query is ", query)
 
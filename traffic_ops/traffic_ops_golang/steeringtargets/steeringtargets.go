@@ -95,7 +95,8 @@ func (st TOSteeringTargetV11) Validate() error {
 }
 
 func (st *TOSteeringTargetV11) Read() ([]interface{}, error, error, int) {
-	steeringTargets, userErr, sysErr, errCode := read(st.ReqInfo.Tx, st.ReqInfo.Params, st.ReqInfo.User)
+	// targets are filtered by tenant after they're read, so they're paginated by ReadHandler rather than in the database
+	steeringTargets, userErr, sysErr, errCode := read(st.ReqInfo.Tx, api.WithoutPaginationParams(st.ReqInfo.Params), st.ReqInfo.User)
 	if userErr != nil || sysErr != nil {
 		return nil, userErr, sysErr, errCode
 	}
@@ -0,0 +1,320 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/lib/pq"
+)
+
+// Get handles GET requests for topologies, optionally filtered by the name query parameter.
+func Get(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	topologies, err := ReadTopologies(inf.Tx.Tx, inf.Params["name"])
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("reading topologies: "+err.Error()))
+		return
+	}
+	api.WriteResp(w, r, topologies)
+}
+
+// Create handles POST requests to create a topology.
+func Create(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, nil, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	topology, userErr, sysErr, errCode := parseTopology(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if _, err := inf.Tx.Tx.Exec(`INSERT INTO topology (name, description) VALUES ($1, $2)`, topology.Name, topology.Description); err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	writeTopology(w, r, inf, topology, "created")
+}
+
+// Update handles PUT requests to replace the topology with the name query parameter. The topology may be renamed; delivery services assigned to it are kept.
+func Update(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	topology, userErr, sysErr, errCode := parseTopology(inf.Tx.Tx, r)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	result, err := inf.Tx.Tx.Exec(`UPDATE topology SET name = $1, description = $2, last_updated = now() WHERE name = $3`, topology.Name, topology.Description, inf.Params["name"])
+	if err != nil {
+		userErr, sysErr, errCode := api.ParseDBError(err)
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("updating topology: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("topology '"+inf.Params["name"]+"' not found"), nil)
+		return
+	}
+	if _, err := inf.Tx.Tx.Exec(`DELETE FROM topology_cachegroup WHERE topology = $1`, topology.Name); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology nodes: "+err.Error()))
+		return
+	}
+	writeTopology(w, r, inf, topology, "updated")
+}
+
+// Delete handles DELETE requests for the topology with the name query parameter. Topologies can't be deleted while delivery services are assigned to them.
+func Delete(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"name"}, nil)
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+	name := inf.Params["name"]
+	dses := []string{}
+	if err := inf.Tx.Tx.QueryRow(`SELECT COALESCE(ARRAY_AGG(xml_id ORDER BY xml_id), '{}') FROM deliveryservice WHERE topology = $1`, name).Scan(pq.Array(&dses)); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting topology delivery services: "+err.Error()))
+		return
+	}
+	if len(dses) > 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusBadRequest, errors.New("topology '"+name+"' is assigned to delivery services: "+strings.Join(dses, ", ")), nil)
+		return
+	}
+	result, err := inf.Tx.Tx.Exec(`DELETE FROM topology WHERE name = $1`, name)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology: "+err.Error()))
+		return
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting topology: getting rows affected: "+err.Error()))
+		return
+	} else if rows == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("topology '"+name+"' not found"), nil)
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "TOPOLOGY: "+name+", ACTION: Deleted topology", inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, "topology was deleted.")
+}
+
+// parseTopology decodes and validates the topology in the request body, including that its cache groups exist and can be used in the positions they're in.
+func parseTopology(tx *sql.Tx, r *http.Request) (tc.Topology, error, error, int) {
+	topology := tc.Topology{}
+	if err := json.NewDecoder(r.Body).Decode(&topology); err != nil {
+		return tc.Topology{}, errors.New("decoding: " + err.Error()), nil, http.StatusBadRequest
+	}
+	if err := topology.Validate(); err != nil {
+		return tc.Topology{}, errors.New("validating: " + err.Error()), nil, http.StatusBadRequest
+	}
+	cgTypes, err := getCacheGroupTypes(tx, topology)
+	if err != nil {
+		return tc.Topology{}, nil, errors.New("getting topology cachegroup types: " + err.Error()), http.StatusInternalServerError
+	}
+	if err := checkCacheGroups(topology, cgTypes); err != nil {
+		return tc.Topology{}, errors.New("validating: " + err.Error()), nil, http.StatusBadRequest
+	}
+	return topology, nil, nil, http.StatusOK
+}
+
+// getCacheGroupTypes returns the type names of the topology's cache groups which exist.
+func getCacheGroupTypes(tx *sql.Tx, topology tc.Topology) (map[string]string, error) {
+	names := []string{}
+	for _, node := range topology.Nodes {
+		names = append(names, node.CacheGroup)
+	}
+	rows, err := tx.Query(`SELECT cg.name, t.name FROM cachegroup AS cg JOIN type AS t ON t.id = cg.type WHERE cg.name = ANY($1)`, pq.Array(names))
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	types := map[string]string{}
+	for rows.Next() {
+		name := ""
+		typeName := ""
+		if err := rows.Scan(&name, &typeName); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		types[name] = typeName
+	}
+	return types, rows.Err()
+}
+
+// checkCacheGroups returns an error if any of the topology's cache groups don't exist, are origin cache groups, or are edge cache groups which are the parent of another node.
+func checkCacheGroups(topology tc.Topology, cgTypes map[string]string) error {
+	errs := []string{}
+	for _, node := range topology.Nodes {
+		cgType, ok := cgTypes[node.CacheGroup]
+		if !ok {
+			errs = append(errs, "cachegroup '"+node.CacheGroup+"' not found")
+			continue
+		}
+		if cgType == tc.CacheGroupOriginTypeName {
+			errs = append(errs, "cachegroup '"+node.CacheGroup+"' is an origin cachegroup; topologies may only contain cache cachegroups, their top tier goes to the origin")
+		}
+	}
+	for _, node := range topology.Nodes {
+		for _, parent := range node.Parents {
+			parentCG := topology.Nodes[parent].CacheGroup
+			if cgTypes[parentCG] == tc.CacheGroupEdgeTypeName {
+				errs = append(errs, "cachegroup '"+parentCG+"' is an edge cachegroup, and can't be the parent of '"+node.CacheGroup+"'")
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, ", "))
+}
+
+// writeTopology inserts the topology's nodes and parents, which must not exist, and writes the resulting topology with a success alert.
+func writeTopology(w http.ResponseWriter, r *http.Request, inf *api.APIInfo, topology tc.Topology, action string) {
+	tx := inf.Tx.Tx
+	if err := insertNodes(tx, topology); err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("inserting topology nodes: "+err.Error()))
+		return
+	}
+	topologies, err := ReadTopologies(tx, topology.Name)
+	if err != nil {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("reading "+action+" topology: "+err.Error()))
+		return
+	}
+	if len(topologies) != 1 {
+		api.HandleErr(w, r, tx, http.StatusInternalServerError, nil, errors.New("reading "+action+" topology: not found"))
+		return
+	}
+	api.CreateChangeLogRawTx(api.ApiChange, "TOPOLOGY: "+topology.Name+", ACTION: "+strings.Title(action)+" topology", inf.User, tx)
+	api.WriteRespAlertObj(w, r, tc.SuccessLevel, "topology was "+action+".", topologies[0])
+}
+
+func insertNodes(tx *sql.Tx, topology tc.Topology) error {
+	ids := make([]int64, len(topology.Nodes))
+	for i, node := range topology.Nodes {
+		if err := tx.QueryRow(`INSERT INTO topology_cachegroup (topology, cachegroup) VALUES ($1, $2) RETURNING id`, topology.Name, node.CacheGroup).Scan(&ids[i]); err != nil {
+			return errors.New("inserting node '" + node.CacheGroup + "': " + err.Error())
+		}
+	}
+	for i, node := range topology.Nodes {
+		for rank, parent := range node.Parents {
+			if _, err := tx.Exec(`INSERT INTO topology_cachegroup_parents (child, parent, rank) VALUES ($1, $2, $3)`, ids[i], ids[parent], rank+1); err != nil {
+				return errors.New("inserting node '" + node.CacheGroup + "' parents: " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// ReadTopologies returns the topologies, sorted by name, or only the named topology if name isn't empty. Nodes are in the order they were created, and parents are in rank order.
+func ReadTopologies(tx *sql.Tx, name string) ([]tc.Topology, error) {
+	rows, err := tx.Query(`
+SELECT t.name, t.description, t.last_updated, tcg.id, tcg.cachegroup
+FROM topology AS t
+LEFT JOIN topology_cachegroup AS tcg ON tcg.topology = t.name
+WHERE $1 = '' OR t.name = $1
+ORDER BY t.name, tcg.id
+`, name)
+	if err != nil {
+		return nil, errors.New("querying topologies: " + err.Error())
+	}
+	defer rows.Close()
+
+	topologies := []tc.Topology{}
+	type nodePosition struct {
+		topology int
+		node     int
+	}
+	nodes := map[int64]nodePosition{}
+	for rows.Next() {
+		t := tc.Topology{}
+		nodeID := sql.NullInt64{}
+		cacheGroup := sql.NullString{}
+		if err := rows.Scan(&t.Name, &t.Description, &t.LastUpdated, &nodeID, &cacheGroup); err != nil {
+			return nil, errors.New("scanning topologies: " + err.Error())
+		}
+		if len(topologies) == 0 || topologies[len(topologies)-1].Name != t.Name {
+			t.Nodes = []tc.TopologyNode{}
+			topologies = append(topologies, t)
+		}
+		if !nodeID.Valid {
+			continue
+		}
+		last := &topologies[len(topologies)-1]
+		nodes[nodeID.Int64] = nodePosition{topology: len(topologies) - 1, node: len(last.Nodes)}
+		last.Nodes = append(last.Nodes, tc.TopologyNode{CacheGroup: cacheGroup.String, Parents: []int{}})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("reading topologies: " + err.Error())
+	}
+
+	parentRows, err := tx.Query(`
+SELECT p.child, p.parent, p.rank
+FROM topology_cachegroup_parents AS p
+JOIN topology_cachegroup AS tcg ON tcg.id = p.child
+WHERE $1 = '' OR tcg.topology = $1
+ORDER BY p.child, p.rank
+`, name)
+	if err != nil {
+		return nil, errors.New("querying topology parents: " + err.Error())
+	}
+	defer parentRows.Close()
+	for parentRows.Next() {
+		child := int64(0)
+		parent := int64(0)
+		rank := 0
+		if err := parentRows.Scan(&child, &parent, &rank); err != nil {
+			return nil, errors.New("scanning topology parents: " + err.Error())
+		}
+		childPos, ok := nodes[child]
+		if !ok {
+			continue
+		}
+		parentPos, ok := nodes[parent]
+		if !ok || parentPos.topology != childPos.topology {
+			return nil, errors.New("topology node parent is not in the same topology")
+		}
+		node := &topologies[childPos.topology].Nodes[childPos.node]
+		node.Parents = append(node.Parents, parentPos.node)
+	}
+	if err := parentRows.Err(); err != nil {
+		return nil, errors.New("reading topology parents: " + err.Error())
+	}
+	return topologies, nil
+}
@@ -0,0 +1,117 @@
+package topology
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCheckCacheGroups(t *testing.T) {
+	topology := tc.Topology{
+		Name: "three-tier",
+		Nodes: []tc.TopologyNode{
+			{CacheGroup: "edge", Parents: []int{1}},
+			{CacheGroup: "mid", Parents: []int{2}},
+			{CacheGroup: "top"},
+		},
+	}
+	cgTypes := map[string]string{"edge": "EDGE_LOC", "mid": "MID_LOC", "top": "MID_LOC"}
+	if err := checkCacheGroups(topology, cgTypes); err != nil {
+		t.Errorf("checkCacheGroups expected nil error, actual: %v", err)
+	}
+
+	invalids := map[string]map[string]string{
+		"'top' not found":               {"edge": "EDGE_LOC", "mid": "MID_LOC"},
+		"origin cachegroup":             {"edge": "EDGE_LOC", "mid": "MID_LOC", "top": tc.CacheGroupOriginTypeName},
+		"can't be the parent of 'edge'": {"edge": "EDGE_LOC", "mid": "EDGE_LOC", "top": "MID_LOC"},
+	}
+	for expected, cgTypes := range invalids {
+		err := checkCacheGroups(topology, cgTypes)
+		if err == nil {
+			t.Errorf("checkCacheGroups expected error containing '%v', actual: nil", expected)
+		} else if !strings.Contains(err.Error(), expected) {
+			t.Errorf("checkCacheGroups expected error containing '%v', actual: %v", expected, err)
+		}
+	}
+}
+
+func TestReadTopologies(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	rows := sqlmock.NewRows([]string{"name", "description", "last_updated", "id", "cachegroup"})
+	rows = rows.AddRow("edge-only", "", time.Now(), 1, "edge")
+	rows = rows.AddRow("empty", "no nodes", time.Now(), nil, nil)
+	rows = rows.AddRow("three-tier", "", time.Now(), 2, "edge")
+	rows = rows.AddRow("three-tier", "", time.Now(), 3, "mid1")
+	rows = rows.AddRow("three-tier", "", time.Now(), 4, "mid2")
+	rows = rows.AddRow("three-tier", "", time.Now(), 5, "top")
+	parentRows := sqlmock.NewRows([]string{"child", "parent", "rank"})
+	parentRows = parentRows.AddRow(2, 3, 1)
+	parentRows = parentRows.AddRow(2, 4, 2)
+	parentRows = parentRows.AddRow(3, 5, 1)
+	parentRows = parentRows.AddRow(4, 5, 1)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT").WithArgs("").WillReturnRows(parentRows)
+	mock.ExpectCommit()
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	topologies, err := ReadTopologies(tx, "")
+	if err != nil {
+		t.Fatalf("ReadTopologies expected nil error, actual: %v", err)
+	}
+	tx.Commit()
+
+	if len(topologies) != 3 {
+		t.Fatalf("ReadTopologies expected 3 topologies, actual: %+v", topologies)
+	}
+	if topologies[0].Name != "edge-only" || !reflect.DeepEqual(topologies[0].Nodes, []tc.TopologyNode{{CacheGroup: "edge", Parents: []int{}}}) {
+		t.Errorf("ReadTopologies expected edge-only topology, actual: %+v", topologies[0])
+	}
+	if topologies[1].Name != "empty" || len(topologies[1].Nodes) != 0 || topologies[1].Nodes == nil {
+		t.Errorf("ReadTopologies expected empty topology with empty nodes, actual: %+v", topologies[1])
+	}
+	expectedNodes := []tc.TopologyNode{
+		{CacheGroup: "edge", Parents: []int{1, 2}},
+		{CacheGroup: "mid1", Parents: []int{3}},
+		{CacheGroup: "mid2", Parents: []int{3}},
+		{CacheGroup: "top", Parents: []int{}},
+	}
+	if topologies[2].Name != "three-tier" || !reflect.DeepEqual(topologies[2].Nodes, expectedNodes) {
+		t.Errorf("ReadTopologies expected three-tier nodes %+v, actual: %+v", expectedNodes, topologies[2].Nodes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -21,6 +21,7 @@ package main
 
 import (
 	"crypto/tls"
+	"database/sql"
 	"flag"
 	"fmt"
 	"net/http"
@@ -37,8 +38,15 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/about"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbreplica"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/acmecert"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/keystore"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/maintenancewindow"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/plugin"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/ratelimit"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/routing"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/webhook"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -58,6 +66,7 @@ func main() {
 	configFileName := flag.String("cfg", "", "The config file path")
 	dbConfigFileName := flag.String("dbcfg", "", "The db config file path")
 	riakConfigFileName := flag.String("riakcfg", "", "The riak config file path")
+	migrateKeys := flag.Bool("migratekeys", false, "Copy all keys from Riak into the keystore configured in the cfg file, and exit")
 	flag.Parse()
 
 	if *showVersion {
@@ -116,6 +125,39 @@ func main() {
 	db.SetMaxIdleConns(cfg.DBMaxIdleConnections)
 	db.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeSeconds) * time.Second)
 
+	replicas, err := dbreplica.Open(&cfg, db)
+	if err != nil {
+		log.Errorf("opening database replicas: %v\n", err)
+		os.Exit(1)
+	}
+	defer replicas.Close()
+
+	if *migrateKeys {
+		if err := migrateRiakKeys(db.DB, cfg); err != nil {
+			log.Errorf("migrating keys from riak: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Migrating keys from Riak: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if err := keystore.Init(cfg); err != nil {
+		log.Errorf("initializing keystore: %v\n", err)
+		os.Exit(1)
+	}
+
+	go webhook.NewDispatcher(db.DB, *cfg.Webhooks).Run()
+
+	if cfg.ACME != nil {
+		go acmecert.NewRenewer(db.DB, cfg).Run()
+	}
+
+	go maintenancewindow.NewScheduler(db.DB, cfg).Run()
+
+	if replicas != nil {
+		go replicas.Run()
+	}
+
 	// TODO combine
 	plugins := plugin.Get(cfg)
 	profiling := cfg.ProfilingEnabled
@@ -133,7 +175,7 @@ func main() {
 		log.Errorln(debugServer.ListenAndServe())
 	}()
 
-	if err := routing.RegisterRoutes(routing.ServerData{DB: db, Config: cfg, Profiling: &profiling, Plugins: plugins}); err != nil {
+	if err := routing.RegisterRoutes(routing.ServerData{DB: db, Config: cfg, Profiling: &profiling, Plugins: plugins, RateLimiter: ratelimit.New(cfg.RateLimit), Replicas: replicas}); err != nil {
 		log.Errorf("registering routes: %v\n", err)
 		os.Exit(1)
 	}
@@ -283,6 +325,27 @@ func continuousProfile(profiling *bool, profilingDir *string, version string) {
 	}
 }
 
+// migrateRiakKeys copies all keys from Riak into the keystore configured in cdn.conf, and prints the number of keys copied.
+func migrateRiakKeys(db *sql.DB, cfg config.Config) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %v", err)
+	}
+	copied, err := keystore.MigrateFromRiak(tx, cfg)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %v", err)
+	}
+	for _, bucket := range riaksvc.KeyStoreBuckets {
+		fmt.Printf("Copied %d keys from Riak bucket '%s'\n", copied[bucket], bucket)
+		log.Infof("copied %d keys from riak bucket '%s'\n", copied[bucket], bucket)
+	}
+	return nil
+}
+
 func signalReloader(sig os.Signal, f func()) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, sig)
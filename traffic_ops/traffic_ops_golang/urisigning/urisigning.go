@@ -34,7 +34,6 @@ import (
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
 	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 
-	"github.com/basho/riak-go-client"
 	"github.com/lestrrat/go-jwx/jwk"
 )
 
@@ -56,7 +55,7 @@ func GetURIsignkeysHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("The RIAK service is unavailable"), errors.New("getting Riak SSL keys by host name: riak is not configured"))
 		return
 	}
@@ -68,23 +67,17 @@ func GetURIsignkeysHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cluster, err := riaksvc.GetPooledCluster(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort)
-	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("starting riak cluster: "+err.Error()))
-		return
-	}
-
-	ro, err := riaksvc.FetchObjectValues(xmlID, CDNURIKeysBucket, cluster)
+	val, ok, err := riaksvc.GetURISigningKeysRaw(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, xmlID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("fetching riak objects: "+err.Error()))
 		return
 	}
-	if len(ro) == 0 {
+	if !ok {
 		api.WriteRespRaw(w, r, URISignerKeyset{})
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write(ro[0].Value)
+	w.Write(val)
 }
 
 // removeDeliveryServiceURIKeysHandler is the HTTP DELETE handler used to remove urisigning keys assigned to a delivery service.
@@ -96,7 +89,7 @@ func RemoveDeliveryServiceURIKeysHandler(w http.ResponseWriter, r *http.Request)
 	}
 	defer inf.Close()
 
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("The RIAK service is unavailable"), errors.New("getting Riak SSL keys by host name: riak is not configured"))
 		return
 	}
@@ -115,23 +108,17 @@ func RemoveDeliveryServiceURIKeysHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	cluster, err := riaksvc.GetPooledCluster(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort)
-	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("starting riak cluster: "+err.Error()))
-		return
-	}
-
-	ro, err := riaksvc.FetchObjectValues(xmlID, CDNURIKeysBucket, cluster)
+	val, ok, err := riaksvc.GetURISigningKeysRaw(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, xmlID)
 	if err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("fetching riak objects: "+err.Error()))
 		return
 	}
 
-	if len(ro) == 0 || ro[0].Value == nil {
+	if !ok || val == nil {
 		api.WriteRespAlert(w, r, tc.InfoLevel, "not deleted, no object found to delete")
 		return
 	}
-	if err := riaksvc.DeleteObject(xmlID, CDNURIKeysBucket, cluster); err != nil {
+	if err := riaksvc.DeleteURISigningKeys(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, xmlID); err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("deleting riak object: "+err.Error()))
 		return
 	}
@@ -149,7 +136,7 @@ func SaveDeliveryServiceURIKeysHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer inf.Close()
 
-	if inf.Config.RiakEnabled == false {
+	if !inf.Config.KeyStoreEnabled {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusServiceUnavailable, errors.New("The RIAK service is unavailable"), errors.New("getting Riak SSL keys by host name: riak is not configured"))
 		return
 	}
@@ -183,21 +170,7 @@ func SaveDeliveryServiceURIKeysHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cluster, err := riaksvc.GetPooledCluster(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort)
-	if err != nil {
-		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("starting riak cluster: "+err.Error()))
-		return
-	}
-
-	obj := &riak.Object{
-		ContentType:     "text/json",
-		Charset:         "utf-8",
-		ContentEncoding: "utf-8",
-		Key:             xmlID,
-		Value:           []byte(data),
-	}
-
-	if err = riaksvc.SaveObject(obj, CDNURIKeysBucket, cluster); err != nil {
+	if err := riaksvc.PutURISigningKeysRaw(inf.Tx.Tx, inf.Config.RiakAuthOptions, inf.Config.RiakPort, xmlID, data); err != nil {
 		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("saving riak object: "+err.Error()))
 		return
 	}
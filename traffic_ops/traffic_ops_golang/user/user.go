@@ -196,6 +196,9 @@ func (this *TOUser) Read() ([]interface{}, error, error, int) {
 	}
 	where, queryValues = dbhelpers.AddTenancyCheck(where, queryValues, "u.tenant_id", tenantIDs)
 
+	if err := inf.CountResults(this.SelectQuery()+where, queryValues); err != nil {
+		return nil, nil, fmt.Errorf("counting users: %v", err), http.StatusInternalServerError
+	}
 	query := this.SelectQuery() + where + orderBy + pagination
 	rows, err := inf.Tx.NamedQuery(query, queryValues)
 	if err != nil {
@@ -0,0 +1,174 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+// TODelivery is the api.Reader of the deliveries of a webhook.
+type TODelivery struct {
+	api.APIInfoImpl `json:"-"`
+}
+
+func (d *TODelivery) Read() ([]interface{}, error, error, int) {
+	inf := d.APIInfo()
+	if status, ok := inf.Params["status"]; ok {
+		switch tc.WebhookDeliveryStatus(status) {
+		case tc.WebhookDeliveryPending, tc.WebhookDeliveryDelivered, tc.WebhookDeliveryDead:
+		default:
+			return nil, errors.New("status must be one of: pending, delivered, dead"), nil, http.StatusBadRequest
+		}
+	}
+	cols := map[string]dbhelpers.WhereColumnInfo{
+		"id":     dbhelpers.WhereColumnInfo{Column: "d.webhook", Checker: api.IsInt},
+		"status": dbhelpers.WhereColumnInfo{Column: "d.status", Checker: nil},
+		"logId":  dbhelpers.WhereColumnInfo{Column: "d.log", Checker: api.IsInt},
+	}
+	// the "id" parameter is the webhook of the path, so cursor pages are by the delivery ID
+	where, orderBy, pagination, queryValues, errs := dbhelpers.BuildWhereAndOrderByAndCursorPagination(inf.Params, cols, "d.id")
+	if len(errs) > 0 {
+		return nil, util.JoinErrs(errs), nil, http.StatusBadRequest
+	}
+	if orderBy == "" {
+		orderBy = "\nORDER BY d.id"
+	}
+
+	webhookID, err := strconv.Atoi(inf.Params["id"])
+	if err != nil {
+		return nil, errors.New("id must be an integer"), nil, http.StatusBadRequest
+	}
+	if _, ok, err := getWebhookName(inf.Tx.Tx, webhookID); err != nil {
+		return nil, nil, errors.New("getting webhook: " + err.Error()), http.StatusInternalServerError
+	} else if !ok {
+		return nil, errors.New("webhook not found"), nil, http.StatusNotFound
+	}
+
+	if err := inf.CountResults(selectDeliveriesQuery()+where, queryValues); err != nil {
+		return nil, nil, errors.New("counting webhook deliveries: " + err.Error()), http.StatusInternalServerError
+	}
+	rows, err := inf.Tx.NamedQuery(selectDeliveriesQuery()+where+orderBy+pagination, queryValues)
+	if err != nil {
+		return nil, nil, errors.New("querying webhook deliveries: " + err.Error()), http.StatusInternalServerError
+	}
+	defer rows.Close()
+	deliveries := []interface{}{}
+	for rows.Next() {
+		delivery := tc.WebhookDelivery{}
+		if err := rows.StructScan(&delivery); err != nil {
+			return nil, nil, errors.New("scanning webhook deliveries: " + err.Error()), http.StatusInternalServerError
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil, nil, http.StatusOK
+}
+
+func selectDeliveriesQuery() string {
+	return `SELECT
+d.id,
+d.webhook,
+d.log,
+d.status,
+d.attempts,
+CASE WHEN d.status = 'pending' THEN d.next_attempt END AS next_attempt,
+d.last_error,
+d.payload,
+d.last_updated
+
+FROM webhook_delivery d`
+}
+
+// Retry re-queues the dead deliveries of a webhook, or with the deliveryId parameter, a single delivery of any status, to be attempted again immediately, as if they were new.
+func Retry(w http.ResponseWriter, r *http.Request) {
+	inf, userErr, sysErr, errCode := api.NewInfo(r, []string{"id"}, []string{"id", "deliveryId"})
+	if userErr != nil || sysErr != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, errCode, userErr, sysErr)
+		return
+	}
+	defer inf.Close()
+
+	webhookID := inf.IntParams["id"]
+	name, ok, err := getWebhookName(inf.Tx.Tx, webhookID)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("getting webhook: "+err.Error()))
+		return
+	} else if !ok {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("webhook not found"), nil)
+		return
+	}
+
+	deliveryID, oneDelivery := inf.IntParams["deliveryId"]
+	count, err := retryDeliveries(inf.Tx.Tx, webhookID, deliveryID, oneDelivery)
+	if err != nil {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusInternalServerError, nil, errors.New("retrying webhook deliveries: "+err.Error()))
+		return
+	}
+	if oneDelivery && count == 0 {
+		api.HandleErr(w, r, inf.Tx.Tx, http.StatusNotFound, errors.New("delivery not found"), nil)
+		return
+	}
+
+	msg := strconv.FormatInt(count, 10) + " deliveries of webhook " + name + " re-queued"
+	api.CreateChangeLogRawTx(api.ApiChange, "WEBHOOK: "+name+", ID: "+strconv.Itoa(webhookID)+", ACTION: "+msg, inf.User, inf.Tx.Tx)
+	api.WriteRespAlert(w, r, tc.SuccessLevel, msg)
+}
+
+func retryDeliveries(tx *sql.Tx, webhookID int, deliveryID int, oneDelivery bool) (int64, error) {
+	qry := `
+UPDATE webhook_delivery SET status = 'pending', attempts = 0, next_attempt = now(), last_error = NULL, last_updated = now()
+WHERE webhook = $1
+`
+	args := []interface{}{webhookID}
+	if oneDelivery {
+		qry += `AND id = $2`
+		args = append(args, deliveryID)
+	} else {
+		qry += `AND status = 'dead'`
+	}
+	result, err := tx.Exec(qry, args...)
+	if err != nil {
+		return 0, errors.New("updating deliveries: " + err.Error())
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New("getting rows affected: " + err.Error())
+	}
+	return count, nil
+}
+
+// getWebhookName returns the name of the webhook with the given ID, and whether it exists.
+func getWebhookName(tx *sql.Tx, id int) (string, bool, error) {
+	name := ""
+	if err := tx.QueryRow(`SELECT name FROM webhook WHERE id = $1`, id).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, errors.New("querying webhook name: " + err.Error())
+	}
+	return name, true, nil
+}
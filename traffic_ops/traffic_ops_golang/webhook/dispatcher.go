@@ -0,0 +1,219 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-log"
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+// MaxRetryInterval is the longest time between attempts of a failed delivery.
+const MaxRetryInterval = time.Hour
+
+// DeliveryBatchSize is the maximum number of deliveries attempted concurrently.
+const DeliveryBatchSize = 100
+
+// MaxErrorBodyLen is the maximum number of bytes of a failed delivery's response body recorded in its last error.
+const MaxErrorBodyLen = 512
+
+// PurgeInterval is how often successful deliveries older than the configured retention are deleted.
+const PurgeInterval = time.Hour
+
+// Dispatcher delivers the change log entries queued for webhooks by api.CreateChangeLog.
+// Deliveries are claimed from the database, so any number of Traffic Ops instances may run a Dispatcher; each delivery is attempted by only one of them at a time.
+type Dispatcher struct {
+	DB     *sql.DB
+	Client *http.Client
+	Cfg    config.ConfigWebhooks
+}
+
+// NewDispatcher creates a Dispatcher from the given config.
+func NewDispatcher(db *sql.DB, cfg config.ConfigWebhooks) *Dispatcher {
+	return &Dispatcher{
+		DB: db,
+		Client: &http.Client{
+			Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.Insecure}},
+		},
+		Cfg: cfg,
+	}
+}
+
+// Run delivers queued change log entries forever. It should be called in its own goroutine.
+func (d *Dispatcher) Run() {
+	interval := time.Duration(d.Cfg.PollIntervalMS) * time.Millisecond
+	lastPurge := time.Time{}
+	for {
+		if time.Since(lastPurge) > PurgeInterval {
+			if err := d.Purge(); err != nil {
+				log.Errorln("purging webhook deliveries: " + err.Error())
+			}
+			lastPurge = time.Now()
+		}
+		n, err := d.DeliverBatch()
+		if err != nil {
+			log.Errorln("delivering webhooks: " + err.Error())
+		}
+		if n < DeliveryBatchSize {
+			time.Sleep(interval) // if the batch was full, there may be more due, so don't wait
+		}
+	}
+}
+
+// pendingDelivery is a delivery claimed for an attempt.
+type pendingDelivery struct {
+	ID       int
+	LogID    int
+	Payload  []byte
+	Attempts int
+	URL      string
+	Secret   string
+}
+
+// DeliverBatch claims up to DeliveryBatchSize deliveries which are due, and attempts them concurrently. Returns the number of deliveries attempted.
+func (d *Dispatcher) DeliverBatch() (int, error) {
+	deliveries, err := d.claim()
+	if err != nil {
+		return 0, errors.New("claiming deliveries: " + err.Error())
+	}
+	wg := sync.WaitGroup{}
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery pendingDelivery) {
+			defer wg.Done()
+			deliverErr := d.deliver(delivery)
+			if err := d.record(delivery, deliverErr); err != nil {
+				log.Errorln("recording webhook delivery " + strconv.Itoa(delivery.ID) + ": " + err.Error())
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// claim returns the deliveries which are due, and pushes their next attempt back far enough that no other Dispatcher will claim them while they're being attempted.
+func (d *Dispatcher) claim() ([]pendingDelivery, error) {
+	leaseSecs := d.Cfg.TimeoutSeconds * 2
+	qry := `
+WITH claimed AS (
+  UPDATE webhook_delivery SET next_attempt = now() + ($1 || ' SECONDS')::INTERVAL
+  WHERE id IN (
+    SELECT d.id FROM webhook_delivery d
+    JOIN webhook w ON w.id = d.webhook
+    WHERE d.status = 'pending' AND d.next_attempt <= now() AND w.active
+    ORDER BY d.id
+    LIMIT $2
+    FOR UPDATE OF d SKIP LOCKED
+  )
+  RETURNING id, webhook, log, payload, attempts
+)
+SELECT c.id, c.log, c.payload, c.attempts, w.url, w.secret
+FROM claimed c JOIN webhook w ON w.id = c.webhook
+ORDER BY c.id
+`
+	rows, err := d.DB.Query(qry, leaseSecs, DeliveryBatchSize)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	deliveries := []pendingDelivery{}
+	for rows.Next() {
+		pd := pendingDelivery{}
+		if err := rows.Scan(&pd.ID, &pd.LogID, &pd.Payload, &pd.Attempts, &pd.URL, &pd.Secret); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		deliveries = append(deliveries, pd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("iterating: " + err.Error())
+	}
+	return deliveries, nil
+}
+
+// deliver makes a single attempt of the delivery, returning an error if it failed.
+func (d *Dispatcher) deliver(pd pendingDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, pd.URL, bytes.NewReader(pd.Payload))
+	if err != nil {
+		return errors.New("creating request: " + err.Error())
+	}
+	req.Header.Set(tc.ContentType, tc.ApplicationJson)
+	req.Header.Set(tc.WebhookSignatureHeader, tc.WebhookSignature(pd.Secret, pd.Payload))
+	req.Header.Set(tc.WebhookDeliveryHeader, strconv.Itoa(pd.ID))
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return errors.New("requesting: " + err.Error())
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, MaxErrorBodyLen))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("returned code " + strconv.Itoa(resp.StatusCode) + ": " + string(body))
+	}
+	return nil
+}
+
+// record saves the result of an attempt of the delivery. If the attempt failed, the delivery is scheduled to be retried, or if it has been attempted the maximum number of times, it's dead.
+func (d *Dispatcher) record(pd pendingDelivery, deliverErr error) error {
+	attempts := pd.Attempts + 1
+	if deliverErr == nil {
+		_, err := d.DB.Exec(`UPDATE webhook_delivery SET status = 'delivered', attempts = $2, last_error = NULL, last_updated = now() WHERE id = $1`, pd.ID, attempts)
+		return err
+	}
+	log.Warnln("webhook delivery " + strconv.Itoa(pd.ID) + " of change log " + strconv.Itoa(pd.LogID) + " to '" + pd.URL + "' attempt " + strconv.Itoa(attempts) + " failed: " + deliverErr.Error())
+	status := tc.WebhookDeliveryPending
+	if attempts >= d.Cfg.MaxAttempts {
+		status = tc.WebhookDeliveryDead
+	}
+	retrySecs := int(RetryInterval(time.Duration(d.Cfg.RetryBaseSeconds)*time.Second, attempts) / time.Second)
+	_, err := d.DB.Exec(`
+UPDATE webhook_delivery SET status = $2, attempts = $3, next_attempt = now() + ($4 || ' SECONDS')::INTERVAL, last_error = $5, last_updated = now()
+WHERE id = $1
+`, pd.ID, string(status), attempts, retrySecs, deliverErr.Error())
+	return err
+}
+
+// RetryInterval returns how long to wait before the next attempt of a delivery which has failed the given number of attempts: the base interval, doubled for each attempt after the first, up to MaxRetryInterval.
+func RetryInterval(base time.Duration, attempts int) time.Duration {
+	interval := base
+	for i := 1; i < attempts; i++ {
+		interval *= 2
+		if interval >= MaxRetryInterval {
+			return MaxRetryInterval
+		}
+	}
+	return interval
+}
+
+// Purge deletes successful deliveries older than the configured retention.
+func (d *Dispatcher) Purge() error {
+	_, err := d.DB.Exec(`DELETE FROM webhook_delivery WHERE status = 'delivered' AND last_updated < now() - ($1 || ' DAYS')::INTERVAL`, d.Cfg.RetentionDays)
+	return err
+}
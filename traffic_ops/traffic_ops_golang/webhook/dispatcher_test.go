@@ -0,0 +1,136 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/hmac"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestRetryInterval(t *testing.T) {
+	base := 30 * time.Second
+	expecteds := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		4:  4 * time.Minute,
+		8:  MaxRetryInterval,
+		50: MaxRetryInterval,
+	}
+	for attempts, expected := range expecteds {
+		if actual := RetryInterval(base, attempts); actual != expected {
+			t.Errorf("RetryInterval(%v, %v) expected %v, actual: %v", base, attempts, expected, actual)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	payload := []byte(`{"id":42,"level":"APICHANGE","message":"CDN: cdn0, ID: 1, ACTION: Created cdn, keys: { id:1 }"}`)
+	secret := "hunter2"
+
+	received := make(chan *http.Request, 1)
+	receivedBody := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- r
+		receivedBody <- body
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(nil, config.ConfigWebhooks{TimeoutSeconds: 5})
+	if err := d.deliver(pendingDelivery{ID: 7, LogID: 42, Payload: payload, URL: srv.URL, Secret: secret}); err != nil {
+		t.Fatalf("deliver expected nil error, actual: %v", err)
+	}
+
+	r := <-received
+	body := <-receivedBody
+	if string(body) != string(payload) {
+		t.Errorf("deliver expected body %s, actual: %s", payload, body)
+	}
+	if r.Method != http.MethodPost {
+		t.Errorf("deliver expected method POST, actual: %v", r.Method)
+	}
+	if !hmac.Equal([]byte(r.Header.Get(tc.WebhookSignatureHeader)), []byte(tc.WebhookSignature(secret, body))) {
+		t.Errorf("deliver expected signature %v, actual: %v", tc.WebhookSignature(secret, body), r.Header.Get(tc.WebhookSignatureHeader))
+	}
+	if !strings.HasPrefix(r.Header.Get(tc.WebhookSignatureHeader), "sha256=") {
+		t.Errorf("deliver expected signature to start with sha256=, actual: %v", r.Header.Get(tc.WebhookSignatureHeader))
+	}
+	if r.Header.Get(tc.WebhookDeliveryHeader) != "7" {
+		t.Errorf("deliver expected delivery header 7, actual: %v", r.Header.Get(tc.WebhookDeliveryHeader))
+	}
+}
+
+func TestDeliverFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("try later"))
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(nil, config.ConfigWebhooks{TimeoutSeconds: 5})
+	err := d.deliver(pendingDelivery{ID: 7, Payload: []byte(`{}`), URL: srv.URL, Secret: "s"})
+	if err == nil {
+		t.Fatalf("deliver to failing server expected error, actual: nil")
+	}
+	if !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "try later") {
+		t.Errorf("deliver to failing server expected error with code and body, actual: %v", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	d := NewDispatcher(mockDB, config.ConfigWebhooks{TimeoutSeconds: 5, MaxAttempts: 3, RetryBaseSeconds: 10})
+
+	mock.ExpectExec("UPDATE webhook_delivery SET status = 'delivered'").WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := d.record(pendingDelivery{ID: 1}, nil); err != nil {
+		t.Errorf("record success expected nil error, actual: %v", err)
+	}
+
+	mock.ExpectExec("UPDATE webhook_delivery").WithArgs(2, string(tc.WebhookDeliveryPending), 2, 20, "failed").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := d.record(pendingDelivery{ID: 2, Attempts: 1}, errors.New("failed")); err != nil {
+		t.Errorf("record failure expected nil error, actual: %v", err)
+	}
+
+	mock.ExpectExec("UPDATE webhook_delivery").WithArgs(3, string(tc.WebhookDeliveryDead), 3, 40, "failed").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := d.record(pendingDelivery{ID: 3, Attempts: 2}, errors.New("failed")); err != nil {
+		t.Errorf("record last failure expected nil error, actual: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all queries to be executed: %v", err)
+	}
+}
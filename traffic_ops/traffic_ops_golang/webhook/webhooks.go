@@ -0,0 +1,202 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/trafficcontrol/lib/go-tc"
+	"github.com/apache/trafficcontrol/lib/go-tc/tovalidate"
+	"github.com/apache/trafficcontrol/lib/go-util"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/cdn"
+	"github.com/apache/trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+// SecretLen is the length in bytes of generated webhook secrets, before hex encoding.
+const SecretLen = 32
+
+// TOWebhook is the api.CRUDer of webhooks.
+type TOWebhook struct {
+	api.APIInfoImpl `json:"-"`
+	tc.WebhookNullable
+}
+
+func (v *TOWebhook) SetLastUpdated(t tc.TimeNoMod) { v.LastUpdated = &t }
+func (v *TOWebhook) InsertQuery() string           { return insertQuery() }
+func (v *TOWebhook) NewReadObj() interface{}       { return &tc.WebhookNullable{} }
+func (v *TOWebhook) SelectQuery() string           { return selectQuery() }
+func (v *TOWebhook) ParamColumns() map[string]dbhelpers.WhereColumnInfo {
+	return map[string]dbhelpers.WhereColumnInfo{
+		"id":         dbhelpers.WhereColumnInfo{Column: "id", Checker: api.IsInt},
+		"name":       dbhelpers.WhereColumnInfo{Column: "name", Checker: nil},
+		"objectType": dbhelpers.WhereColumnInfo{Column: "object_type", Checker: nil},
+		"cdn":        dbhelpers.WhereColumnInfo{Column: "cdn", Checker: nil},
+		"level":      dbhelpers.WhereColumnInfo{Column: "level", Checker: nil},
+		"active":     dbhelpers.WhereColumnInfo{Column: "active", Checker: api.IsBool},
+	}
+}
+func (v *TOWebhook) UpdateQuery() string { return updateQuery() }
+func (v *TOWebhook) DeleteQuery() string { return deleteQuery() }
+
+func (wh TOWebhook) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{Field: "id", Func: api.GetIntKey}}
+}
+
+func (wh TOWebhook) GetKeys() (map[string]interface{}, bool) {
+	if wh.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *wh.ID}, true
+}
+
+func (wh TOWebhook) GetAuditName() string {
+	if wh.Name != nil {
+		return *wh.Name
+	}
+	if wh.ID != nil {
+		return strconv.Itoa(*wh.ID)
+	}
+	return "0"
+}
+
+func (wh TOWebhook) GetType() string {
+	return "webhook"
+}
+
+func (wh *TOWebhook) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	wh.ID = &i
+}
+
+// Validate fulfills the api.Validator interface
+func (wh TOWebhook) Validate() error {
+	validCDNName := validation.NewStringRule(cdn.IsValidCDNName, "invalid characters found - Use alphanumeric . or - .")
+	validScheme := validation.NewStringRule(isHTTPURL, "must be an http or https URL")
+	errs := validation.Errors{
+		"name": validation.Validate(wh.Name, validation.Required),
+		"url":  validation.Validate(wh.URL, validation.Required, is.URL, validScheme),
+		"cdn":  validation.Validate(wh.CDN, validCDNName),
+	}
+	return util.JoinErrs(tovalidate.ToErrors(errs))
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// Create creates the webhook. If no secret was given, a random one is generated; the secret is only returned in the response to Create.
+func (wh *TOWebhook) Create() (error, error, int) {
+	if wh.Secret == nil || *wh.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, errors.New("generating webhook secret: " + err.Error()), http.StatusInternalServerError
+		}
+		wh.Secret = &secret
+	}
+	if wh.Active == nil {
+		active := true
+		wh.Active = &active
+	}
+	return api.GenericCreate(wh)
+}
+
+func (wh *TOWebhook) Read() ([]interface{}, error, error, int) { return api.GenericRead(wh) }
+
+// Update updates the webhook. If no secret is given, the existing secret is kept.
+func (wh *TOWebhook) Update() (error, error, int) {
+	if wh.Secret != nil && *wh.Secret == "" {
+		wh.Secret = nil
+	}
+	if wh.Active == nil {
+		active := true
+		wh.Active = &active
+	}
+	return api.GenericUpdate(wh)
+}
+
+func (wh *TOWebhook) Delete() (error, error, int) { return api.GenericDelete(wh) }
+
+func newSecret() (string, error) {
+	bts := make([]byte, SecretLen)
+	if _, err := io.ReadFull(rand.Reader, bts); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bts), nil
+}
+
+func selectQuery() string {
+	// the secret is deliberately not selected, so it's never returned after creation
+	return `SELECT
+id,
+name,
+url,
+object_type,
+cdn,
+level,
+active,
+last_updated
+
+FROM webhook`
+}
+
+func updateQuery() string {
+	return `UPDATE
+webhook SET
+name=:name,
+url=:url,
+secret=COALESCE(:secret, secret),
+object_type=:object_type,
+cdn=:cdn,
+level=:level,
+active=:active
+WHERE id=:id RETURNING last_updated`
+}
+
+func insertQuery() string {
+	return `INSERT INTO webhook (
+name,
+url,
+secret,
+object_type,
+cdn,
+level,
+active) VALUES (
+:name,
+:url,
+:secret,
+:object_type,
+:cdn,
+:level,
+:active) RETURNING id,last_updated`
+}
+
+func deleteQuery() string {
+	return `DELETE FROM webhook WHERE id = :id`
+}
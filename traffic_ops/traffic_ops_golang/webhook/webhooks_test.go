@@ -0,0 +1,73 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/trafficcontrol/lib/go-util"
+)
+
+func TestValidate(t *testing.T) {
+	wh := TOWebhook{}
+	wh.Name = util.StrPtr("hook")
+	wh.URL = util.StrPtr("https://example.test/hook")
+	if err := wh.Validate(); err != nil {
+		t.Errorf("Validate valid webhook expected nil error, actual: %v", err)
+	}
+
+	wh.CDN = util.StrPtr("cdn-1.example")
+	if err := wh.Validate(); err != nil {
+		t.Errorf("Validate valid webhook with cdn expected nil error, actual: %v", err)
+	}
+
+	invalids := []TOWebhook{}
+	noName := wh
+	noName.Name = nil
+	invalids = append(invalids, noName)
+	ftp := wh
+	ftp.URL = util.StrPtr("ftp://example.test/hook")
+	invalids = append(invalids, ftp)
+	badCDN := wh
+	badCDN.CDN = util.StrPtr("cdn 1")
+	invalids = append(invalids, badCDN)
+	for _, invalid := range invalids {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate invalid webhook %+v expected error, actual: nil", invalid.WebhookNullable)
+		}
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := newSecret()
+	if err != nil {
+		t.Fatalf("newSecret expected nil error, actual: %v", err)
+	}
+	b, err := newSecret()
+	if err != nil {
+		t.Fatalf("newSecret expected nil error, actual: %v", err)
+	}
+	if len(a) != SecretLen*2 {
+		t.Errorf("newSecret expected length %v, actual: %v", SecretLen*2, len(a))
+	}
+	if a == b {
+		t.Errorf("newSecret expected different secrets, actual: both %v", a)
+	}
+}